@@ -26,6 +26,18 @@ const (
 	InteractiveService_Get_FullMethodName                  = "/intr.v1.InteractiveService/Get"
 	InteractiveService_GetByIds_FullMethodName             = "/intr.v1.InteractiveService/GetByIds"
 	InteractiveService_GetTopNLikedArticles_FullMethodName = "/intr.v1.InteractiveService/GetTopNLikedArticles"
+	InteractiveService_CreateCollection_FullMethodName     = "/intr.v1.InteractiveService/CreateCollection"
+	InteractiveService_RenameCollection_FullMethodName     = "/intr.v1.InteractiveService/RenameCollection"
+	InteractiveService_DeleteCollection_FullMethodName     = "/intr.v1.InteractiveService/DeleteCollection"
+	InteractiveService_ListCollections_FullMethodName      = "/intr.v1.InteractiveService/ListCollections"
+	InteractiveService_ListCollectionItems_FullMethodName  = "/intr.v1.InteractiveService/ListCollectionItems"
+	InteractiveService_RemoveCollectionItem_FullMethodName = "/intr.v1.InteractiveService/RemoveCollectionItem"
+	InteractiveService_MoveCollectionItem_FullMethodName   = "/intr.v1.InteractiveService/MoveCollectionItem"
+	InteractiveService_GetUserStatus_FullMethodName        = "/intr.v1.InteractiveService/GetUserStatus"
+	InteractiveService_ListLikedItems_FullMethodName       = "/intr.v1.InteractiveService/ListLikedItems"
+	InteractiveService_ListCollectedItems_FullMethodName   = "/intr.v1.InteractiveService/ListCollectedItems"
+	InteractiveService_React_FullMethodName                = "/intr.v1.InteractiveService/React"
+	InteractiveService_Unreact_FullMethodName              = "/intr.v1.InteractiveService/Unreact"
 )
 
 // InteractiveServiceClient is the client API for InteractiveService service.
@@ -39,6 +51,27 @@ type InteractiveServiceClient interface {
 	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
 	GetByIds(ctx context.Context, in *GetByIdsRequest, opts ...grpc.CallOption) (*GetByIdsResponse, error)
 	GetTopNLikedArticles(ctx context.Context, in *GetTopNLikedArticlesRequest, opts ...grpc.CallOption) (*GetTopNLikedArticlesResponse, error)
+	// 收藏夹。cid 为 0 的是默认收藏夹，每个用户都有
+	CreateCollection(ctx context.Context, in *CreateCollectionRequest, opts ...grpc.CallOption) (*CreateCollectionResponse, error)
+	RenameCollection(ctx context.Context, in *RenameCollectionRequest, opts ...grpc.CallOption) (*RenameCollectionResponse, error)
+	// DeleteCollection 里面的收藏也会被取消
+	DeleteCollection(ctx context.Context, in *DeleteCollectionRequest, opts ...grpc.CallOption) (*DeleteCollectionResponse, error)
+	ListCollections(ctx context.Context, in *ListCollectionsRequest, opts ...grpc.CallOption) (*ListCollectionsResponse, error)
+	ListCollectionItems(ctx context.Context, in *ListCollectionItemsRequest, opts ...grpc.CallOption) (*ListCollectionItemsResponse, error)
+	// RemoveCollectionItem 取消收藏
+	RemoveCollectionItem(ctx context.Context, in *RemoveCollectionItemRequest, opts ...grpc.CallOption) (*RemoveCollectionItemResponse, error)
+	MoveCollectionItem(ctx context.Context, in *MoveCollectionItemRequest, opts ...grpc.CallOption) (*MoveCollectionItemResponse, error)
+	// GetUserStatus 列表页一次查出用户对每一个资源有没有点赞、收藏
+	GetUserStatus(ctx context.Context, in *GetUserStatusRequest, opts ...grpc.CallOption) (*GetUserStatusResponse, error)
+	// ListLikedItems 我的点赞，最近点赞的在前面
+	ListLikedItems(ctx context.Context, in *ListLikedItemsRequest, opts ...grpc.CallOption) (*ListLikedItemsResponse, error)
+	// ListCollectedItems 我的收藏，所有收藏夹里面的，最近收藏的在前面
+	ListCollectedItems(ctx context.Context, in *ListCollectedItemsRequest, opts ...grpc.CallOption) (*ListCollectedItemsResponse, error)
+	// React 表态，每个用户对一个资源只能有一个表态，再次 React 会替换掉原来的。
+	// reaction 为 like 的时候等价于 Like
+	React(ctx context.Context, in *ReactRequest, opts ...grpc.CallOption) (*ReactResponse, error)
+	// Unreact 取消用户对这个资源的表态，不管是什么表态
+	Unreact(ctx context.Context, in *UnreactRequest, opts ...grpc.CallOption) (*UnreactResponse, error)
 }
 
 type interactiveServiceClient struct {
@@ -112,6 +145,114 @@ func (c *interactiveServiceClient) GetTopNLikedArticles(ctx context.Context, in
 	return out, nil
 }
 
+func (c *interactiveServiceClient) CreateCollection(ctx context.Context, in *CreateCollectionRequest, opts ...grpc.CallOption) (*CreateCollectionResponse, error) {
+	out := new(CreateCollectionResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_CreateCollection_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) RenameCollection(ctx context.Context, in *RenameCollectionRequest, opts ...grpc.CallOption) (*RenameCollectionResponse, error) {
+	out := new(RenameCollectionResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_RenameCollection_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) DeleteCollection(ctx context.Context, in *DeleteCollectionRequest, opts ...grpc.CallOption) (*DeleteCollectionResponse, error) {
+	out := new(DeleteCollectionResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_DeleteCollection_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) ListCollections(ctx context.Context, in *ListCollectionsRequest, opts ...grpc.CallOption) (*ListCollectionsResponse, error) {
+	out := new(ListCollectionsResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_ListCollections_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) ListCollectionItems(ctx context.Context, in *ListCollectionItemsRequest, opts ...grpc.CallOption) (*ListCollectionItemsResponse, error) {
+	out := new(ListCollectionItemsResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_ListCollectionItems_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) RemoveCollectionItem(ctx context.Context, in *RemoveCollectionItemRequest, opts ...grpc.CallOption) (*RemoveCollectionItemResponse, error) {
+	out := new(RemoveCollectionItemResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_RemoveCollectionItem_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) MoveCollectionItem(ctx context.Context, in *MoveCollectionItemRequest, opts ...grpc.CallOption) (*MoveCollectionItemResponse, error) {
+	out := new(MoveCollectionItemResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_MoveCollectionItem_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) GetUserStatus(ctx context.Context, in *GetUserStatusRequest, opts ...grpc.CallOption) (*GetUserStatusResponse, error) {
+	out := new(GetUserStatusResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_GetUserStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) ListLikedItems(ctx context.Context, in *ListLikedItemsRequest, opts ...grpc.CallOption) (*ListLikedItemsResponse, error) {
+	out := new(ListLikedItemsResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_ListLikedItems_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) ListCollectedItems(ctx context.Context, in *ListCollectedItemsRequest, opts ...grpc.CallOption) (*ListCollectedItemsResponse, error) {
+	out := new(ListCollectedItemsResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_ListCollectedItems_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) React(ctx context.Context, in *ReactRequest, opts ...grpc.CallOption) (*ReactResponse, error) {
+	out := new(ReactResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_React_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) Unreact(ctx context.Context, in *UnreactRequest, opts ...grpc.CallOption) (*UnreactResponse, error) {
+	out := new(UnreactResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_Unreact_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // InteractiveServiceServer is the server API for InteractiveService service.
 // All implementations must embed UnimplementedInteractiveServiceServer
 // for forward compatibility
@@ -123,6 +264,27 @@ type InteractiveServiceServer interface {
 	Get(context.Context, *GetRequest) (*GetResponse, error)
 	GetByIds(context.Context, *GetByIdsRequest) (*GetByIdsResponse, error)
 	GetTopNLikedArticles(context.Context, *GetTopNLikedArticlesRequest) (*GetTopNLikedArticlesResponse, error)
+	// 收藏夹。cid 为 0 的是默认收藏夹，每个用户都有
+	CreateCollection(context.Context, *CreateCollectionRequest) (*CreateCollectionResponse, error)
+	RenameCollection(context.Context, *RenameCollectionRequest) (*RenameCollectionResponse, error)
+	// DeleteCollection 里面的收藏也会被取消
+	DeleteCollection(context.Context, *DeleteCollectionRequest) (*DeleteCollectionResponse, error)
+	ListCollections(context.Context, *ListCollectionsRequest) (*ListCollectionsResponse, error)
+	ListCollectionItems(context.Context, *ListCollectionItemsRequest) (*ListCollectionItemsResponse, error)
+	// RemoveCollectionItem 取消收藏
+	RemoveCollectionItem(context.Context, *RemoveCollectionItemRequest) (*RemoveCollectionItemResponse, error)
+	MoveCollectionItem(context.Context, *MoveCollectionItemRequest) (*MoveCollectionItemResponse, error)
+	// GetUserStatus 列表页一次查出用户对每一个资源有没有点赞、收藏
+	GetUserStatus(context.Context, *GetUserStatusRequest) (*GetUserStatusResponse, error)
+	// ListLikedItems 我的点赞，最近点赞的在前面
+	ListLikedItems(context.Context, *ListLikedItemsRequest) (*ListLikedItemsResponse, error)
+	// ListCollectedItems 我的收藏，所有收藏夹里面的，最近收藏的在前面
+	ListCollectedItems(context.Context, *ListCollectedItemsRequest) (*ListCollectedItemsResponse, error)
+	// React 表态，每个用户对一个资源只能有一个表态，再次 React 会替换掉原来的。
+	// reaction 为 like 的时候等价于 Like
+	React(context.Context, *ReactRequest) (*ReactResponse, error)
+	// Unreact 取消用户对这个资源的表态，不管是什么表态
+	Unreact(context.Context, *UnreactRequest) (*UnreactResponse, error)
 	mustEmbedUnimplementedInteractiveServiceServer()
 }
 
@@ -151,6 +313,42 @@ func (UnimplementedInteractiveServiceServer) GetByIds(context.Context, *GetByIds
 func (UnimplementedInteractiveServiceServer) GetTopNLikedArticles(context.Context, *GetTopNLikedArticlesRequest) (*GetTopNLikedArticlesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTopNLikedArticles not implemented")
 }
+func (UnimplementedInteractiveServiceServer) CreateCollection(context.Context, *CreateCollectionRequest) (*CreateCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCollection not implemented")
+}
+func (UnimplementedInteractiveServiceServer) RenameCollection(context.Context, *RenameCollectionRequest) (*RenameCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameCollection not implemented")
+}
+func (UnimplementedInteractiveServiceServer) DeleteCollection(context.Context, *DeleteCollectionRequest) (*DeleteCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCollection not implemented")
+}
+func (UnimplementedInteractiveServiceServer) ListCollections(context.Context, *ListCollectionsRequest) (*ListCollectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCollections not implemented")
+}
+func (UnimplementedInteractiveServiceServer) ListCollectionItems(context.Context, *ListCollectionItemsRequest) (*ListCollectionItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCollectionItems not implemented")
+}
+func (UnimplementedInteractiveServiceServer) RemoveCollectionItem(context.Context, *RemoveCollectionItemRequest) (*RemoveCollectionItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveCollectionItem not implemented")
+}
+func (UnimplementedInteractiveServiceServer) MoveCollectionItem(context.Context, *MoveCollectionItemRequest) (*MoveCollectionItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveCollectionItem not implemented")
+}
+func (UnimplementedInteractiveServiceServer) GetUserStatus(context.Context, *GetUserStatusRequest) (*GetUserStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserStatus not implemented")
+}
+func (UnimplementedInteractiveServiceServer) ListLikedItems(context.Context, *ListLikedItemsRequest) (*ListLikedItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLikedItems not implemented")
+}
+func (UnimplementedInteractiveServiceServer) ListCollectedItems(context.Context, *ListCollectedItemsRequest) (*ListCollectedItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCollectedItems not implemented")
+}
+func (UnimplementedInteractiveServiceServer) React(context.Context, *ReactRequest) (*ReactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method React not implemented")
+}
+func (UnimplementedInteractiveServiceServer) Unreact(context.Context, *UnreactRequest) (*UnreactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unreact not implemented")
+}
 func (UnimplementedInteractiveServiceServer) mustEmbedUnimplementedInteractiveServiceServer() {}
 
 // UnsafeInteractiveServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -290,6 +488,222 @@ func _InteractiveService_GetTopNLikedArticles_Handler(srv interface{}, ctx conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InteractiveService_CreateCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).CreateCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_CreateCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).CreateCollection(ctx, req.(*CreateCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_RenameCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).RenameCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_RenameCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).RenameCollection(ctx, req.(*RenameCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_DeleteCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).DeleteCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_DeleteCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).DeleteCollection(ctx, req.(*DeleteCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_ListCollections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCollectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).ListCollections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_ListCollections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).ListCollections(ctx, req.(*ListCollectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_ListCollectionItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCollectionItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).ListCollectionItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_ListCollectionItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).ListCollectionItems(ctx, req.(*ListCollectionItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_RemoveCollectionItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveCollectionItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).RemoveCollectionItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_RemoveCollectionItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).RemoveCollectionItem(ctx, req.(*RemoveCollectionItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_MoveCollectionItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveCollectionItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).MoveCollectionItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_MoveCollectionItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).MoveCollectionItem(ctx, req.(*MoveCollectionItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_GetUserStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).GetUserStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_GetUserStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).GetUserStatus(ctx, req.(*GetUserStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_ListLikedItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLikedItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).ListLikedItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_ListLikedItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).ListLikedItems(ctx, req.(*ListLikedItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_ListCollectedItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCollectedItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).ListCollectedItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_ListCollectedItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).ListCollectedItems(ctx, req.(*ListCollectedItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_React_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).React(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_React_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).React(ctx, req.(*ReactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_Unreact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnreactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).Unreact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_Unreact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).Unreact(ctx, req.(*UnreactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // InteractiveService_ServiceDesc is the grpc.ServiceDesc for InteractiveService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -325,6 +739,54 @@ var InteractiveService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTopNLikedArticles",
 			Handler:    _InteractiveService_GetTopNLikedArticles_Handler,
 		},
+		{
+			MethodName: "CreateCollection",
+			Handler:    _InteractiveService_CreateCollection_Handler,
+		},
+		{
+			MethodName: "RenameCollection",
+			Handler:    _InteractiveService_RenameCollection_Handler,
+		},
+		{
+			MethodName: "DeleteCollection",
+			Handler:    _InteractiveService_DeleteCollection_Handler,
+		},
+		{
+			MethodName: "ListCollections",
+			Handler:    _InteractiveService_ListCollections_Handler,
+		},
+		{
+			MethodName: "ListCollectionItems",
+			Handler:    _InteractiveService_ListCollectionItems_Handler,
+		},
+		{
+			MethodName: "RemoveCollectionItem",
+			Handler:    _InteractiveService_RemoveCollectionItem_Handler,
+		},
+		{
+			MethodName: "MoveCollectionItem",
+			Handler:    _InteractiveService_MoveCollectionItem_Handler,
+		},
+		{
+			MethodName: "GetUserStatus",
+			Handler:    _InteractiveService_GetUserStatus_Handler,
+		},
+		{
+			MethodName: "ListLikedItems",
+			Handler:    _InteractiveService_ListLikedItems_Handler,
+		},
+		{
+			MethodName: "ListCollectedItems",
+			Handler:    _InteractiveService_ListCollectedItems_Handler,
+		},
+		{
+			MethodName: "React",
+			Handler:    _InteractiveService_React_Handler,
+		},
+		{
+			MethodName: "Unreact",
+			Handler:    _InteractiveService_Unreact_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "intr/v1/interactive.proto",
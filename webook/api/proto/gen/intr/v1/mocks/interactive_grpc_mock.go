@@ -0,0 +1,776 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./api/proto/gen/intr/v1/interactive_grpc.pb.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./api/proto/gen/intr/v1/interactive_grpc.pb.go -destination=./api/proto/gen/intr/v1/mocks/interactive_grpc_mock.go
+//
+// Package mock_intrv1 is a generated GoMock package.
+package mock_intrv1
+
+import (
+	context "context"
+	reflect "reflect"
+
+	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
+	gomock "go.uber.org/mock/gomock"
+	grpc "google.golang.org/grpc"
+)
+
+// MockInteractiveServiceClient is a mock of InteractiveServiceClient interface.
+type MockInteractiveServiceClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockInteractiveServiceClientMockRecorder
+}
+
+// MockInteractiveServiceClientMockRecorder is the mock recorder for MockInteractiveServiceClient.
+type MockInteractiveServiceClientMockRecorder struct {
+	mock *MockInteractiveServiceClient
+}
+
+// NewMockInteractiveServiceClient creates a new mock instance.
+func NewMockInteractiveServiceClient(ctrl *gomock.Controller) *MockInteractiveServiceClient {
+	mock := &MockInteractiveServiceClient{ctrl: ctrl}
+	mock.recorder = &MockInteractiveServiceClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInteractiveServiceClient) EXPECT() *MockInteractiveServiceClientMockRecorder {
+	return m.recorder
+}
+
+// CancelLike mocks base method.
+func (m *MockInteractiveServiceClient) CancelLike(ctx context.Context, in *intrv1.CancelLikeRequest, opts ...grpc.CallOption) (*intrv1.CancelLikeResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CancelLike", varargs...)
+	ret0, _ := ret[0].(*intrv1.CancelLikeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelLike indicates an expected call of CancelLike.
+func (mr *MockInteractiveServiceClientMockRecorder) CancelLike(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelLike", reflect.TypeOf((*MockInteractiveServiceClient)(nil).CancelLike), varargs...)
+}
+
+// Collect mocks base method.
+func (m *MockInteractiveServiceClient) Collect(ctx context.Context, in *intrv1.CollectRequest, opts ...grpc.CallOption) (*intrv1.CollectResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Collect", varargs...)
+	ret0, _ := ret[0].(*intrv1.CollectResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Collect indicates an expected call of Collect.
+func (mr *MockInteractiveServiceClientMockRecorder) Collect(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Collect", reflect.TypeOf((*MockInteractiveServiceClient)(nil).Collect), varargs...)
+}
+
+// CreateCollection mocks base method.
+func (m *MockInteractiveServiceClient) CreateCollection(ctx context.Context, in *intrv1.CreateCollectionRequest, opts ...grpc.CallOption) (*intrv1.CreateCollectionResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateCollection", varargs...)
+	ret0, _ := ret[0].(*intrv1.CreateCollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCollection indicates an expected call of CreateCollection.
+func (mr *MockInteractiveServiceClientMockRecorder) CreateCollection(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCollection", reflect.TypeOf((*MockInteractiveServiceClient)(nil).CreateCollection), varargs...)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockInteractiveServiceClient) DeleteCollection(ctx context.Context, in *intrv1.DeleteCollectionRequest, opts ...grpc.CallOption) (*intrv1.DeleteCollectionResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteCollection", varargs...)
+	ret0, _ := ret[0].(*intrv1.DeleteCollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockInteractiveServiceClientMockRecorder) DeleteCollection(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockInteractiveServiceClient)(nil).DeleteCollection), varargs...)
+}
+
+// Get mocks base method.
+func (m *MockInteractiveServiceClient) Get(ctx context.Context, in *intrv1.GetRequest, opts ...grpc.CallOption) (*intrv1.GetResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Get", varargs...)
+	ret0, _ := ret[0].(*intrv1.GetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockInteractiveServiceClientMockRecorder) Get(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInteractiveServiceClient)(nil).Get), varargs...)
+}
+
+// GetByIds mocks base method.
+func (m *MockInteractiveServiceClient) GetByIds(ctx context.Context, in *intrv1.GetByIdsRequest, opts ...grpc.CallOption) (*intrv1.GetByIdsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetByIds", varargs...)
+	ret0, _ := ret[0].(*intrv1.GetByIdsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIds indicates an expected call of GetByIds.
+func (mr *MockInteractiveServiceClientMockRecorder) GetByIds(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIds", reflect.TypeOf((*MockInteractiveServiceClient)(nil).GetByIds), varargs...)
+}
+
+// GetTopNLikedArticles mocks base method.
+func (m *MockInteractiveServiceClient) GetTopNLikedArticles(ctx context.Context, in *intrv1.GetTopNLikedArticlesRequest, opts ...grpc.CallOption) (*intrv1.GetTopNLikedArticlesResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetTopNLikedArticles", varargs...)
+	ret0, _ := ret[0].(*intrv1.GetTopNLikedArticlesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopNLikedArticles indicates an expected call of GetTopNLikedArticles.
+func (mr *MockInteractiveServiceClientMockRecorder) GetTopNLikedArticles(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopNLikedArticles", reflect.TypeOf((*MockInteractiveServiceClient)(nil).GetTopNLikedArticles), varargs...)
+}
+
+// GetUserStatus mocks base method.
+func (m *MockInteractiveServiceClient) GetUserStatus(ctx context.Context, in *intrv1.GetUserStatusRequest, opts ...grpc.CallOption) (*intrv1.GetUserStatusResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetUserStatus", varargs...)
+	ret0, _ := ret[0].(*intrv1.GetUserStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserStatus indicates an expected call of GetUserStatus.
+func (mr *MockInteractiveServiceClientMockRecorder) GetUserStatus(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserStatus", reflect.TypeOf((*MockInteractiveServiceClient)(nil).GetUserStatus), varargs...)
+}
+
+// IncrReadCnt mocks base method.
+func (m *MockInteractiveServiceClient) IncrReadCnt(ctx context.Context, in *intrv1.IncrReadCntRequest, opts ...grpc.CallOption) (*intrv1.IncrReadCntResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "IncrReadCnt", varargs...)
+	ret0, _ := ret[0].(*intrv1.IncrReadCntResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrReadCnt indicates an expected call of IncrReadCnt.
+func (mr *MockInteractiveServiceClientMockRecorder) IncrReadCnt(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrReadCnt", reflect.TypeOf((*MockInteractiveServiceClient)(nil).IncrReadCnt), varargs...)
+}
+
+// Like mocks base method.
+func (m *MockInteractiveServiceClient) Like(ctx context.Context, in *intrv1.LikeRequest, opts ...grpc.CallOption) (*intrv1.LikeResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Like", varargs...)
+	ret0, _ := ret[0].(*intrv1.LikeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Like indicates an expected call of Like.
+func (mr *MockInteractiveServiceClientMockRecorder) Like(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Like", reflect.TypeOf((*MockInteractiveServiceClient)(nil).Like), varargs...)
+}
+
+// ListCollectedItems mocks base method.
+func (m *MockInteractiveServiceClient) ListCollectedItems(ctx context.Context, in *intrv1.ListCollectedItemsRequest, opts ...grpc.CallOption) (*intrv1.ListCollectedItemsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListCollectedItems", varargs...)
+	ret0, _ := ret[0].(*intrv1.ListCollectedItemsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCollectedItems indicates an expected call of ListCollectedItems.
+func (mr *MockInteractiveServiceClientMockRecorder) ListCollectedItems(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollectedItems", reflect.TypeOf((*MockInteractiveServiceClient)(nil).ListCollectedItems), varargs...)
+}
+
+// ListCollectionItems mocks base method.
+func (m *MockInteractiveServiceClient) ListCollectionItems(ctx context.Context, in *intrv1.ListCollectionItemsRequest, opts ...grpc.CallOption) (*intrv1.ListCollectionItemsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListCollectionItems", varargs...)
+	ret0, _ := ret[0].(*intrv1.ListCollectionItemsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCollectionItems indicates an expected call of ListCollectionItems.
+func (mr *MockInteractiveServiceClientMockRecorder) ListCollectionItems(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollectionItems", reflect.TypeOf((*MockInteractiveServiceClient)(nil).ListCollectionItems), varargs...)
+}
+
+// ListCollections mocks base method.
+func (m *MockInteractiveServiceClient) ListCollections(ctx context.Context, in *intrv1.ListCollectionsRequest, opts ...grpc.CallOption) (*intrv1.ListCollectionsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListCollections", varargs...)
+	ret0, _ := ret[0].(*intrv1.ListCollectionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCollections indicates an expected call of ListCollections.
+func (mr *MockInteractiveServiceClientMockRecorder) ListCollections(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollections", reflect.TypeOf((*MockInteractiveServiceClient)(nil).ListCollections), varargs...)
+}
+
+// ListLikedItems mocks base method.
+func (m *MockInteractiveServiceClient) ListLikedItems(ctx context.Context, in *intrv1.ListLikedItemsRequest, opts ...grpc.CallOption) (*intrv1.ListLikedItemsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListLikedItems", varargs...)
+	ret0, _ := ret[0].(*intrv1.ListLikedItemsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLikedItems indicates an expected call of ListLikedItems.
+func (mr *MockInteractiveServiceClientMockRecorder) ListLikedItems(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLikedItems", reflect.TypeOf((*MockInteractiveServiceClient)(nil).ListLikedItems), varargs...)
+}
+
+// MoveCollectionItem mocks base method.
+func (m *MockInteractiveServiceClient) MoveCollectionItem(ctx context.Context, in *intrv1.MoveCollectionItemRequest, opts ...grpc.CallOption) (*intrv1.MoveCollectionItemResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MoveCollectionItem", varargs...)
+	ret0, _ := ret[0].(*intrv1.MoveCollectionItemResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveCollectionItem indicates an expected call of MoveCollectionItem.
+func (mr *MockInteractiveServiceClientMockRecorder) MoveCollectionItem(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveCollectionItem", reflect.TypeOf((*MockInteractiveServiceClient)(nil).MoveCollectionItem), varargs...)
+}
+
+// React mocks base method.
+func (m *MockInteractiveServiceClient) React(ctx context.Context, in *intrv1.ReactRequest, opts ...grpc.CallOption) (*intrv1.ReactResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "React", varargs...)
+	ret0, _ := ret[0].(*intrv1.ReactResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// React indicates an expected call of React.
+func (mr *MockInteractiveServiceClientMockRecorder) React(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "React", reflect.TypeOf((*MockInteractiveServiceClient)(nil).React), varargs...)
+}
+
+// RemoveCollectionItem mocks base method.
+func (m *MockInteractiveServiceClient) RemoveCollectionItem(ctx context.Context, in *intrv1.RemoveCollectionItemRequest, opts ...grpc.CallOption) (*intrv1.RemoveCollectionItemResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveCollectionItem", varargs...)
+	ret0, _ := ret[0].(*intrv1.RemoveCollectionItemResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveCollectionItem indicates an expected call of RemoveCollectionItem.
+func (mr *MockInteractiveServiceClientMockRecorder) RemoveCollectionItem(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCollectionItem", reflect.TypeOf((*MockInteractiveServiceClient)(nil).RemoveCollectionItem), varargs...)
+}
+
+// RenameCollection mocks base method.
+func (m *MockInteractiveServiceClient) RenameCollection(ctx context.Context, in *intrv1.RenameCollectionRequest, opts ...grpc.CallOption) (*intrv1.RenameCollectionResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RenameCollection", varargs...)
+	ret0, _ := ret[0].(*intrv1.RenameCollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameCollection indicates an expected call of RenameCollection.
+func (mr *MockInteractiveServiceClientMockRecorder) RenameCollection(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameCollection", reflect.TypeOf((*MockInteractiveServiceClient)(nil).RenameCollection), varargs...)
+}
+
+// Unreact mocks base method.
+func (m *MockInteractiveServiceClient) Unreact(ctx context.Context, in *intrv1.UnreactRequest, opts ...grpc.CallOption) (*intrv1.UnreactResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Unreact", varargs...)
+	ret0, _ := ret[0].(*intrv1.UnreactResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unreact indicates an expected call of Unreact.
+func (mr *MockInteractiveServiceClientMockRecorder) Unreact(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unreact", reflect.TypeOf((*MockInteractiveServiceClient)(nil).Unreact), varargs...)
+}
+
+// MockInteractiveServiceServer is a mock of InteractiveServiceServer interface.
+type MockInteractiveServiceServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockInteractiveServiceServerMockRecorder
+}
+
+// MockInteractiveServiceServerMockRecorder is the mock recorder for MockInteractiveServiceServer.
+type MockInteractiveServiceServerMockRecorder struct {
+	mock *MockInteractiveServiceServer
+}
+
+// NewMockInteractiveServiceServer creates a new mock instance.
+func NewMockInteractiveServiceServer(ctrl *gomock.Controller) *MockInteractiveServiceServer {
+	mock := &MockInteractiveServiceServer{ctrl: ctrl}
+	mock.recorder = &MockInteractiveServiceServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInteractiveServiceServer) EXPECT() *MockInteractiveServiceServerMockRecorder {
+	return m.recorder
+}
+
+// CancelLike mocks base method.
+func (m *MockInteractiveServiceServer) CancelLike(arg0 context.Context, arg1 *intrv1.CancelLikeRequest) (*intrv1.CancelLikeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelLike", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.CancelLikeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelLike indicates an expected call of CancelLike.
+func (mr *MockInteractiveServiceServerMockRecorder) CancelLike(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelLike", reflect.TypeOf((*MockInteractiveServiceServer)(nil).CancelLike), arg0, arg1)
+}
+
+// Collect mocks base method.
+func (m *MockInteractiveServiceServer) Collect(arg0 context.Context, arg1 *intrv1.CollectRequest) (*intrv1.CollectResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Collect", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.CollectResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Collect indicates an expected call of Collect.
+func (mr *MockInteractiveServiceServerMockRecorder) Collect(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Collect", reflect.TypeOf((*MockInteractiveServiceServer)(nil).Collect), arg0, arg1)
+}
+
+// CreateCollection mocks base method.
+func (m *MockInteractiveServiceServer) CreateCollection(arg0 context.Context, arg1 *intrv1.CreateCollectionRequest) (*intrv1.CreateCollectionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCollection", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.CreateCollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCollection indicates an expected call of CreateCollection.
+func (mr *MockInteractiveServiceServerMockRecorder) CreateCollection(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCollection", reflect.TypeOf((*MockInteractiveServiceServer)(nil).CreateCollection), arg0, arg1)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockInteractiveServiceServer) DeleteCollection(arg0 context.Context, arg1 *intrv1.DeleteCollectionRequest) (*intrv1.DeleteCollectionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.DeleteCollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockInteractiveServiceServerMockRecorder) DeleteCollection(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockInteractiveServiceServer)(nil).DeleteCollection), arg0, arg1)
+}
+
+// Get mocks base method.
+func (m *MockInteractiveServiceServer) Get(arg0 context.Context, arg1 *intrv1.GetRequest) (*intrv1.GetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.GetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockInteractiveServiceServerMockRecorder) Get(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInteractiveServiceServer)(nil).Get), arg0, arg1)
+}
+
+// GetByIds mocks base method.
+func (m *MockInteractiveServiceServer) GetByIds(arg0 context.Context, arg1 *intrv1.GetByIdsRequest) (*intrv1.GetByIdsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIds", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.GetByIdsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIds indicates an expected call of GetByIds.
+func (mr *MockInteractiveServiceServerMockRecorder) GetByIds(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIds", reflect.TypeOf((*MockInteractiveServiceServer)(nil).GetByIds), arg0, arg1)
+}
+
+// GetTopNLikedArticles mocks base method.
+func (m *MockInteractiveServiceServer) GetTopNLikedArticles(arg0 context.Context, arg1 *intrv1.GetTopNLikedArticlesRequest) (*intrv1.GetTopNLikedArticlesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopNLikedArticles", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.GetTopNLikedArticlesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopNLikedArticles indicates an expected call of GetTopNLikedArticles.
+func (mr *MockInteractiveServiceServerMockRecorder) GetTopNLikedArticles(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopNLikedArticles", reflect.TypeOf((*MockInteractiveServiceServer)(nil).GetTopNLikedArticles), arg0, arg1)
+}
+
+// GetUserStatus mocks base method.
+func (m *MockInteractiveServiceServer) GetUserStatus(arg0 context.Context, arg1 *intrv1.GetUserStatusRequest) (*intrv1.GetUserStatusResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserStatus", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.GetUserStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserStatus indicates an expected call of GetUserStatus.
+func (mr *MockInteractiveServiceServerMockRecorder) GetUserStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserStatus", reflect.TypeOf((*MockInteractiveServiceServer)(nil).GetUserStatus), arg0, arg1)
+}
+
+// IncrReadCnt mocks base method.
+func (m *MockInteractiveServiceServer) IncrReadCnt(arg0 context.Context, arg1 *intrv1.IncrReadCntRequest) (*intrv1.IncrReadCntResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrReadCnt", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.IncrReadCntResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrReadCnt indicates an expected call of IncrReadCnt.
+func (mr *MockInteractiveServiceServerMockRecorder) IncrReadCnt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrReadCnt", reflect.TypeOf((*MockInteractiveServiceServer)(nil).IncrReadCnt), arg0, arg1)
+}
+
+// Like mocks base method.
+func (m *MockInteractiveServiceServer) Like(arg0 context.Context, arg1 *intrv1.LikeRequest) (*intrv1.LikeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Like", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.LikeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Like indicates an expected call of Like.
+func (mr *MockInteractiveServiceServerMockRecorder) Like(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Like", reflect.TypeOf((*MockInteractiveServiceServer)(nil).Like), arg0, arg1)
+}
+
+// ListCollectedItems mocks base method.
+func (m *MockInteractiveServiceServer) ListCollectedItems(arg0 context.Context, arg1 *intrv1.ListCollectedItemsRequest) (*intrv1.ListCollectedItemsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCollectedItems", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.ListCollectedItemsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCollectedItems indicates an expected call of ListCollectedItems.
+func (mr *MockInteractiveServiceServerMockRecorder) ListCollectedItems(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollectedItems", reflect.TypeOf((*MockInteractiveServiceServer)(nil).ListCollectedItems), arg0, arg1)
+}
+
+// ListCollectionItems mocks base method.
+func (m *MockInteractiveServiceServer) ListCollectionItems(arg0 context.Context, arg1 *intrv1.ListCollectionItemsRequest) (*intrv1.ListCollectionItemsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCollectionItems", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.ListCollectionItemsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCollectionItems indicates an expected call of ListCollectionItems.
+func (mr *MockInteractiveServiceServerMockRecorder) ListCollectionItems(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollectionItems", reflect.TypeOf((*MockInteractiveServiceServer)(nil).ListCollectionItems), arg0, arg1)
+}
+
+// ListCollections mocks base method.
+func (m *MockInteractiveServiceServer) ListCollections(arg0 context.Context, arg1 *intrv1.ListCollectionsRequest) (*intrv1.ListCollectionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCollections", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.ListCollectionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCollections indicates an expected call of ListCollections.
+func (mr *MockInteractiveServiceServerMockRecorder) ListCollections(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollections", reflect.TypeOf((*MockInteractiveServiceServer)(nil).ListCollections), arg0, arg1)
+}
+
+// ListLikedItems mocks base method.
+func (m *MockInteractiveServiceServer) ListLikedItems(arg0 context.Context, arg1 *intrv1.ListLikedItemsRequest) (*intrv1.ListLikedItemsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLikedItems", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.ListLikedItemsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLikedItems indicates an expected call of ListLikedItems.
+func (mr *MockInteractiveServiceServerMockRecorder) ListLikedItems(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLikedItems", reflect.TypeOf((*MockInteractiveServiceServer)(nil).ListLikedItems), arg0, arg1)
+}
+
+// MoveCollectionItem mocks base method.
+func (m *MockInteractiveServiceServer) MoveCollectionItem(arg0 context.Context, arg1 *intrv1.MoveCollectionItemRequest) (*intrv1.MoveCollectionItemResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveCollectionItem", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.MoveCollectionItemResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveCollectionItem indicates an expected call of MoveCollectionItem.
+func (mr *MockInteractiveServiceServerMockRecorder) MoveCollectionItem(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveCollectionItem", reflect.TypeOf((*MockInteractiveServiceServer)(nil).MoveCollectionItem), arg0, arg1)
+}
+
+// React mocks base method.
+func (m *MockInteractiveServiceServer) React(arg0 context.Context, arg1 *intrv1.ReactRequest) (*intrv1.ReactResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "React", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.ReactResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// React indicates an expected call of React.
+func (mr *MockInteractiveServiceServerMockRecorder) React(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "React", reflect.TypeOf((*MockInteractiveServiceServer)(nil).React), arg0, arg1)
+}
+
+// RemoveCollectionItem mocks base method.
+func (m *MockInteractiveServiceServer) RemoveCollectionItem(arg0 context.Context, arg1 *intrv1.RemoveCollectionItemRequest) (*intrv1.RemoveCollectionItemResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCollectionItem", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.RemoveCollectionItemResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveCollectionItem indicates an expected call of RemoveCollectionItem.
+func (mr *MockInteractiveServiceServerMockRecorder) RemoveCollectionItem(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCollectionItem", reflect.TypeOf((*MockInteractiveServiceServer)(nil).RemoveCollectionItem), arg0, arg1)
+}
+
+// RenameCollection mocks base method.
+func (m *MockInteractiveServiceServer) RenameCollection(arg0 context.Context, arg1 *intrv1.RenameCollectionRequest) (*intrv1.RenameCollectionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameCollection", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.RenameCollectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameCollection indicates an expected call of RenameCollection.
+func (mr *MockInteractiveServiceServerMockRecorder) RenameCollection(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameCollection", reflect.TypeOf((*MockInteractiveServiceServer)(nil).RenameCollection), arg0, arg1)
+}
+
+// Unreact mocks base method.
+func (m *MockInteractiveServiceServer) Unreact(arg0 context.Context, arg1 *intrv1.UnreactRequest) (*intrv1.UnreactResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unreact", arg0, arg1)
+	ret0, _ := ret[0].(*intrv1.UnreactResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unreact indicates an expected call of Unreact.
+func (mr *MockInteractiveServiceServerMockRecorder) Unreact(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unreact", reflect.TypeOf((*MockInteractiveServiceServer)(nil).Unreact), arg0, arg1)
+}
+
+// mustEmbedUnimplementedInteractiveServiceServer mocks base method.
+func (m *MockInteractiveServiceServer) mustEmbedUnimplementedInteractiveServiceServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedInteractiveServiceServer")
+}
+
+// mustEmbedUnimplementedInteractiveServiceServer indicates an expected call of mustEmbedUnimplementedInteractiveServiceServer.
+func (mr *MockInteractiveServiceServerMockRecorder) mustEmbedUnimplementedInteractiveServiceServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedInteractiveServiceServer", reflect.TypeOf((*MockInteractiveServiceServer)(nil).mustEmbedUnimplementedInteractiveServiceServer))
+}
+
+// MockUnsafeInteractiveServiceServer is a mock of UnsafeInteractiveServiceServer interface.
+type MockUnsafeInteractiveServiceServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnsafeInteractiveServiceServerMockRecorder
+}
+
+// MockUnsafeInteractiveServiceServerMockRecorder is the mock recorder for MockUnsafeInteractiveServiceServer.
+type MockUnsafeInteractiveServiceServerMockRecorder struct {
+	mock *MockUnsafeInteractiveServiceServer
+}
+
+// NewMockUnsafeInteractiveServiceServer creates a new mock instance.
+func NewMockUnsafeInteractiveServiceServer(ctrl *gomock.Controller) *MockUnsafeInteractiveServiceServer {
+	mock := &MockUnsafeInteractiveServiceServer{ctrl: ctrl}
+	mock.recorder = &MockUnsafeInteractiveServiceServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUnsafeInteractiveServiceServer) EXPECT() *MockUnsafeInteractiveServiceServerMockRecorder {
+	return m.recorder
+}
+
+// mustEmbedUnimplementedInteractiveServiceServer mocks base method.
+func (m *MockUnsafeInteractiveServiceServer) mustEmbedUnimplementedInteractiveServiceServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedInteractiveServiceServer")
+}
+
+// mustEmbedUnimplementedInteractiveServiceServer indicates an expected call of mustEmbedUnimplementedInteractiveServiceServer.
+func (mr *MockUnsafeInteractiveServiceServerMockRecorder) mustEmbedUnimplementedInteractiveServiceServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedInteractiveServiceServer", reflect.TypeOf((*MockUnsafeInteractiveServiceServer)(nil).mustEmbedUnimplementedInteractiveServiceServer))
+}
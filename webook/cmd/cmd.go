@@ -19,6 +19,7 @@ var (
 
 func init() {
 	rootCmd.AddCommand(command.NewWebookCommand())
+	rootCmd.AddCommand(command.NewArticleCommand())
 }
 
 func start() error {
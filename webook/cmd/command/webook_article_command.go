@@ -0,0 +1,102 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jayleonc/geektime-go/webook/cmd/wire"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+type articleFlags struct {
+	Uid    int64
+	Output string
+	Input  string
+}
+
+var artFlags = articleFlags{}
+
+// NewArticleCommand 把某个用户的文章导出成 Markdown 的 zip，或者把这样的 zip 导入成草稿
+func NewArticleCommand() *cobra.Command {
+	a := &cobra.Command{
+		Use:   "article",
+		Short: "export or import a user's articles as Markdown.",
+	}
+	a.PersistentFlags().StringVarP(&Flags.Config, "config", "c", "config/config.yaml", "config file")
+	a.PersistentFlags().Int64Var(&artFlags.Uid, "uid", 0, "author id")
+	_ = a.MarkPersistentFlagRequired("uid")
+
+	export := &cobra.Command{
+		Use:   "export",
+		Short: "export all articles of the user to a zip file.",
+		RunE:  runArticleExport,
+	}
+	export.Flags().StringVarP(&artFlags.Output, "output", "o", "articles.zip", "zip file to write")
+
+	imp := &cobra.Command{
+		Use:   "import",
+		Short: "import Markdown files in a zip file as drafts of the user.",
+		RunE:  runArticleImport,
+	}
+	imp.Flags().StringVarP(&artFlags.Input, "file", "f", "", "zip file to read")
+	_ = imp.MarkFlagRequired("file")
+
+	a.AddCommand(export, imp)
+	return a
+}
+
+func runArticleExport(cmd *cobra.Command, args []string) (err error) {
+	initConfig()
+	svc := wire.InitArticleArchiveService()
+	f, err := os.Create(artFlags.Output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if er := f.Close(); err == nil {
+			err = er
+		}
+		if err != nil {
+			// 不留下一个不完整的文件
+			_ = os.Remove(artFlags.Output)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+	err = svc.Export(ctx, artFlags.Uid, f)
+	if err != nil {
+		return err
+	}
+	fmt.Println("导出完成:", artFlags.Output)
+	return nil
+}
+
+func runArticleImport(cmd *cobra.Command, args []string) error {
+	initConfig()
+	svc := wire.InitArticleArchiveService()
+	f, err := os.Open(artFlags.Input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+	res, err := svc.Import(ctx, artFlags.Uid, f, info.Size())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("导入了 %d 篇草稿\n", len(res.Ids))
+	for _, fail := range res.Failures {
+		fmt.Printf("导入失败 %s: %s\n", fail.Name, fail.Reason)
+	}
+	if len(res.Failures) > 0 {
+		return errors.New("部分文件导入失败")
+	}
+	return nil
+}
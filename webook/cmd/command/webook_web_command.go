@@ -1,6 +1,7 @@
 package command
 
 import (
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/jayleonc/geektime-go/webook/cmd/wire"
@@ -62,6 +63,15 @@ func runApp() {
 		// 等待定时任务退出
 		<-app.Corn.Stop().Done()
 	}()
+	// 启动分布式任务调度
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		err := app.JobScheduler.Schedule(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Println("任务调度退出", err)
+		}
+	}()
 	// 启动 Web
 	server := app.Web
 	server.GET("/hello", func(ctx *gin.Context) {
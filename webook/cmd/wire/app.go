@@ -3,6 +3,7 @@ package wire
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/jayleonc/geektime-go/webook/internal/events"
+	"github.com/jayleonc/geektime-go/webook/job"
 	"github.com/jayleonc/geektime-go/webook/pkg/retry"
 	"github.com/robfig/cron/v3"
 )
@@ -12,4 +13,6 @@ type App struct {
 	Consumers []events.Consumer
 	Corn      *cron.Cron
 	Scheduler *retry.Scheduler
+	// JobScheduler 基于 MySQL 抢占的分布式任务调度
+	JobScheduler *job.Scheduler
 }
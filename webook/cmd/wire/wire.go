@@ -4,10 +4,16 @@ package wire
 
 import (
 	"github.com/google/wire"
+	ioc2 "github.com/jayleonc/geektime-go/webook/interactive/ioc"
 	repository2 "github.com/jayleonc/geektime-go/webook/interactive/repository"
 	cache2 "github.com/jayleonc/geektime-go/webook/interactive/repository/cache"
 	dao2 "github.com/jayleonc/geektime-go/webook/interactive/repository/dao"
 	service2 "github.com/jayleonc/geektime-go/webook/interactive/service"
+	"github.com/jayleonc/geektime-go/webook/internal/events/comment"
+	"github.com/jayleonc/geektime-go/webook/internal/events/feed"
+	"github.com/jayleonc/geektime-go/webook/internal/events/history"
+	"github.com/jayleonc/geektime-go/webook/internal/events/search"
+	"github.com/jayleonc/geektime-go/webook/internal/events/syndication"
 	"github.com/jayleonc/geektime-go/webook/internal/repository"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
@@ -20,13 +26,123 @@ import (
 
 var interactiveSvcSet = wire.NewSet(
 	dao2.NewGORMInteractiveDAO,
+	dao2.NewGORMCollectionDAO,
+	dao2.NewGORMReactionDAO,
+	dao2.NewGORMInteractiveCntDAO,
 	cache2.NewInteractiveRedisCache,
 	repository2.NewCachedInteractiveRepository,
+	repository2.NewCachedCollectionRepository,
+	ioc2.InitReactionConfig,
+	ioc2.InitWriteBehindConfig,
 	service2.NewInteractiveService,
 )
 
+var jobSvcSet = wire.NewSet(
+	dao.NewGORMJobDAO,
+	repository.NewPreemptJobRepository,
+	service.NewCronJobService,
+	ioc.InitScheduler,
+)
+
+var searchSvcSet = wire.NewSet(
+	service.NewArticleSearchService,
+	search.NewArticleIndexConsumer,
+	web.NewSearchHandler,
+)
+
+var commentSvcSet = wire.NewSet(
+	dao.NewCommentGORMDAO,
+	repository.NewCommentRepository,
+	comment.NewKafkaProducer,
+	service.NewCommentService,
+	web.NewCommentHandler,
+)
+
 var rankingSvcSet = wire.NewSet(cache.NewRankingRedisCache, repository.NewCachedRankingRepository, service.NewBatchRankingService)
 
+var followSvcSet = wire.NewSet(
+	dao.NewFollowGORMDAO,
+	cache.NewFollowRedisCache,
+	repository.NewCachedFollowRepository,
+	cache.NewFeedRedisCache,
+	repository.NewFeedRepository,
+	service.NewFollowService,
+	ioc.InitFeedService,
+	feed.NewArticlePublishConsumer,
+	web.NewFollowHandler,
+)
+
+// articleSvcSet 命令行工具也要用
+var articleSvcSet = wire.NewSet(
+	ioc.InitObjectStore,
+	ioc.InitArticleDAO,
+	dao.NewArticleRevisionGORMDAO,
+	repository.NewCachedArticleRepository,
+	repository.NewArticleRevisionRepository,
+	ioc.InitTagDAO,
+	repository.NewTagRepository,
+	dao.NewArticleReviewGORMDAO,
+	repository.NewArticleReviewRepository,
+	dao.NewAttachmentGORMDAO,
+	repository.NewAttachmentRepository,
+	ioc.InitSensitiveFilter,
+	service.NewArticleService,
+	service.NewArticleArchiveService,
+)
+
+var syndicationSvcSet = wire.NewSet(
+	cache.NewSyndicationRedisCache,
+	repository.NewSyndicationRepository,
+	ioc.InitSyndicationService,
+	syndication.NewArticlePublishConsumer,
+	web.NewSyndicationHandler,
+)
+
+var historySvcSet = wire.NewSet(
+	dao.NewReadHistoryGORMDAO,
+	repository.NewReadHistoryRepository,
+	service.NewReadHistoryService,
+	history.NewReadEventConsumer,
+	web.NewReadHistoryHandler,
+)
+
+var relatedSvcSet = wire.NewSet(
+	dao.NewRelatedArticleGORMDAO,
+	cache.NewRelatedArticleRedisCache,
+	repository.NewCachedRelatedArticleRepository,
+	service.NewRelatedArticleService,
+)
+
+var shareSvcSet = wire.NewSet(
+	dao.NewArticleShareGORMDAO,
+	repository.NewArticleShareRepository,
+	ioc.InitArticleShareService,
+	web.NewArticleShareHandler,
+)
+
+var seriesSvcSet = wire.NewSet(
+	dao.NewSeriesGORMDAO,
+	cache.NewSeriesRedisCache,
+	repository.NewCachedSeriesRepository,
+	service.NewSeriesService,
+	web.NewSeriesHandler,
+)
+
+var orderSvcSet = wire.NewSet(
+	dao.NewOrderGORMDAO,
+	repository.NewOrderRepository,
+	ioc.InitPaymentGateway,
+	ioc.InitOrderService,
+	ioc.InitOrderReconcileJob,
+	web.NewOrderHandler,
+)
+
+var attachmentSvcSet = wire.NewSet(
+	ioc.InitAttachmentService,
+	ioc.InitAttachmentGCJob,
+	web.NewAttachmentHandler,
+)
+
 func InitWebServer() *App {
 	wire.Build(
 		// 第三方依赖
@@ -60,6 +176,8 @@ func InitWebServer() *App {
 		rankingSvcSet,
 		ioc.InitJobs,
 		ioc.InitRankingJob,
+		ioc.InitArticlePurgeJob,
+		jobSvcSet,
 
 		// repository 部分
 		repository.NewCachedUserRepository,
@@ -74,10 +192,22 @@ func InitWebServer() *App {
 		service.NewUserService,
 		service.NewCodeService,
 
-		dao.NewArticleGORMDAO,
-		repository.NewCachedArticleRepository,
-		service.NewArticleService,
+		articleSvcSet,
 		web.NewArticleHandler,
+		web.NewCollectionHandler,
+		web.NewArticleArchiveHandler,
+		ioc.InitReviewHandler,
+		web.NewObjectHandler,
+		searchSvcSet,
+		commentSvcSet,
+		followSvcSet,
+		attachmentSvcSet,
+		syndicationSvcSet,
+		historySvcSet,
+		relatedSvcSet,
+		shareSvcSet,
+		seriesSvcSet,
+		orderSvcSet,
 
 		// handler 部分
 		ijwt.NewRedisJWTHandler,
@@ -91,6 +221,21 @@ func InitWebServer() *App {
 	return new(App)
 }
 
+// InitArticleArchiveService 命令行导出、导入文章用
+func InitArticleArchiveService() service.ArticleArchiveService {
+	wire.Build(
+		ioc.InitRedis, ioc.InitDB, ioc.InitLogger,
+		ioc.InitKafka, ioc.NewSyncProducer,
+		ioc.NewKafkaProducerWithMetricsDecorator,
+		dao.NewUserDAO,
+		cache.NewUserCache,
+		repository.NewCachedUserRepository,
+		cache.NewArticleRedisCache,
+		articleSvcSet,
+	)
+	return nil
+}
+
 var smsServiceSet = wire.NewSet(
 	async.NewSmsService,
 	ioc.InitUserSMSService,
@@ -8,10 +8,16 @@ package wire
 
 import (
 	"github.com/google/wire"
+	ioc2 "github.com/jayleonc/geektime-go/webook/interactive/ioc"
 	repository2 "github.com/jayleonc/geektime-go/webook/interactive/repository"
 	cache2 "github.com/jayleonc/geektime-go/webook/interactive/repository/cache"
 	dao2 "github.com/jayleonc/geektime-go/webook/interactive/repository/dao"
 	service2 "github.com/jayleonc/geektime-go/webook/interactive/service"
+	"github.com/jayleonc/geektime-go/webook/internal/events/comment"
+	"github.com/jayleonc/geektime-go/webook/internal/events/feed"
+	"github.com/jayleonc/geektime-go/webook/internal/events/history"
+	"github.com/jayleonc/geektime-go/webook/internal/events/search"
+	"github.com/jayleonc/geektime-go/webook/internal/events/syndication"
 	"github.com/jayleonc/geektime-go/webook/internal/repository"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
@@ -43,40 +49,163 @@ func InitWebServer() *App {
 	userHandler := web.NewUserHandler(userService, codeService, handler)
 	wechatService := ioc.InitWeChatService()
 	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, handler)
-	articleDAO := dao.NewArticleGORMDAO(db)
+	objectStore := ioc.InitObjectStore()
+	articleDAO := ioc.InitArticleDAO(db, objectStore)
 	articleCache := cache.NewArticleRedisCache(cmdable)
 	articleRepository := repository.NewCachedArticleRepository(articleDAO, articleCache, userRepository)
 	client := ioc.InitKafka()
 	syncProducer := ioc.NewSyncProducer(client)
 	producer := ioc.NewKafkaProducerWithMetricsDecorator(syncProducer)
-	articleService := service.NewArticleService(articleRepository, producer)
+	articleRevisionDAO := dao.NewArticleRevisionGORMDAO(db)
+	articleRevisionRepository := repository.NewArticleRevisionRepository(articleRevisionDAO)
+	tagDAO := ioc.InitTagDAO(db, articleDAO)
+	tagRepository := repository.NewTagRepository(tagDAO)
+	articleReviewDAO := dao.NewArticleReviewGORMDAO(db)
+	articleReviewRepository := repository.NewArticleReviewRepository(articleReviewDAO)
+	attachmentDAO := dao.NewAttachmentGORMDAO(db)
+	attachmentRepository := repository.NewAttachmentRepository(attachmentDAO, objectStore)
+	filter := ioc.InitSensitiveFilter(logger)
+	articleService := service.NewArticleService(articleRepository, articleRevisionRepository, tagRepository, articleReviewRepository, attachmentRepository, filter, producer, logger)
 	clientv3Client := ioc.InitEtcd()
 	interactiveServiceClient := ioc.NewIntrClientV1(clientv3Client)
-	articleHandler := web.NewArticleHandler(logger, articleService, interactiveServiceClient)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler)
-	v2 := ioc.RegisterConsumers()
+	relatedArticleDAO := dao.NewRelatedArticleGORMDAO(db)
+	relatedArticleCache := cache.NewRelatedArticleRedisCache(cmdable)
+	relatedArticleRepository := repository.NewCachedRelatedArticleRepository(relatedArticleDAO, relatedArticleCache, logger)
+	readHistoryDAO := dao.NewReadHistoryGORMDAO(db)
+	readHistoryRepository := repository.NewReadHistoryRepository(readHistoryDAO)
+	relatedArticleService := service.NewRelatedArticleService(relatedArticleRepository, articleRepository, readHistoryRepository, logger)
+	articleShareDAO := dao.NewArticleShareGORMDAO(db)
+	articleShareRepository := repository.NewArticleShareRepository(articleShareDAO)
+	articleShareService := ioc.InitArticleShareService(articleShareRepository, articleRepository, logger)
+	seriesDAO := dao.NewSeriesGORMDAO(db)
+	seriesCache := cache.NewSeriesRedisCache(cmdable)
+	seriesRepository := repository.NewCachedSeriesRepository(seriesDAO, seriesCache, logger)
+	seriesService := service.NewSeriesService(seriesRepository, articleRepository, interactiveServiceClient, logger)
+	orderDAO := dao.NewOrderGORMDAO(db)
+	orderRepository := repository.NewOrderRepository(orderDAO)
+	gateway := ioc.InitPaymentGateway(logger)
+	orderService := ioc.InitOrderService(orderRepository, articleRepository, gateway, logger)
 	rankingCache := cache.NewRankingRedisCache(cmdable)
 	rankingRepository := repository.NewCachedRankingRepository(rankingCache)
 	rankingService := service.NewBatchRankingService(interactiveServiceClient, articleService, rankingRepository)
+	articleHandler := web.NewArticleHandler(logger, articleService, interactiveServiceClient, relatedArticleService, articleShareService, seriesService, orderService, rankingService)
+	searchService := service.NewArticleSearchService(articleRepository, tagRepository, logger)
+	searchHandler := web.NewSearchHandler(searchService, logger)
+	commentDAO := dao.NewCommentGORMDAO(db)
+	commentRepository := repository.NewCommentRepository(commentDAO)
+	commentProducer := comment.NewKafkaProducer(syncProducer)
+	commentService := service.NewCommentService(commentRepository, articleRepository, commentProducer, logger)
+	commentHandler := web.NewCommentHandler(commentService, interactiveServiceClient, logger)
+	objectHandler := web.NewObjectHandler(objectStore, logger)
+	reviewHandler := ioc.InitReviewHandler(articleService, commentService, logger)
+	followDAO := dao.NewFollowGORMDAO(db)
+	followCache := cache.NewFollowRedisCache(cmdable)
+	followRepository := repository.NewCachedFollowRepository(followDAO, followCache)
+	feedCache := cache.NewFeedRedisCache(cmdable)
+	feedRepository := repository.NewFeedRepository(feedCache)
+	followService := service.NewFollowService(followRepository, userRepository, feedRepository, logger)
+	feedService := ioc.InitFeedService(feedRepository, followRepository, articleRepository, logger)
+	followHandler := web.NewFollowHandler(followService, feedService, interactiveServiceClient, logger)
+	attachmentService := ioc.InitAttachmentService(attachmentRepository, logger)
+	attachmentHandler := web.NewAttachmentHandler(attachmentService, logger)
+	articleArchiveService := service.NewArticleArchiveService(articleRepository, tagRepository, articleService, logger)
+	articleArchiveHandler := web.NewArticleArchiveHandler(articleArchiveService, logger)
+	syndicationCache := cache.NewSyndicationRedisCache(cmdable)
+	syndicationRepository := repository.NewSyndicationRepository(syndicationCache)
+	syndicationService := ioc.InitSyndicationService(syndicationRepository, articleRepository, rankingService, logger)
+	syndicationHandler := web.NewSyndicationHandler(syndicationService, logger)
+	readHistoryService := service.NewReadHistoryService(readHistoryRepository, articleRepository)
+	readHistoryHandler := web.NewReadHistoryHandler(readHistoryService, logger)
+	articleShareHandler := web.NewArticleShareHandler(articleShareService, logger)
+	seriesHandler := web.NewSeriesHandler(seriesService, logger)
+	orderHandler := web.NewOrderHandler(orderService, gateway, logger)
+	collectionHandler := web.NewCollectionHandler(interactiveServiceClient, articleService, logger)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, searchHandler, commentHandler, objectHandler, reviewHandler, followHandler, attachmentHandler, articleArchiveHandler, syndicationHandler, readHistoryHandler, articleShareHandler, seriesHandler, orderHandler, collectionHandler)
+	articleIndexConsumer := search.NewArticleIndexConsumer(searchService, client, logger)
+	articlePublishConsumer := feed.NewArticlePublishConsumer(feedService, client, logger)
+	syndicationArticlePublishConsumer := syndication.NewArticlePublishConsumer(syndicationService, client, logger)
+	readEventConsumer := history.NewReadEventConsumer(readHistoryService, client, logger)
+	v2 := ioc.RegisterConsumers(articleIndexConsumer, articlePublishConsumer, syndicationArticlePublishConsumer, readEventConsumer)
 	rlockClient := ioc.InitRLockClient(cmdable)
 	rankingJob := ioc.InitRankingJob(rankingService, logger, rlockClient)
-	cron := ioc.InitJobs(logger, rankingJob)
+	articlePurgeJob := ioc.InitArticlePurgeJob(articleService)
+	attachmentGCJob := ioc.InitAttachmentGCJob(attachmentService)
+	orderReconcileJob := ioc.InitOrderReconcileJob(orderService)
+	cron := ioc.InitJobs(logger, rankingJob, articlePurgeJob, attachmentGCJob, orderReconcileJob)
 	asyncSmsService := async.NewSmsService(smsService, asyncTaskRepository, logger)
 	demo := service.NewDemo()
 	scheduler := ioc.InitTask(asyncSmsService, demo)
+	jobDAO := dao.NewGORMJobDAO(db)
+	cronJobRepository := repository.NewPreemptJobRepository(jobDAO)
+	cronJobService := service.NewCronJobService(cronJobRepository, logger)
+	jobScheduler := ioc.InitScheduler(logger, cronJobService, articleService, relatedArticleService)
 	app := &App{
-		Web:       engine,
-		Consumers: v2,
-		Corn:      cron,
-		Scheduler: scheduler,
+		Web:          engine,
+		Consumers:    v2,
+		Corn:         cron,
+		Scheduler:    scheduler,
+		JobScheduler: jobScheduler,
 	}
 	return app
 }
 
+func InitArticleArchiveService() service.ArticleArchiveService {
+	cmdable := ioc.InitRedis()
+	logger := ioc.InitLogger()
+	db := ioc.InitDB(logger)
+	objectStore := ioc.InitObjectStore()
+	articleDAO := ioc.InitArticleDAO(db, objectStore)
+	articleCache := cache.NewArticleRedisCache(cmdable)
+	userDAO := dao.NewUserDAO(db)
+	userCache := cache.NewUserCache(cmdable)
+	userRepository := repository.NewCachedUserRepository(userDAO, userCache)
+	articleRepository := repository.NewCachedArticleRepository(articleDAO, articleCache, userRepository)
+	tagDAO := ioc.InitTagDAO(db, articleDAO)
+	tagRepository := repository.NewTagRepository(tagDAO)
+	articleRevisionDAO := dao.NewArticleRevisionGORMDAO(db)
+	articleRevisionRepository := repository.NewArticleRevisionRepository(articleRevisionDAO)
+	articleReviewDAO := dao.NewArticleReviewGORMDAO(db)
+	articleReviewRepository := repository.NewArticleReviewRepository(articleReviewDAO)
+	attachmentDAO := dao.NewAttachmentGORMDAO(db)
+	attachmentRepository := repository.NewAttachmentRepository(attachmentDAO, objectStore)
+	filter := ioc.InitSensitiveFilter(logger)
+	client := ioc.InitKafka()
+	syncProducer := ioc.NewSyncProducer(client)
+	producer := ioc.NewKafkaProducerWithMetricsDecorator(syncProducer)
+	articleService := service.NewArticleService(articleRepository, articleRevisionRepository, tagRepository, articleReviewRepository, attachmentRepository, filter, producer, logger)
+	articleArchiveService := service.NewArticleArchiveService(articleRepository, tagRepository, articleService, logger)
+	return articleArchiveService
+}
+
 // wire.go:
 
-var interactiveSvcSet = wire.NewSet(dao2.NewGORMInteractiveDAO, cache2.NewInteractiveRedisCache, repository2.NewCachedInteractiveRepository, service2.NewInteractiveService)
+var interactiveSvcSet = wire.NewSet(dao2.NewGORMInteractiveDAO, dao2.NewGORMCollectionDAO, dao2.NewGORMReactionDAO, dao2.NewGORMInteractiveCntDAO, cache2.NewInteractiveRedisCache, repository2.NewCachedInteractiveRepository, repository2.NewCachedCollectionRepository, ioc2.InitReactionConfig, ioc2.InitWriteBehindConfig, service2.NewInteractiveService)
+
+var jobSvcSet = wire.NewSet(dao.NewGORMJobDAO, repository.NewPreemptJobRepository, service.NewCronJobService, ioc.InitScheduler)
+
+var searchSvcSet = wire.NewSet(service.NewArticleSearchService, search.NewArticleIndexConsumer, web.NewSearchHandler)
+
+var commentSvcSet = wire.NewSet(dao.NewCommentGORMDAO, repository.NewCommentRepository, comment.NewKafkaProducer, service.NewCommentService, web.NewCommentHandler)
 
 var rankingSvcSet = wire.NewSet(cache.NewRankingRedisCache, repository.NewCachedRankingRepository, service.NewBatchRankingService)
 
+var followSvcSet = wire.NewSet(dao.NewFollowGORMDAO, cache.NewFollowRedisCache, repository.NewCachedFollowRepository, cache.NewFeedRedisCache, repository.NewFeedRepository, service.NewFollowService, ioc.InitFeedService, feed.NewArticlePublishConsumer, web.NewFollowHandler)
+
+// articleSvcSet 命令行工具也要用
+var articleSvcSet = wire.NewSet(ioc.InitObjectStore, ioc.InitArticleDAO, dao.NewArticleRevisionGORMDAO, repository.NewCachedArticleRepository, repository.NewArticleRevisionRepository, ioc.InitTagDAO, repository.NewTagRepository, dao.NewArticleReviewGORMDAO, repository.NewArticleReviewRepository, dao.NewAttachmentGORMDAO, repository.NewAttachmentRepository, ioc.InitSensitiveFilter, service.NewArticleService, service.NewArticleArchiveService)
+
+var syndicationSvcSet = wire.NewSet(cache.NewSyndicationRedisCache, repository.NewSyndicationRepository, ioc.InitSyndicationService, syndication.NewArticlePublishConsumer, web.NewSyndicationHandler)
+
+var historySvcSet = wire.NewSet(dao.NewReadHistoryGORMDAO, repository.NewReadHistoryRepository, service.NewReadHistoryService, history.NewReadEventConsumer, web.NewReadHistoryHandler)
+
+var relatedSvcSet = wire.NewSet(dao.NewRelatedArticleGORMDAO, cache.NewRelatedArticleRedisCache, repository.NewCachedRelatedArticleRepository, service.NewRelatedArticleService)
+
+var shareSvcSet = wire.NewSet(dao.NewArticleShareGORMDAO, repository.NewArticleShareRepository, ioc.InitArticleShareService, web.NewArticleShareHandler)
+
+var seriesSvcSet = wire.NewSet(dao.NewSeriesGORMDAO, cache.NewSeriesRedisCache, repository.NewCachedSeriesRepository, service.NewSeriesService, web.NewSeriesHandler)
+
+var orderSvcSet = wire.NewSet(dao.NewOrderGORMDAO, repository.NewOrderRepository, ioc.InitPaymentGateway, ioc.InitOrderService, ioc.InitOrderReconcileJob, web.NewOrderHandler)
+
+var attachmentSvcSet = wire.NewSet(ioc.InitAttachmentService, ioc.InitAttachmentGCJob, web.NewAttachmentHandler)
+
 var smsServiceSet = wire.NewSet(async.NewSmsService, ioc.InitUserSMSService)
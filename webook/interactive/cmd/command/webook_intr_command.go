@@ -47,6 +47,13 @@ func runApp() {
 		}
 	}
 
+	// 启动定时任务
+	app.Cron.Start()
+	defer func() {
+		// 等待定时任务退出
+		<-app.Cron.Stop().Done()
+	}()
+
 	go func() {
 		err1 := app.AdminServer.Start()
 		panic(err1)
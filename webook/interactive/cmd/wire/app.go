@@ -4,10 +4,13 @@ import (
 	"github.com/jayleonc/geektime-go/webook/internal/events"
 	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
 	"github.com/jayleonc/geektime-go/webook/pkg/grpcx"
+	"github.com/robfig/cron/v3"
 )
 
 type App struct {
 	Consumers   []events.Consumer
 	Server      *grpcx.Server
 	AdminServer *ginx.Server
+	// Cron write-behind 的计数落库和点赞数对账
+	Cron *cron.Cron
 }
@@ -27,8 +27,14 @@ var thirdPartySet = wire.NewSet(
 
 var interactiveSvcSet = wire.NewSet(
 	dao.NewGORMInteractiveDAO,
+	dao.NewGORMCollectionDAO,
+	dao.NewGORMReactionDAO,
+	dao.NewGORMInteractiveCntDAO,
 	cache.NewInteractiveRedisCache,
 	repository.NewCachedInteractiveRepository,
+	repository.NewCachedCollectionRepository,
+	ioc.InitReactionConfig,
+	ioc.InitWriteBehindConfig,
 	service.NewInteractiveService,
 )
 
@@ -42,9 +48,16 @@ func InitApp() *App {
 		ioc.InitInteractiveProducer,
 		ioc.InitFixerConsumer,
 		ioc.InitGinxServer,
+		ioc.InitCntFlushJob,
+		ioc.InitLikeCntReconcileJob,
+		ioc.InitFlushLogCleanJob,
+		ioc.InitRLockClient,
+		ioc.InitJobs,
 
+		ioc.InitReadDedupeConfig,
 		events.NewInteractiveReadEventConsumer,
 		prometheus.NewInteractiveReadEventConsumerWithMetrics,
+		events.NewInteractiveCommentEventConsumer,
 		wire.Struct(new(App), "*"),
 	)
 
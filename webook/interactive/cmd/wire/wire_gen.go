@@ -29,22 +29,36 @@ func InitApp() *App {
 	interactiveDAO := dao.NewGORMInteractiveDAO(db)
 	cmdable := ioc.InitRedis()
 	interactiveCache := cache.NewInteractiveRedisCache(cmdable)
-	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache)
+	writeBehindConfig := ioc.InitWriteBehindConfig()
+	reactionDAO := dao.NewGORMReactionDAO(db, writeBehindConfig)
+	interactiveCntDAO := dao.NewGORMInteractiveCntDAO(db)
+	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, reactionDAO, interactiveCntDAO, interactiveCache, writeBehindConfig)
 	client := ioc.InitKafka()
-	interactiveReadEventConsumer := events.NewInteractiveReadEventConsumer(interactiveRepository, client)
+	readDedupeConfig := ioc.InitReadDedupeConfig()
+	interactiveReadEventConsumer := events.NewInteractiveReadEventConsumer(interactiveRepository, client, readDedupeConfig)
 	interactiveReadEventConsumerWithMetrics := prometheus.NewInteractiveReadEventConsumerWithMetrics(interactiveReadEventConsumer)
 	consumer := ioc.InitFixerConsumer(client, logger, srcDB, dstDB)
-	v := ioc.InitConsumers(interactiveReadEventConsumerWithMetrics, consumer)
-	interactiveService := service.NewInteractiveService(interactiveRepository)
+	interactiveCommentEventConsumer := events.NewInteractiveCommentEventConsumer(interactiveRepository, client)
+	v := ioc.InitConsumers(interactiveReadEventConsumerWithMetrics, consumer, interactiveCommentEventConsumer)
+	collectionDAO := dao.NewGORMCollectionDAO(db)
+	collectionRepository := repository.NewCachedCollectionRepository(collectionDAO, interactiveCache)
+	reactionConfig := ioc.InitReactionConfig()
+	interactiveService := service.NewInteractiveService(interactiveRepository, collectionRepository, reactionConfig)
 	interactiveServiceServer := grpc.NewInteractiveServiceServer(interactiveService)
 	server := ioc.NewGrpcxServer(interactiveServiceServer, logger)
 	syncProducer := ioc.NewSyncProducer(client)
 	producer := ioc.InitInteractiveProducer(syncProducer)
 	ginxServer := ioc.InitGinxServer(logger, srcDB, dstDB, doubleWritePool, producer)
+	cntFlushJob := ioc.InitCntFlushJob(interactiveService)
+	rlockClient := ioc.InitRLockClient(cmdable)
+	likeCntReconcileJob := ioc.InitLikeCntReconcileJob(interactiveService, logger, rlockClient)
+	flushLogCleanJob := ioc.InitFlushLogCleanJob(interactiveService)
+	cron := ioc.InitJobs(logger, writeBehindConfig, cntFlushJob, likeCntReconcileJob, flushLogCleanJob)
 	app := &App{
 		Consumers:   v,
 		Server:      server,
 		AdminServer: ginxServer,
+		Cron:        cron,
 	}
 	return app
 }
@@ -53,4 +67,4 @@ func InitApp() *App {
 
 var thirdPartySet = wire.NewSet(ioc.InitSrcDB, ioc.InitDstDB, ioc.InitDoubleWritePool, ioc.InitBizDB, ioc.InitLogger, ioc.InitKafka, ioc.NewSyncProducer, ioc.InitRedis)
 
-var interactiveSvcSet = wire.NewSet(dao.NewGORMInteractiveDAO, cache.NewInteractiveRedisCache, repository.NewCachedInteractiveRepository, service.NewInteractiveService)
+var interactiveSvcSet = wire.NewSet(dao.NewGORMInteractiveDAO, dao.NewGORMCollectionDAO, dao.NewGORMReactionDAO, dao.NewGORMInteractiveCntDAO, cache.NewInteractiveRedisCache, repository.NewCachedInteractiveRepository, repository.NewCachedCollectionRepository, ioc.InitReactionConfig, ioc.InitWriteBehindConfig, service.NewInteractiveService)
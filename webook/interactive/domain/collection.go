@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// Collection 收藏夹。Id 为 0 的是每个用户都有的默认收藏夹，不存在数据库里
+type Collection struct {
+	Id   int64
+	Uid  int64
+	Name string
+	// ItemCnt 收藏夹里面有多少个收藏
+	ItemCnt int64
+	Ctime   time.Time
+	Utime   time.Time
+}
+
+// CollectionItem 收藏夹里面的一个收藏
+type CollectionItem struct {
+	Cid   int64
+	Biz   string
+	BizId int64
+	Ctime time.Time
+}
@@ -1,11 +1,33 @@
 package domain
 
+import "time"
+
 type Interactive struct {
 	BizId      int64
 	ReadCnt    int64
 	LikeCnt    int64
 	CollectCnt int64
+	CommentCnt int64
+	// UniqueReadCnt 去重之后的读者数，估算值
+	UniqueReadCnt int64
+	// Reactions 每个表情的数量，点赞的数量和 LikeCnt 一样
+	Reactions map[string]int64
 
 	Liked     bool
 	Collected bool
+	// Reaction 用户自己用的表情，没有的时候是空字符串
+	Reaction string
+}
+
+// UserStatus 用户对一个资源有没有点赞、收藏
+type UserStatus struct {
+	Liked     bool
+	Collected bool
+}
+
+// LikeItem 用户点过赞的一个资源，Ctime 是点赞的时间
+type LikeItem struct {
+	Biz   string
+	BizId int64
+	Ctime time.Time
 }
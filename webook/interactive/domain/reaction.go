@@ -0,0 +1,21 @@
+package domain
+
+// ReactionLike 点赞也是一种表情。为了兼容，点赞还是记在原来的点赞表里面，点赞数就是 Interactive.LikeCnt
+const ReactionLike = "like"
+
+// ReactionConfig 每个业务可以用哪些表情，key 是 biz。
+// 表情用英文名字，比如 like、love、laugh，前端自己换成 👍❤️😂
+type ReactionConfig map[string][]string
+
+// Allowed 点赞在所有业务里面都可以用
+func (c ReactionConfig) Allowed(biz, reaction string) bool {
+	if reaction == ReactionLike {
+		return true
+	}
+	for _, r := range c[biz] {
+		if r == reaction {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// WriteBehindConfig 打开之后，阅读数、点赞数和评论数先在 Redis 里面攒着，
+// 每隔 Interval 合并成一次写到数据库。数据库里面的计数最多落后一个 Interval
+type WriteBehindConfig struct {
+	Enabled  bool
+	Interval time.Duration
+}
+
+// InteractiveDelta 还没有写到数据库的计数变化
+type InteractiveDelta struct {
+	Biz        string
+	BizId      int64
+	ReadCnt    int64
+	LikeCnt    int64
+	CommentCnt int64
+}
+
+// PendingDeltas 还没有写到数据库的计数，Pending 和 Flushing 都和查询的 bizIds 一一对应。
+// Flushing 是 Batch 正在写数据库的部分，可能已经写进去了，只是还没有确认
+type PendingDeltas struct {
+	// Epoch 每拿走一个新的 batch 就加一，前后两次不一样说明中间换过 batch
+	Epoch    int64
+	Batch    string
+	Pending  []InteractiveDelta
+	Flushing []InteractiveDelta
+}
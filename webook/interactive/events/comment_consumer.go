@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/jayleonc/geektime-go/webook/interactive/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/saramax"
+	"time"
+)
+
+const CommentCntEventTopic = "comment_cnt"
+
+// CommentCntEvent 评论数变化，Delta 可以是负数
+type CommentCntEvent struct {
+	Biz   string
+	BizId int64
+	Delta int64
+}
+
+type InteractiveCommentEventConsumer struct {
+	repo   repository.InteractiveRepository
+	client sarama.Client
+}
+
+func NewInteractiveCommentEventConsumer(repo repository.InteractiveRepository, client sarama.Client) *InteractiveCommentEventConsumer {
+	return &InteractiveCommentEventConsumer{repo: repo, client: client}
+}
+
+func (c *InteractiveCommentEventConsumer) Start() error {
+	cgroup, err := sarama.NewConsumerGroupFromClient("interactive", c.client)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		er := cgroup.Consume(context.Background(), []string{CommentCntEventTopic}, saramax.NewHandler[CommentCntEvent](c.Consume))
+		if er != nil {
+			fmt.Println("退出消费循环", er)
+		}
+	}()
+
+	return err
+}
+
+func (c *InteractiveCommentEventConsumer) Consume(msg *sarama.ConsumerMessage, t CommentCntEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.repo.IncrCommentCnt(ctx, t.Biz, t.BizId, t.Delta)
+}
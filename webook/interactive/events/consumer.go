@@ -16,13 +16,21 @@ type ReadEvent struct {
 	Aid int64
 }
 
+// ReadDedupeConfig 同一个用户在 Window 里面重复阅读同一篇文章，阅读数只加一次。
+// Window 为 0 的时候不去重
+type ReadDedupeConfig struct {
+	Window time.Duration
+}
+
 type InteractiveReadEventConsumer struct {
 	repo   repository.InteractiveRepository
 	client sarama.Client
+	cfg    ReadDedupeConfig
 }
 
-func NewInteractiveReadEventConsumer(repo repository.InteractiveRepository, client sarama.Client) *InteractiveReadEventConsumer {
-	return &InteractiveReadEventConsumer{repo: repo, client: client}
+func NewInteractiveReadEventConsumer(repo repository.InteractiveRepository, client sarama.Client,
+	cfg ReadDedupeConfig) *InteractiveReadEventConsumer {
+	return &InteractiveReadEventConsumer{repo: repo, client: client, cfg: cfg}
 }
 
 // Start 负责初始化 Kafka 消费者组并开始消费消息
@@ -60,24 +68,61 @@ func (r *InteractiveReadEventConsumer) Start() error {
 }
 
 func (r *InteractiveReadEventConsumer) BatchConsume(msgs []*sarama.ConsumerMessage, t []ReadEvent) error {
-	bizs := make([]string, len(t))
-	bizIds := make([]int64, len(t))
-	for _, event := range t {
-		bizs = append(bizs, "article")
-		bizIds = append(bizIds, event.Aid)
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
+	counted := r.dedupe(ctx, t)
+	bizs := make([]string, 0, len(t))
+	bizIds := make([]int64, 0, len(t))
+	for idx, event := range t {
+		if counted[idx] {
+			bizs = append(bizs, "article")
+			bizIds = append(bizIds, event.Aid)
+		}
+	}
+	if len(bizIds) == 0 {
+		return nil
+	}
 	return r.repo.BatchIncrReadCnt(ctx, bizs, bizIds)
 }
 
 func (r *InteractiveReadEventConsumer) Consume(msg *sarama.ConsumerMessage, t ReadEvent) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
+	if !r.dedupe(ctx, []ReadEvent{t})[0] {
+		return nil
+	}
 	return r.repo.IncrReadCnt(ctx, "article", t.Aid)
 }
 
+// dedupe 返回每一个事件要不要加阅读数。
+// 没登录的读者没法去重，都要算；Redis 出问题的时候宁可多算，也不要丢
+func (r *InteractiveReadEventConsumer) dedupe(ctx context.Context, evts []ReadEvent) []bool {
+	res := make([]bool, len(evts))
+	bizIds := make([]int64, 0, len(evts))
+	uids := make([]int64, 0, len(evts))
+	idxs := make([]int, 0, len(evts))
+	for idx, event := range evts {
+		if event.Uid <= 0 {
+			res[idx] = true
+			continue
+		}
+		bizIds = append(bizIds, event.Aid)
+		uids = append(uids, event.Uid)
+		idxs = append(idxs, idx)
+	}
+	if len(idxs) == 0 {
+		return res
+	}
+	counted, err := r.repo.AddReaders(ctx, "article", bizIds, uids, r.cfg.Window)
+	if err != nil {
+		fmt.Println("阅读去重失败", err)
+	}
+	for i, idx := range idxs {
+		res[idx] = err != nil || counted[i]
+	}
+	return res
+}
+
 func (r *InteractiveReadEventConsumer) GetClient() sarama.Client {
 	return r.client
 }
@@ -2,10 +2,14 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
 	"github.com/jayleonc/geektime-go/webook/interactive/domain"
 	"github.com/jayleonc/geektime-go/webook/interactive/service"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type InteractiveServiceServer struct {
@@ -38,7 +42,136 @@ func (i *InteractiveServiceServer) CancelLike(ctx context.Context, request *intr
 
 func (i *InteractiveServiceServer) Collect(ctx context.Context, request *intrv1.CollectRequest) (*intrv1.CollectResponse, error) {
 	err := i.svc.Collect(ctx, request.GetBiz(), request.GetBizId(), request.GetCid(), request.GetUid())
-	return &intrv1.CollectResponse{}, err
+	return &intrv1.CollectResponse{}, i.toStatusErr(err)
+}
+
+func (i *InteractiveServiceServer) CreateCollection(ctx context.Context, request *intrv1.CreateCollectionRequest) (*intrv1.CreateCollectionResponse, error) {
+	cid, err := i.svc.CreateCollection(ctx, request.GetUid(), request.GetName())
+	return &intrv1.CreateCollectionResponse{Cid: cid}, err
+}
+
+func (i *InteractiveServiceServer) RenameCollection(ctx context.Context, request *intrv1.RenameCollectionRequest) (*intrv1.RenameCollectionResponse, error) {
+	err := i.svc.RenameCollection(ctx, request.GetUid(), request.GetCid(), request.GetName())
+	return &intrv1.RenameCollectionResponse{}, i.toStatusErr(err)
+}
+
+func (i *InteractiveServiceServer) DeleteCollection(ctx context.Context, request *intrv1.DeleteCollectionRequest) (*intrv1.DeleteCollectionResponse, error) {
+	err := i.svc.DeleteCollection(ctx, request.GetUid(), request.GetCid())
+	return &intrv1.DeleteCollectionResponse{}, i.toStatusErr(err)
+}
+
+func (i *InteractiveServiceServer) ListCollections(ctx context.Context, request *intrv1.ListCollectionsRequest) (*intrv1.ListCollectionsResponse, error) {
+	cols, total, err := i.svc.ListCollections(ctx, request.GetUid(), int(request.GetOffset()), int(request.GetLimit()))
+	if err != nil {
+		return nil, err
+	}
+	return &intrv1.ListCollectionsResponse{
+		Collections: slice.Map(cols, func(idx int, src domain.Collection) *intrv1.Collection {
+			return &intrv1.Collection{
+				Id:      src.Id,
+				Uid:     src.Uid,
+				Name:    src.Name,
+				ItemCnt: src.ItemCnt,
+				Ctime:   src.Ctime.UnixMilli(),
+				Utime:   src.Utime.UnixMilli(),
+			}
+		}),
+		Total: total,
+	}, nil
+}
+
+func (i *InteractiveServiceServer) ListCollectionItems(ctx context.Context, request *intrv1.ListCollectionItemsRequest) (*intrv1.ListCollectionItemsResponse, error) {
+	items, total, err := i.svc.ListCollectionItems(ctx, request.GetUid(), request.GetCid(),
+		int(request.GetOffset()), int(request.GetLimit()))
+	if err != nil {
+		return nil, i.toStatusErr(err)
+	}
+	return &intrv1.ListCollectionItemsResponse{
+		Items: slice.Map(items, i.toCollectionItemDTO),
+		Total: total,
+	}, nil
+}
+
+func (i *InteractiveServiceServer) RemoveCollectionItem(ctx context.Context, request *intrv1.RemoveCollectionItemRequest) (*intrv1.RemoveCollectionItemResponse, error) {
+	err := i.svc.RemoveCollectionItem(ctx, request.GetBiz(), request.GetBizId(), request.GetUid())
+	return &intrv1.RemoveCollectionItemResponse{}, err
+}
+
+func (i *InteractiveServiceServer) MoveCollectionItem(ctx context.Context, request *intrv1.MoveCollectionItemRequest) (*intrv1.MoveCollectionItemResponse, error) {
+	err := i.svc.MoveCollectionItem(ctx, request.GetBiz(), request.GetBizId(), request.GetCid(), request.GetUid())
+	return &intrv1.MoveCollectionItemResponse{}, i.toStatusErr(err)
+}
+
+func (i *InteractiveServiceServer) GetUserStatus(ctx context.Context, request *intrv1.GetUserStatusRequest) (*intrv1.GetUserStatusResponse, error) {
+	res, err := i.svc.GetUserStatus(ctx, request.GetBiz(), request.GetIds(), request.GetUid())
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[int64]*intrv1.UserStatus, len(res))
+	for id, st := range res {
+		statuses[id] = &intrv1.UserStatus{Liked: st.Liked, Collected: st.Collected}
+	}
+	return &intrv1.GetUserStatusResponse{Statuses: statuses}, nil
+}
+
+func (i *InteractiveServiceServer) ListLikedItems(ctx context.Context, request *intrv1.ListLikedItemsRequest) (*intrv1.ListLikedItemsResponse, error) {
+	items, total, err := i.svc.ListLikedItems(ctx, request.GetBiz(), request.GetUid(),
+		int(request.GetOffset()), int(request.GetLimit()))
+	if err != nil {
+		return nil, err
+	}
+	return &intrv1.ListLikedItemsResponse{
+		Items: slice.Map(items, func(idx int, src domain.LikeItem) *intrv1.LikeItem {
+			return &intrv1.LikeItem{
+				Biz:   src.Biz,
+				BizId: src.BizId,
+				Ctime: src.Ctime.UnixMilli(),
+			}
+		}),
+		Total: total,
+	}, nil
+}
+
+func (i *InteractiveServiceServer) ListCollectedItems(ctx context.Context, request *intrv1.ListCollectedItemsRequest) (*intrv1.ListCollectedItemsResponse, error) {
+	items, total, err := i.svc.ListCollectedItems(ctx, request.GetBiz(), request.GetUid(),
+		int(request.GetOffset()), int(request.GetLimit()))
+	if err != nil {
+		return nil, err
+	}
+	return &intrv1.ListCollectedItemsResponse{
+		Items: slice.Map(items, i.toCollectionItemDTO),
+		Total: total,
+	}, nil
+}
+
+func (i *InteractiveServiceServer) toCollectionItemDTO(idx int, src domain.CollectionItem) *intrv1.CollectionItem {
+	return &intrv1.CollectionItem{
+		Cid:   src.Cid,
+		Biz:   src.Biz,
+		BizId: src.BizId,
+		Ctime: src.Ctime.UnixMilli(),
+	}
+}
+
+// toStatusErr 收藏夹不存在转成 NotFound，调用方用 status.Code 判断
+func (i *InteractiveServiceServer) toStatusErr(err error) error {
+	switch {
+	case errors.Is(err, service.ErrCollectionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidReaction):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
+func (i *InteractiveServiceServer) React(ctx context.Context, request *intrv1.ReactRequest) (*intrv1.ReactResponse, error) {
+	err := i.svc.React(ctx, request.GetBiz(), request.GetBizId(), request.GetUid(), request.GetReaction())
+	return &intrv1.ReactResponse{}, i.toStatusErr(err)
+}
+
+func (i *InteractiveServiceServer) Unreact(ctx context.Context, request *intrv1.UnreactRequest) (*intrv1.UnreactResponse, error) {
+	err := i.svc.Unreact(ctx, request.GetBiz(), request.GetBizId(), request.GetUid())
+	return &intrv1.UnreactResponse{}, err
 }
 
 func (i *InteractiveServiceServer) Get(ctx context.Context, request *intrv1.GetRequest) (*intrv1.GetResponse, error) {
@@ -86,8 +219,13 @@ func (i *InteractiveServiceServer) toDTO(intr domain.Interactive) *intrv1.Intera
 		ReadCnt:    intr.ReadCnt,
 		LikeCnt:    intr.LikeCnt,
 		CollectCnt: intr.CollectCnt,
+		CommentCnt: intr.CommentCnt,
 		Liked:      intr.Liked,
 		Collected:  intr.Collected,
+		Reactions:  intr.Reactions,
+		Reaction:   intr.Reaction,
+
+		UniqueReadCnt: intr.UniqueReadCnt,
 	}
 }
 
@@ -1,13 +1,18 @@
 package integration
 
 import (
+	"github.com/IBM/sarama"
+	"github.com/ecodeclub/ekit/slice"
 	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
+	"github.com/jayleonc/geektime-go/webook/interactive/events"
 	"github.com/jayleonc/geektime-go/webook/interactive/integration/startup"
 	"github.com/jayleonc/geektime-go/webook/interactive/repository/dao"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
 	"testing"
 	"time"
@@ -33,6 +38,14 @@ func (s *InteractiveTestSuite) TearDownTest() {
 	assert.NoError(s.T(), err)
 	err = s.db.Exec("TRUNCATE TABLE `user_collection_bizs`").Error
 	assert.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `collections`").Error
+	assert.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `user_reaction_bizs`").Error
+	assert.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `interactive_reactions`").Error
+	assert.NoError(s.T(), err)
+	err = s.db.Exec("TRUNCATE TABLE `interactive_flush_logs`").Error
+	assert.NoError(s.T(), err)
 	// 清空 Redis
 	err = s.rdb.FlushDB(ctx).Err()
 	assert.NoError(s.T(), err)
@@ -620,8 +633,26 @@ func (s *InteractiveTestSuite) TestCollect() {
 			uid:      1,
 			wantResp: &intrv1.CollectResponse{},
 		},
+		{
+			name:   "收藏夹不是自己的",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				var cnt int64
+				err := s.db.Model(&dao.UserCollectionBiz{}).Where("uid = ?", 2).Count(&cnt).Error
+				assert.NoError(t, err)
+				assert.Equal(t, int64(0), cnt)
+			},
+			bizId:   4,
+			biz:     "test",
+			cid:     1,
+			uid:     2,
+			wantErr: status.Error(codes.NotFound, "record not found"),
+		},
 	}
 
+	// 收藏夹 1 是用户 1 的
+	err := s.db.Create(&dao.Collection{Id: 1, Name: "test", Uid: 1, Ctime: 123, Utime: 234}).Error
+	assert.NoError(s.T(), err)
 	svc := startup.InitInteractiveService()
 
 	for _, tc := range testCases {
@@ -675,6 +706,7 @@ func (s *InteractiveTestSuite) TestGet() {
 					ReadCnt:    100,
 					CollectCnt: 200,
 					LikeCnt:    300,
+					Reactions:  map[string]int64{"like": 300},
 				},
 			},
 		},
@@ -716,6 +748,8 @@ func (s *InteractiveTestSuite) TestGet() {
 					CollectCnt: 1,
 					Collected:  true,
 					Liked:      true,
+					Reactions:  map[string]int64{},
+					Reaction:   "like",
 				},
 			},
 		},
@@ -806,6 +840,319 @@ func (s *InteractiveTestSuite) TestGetByIds() {
 	}
 }
 
+func (s *InteractiveTestSuite) TestCollection() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	svc := startup.InitInteractiveService()
+
+	created, err := svc.CreateCollection(ctx, &intrv1.CreateCollectionRequest{Uid: 1, Name: "Go"})
+	assert.NoError(t, err)
+	cid := created.GetCid()
+	_, err = svc.RenameCollection(ctx, &intrv1.RenameCollectionRequest{Uid: 1, Cid: cid, Name: "Golang"})
+	assert.NoError(t, err)
+	// 别人的收藏夹改不了
+	_, err = svc.RenameCollection(ctx, &intrv1.RenameCollectionRequest{Uid: 2, Cid: cid, Name: "Java"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	for _, id := range []int64{1, 2} {
+		_, err = svc.Collect(ctx, &intrv1.CollectRequest{Biz: "test", BizId: id, Cid: cid, Uid: 1})
+		assert.NoError(t, err)
+	}
+	_, err = svc.Collect(ctx, &intrv1.CollectRequest{Biz: "test", BizId: 3, Uid: 1})
+	assert.NoError(t, err)
+	err = s.rdb.HSet(ctx, "interactive:test:1", "collect_cnt", 1).Err()
+	assert.NoError(t, err)
+
+	cols, err := svc.ListCollections(ctx, &intrv1.ListCollectionsRequest{Uid: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), cols.GetTotal())
+	assert.Equal(t, "Golang", cols.GetCollections()[0].GetName())
+	assert.Equal(t, int64(2), cols.GetCollections()[0].GetItemCnt())
+
+	// 分页，最近收藏的在前面
+	items, err := svc.ListCollectionItems(ctx, &intrv1.ListCollectionItemsRequest{Uid: 1, Cid: cid, Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), items.GetTotal())
+	assert.Equal(t, int64(2), items.GetItems()[0].GetBizId())
+	_, err = svc.ListCollectionItems(ctx, &intrv1.ListCollectionItemsRequest{Uid: 2, Cid: cid, Limit: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// 移动到默认收藏夹
+	_, err = svc.MoveCollectionItem(ctx, &intrv1.MoveCollectionItemRequest{Biz: "test", BizId: 2, Uid: 1})
+	assert.NoError(t, err)
+	items, err = svc.ListCollectionItems(ctx, &intrv1.ListCollectionItemsRequest{Uid: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), items.GetTotal())
+	_, err = svc.MoveCollectionItem(ctx, &intrv1.MoveCollectionItemRequest{Biz: "test", BizId: 4, Cid: cid, Uid: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// 取消收藏，重复取消不会再减
+	for i := 0; i < 2; i++ {
+		_, err = svc.RemoveCollectionItem(ctx, &intrv1.RemoveCollectionItemRequest{Biz: "test", BizId: 3, Uid: 1})
+		assert.NoError(t, err)
+	}
+	s.assertCollectCnt(t, 3, 0)
+
+	// 删除收藏夹，里面的收藏一起取消
+	_, err = svc.DeleteCollection(ctx, &intrv1.DeleteCollectionRequest{Uid: 1, Cid: cid})
+	assert.NoError(t, err)
+	s.assertCollectCnt(t, 1, 0)
+	s.assertCollectCnt(t, 2, 1)
+	cnt, err := s.rdb.HGet(ctx, "interactive:test:1", "collect_cnt").Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cnt)
+	_, err = svc.DeleteCollection(ctx, &intrv1.DeleteCollectionRequest{Uid: 1, Cid: cid})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func (s *InteractiveTestSuite) TestGetUserStatus() {
+	t := s.T()
+	err := s.db.Create([]dao.UserLikeBiz{
+		{Uid: 1, Biz: "test", BizId: 1, Status: 1, Ctime: 123, Utime: 234},
+		// 取消了点赞
+		{Uid: 1, Biz: "test", BizId: 2, Status: 0, Ctime: 123, Utime: 234},
+		{Uid: 2, Biz: "test", BizId: 3, Status: 1, Ctime: 123, Utime: 234},
+	}).Error
+	assert.NoError(t, err)
+	err = s.db.Create([]dao.UserCollectionBiz{
+		{Uid: 1, Biz: "test", BizId: 2, Ctime: 123, Utime: 234},
+		{Uid: 1, Biz: "test", BizId: 1, Ctime: 123, Utime: 234},
+	}).Error
+	assert.NoError(t, err)
+
+	svc := startup.InitInteractiveService()
+	res, err := svc.GetUserStatus(context.Background(), &intrv1.GetUserStatusRequest{
+		Biz: "test", Ids: []int64{1, 2, 3}, Uid: 1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &intrv1.UserStatus{Liked: true, Collected: true}, res.GetStatuses()[1])
+	assert.Equal(t, &intrv1.UserStatus{Collected: true}, res.GetStatuses()[2])
+	assert.Equal(t, &intrv1.UserStatus{}, res.GetStatuses()[3])
+}
+
+func (s *InteractiveTestSuite) TestListLikedAndCollectedItems() {
+	t := s.T()
+	err := s.db.Create([]dao.UserLikeBiz{
+		{Uid: 1, Biz: "test", BizId: 1, Status: 1, Ctime: 100, Utime: 100},
+		{Uid: 1, Biz: "test", BizId: 2, Status: 1, Ctime: 100, Utime: 300},
+		{Uid: 1, Biz: "test", BizId: 3, Status: 0, Ctime: 100, Utime: 400},
+		{Uid: 1, Biz: "other", BizId: 4, Status: 1, Ctime: 100, Utime: 500},
+	}).Error
+	assert.NoError(t, err)
+	err = s.db.Create([]dao.UserCollectionBiz{
+		{Uid: 1, Biz: "test", BizId: 5, Cid: 0, Ctime: 100, Utime: 100},
+		{Uid: 1, Biz: "test", BizId: 6, Cid: 7, Ctime: 200, Utime: 200},
+		{Uid: 2, Biz: "test", BizId: 8, Ctime: 300, Utime: 300},
+	}).Error
+	assert.NoError(t, err)
+
+	svc := startup.InitInteractiveService()
+	likes, err := svc.ListLikedItems(context.Background(), &intrv1.ListLikedItemsRequest{
+		Biz: "test", Uid: 1, Limit: 10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), likes.GetTotal())
+	assert.Equal(t, []int64{2, 1}, slice.Map(likes.GetItems(), func(idx int, src *intrv1.LikeItem) int64 {
+		return src.GetBizId()
+	}))
+
+	cols, err := svc.ListCollectedItems(context.Background(), &intrv1.ListCollectedItemsRequest{
+		Biz: "test", Uid: 1, Offset: 1, Limit: 1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cols.GetTotal())
+	assert.Equal(t, []int64{5}, slice.Map(cols.GetItems(), func(idx int, src *intrv1.CollectionItem) int64 {
+		return src.GetBizId()
+	}))
+}
+
+func (s *InteractiveTestSuite) TestReact() {
+	t := s.T()
+	ctx := context.Background()
+	svc := startup.InitInteractiveService()
+	// 缓存里面有数据的时候，也要跟着变
+	err := s.rdb.HSet(ctx, "interactive:test:1", "read_cnt", 1, "like_cnt", 0).Err()
+	assert.NoError(t, err)
+
+	_, err = svc.React(ctx, &intrv1.ReactRequest{Biz: "test", BizId: 1, Uid: 1, Reaction: "love"})
+	assert.NoError(t, err)
+	_, err = svc.React(ctx, &intrv1.ReactRequest{Biz: "test", BizId: 1, Uid: 2, Reaction: "love"})
+	assert.NoError(t, err)
+	// 点赞也是一种表态
+	_, err = svc.Like(ctx, &intrv1.LikeRequest{Biz: "test", Id: 1, Uid: 3})
+	assert.NoError(t, err)
+	// 不支持的表态
+	_, err = svc.React(ctx, &intrv1.ReactRequest{Biz: "test", BizId: 1, Uid: 4, Reaction: "angry"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	res, err := svc.Get(ctx, &intrv1.GetRequest{Biz: "test", Id: 1, Uid: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"love": 2, "like": 1}, res.GetIntr().GetReactions())
+	assert.Equal(t, int64(1), res.GetIntr().GetLikeCnt())
+	assert.Equal(t, "love", res.GetIntr().GetReaction())
+
+	// 换成点赞，原来的表态被替换掉
+	_, err = svc.React(ctx, &intrv1.ReactRequest{Biz: "test", BizId: 1, Uid: 1, Reaction: "like"})
+	assert.NoError(t, err)
+	// 取消点赞的表态
+	_, err = svc.Unreact(ctx, &intrv1.UnreactRequest{Biz: "test", BizId: 1, Uid: 3})
+	assert.NoError(t, err)
+
+	res, err = svc.Get(ctx, &intrv1.GetRequest{Biz: "test", Id: 1, Uid: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"love": 1, "like": 1}, res.GetIntr().GetReactions())
+	assert.True(t, res.GetIntr().GetLiked())
+	assert.Equal(t, "like", res.GetIntr().GetReaction())
+
+	// 缓存失效之后，从数据库里面也能拿到一样的结果
+	err = s.rdb.Del(ctx, "interactive:test:1").Err()
+	assert.NoError(t, err)
+	res, err = svc.Get(ctx, &intrv1.GetRequest{Biz: "test", Id: 1, Uid: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"love": 1, "like": 1}, res.GetIntr().GetReactions())
+	assert.Equal(t, "", res.GetIntr().GetReaction())
+
+	var cnt int64
+	err = s.db.Model(&dao.UserReactionBiz{}).Where("biz = ? AND biz_id = ?", "test", 1).Count(&cnt).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), cnt)
+}
+
+func (s *InteractiveTestSuite) TestReadDedupe() {
+	t := s.T()
+	consumer := startup.InitReadEventConsumer()
+	evts := []events.ReadEvent{
+		{Uid: 1, Aid: 1},
+		// 刷新页面，不算
+		{Uid: 1, Aid: 1},
+		{Uid: 2, Aid: 1},
+		{Uid: 1, Aid: 2},
+	}
+	for _, evt := range evts {
+		err := consumer.Consume(&sarama.ConsumerMessage{}, evt)
+		assert.NoError(t, err)
+	}
+	// 批量消费的也要去重
+	err := consumer.BatchConsume(nil, []events.ReadEvent{{Uid: 2, Aid: 1}, {Uid: 3, Aid: 1}})
+	assert.NoError(t, err)
+
+	svc := startup.InitInteractiveService()
+	res, err := svc.GetByIds(context.Background(), &intrv1.GetByIdsRequest{
+		Biz: "article", Ids: []int64{1, 2},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), res.GetIntrs()[1].GetReadCnt())
+	assert.Equal(t, int64(3), res.GetIntrs()[1].GetUniqueReadCnt())
+	assert.Equal(t, int64(1), res.GetIntrs()[2].GetReadCnt())
+	assert.Equal(t, int64(1), res.GetIntrs()[2].GetUniqueReadCnt())
+
+	// 去重窗口过了之后，重新算一次阅读，但是读者数不变
+	err = s.rdb.Del(context.Background(), "read_dedupe:article:1:1").Err()
+	assert.NoError(t, err)
+	err = consumer.Consume(&sarama.ConsumerMessage{}, events.ReadEvent{Uid: 1, Aid: 1})
+	assert.NoError(t, err)
+	detail, err := svc.Get(context.Background(), &intrv1.GetRequest{Biz: "article", Id: 1, Uid: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), detail.GetIntr().GetReadCnt())
+	assert.Equal(t, int64(3), detail.GetIntr().GetUniqueReadCnt())
+}
+
+func (s *InteractiveTestSuite) TestWriteBehind() {
+	t := s.T()
+	ctx := context.Background()
+	svc := startup.InitWriteBehindInteractiveService()
+	for i := 0; i < 3; i++ {
+		err := svc.IncrReadCnt(ctx, "test", 1)
+		assert.NoError(t, err)
+	}
+	err := svc.Like(ctx, "test", 1, 1)
+	assert.NoError(t, err)
+	err = svc.Like(ctx, "test", 1, 2)
+	assert.NoError(t, err)
+	err = svc.CancelLike(ctx, "test", 1, 2)
+	assert.NoError(t, err)
+
+	// 还没有写到数据库，但是查询的时候已经能看到了
+	var cnt int64
+	err = s.db.Model(&dao.Interactive{}).Where("biz = ? AND biz_id = ?", "test", 1).Count(&cnt).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), cnt)
+	intr, err := svc.Get(ctx, "test", 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), intr.ReadCnt)
+	assert.Equal(t, int64(1), intr.LikeCnt)
+
+	err = svc.FlushCnt(ctx)
+	assert.NoError(t, err)
+	s.assertCnt(t, 1, 3, 1)
+	exist, err := s.rdb.Exists(ctx, "{interactive_delta}:pending", "{interactive_delta}:flushing").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), exist)
+
+	// 模拟写完数据库之后没来得及确认就挂了，重放不会多算
+	err = svc.IncrReadCnt(ctx, "test", 1)
+	assert.NoError(t, err)
+	err = s.rdb.HSet(ctx, "{interactive_delta}:pending", "batch", "crashed").Err()
+	assert.NoError(t, err)
+	err = s.rdb.Rename(ctx, "{interactive_delta}:pending", "{interactive_delta}:flushing").Err()
+	assert.NoError(t, err)
+	err = s.db.Create(&dao.InteractiveFlushLog{Batch: "crashed"}).Error
+	assert.NoError(t, err)
+	err = s.db.Model(&dao.Interactive{}).Where("biz = ? AND biz_id = ?", "test", 1).
+		Update("read_cnt", 4).Error
+	assert.NoError(t, err)
+	err = svc.FlushCnt(ctx)
+	assert.NoError(t, err)
+	s.assertCnt(t, 1, 4, 1)
+	exist, err = s.rdb.Exists(ctx, "{interactive_delta}:flushing").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), exist)
+
+	// 点赞数被改坏了，对账的时候修回来，正在 flush 还没写进去的点赞也要算上
+	err = s.db.Model(&dao.Interactive{}).Where("biz = ? AND biz_id = ?", "test", 1).
+		Update("like_cnt", 5).Error
+	assert.NoError(t, err)
+	err = svc.Like(ctx, "test", 1, 3)
+	assert.NoError(t, err)
+	err = s.rdb.HSet(ctx, "{interactive_delta}:pending", "batch", "flushing").Err()
+	assert.NoError(t, err)
+	err = s.rdb.Rename(ctx, "{interactive_delta}:pending", "{interactive_delta}:flushing").Err()
+	assert.NoError(t, err)
+	err = svc.ReconcileLikeCnt(ctx, time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	s.assertCnt(t, 1, 4, 1)
+	err = svc.FlushCnt(ctx)
+	assert.NoError(t, err)
+	s.assertCnt(t, 1, 4, 2)
+
+	// 很久之前的 batch 记录会被删掉，最近的还留着
+	err = svc.CleanFlushLogs(ctx, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	var logs []dao.InteractiveFlushLog
+	err = s.db.Find(&logs).Error
+	assert.NoError(t, err)
+	assert.NotEmpty(t, logs)
+	for _, log := range logs {
+		assert.NotEqual(t, "crashed", log.Batch)
+	}
+}
+
+func (s *InteractiveTestSuite) assertCnt(t *testing.T, bizId int64, readCnt, likeCnt int64) {
+	var intr dao.Interactive
+	err := s.db.Where("biz = ? AND biz_id = ?", "test", bizId).First(&intr).Error
+	assert.NoError(t, err)
+	assert.Equal(t, readCnt, intr.ReadCnt)
+	assert.Equal(t, likeCnt, intr.LikeCnt)
+}
+
+func (s *InteractiveTestSuite) assertCollectCnt(t *testing.T, bizId int64, want int64) {
+	var intr dao.Interactive
+	err := s.db.Where("biz = ? AND biz_id = ?", "test", bizId).First(&intr).Error
+	assert.NoError(t, err)
+	assert.Equal(t, want, intr.CollectCnt)
+}
+
 func TestInteractiveService(t *testing.T) {
 	suite.Run(t, &InteractiveTestSuite{})
 }
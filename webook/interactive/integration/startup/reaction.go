@@ -0,0 +1,9 @@
+package startup
+
+import "github.com/jayleonc/geektime-go/webook/interactive/domain"
+
+func InitReactionConfig() domain.ReactionConfig {
+	return domain.ReactionConfig{
+		"test": {domain.ReactionLike, "love", "laugh"},
+	}
+}
@@ -0,0 +1,10 @@
+package startup
+
+import (
+	"github.com/jayleonc/geektime-go/webook/interactive/events"
+	"time"
+)
+
+func InitReadDedupeConfig() events.ReadDedupeConfig {
+	return events.ReadDedupeConfig{Window: time.Minute}
+}
@@ -4,6 +4,7 @@ package startup
 
 import (
 	"github.com/google/wire"
+	"github.com/jayleonc/geektime-go/webook/interactive/events"
 	"github.com/jayleonc/geektime-go/webook/interactive/grpc"
 	"github.com/jayleonc/geektime-go/webook/interactive/repository"
 	"github.com/jayleonc/geektime-go/webook/interactive/repository/cache"
@@ -19,8 +20,14 @@ var thirdPartySet = wire.NewSet( // 第三方依赖
 )
 
 var interactiveSvcSet = wire.NewSet(dao.NewGORMInteractiveDAO,
+	dao.NewGORMCollectionDAO,
+	dao.NewGORMReactionDAO,
+	dao.NewGORMInteractiveCntDAO,
 	cache.NewInteractiveRedisCache,
 	repository.NewCachedInteractiveRepository,
+	repository.NewCachedCollectionRepository,
+	InitReactionConfig,
+	InitWriteBehindConfig,
 	service.NewInteractiveService,
 )
 
@@ -28,3 +35,26 @@ func InitInteractiveService() *grpc.InteractiveServiceServer {
 	wire.Build(thirdPartySet, interactiveSvcSet, grpc.NewInteractiveServiceServer)
 	return new(grpc.InteractiveServiceServer)
 }
+
+func InitReadEventConsumer() *events.InteractiveReadEventConsumer {
+	wire.Build(thirdPartySet, interactiveSvcSet, InitReadDedupeConfig, events.NewInteractiveReadEventConsumer)
+	return new(events.InteractiveReadEventConsumer)
+}
+
+var writeBehindSvcSet = wire.NewSet(dao.NewGORMInteractiveDAO,
+	dao.NewGORMCollectionDAO,
+	dao.NewGORMReactionDAO,
+	dao.NewGORMInteractiveCntDAO,
+	cache.NewInteractiveRedisCache,
+	repository.NewCachedInteractiveRepository,
+	repository.NewCachedCollectionRepository,
+	InitReactionConfig,
+	InitEnabledWriteBehindConfig,
+	service.NewInteractiveService,
+)
+
+// InitWriteBehindInteractiveService 打开了 write-behind 的
+func InitWriteBehindInteractiveService() service.InteractiveService {
+	wire.Build(thirdPartySet, writeBehindSvcSet)
+	return service.NewInteractiveService(nil, nil, nil)
+}
@@ -8,6 +8,7 @@ package startup
 
 import (
 	"github.com/google/wire"
+	"github.com/jayleonc/geektime-go/webook/interactive/events"
 	"github.com/jayleonc/geektime-go/webook/interactive/grpc"
 	"github.com/jayleonc/geektime-go/webook/interactive/repository"
 	"github.com/jayleonc/geektime-go/webook/interactive/repository/cache"
@@ -22,12 +23,49 @@ func InitInteractiveService() *grpc.InteractiveServiceServer {
 	interactiveDAO := dao.NewGORMInteractiveDAO(db)
 	cmdable := InitRedis()
 	interactiveCache := cache.NewInteractiveRedisCache(cmdable)
-	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache)
-	interactiveService := service.NewInteractiveService(interactiveRepository)
+	writeBehindConfig := InitWriteBehindConfig()
+	reactionDAO := dao.NewGORMReactionDAO(db, writeBehindConfig)
+	interactiveCntDAO := dao.NewGORMInteractiveCntDAO(db)
+	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, reactionDAO, interactiveCntDAO, interactiveCache, writeBehindConfig)
+	collectionDAO := dao.NewGORMCollectionDAO(db)
+	collectionRepository := repository.NewCachedCollectionRepository(collectionDAO, interactiveCache)
+	reactionConfig := InitReactionConfig()
+	interactiveService := service.NewInteractiveService(interactiveRepository, collectionRepository, reactionConfig)
 	interactiveServiceServer := grpc.NewInteractiveServiceServer(interactiveService)
 	return interactiveServiceServer
 }
 
+func InitReadEventConsumer() *events.InteractiveReadEventConsumer {
+	db := InitDB()
+	interactiveDAO := dao.NewGORMInteractiveDAO(db)
+	writeBehindConfig := InitWriteBehindConfig()
+	reactionDAO := dao.NewGORMReactionDAO(db, writeBehindConfig)
+	interactiveCntDAO := dao.NewGORMInteractiveCntDAO(db)
+	cmdable := InitRedis()
+	interactiveCache := cache.NewInteractiveRedisCache(cmdable)
+	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, reactionDAO, interactiveCntDAO, interactiveCache, writeBehindConfig)
+	client := InitSaramaClient()
+	readDedupeConfig := InitReadDedupeConfig()
+	interactiveReadEventConsumer := events.NewInteractiveReadEventConsumer(interactiveRepository, client, readDedupeConfig)
+	return interactiveReadEventConsumer
+}
+
+func InitWriteBehindInteractiveService() service.InteractiveService {
+	db := InitDB()
+	interactiveDAO := dao.NewGORMInteractiveDAO(db)
+	writeBehindConfig := InitEnabledWriteBehindConfig()
+	reactionDAO := dao.NewGORMReactionDAO(db, writeBehindConfig)
+	interactiveCntDAO := dao.NewGORMInteractiveCntDAO(db)
+	cmdable := InitRedis()
+	interactiveCache := cache.NewInteractiveRedisCache(cmdable)
+	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, reactionDAO, interactiveCntDAO, interactiveCache, writeBehindConfig)
+	collectionDAO := dao.NewGORMCollectionDAO(db)
+	collectionRepository := repository.NewCachedCollectionRepository(collectionDAO, interactiveCache)
+	reactionConfig := InitReactionConfig()
+	interactiveService := service.NewInteractiveService(interactiveRepository, collectionRepository, reactionConfig)
+	return interactiveService
+}
+
 // wire.go:
 
 var thirdPartySet = wire.NewSet(
@@ -37,4 +75,6 @@ var thirdPartySet = wire.NewSet(
 	InitLogger,
 )
 
-var interactiveSvcSet = wire.NewSet(dao.NewGORMInteractiveDAO, cache.NewInteractiveRedisCache, repository.NewCachedInteractiveRepository, service.NewInteractiveService)
+var interactiveSvcSet = wire.NewSet(dao.NewGORMInteractiveDAO, dao.NewGORMCollectionDAO, dao.NewGORMReactionDAO, dao.NewGORMInteractiveCntDAO, cache.NewInteractiveRedisCache, repository.NewCachedInteractiveRepository, repository.NewCachedCollectionRepository, InitReactionConfig, InitWriteBehindConfig, service.NewInteractiveService)
+
+var writeBehindSvcSet = wire.NewSet(dao.NewGORMInteractiveDAO, dao.NewGORMCollectionDAO, dao.NewGORMReactionDAO, dao.NewGORMInteractiveCntDAO, cache.NewInteractiveRedisCache, repository.NewCachedInteractiveRepository, repository.NewCachedCollectionRepository, InitReactionConfig, InitEnabledWriteBehindConfig, service.NewInteractiveService)
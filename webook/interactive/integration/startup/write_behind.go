@@ -0,0 +1,12 @@
+package startup
+
+import "github.com/jayleonc/geektime-go/webook/interactive/domain"
+
+// InitWriteBehindConfig 别的测试要直接检查数据库，默认不打开
+func InitWriteBehindConfig() domain.WriteBehindConfig {
+	return domain.WriteBehindConfig{}
+}
+
+func InitEnabledWriteBehindConfig() domain.WriteBehindConfig {
+	return domain.WriteBehindConfig{Enabled: true}
+}
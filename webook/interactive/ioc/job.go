@@ -0,0 +1,81 @@
+package ioc
+
+import (
+	"fmt"
+	rlock "github.com/gotomicro/redis-lock"
+	"github.com/jayleonc/geektime-go/webook/interactive/domain"
+	ijob "github.com/jayleonc/geektime-go/webook/interactive/job"
+	"github.com/jayleonc/geektime-go/webook/interactive/service"
+	"github.com/jayleonc/geektime-go/webook/job"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/viper"
+	"time"
+)
+
+// InitWriteBehindConfig writeBehind.enabled 打开 write-behind，默认关闭；
+// writeBehind.interval 是写数据库的间隔，默认 1 秒
+func InitWriteBehindConfig() domain.WriteBehindConfig {
+	cfg := domain.WriteBehindConfig{
+		Enabled:  viper.GetBool("writeBehind.enabled"),
+		Interval: viper.GetDuration("writeBehind.interval"),
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	return cfg
+}
+
+func InitCntFlushJob(svc service.InteractiveService) *ijob.CntFlushJob {
+	return ijob.NewCntFlushJob(svc, time.Second*10)
+}
+
+// InitLikeCntReconcileJob 每次核对最近 10 分钟有人点过赞的，对账本身 5 分钟一次
+func InitLikeCntReconcileJob(svc service.InteractiveService, l logger.Logger,
+	client *rlock.Client) *ijob.LikeCntReconcileJob {
+	return ijob.NewLikeCntReconcileJob(svc, l, time.Minute*10, time.Minute, client)
+}
+
+// InitFlushLogCleanJob writeBehind.flushLogTTL 是 batch 记录保留多久，默认 24 小时
+func InitFlushLogCleanJob(svc service.InteractiveService) *ijob.FlushLogCleanJob {
+	ttl := viper.GetDuration("writeBehind.flushLogTTL")
+	if ttl <= 0 {
+		ttl = time.Hour * 24
+	}
+	return ijob.NewFlushLogCleanJob(svc, ttl, time.Minute)
+}
+
+// InitJobs 没有打开 write-behind 的时候也要对账，对账也会记 batch，所以也要清理
+func InitJobs(l logger.Logger, cfg domain.WriteBehindConfig, flushJob *ijob.CntFlushJob,
+	reconcileJob *ijob.LikeCntReconcileJob, cleanJob *ijob.FlushLogCleanJob) *cron.Cron {
+	builder := job.NewCronJobBuilder(l, prometheus.SummaryOpts{
+		Namespace: "geektime_jayleonc",
+		Subsystem: "webook_intr",
+		Name:      "cron_job",
+		Help:      "定时任务执行",
+		Objectives: map[float64]float64{
+			0.5:   0.01,
+			0.75:  0.01,
+			0.9:   0.01,
+			0.99:  0.001,
+			0.999: 0.0001,
+		},
+	})
+	expr := cron.New(cron.WithSeconds())
+	if cfg.Enabled {
+		_, err := expr.AddJob(fmt.Sprintf("@every %s", cfg.Interval), builder.Build(flushJob))
+		if err != nil {
+			panic(err)
+		}
+	}
+	_, err := expr.AddJob("@every 5m", builder.Build(reconcileJob))
+	if err != nil {
+		panic(err)
+	}
+	_, err = expr.AddJob("@every 1h", builder.Build(cleanJob))
+	if err != nil {
+		panic(err)
+	}
+	return expr
+}
@@ -2,6 +2,7 @@ package ioc
 
 import (
 	"github.com/IBM/sarama"
+	events2 "github.com/jayleonc/geektime-go/webook/interactive/events"
 	prometheus2 "github.com/jayleonc/geektime-go/webook/interactive/events/prometheus"
 	"github.com/jayleonc/geektime-go/webook/interactive/repository/dao"
 	"github.com/jayleonc/geektime-go/webook/internal/events"
@@ -9,6 +10,7 @@ import (
 	"github.com/jayleonc/geektime-go/webook/internal/events/article/prometheus"
 	"github.com/jayleonc/geektime-go/webook/pkg/migrator/events/fixer"
 	"github.com/spf13/viper"
+	"time"
 )
 
 func InitKafka() sarama.Client {
@@ -38,11 +40,21 @@ func NewSyncProducer(client sarama.Client) sarama.SyncProducer {
 }
 
 // InitConsumers 注册 Consumer
-func InitConsumers(m *prometheus2.InteractiveReadEventConsumerWithMetrics, fixConsumer *fixer.Consumer[dao.Interactive]) []events.Consumer {
-	return []events.Consumer{m, fixConsumer}
+func InitConsumers(m *prometheus2.InteractiveReadEventConsumerWithMetrics, fixConsumer *fixer.Consumer[dao.Interactive],
+	commentConsumer *events2.InteractiveCommentEventConsumer) []events.Consumer {
+	return []events.Consumer{m, fixConsumer, commentConsumer}
 }
 
 func NewKafkaProducerWithMetricsDecorator(syncProducer sarama.SyncProducer) article.Producer {
 	baseProducer := article.NewKafkaProducer(syncProducer)
 	return prometheus.NewKafkaProducerWithMetrics(baseProducer)
 }
+
+// InitReadDedupeConfig 默认同一个用户半个小时里面重复阅读只算一次
+func InitReadDedupeConfig() events2.ReadDedupeConfig {
+	cfg := events2.ReadDedupeConfig{Window: time.Minute * 30}
+	if viper.IsSet("readDedupe.window") {
+		cfg.Window = viper.GetDuration("readDedupe.window")
+	}
+	return cfg
+}
@@ -0,0 +1,22 @@
+package ioc
+
+import (
+	"github.com/jayleonc/geektime-go/webook/interactive/domain"
+	"github.com/spf13/viper"
+)
+
+// InitReactionConfig 没有配置的时候，文章可以用点赞、喜欢和笑哭
+func InitReactionConfig() domain.ReactionConfig {
+	cfg := domain.ReactionConfig{
+		"article": {domain.ReactionLike, "love", "laugh"},
+	}
+	if !viper.IsSet("reactions") {
+		return cfg
+	}
+	cfg = domain.ReactionConfig{}
+	err := viper.UnmarshalKey("reactions", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
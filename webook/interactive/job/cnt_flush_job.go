@@ -0,0 +1,28 @@
+package job
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/interactive/service"
+	"time"
+)
+
+// CntFlushJob 把 write-behind 攒下来的计数写到数据库。
+// 多个实例同时跑也没关系，同一个 batch 只会写一次
+type CntFlushJob struct {
+	svc     service.InteractiveService
+	timeout time.Duration
+}
+
+func NewCntFlushJob(svc service.InteractiveService, timeout time.Duration) *CntFlushJob {
+	return &CntFlushJob{svc: svc, timeout: timeout}
+}
+
+func (c *CntFlushJob) Name() string {
+	return "interactive_cnt_flush"
+}
+
+func (c *CntFlushJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	return c.svc.FlushCnt(ctx)
+}
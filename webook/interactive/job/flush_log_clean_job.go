@@ -0,0 +1,30 @@
+package job
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/interactive/service"
+	"time"
+)
+
+// FlushLogCleanJob 删掉 ttl 之前的 batch 记录。
+// ttl 要比 batch 可能卡在 Redis 里面等着重放的时间长，不然重放的时候就去不了重
+type FlushLogCleanJob struct {
+	svc     service.InteractiveService
+	ttl     time.Duration
+	timeout time.Duration
+}
+
+func NewFlushLogCleanJob(svc service.InteractiveService, ttl time.Duration, timeout time.Duration) *FlushLogCleanJob {
+	return &FlushLogCleanJob{svc: svc, ttl: ttl, timeout: timeout}
+}
+
+func (f *FlushLogCleanJob) Name() string {
+	return "interactive_flush_log_clean"
+}
+
+// Run 多个实例同时删也没关系
+func (f *FlushLogCleanJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+	return f.svc.CleanFlushLogs(ctx, time.Now().Add(-f.ttl))
+}
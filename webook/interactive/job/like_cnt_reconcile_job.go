@@ -0,0 +1,70 @@
+package job
+
+import (
+	"context"
+	rlock "github.com/gotomicro/redis-lock"
+	"github.com/jayleonc/geektime-go/webook/interactive/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"sync"
+	"time"
+)
+
+// LikeCntReconcileJob 用点赞记录核对最近 window 里面有人点过赞的资源的点赞数。
+// 修正是增量，两个实例同时修会修过头，所以只有拿到分布式锁的实例才跑
+type LikeCntReconcileJob struct {
+	svc     service.InteractiveService
+	l       logger.Logger
+	window  time.Duration
+	timeout time.Duration
+	client  *rlock.Client
+
+	lock      *rlock.Lock
+	localLock *sync.Mutex
+	key       string
+}
+
+func NewLikeCntReconcileJob(svc service.InteractiveService, l logger.Logger, window time.Duration,
+	timeout time.Duration, client *rlock.Client) *LikeCntReconcileJob {
+	return &LikeCntReconcileJob{svc: svc, l: l, window: window, timeout: timeout, client: client,
+		localLock: &sync.Mutex{}, key: "job:like_cnt_reconcile"}
+}
+
+func (l *LikeCntReconcileJob) Name() string {
+	return "like_cnt_reconcile"
+}
+
+func (l *LikeCntReconcileJob) Run() error {
+	l.localLock.Lock()
+	lock := l.lock
+	if lock == nil {
+		// 抢分布式锁
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*4)
+		defer cancel()
+		lock, err := l.client.Lock(ctx, l.key, l.timeout,
+			&rlock.FixIntervalRetry{
+				Interval: time.Millisecond * 100,
+				Max:      3,
+			}, time.Second)
+		if err != nil {
+			l.localLock.Unlock()
+			l.l.Warn("获取分布式锁失败", logger.Error(err))
+			return nil
+		}
+		l.lock = lock
+		l.localLock.Unlock()
+		go func() {
+			er := lock.AutoRefresh(l.timeout/2, l.timeout)
+			if er != nil {
+				// 续约失败了，下一次重新抢
+				l.localLock.Lock()
+				l.lock = nil
+				l.localLock.Unlock()
+			}
+		}()
+	} else {
+		l.localLock.Unlock()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+	return l.svc.ReconcileLikeCnt(ctx, time.Now().Add(-l.window))
+}
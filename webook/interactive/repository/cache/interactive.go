@@ -8,18 +8,26 @@ import (
 	er "github.com/jayleonc/geektime-go/webook/interactive/error"
 	"github.com/redis/go-redis/v9"
 	"strconv"
+	"strings"
 	"time"
 )
 
 var (
 	//go:embed lua/incr_cnt.lua
 	luaIncrCnt string
+	//go:embed lua/react.lua
+	luaReact string
+	//go:embed lua/add_reader.lua
+	luaAddReader string
 )
 
 const (
 	fieldReadCnt    = "read_cnt"
 	fieldLikeCnt    = "like_cnt"
 	fieldCollectCnt = "collect_cnt"
+	fieldCommentCnt = "comment_cnt"
+	// fieldReactionPrefix 点赞之外的表情的计数字段，比如 reaction:love。点赞用的是 like_cnt
+	fieldReactionPrefix = "reaction:"
 )
 
 type InteractiveCache interface {
@@ -27,12 +35,33 @@ type InteractiveCache interface {
 	IncrLikeCntIfPresent(ctx context.Context, biz string, id int64) error
 	DecrLikeCntIfPresent(ctx context.Context, biz string, id int64) error
 	IncrCollectCntIfPresent(ctx context.Context, biz string, id int64) error
+	DecrCollectCntIfPresent(ctx context.Context, biz string, id int64) error
+	IncrCommentCntIfPresent(ctx context.Context, biz string, id int64, delta int64) error
+	// ReactIfPresent 表情从 oldReaction 换成 newReaction，空字符串表示没有表情
+	ReactIfPresent(ctx context.Context, biz string, id int64, oldReaction, newReaction string) error
 	Get(ctx context.Context, biz string, id int64) (domain.Interactive, error)
 	Set(ctx context.Context, biz string, bizId int64, res domain.Interactive) error
 	GetTopNLikedInteractive(ctx context.Context, biz string, n int) ([]domain.ArticleLike, error)
 	SetTopNLikedInteractive(ctx context.Context, biz string, likes []domain.ArticleLike) error
 	IncrLikeCnt(ctx context.Context, biz string, id int64, i int) error
 	DecrLikeCnt(ctx context.Context, biz string, id int64, decrement int64) error
+	// AddReaders bizIds 和 uids 一一对应。返回的是每一次阅读是不是 window 里面的第一次，
+	// 只有第一次才要加阅读数
+	AddReaders(ctx context.Context, biz string, bizIds []int64, uids []int64, window time.Duration) ([]bool, error)
+	// UniqueReadCnts 去重之后的读者数，是 HyperLogLog 的估算值
+	UniqueReadCnts(ctx context.Context, biz string, ids []int64) (map[int64]int64, error)
+	Del(ctx context.Context, biz string, id int64) error
+
+	// AddDeltas write-behind 模式下把计数的变化先攒在 Redis 里面
+	AddDeltas(ctx context.Context, deltas []domain.InteractiveDelta) error
+	// PendingDeltas 攒着的和正在写数据库的计数，两个是一起读的。
+	// bizIds 为空的时候只拿 Epoch 和 Batch
+	PendingDeltas(ctx context.Context, bizs []string, bizIds []int64) (domain.PendingDeltas, error)
+	// TakeDeltas 把攒着的计数换成一个 batch 拿出来，batch 是新的 batch 的名字。
+	// 上一个 batch 没有 AckDeltas 的时候，拿到的还是上一个 batch，返回的是真正拿到的 batch
+	TakeDeltas(ctx context.Context, batch string) (string, []domain.InteractiveDelta, error)
+	// AckDeltas batch 已经写到数据库了，可以删掉了
+	AckDeltas(ctx context.Context, batch string) error
 }
 
 type InteractiveRedisCache struct {
@@ -101,7 +130,13 @@ func (i *InteractiveRedisCache) GetTopNLikedInteractive(ctx context.Context, biz
 
 func (i *InteractiveRedisCache) Set(ctx context.Context, biz string, bizId int64, res domain.Interactive) error {
 	key := i.key(biz, bizId)
-	err := i.client.HSet(ctx, key, fieldReadCnt, res.ReadCnt, fieldLikeCnt, res.LikeCnt, fieldCollectCnt, res.CollectCnt).Err()
+	vals := []interface{}{fieldReadCnt, res.ReadCnt, fieldLikeCnt, res.LikeCnt, fieldCollectCnt, res.CollectCnt, fieldCommentCnt, res.CommentCnt}
+	for reaction, cnt := range res.Reactions {
+		if reaction != domain.ReactionLike {
+			vals = append(vals, i.reactionField(reaction), cnt)
+		}
+	}
+	err := i.client.HSet(ctx, key, vals...).Err()
 	if err != nil {
 		return err
 	}
@@ -122,6 +157,13 @@ func (i *InteractiveRedisCache) Get(ctx context.Context, biz string, id int64) (
 	intr.ReadCnt, _ = strconv.ParseInt(res[fieldReadCnt], 10, 64)
 	intr.LikeCnt, _ = strconv.ParseInt(res[fieldLikeCnt], 10, 64)
 	intr.CollectCnt, _ = strconv.ParseInt(res[fieldCollectCnt], 10, 64)
+	intr.CommentCnt, _ = strconv.ParseInt(res[fieldCommentCnt], 10, 64)
+	intr.Reactions = make(map[string]int64)
+	for field, val := range res {
+		if reaction, ok := strings.CutPrefix(field, fieldReactionPrefix); ok {
+			intr.Reactions[reaction], _ = strconv.ParseInt(val, 10, 64)
+		}
+	}
 	return intr, nil
 }
 
@@ -130,6 +172,34 @@ func (i *InteractiveRedisCache) IncrCollectCntIfPresent(ctx context.Context, biz
 	return i.client.Eval(ctx, luaIncrCnt, []string{key}, fieldCollectCnt, 1).Err()
 }
 
+func (i *InteractiveRedisCache) DecrCollectCntIfPresent(ctx context.Context, biz string, id int64) error {
+	key := i.key(biz, id)
+	return i.client.Eval(ctx, luaIncrCnt, []string{key}, fieldCollectCnt, -1).Err()
+}
+
+func (i *InteractiveRedisCache) IncrCommentCntIfPresent(ctx context.Context, biz string, id int64, delta int64) error {
+	key := i.key(biz, id)
+	return i.client.Eval(ctx, luaIncrCnt, []string{key}, fieldCommentCnt, delta).Err()
+}
+
+func (i *InteractiveRedisCache) ReactIfPresent(ctx context.Context, biz string, id int64, oldReaction, newReaction string) error {
+	key := i.key(biz, id)
+	return i.client.Eval(ctx, luaReact, []string{key},
+		i.reactionField(oldReaction), i.reactionField(newReaction)).Err()
+}
+
+// reactionField 点赞还是用 like_cnt，和原来的点赞兼容
+func (i *InteractiveRedisCache) reactionField(reaction string) string {
+	switch reaction {
+	case "":
+		return ""
+	case domain.ReactionLike:
+		return fieldLikeCnt
+	default:
+		return fieldReactionPrefix + reaction
+	}
+}
+
 func (i *InteractiveRedisCache) IncrLikeCntIfPresent(ctx context.Context, biz string, id int64) error {
 	key := i.key(biz, id)
 	return i.client.Eval(ctx, luaIncrCnt, []string{key}, fieldLikeCnt, 1).Err()
@@ -149,6 +219,51 @@ func (i *InteractiveRedisCache) IncrReadCntIfPresent(ctx context.Context, biz st
 	return i.client.Eval(ctx, luaIncrCnt, []string{key}, fieldReadCnt, 1).Err()
 }
 
+func (i *InteractiveRedisCache) AddReaders(ctx context.Context, biz string, bizIds []int64, uids []int64, window time.Duration) ([]bool, error) {
+	pipe := i.client.Pipeline()
+	cmds := make([]*redis.Cmd, len(bizIds))
+	for idx, bizId := range bizIds {
+		keys := []string{i.dedupeKey(biz, bizId, uids[idx]), i.uvKey(biz, bizId)}
+		cmds[idx] = pipe.Eval(ctx, luaAddReader, keys, uids[idx], int64(window/time.Second))
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]bool, len(cmds))
+	for idx, cmd := range cmds {
+		ok, _ := cmd.Int()
+		res[idx] = ok == 1
+	}
+	return res, nil
+}
+
+func (i *InteractiveRedisCache) UniqueReadCnts(ctx context.Context, biz string, ids []int64) (map[int64]int64, error) {
+	pipe := i.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(ids))
+	for idx, id := range ids {
+		cmds[idx] = pipe.PFCount(ctx, i.uvKey(biz, id))
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]int64, len(ids))
+	for idx, cmd := range cmds {
+		res[ids[idx]] = cmd.Val()
+	}
+	return res, nil
+}
+
+// uvKey 读者的 HyperLogLog 不过期，一篇文章最多占 12KB
+func (i *InteractiveRedisCache) uvKey(biz string, bizId int64) string {
+	return fmt.Sprintf("read_uv:%s:%d", biz, bizId)
+}
+
+func (i *InteractiveRedisCache) dedupeKey(biz string, bizId int64, uid int64) string {
+	return fmt.Sprintf("read_dedupe:%s:%d:%d", biz, bizId, uid)
+}
+
 func (i *InteractiveRedisCache) key(biz string, bizId int64) string {
 	return fmt.Sprintf("interactive:%s:%d", biz, bizId)
 }
@@ -0,0 +1,8 @@
+local flushing = KEYS[1]
+local batch = ARGV[1]
+
+-- 只删自己写完的那个 batch，别的实例可能已经换上了新的
+if redis.call("HGET", flushing, "batch") == batch then
+    return redis.call("DEL", flushing)
+end
+return 0
@@ -0,0 +1,14 @@
+local dedupeKey = KEYS[1] -- 这个用户在去重窗口里面有没有读过
+local uvKey = KEYS[2] -- HyperLogLog，统计一共有多少个读者
+local uid = ARGV[1]
+local window = tonumber(ARGV[2]) -- 去重窗口，秒，0 表示不去重
+
+redis.call("PFADD", uvKey, uid)
+if window <= 0 then
+    return 1
+end
+-- 窗口里面第一次读才能设置成功
+if redis.call("SET", dedupeKey, 1, "NX", "EX", window) then
+    return 1
+end
+return 0
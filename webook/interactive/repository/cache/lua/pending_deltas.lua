@@ -0,0 +1,18 @@
+local pending = KEYS[1] -- 还在攒的计数
+local flushing = KEYS[2] -- 正在写数据库的计数
+local epoch = KEYS[3]
+
+-- 两个 hash 要一起读，不然中间换了 batch 就会漏掉或者多算
+local res = {redis.call("GET", epoch) or "0", redis.call("HGET", flushing, "batch") or ""}
+if #ARGV == 0 then
+    return res
+end
+local pendingVals = redis.call("HMGET", pending, unpack(ARGV))
+local flushingVals = redis.call("HMGET", flushing, unpack(ARGV))
+for i = 1, #ARGV do
+    res[#res + 1] = pendingVals[i] or "0"
+end
+for i = 1, #ARGV do
+    res[#res + 1] = flushingVals[i] or "0"
+end
+return res
@@ -0,0 +1,15 @@
+local key = KEYS[1] -- 具体的业务
+local oldField = ARGV[1] -- 原来的表情的计数字段，空字符串表示原来没有表情
+local newField = ARGV[2] -- 新的表情的计数字段，空字符串表示取消表情
+
+local exist = redis.call("EXISTS", key)
+if exist == 0 then
+    return 0
+end
+if oldField ~= "" then
+    redis.call("HINCRBY", key, oldField, -1)
+end
+if newField ~= "" then
+    redis.call("HINCRBY", key, newField, 1)
+end
+return 1
@@ -0,0 +1,15 @@
+local pending = KEYS[1] -- 还在攒的计数
+local flushing = KEYS[2] -- 正在写数据库的计数
+local epoch = KEYS[3] -- 换了几次 batch
+local batch = ARGV[1]
+
+-- 上一次没有确认的要先重放，不然就把攒着的整个换成新的 batch
+if redis.call("EXISTS", flushing) == 0 then
+    if redis.call("EXISTS", pending) == 0 then
+        return {}
+    end
+    redis.call("HSET", pending, "batch", batch)
+    redis.call("RENAME", pending, flushing)
+    redis.call("INCR", epoch)
+end
+return redis.call("HGETALL", flushing)
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/jayleonc/geektime-go/webook/interactive/domain"
+	"strconv"
+	"strings"
+)
+
+var (
+	//go:embed lua/take_deltas.lua
+	luaTakeDeltas string
+	//go:embed lua/ack_deltas.lua
+	luaAckDeltas string
+	//go:embed lua/pending_deltas.lua
+	luaPendingDeltas string
+)
+
+// 两个 key 用同一个 hash tag，集群模式下也能 RENAME
+const (
+	keyPendingDeltas  = "{interactive_delta}:pending"
+	keyFlushingDeltas = "{interactive_delta}:flushing"
+	keyDeltaEpoch     = "{interactive_delta}:epoch"
+	fieldBatch        = "batch"
+)
+
+func (i *InteractiveRedisCache) AddDeltas(ctx context.Context, deltas []domain.InteractiveDelta) error {
+	pipe := i.client.Pipeline()
+	for _, d := range deltas {
+		for field, delta := range map[string]int64{
+			fieldReadCnt:    d.ReadCnt,
+			fieldLikeCnt:    d.LikeCnt,
+			fieldCommentCnt: d.CommentCnt,
+		} {
+			if delta != 0 {
+				pipe.HIncrBy(ctx, keyPendingDeltas, i.deltaField(d.Biz, d.BizId, field), delta)
+			}
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (i *InteractiveRedisCache) PendingDeltas(ctx context.Context, bizs []string, bizIds []int64) (domain.PendingDeltas, error) {
+	res := domain.PendingDeltas{
+		Pending:  make([]domain.InteractiveDelta, len(bizIds)),
+		Flushing: make([]domain.InteractiveDelta, len(bizIds)),
+	}
+	fields := make([]any, 0, len(bizIds)*3)
+	for idx, bizId := range bizIds {
+		res.Pending[idx] = domain.InteractiveDelta{Biz: bizs[idx], BizId: bizId}
+		res.Flushing[idx] = domain.InteractiveDelta{Biz: bizs[idx], BizId: bizId}
+		fields = append(fields,
+			i.deltaField(bizs[idx], bizId, fieldReadCnt),
+			i.deltaField(bizs[idx], bizId, fieldLikeCnt),
+			i.deltaField(bizs[idx], bizId, fieldCommentCnt))
+	}
+	vals, err := i.client.Eval(ctx, luaPendingDeltas,
+		[]string{keyPendingDeltas, keyFlushingDeltas, keyDeltaEpoch}, fields...).StringSlice()
+	if err != nil {
+		return domain.PendingDeltas{}, err
+	}
+	res.Epoch, _ = strconv.ParseInt(vals[0], 10, 64)
+	res.Batch = vals[1]
+	pending, flushing := vals[2:2+len(fields)], vals[2+len(fields):]
+	for idx := range bizIds {
+		res.Pending[idx].ReadCnt = i.toInt64(pending[idx*3])
+		res.Pending[idx].LikeCnt = i.toInt64(pending[idx*3+1])
+		res.Pending[idx].CommentCnt = i.toInt64(pending[idx*3+2])
+		res.Flushing[idx].ReadCnt = i.toInt64(flushing[idx*3])
+		res.Flushing[idx].LikeCnt = i.toInt64(flushing[idx*3+1])
+		res.Flushing[idx].CommentCnt = i.toInt64(flushing[idx*3+2])
+	}
+	return res, nil
+}
+
+func (i *InteractiveRedisCache) TakeDeltas(ctx context.Context, batch string) (string, []domain.InteractiveDelta, error) {
+	vals, err := i.client.Eval(ctx, luaTakeDeltas,
+		[]string{keyPendingDeltas, keyFlushingDeltas, keyDeltaEpoch}, batch).StringSlice()
+	if err != nil {
+		return "", nil, err
+	}
+	var (
+		res   []domain.InteractiveDelta
+		idxes = make(map[string]int)
+	)
+	batch = ""
+	for idx := 0; idx+1 < len(vals); idx += 2 {
+		field, val := vals[idx], vals[idx+1]
+		if field == fieldBatch {
+			batch = val
+			continue
+		}
+		biz, bizId, cntField, ok := i.parseDeltaField(field)
+		if !ok {
+			continue
+		}
+		key := biz + ":" + strconv.FormatInt(bizId, 10)
+		pos, ok := idxes[key]
+		if !ok {
+			pos = len(res)
+			idxes[key] = pos
+			res = append(res, domain.InteractiveDelta{Biz: biz, BizId: bizId})
+		}
+		delta, _ := strconv.ParseInt(val, 10, 64)
+		switch cntField {
+		case fieldReadCnt:
+			res[pos].ReadCnt = delta
+		case fieldLikeCnt:
+			res[pos].LikeCnt = delta
+		case fieldCommentCnt:
+			res[pos].CommentCnt = delta
+		}
+	}
+	return batch, res, nil
+}
+
+func (i *InteractiveRedisCache) AckDeltas(ctx context.Context, batch string) error {
+	return i.client.Eval(ctx, luaAckDeltas, []string{keyFlushingDeltas}, batch).Err()
+}
+
+func (i *InteractiveRedisCache) Del(ctx context.Context, biz string, id int64) error {
+	return i.client.Del(ctx, i.key(biz, id)).Err()
+}
+
+// deltaField 比如 article:1:read_cnt
+func (i *InteractiveRedisCache) deltaField(biz string, bizId int64, field string) string {
+	return fmt.Sprintf("%s:%d:%s", biz, bizId, field)
+}
+
+func (i *InteractiveRedisCache) parseDeltaField(field string) (string, int64, string, bool) {
+	pos := strings.LastIndex(field, ":")
+	if pos < 0 {
+		return "", 0, "", false
+	}
+	cntField := field[pos+1:]
+	field = field[:pos]
+	pos = strings.LastIndex(field, ":")
+	if pos < 0 {
+		return "", 0, "", false
+	}
+	bizId, err := strconv.ParseInt(field[pos+1:], 10, 64)
+	if err != nil {
+		return "", 0, "", false
+	}
+	return field[:pos], bizId, cntField, true
+}
+
+func (i *InteractiveRedisCache) toInt64(val string) int64 {
+	res, _ := strconv.ParseInt(val, 10, 64)
+	return res
+}
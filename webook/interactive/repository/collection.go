@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/interactive/domain"
+	er "github.com/jayleonc/geektime-go/webook/interactive/error"
+	"github.com/jayleonc/geektime-go/webook/interactive/repository/cache"
+	"github.com/jayleonc/geektime-go/webook/interactive/repository/dao"
+	"time"
+)
+
+// ErrCollectionNotFound 收藏夹或者收藏不存在，或者不是这个用户的
+var ErrCollectionNotFound = er.ErrRecordNotFound
+
+type CollectionRepository interface {
+	Create(ctx context.Context, c domain.Collection) (int64, error)
+	Rename(ctx context.Context, uid, cid int64, name string) error
+	// Delete 删除收藏夹，里面的收藏也一起删掉
+	Delete(ctx context.Context, uid, cid int64) error
+	GetById(ctx context.Context, uid, cid int64) (domain.Collection, error)
+	// ListByUid 带上每个收藏夹的收藏数
+	ListByUid(ctx context.Context, uid int64, offset, limit int) ([]domain.Collection, int64, error)
+	ListItems(ctx context.Context, uid, cid int64, offset, limit int) ([]domain.CollectionItem, int64, error)
+	// ListItemsByBiz 不管在哪个收藏夹
+	ListItemsByBiz(ctx context.Context, uid int64, biz string, offset, limit int) ([]domain.CollectionItem, int64, error)
+	// RemoveItem 取消收藏，原本就没有收藏的时候什么也不做
+	RemoveItem(ctx context.Context, uid int64, biz string, bizId int64) error
+	MoveItem(ctx context.Context, uid int64, biz string, bizId, cid int64) error
+}
+
+type CachedCollectionRepository struct {
+	dao   dao.CollectionDAO
+	cache cache.InteractiveCache
+}
+
+func NewCachedCollectionRepository(dao dao.CollectionDAO, cache cache.InteractiveCache) CollectionRepository {
+	return &CachedCollectionRepository{dao: dao, cache: cache}
+}
+
+func (c *CachedCollectionRepository) Create(ctx context.Context, col domain.Collection) (int64, error) {
+	return c.dao.Insert(ctx, dao.Collection{
+		Name: col.Name,
+		Uid:  col.Uid,
+	})
+}
+
+func (c *CachedCollectionRepository) Rename(ctx context.Context, uid, cid int64, name string) error {
+	return c.dao.UpdateName(ctx, uid, cid, name)
+}
+
+func (c *CachedCollectionRepository) Delete(ctx context.Context, uid, cid int64) error {
+	items, err := c.dao.Delete(ctx, uid, cid)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		// 缓存更新失败了也只是收藏数短暂不准，等缓存过期
+		_ = c.cache.DecrCollectCntIfPresent(ctx, item.Biz, item.BizId)
+	}
+	return nil
+}
+
+func (c *CachedCollectionRepository) GetById(ctx context.Context, uid, cid int64) (domain.Collection, error) {
+	col, err := c.dao.FindById(ctx, uid, cid)
+	if err != nil {
+		return domain.Collection{}, err
+	}
+	return c.toDomain(col), nil
+}
+
+func (c *CachedCollectionRepository) ListByUid(ctx context.Context, uid int64, offset, limit int) ([]domain.Collection, int64, error) {
+	cols, count, err := c.dao.FindByUid(ctx, uid, offset, limit)
+	if err != nil || len(cols) == 0 {
+		return nil, count, err
+	}
+	cnts, err := c.dao.CountItems(ctx, uid, slice.Map(cols, func(idx int, src dao.Collection) int64 {
+		return src.Id
+	}))
+	if err != nil {
+		return nil, 0, err
+	}
+	return slice.Map(cols, func(idx int, src dao.Collection) domain.Collection {
+		res := c.toDomain(src)
+		res.ItemCnt = cnts[src.Id]
+		return res
+	}), count, nil
+}
+
+func (c *CachedCollectionRepository) ListItems(ctx context.Context, uid, cid int64, offset, limit int) ([]domain.CollectionItem, int64, error) {
+	items, count, err := c.dao.FindItems(ctx, uid, cid, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.toItems(items), count, nil
+}
+
+func (c *CachedCollectionRepository) ListItemsByBiz(ctx context.Context, uid int64, biz string, offset, limit int) ([]domain.CollectionItem, int64, error) {
+	items, count, err := c.dao.FindItemsByBiz(ctx, uid, biz, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.toItems(items), count, nil
+}
+
+func (c *CachedCollectionRepository) RemoveItem(ctx context.Context, uid int64, biz string, bizId int64) error {
+	deleted, err := c.dao.DeleteItem(ctx, uid, biz, bizId)
+	if err != nil || !deleted {
+		return err
+	}
+	return c.cache.DecrCollectCntIfPresent(ctx, biz, bizId)
+}
+
+func (c *CachedCollectionRepository) MoveItem(ctx context.Context, uid int64, biz string, bizId, cid int64) error {
+	return c.dao.MoveItem(ctx, uid, biz, bizId, cid)
+}
+
+func (c *CachedCollectionRepository) toItems(items []dao.UserCollectionBiz) []domain.CollectionItem {
+	return slice.Map(items, func(idx int, src dao.UserCollectionBiz) domain.CollectionItem {
+		return domain.CollectionItem{
+			Cid:   src.Cid,
+			Biz:   src.Biz,
+			BizId: src.BizId,
+			Ctime: time.UnixMilli(src.Ctime),
+		}
+	})
+}
+
+func (c *CachedCollectionRepository) toDomain(col dao.Collection) domain.Collection {
+	return domain.Collection{
+		Id:    col.Id,
+		Uid:   col.Uid,
+		Name:  col.Name,
+		Ctime: time.UnixMilli(col.Ctime),
+		Utime: time.UnixMilli(col.Utime),
+	}
+}
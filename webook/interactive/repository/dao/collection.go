@@ -0,0 +1,202 @@
+package dao
+
+import (
+	"context"
+	er "github.com/jayleonc/geektime-go/webook/interactive/error"
+	"gorm.io/gorm"
+	"time"
+)
+
+// CollectionDAO 收藏夹和收藏夹里面的收藏。
+// 收藏本身（UserCollectionBiz）是 InteractiveDAO.InsertCollectionBiz 插入的，这里只管查询、移动和删除
+type CollectionDAO interface {
+	Insert(ctx context.Context, c Collection) (int64, error)
+	// UpdateName 收藏夹不存在或者不是 uid 的，返回 er.ErrRecordNotFound
+	UpdateName(ctx context.Context, uid, id int64, name string) error
+	// Delete 删除收藏夹和里面所有的收藏，返回被删掉的收藏
+	Delete(ctx context.Context, uid, id int64) ([]UserCollectionBiz, error)
+	FindById(ctx context.Context, uid, id int64) (Collection, error)
+	FindByUid(ctx context.Context, uid int64, offset, limit int) ([]Collection, int64, error)
+	// CountItems 每个收藏夹里面有多少个收藏，key 是收藏夹 ID
+	CountItems(ctx context.Context, uid int64, cids []int64) (map[int64]int64, error)
+	FindItems(ctx context.Context, uid, cid int64, offset, limit int) ([]UserCollectionBiz, int64, error)
+	// FindItemsByBiz 不管在哪个收藏夹，最近收藏的在前面
+	FindItemsByBiz(ctx context.Context, uid int64, biz string, offset, limit int) ([]UserCollectionBiz, int64, error)
+	// DeleteItem 取消收藏，原本就没有收藏的时候返回 false
+	DeleteItem(ctx context.Context, uid int64, biz string, bizId int64) (bool, error)
+	// MoveItem 没有收藏的时候返回 er.ErrRecordNotFound
+	MoveItem(ctx context.Context, uid int64, biz string, bizId, cid int64) error
+}
+
+type GORMCollectionDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMCollectionDAO(db *gorm.DB) CollectionDAO {
+	return &GORMCollectionDAO{db: db}
+}
+
+func (dao *GORMCollectionDAO) Insert(ctx context.Context, c Collection) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := dao.db.WithContext(ctx).Create(&c).Error
+	return c.Id, err
+}
+
+func (dao *GORMCollectionDAO) UpdateName(ctx context.Context, uid, id int64, name string) error {
+	res := dao.db.WithContext(ctx).Model(&Collection{}).
+		Where("id = ? AND uid = ?", id, uid).
+		Updates(map[string]interface{}{
+			"name":  name,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return er.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (dao *GORMCollectionDAO) Delete(ctx context.Context, uid, id int64) ([]UserCollectionBiz, error) {
+	var items []UserCollectionBiz
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND uid = ?", id, uid).Delete(&Collection{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return er.ErrRecordNotFound
+		}
+		err := tx.Where("uid = ? AND cid = ?", uid, id).Find(&items).Error
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		err = tx.Where("uid = ? AND cid = ?", uid, id).Delete(&UserCollectionBiz{}).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now().UnixMilli()
+		for _, item := range items {
+			if err = dao.decrCollectCnt(tx, item.Biz, item.BizId, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+func (dao *GORMCollectionDAO) FindById(ctx context.Context, uid, id int64) (Collection, error) {
+	var res Collection
+	err := dao.db.WithContext(ctx).Where("id = ? AND uid = ?", id, uid).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMCollectionDAO) FindByUid(ctx context.Context, uid int64, offset, limit int) ([]Collection, int64, error) {
+	var (
+		res   []Collection
+		count int64
+	)
+	db := dao.db.WithContext(ctx).Model(&Collection{}).Where("uid = ?", uid)
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("id").Offset(offset).Limit(limit).Find(&res).Error
+	return res, count, err
+}
+
+func (dao *GORMCollectionDAO) CountItems(ctx context.Context, uid int64, cids []int64) (map[int64]int64, error) {
+	var rows []struct {
+		Cid int64
+		Cnt int64
+	}
+	err := dao.db.WithContext(ctx).Model(&UserCollectionBiz{}).
+		Select("cid, COUNT(*) AS cnt").
+		Where("uid = ? AND cid IN ?", uid, cids).
+		Group("cid").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		res[row.Cid] = row.Cnt
+	}
+	return res, nil
+}
+
+func (dao *GORMCollectionDAO) FindItems(ctx context.Context, uid, cid int64, offset, limit int) ([]UserCollectionBiz, int64, error) {
+	var (
+		res   []UserCollectionBiz
+		count int64
+	)
+	db := dao.db.WithContext(ctx).Model(&UserCollectionBiz{}).Where("uid = ? AND cid = ?", uid, cid)
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	// 最近收藏的在前面
+	err := db.Order("id DESC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, count, err
+}
+
+func (dao *GORMCollectionDAO) FindItemsByBiz(ctx context.Context, uid int64, biz string, offset, limit int) ([]UserCollectionBiz, int64, error) {
+	var (
+		res   []UserCollectionBiz
+		count int64
+	)
+	db := dao.db.WithContext(ctx).Model(&UserCollectionBiz{}).Where("uid = ? AND biz = ?", uid, biz)
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("id DESC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, count, err
+}
+
+func (dao *GORMCollectionDAO) DeleteItem(ctx context.Context, uid int64, biz string, bizId int64) (bool, error) {
+	var deleted bool
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).
+			Delete(&UserCollectionBiz{})
+		if res.Error != nil {
+			return res.Error
+		}
+		// 重复取消收藏的时候不能再减一次
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		deleted = true
+		return dao.decrCollectCnt(tx, biz, bizId, time.Now().UnixMilli())
+	})
+	return deleted, err
+}
+
+func (dao *GORMCollectionDAO) MoveItem(ctx context.Context, uid int64, biz string, bizId, cid int64) error {
+	res := dao.db.WithContext(ctx).Model(&UserCollectionBiz{}).
+		Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).
+		Updates(map[string]interface{}{
+			"cid":   cid,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return er.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (dao *GORMCollectionDAO) decrCollectCnt(tx *gorm.DB, biz string, bizId int64, now int64) error {
+	return tx.Model(&Interactive{}).
+		Where("biz = ? AND biz_id = ?", biz, bizId).
+		Updates(map[string]interface{}{
+			"collect_cnt": gorm.Expr("GREATEST(`collect_cnt` - 1, 0)"),
+			"utime":       now,
+		}).Error
+}
@@ -216,6 +216,42 @@ func (d *DoubleWriteDAO) GetCollectInfo(ctx context.Context, biz string, id int6
 	}
 }
 
+func (d *DoubleWriteDAO) GetLikeInfos(ctx context.Context, biz string, ids []int64, uid int64) ([]UserLikeBiz, error) {
+	pattern := d.pattern.Load()
+	switch pattern {
+	case PatternSrcOnly, PatternSrcFirst:
+		return d.src.GetLikeInfos(ctx, biz, ids, uid)
+	case PatternDstOnly, PatternDstFirst:
+		return d.dst.GetLikeInfos(ctx, biz, ids, uid)
+	default:
+		return nil, errUnknownPattern
+	}
+}
+
+func (d *DoubleWriteDAO) GetCollectInfos(ctx context.Context, biz string, ids []int64, uid int64) ([]UserCollectionBiz, error) {
+	pattern := d.pattern.Load()
+	switch pattern {
+	case PatternSrcOnly, PatternSrcFirst:
+		return d.src.GetCollectInfos(ctx, biz, ids, uid)
+	case PatternDstOnly, PatternDstFirst:
+		return d.dst.GetCollectInfos(ctx, biz, ids, uid)
+	default:
+		return nil, errUnknownPattern
+	}
+}
+
+func (d *DoubleWriteDAO) FindLikes(ctx context.Context, biz string, uid int64, offset, limit int) ([]UserLikeBiz, int64, error) {
+	pattern := d.pattern.Load()
+	switch pattern {
+	case PatternSrcOnly, PatternSrcFirst:
+		return d.src.FindLikes(ctx, biz, uid, offset, limit)
+	case PatternDstOnly, PatternDstFirst:
+		return d.dst.FindLikes(ctx, biz, uid, offset, limit)
+	default:
+		return nil, 0, errUnknownPattern
+	}
+}
+
 func (d *DoubleWriteDAO) Get(ctx context.Context, biz string, id int64) (Interactive, error) {
 	pattern := d.pattern.Load()
 	switch pattern {
@@ -252,6 +288,38 @@ func (d *DoubleWriteDAO) GetTopNLikedInteractive(ctx context.Context, biz string
 	}
 }
 
+func (d *DoubleWriteDAO) IncrCommentCnt(ctx context.Context, biz string, id int64, delta int64) error {
+	pattern := d.pattern.Load()
+	switch pattern {
+	case PatternSrcOnly:
+		return d.src.IncrCommentCnt(ctx, biz, id, delta)
+	case PatternSrcFirst:
+		if err := d.src.IncrCommentCnt(ctx, biz, id, delta); err != nil {
+			return err
+		}
+		if err := d.dst.IncrCommentCnt(ctx, biz, id, delta); err != nil {
+			d.l.Error("双写写入 dst 失败", logger.Error(err),
+				logger.String("biz", biz),
+				logger.Int64("biz_id", id))
+		}
+		return nil
+	case PatternDstFirst:
+		if err := d.dst.IncrCommentCnt(ctx, biz, id, delta); err != nil {
+			return err
+		}
+		if err := d.src.IncrCommentCnt(ctx, biz, id, delta); err != nil {
+			d.l.Error("双写写入 src 失败", logger.Error(err),
+				logger.String("biz", biz),
+				logger.Int64("biz_id", id))
+		}
+		return nil
+	case PatternDstOnly:
+		return d.dst.IncrCommentCnt(ctx, biz, id, delta)
+	default:
+		return errUnknownPattern
+	}
+}
+
 const (
 	PatternSrcOnly  = "src_only"
 	PatternSrcFirst = "src_first"
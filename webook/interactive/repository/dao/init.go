@@ -9,5 +9,8 @@ func InitTables(db *gorm.DB) error {
 		&UserCollectionBiz{},
 		&Interactive{},
 		&Collection{},
+		&UserReactionBiz{},
+		&InteractiveReaction{},
+		&InteractiveFlushLog{},
 	)
 }
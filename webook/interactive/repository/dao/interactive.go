@@ -17,10 +17,17 @@ type InteractiveDAO interface {
 	InsertCollectionBiz(ctx context.Context, biz string, id int64, cid int64, uid int64) error
 	GetLikeInfo(ctx context.Context, biz string, id int64, uid int64) (UserLikeBiz, error)
 	GetCollectInfo(ctx context.Context, biz string, id int64, uid int64) (UserCollectionBiz, error)
+	// GetLikeInfos 批量查询 uid 有没有点赞，只返回点了赞的
+	GetLikeInfos(ctx context.Context, biz string, ids []int64, uid int64) ([]UserLikeBiz, error)
+	GetCollectInfos(ctx context.Context, biz string, ids []int64, uid int64) ([]UserCollectionBiz, error)
+	// FindLikes uid 点过赞的，最近点赞的在前面
+	FindLikes(ctx context.Context, biz string, uid int64, offset, limit int) ([]UserLikeBiz, int64, error)
 	Get(ctx context.Context, biz string, id int64) (Interactive, error)
 	GetByIds(ctx context.Context, biz string, ids []int64) ([]Interactive, error)
 	// GetTopNLikedInteractive 得到点赞数前 N 的 文章Id
 	GetTopNLikedInteractive(ctx context.Context, biz string, n int) ([]Interactive, error)
+	// IncrCommentCnt 评论数加上 delta，delta 可以是负数，结果不会小于 0
+	IncrCommentCnt(ctx context.Context, biz string, id int64, delta int64) error
 }
 
 type GORMInteractiveDAO struct {
@@ -75,6 +82,37 @@ func (dao *GORMInteractiveDAO) GetCollectInfo(ctx context.Context, biz string, i
 	return res, err
 }
 
+func (dao *GORMInteractiveDAO) GetLikeInfos(ctx context.Context, biz string, ids []int64, uid int64) ([]UserLikeBiz, error) {
+	var res []UserLikeBiz
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND biz = ? AND biz_id IN ? AND status = ?", uid, biz, ids, 1).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) GetCollectInfos(ctx context.Context, biz string, ids []int64, uid int64) ([]UserCollectionBiz, error) {
+	var res []UserCollectionBiz
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND biz = ? AND biz_id IN ?", uid, biz, ids).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) FindLikes(ctx context.Context, biz string, uid int64, offset, limit int) ([]UserLikeBiz, int64, error) {
+	var (
+		res   []UserLikeBiz
+		count int64
+	)
+	db := dao.db.WithContext(ctx).Model(&UserLikeBiz{}).
+		Where("uid = ? AND biz = ? AND status = ?", uid, biz, 1)
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	// 重新点赞的时候会更新 utime
+	err := db.Order("utime DESC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, count, err
+}
+
 func (dao *GORMInteractiveDAO) InsertCollectionBiz(ctx context.Context, biz string, id int64, cid int64, uid int64) error {
 	now := time.Now().UnixMilli()
 	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -175,6 +213,26 @@ func (dao *GORMInteractiveDAO) IncrReadCnt(ctx context.Context, biz string, id i
 	}).Error
 }
 
+func (dao *GORMInteractiveDAO) IncrCommentCnt(ctx context.Context, biz string, id int64, delta int64) error {
+	now := time.Now().UnixMilli()
+	cnt := delta
+	if cnt < 0 {
+		cnt = 0
+	}
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"comment_cnt": gorm.Expr("GREATEST(`comment_cnt` + ?, 0)", delta),
+			"utime":       now,
+		}),
+	}).Create(&Interactive{
+		Biz:        biz,
+		BizId:      id,
+		CommentCnt: cnt,
+		Ctime:      now,
+		Utime:      now,
+	}).Error
+}
+
 func (dao *GORMInteractiveDAO) BatchIncrReadCnt(ctx context.Context, bizs []string, ids []int64) error {
 	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		txDao := NewGORMInteractiveDAO(tx)
@@ -205,6 +263,7 @@ type Interactive struct {
 	ReadCnt    int64
 	LikeCnt    int64
 	CollectCnt int64
+	CommentCnt int64
 	Ctime      int64
 	Utime      int64
 }
@@ -222,8 +281,8 @@ type UserCollectionBiz struct {
 // Collection 收藏夹
 type Collection struct {
 	Id   int64  `gorm:"primaryKey,autoIncrement"`
-	Name string `gorm:"type=varchar(1024)"`
-	Uid  int64  `gorm:""`
+	Name string `gorm:"type:varchar(1024)"`
+	Uid  int64  `gorm:"index"`
 
 	Ctime int64
 	Utime int64
@@ -0,0 +1,229 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/interactive/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ReactionDAO 表情。一个用户对一个资源只能有一个表情：
+// 点赞还是记在 UserLikeBiz 和 Interactive.LikeCnt，别的表情记在 UserReactionBiz 和 InteractiveReaction
+type ReactionDAO interface {
+	// React 换成 reaction，返回原来的表情，原来没有的时候是空字符串
+	React(ctx context.Context, biz string, bizId, uid int64, reaction string) (string, error)
+	// Unreact 取消表情，返回被取消的表情。
+	// reaction 不是空字符串的时候，只有当前的表情是 reaction 才取消
+	Unreact(ctx context.Context, biz string, bizId, uid int64, reaction string) (string, error)
+	// GetReaction 没有的时候返回空字符串
+	GetReaction(ctx context.Context, biz string, bizId, uid int64) (string, error)
+	// GetReactionCnts 点赞之外每个表情的数量
+	GetReactionCnts(ctx context.Context, biz string, bizId int64) (map[string]int64, error)
+}
+
+type GORMReactionDAO struct {
+	db *gorm.DB
+	// write-behind 模式下点赞数由 repository 攒着一起写，这里只改点赞记录
+	skipLikeCnt bool
+}
+
+func NewGORMReactionDAO(db *gorm.DB, cfg domain.WriteBehindConfig) ReactionDAO {
+	return &GORMReactionDAO{db: db, skipLikeCnt: cfg.Enabled}
+}
+
+func (dao *GORMReactionDAO) React(ctx context.Context, biz string, bizId, uid int64, reaction string) (string, error) {
+	var old string
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		old, err = dao.current(tx, biz, bizId, uid, true)
+		if err != nil || old == reaction {
+			return err
+		}
+		now := time.Now().UnixMilli()
+		if old != "" {
+			if err = dao.remove(tx, biz, bizId, uid, old, now); err != nil {
+				return err
+			}
+		}
+		return dao.add(tx, biz, bizId, uid, reaction, now)
+	})
+	return old, err
+}
+
+func (dao *GORMReactionDAO) Unreact(ctx context.Context, biz string, bizId, uid int64, reaction string) (string, error) {
+	var old string
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		old, err = dao.current(tx, biz, bizId, uid, true)
+		if err != nil || old == "" {
+			return err
+		}
+		if reaction != "" && old != reaction {
+			old = ""
+			return nil
+		}
+		return dao.remove(tx, biz, bizId, uid, old, time.Now().UnixMilli())
+	})
+	return old, err
+}
+
+func (dao *GORMReactionDAO) GetReaction(ctx context.Context, biz string, bizId, uid int64) (string, error) {
+	return dao.current(dao.db.WithContext(ctx), biz, bizId, uid, false)
+}
+
+func (dao *GORMReactionDAO) GetReactionCnts(ctx context.Context, biz string, bizId int64) (map[string]int64, error) {
+	var rows []InteractiveReaction
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ? AND cnt > 0", biz, bizId).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		res[row.Reaction] = row.Cnt
+	}
+	return res, nil
+}
+
+// current 当前的表情。lock 的时候锁住记录，避免并发换表情的时候计数不对
+func (dao *GORMReactionDAO) current(db *gorm.DB, biz string, bizId, uid int64, lock bool) (string, error) {
+	query := func() *gorm.DB {
+		if lock {
+			return db.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		return db
+	}
+	var ur UserReactionBiz
+	err := query().Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).
+		First(&ur).Error
+	switch {
+	case err == nil:
+		return ur.Reaction, nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return "", err
+	}
+	var ul UserLikeBiz
+	err = query().Where("uid = ? AND biz = ? AND biz_id = ? AND status = ?", uid, biz, bizId, 1).
+		First(&ul).Error
+	switch {
+	case err == nil:
+		return domain.ReactionLike, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "", nil
+	default:
+		return "", err
+	}
+}
+
+func (dao *GORMReactionDAO) add(tx *gorm.DB, biz string, bizId, uid int64, reaction string, now int64) error {
+	if reaction == domain.ReactionLike {
+		err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"utime":  now,
+				"status": 1,
+			}),
+		}).Create(&UserLikeBiz{
+			Uid:    uid,
+			Biz:    biz,
+			BizId:  bizId,
+			Status: 1,
+			Ctime:  now,
+			Utime:  now,
+		}).Error
+		if err != nil || dao.skipLikeCnt {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"like_cnt": gorm.Expr("`like_cnt` + 1"),
+				"utime":    now,
+			}),
+		}).Create(&Interactive{
+			Biz:     biz,
+			BizId:   bizId,
+			LikeCnt: 1,
+			Ctime:   now,
+			Utime:   now,
+		}).Error
+	}
+	err := tx.Create(&UserReactionBiz{
+		Uid:      uid,
+		Biz:      biz,
+		BizId:    bizId,
+		Reaction: reaction,
+		Ctime:    now,
+		Utime:    now,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"cnt":   gorm.Expr("`cnt` + 1"),
+			"utime": now,
+		}),
+	}).Create(&InteractiveReaction{
+		Biz:      biz,
+		BizId:    bizId,
+		Reaction: reaction,
+		Cnt:      1,
+		Ctime:    now,
+		Utime:    now,
+	}).Error
+}
+
+func (dao *GORMReactionDAO) remove(tx *gorm.DB, biz string, bizId, uid int64, reaction string, now int64) error {
+	if reaction == domain.ReactionLike {
+		err := tx.Model(&UserLikeBiz{}).
+			Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).
+			Updates(map[string]interface{}{
+				"utime":  now,
+				"status": 0,
+			}).Error
+		if err != nil || dao.skipLikeCnt {
+			return err
+		}
+		return tx.Model(&Interactive{}).
+			Where("biz = ? AND biz_id = ?", biz, bizId).
+			Updates(map[string]interface{}{
+				"like_cnt": gorm.Expr("GREATEST(`like_cnt` - 1, 0)"),
+				"utime":    now,
+			}).Error
+	}
+	err := tx.Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).
+		Delete(&UserReactionBiz{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&InteractiveReaction{}).
+		Where("biz = ? AND biz_id = ? AND reaction = ?", biz, bizId, reaction).
+		Updates(map[string]interface{}{
+			"cnt":   gorm.Expr("GREATEST(`cnt` - 1, 0)"),
+			"utime": now,
+		}).Error
+}
+
+// UserReactionBiz 用户对一个资源用的表情，点赞不在这里
+type UserReactionBiz struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	Uid      int64  `gorm:"uniqueIndex:uid_biz_type_id"`
+	BizId    int64  `gorm:"uniqueIndex:uid_biz_type_id"`
+	Biz      string `gorm:"type:varchar(32);uniqueIndex:uid_biz_type_id"`
+	Reaction string `gorm:"type:varchar(32)"`
+	Ctime    int64
+	Utime    int64
+}
+
+// InteractiveReaction 一个资源上点赞之外每个表情的数量
+type InteractiveReaction struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	BizId    int64  `gorm:"uniqueIndex:biz_type_id_reaction"`
+	Biz      string `gorm:"type:varchar(128);uniqueIndex:biz_type_id_reaction"`
+	Reaction string `gorm:"type:varchar(32);uniqueIndex:biz_type_id_reaction"`
+	Cnt      int64
+	Ctime    int64
+	Utime    int64
+}
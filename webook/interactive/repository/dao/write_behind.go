@@ -0,0 +1,150 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// InteractiveCntDAO write-behind 模式下把攒下来的计数写到数据库，还有点赞数的对账
+type InteractiveCntDAO interface {
+	// ApplyDeltas deltas 里面的计数是增量。同一个 batch 只会生效一次，
+	// 重放已经写进去的 batch 什么也不做
+	ApplyDeltas(ctx context.Context, batch string, deltas []Interactive) error
+	// FindLikeCnts utime 之后有人点赞或者取消点赞的资源，
+	// 和它们记下来的点赞数、按照点赞记录算出来的点赞数。
+	// applied 是 batch 有没有写进去，和点赞数是同一个事务里面读的
+	FindLikeCnts(ctx context.Context, utime int64, limit int, batch string) (cnts []LikeCnt, applied bool, err error)
+	// Get 没有记录的时候返回空的计数，applied 和 FindLikeCnts 一样
+	Get(ctx context.Context, biz string, bizId int64, batch string) (intr Interactive, applied bool, err error)
+	// DeleteFlushLogs 删掉 ctime 之前的 batch 记录
+	DeleteFlushLogs(ctx context.Context, ctime int64) error
+}
+
+type GORMInteractiveCntDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMInteractiveCntDAO(db *gorm.DB) InteractiveCntDAO {
+	return &GORMInteractiveCntDAO{db: db}
+}
+
+func (dao *GORMInteractiveCntDAO) ApplyDeltas(ctx context.Context, batch string, deltas []Interactive) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&InteractiveFlushLog{Batch: batch, Ctime: now}).Error
+		if me, ok := err.(*mysql.MySQLError); ok {
+			const duplicateErr uint16 = 1062
+			if me.Number == duplicateErr {
+				// 上一次已经写进去了，只是没来得及确认
+				return nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+		for _, d := range deltas {
+			err = tx.Clauses(clause.OnConflict{
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"read_cnt":    gorm.Expr("GREATEST(`read_cnt` + ?, 0)", d.ReadCnt),
+					"like_cnt":    gorm.Expr("GREATEST(`like_cnt` + ?, 0)", d.LikeCnt),
+					"comment_cnt": gorm.Expr("GREATEST(`comment_cnt` + ?, 0)", d.CommentCnt),
+					"utime":       now,
+				}),
+			}).Create(&Interactive{
+				Biz:        d.Biz,
+				BizId:      d.BizId,
+				ReadCnt:    nonNegative(d.ReadCnt),
+				LikeCnt:    nonNegative(d.LikeCnt),
+				CommentCnt: nonNegative(d.CommentCnt),
+				Ctime:      now,
+				Utime:      now,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (dao *GORMInteractiveCntDAO) FindLikeCnts(ctx context.Context, utime int64, limit int, batch string) ([]LikeCnt, bool, error) {
+	var (
+		res     []LikeCnt
+		applied bool
+	)
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw("SELECT t.biz, t.biz_id, COALESCE(i.like_cnt, 0) AS like_cnt, "+
+			"(SELECT COUNT(*) FROM user_like_bizs u WHERE u.biz = t.biz AND u.biz_id = t.biz_id AND u.status = 1) AS actual "+
+			"FROM (SELECT DISTINCT biz, biz_id FROM user_like_bizs WHERE utime >= ? LIMIT ?) t "+
+			"LEFT JOIN interactives i ON i.biz = t.biz AND i.biz_id = t.biz_id", utime, limit).
+			Scan(&res).Error
+		if err != nil {
+			return err
+		}
+		applied, err = dao.applied(tx, batch)
+		return err
+	})
+	return res, applied, err
+}
+
+func (dao *GORMInteractiveCntDAO) Get(ctx context.Context, biz string, bizId int64, batch string) (Interactive, bool, error) {
+	var (
+		res     Interactive
+		applied bool
+	)
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("biz = ? AND biz_id = ?", biz, bizId).First(&res).Error
+		switch {
+		case err == nil:
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// 第一次的计数可能还攒着，没有写到数据库
+			res = Interactive{Biz: biz, BizId: bizId}
+		default:
+			return err
+		}
+		applied, err = dao.applied(tx, batch)
+		return err
+	})
+	return res, applied, err
+}
+
+func (dao *GORMInteractiveCntDAO) DeleteFlushLogs(ctx context.Context, ctime int64) error {
+	return dao.db.WithContext(ctx).Where("ctime < ?", ctime).Delete(&InteractiveFlushLog{}).Error
+}
+
+// applied 要在同一个事务里面查，这样和读到的计数是同一个快照
+func (dao *GORMInteractiveCntDAO) applied(tx *gorm.DB, batch string) (bool, error) {
+	if batch == "" {
+		return false, nil
+	}
+	var cnt int64
+	err := tx.Model(&InteractiveFlushLog{}).Where("batch = ?", batch).Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func nonNegative(cnt int64) int64 {
+	if cnt < 0 {
+		return 0
+	}
+	return cnt
+}
+
+// LikeCnt LikeCnt 是记下来的点赞数，Actual 是按照 UserLikeBiz 算出来的
+type LikeCnt struct {
+	Biz     string
+	BizId   int64
+	LikeCnt int64
+	Actual  int64
+}
+
+// InteractiveFlushLog 已经写到数据库的 batch，重放的时候用来去重。
+// 只有还没确认的 batch 会重放，太久之前的记录就没用了，定时删掉
+type InteractiveFlushLog struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Batch string `gorm:"type:varchar(64);uniqueIndex"`
+	Ctime int64  `gorm:"index"`
+}
@@ -9,25 +9,50 @@ import (
 	er "github.com/jayleonc/geektime-go/webook/interactive/error"
 	"github.com/jayleonc/geektime-go/webook/interactive/repository/cache"
 	"github.com/jayleonc/geektime-go/webook/interactive/repository/dao"
+	"golang.org/x/sync/errgroup"
+	"time"
 )
 
 type InteractiveRepository interface {
 	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
 	// BatchIncrReadCnt bizs 和 bizIds 长度必须一致
 	BatchIncrReadCnt(ctx context.Context, bizs []string, bizIds []int64) error
+	// AddReaders 记录读者，bizIds 和 uids 一一对应。
+	// 返回的是每一次阅读要不要算阅读数，同一个用户在 window 里面重复阅读只算一次
+	AddReaders(ctx context.Context, biz string, bizIds []int64, uids []int64, window time.Duration) ([]bool, error)
 	IncrLike(ctx context.Context, biz string, id int64, uid int64) error
 	DecrLike(ctx context.Context, biz string, id int64, uid int64) error
 	AddCollectionItem(ctx context.Context, biz string, id int64, cid int64, uid int64) error
 	Get(ctx context.Context, biz string, id int64) (domain.Interactive, error)
 	Liked(ctx context.Context, biz string, id int64, uid int64) (bool, error)
 	Collected(ctx context.Context, biz string, id int64, uid int64) (bool, error)
+	// GetUserStatus 批量查询 uid 有没有点赞、收藏，key 是 ids 里面的每一个
+	GetUserStatus(ctx context.Context, biz string, ids []int64, uid int64) (map[int64]domain.UserStatus, error)
+	ListLiked(ctx context.Context, biz string, uid int64, offset, limit int) ([]domain.LikeItem, int64, error)
 	GetByIds(ctx context.Context, biz string, ids []int64) ([]domain.Interactive, error)
 	GetTopNLikedArticles(ctx context.Context, biz string, n int) ([]domain.ArticleLike, error)
+	// IncrCommentCnt delta 可以是负数，删除评论或者审核不通过的时候减少
+	IncrCommentCnt(ctx context.Context, biz string, bizId int64, delta int64) error
+	// React 换成 reaction，原来有别的表情的时候会被替换掉
+	React(ctx context.Context, biz string, id int64, uid int64, reaction string) error
+	// Unreact reaction 不是空字符串的时候，只有当前的表情是 reaction 才取消
+	Unreact(ctx context.Context, biz string, id int64, uid int64, reaction string) error
+	Reaction(ctx context.Context, biz string, id int64, uid int64) (string, error)
+
+	// FlushCnt 把 write-behind 攒下来的计数写到数据库
+	FlushCnt(ctx context.Context) error
+	// ReconcileLikeCnt 用点赞记录核对 since 之后有人点过赞的资源的点赞数，对不上的修正过来
+	ReconcileLikeCnt(ctx context.Context, since time.Time, limit int) error
+	// CleanFlushLogs 删掉 before 之前写到数据库的 batch 记录
+	CleanFlushLogs(ctx context.Context, before time.Time) error
 }
 
 type CachedInteractiveRepository struct {
-	dao   dao.InteractiveDAO
-	cache cache.InteractiveCache
+	dao         dao.InteractiveDAO
+	reactionDAO dao.ReactionDAO
+	cntDAO      dao.InteractiveCntDAO
+	cache       cache.InteractiveCache
+	cfg         domain.WriteBehindConfig
 }
 
 func (c *CachedInteractiveRepository) GetTopNLikedArticles(ctx context.Context, biz string, n int) ([]domain.ArticleLike, error) {
@@ -71,8 +96,12 @@ func (c *CachedInteractiveRepository) GetByIds(ctx context.Context, biz string,
 	if err != nil {
 		return nil, err
 	}
+	// 读者数拿不到的时候就是 0，不影响别的计数
+	uvs, _ := c.cache.UniqueReadCnts(ctx, biz, ids)
 	return slice.Map(intrs, func(idx int, src dao.Interactive) domain.Interactive {
-		return c.toDomain(src)
+		res := c.toDomain(src)
+		res.UniqueReadCnt = uvs[src.BizId]
+		return res
 	}), nil
 }
 
@@ -108,17 +137,32 @@ func (c *CachedInteractiveRepository) DecrLike(ctx context.Context, biz string,
 }
 
 func (c *CachedInteractiveRepository) Get(ctx context.Context, biz string, id int64) (domain.Interactive, error) {
+	intr, err := c.get(ctx, biz, id)
+	if err != nil {
+		return intr, err
+	}
+	// 读者数拿不到的时候就是 0，不影响别的计数
+	uvs, _ := c.cache.UniqueReadCnts(ctx, biz, []int64{id})
+	intr.UniqueReadCnt = uvs[id]
+	return intr, nil
+}
+
+func (c *CachedInteractiveRepository) get(ctx context.Context, biz string, id int64) (domain.Interactive, error) {
 	intr, err := c.cache.Get(ctx, biz, id)
 	if err == nil {
-		return intr, nil
+		return c.withLikeReaction(intr), nil
 	}
 
-	ie, err := c.dao.Get(ctx, biz, id)
-	if err != nil {
-		return domain.Interactive{}, err
-	}
+	res, cacheable, err := c.loadCnt(ctx, biz, id)
 	if err == nil {
-		res := c.toDomain(ie)
+		res.Reactions, err = c.reactionDAO.GetReactionCnts(ctx, biz, id)
+		if err != nil {
+			return domain.Interactive{}, err
+		}
+		res = c.withLikeReaction(res)
+		if !cacheable {
+			return res, nil
+		}
 		err = c.cache.Set(ctx, biz, id, res)
 		if err != nil {
 			// 记录日志
@@ -129,6 +173,30 @@ func (c *CachedInteractiveRepository) Get(ctx context.Context, biz string, id in
 	return intr, err
 }
 
+// loadCnt write-behind 模式下还要加上没有写到数据库的计数。
+// 读的时候正好换了 batch 的话，不知道攒着的有没有算重，这个结果就不回写缓存了
+func (c *CachedInteractiveRepository) loadCnt(ctx context.Context, biz string, id int64) (domain.Interactive, bool, error) {
+	if !c.cfg.Enabled {
+		ie, err := c.dao.Get(ctx, biz, id)
+		return c.toDomain(ie), true, err
+	}
+	before, err := c.cache.PendingDeltas(ctx, []string{biz}, []int64{id})
+	if err != nil {
+		return domain.Interactive{}, false, err
+	}
+	ie, applied, err := c.cntDAO.Get(ctx, biz, id, before.Batch)
+	if err != nil {
+		return domain.Interactive{}, false, err
+	}
+	res := c.toDomain(ie)
+	pending := c.unflushed(before, applied)[0]
+	res.ReadCnt += pending.ReadCnt
+	res.LikeCnt += pending.LikeCnt
+	res.CommentCnt += pending.CommentCnt
+	after, err := c.cache.PendingDeltas(ctx, nil, nil)
+	return res, err == nil && after.Epoch == before.Epoch, nil
+}
+
 func (c *CachedInteractiveRepository) Liked(ctx context.Context, biz string, id int64, uid int64) (bool, error) {
 	_, err := c.dao.GetLikeInfo(ctx, biz, id, uid)
 	switch {
@@ -152,12 +220,123 @@ func (c *CachedInteractiveRepository) Collected(ctx context.Context, biz string,
 		return false, err
 	}
 }
-func NewCachedInteractiveRepository(dao dao.InteractiveDAO, cache cache.InteractiveCache) InteractiveRepository {
-	return &CachedInteractiveRepository{dao: dao, cache: cache}
+func (c *CachedInteractiveRepository) GetUserStatus(ctx context.Context, biz string, ids []int64, uid int64) (map[int64]domain.UserStatus, error) {
+	var (
+		eg       errgroup.Group
+		likes    []dao.UserLikeBiz
+		collects []dao.UserCollectionBiz
+	)
+	eg.Go(func() error {
+		var err error
+		likes, err = c.dao.GetLikeInfos(ctx, biz, ids, uid)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		collects, err = c.dao.GetCollectInfos(ctx, biz, ids, uid)
+		return err
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.UserStatus, len(ids))
+	for _, id := range ids {
+		res[id] = domain.UserStatus{}
+	}
+	for _, like := range likes {
+		st := res[like.BizId]
+		st.Liked = true
+		res[like.BizId] = st
+	}
+	for _, col := range collects {
+		st := res[col.BizId]
+		st.Collected = true
+		res[col.BizId] = st
+	}
+	return res, nil
+}
+
+func (c *CachedInteractiveRepository) ListLiked(ctx context.Context, biz string, uid int64, offset, limit int) ([]domain.LikeItem, int64, error) {
+	likes, count, err := c.dao.FindLikes(ctx, biz, uid, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return slice.Map(likes, func(idx int, src dao.UserLikeBiz) domain.LikeItem {
+		return domain.LikeItem{
+			Biz:   src.Biz,
+			BizId: src.BizId,
+			Ctime: time.UnixMilli(src.Utime),
+		}
+	}), count, nil
+}
+
+func NewCachedInteractiveRepository(dao dao.InteractiveDAO, reactionDAO dao.ReactionDAO, cntDAO dao.InteractiveCntDAO,
+	cache cache.InteractiveCache, cfg domain.WriteBehindConfig) InteractiveRepository {
+	return &CachedInteractiveRepository{dao: dao, reactionDAO: reactionDAO, cntDAO: cntDAO, cache: cache, cfg: cfg}
+}
+
+func (c *CachedInteractiveRepository) React(ctx context.Context, biz string, id int64, uid int64, reaction string) error {
+	old, err := c.reactionDAO.React(ctx, biz, id, uid, reaction)
+	if err != nil || old == reaction {
+		return err
+	}
+	if err = c.addLikeDelta(ctx, biz, id, old, reaction); err != nil {
+		return err
+	}
+	return c.updateReactionCache(ctx, biz, id, old, reaction)
+}
+
+func (c *CachedInteractiveRepository) Unreact(ctx context.Context, biz string, id int64, uid int64, reaction string) error {
+	old, err := c.reactionDAO.Unreact(ctx, biz, id, uid, reaction)
+	if err != nil || old == "" {
+		return err
+	}
+	if err = c.addLikeDelta(ctx, biz, id, old, ""); err != nil {
+		return err
+	}
+	return c.updateReactionCache(ctx, biz, id, old, "")
+}
+
+func (c *CachedInteractiveRepository) Reaction(ctx context.Context, biz string, id int64, uid int64) (string, error) {
+	return c.reactionDAO.GetReaction(ctx, biz, id, uid)
+}
+
+// updateReactionCache 点赞数变了的时候，Top N 的点赞数也要跟着变
+func (c *CachedInteractiveRepository) updateReactionCache(ctx context.Context, biz string, id int64, old, reaction string) error {
+	if err := c.cache.ReactIfPresent(ctx, biz, id, old, reaction); err != nil {
+		return err
+	}
+	switch {
+	case old == domain.ReactionLike:
+		return c.cache.DecrLikeCnt(ctx, biz, id, -1)
+	case reaction == domain.ReactionLike:
+		return c.cache.IncrLikeCnt(ctx, biz, id, 1)
+	}
+	return nil
+}
+
+// withLikeReaction 点赞数放进表情里面，去掉已经减到 0 的表情
+func (c *CachedInteractiveRepository) withLikeReaction(intr domain.Interactive) domain.Interactive {
+	reactions := make(map[string]int64, len(intr.Reactions)+1)
+	for reaction, cnt := range intr.Reactions {
+		if cnt > 0 {
+			reactions[reaction] = cnt
+		}
+	}
+	if intr.LikeCnt > 0 {
+		reactions[domain.ReactionLike] = intr.LikeCnt
+	}
+	intr.Reactions = reactions
+	return intr
 }
 
 func (c *CachedInteractiveRepository) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
-	err := c.dao.IncrReadCnt(ctx, biz, bizId)
+	var err error
+	if c.cfg.Enabled {
+		err = c.cache.AddDeltas(ctx, []domain.InteractiveDelta{{Biz: biz, BizId: bizId, ReadCnt: 1}})
+	} else {
+		err = c.dao.IncrReadCnt(ctx, biz, bizId)
+	}
 	if err != nil {
 		return err
 	}
@@ -167,7 +346,16 @@ func (c *CachedInteractiveRepository) IncrReadCnt(ctx context.Context, biz strin
 }
 
 func (c *CachedInteractiveRepository) BatchIncrReadCnt(ctx context.Context, bizs []string, bizIds []int64) error {
-	err := c.dao.BatchIncrReadCnt(ctx, bizs, bizIds)
+	var err error
+	if c.cfg.Enabled {
+		deltas := make([]domain.InteractiveDelta, len(bizIds))
+		for i := range bizIds {
+			deltas[i] = domain.InteractiveDelta{Biz: bizs[i], BizId: bizIds[i], ReadCnt: 1}
+		}
+		err = c.cache.AddDeltas(ctx, deltas)
+	} else {
+		err = c.dao.BatchIncrReadCnt(ctx, bizs, bizIds)
+	}
 	if err != nil {
 		return err
 	}
@@ -182,6 +370,23 @@ func (c *CachedInteractiveRepository) BatchIncrReadCnt(ctx context.Context, bizs
 	return nil
 }
 
+func (c *CachedInteractiveRepository) AddReaders(ctx context.Context, biz string, bizIds []int64, uids []int64, window time.Duration) ([]bool, error) {
+	return c.cache.AddReaders(ctx, biz, bizIds, uids, window)
+}
+
+func (c *CachedInteractiveRepository) IncrCommentCnt(ctx context.Context, biz string, bizId int64, delta int64) error {
+	var err error
+	if c.cfg.Enabled {
+		err = c.cache.AddDeltas(ctx, []domain.InteractiveDelta{{Biz: biz, BizId: bizId, CommentCnt: delta}})
+	} else {
+		err = c.dao.IncrCommentCnt(ctx, biz, bizId, delta)
+	}
+	if err != nil {
+		return err
+	}
+	return c.cache.IncrCommentCntIfPresent(ctx, biz, bizId, delta)
+}
+
 func (c *CachedInteractiveRepository) AddCollectionItem(ctx context.Context, biz string, bizId int64, cid int64, uid int64) error {
 	err := c.dao.InsertCollectionBiz(ctx, biz, bizId, cid, uid)
 	if err != nil {
@@ -196,5 +401,6 @@ func (c *CachedInteractiveRepository) toDomain(ie dao.Interactive) domain.Intera
 		ReadCnt:    ie.ReadCnt,
 		LikeCnt:    ie.LikeCnt,
 		CollectCnt: ie.CollectCnt,
+		CommentCnt: ie.CommentCnt,
 	}
 }
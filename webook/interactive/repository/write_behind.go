@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/interactive/domain"
+	"github.com/jayleonc/geektime-go/webook/interactive/repository/dao"
+	"strconv"
+	"time"
+)
+
+// FlushCnt 攒着的计数在 Redis 里面，进程挂了也不会丢。
+// 写数据库和记录 batch 在同一个事务里面，没来得及 AckDeltas 就挂了的话，下一次重放也不会多算
+func (c *CachedInteractiveRepository) FlushCnt(ctx context.Context) error {
+	batch, deltas, err := c.cache.TakeDeltas(ctx, strconv.FormatInt(time.Now().UnixNano(), 10))
+	if err != nil || batch == "" {
+		return err
+	}
+	err = c.cntDAO.ApplyDeltas(ctx, batch, c.toDeltaEntities(deltas))
+	if err != nil {
+		return err
+	}
+	return c.cache.AckDeltas(ctx, batch)
+}
+
+// ReconcileLikeCnt 点赞数应该等于数据库里面的加上还在攒着的，正在 flush 的 batch 没写进去的话也要加上。
+// 对账的时候正好换了 batch，就不知道那个 batch 有没有算进读到的点赞数里面，这一次先不修了
+func (c *CachedInteractiveRepository) ReconcileLikeCnt(ctx context.Context, since time.Time, limit int) error {
+	if !c.cfg.Enabled {
+		cnts, _, err := c.cntDAO.FindLikeCnts(ctx, since.UnixMilli(), limit, "")
+		if err != nil {
+			return err
+		}
+		return c.fixLikeCnts(ctx, cnts, make([]domain.InteractiveDelta, len(cnts)))
+	}
+	before, err := c.cache.PendingDeltas(ctx, nil, nil)
+	if err != nil {
+		return err
+	}
+	cnts, applied, err := c.cntDAO.FindLikeCnts(ctx, since.UnixMilli(), limit, before.Batch)
+	if err != nil || len(cnts) == 0 {
+		return err
+	}
+	bizs := make([]string, len(cnts))
+	bizIds := make([]int64, len(cnts))
+	for i, cnt := range cnts {
+		bizs[i], bizIds[i] = cnt.Biz, cnt.BizId
+	}
+	after, err := c.cache.PendingDeltas(ctx, bizs, bizIds)
+	if err != nil {
+		return err
+	}
+	if after.Epoch != before.Epoch || after.Batch != before.Batch {
+		return nil
+	}
+	return c.fixLikeCnts(ctx, cnts, c.unflushed(after, applied))
+}
+
+func (c *CachedInteractiveRepository) CleanFlushLogs(ctx context.Context, before time.Time) error {
+	return c.cntDAO.DeleteFlushLogs(ctx, before.UnixMilli())
+}
+
+func (c *CachedInteractiveRepository) fixLikeCnts(ctx context.Context, cnts []dao.LikeCnt, pending []domain.InteractiveDelta) error {
+	var fixes []domain.InteractiveDelta
+	for i, cnt := range cnts {
+		diff := cnt.Actual - cnt.LikeCnt - pending[i].LikeCnt
+		if diff != 0 {
+			fixes = append(fixes, domain.InteractiveDelta{Biz: cnt.Biz, BizId: cnt.BizId, LikeCnt: diff})
+		}
+	}
+	if len(fixes) == 0 {
+		return nil
+	}
+	err := c.cntDAO.ApplyDeltas(ctx, "reconcile:"+strconv.FormatInt(time.Now().UnixNano(), 10),
+		c.toDeltaEntities(fixes))
+	if err != nil {
+		return err
+	}
+	for _, fix := range fixes {
+		// 缓存直接删掉，下一次从数据库加载；Top N 的点赞数跟着修
+		if err = c.cache.Del(ctx, fix.Biz, fix.BizId); err != nil {
+			return err
+		}
+		if err = c.cache.IncrLikeCnt(ctx, fix.Biz, fix.BizId, int(fix.LikeCnt)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addLikeDelta write-behind 模式下点赞数不在 ReactionDAO 的事务里面改，攒起来一起写
+func (c *CachedInteractiveRepository) addLikeDelta(ctx context.Context, biz string, id int64, old, reaction string) error {
+	if !c.cfg.Enabled {
+		return nil
+	}
+	var delta int64
+	if old == domain.ReactionLike {
+		delta--
+	}
+	if reaction == domain.ReactionLike {
+		delta++
+	}
+	if delta == 0 {
+		return nil
+	}
+	return c.cache.AddDeltas(ctx, []domain.InteractiveDelta{{Biz: biz, BizId: id, LikeCnt: delta}})
+}
+
+// unflushed 还没有写到数据库的计数。Flushing 的 batch 已经写进去了的话就不能再算一遍
+func (c *CachedInteractiveRepository) unflushed(p domain.PendingDeltas, applied bool) []domain.InteractiveDelta {
+	res := make([]domain.InteractiveDelta, len(p.Pending))
+	copy(res, p.Pending)
+	if applied {
+		return res
+	}
+	for i, d := range p.Flushing {
+		res[i].ReadCnt += d.ReadCnt
+		res[i].LikeCnt += d.LikeCnt
+		res[i].CommentCnt += d.CommentCnt
+	}
+	return res
+}
+
+func (c *CachedInteractiveRepository) toDeltaEntities(deltas []domain.InteractiveDelta) []dao.Interactive {
+	res := make([]dao.Interactive, len(deltas))
+	for i, d := range deltas {
+		res[i] = dao.Interactive{
+			Biz:        d.Biz,
+			BizId:      d.BizId,
+			ReadCnt:    d.ReadCnt,
+			LikeCnt:    d.LikeCnt,
+			CommentCnt: d.CommentCnt,
+		}
+	}
+	return res
+}
@@ -2,23 +2,75 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/jayleonc/geektime-go/webook/interactive/domain"
 	"github.com/jayleonc/geektime-go/webook/interactive/repository"
 	"golang.org/x/sync/errgroup"
+	"time"
 )
 
+// ErrInvalidReaction 这个业务不能用这个表情
+var ErrInvalidReaction = errors.New("不支持的表情")
+
+// ErrCollectionNotFound 收藏夹不存在或者不是这个用户的，也用来表示要移动的收藏不存在
+var ErrCollectionNotFound = repository.ErrCollectionNotFound
+
 type InteractiveService interface {
 	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
+	// Like 就是 React 点赞，CancelLike 只会取消点赞，不会取消别的表情
 	Like(ctx context.Context, biz string, id, uid int64) error
 	CancelLike(ctx context.Context, biz string, id, uid int64) error
+	// React 一个用户对一个资源只有一个表情，已经有别的表情的时候会被换掉
+	React(ctx context.Context, biz string, id, uid int64, reaction string) error
+	// Unreact 取消表情，不管是哪一个
+	Unreact(ctx context.Context, biz string, id, uid int64) error
+	// Collect 收藏到 cid 收藏夹，cid 为 0 的时候放到默认收藏夹
 	Collect(ctx context.Context, biz string, bizId, cid, uid int64) error
+	// RemoveCollectionItem 取消收藏，不管在哪个收藏夹。没有收藏过的时候什么也不做
+	RemoveCollectionItem(ctx context.Context, biz string, bizId, uid int64) error
+	// MoveCollectionItem 把收藏移动到 cid 收藏夹
+	MoveCollectionItem(ctx context.Context, biz string, bizId, cid, uid int64) error
+	CreateCollection(ctx context.Context, uid int64, name string) (int64, error)
+	RenameCollection(ctx context.Context, uid, cid int64, name string) error
+	// DeleteCollection 删除收藏夹，里面的收藏也会被取消
+	DeleteCollection(ctx context.Context, uid, cid int64) error
+	// ListCollections 用户自己建的收藏夹，不包括默认收藏夹
+	ListCollections(ctx context.Context, uid int64, offset, limit int) ([]domain.Collection, int64, error)
+	ListCollectionItems(ctx context.Context, uid, cid int64, offset, limit int) ([]domain.CollectionItem, int64, error)
 	Get(ctx context.Context, biz string, id int64, uid int64) (domain.Interactive, error)
 	GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interactive, error)
 	GetTopNLikedArticles(ctx context.Context, biz string, N int) ([]domain.ArticleLike, error)
+	// GetUserStatus 列表页一次查出 uid 对每一个资源有没有点赞、收藏
+	GetUserStatus(ctx context.Context, biz string, ids []int64, uid int64) (map[int64]domain.UserStatus, error)
+	// ListLikedItems 我的点赞，最近点赞的在前面
+	ListLikedItems(ctx context.Context, biz string, uid int64, offset, limit int) ([]domain.LikeItem, int64, error)
+	// ListCollectedItems 我的收藏，所有收藏夹里面的，最近收藏的在前面
+	ListCollectedItems(ctx context.Context, biz string, uid int64, offset, limit int) ([]domain.CollectionItem, int64, error)
+	// FlushCnt 把 write-behind 攒下来的计数写到数据库，没有打开的时候什么也不做
+	FlushCnt(ctx context.Context) error
+	// ReconcileLikeCnt 核对 since 之后有人点过赞的资源的点赞数
+	ReconcileLikeCnt(ctx context.Context, since time.Time) error
+	// CleanFlushLogs 删掉 before 之前的 batch 记录，只有没确认的 batch 会重放，用不上了
+	CleanFlushLogs(ctx context.Context, before time.Time) error
 }
 
 type interactiveService struct {
-	repo repository.InteractiveRepository
+	repo      repository.InteractiveRepository
+	colRepo   repository.CollectionRepository
+	reactions domain.ReactionConfig
+}
+
+func (i *interactiveService) FlushCnt(ctx context.Context) error {
+	return i.repo.FlushCnt(ctx)
+}
+
+func (i *interactiveService) ReconcileLikeCnt(ctx context.Context, since time.Time) error {
+	// 一次最多核对 1000 个，对账的间隔比 since 短，漏掉的下一次还会核对
+	return i.repo.ReconcileLikeCnt(ctx, since, 1000)
+}
+
+func (i *interactiveService) CleanFlushLogs(ctx context.Context, before time.Time) error {
+	return i.repo.CleanFlushLogs(ctx, before)
 }
 
 func (i *interactiveService) GetTopNLikedArticles(ctx context.Context, biz string, n int) ([]domain.ArticleLike, error) {
@@ -59,19 +111,52 @@ func (i *interactiveService) Get(ctx context.Context, biz string, id int64, uid
 		intr.Collected, er = i.repo.Collected(ctx, biz, id, uid)
 		return er
 	})
+
+	eg.Go(func() error {
+		var er error
+		intr.Reaction, er = i.repo.Reaction(ctx, biz, id, uid)
+		return er
+	})
 	return intr, eg.Wait()
 }
 
+func (i *interactiveService) GetUserStatus(ctx context.Context, biz string, ids []int64, uid int64) (map[int64]domain.UserStatus, error) {
+	if len(ids) == 0 {
+		return map[int64]domain.UserStatus{}, nil
+	}
+	return i.repo.GetUserStatus(ctx, biz, ids, uid)
+}
+
+func (i *interactiveService) ListLikedItems(ctx context.Context, biz string, uid int64, offset, limit int) ([]domain.LikeItem, int64, error) {
+	return i.repo.ListLiked(ctx, biz, uid, offset, limit)
+}
+
+func (i *interactiveService) ListCollectedItems(ctx context.Context, biz string, uid int64, offset, limit int) ([]domain.CollectionItem, int64, error) {
+	return i.colRepo.ListItemsByBiz(ctx, uid, biz, offset, limit)
+}
+
 func (i *interactiveService) Like(ctx context.Context, biz string, id, uid int64) error {
-	return i.repo.IncrLike(ctx, biz, id, uid)
+	return i.repo.React(ctx, biz, id, uid, domain.ReactionLike)
 }
 
 func (i *interactiveService) CancelLike(ctx context.Context, biz string, id, uid int64) error {
-	return i.repo.DecrLike(ctx, biz, id, uid)
+	return i.repo.Unreact(ctx, biz, id, uid, domain.ReactionLike)
+}
+
+func (i *interactiveService) React(ctx context.Context, biz string, id, uid int64, reaction string) error {
+	if !i.reactions.Allowed(biz, reaction) {
+		return ErrInvalidReaction
+	}
+	return i.repo.React(ctx, biz, id, uid, reaction)
 }
 
-func NewInteractiveService(repo repository.InteractiveRepository) InteractiveService {
-	return &interactiveService{repo: repo}
+func (i *interactiveService) Unreact(ctx context.Context, biz string, id, uid int64) error {
+	return i.repo.Unreact(ctx, biz, id, uid, "")
+}
+
+func NewInteractiveService(repo repository.InteractiveRepository,
+	colRepo repository.CollectionRepository, reactions domain.ReactionConfig) InteractiveService {
+	return &interactiveService{repo: repo, colRepo: colRepo, reactions: reactions}
 }
 
 func (i *interactiveService) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
@@ -79,5 +164,54 @@ func (i *interactiveService) IncrReadCnt(ctx context.Context, biz string, bizId
 }
 
 func (i *interactiveService) Collect(ctx context.Context, biz string, bizId, cid, uid int64) error {
+	if err := i.checkCollection(ctx, uid, cid); err != nil {
+		return err
+	}
 	return i.repo.AddCollectionItem(ctx, biz, bizId, cid, uid)
 }
+
+func (i *interactiveService) RemoveCollectionItem(ctx context.Context, biz string, bizId, uid int64) error {
+	return i.colRepo.RemoveItem(ctx, uid, biz, bizId)
+}
+
+func (i *interactiveService) MoveCollectionItem(ctx context.Context, biz string, bizId, cid, uid int64) error {
+	if err := i.checkCollection(ctx, uid, cid); err != nil {
+		return err
+	}
+	return i.colRepo.MoveItem(ctx, uid, biz, bizId, cid)
+}
+
+func (i *interactiveService) CreateCollection(ctx context.Context, uid int64, name string) (int64, error) {
+	return i.colRepo.Create(ctx, domain.Collection{Uid: uid, Name: name})
+}
+
+func (i *interactiveService) RenameCollection(ctx context.Context, uid, cid int64, name string) error {
+	return i.colRepo.Rename(ctx, uid, cid, name)
+}
+
+func (i *interactiveService) DeleteCollection(ctx context.Context, uid, cid int64) error {
+	return i.colRepo.Delete(ctx, uid, cid)
+}
+
+func (i *interactiveService) ListCollections(ctx context.Context, uid int64, offset, limit int) ([]domain.Collection, int64, error) {
+	return i.colRepo.ListByUid(ctx, uid, offset, limit)
+}
+
+func (i *interactiveService) ListCollectionItems(ctx context.Context, uid, cid int64, offset, limit int) ([]domain.CollectionItem, int64, error) {
+	if err := i.checkCollection(ctx, uid, cid); err != nil {
+		return nil, 0, err
+	}
+	return i.colRepo.ListItems(ctx, uid, cid, offset, limit)
+}
+
+// checkCollection 收藏夹必须是 uid 自己的，默认收藏夹每个人都有
+func (i *interactiveService) checkCollection(ctx context.Context, uid, cid int64) error {
+	if cid == 0 {
+		return nil
+	}
+	_, err := i.colRepo.GetById(ctx, uid, cid)
+	if errors.Is(err, repository.ErrCollectionNotFound) {
+		return ErrCollectionNotFound
+	}
+	return err
+}
@@ -43,6 +43,54 @@ func (i *InteractiveClient) GetTopNLikedArticles(ctx context.Context, in *intrv1
 	return i.selectClient().GetTopNLikedArticles(ctx, in, opts...)
 }
 
+func (i *InteractiveClient) CreateCollection(ctx context.Context, in *intrv1.CreateCollectionRequest, opts ...grpc.CallOption) (*intrv1.CreateCollectionResponse, error) {
+	return i.selectClient().CreateCollection(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) RenameCollection(ctx context.Context, in *intrv1.RenameCollectionRequest, opts ...grpc.CallOption) (*intrv1.RenameCollectionResponse, error) {
+	return i.selectClient().RenameCollection(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) DeleteCollection(ctx context.Context, in *intrv1.DeleteCollectionRequest, opts ...grpc.CallOption) (*intrv1.DeleteCollectionResponse, error) {
+	return i.selectClient().DeleteCollection(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) ListCollections(ctx context.Context, in *intrv1.ListCollectionsRequest, opts ...grpc.CallOption) (*intrv1.ListCollectionsResponse, error) {
+	return i.selectClient().ListCollections(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) ListCollectionItems(ctx context.Context, in *intrv1.ListCollectionItemsRequest, opts ...grpc.CallOption) (*intrv1.ListCollectionItemsResponse, error) {
+	return i.selectClient().ListCollectionItems(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) RemoveCollectionItem(ctx context.Context, in *intrv1.RemoveCollectionItemRequest, opts ...grpc.CallOption) (*intrv1.RemoveCollectionItemResponse, error) {
+	return i.selectClient().RemoveCollectionItem(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) MoveCollectionItem(ctx context.Context, in *intrv1.MoveCollectionItemRequest, opts ...grpc.CallOption) (*intrv1.MoveCollectionItemResponse, error) {
+	return i.selectClient().MoveCollectionItem(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) GetUserStatus(ctx context.Context, in *intrv1.GetUserStatusRequest, opts ...grpc.CallOption) (*intrv1.GetUserStatusResponse, error) {
+	return i.selectClient().GetUserStatus(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) ListLikedItems(ctx context.Context, in *intrv1.ListLikedItemsRequest, opts ...grpc.CallOption) (*intrv1.ListLikedItemsResponse, error) {
+	return i.selectClient().ListLikedItems(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) ListCollectedItems(ctx context.Context, in *intrv1.ListCollectedItemsRequest, opts ...grpc.CallOption) (*intrv1.ListCollectedItemsResponse, error) {
+	return i.selectClient().ListCollectedItems(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) React(ctx context.Context, in *intrv1.ReactRequest, opts ...grpc.CallOption) (*intrv1.ReactResponse, error) {
+	return i.selectClient().React(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) Unreact(ctx context.Context, in *intrv1.UnreactRequest, opts ...grpc.CallOption) (*intrv1.UnreactResponse, error) {
+	return i.selectClient().Unreact(ctx, in, opts...)
+}
+
 func NewInteractiveClient(remote intrv1.InteractiveServiceClient, local intrv1.InteractiveServiceClient) *InteractiveClient {
 	return &InteractiveClient{
 		remote:    remote,
@@ -2,10 +2,14 @@ package client
 
 import (
 	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
 	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
 	"github.com/jayleonc/geektime-go/webook/interactive/domain"
 	"github.com/jayleonc/geektime-go/webook/interactive/service"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type LocalInteractiveServiceAdapter struct {
@@ -33,7 +37,135 @@ func (l *LocalInteractiveServiceAdapter) CancelLike(ctx context.Context, in *int
 
 func (l *LocalInteractiveServiceAdapter) Collect(ctx context.Context, in *intrv1.CollectRequest, opts ...grpc.CallOption) (*intrv1.CollectResponse, error) {
 	err := l.svc.Collect(ctx, in.GetBiz(), in.GetBizId(), in.GetCid(), in.GetUid())
-	return &intrv1.CollectResponse{}, err
+	return &intrv1.CollectResponse{}, l.toStatusErr(err)
+}
+
+func (l *LocalInteractiveServiceAdapter) CreateCollection(ctx context.Context, in *intrv1.CreateCollectionRequest, opts ...grpc.CallOption) (*intrv1.CreateCollectionResponse, error) {
+	cid, err := l.svc.CreateCollection(ctx, in.GetUid(), in.GetName())
+	return &intrv1.CreateCollectionResponse{Cid: cid}, err
+}
+
+func (l *LocalInteractiveServiceAdapter) RenameCollection(ctx context.Context, in *intrv1.RenameCollectionRequest, opts ...grpc.CallOption) (*intrv1.RenameCollectionResponse, error) {
+	err := l.svc.RenameCollection(ctx, in.GetUid(), in.GetCid(), in.GetName())
+	return &intrv1.RenameCollectionResponse{}, l.toStatusErr(err)
+}
+
+func (l *LocalInteractiveServiceAdapter) DeleteCollection(ctx context.Context, in *intrv1.DeleteCollectionRequest, opts ...grpc.CallOption) (*intrv1.DeleteCollectionResponse, error) {
+	err := l.svc.DeleteCollection(ctx, in.GetUid(), in.GetCid())
+	return &intrv1.DeleteCollectionResponse{}, l.toStatusErr(err)
+}
+
+func (l *LocalInteractiveServiceAdapter) ListCollections(ctx context.Context, in *intrv1.ListCollectionsRequest, opts ...grpc.CallOption) (*intrv1.ListCollectionsResponse, error) {
+	cols, total, err := l.svc.ListCollections(ctx, in.GetUid(), int(in.GetOffset()), int(in.GetLimit()))
+	if err != nil {
+		return nil, err
+	}
+	return &intrv1.ListCollectionsResponse{
+		Collections: slice.Map(cols, func(idx int, src domain.Collection) *intrv1.Collection {
+			return &intrv1.Collection{
+				Id:      src.Id,
+				Uid:     src.Uid,
+				Name:    src.Name,
+				ItemCnt: src.ItemCnt,
+				Ctime:   src.Ctime.UnixMilli(),
+				Utime:   src.Utime.UnixMilli(),
+			}
+		}),
+		Total: total,
+	}, nil
+}
+
+func (l *LocalInteractiveServiceAdapter) ListCollectionItems(ctx context.Context, in *intrv1.ListCollectionItemsRequest, opts ...grpc.CallOption) (*intrv1.ListCollectionItemsResponse, error) {
+	items, total, err := l.svc.ListCollectionItems(ctx, in.GetUid(), in.GetCid(), int(in.GetOffset()), int(in.GetLimit()))
+	if err != nil {
+		return nil, l.toStatusErr(err)
+	}
+	return &intrv1.ListCollectionItemsResponse{
+		Items: slice.Map(items, l.toCollectionItemDTO),
+		Total: total,
+	}, nil
+}
+
+func (l *LocalInteractiveServiceAdapter) RemoveCollectionItem(ctx context.Context, in *intrv1.RemoveCollectionItemRequest, opts ...grpc.CallOption) (*intrv1.RemoveCollectionItemResponse, error) {
+	err := l.svc.RemoveCollectionItem(ctx, in.GetBiz(), in.GetBizId(), in.GetUid())
+	return &intrv1.RemoveCollectionItemResponse{}, err
+}
+
+func (l *LocalInteractiveServiceAdapter) MoveCollectionItem(ctx context.Context, in *intrv1.MoveCollectionItemRequest, opts ...grpc.CallOption) (*intrv1.MoveCollectionItemResponse, error) {
+	err := l.svc.MoveCollectionItem(ctx, in.GetBiz(), in.GetBizId(), in.GetCid(), in.GetUid())
+	return &intrv1.MoveCollectionItemResponse{}, l.toStatusErr(err)
+}
+
+func (l *LocalInteractiveServiceAdapter) GetUserStatus(ctx context.Context, in *intrv1.GetUserStatusRequest, opts ...grpc.CallOption) (*intrv1.GetUserStatusResponse, error) {
+	res, err := l.svc.GetUserStatus(ctx, in.GetBiz(), in.GetIds(), in.GetUid())
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[int64]*intrv1.UserStatus, len(res))
+	for id, st := range res {
+		statuses[id] = &intrv1.UserStatus{Liked: st.Liked, Collected: st.Collected}
+	}
+	return &intrv1.GetUserStatusResponse{Statuses: statuses}, nil
+}
+
+func (l *LocalInteractiveServiceAdapter) ListLikedItems(ctx context.Context, in *intrv1.ListLikedItemsRequest, opts ...grpc.CallOption) (*intrv1.ListLikedItemsResponse, error) {
+	items, total, err := l.svc.ListLikedItems(ctx, in.GetBiz(), in.GetUid(),
+		int(in.GetOffset()), int(in.GetLimit()))
+	if err != nil {
+		return nil, err
+	}
+	return &intrv1.ListLikedItemsResponse{
+		Items: slice.Map(items, func(idx int, src domain.LikeItem) *intrv1.LikeItem {
+			return &intrv1.LikeItem{
+				Biz:   src.Biz,
+				BizId: src.BizId,
+				Ctime: src.Ctime.UnixMilli(),
+			}
+		}),
+		Total: total,
+	}, nil
+}
+
+func (l *LocalInteractiveServiceAdapter) ListCollectedItems(ctx context.Context, in *intrv1.ListCollectedItemsRequest, opts ...grpc.CallOption) (*intrv1.ListCollectedItemsResponse, error) {
+	items, total, err := l.svc.ListCollectedItems(ctx, in.GetBiz(), in.GetUid(),
+		int(in.GetOffset()), int(in.GetLimit()))
+	if err != nil {
+		return nil, err
+	}
+	return &intrv1.ListCollectedItemsResponse{
+		Items: slice.Map(items, l.toCollectionItemDTO),
+		Total: total,
+	}, nil
+}
+
+func (l *LocalInteractiveServiceAdapter) toCollectionItemDTO(idx int, src domain.CollectionItem) *intrv1.CollectionItem {
+	return &intrv1.CollectionItem{
+		Cid:   src.Cid,
+		Biz:   src.Biz,
+		BizId: src.BizId,
+		Ctime: src.Ctime.UnixMilli(),
+	}
+}
+
+// toStatusErr 和远程调用返回一样的错误，调用方不用区分是不是本地调用
+func (l *LocalInteractiveServiceAdapter) toStatusErr(err error) error {
+	switch {
+	case errors.Is(err, service.ErrCollectionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidReaction):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
+func (l *LocalInteractiveServiceAdapter) React(ctx context.Context, in *intrv1.ReactRequest, opts ...grpc.CallOption) (*intrv1.ReactResponse, error) {
+	err := l.svc.React(ctx, in.GetBiz(), in.GetBizId(), in.GetUid(), in.GetReaction())
+	return &intrv1.ReactResponse{}, l.toStatusErr(err)
+}
+
+func (l *LocalInteractiveServiceAdapter) Unreact(ctx context.Context, in *intrv1.UnreactRequest, opts ...grpc.CallOption) (*intrv1.UnreactResponse, error) {
+	err := l.svc.Unreact(ctx, in.GetBiz(), in.GetBizId(), in.GetUid())
+	return &intrv1.UnreactResponse{}, err
 }
 
 func (l *LocalInteractiveServiceAdapter) Get(ctx context.Context, in *intrv1.GetRequest, opts ...grpc.CallOption) (*intrv1.GetResponse, error) {
@@ -81,8 +213,13 @@ func (l *LocalInteractiveServiceAdapter) toDTO(intr domain.Interactive) *intrv1.
 		ReadCnt:    intr.ReadCnt,
 		LikeCnt:    intr.LikeCnt,
 		CollectCnt: intr.CollectCnt,
+		CommentCnt: intr.CommentCnt,
 		Liked:      intr.Liked,
 		Collected:  intr.Collected,
+		Reactions:  intr.Reactions,
+		Reaction:   intr.Reaction,
+
+		UniqueReadCnt: intr.UniqueReadCnt,
 	}
 }
 
@@ -0,0 +1,14 @@
+package domain
+
+// ArticleImportResult 导入 Markdown 的结果，一个文件失败了不影响别的文件
+type ArticleImportResult struct {
+	// Ids 导入成功的草稿
+	Ids      []int64
+	Failures []ArticleImportFailure
+}
+
+type ArticleImportFailure struct {
+	// Name zip 里面的文件名
+	Name   string
+	Reason string
+}
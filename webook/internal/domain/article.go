@@ -8,8 +8,18 @@ type Article struct {
 	Content string
 	Author  Author
 	Status  ArticleStatus
-	Ctime   time.Time
-	Utime   time.Time
+	// Tags 文章的标签，nil 表示不修改标签
+	Tags []string
+	// PublishAt 定时发表的时间
+	PublishAt time.Time
+	// Version 草稿的版本号，保存的时候用来发现并发修改
+	Version int64
+	// ContentURL 私密文章内容的临时下载链接
+	ContentURL string
+	// Price 价格，单位是分，0 表示免费
+	Price int64
+	Ctime time.Time
+	Utime time.Time
 }
 
 type Author struct {
@@ -32,8 +42,28 @@ const (
 	ArticleStatusPublished
 	// ArticleStatusPrivate 仅自己可见
 	ArticleStatusPrivate
+	// ArticleStatusScheduled 定时发表，等待到点发表
+	ArticleStatusScheduled
+	// ArticleStatusReviewing 命中了敏感词，等待人工审核
+	ArticleStatusReviewing
 )
 
+// ArticleCursor 已发表文章按照 (Utime, Id) 倒序翻页的游标，
+// 下一页从比它旧的文章开始。零值表示从最新的文章开始
+type ArticleCursor struct {
+	Utime time.Time
+	Id    int64
+}
+
+func (c ArticleCursor) IsZero() bool {
+	return c.Utime.IsZero() && c.Id == 0
+}
+
+// Paid 付费文章，没有买过的读者只能看到摘要
+func (a *Article) Paid() bool {
+	return a.Price > 0
+}
+
 // Abstract 考虑引入 AI 生成摘要
 func (a *Article) Abstract() string {
 	str := []rune(a.Content)
@@ -43,8 +73,35 @@ func (a *Article) Abstract() string {
 	return string(str)
 }
 
+// DeletedArticle 回收站里面的文章
+type DeletedArticle struct {
+	// Article 删除之前的草稿
+	Article Article
+	// Published 删除之前有没有线上版本，恢复的时候线上版本也会一起恢复
+	Published bool
+	// Dtime 删除的时间
+	Dtime time.Time
+}
+
+// ArticleRevision 文章的某一个历史版本
+type ArticleRevision struct {
+	Id        int64
+	ArticleId int64
+	Title     string
+	Content   string
+	Author    Author
+	Status    ArticleStatus
+	Ctime     time.Time
+}
+
 // ArticleLike 点赞数前 N 的文章
 type ArticleLike struct {
 	ArticleId int64
 	LikeCnt   int64
 }
+
+// Tag 标签，以及标签下已发表的文章数
+type Tag struct {
+	Name       string
+	ArticleCnt int64
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"regexp"
+	"time"
+)
+
+// AttachmentPath 图片链接的路径前缀，后面是 Attachment.Name 或者 Attachment.ThumbName
+const AttachmentPath = "/articles/attachments/"
+
+// attachmentRefPattern 文章内容里面引用的图片，原图和缩略图都算
+var attachmentRefPattern = regexp.MustCompile(`/articles/attachments/([0-9a-f]{64})(?:_thumb)?\.(?:jpg|png|gif)`)
+
+// Attachment 文章里面用到的图片。
+// Hash 是处理之后的原图内容的哈希，内容一样的图片只存一份，链接也一样
+type Attachment struct {
+	Id       int64
+	Hash     string
+	Ext      string
+	ThumbExt string
+	// ContentType 原图的类型
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	Uploader    int64
+	Ctime       time.Time
+	// Utime 最后一次上传的时间，垃圾回收按照这个判断
+	Utime time.Time
+}
+
+// Name 原图的文件名，链接里面用这个
+func (a Attachment) Name() string {
+	return a.Hash + "." + a.Ext
+}
+
+func (a Attachment) ThumbName() string {
+	return a.Hash + "_thumb." + a.ThumbExt
+}
+
+// ExtractAttachmentHashes 找出文章内容里面引用了哪些图片，去重
+func ExtractAttachmentHashes(content string) []string {
+	matches := attachmentRefPattern.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(matches))
+	seen := make(map[string]struct{}, len(matches))
+	for _, m := range matches {
+		if _, ok := seen[m[1]]; ok {
+			continue
+		}
+		seen[m[1]] = struct{}{}
+		res = append(res, m[1])
+	}
+	return res
+}
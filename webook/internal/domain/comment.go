@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// Comment 评论。评论挂在 Biz + BizId 上，和 interactive 的模型一致。
+// 顶层评论的 RootId 和 ParentId 都是 0；回复的 RootId 是所在的顶层评论，ParentId 是直接回复的那条评论
+type Comment struct {
+	Id          int64
+	Biz         string
+	BizId       int64
+	Commentator Author
+	Content     string
+	RootId      int64
+	ParentId    int64
+	// ReplyTo 被回复的人，顶层评论没有
+	ReplyTo Author
+	Status  CommentStatus
+	Ctime   time.Time
+	Utime   time.Time
+}
+
+type CommentStatus uint8
+
+func (s CommentStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+const (
+	// CommentStatusUnknown 未知状态
+	CommentStatusUnknown CommentStatus = iota
+	// CommentStatusPending 等待审核，只有审核通过之后才会展示
+	CommentStatusPending
+	// CommentStatusApproved 审核通过
+	CommentStatusApproved
+	// CommentStatusRejected 审核不通过
+	CommentStatusRejected
+)
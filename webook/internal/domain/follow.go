@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// FollowRelation Follower 关注了 Followee
+type FollowRelation struct {
+	Id       int64
+	Follower int64
+	Followee int64
+	Ctime    time.Time
+}
+
+// FollowStatics 关注的人数和粉丝数
+type FollowStatics struct {
+	Followers int64
+	Followees int64
+}
+
+// FeedItem 关注的人发表的文章，Ftime 是进入信息流的时间，用来排序和翻页
+type FeedItem struct {
+	Article Article
+	Ftime   time.Time
+}
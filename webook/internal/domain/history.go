@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// ReadHistory 读者看过的一篇文章
+type ReadHistory struct {
+	Id  int64
+	Uid int64
+	// Article 只有 Id、标题和摘要，文章已经下线的时候只有 Id
+	Article Article
+	// Progress 读到了哪里，0 到 1 之间，用来下次打开的时候接着读
+	Progress float64
+	// Rtime 最近一次看的时间
+	Rtime time.Time
+}
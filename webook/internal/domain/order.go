@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// Order 购买付费文章的订单
+type Order struct {
+	Id int64
+	// SN 业务单号，支付网关用它来标识这一笔
+	SN  string
+	Uid int64
+	Aid int64
+	// Amount 下单时候的价格，单位是分
+	Amount int64
+	Status OrderStatus
+	// TxnId 支付网关的流水号，付款之后才有
+	TxnId string
+	// PayURL 让用户去支付的链接
+	PayURL string
+	Ctime  time.Time
+	Utime  time.Time
+}
+
+type OrderStatus uint8
+
+func (s OrderStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+const (
+	OrderStatusUnknown OrderStatus = iota
+	// OrderStatusInit 已经下单，还没有拿到支付结果
+	OrderStatusInit
+	OrderStatusPaid
+	// OrderStatusFailed 支付失败
+	OrderStatusFailed
+	// OrderStatusClosed 超时没有支付，关掉了
+	OrderStatusClosed
+)
@@ -0,0 +1,7 @@
+package domain
+
+// RelatedArticle 一篇相关的文章，Score 越大越相关
+type RelatedArticle struct {
+	Id    int64
+	Score float64
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// ArticleReview 发表的时候命中了敏感词，等待人工审核的文章
+type ArticleReview struct {
+	Id        int64
+	ArticleId int64
+	Author    Author
+	// Title 和 Content 是提交审核时候的内容
+	Title   string
+	Content string
+	// Hits 命中的敏感词
+	Hits       []string
+	Status     ReviewStatus
+	ReviewerId int64
+	// Reason 驳回的理由
+	Reason string
+	Ctime  time.Time
+	Utime  time.Time
+}
+
+type ReviewStatus uint8
+
+func (s ReviewStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+const (
+	ReviewStatusUnknown ReviewStatus = iota
+	// ReviewStatusPending 等待审核
+	ReviewStatusPending
+	// ReviewStatusApproved 审核通过，文章已经发表
+	ReviewStatusApproved
+	// ReviewStatusRejected 驳回，文章退回到草稿
+	ReviewStatusRejected
+)
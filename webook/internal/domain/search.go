@@ -0,0 +1,10 @@
+package domain
+
+// ArticleSearchHit 一条文章搜索结果
+type ArticleSearchHit struct {
+	Article Article
+	Score   float64
+	// HighlightTitle 和 HighlightSnippet 已经做了 HTML 转义，命中的部分用 <em> 标出来了
+	HighlightTitle   string
+	HighlightSnippet string
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Series 专栏，作者把一组文章按照顺序放在一起
+type Series struct {
+	Id          int64
+	Author      Author
+	Title       string
+	Description string
+	// Aids 专栏里面按照顺序排好的文章，包括还没有发表的
+	Aids  []int64
+	Ctime time.Time
+	Utime time.Time
+}
+
+// SeriesNav 一篇文章在专栏里面的上一篇和下一篇，只有已经发表的才算。
+// Prev 和 Next 只有 Id 和标题，没有的时候是零值
+type SeriesNav struct {
+	Series Series
+	Prev   Article
+	Next   Article
+}
+
+// SeriesDetail 专栏页，Articles 只有已经发表的文章，计数是这些文章加起来的
+type SeriesDetail struct {
+	Series     Series
+	Articles   []Article
+	ReadCnt    int64
+	LikeCnt    int64
+	CollectCnt int64
+	CommentCnt int64
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// ArticleShare 作者给仅自己可见的文章生成的分享链接，拿到链接的人在过期之前都能看
+type ArticleShare struct {
+	Id int64
+	// Aid 分享的文章
+	Aid int64
+	// Uid 作者
+	Uid int64
+	// Token 只有刚创建的时候有，数据库里面不保存
+	Token   string
+	Expire  time.Time
+	Revoked bool
+	Ctime   time.Time
+}
+
+// Valid 没有被撤销，也还没有过期
+func (s ArticleShare) Valid(now time.Time) bool {
+	return !s.Revoked && now.Before(s.Expire)
+}
+
+type ShareAuditAction uint8
+
+func (a ShareAuditAction) ToUint8() uint8 {
+	return uint8(a)
+}
+
+const (
+	ShareAuditUnknown ShareAuditAction = iota
+	// ShareAuditIssue 作者创建了分享链接
+	ShareAuditIssue
+	// ShareAuditRevoke 作者撤销了分享链接
+	ShareAuditRevoke
+	// ShareAuditAccess 有人通过分享链接看了文章
+	ShareAuditAccess
+	// ShareAuditDenied 签名是对的，但是链接已经撤销或者过期了
+	ShareAuditDenied
+)
+
+// ShareAudit 分享链接的审计记录
+type ShareAudit struct {
+	Id      int64
+	ShareId int64
+	Aid     int64
+	// Uid 创建和撤销的时候是作者，访问的时候是读者
+	Uid       int64
+	Action    ShareAuditAction
+	IP        string
+	UserAgent string
+	Ctime     time.Time
+}
+
+// ShareVisitor 通过分享链接访问文章的人，用来记审计
+type ShareVisitor struct {
+	Uid       int64
+	IP        string
+	UserAgent string
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// SyndicationFormat 订阅源的格式
+type SyndicationFormat string
+
+const (
+	SyndicationRSS  SyndicationFormat = "rss"
+	SyndicationAtom SyndicationFormat = "atom"
+)
+
+func (f SyndicationFormat) ContentType() string {
+	if f == SyndicationAtom {
+		return "application/atom+xml; charset=utf-8"
+	}
+	return "application/rss+xml; charset=utf-8"
+}
+
+// SyndicationDocument 渲染好的 RSS 或者 Atom 文档
+type SyndicationDocument struct {
+	Data []byte
+	// ETag 根据 Data 算出来的，带着双引号
+	ETag string
+	// Modified 最新一篇文章的更新时间，没有文章的时候是零值
+	Modified time.Time
+}
@@ -14,6 +14,24 @@ const (
 
 const (
 	// ArticleInvalidInput 文章模块的统一的错误码
-	ArticleInvalidInput        = 402001
+	ArticleInvalidInput = 402001
+	// ArticleVersionConflict 草稿已经被修改过了，Data 里面是服务端最新的草稿
+	ArticleVersionConflict = 402002
+	// ArticlePendingReview 文章命中了敏感词，需要人工审核之后才能发表，Data 里面是文章 ID
+	ArticlePendingReview       = 402003
 	ArticleInternalServerError = 502001
 )
+
+const (
+	// CommentInvalidInput 评论模块的统一的输入错误
+	CommentInvalidInput        = 403001
+	CommentInternalServerError = 503001
+)
+
+const (
+	// OrderInvalidInput 订单模块的统一的输入错误
+	OrderInvalidInput = 404001
+	// OrderArticlePurchased 已经买过这篇文章了
+	OrderArticlePurchased    = 404002
+	OrderInternalServerError = 504001
+)
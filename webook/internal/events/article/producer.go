@@ -4,12 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/IBM/sarama"
+	"strconv"
 )
 
-const ReadEventTopic = "read_article"
+const (
+	ReadEventTopic    = "read_article"
+	PublishEventTopic = "publish_article"
+)
 
 type Producer interface {
 	ProduceReadEvent(ctx context.Context, evt ReadEvent) error
+	// ProducePublishEvent 文章发表或者线上版本发生变化
+	ProducePublishEvent(ctx context.Context, evt PublishEvent) error
 }
 
 type ReadEvent struct {
@@ -17,6 +23,21 @@ type ReadEvent struct {
 	Aid int64
 }
 
+// PublishEvent 带上了线上版本的完整内容，消费者不需要再回查
+type PublishEvent struct {
+	Aid     int64
+	Uid     int64
+	Title   string
+	Content string
+	Tags    []string
+	// Price 价格，单位是分。付费文章只能按照摘要索引
+	Price int64
+	// Status 不是已发表状态的时候，消费者应该把文章下线
+	Status uint8
+	// Utime 毫秒数，消费者用来丢弃乱序到达的旧事件
+	Utime int64
+}
+
 type KafkaProducer struct {
 	producer sarama.SyncProducer
 }
@@ -36,3 +57,17 @@ func (k *KafkaProducer) ProduceReadEvent(ctx context.Context, evt ReadEvent) err
 	})
 	return err
 }
+
+func (k *KafkaProducer) ProducePublishEvent(ctx context.Context, evt PublishEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: PublishEventTopic,
+		// 同一篇文章的事件进入同一个分区，保证顺序
+		Key:   sarama.StringEncoder(strconv.FormatInt(evt.Aid, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
@@ -46,3 +46,16 @@ func (k *KafkaProducerWithMetrics) ProduceReadEvent(ctx context.Context, evt art
 
 	return err
 }
+
+func (k *KafkaProducerWithMetrics) ProducePublishEvent(ctx context.Context, evt article.PublishEvent) error {
+	startTime := time.Now()
+	err := k.Producer.ProducePublishEvent(ctx, evt)
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	duration := time.Since(startTime).Milliseconds()
+	k.durationVec.WithLabelValues(article.PublishEventTopic, status).Observe(float64(duration))
+	k.counterVec.WithLabelValues(article.PublishEventTopic, status).Inc()
+	return err
+}
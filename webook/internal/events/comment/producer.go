@@ -0,0 +1,41 @@
+package comment
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/IBM/sarama"
+)
+
+const CommentCntEventTopic = "comment_cnt"
+
+type Producer interface {
+	// ProduceCommentCntEvent 评论数变化，由 interactive 维护 CommentCnt
+	ProduceCommentCntEvent(ctx context.Context, evt CommentCntEvent) error
+}
+
+// CommentCntEvent Delta 可以是负数
+type CommentCntEvent struct {
+	Biz   string
+	BizId int64
+	Delta int64
+}
+
+type KafkaProducer struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaProducer(producer sarama.SyncProducer) Producer {
+	return &KafkaProducer{producer: producer}
+}
+
+func (k *KafkaProducer) ProduceCommentCntEvent(ctx context.Context, evt CommentCntEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: CommentCntEventTopic,
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
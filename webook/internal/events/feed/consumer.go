@@ -0,0 +1,44 @@
+package feed
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/events/article"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/saramax"
+	"time"
+)
+
+// ArticlePublishConsumer 消费文章发表事件，把文章推到粉丝的信息流里面
+type ArticlePublishConsumer struct {
+	svc    service.FeedService
+	client sarama.Client
+	l      logger.Logger
+}
+
+func NewArticlePublishConsumer(svc service.FeedService, client sarama.Client, l logger.Logger) *ArticlePublishConsumer {
+	return &ArticlePublishConsumer{svc: svc, client: client, l: l}
+}
+
+func (c *ArticlePublishConsumer) Start() error {
+	cgroup, err := sarama.NewConsumerGroupFromClient("feed", c.client)
+	if err != nil {
+		return err
+	}
+	go saramax.ConsumeLoop(cgroup, []string{article.PublishEventTopic},
+		saramax.NewHandler[article.PublishEvent](c.Consume), c.l)
+	return nil
+}
+
+func (c *ArticlePublishConsumer) Consume(msg *sarama.ConsumerMessage, evt article.PublishEvent) error {
+	// 粉丝多的时候要推好几批
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return c.svc.HandlePublish(ctx, domain.Article{
+		Id:     evt.Aid,
+		Author: domain.Author{Id: evt.Uid},
+		Status: domain.ArticleStatus(evt.Status),
+	}, time.UnixMilli(evt.Utime))
+}
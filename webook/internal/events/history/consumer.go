@@ -0,0 +1,46 @@
+package history
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"github.com/jayleonc/geektime-go/webook/internal/events/article"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/saramax"
+	"time"
+)
+
+// ReadEventConsumer 消费阅读事件，记录读者的阅读历史
+type ReadEventConsumer struct {
+	svc    service.ReadHistoryService
+	client sarama.Client
+	l      logger.Logger
+}
+
+func NewReadEventConsumer(svc service.ReadHistoryService, client sarama.Client, l logger.Logger) *ReadEventConsumer {
+	return &ReadEventConsumer{svc: svc, client: client, l: l}
+}
+
+func (c *ReadEventConsumer) Start() error {
+	cgroup, err := sarama.NewConsumerGroupFromClient("history", c.client)
+	if err != nil {
+		return err
+	}
+	go saramax.ConsumeLoop(cgroup, []string{article.ReadEventTopic},
+		saramax.NewHandler[article.ReadEvent](c.Consume), c.l)
+	return nil
+}
+
+func (c *ReadEventConsumer) Consume(msg *sarama.ConsumerMessage, evt article.ReadEvent) error {
+	if evt.Uid <= 0 {
+		return nil
+	}
+	// 用消息的时间，消费积压的时候阅读时间也是对的
+	rtime := msg.Timestamp
+	if rtime.IsZero() {
+		rtime = time.Now()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.svc.Record(ctx, evt.Uid, evt.Aid, rtime)
+}
@@ -0,0 +1,63 @@
+package search
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/events/article"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/saramax"
+	"os"
+	"time"
+)
+
+// ArticleIndexConsumer 消费文章发表事件，维护进程内的搜索索引
+type ArticleIndexConsumer struct {
+	svc    service.SearchService
+	client sarama.Client
+	l      logger.Logger
+}
+
+func NewArticleIndexConsumer(svc service.SearchService, client sarama.Client, l logger.Logger) *ArticleIndexConsumer {
+	return &ArticleIndexConsumer{svc: svc, client: client, l: l}
+}
+
+// Start 索引在每个实例自己的内存里面，所以每个实例要用自己的消费者组消费全部的事件。
+// 先开始消费再全量重建，重建期间到达的事件靠 Utime 去重
+func (c *ArticleIndexConsumer) Start() error {
+	host, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	cgroup, err := sarama.NewConsumerGroupFromClient("search_index_"+host, c.client)
+	if err != nil {
+		return err
+	}
+	go saramax.ConsumeLoop(cgroup, []string{article.PublishEventTopic},
+		saramax.NewHandler[article.PublishEvent](c.Consume), c.l)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+		defer cancel()
+		er := c.svc.Rebuild(ctx)
+		if er != nil {
+			c.l.Error("重建搜索索引失败", logger.Error(er))
+		}
+	}()
+	return nil
+}
+
+func (c *ArticleIndexConsumer) Consume(msg *sarama.ConsumerMessage, evt article.PublishEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.svc.IndexArticle(ctx, domain.Article{
+		Id:      evt.Aid,
+		Title:   evt.Title,
+		Content: evt.Content,
+		Author:  domain.Author{Id: evt.Uid},
+		Status:  domain.ArticleStatus(evt.Status),
+		Tags:    evt.Tags,
+		Price:   evt.Price,
+		Utime:   time.UnixMilli(evt.Utime),
+	})
+}
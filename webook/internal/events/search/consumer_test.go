@@ -0,0 +1,64 @@
+package search
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/events/article"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestArticleIndexConsumer_Consume(t *testing.T) {
+	// 摘要是前 128 个字符，secret 在摘要后面
+	content := strings.Repeat("free ", 30) + "secret"
+	testCases := []struct {
+		name  string
+		price int64
+
+		wantSecret int
+	}{
+		{
+			name:       "免费文章索引全文",
+			wantSecret: 1,
+		},
+		{
+			name:       "付费文章只索引摘要",
+			price:      100,
+			wantSecret: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := service.NewArticleSearchService(nil, nil, logger.NewNopLogger())
+			c := NewArticleIndexConsumer(svc, nil, logger.NewNopLogger())
+			err := c.Consume(&sarama.ConsumerMessage{}, article.PublishEvent{
+				Aid:     1,
+				Uid:     123,
+				Title:   "title",
+				Content: content,
+				Price:   tc.price,
+				Status:  domain.ArticleStatusPublished,
+				Utime:   1000,
+			})
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			_, total, err := svc.SearchArticle(ctx, "secret", 0, 10)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantSecret, total)
+
+			hits, total, err := svc.SearchArticle(ctx, "free", 0, 10)
+			require.NoError(t, err)
+			require.Equal(t, 1, total)
+			assert.Equal(t, tc.price, hits[0].Article.Price)
+			if tc.price > 0 {
+				assert.NotContains(t, hits[0].HighlightSnippet, "secret")
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package syndication
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"github.com/jayleonc/geektime-go/webook/internal/events/article"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/saramax"
+	"time"
+)
+
+// ArticlePublishConsumer 作者发表或者下线文章之后，删掉作者订阅源的缓存
+type ArticlePublishConsumer struct {
+	svc    service.SyndicationService
+	client sarama.Client
+	l      logger.Logger
+}
+
+func NewArticlePublishConsumer(svc service.SyndicationService, client sarama.Client, l logger.Logger) *ArticlePublishConsumer {
+	return &ArticlePublishConsumer{svc: svc, client: client, l: l}
+}
+
+func (c *ArticlePublishConsumer) Start() error {
+	cgroup, err := sarama.NewConsumerGroupFromClient("syndication", c.client)
+	if err != nil {
+		return err
+	}
+	go saramax.ConsumeLoop(cgroup, []string{article.PublishEventTopic},
+		saramax.NewHandler[article.PublishEvent](c.Consume), c.l)
+	return nil
+}
+
+func (c *ArticlePublishConsumer) Consume(msg *sarama.ConsumerMessage, evt article.PublishEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.svc.InvalidateAuthor(ctx, evt.Uid)
+}
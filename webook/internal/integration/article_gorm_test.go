@@ -2,17 +2,24 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
 	"github.com/jayleonc/geektime-go/webook/internal/integration/startup"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
 	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 type ArticleHandlerSuite struct {
@@ -51,7 +58,7 @@ func (s *ArticleHandlerSuite) TestArticle_Publish() {
 
 		// 预期响应
 		wantCode   int
-		wantResult Result[int64]
+		wantResult Result[vo.ArticleSaved]
 	}{
 		{
 			name: "新建帖子并发表",
@@ -82,8 +89,8 @@ func (s *ArticleHandlerSuite) TestArticle_Publish() {
 				Content: "随便试试",
 			},
 			wantCode: 200,
-			wantResult: Result[int64]{
-				Data: 1,
+			wantResult: Result[vo.ArticleSaved]{
+				Data: vo.ArticleSaved{Id: 1, Version: 1},
 				Code: http.StatusOK,
 			},
 		},
@@ -127,10 +134,11 @@ func (s *ArticleHandlerSuite) TestArticle_Publish() {
 				Id:      2,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 			wantCode: 200,
-			wantResult: Result[int64]{
-				Data: 2,
+			wantResult: Result[vo.ArticleSaved]{
+				Data: vo.ArticleSaved{Id: 2, Version: 2},
 				Code: http.StatusOK,
 			},
 		},
@@ -177,10 +185,11 @@ func (s *ArticleHandlerSuite) TestArticle_Publish() {
 				Id:      3,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 			wantCode: 200,
-			wantResult: Result[int64]{
-				Data: 3,
+			wantResult: Result[vo.ArticleSaved]{
+				Data: vo.ArticleSaved{Id: 3, Version: 2},
 				Code: http.StatusOK,
 			},
 		},
@@ -236,9 +245,10 @@ func (s *ArticleHandlerSuite) TestArticle_Publish() {
 				Id:      4,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 			wantCode: 200,
-			wantResult: Result[int64]{
+			wantResult: Result[vo.ArticleSaved]{
 				Code: 5,
 				Msg:  "系统错误",
 			},
@@ -265,8 +275,7 @@ func (s *ArticleHandlerSuite) TestArticle_Publish() {
 				return
 			}
 			// 反序列化为结果
-			// 利用泛型来限定结果必须是 int64
-			var result Result[int64]
+			var result Result[vo.ArticleSaved]
 			err = json.Unmarshal(recorder.Body.Bytes(), &result)
 			assert.NoError(t, err)
 			assert.Equal(t, tc.wantResult, result)
@@ -285,7 +294,7 @@ func (s *ArticleHandlerSuite) TestEdit() {
 		art Article
 
 		wantCode int
-		wantRes  Result[int64]
+		wantRes  Result[vo.ArticleSaved]
 	}{
 
 		{
@@ -309,9 +318,9 @@ func (s *ArticleHandlerSuite) TestEdit() {
 			},
 
 			wantCode: http.StatusOK,
-			wantRes: Result[int64]{
+			wantRes: Result[vo.ArticleSaved]{
 				Code: http.StatusOK,
-				Data: 1,
+				Data: vo.ArticleSaved{Id: 1, Version: 1},
 			},
 		},
 		{
@@ -341,17 +350,19 @@ func (s *ArticleHandlerSuite) TestEdit() {
 				assert.Equal(t, int64(123), art.AuthorId)
 				assert.Equal(t, int64(456), art.Ctime)
 				assert.Equal(t, uint8(1), art.Status)
+				assert.Equal(t, int64(2), art.Version)
 			},
 			art: Article{
 				Id:      2,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 
 			wantCode: http.StatusOK,
-			wantRes: Result[int64]{
+			wantRes: Result[vo.ArticleSaved]{
 				Code: http.StatusOK,
-				Data: 2,
+				Data: vo.ArticleSaved{Id: 2, Version: 2},
 			},
 		},
 		{
@@ -386,11 +397,38 @@ func (s *ArticleHandlerSuite) TestEdit() {
 				Id:      3,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 
 			wantCode: http.StatusOK,
-			wantRes: Result[int64]{
-				Msg: "系统错误",
+			wantRes: Result[vo.ArticleSaved]{
+				Code: errs.ArticleInternalServerError,
+				Msg:  "系统错误",
+			},
+		},
+		{
+			// 和发表一样当成冲突处理，带回服务端的草稿
+			name:   "修改文章 - 没有带版本号",
+			before: func(t *testing.T) {},
+			after: func(t *testing.T) {
+				var art dao.Article
+				err := s.db.Where("id = ?", 2).First(&art).Error
+				assert.NoError(t, err)
+				assert.Equal(t, "新的标题", art.Title)
+				assert.Equal(t, int64(2), art.Version)
+			},
+			art: Article{
+				Id:      2,
+				Title:   "没有版本号的标题",
+				Content: "新的内容",
+			},
+
+			wantCode: http.StatusOK,
+			wantRes: Result[vo.ArticleSaved]{
+				Code: errs.ArticleVersionConflict,
+				Msg:  "文章已经被修改过了",
+				// 冲突的时候 Data 是完整的草稿，这里只比较 id 和 version
+				Data: vo.ArticleSaved{Id: 2, Version: 2},
 			},
 		},
 	}
@@ -413,7 +451,7 @@ func (s *ArticleHandlerSuite) TestEdit() {
 
 			assert.Equal(t, tc.wantCode, recorder.Code)
 
-			var res Result[int64]
+			var res Result[vo.ArticleSaved]
 			err = json.NewDecoder(recorder.Body).Decode(&res)
 			assert.NoError(t, err)
 			assert.Equal(t, tc.wantRes, res)
@@ -425,6 +463,92 @@ func TestArticleHandler(t *testing.T) {
 	suite.Run(t, &ArticleHandlerSuite{})
 }
 
+// TestEditConflict 另外一个页面已经保存过了，旧版本的保存会失败，并且带回服务端的草稿
+func (s *ArticleHandlerSuite) TestEditConflict() {
+	t := s.T()
+	err := s.db.Create(dao.Article{
+		Id:       4,
+		Title:    "我的标题",
+		Content:  "我的内容",
+		AuthorId: 123,
+		Status:   1,
+		Version:  2,
+		Ctime:    456,
+		Utime:    789,
+	}).Error
+	assert.NoError(t, err)
+
+	reqBody, err := json.Marshal(Article{
+		Id:      4,
+		Title:   "新的标题",
+		Content: "新的内容",
+		Version: 1,
+	})
+	assert.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost,
+		"/articles/edit", bytes.NewReader(reqBody))
+	assert.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.server.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var res Result[vo.Article]
+	err = json.NewDecoder(recorder.Body).Decode(&res)
+	assert.NoError(t, err)
+	assert.Equal(t, errs.ArticleVersionConflict, res.Code)
+	assert.Equal(t, int64(4), res.Data.Id)
+	assert.Equal(t, "我的标题", res.Data.Title)
+	assert.Equal(t, "我的内容", res.Data.Content)
+	assert.Equal(t, int64(2), res.Data.Version)
+
+	// 数据库里面的草稿没有被覆盖
+	var art dao.Article
+	err = s.db.Where("id = ?", 4).First(&art).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "我的标题", art.Title)
+	assert.Equal(t, int64(2), art.Version)
+}
+
+// TestTopNByTag 标签热榜是定时任务算好放在缓存里面的，没有算过的标签返回空列表
+func (s *ArticleHandlerSuite) TestTopNByTag() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	rdb := startup.InitRedis()
+	err := cache.NewRankingRedisCache(rdb).SetByTag(ctx, "Go", []domain.Article{
+		{Id: 2, Title: "标题2", Content: "内容2", Author: domain.Author{Id: 123}, Status: domain.ArticleStatusPublished},
+		{Id: 1, Title: "标题1", Content: "内容1", Author: domain.Author{Id: 123}, Status: domain.ArticleStatusPublished},
+	})
+	require.NoError(t, err)
+	defer rdb.Del(ctx, "ranking:top_n:tag:Go")
+
+	testCases := []struct {
+		name    string
+		tag     string
+		wantIds []int64
+	}{
+		{name: "热门标签", tag: "Go", wantIds: []int64{2, 1}},
+		{name: "没有热榜的标签", tag: "Rust", wantIds: []int64{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/articles/pub/tag/"+tc.tag+"/top", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			s.server.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusOK, resp.Code)
+			var res Result[[]vo.Article]
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+			ids := make([]int64, 0, len(res.Data))
+			for _, art := range res.Data {
+				ids = append(ids, art.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
+
 type Result[T any] struct {
 	Code int    `json:"code"`
 	Msg  string `json:"msg"`
@@ -435,4 +559,5 @@ type Article struct {
 	Id      int64
 	Title   string
 	Content string
+	Version int64 `json:"version"`
 }
@@ -6,13 +6,19 @@ import (
 	"encoding/json"
 	"github.com/bwmarrin/snowflake"
 	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
 	"github.com/jayleonc/geektime-go/webook/internal/integration/startup"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
 	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/ioc"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -21,6 +27,8 @@ import (
 
 type ArticleMongoDBHandlerSuite struct {
 	suite.Suite
+	// db 标签还是放在数据库里面
+	db      *gorm.DB
 	mdb     *mongo.Database
 	col     *mongo.Collection
 	liveCol *mongo.Collection
@@ -28,6 +36,7 @@ type ArticleMongoDBHandlerSuite struct {
 }
 
 func (s *ArticleMongoDBHandlerSuite) SetupSuite() {
+	s.db = startup.InitDB()
 	s.mdb = startup.InitMongoDB()
 	s.col = s.mdb.Collection("articles")
 	s.liveCol = s.mdb.Collection("published_articles")
@@ -57,7 +66,7 @@ func (s *ArticleMongoDBHandlerSuite) TestArticle_Publish() {
 
 		// 预期响应
 		wantCode   int
-		wantResult Result[int64]
+		wantResult Result[vo.ArticleSaved]
 	}{
 		{
 			name: "新建帖子并发表",
@@ -96,8 +105,8 @@ func (s *ArticleMongoDBHandlerSuite) TestArticle_Publish() {
 				Content: "随便试试",
 			},
 			wantCode: 200,
-			wantResult: Result[int64]{
-				Data: 1,
+			wantResult: Result[vo.ArticleSaved]{
+				Data: vo.ArticleSaved{Id: 1, Version: 1},
 			},
 		},
 		{
@@ -115,6 +124,7 @@ func (s *ArticleMongoDBHandlerSuite) TestArticle_Publish() {
 					Status:   1,
 					Utime:    234,
 					AuthorId: 123,
+					Version:  1,
 				})
 				assert.NoError(t, err)
 			},
@@ -147,10 +157,11 @@ func (s *ArticleMongoDBHandlerSuite) TestArticle_Publish() {
 				Id:      2,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 			wantCode: 200,
-			wantResult: Result[int64]{
-				Data: 2,
+			wantResult: Result[vo.ArticleSaved]{
+				Data: vo.ArticleSaved{Id: 2, Version: 2},
 			},
 		},
 		{
@@ -166,6 +177,7 @@ func (s *ArticleMongoDBHandlerSuite) TestArticle_Publish() {
 					Status:   1,
 					Utime:    234,
 					AuthorId: 123,
+					Version:  1,
 				}
 				_, err := s.col.InsertOne(ctx, &art)
 				assert.NoError(t, err)
@@ -195,6 +207,8 @@ func (s *ArticleMongoDBHandlerSuite) TestArticle_Publish() {
 				assert.Equal(t, "新的内容", part.Content)
 				assert.Equal(t, int64(123), part.AuthorId)
 				assert.Equal(t, uint8(2), part.Status)
+				// 草稿的版本号变了，线上库的不会跟着变
+				assert.Equal(t, int64(1), part.Version)
 				// 创建时间没变
 				assert.Equal(t, int64(456), part.Ctime)
 				// 更新时间变了
@@ -204,10 +218,11 @@ func (s *ArticleMongoDBHandlerSuite) TestArticle_Publish() {
 				Id:      3,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 			wantCode: 200,
-			wantResult: Result[int64]{
-				Data: 3,
+			wantResult: Result[vo.ArticleSaved]{
+				Data: vo.ArticleSaved{Id: 3, Version: 2},
 			},
 		},
 		{
@@ -270,9 +285,10 @@ func (s *ArticleMongoDBHandlerSuite) TestArticle_Publish() {
 				Id:      4,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 			wantCode: 200,
-			wantResult: Result[int64]{
+			wantResult: Result[vo.ArticleSaved]{
 				Code: 5,
 				Msg:  "系统错误",
 			},
@@ -299,14 +315,14 @@ func (s *ArticleMongoDBHandlerSuite) TestArticle_Publish() {
 				return
 			}
 			// 反序列化为结果
-			// 利用泛型来限定结果必须是 int64
-			var result Result[int64]
+			var result Result[vo.ArticleSaved]
 			err = json.Unmarshal(recorder.Body.Bytes(), &result)
 			assert.NoError(t, err)
 			assert.NoError(t, err)
-			if tc.wantResult.Data > 0 {
+			if tc.wantResult.Data.Id > 0 {
 				// 你只能断定有 ID
-				assert.True(t, result.Data > 0)
+				assert.True(t, result.Data.Id > 0)
+				assert.Equal(t, tc.wantResult.Data.Version, result.Data.Version)
 			}
 			tc.after(t)
 		})
@@ -324,7 +340,7 @@ func (s *ArticleMongoDBHandlerSuite) TestEdit() {
 		art Article
 
 		wantCode int
-		wantRes  Result[int64]
+		wantRes  Result[vo.ArticleSaved]
 	}{
 		{
 			name:   "新建帖子",
@@ -356,9 +372,9 @@ func (s *ArticleMongoDBHandlerSuite) TestEdit() {
 				Content: "我的内容",
 			},
 			wantCode: http.StatusOK,
-			wantRes: Result[int64]{
+			wantRes: Result[vo.ArticleSaved]{
 				// 我希望你的 ID 是 1
-				Data: 1,
+				Data: vo.ArticleSaved{Id: 1, Version: 1},
 			},
 		},
 		{
@@ -373,9 +389,10 @@ func (s *ArticleMongoDBHandlerSuite) TestEdit() {
 					Content:  "我的内容",
 					AuthorId: 123,
 					// 假设这是一个已经发表了的帖子
-					Status: 2,
-					Ctime:  456,
-					Utime:  789,
+					Status:  2,
+					Version: 1,
+					Ctime:   456,
+					Utime:   789,
 				})
 				assert.NoError(t, err)
 			},
@@ -396,19 +413,21 @@ func (s *ArticleMongoDBHandlerSuite) TestEdit() {
 					Content:  "新的内容",
 					AuthorId: 123,
 					// 更新之后，是未发表状态
-					Status: 1,
-					Ctime:  456,
+					Status:  1,
+					Version: 2,
+					Ctime:   456,
 				}, art)
 			},
 			art: Article{
 				Id:      11,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 			wantCode: http.StatusOK,
-			wantRes: Result[int64]{
+			wantRes: Result[vo.ArticleSaved]{
 				// 我希望你的 ID 是 11
-				Data: 11,
+				Data: vo.ArticleSaved{Id: 11, Version: 2},
 			},
 		},
 		{
@@ -424,6 +443,7 @@ func (s *ArticleMongoDBHandlerSuite) TestEdit() {
 					// 模拟别人
 					AuthorId: 1024,
 					Status:   2,
+					Version:  1,
 					Ctime:    456,
 					Utime:    789,
 				})
@@ -443,6 +463,7 @@ func (s *ArticleMongoDBHandlerSuite) TestEdit() {
 					Content:  "我的内容",
 					AuthorId: 1024,
 					Status:   2,
+					Version:  1,
 					Ctime:    456,
 					Utime:    789,
 				}, art)
@@ -451,10 +472,12 @@ func (s *ArticleMongoDBHandlerSuite) TestEdit() {
 				Id:      22,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 			wantCode: http.StatusOK,
-			wantRes: Result[int64]{
-				Msg: "系统错误",
+			wantRes: Result[vo.ArticleSaved]{
+				Code: errs.ArticleInternalServerError,
+				Msg:  "系统错误",
 			},
 		},
 	}
@@ -481,17 +504,76 @@ func (s *ArticleMongoDBHandlerSuite) TestEdit() {
 			if tc.wantCode != http.StatusOK {
 				return
 			}
-			var res Result[int64]
+			var res Result[vo.ArticleSaved]
 			err = json.NewDecoder(recorder.Body).Decode(&res)
 			assert.NoError(t, err)
-			if tc.wantRes.Data > 0 {
+			if tc.wantRes.Data.Id > 0 {
 				// 你只能断定有 ID
-				assert.True(t, res.Data > 0)
+				assert.True(t, res.Data.Id > 0)
+				assert.Equal(t, tc.wantRes.Data.Version, res.Data.Version)
 			}
 		})
 	}
 }
 
+// TestStartup article.storage 是 mongodb 的时候，文章和标签都要能初始化
+func (s *ArticleMongoDBHandlerSuite) TestStartup() {
+	t := s.T()
+	viper.Set("article.storage", "mongodb")
+	viper.Set("article.nodeId", 1)
+	viper.Set("mongo.uri", startup.MongoURI)
+	viper.Set("mongo.database", "webook")
+	defer viper.Reset()
+	// mongodb 用不上对象存储
+	artDAO := ioc.InitArticleDAO(s.db, nil)
+	assert.IsType(t, &dao.MongoDBArticleDAO{}, artDAO)
+	assert.IsType(t, &dao.TagMongoDBDAO{}, ioc.InitTagDAO(s.db, artDAO))
+}
+
+func (s *ArticleMongoDBHandlerSuite) TestTags() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	defer func() {
+		s.db.Exec("TRUNCATE TABLE `tags`")
+		s.db.Exec("TRUNCATE TABLE `article_tags`")
+	}()
+	now := time.Now().UnixMilli()
+	_, err := s.liveCol.InsertMany(ctx, []any{
+		dao.PublishedArticle{Id: 1, Title: "标题1", AuthorId: 123, Status: 2, Utime: now - 300},
+		dao.PublishedArticle{Id: 2, Title: "标题2", AuthorId: 123, Status: 2, Utime: now - 100},
+		// 私密的不算
+		dao.PublishedArticle{Id: 3, Title: "标题3", AuthorId: 123, Status: 3, Utime: now - 200},
+	})
+	require.NoError(t, err)
+	tagDAO := dao.NewTagMongoDBDAO(s.db, dao.NewMongoDBArticleDAO(s.mdb, nil))
+	require.NoError(t, tagDAO.SetArticleTags(ctx, 1, []string{"Go", "MongoDB"}))
+	require.NoError(t, tagDAO.SetArticleTags(ctx, 2, []string{"Go"}))
+	require.NoError(t, tagDAO.SetArticleTags(ctx, 3, []string{"MongoDB", "Go"}))
+
+	req, err := http.NewRequest(http.MethodGet, "/articles/pub/tag/Go", nil)
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+	s.server.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var arts Result[[]Article]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&arts))
+	require.Len(t, arts.Data, 2)
+	assert.Equal(t, []int64{2, 1}, []int64{arts.Data[0].Id, arts.Data[1].Id})
+
+	req, err = http.NewRequest(http.MethodGet, "/articles/pub/tags", nil)
+	require.NoError(t, err)
+	resp = httptest.NewRecorder()
+	s.server.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var tags Result[[]vo.Tag]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tags))
+	assert.Equal(t, []vo.Tag{
+		{Name: "Go", ArticleCnt: 2},
+		{Name: "MongoDB", ArticleCnt: 1},
+	}, tags.Data)
+}
+
 func (s *ArticleMongoDBHandlerSuite) TearDownTest() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -504,3 +586,429 @@ func (s *ArticleMongoDBHandlerSuite) TearDownTest() {
 func TestArticleMongoDBHandler(t *testing.T) {
 	suite.Run(t, &ArticleMongoDBHandlerSuite{})
 }
+
+// ArticleMongoDBDAOSuite 直接测试 MongoDBArticleDAO 的每一个方法
+type ArticleMongoDBDAOSuite struct {
+	suite.Suite
+	mdb     *mongo.Database
+	col     *mongo.Collection
+	liveCol *mongo.Collection
+	// trashCol 回收站
+	trashCol *mongo.Collection
+	dao      dao.ArticleDAO
+}
+
+func (s *ArticleMongoDBDAOSuite) SetupSuite() {
+	s.mdb = startup.InitMongoDB()
+	s.col = s.mdb.Collection("articles")
+	s.liveCol = s.mdb.Collection("published_articles")
+	s.trashCol = s.mdb.Collection("deleted_articles")
+	err := dao.InitCollection(s.mdb)
+	require.NoError(s.T(), err)
+	node, err := snowflake.NewNode(1)
+	require.NoError(s.T(), err)
+	s.dao = dao.NewMongoDBArticleDAO(s.mdb, node)
+}
+
+func (s *ArticleMongoDBDAOSuite) TearDownTest() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := s.col.DeleteMany(ctx, bson.D{})
+	assert.NoError(s.T(), err)
+	_, err = s.liveCol.DeleteMany(ctx, bson.D{})
+	assert.NoError(s.T(), err)
+	_, err = s.trashCol.DeleteMany(ctx, bson.D{})
+	assert.NoError(s.T(), err)
+}
+
+// insert 准备数据，live 为 true 的时候同时写入线上库
+func (s *ArticleMongoDBDAOSuite) insert(live bool, arts ...dao.Article) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for _, art := range arts {
+		_, err := s.col.InsertOne(ctx, art)
+		require.NoError(s.T(), err)
+		if live {
+			_, err = s.liveCol.InsertOne(ctx, dao.PublishedArticle(art))
+			require.NoError(s.T(), err)
+		}
+	}
+}
+
+func (s *ArticleMongoDBDAOSuite) TestInsertAndGetById() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	id, err := s.dao.Insert(ctx, dao.Article{
+		Title:    "我的标题",
+		Content:  "我的内容",
+		AuthorId: 123,
+		Status:   1,
+	})
+	require.NoError(t, err)
+	assert.True(t, id > 0)
+
+	art, err := s.dao.GetById(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, art.Ctime > 0)
+	assert.Equal(t, art.Ctime, art.Utime)
+	art.Ctime, art.Utime = 0, 0
+	assert.Equal(t, dao.Article{
+		Id:       id,
+		Title:    "我的标题",
+		Content:  "我的内容",
+		AuthorId: 123,
+		Status:   1,
+		Version:  1,
+	}, art)
+
+	_, err = s.dao.GetById(ctx, id+1)
+	assert.Equal(t, dao.ErrRecordNotFound, err)
+}
+
+func (s *ArticleMongoDBDAOSuite) TestUpdateById() {
+	t := s.T()
+	s.insert(false, dao.Article{
+		Id: 1, Title: "我的标题", Content: "我的内容",
+		AuthorId: 123, Status: 1, Version: 2, Ctime: 456, Utime: 789,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// 版本不对
+	err := s.dao.UpdateById(ctx, dao.Article{
+		Id: 1, Title: "新的标题", Content: "新的内容", AuthorId: 123, Status: 1, Version: 1,
+	})
+	assert.Equal(t, dao.ErrArticleVersionConflict, err)
+	// 作者不对
+	err = s.dao.UpdateById(ctx, dao.Article{
+		Id: 1, Title: "新的标题", Content: "新的内容", AuthorId: 234, Status: 1, Version: 2,
+	})
+	assert.Error(t, err)
+	assert.NotEqual(t, dao.ErrArticleVersionConflict, err)
+
+	err = s.dao.UpdateById(ctx, dao.Article{
+		Id: 1, Title: "新的标题", Content: "新的内容", AuthorId: 123, Status: 1, Version: 2,
+	})
+	require.NoError(t, err)
+	art, err := s.dao.GetById(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, art.Utime > 789)
+	art.Utime = 0
+	assert.Equal(t, dao.Article{
+		Id: 1, Title: "新的标题", Content: "新的内容",
+		AuthorId: 123, Status: 1, Version: 3, Ctime: 456,
+	}, art)
+}
+
+func (s *ArticleMongoDBDAOSuite) TestSyncAndGetPubById() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	id, err := s.dao.Sync(ctx, dao.Article{
+		Title: "我的标题", Content: "我的内容", AuthorId: 123, Status: 2,
+	})
+	require.NoError(t, err)
+	pub, err := s.dao.GetPubById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "我的标题", pub.Title)
+	assert.Equal(t, uint8(2), pub.Status)
+	assert.True(t, pub.Ctime > 0)
+
+	// 再发表一次，线上库也跟着更新
+	_, err = s.dao.Sync(ctx, dao.Article{
+		Id: id, Title: "新的标题", Content: "新的内容", AuthorId: 123, Status: 2,
+	})
+	require.NoError(t, err)
+	pub, err = s.dao.GetPubById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "新的标题", pub.Title)
+	assert.Equal(t, "新的内容", pub.Content)
+
+	_, err = s.dao.GetPubById(ctx, id+1)
+	assert.Equal(t, dao.ErrRecordNotFound, err)
+}
+
+func (s *ArticleMongoDBDAOSuite) TestGetByAuthor() {
+	t := s.T()
+	s.insert(false,
+		dao.Article{Id: 1, AuthorId: 123, Utime: 100},
+		dao.Article{Id: 2, AuthorId: 123, Utime: 300},
+		dao.Article{Id: 3, AuthorId: 123, Utime: 200},
+		dao.Article{Id: 4, AuthorId: 234, Utime: 400},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	arts, cnt, err := s.dao.GetByAuthor(ctx, 123, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cnt)
+	assert.Equal(t, []int64{2, 3}, articleIds(arts))
+	arts, _, err = s.dao.GetByAuthor(ctx, 123, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, articleIds(arts))
+}
+
+func (s *ArticleMongoDBDAOSuite) TestFindByAuthor() {
+	t := s.T()
+	s.insert(false,
+		dao.Article{Id: 1, AuthorId: 123, Utime: 300},
+		dao.Article{Id: 2, AuthorId: 234, Utime: 200},
+		dao.Article{Id: 3, AuthorId: 123, Utime: 100},
+		dao.Article{Id: 4, AuthorId: 123, Utime: 400},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	arts, err := s.dao.FindByAuthor(ctx, 123, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, articleIds(arts))
+	arts, err = s.dao.FindByAuthor(ctx, 123, 3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4}, articleIds(arts))
+}
+
+func (s *ArticleMongoDBDAOSuite) TestSyncStatus() {
+	t := s.T()
+	s.insert(true, dao.Article{Id: 1, AuthorId: 123, Status: 2, Utime: 100})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := s.dao.SyncStatus(ctx, 234, 1, 3)
+	assert.Error(t, err)
+
+	err = s.dao.SyncStatus(ctx, 123, 1, 3)
+	require.NoError(t, err)
+	art, err := s.dao.GetById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(3), art.Status)
+	assert.True(t, art.Utime > 100)
+	pub, err := s.dao.GetPubById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(3), pub.Status)
+}
+
+func (s *ArticleMongoDBDAOSuite) TestListPub() {
+	t := s.T()
+	s.insert(true,
+		dao.Article{Id: 1, Status: 2, Utime: 100},
+		dao.Article{Id: 2, Status: 2, Utime: 300},
+		dao.Article{Id: 3, Status: 3, Utime: 200},
+		dao.Article{Id: 4, Status: 2, Utime: 200},
+		dao.Article{Id: 5, Status: 2, Utime: 500},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	arts, err := s.dao.ListPub(ctx, time.UnixMilli(400), 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 4}, pubArticleIds(arts))
+	arts, err = s.dao.ListPub(ctx, time.UnixMilli(400), 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, pubArticleIds(arts))
+}
+
+func (s *ArticleMongoDBDAOSuite) TestListPubByCursor() {
+	t := s.T()
+	s.insert(true,
+		dao.Article{Id: 1, Status: 2, Utime: 100},
+		dao.Article{Id: 2, Status: 2, Utime: 200},
+		dao.Article{Id: 3, Status: 2, Utime: 200},
+		dao.Article{Id: 4, Status: 3, Utime: 300},
+		dao.Article{Id: 5, Status: 2, Utime: 300},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	arts, err := s.dao.ListPubByCursor(ctx, 0, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5, 3}, pubArticleIds(arts))
+	// utime 一样的时候按照 id 继续往后翻
+	arts, err = s.dao.ListPubByCursor(ctx, 200, 3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, pubArticleIds(arts))
+}
+
+func (s *ArticleMongoDBDAOSuite) TestListPubByAuthor() {
+	t := s.T()
+	s.insert(true,
+		dao.Article{Id: 1, AuthorId: 123, Status: 2, Utime: 100},
+		dao.Article{Id: 2, AuthorId: 123, Status: 2, Utime: 300},
+		// 私密的不算
+		dao.Article{Id: 3, AuthorId: 123, Status: 3, Utime: 400},
+		// 别人的文章
+		dao.Article{Id: 4, AuthorId: 234, Status: 2, Utime: 500},
+		dao.Article{Id: 5, AuthorId: 123, Status: 2, Utime: 200},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	arts, err := s.dao.ListPubByAuthor(ctx, 123, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 5, 1}, pubArticleIds(arts))
+	arts, err = s.dao.ListPubByAuthor(ctx, 123, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 5}, pubArticleIds(arts))
+}
+
+func (s *ArticleMongoDBDAOSuite) TestGetByIds() {
+	t := s.T()
+	s.insert(true,
+		dao.Article{Id: 1, Status: 2},
+		dao.Article{Id: 2, Status: 3},
+		dao.Article{Id: 3, Status: 2},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	arts, err := s.dao.GetByIds(ctx, []int64{1, 2, 3, 4})
+	require.NoError(t, err)
+	ids := pubArticleIds(arts)
+	assert.ElementsMatch(t, []int64{1, 3}, ids)
+
+	arts, err = s.dao.GetByIds(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, arts)
+}
+
+func (s *ArticleMongoDBDAOSuite) TestScheduled() {
+	t := s.T()
+	s.insert(false,
+		dao.Article{Id: 1, AuthorId: 123, Status: 4, PublishAt: 300},
+		dao.Article{Id: 2, AuthorId: 123, Status: 4, PublishAt: 100},
+		dao.Article{Id: 3, AuthorId: 123, Status: 1},
+		dao.Article{Id: 4, AuthorId: 234, Status: 4, PublishAt: 200},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	arts, cnt, err := s.dao.ListScheduled(ctx, 123, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cnt)
+	assert.Equal(t, []int64{2, 1}, articleIds(arts))
+
+	arts, err = s.dao.FindDueScheduled(ctx, time.UnixMilli(200), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 4}, articleIds(arts))
+
+	err = s.dao.Reschedule(ctx, 123, 2, 500)
+	require.NoError(t, err)
+	art, err := s.dao.GetById(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), art.PublishAt)
+	// 不是定时发表的文章
+	err = s.dao.Reschedule(ctx, 123, 3, 500)
+	assert.Equal(t, dao.ErrArticleNotScheduled, err)
+
+	err = s.dao.CancelSchedule(ctx, 123, 1)
+	require.NoError(t, err)
+	art, err = s.dao.GetById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), art.Status)
+	assert.Equal(t, int64(0), art.PublishAt)
+	// 别人的文章
+	err = s.dao.CancelSchedule(ctx, 123, 4)
+	assert.Equal(t, dao.ErrArticleNotScheduled, err)
+}
+
+func (s *ArticleMongoDBDAOSuite) TestTransitStatus() {
+	t := s.T()
+	s.insert(false, dao.Article{Id: 1, AuthorId: 123, Status: 5, Utime: 100})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// 状态不对
+	err := s.dao.TransitStatus(ctx, 123, 1, 4, 1)
+	assert.Equal(t, dao.ErrArticleStatusMismatch, err)
+	// 别人的文章
+	err = s.dao.TransitStatus(ctx, 234, 1, 5, 1)
+	assert.Equal(t, dao.ErrArticleStatusMismatch, err)
+
+	err = s.dao.TransitStatus(ctx, 123, 1, 5, 1)
+	require.NoError(t, err)
+	art, err := s.dao.GetById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), art.Status)
+	assert.True(t, art.Utime > 100)
+}
+
+func (s *ArticleMongoDBDAOSuite) TestDeleteAndRestore() {
+	t := s.T()
+	s.insert(true, dao.Article{Id: 1, AuthorId: 123, Title: "发表过", Status: 2})
+	s.insert(false, dao.Article{Id: 2, AuthorId: 123, Title: "草稿", Status: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// 别人的文章
+	err := s.dao.Delete(ctx, 234, 1)
+	assert.Equal(t, dao.ErrArticleNotFound, err)
+
+	require.NoError(t, s.dao.Delete(ctx, 123, 1))
+	require.NoError(t, s.dao.Delete(ctx, 123, 2))
+	_, err = s.dao.GetById(ctx, 1)
+	assert.Equal(t, dao.ErrRecordNotFound, err)
+	_, err = s.dao.GetPubById(ctx, 1)
+	assert.Equal(t, dao.ErrRecordNotFound, err)
+
+	deleted, cnt, err := s.dao.ListDeleted(ctx, 123, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cnt)
+	assert.Len(t, deleted, 2)
+	deleted, cnt, err = s.dao.ListDeleted(ctx, 234, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), cnt)
+
+	// 别人不能恢复
+	_, err = s.dao.Restore(ctx, 234, 1)
+	assert.Equal(t, dao.ErrDeletedArticleNotFound, err)
+	restored, err := s.dao.Restore(ctx, 123, 1)
+	require.NoError(t, err)
+	assert.NotEmpty(t, restored.Published)
+	art, err := s.dao.GetById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "发表过", art.Title)
+	pub, err := s.dao.GetPubById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(2), pub.Status)
+
+	// 草稿没有线上版本
+	restored, err = s.dao.Restore(ctx, 123, 2)
+	require.NoError(t, err)
+	assert.Empty(t, restored.Published)
+	_, err = s.dao.GetPubById(ctx, 2)
+	assert.Equal(t, dao.ErrRecordNotFound, err)
+}
+
+func (s *ArticleMongoDBDAOSuite) TestPurge() {
+	t := s.T()
+	s.insert(false,
+		dao.Article{Id: 1, AuthorId: 123},
+		dao.Article{Id: 2, AuthorId: 123})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.dao.Delete(ctx, 123, 1))
+	_, err := s.trashCol.UpdateOne(ctx, bson.M{"id": 1}, bson.M{"$set": bson.M{"dtime": 100}})
+	require.NoError(t, err)
+	require.NoError(t, s.dao.Delete(ctx, 123, 2))
+
+	expired, err := s.dao.FindExpiredDeleted(ctx, 200, 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, int64(1), expired[0].Id)
+
+	require.NoError(t, s.dao.Purge(ctx, 1))
+	_, err = s.dao.Restore(ctx, 123, 1)
+	assert.Equal(t, dao.ErrDeletedArticleNotFound, err)
+}
+
+func articleIds(arts []dao.Article) []int64 {
+	ids := make([]int64, 0, len(arts))
+	for _, art := range arts {
+		ids = append(ids, art.Id)
+	}
+	return ids
+}
+
+func pubArticleIds(arts []dao.PublishedArticle) []int64 {
+	ids := make([]int64, 0, len(arts))
+	for _, art := range arts {
+		ids = append(ids, art.Id)
+	}
+	return ids
+}
+
+func TestArticleMongoDBDAO(t *testing.T) {
+	suite.Run(t, &ArticleMongoDBDAOSuite{})
+}
@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/integration/startup"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"github.com/jayleonc/geektime-go/webook/pkg/objstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+// ArticleS3DAOSuite 内容放在本地的对象存储里面
+type ArticleS3DAOSuite struct {
+	suite.Suite
+	db  *gorm.DB
+	dao dao.ArticleDAO
+}
+
+func (s *ArticleS3DAOSuite) SetupSuite() {
+	s.db = startup.InitDB()
+	s.dao = dao.NewArticleS3DAO(s.db, startup.InitObjectStore(), objstore.DefaultLayout)
+}
+
+func (s *ArticleS3DAOSuite) TearDownTest() {
+	s.db.Exec("truncate table `articles`")
+	s.db.Exec("truncate table `published_article_v2`")
+}
+
+func (s *ArticleS3DAOSuite) TestListPubByCursorWithContent() {
+	t := s.T()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	for _, art := range []dao.Article{
+		{Title: "标题1", Content: "内容1", AuthorId: 123, Status: domain.ArticleStatusPublished},
+		{Title: "标题2", Content: "内容2", AuthorId: 123, Status: domain.ArticleStatusPrivate},
+		{Title: "标题3", Content: "内容3", AuthorId: 123, Status: domain.ArticleStatusPublished},
+	} {
+		_, err := s.dao.Sync(ctx, art)
+		require.NoError(t, err)
+	}
+
+	// 普通的列表不加载内容
+	arts, err := s.dao.ListPubByCursor(ctx, 0, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, arts, 2)
+	assert.Equal(t, "", arts[0].Content)
+
+	arts, err = s.dao.ListPubByCursorWithContent(ctx, 0, 0, 10)
+	require.NoError(t, err)
+	contents := make(map[string]string, len(arts))
+	for _, art := range arts {
+		contents[art.Title] = art.Content
+	}
+	assert.Equal(t, map[string]string{"标题1": "内容1", "标题3": "内容3"}, contents)
+}
+
+func TestArticleS3DAO(t *testing.T) {
+	suite.Run(t, &ArticleS3DAOSuite{})
+}
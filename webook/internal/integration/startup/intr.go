@@ -0,0 +1,12 @@
+package startup
+
+import (
+	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
+	"github.com/jayleonc/geektime-go/webook/interactive/service"
+	"github.com/jayleonc/geektime-go/webook/internal/client"
+)
+
+// InitIntrClient 测试里面不起 gRPC 服务，直接调本地的 InteractiveService
+func InitIntrClient(svc service.InteractiveService) intrv1.InteractiveServiceClient {
+	return client.NewLocalInteractiveServiceAdapter(svc)
+}
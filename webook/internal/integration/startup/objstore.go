@@ -0,0 +1,14 @@
+package startup
+
+import (
+	"github.com/jayleonc/geektime-go/webook/pkg/objstore"
+	"os"
+)
+
+func InitObjectStore() objstore.ObjectStore {
+	dir, err := os.MkdirTemp("", "webook-objects-*")
+	if err != nil {
+		panic(err)
+	}
+	return objstore.NewLocalStore(dir, "http://localhost:8080/objects", []byte("test-secret"))
+}
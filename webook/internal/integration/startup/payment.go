@@ -0,0 +1,19 @@
+package startup
+
+import (
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/internal/service/payment"
+	"github.com/jayleonc/geektime-go/webook/internal/service/payment/local"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"time"
+)
+
+func InitPaymentGateway(l logger.Logger) payment.Gateway {
+	return local.NewGateway("http://localhost:8080/pay/notify", []byte("test-pay-secret"), time.Second, 0, l)
+}
+
+func InitOrderService(repo repository.OrderRepository, artRepo repository.ArticleRepository,
+	gateway payment.Gateway, l logger.Logger) service.OrderService {
+	return service.NewOrderService(repo, artRepo, gateway, l, time.Minute*30)
+}
@@ -0,0 +1,13 @@
+package startup
+
+import (
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"time"
+)
+
+func InitArticleShareService(repo repository.ArticleShareRepository, artRepo repository.ArticleRepository,
+	l logger.Logger) service.ArticleShareService {
+	return service.NewArticleShareService(repo, artRepo, l, []byte("test-share-secret"), time.Hour*24*30)
+}
@@ -5,11 +5,13 @@ package startup
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
+	ioc2 "github.com/jayleonc/geektime-go/webook/interactive/ioc"
 	repository2 "github.com/jayleonc/geektime-go/webook/interactive/repository"
 	cache2 "github.com/jayleonc/geektime-go/webook/interactive/repository/cache"
 	dao2 "github.com/jayleonc/geektime-go/webook/interactive/repository/dao"
 	service2 "github.com/jayleonc/geektime-go/webook/interactive/service"
 	"github.com/jayleonc/geektime-go/webook/internal/events/article"
+	"github.com/jayleonc/geektime-go/webook/internal/events/comment"
 	"github.com/jayleonc/geektime-go/webook/internal/repository"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
@@ -39,14 +41,57 @@ var userSvcProvider = wire.NewSet(
 
 var articlSvcProvider = wire.NewSet(
 	repository.NewCachedArticleRepository,
+	repository.NewArticleRevisionRepository,
+	dao.NewArticleRevisionGORMDAO,
+	repository.NewTagRepository,
+	ioc.InitTagDAO,
+	dao.NewArticleReviewGORMDAO,
+	repository.NewArticleReviewRepository,
+	ioc.InitSensitiveFilter,
 	cache.NewArticleRedisCache,
 	dao.NewArticleGORMDAO,
+	dao.NewAttachmentGORMDAO,
+	repository.NewAttachmentRepository,
 	service.NewArticleService)
 
 var interactiveSvcSet = wire.NewSet(dao2.NewGORMInteractiveDAO,
+	dao2.NewGORMCollectionDAO,
+	dao2.NewGORMReactionDAO,
+	dao2.NewGORMInteractiveCntDAO,
 	cache2.NewInteractiveRedisCache,
 	repository2.NewCachedInteractiveRepository,
+	repository2.NewCachedCollectionRepository,
+	ioc2.InitReactionConfig,
+	ioc2.InitWriteBehindConfig,
 	service2.NewInteractiveService,
+	InitIntrClient,
+)
+
+var relatedSvcSet = wire.NewSet(
+	dao.NewRelatedArticleGORMDAO,
+	cache.NewRelatedArticleRedisCache,
+	repository.NewCachedRelatedArticleRepository,
+	service.NewRelatedArticleService,
+)
+
+var shareSvcSet = wire.NewSet(
+	dao.NewArticleShareGORMDAO,
+	repository.NewArticleShareRepository,
+	InitArticleShareService,
+)
+
+var seriesSvcSet = wire.NewSet(
+	dao.NewSeriesGORMDAO,
+	cache.NewSeriesRedisCache,
+	repository.NewCachedSeriesRepository,
+	service.NewSeriesService,
+)
+
+var orderSvcSet = wire.NewSet(
+	dao.NewOrderGORMDAO,
+	repository.NewOrderRepository,
+	InitPaymentGateway,
+	InitOrderService,
 )
 
 func InitWebServer() *gin.Engine {
@@ -71,6 +116,47 @@ func InitWebServer() *gin.Engine {
 		// handler 部分
 		web.NewUserHandler,
 		web.NewArticleHandler,
+		web.NewCollectionHandler,
+		service.NewArticleSearchService,
+		web.NewSearchHandler,
+		dao.NewCommentGORMDAO,
+		repository.NewCommentRepository,
+		comment.NewKafkaProducer,
+		service.NewCommentService,
+		web.NewCommentHandler,
+		InitObjectStore,
+		web.NewObjectHandler,
+		ioc.InitReviewHandler,
+		dao.NewFollowGORMDAO,
+		cache.NewFollowRedisCache,
+		repository.NewCachedFollowRepository,
+		cache.NewFeedRedisCache,
+		repository.NewFeedRepository,
+		service.NewFollowService,
+		ioc.InitFeedService,
+		web.NewFollowHandler,
+		ioc.InitAttachmentService,
+		web.NewAttachmentHandler,
+		service.NewArticleArchiveService,
+		web.NewArticleArchiveHandler,
+		cache.NewRankingRedisCache,
+		repository.NewCachedRankingRepository,
+		service.NewBatchRankingService,
+		cache.NewSyndicationRedisCache,
+		repository.NewSyndicationRepository,
+		ioc.InitSyndicationService,
+		web.NewSyndicationHandler,
+		dao.NewReadHistoryGORMDAO,
+		repository.NewReadHistoryRepository,
+		service.NewReadHistoryService,
+		web.NewReadHistoryHandler,
+		relatedSvcSet,
+		shareSvcSet,
+		web.NewArticleShareHandler,
+		seriesSvcSet,
+		web.NewSeriesHandler,
+		orderSvcSet,
+		web.NewOrderHandler,
 		web.NewOAuth2WechatHandler,
 		ijwt.NewRedisJWTHandler,
 		ioc.InitGinMiddlewares,
@@ -85,9 +171,28 @@ func InitArticleHandler(dao dao.ArticleDAO) *web.ArticleHandler {
 		userSvcProvider,
 		interactiveSvcSet,
 		repository.NewCachedArticleRepository,
+		repository.NewArticleRevisionRepository,
+		dao.NewArticleRevisionGORMDAO,
+		repository.NewTagRepository,
+		ioc.InitTagDAO,
+		dao.NewArticleReviewGORMDAO,
+		repository.NewArticleReviewRepository,
+		ioc.InitSensitiveFilter,
 		cache.NewArticleRedisCache,
+		InitObjectStore,
+		dao.NewAttachmentGORMDAO,
+		repository.NewAttachmentRepository,
 		service.NewArticleService,
 		article.NewKafkaProducer,
+		dao.NewReadHistoryGORMDAO,
+		repository.NewReadHistoryRepository,
+		relatedSvcSet,
+		shareSvcSet,
+		seriesSvcSet,
+		orderSvcSet,
+		cache.NewRankingRedisCache,
+		repository.NewCachedRankingRepository,
+		service.NewBatchRankingService,
 		web.NewArticleHandler)
 	return &web.ArticleHandler{}
 }
@@ -99,5 +204,5 @@ func InitJobScheduler() *job.Scheduler {
 
 func InitInteractiveService() service2.InteractiveService {
 	wire.Build(thirdPartySet, interactiveSvcSet)
-	return service2.NewInteractiveService(nil)
+	return service2.NewInteractiveService(nil, nil, nil)
 }
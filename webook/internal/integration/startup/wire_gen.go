@@ -9,11 +9,13 @@ package startup
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
+	ioc2 "github.com/jayleonc/geektime-go/webook/interactive/ioc"
 	repository2 "github.com/jayleonc/geektime-go/webook/interactive/repository"
 	cache2 "github.com/jayleonc/geektime-go/webook/interactive/repository/cache"
 	dao2 "github.com/jayleonc/geektime-go/webook/interactive/repository/dao"
 	service2 "github.com/jayleonc/geektime-go/webook/interactive/service"
 	"github.com/jayleonc/geektime-go/webook/internal/events/article"
+	"github.com/jayleonc/geektime-go/webook/internal/events/comment"
 	"github.com/jayleonc/geektime-go/webook/internal/repository"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
@@ -49,13 +51,81 @@ func InitWebServer() *gin.Engine {
 	client := InitSaramaClient()
 	syncProducer := InitSyncProducer(client)
 	producer := article.NewKafkaProducer(syncProducer)
-	articleService := service.NewArticleService(articleRepository, producer)
+	articleRevisionDAO := dao.NewArticleRevisionGORMDAO(db)
+	articleRevisionRepository := repository.NewArticleRevisionRepository(articleRevisionDAO)
+	tagDAO := ioc.InitTagDAO(db, articleDAO)
+	tagRepository := repository.NewTagRepository(tagDAO)
+	articleReviewDAO := dao.NewArticleReviewGORMDAO(db)
+	articleReviewRepository := repository.NewArticleReviewRepository(articleReviewDAO)
+	objectStore := InitObjectStore()
+	attachmentDAO := dao.NewAttachmentGORMDAO(db)
+	attachmentRepository := repository.NewAttachmentRepository(attachmentDAO, objectStore)
+	filter := ioc.InitSensitiveFilter(logger)
+	articleService := service.NewArticleService(articleRepository, articleRevisionRepository, tagRepository, articleReviewRepository, attachmentRepository, filter, producer, logger)
 	interactiveDAO := dao2.NewGORMInteractiveDAO(db)
 	interactiveCache := cache2.NewInteractiveRedisCache(cmdable)
-	interactiveRepository := repository2.NewCachedInteractiveRepository(interactiveDAO, interactiveCache)
-	interactiveService := service2.NewInteractiveService(interactiveRepository)
-	articleHandler := web.NewArticleHandler(logger, articleService, interactiveService)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler)
+	writeBehindConfig := ioc2.InitWriteBehindConfig()
+	reactionDAO := dao2.NewGORMReactionDAO(db, writeBehindConfig)
+	interactiveCntDAO := dao2.NewGORMInteractiveCntDAO(db)
+	interactiveRepository := repository2.NewCachedInteractiveRepository(interactiveDAO, reactionDAO, interactiveCntDAO, interactiveCache, writeBehindConfig)
+	collectionDAO := dao2.NewGORMCollectionDAO(db)
+	collectionRepository := repository2.NewCachedCollectionRepository(collectionDAO, interactiveCache)
+	reactionConfig := ioc2.InitReactionConfig()
+	interactiveService := service2.NewInteractiveService(interactiveRepository, collectionRepository, reactionConfig)
+	interactiveServiceClient := InitIntrClient(interactiveService)
+	relatedArticleDAO := dao.NewRelatedArticleGORMDAO(db)
+	relatedArticleCache := cache.NewRelatedArticleRedisCache(cmdable)
+	relatedArticleRepository := repository.NewCachedRelatedArticleRepository(relatedArticleDAO, relatedArticleCache, logger)
+	readHistoryDAO := dao.NewReadHistoryGORMDAO(db)
+	readHistoryRepository := repository.NewReadHistoryRepository(readHistoryDAO)
+	relatedArticleService := service.NewRelatedArticleService(relatedArticleRepository, articleRepository, readHistoryRepository, logger)
+	articleShareDAO := dao.NewArticleShareGORMDAO(db)
+	articleShareRepository := repository.NewArticleShareRepository(articleShareDAO)
+	articleShareService := InitArticleShareService(articleShareRepository, articleRepository, logger)
+	seriesDAO := dao.NewSeriesGORMDAO(db)
+	seriesCache := cache.NewSeriesRedisCache(cmdable)
+	seriesRepository := repository.NewCachedSeriesRepository(seriesDAO, seriesCache, logger)
+	seriesService := service.NewSeriesService(seriesRepository, articleRepository, interactiveServiceClient, logger)
+	orderDAO := dao.NewOrderGORMDAO(db)
+	orderRepository := repository.NewOrderRepository(orderDAO)
+	gateway := InitPaymentGateway(logger)
+	orderService := InitOrderService(orderRepository, articleRepository, gateway, logger)
+	rankingCache := cache.NewRankingRedisCache(cmdable)
+	rankingRepository := repository.NewCachedRankingRepository(rankingCache)
+	rankingService := service.NewBatchRankingService(interactiveServiceClient, articleService, rankingRepository)
+	articleHandler := web.NewArticleHandler(logger, articleService, interactiveServiceClient, relatedArticleService, articleShareService, seriesService, orderService, rankingService)
+	searchService := service.NewArticleSearchService(articleRepository, tagRepository, logger)
+	searchHandler := web.NewSearchHandler(searchService, logger)
+	commentDAO := dao.NewCommentGORMDAO(db)
+	commentRepository := repository.NewCommentRepository(commentDAO)
+	commentProducer := comment.NewKafkaProducer(syncProducer)
+	commentService := service.NewCommentService(commentRepository, articleRepository, commentProducer, logger)
+	commentHandler := web.NewCommentHandler(commentService, interactiveServiceClient, logger)
+	objectHandler := web.NewObjectHandler(objectStore, logger)
+	reviewHandler := ioc.InitReviewHandler(articleService, commentService, logger)
+	followDAO := dao.NewFollowGORMDAO(db)
+	followCache := cache.NewFollowRedisCache(cmdable)
+	followRepository := repository.NewCachedFollowRepository(followDAO, followCache)
+	feedCache := cache.NewFeedRedisCache(cmdable)
+	feedRepository := repository.NewFeedRepository(feedCache)
+	followService := service.NewFollowService(followRepository, userRepository, feedRepository, logger)
+	feedService := ioc.InitFeedService(feedRepository, followRepository, articleRepository, logger)
+	followHandler := web.NewFollowHandler(followService, feedService, interactiveServiceClient, logger)
+	attachmentService := ioc.InitAttachmentService(attachmentRepository, logger)
+	attachmentHandler := web.NewAttachmentHandler(attachmentService, logger)
+	articleArchiveService := service.NewArticleArchiveService(articleRepository, tagRepository, articleService, logger)
+	articleArchiveHandler := web.NewArticleArchiveHandler(articleArchiveService, logger)
+	syndicationCache := cache.NewSyndicationRedisCache(cmdable)
+	syndicationRepository := repository.NewSyndicationRepository(syndicationCache)
+	syndicationService := ioc.InitSyndicationService(syndicationRepository, articleRepository, rankingService, logger)
+	syndicationHandler := web.NewSyndicationHandler(syndicationService, logger)
+	readHistoryService := service.NewReadHistoryService(readHistoryRepository, articleRepository)
+	readHistoryHandler := web.NewReadHistoryHandler(readHistoryService, logger)
+	articleShareHandler := web.NewArticleShareHandler(articleShareService, logger)
+	seriesHandler := web.NewSeriesHandler(seriesService, logger)
+	orderHandler := web.NewOrderHandler(orderService, gateway, logger)
+	collectionHandler := web.NewCollectionHandler(interactiveServiceClient, articleService, logger)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, searchHandler, commentHandler, objectHandler, reviewHandler, followHandler, attachmentHandler, articleArchiveHandler, syndicationHandler, readHistoryHandler, articleShareHandler, seriesHandler, orderHandler, collectionHandler)
 	return engine
 }
 
@@ -71,12 +141,49 @@ func InitArticleHandler(dao3 dao.ArticleDAO) *web.ArticleHandler {
 	client := InitSaramaClient()
 	syncProducer := InitSyncProducer(client)
 	producer := article.NewKafkaProducer(syncProducer)
-	articleService := service.NewArticleService(articleRepository, producer)
+	articleRevisionDAO := dao.NewArticleRevisionGORMDAO(db)
+	articleRevisionRepository := repository.NewArticleRevisionRepository(articleRevisionDAO)
+	tagDAO := ioc.InitTagDAO(db, dao3)
+	tagRepository := repository.NewTagRepository(tagDAO)
+	articleReviewDAO := dao.NewArticleReviewGORMDAO(db)
+	articleReviewRepository := repository.NewArticleReviewRepository(articleReviewDAO)
+	objectStore := InitObjectStore()
+	attachmentDAO := dao.NewAttachmentGORMDAO(db)
+	attachmentRepository := repository.NewAttachmentRepository(attachmentDAO, objectStore)
+	filter := ioc.InitSensitiveFilter(logger)
+	articleService := service.NewArticleService(articleRepository, articleRevisionRepository, tagRepository, articleReviewRepository, attachmentRepository, filter, producer, logger)
 	interactiveDAO := dao2.NewGORMInteractiveDAO(db)
 	interactiveCache := cache2.NewInteractiveRedisCache(cmdable)
-	interactiveRepository := repository2.NewCachedInteractiveRepository(interactiveDAO, interactiveCache)
-	interactiveService := service2.NewInteractiveService(interactiveRepository)
-	articleHandler := web.NewArticleHandler(logger, articleService, interactiveService)
+	writeBehindConfig := ioc2.InitWriteBehindConfig()
+	reactionDAO := dao2.NewGORMReactionDAO(db, writeBehindConfig)
+	interactiveCntDAO := dao2.NewGORMInteractiveCntDAO(db)
+	interactiveRepository := repository2.NewCachedInteractiveRepository(interactiveDAO, reactionDAO, interactiveCntDAO, interactiveCache, writeBehindConfig)
+	collectionDAO := dao2.NewGORMCollectionDAO(db)
+	collectionRepository := repository2.NewCachedCollectionRepository(collectionDAO, interactiveCache)
+	reactionConfig := ioc2.InitReactionConfig()
+	interactiveService := service2.NewInteractiveService(interactiveRepository, collectionRepository, reactionConfig)
+	interactiveServiceClient := InitIntrClient(interactiveService)
+	relatedArticleDAO := dao.NewRelatedArticleGORMDAO(db)
+	relatedArticleCache := cache.NewRelatedArticleRedisCache(cmdable)
+	relatedArticleRepository := repository.NewCachedRelatedArticleRepository(relatedArticleDAO, relatedArticleCache, logger)
+	readHistoryDAO := dao.NewReadHistoryGORMDAO(db)
+	readHistoryRepository := repository.NewReadHistoryRepository(readHistoryDAO)
+	relatedArticleService := service.NewRelatedArticleService(relatedArticleRepository, articleRepository, readHistoryRepository, logger)
+	articleShareDAO := dao.NewArticleShareGORMDAO(db)
+	articleShareRepository := repository.NewArticleShareRepository(articleShareDAO)
+	articleShareService := InitArticleShareService(articleShareRepository, articleRepository, logger)
+	seriesDAO := dao.NewSeriesGORMDAO(db)
+	seriesCache := cache.NewSeriesRedisCache(cmdable)
+	seriesRepository := repository.NewCachedSeriesRepository(seriesDAO, seriesCache, logger)
+	seriesService := service.NewSeriesService(seriesRepository, articleRepository, interactiveServiceClient, logger)
+	orderDAO := dao.NewOrderGORMDAO(db)
+	orderRepository := repository.NewOrderRepository(orderDAO)
+	gateway := InitPaymentGateway(logger)
+	orderService := InitOrderService(orderRepository, articleRepository, gateway, logger)
+	rankingCache := cache.NewRankingRedisCache(cmdable)
+	rankingRepository := repository.NewCachedRankingRepository(rankingCache)
+	rankingService := service.NewBatchRankingService(interactiveServiceClient, articleService, rankingRepository)
+	articleHandler := web.NewArticleHandler(logger, articleService, interactiveServiceClient, relatedArticleService, articleShareService, seriesService, orderService, rankingService)
 	return articleHandler
 }
 
@@ -95,8 +202,14 @@ func InitInteractiveService() service2.InteractiveService {
 	interactiveDAO := dao2.NewGORMInteractiveDAO(db)
 	cmdable := InitRedis()
 	interactiveCache := cache2.NewInteractiveRedisCache(cmdable)
-	interactiveRepository := repository2.NewCachedInteractiveRepository(interactiveDAO, interactiveCache)
-	interactiveService := service2.NewInteractiveService(interactiveRepository)
+	writeBehindConfig := ioc2.InitWriteBehindConfig()
+	reactionDAO := dao2.NewGORMReactionDAO(db, writeBehindConfig)
+	interactiveCntDAO := dao2.NewGORMInteractiveCntDAO(db)
+	interactiveRepository := repository2.NewCachedInteractiveRepository(interactiveDAO, reactionDAO, interactiveCntDAO, interactiveCache, writeBehindConfig)
+	collectionDAO := dao2.NewGORMCollectionDAO(db)
+	collectionRepository := repository2.NewCachedCollectionRepository(collectionDAO, interactiveCache)
+	reactionConfig := ioc2.InitReactionConfig()
+	interactiveService := service2.NewInteractiveService(interactiveRepository, collectionRepository, reactionConfig)
 	return interactiveService
 }
 
@@ -112,6 +225,14 @@ var jobProviderSet = wire.NewSet(service.NewCronJobService, repository.NewPreemp
 
 var userSvcProvider = wire.NewSet(dao.NewUserDAO, cache.NewUserCache, repository.NewCachedUserRepository, service.NewUserService)
 
-var articlSvcProvider = wire.NewSet(repository.NewCachedArticleRepository, cache.NewArticleRedisCache, dao.NewArticleGORMDAO, service.NewArticleService)
+var articlSvcProvider = wire.NewSet(repository.NewCachedArticleRepository, repository.NewArticleRevisionRepository, dao.NewArticleRevisionGORMDAO, repository.NewTagRepository, ioc.InitTagDAO, dao.NewArticleReviewGORMDAO, repository.NewArticleReviewRepository, ioc.InitSensitiveFilter, cache.NewArticleRedisCache, dao.NewArticleGORMDAO, dao.NewAttachmentGORMDAO, repository.NewAttachmentRepository, service.NewArticleService)
 
-var interactiveSvcSet = wire.NewSet(dao2.NewGORMInteractiveDAO, cache2.NewInteractiveRedisCache, repository2.NewCachedInteractiveRepository, service2.NewInteractiveService)
+var interactiveSvcSet = wire.NewSet(dao2.NewGORMInteractiveDAO, dao2.NewGORMCollectionDAO, dao2.NewGORMReactionDAO, dao2.NewGORMInteractiveCntDAO, cache2.NewInteractiveRedisCache, repository2.NewCachedInteractiveRepository, repository2.NewCachedCollectionRepository, ioc2.InitReactionConfig, ioc2.InitWriteBehindConfig, service2.NewInteractiveService)
+
+var relatedSvcSet = wire.NewSet(dao.NewRelatedArticleGORMDAO, cache.NewRelatedArticleRedisCache, repository.NewCachedRelatedArticleRepository, service.NewRelatedArticleService)
+
+var shareSvcSet = wire.NewSet(dao.NewArticleShareGORMDAO, repository.NewArticleShareRepository, InitArticleShareService)
+
+var seriesSvcSet = wire.NewSet(dao.NewSeriesGORMDAO, cache.NewSeriesRedisCache, repository.NewCachedSeriesRepository, service.NewSeriesService)
+
+var orderSvcSet = wire.NewSet(dao.NewOrderGORMDAO, repository.NewOrderRepository, InitPaymentGateway, InitOrderService)
@@ -16,13 +16,51 @@ type ArticleRepository interface {
 	Update(ctx context.Context, biz string, article domain.Article) error
 	Sync(ctx context.Context, art domain.Article) (int64, error)
 	GetByAuthor(ctx context.Context, uid int64, limit int, offset int) ([]domain.Article, int64, error)
+	// FindByAuthor 按照 id 遍历作者的全部草稿，minId 为 0 表示从头开始
+	FindByAuthor(ctx context.Context, uid int64, minId int64, limit int) ([]domain.Article, error)
 	SyncStatus(ctx context.Context, uid int64, id int64, status domain.ArticleStatus) error
 	GetById(ctx context.Context, id int64) (domain.Article, error)
 	GetPubById(ctx context.Context, id int64) (domain.Article, error)
 	ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error)
+	// ListPubByCursor 按照 Utime, Id 倒序，返回排在 cursor 之后的已发表文章
+	ListPubByCursor(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
+	// ListPubByCursorWithContent 和 ListPubByCursor 一样，不管存储是什么都带上内容
+	ListPubByCursorWithContent(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
+	// ListPubByAuthor 作者最近发表的文章，带上作者的名字
+	ListPubByAuthor(ctx context.Context, uid int64, limit int) ([]domain.Article, error)
 	GetByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+
+	ListScheduled(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, int64, error)
+	FindDueScheduled(ctx context.Context, now time.Time, limit int) ([]domain.Article, error)
+	Reschedule(ctx context.Context, uid int64, id int64, publishAt time.Time) error
+	CancelSchedule(ctx context.Context, uid int64, id int64) error
+	// TransitStatus 草稿处于 from 状态的时候才改成 to，不然返回 ErrArticleStatusMismatch
+	TransitStatus(ctx context.Context, uid int64, id int64, from domain.ArticleStatus, to domain.ArticleStatus) error
+
+	// Delete 草稿和线上版本一起挪到回收站
+	Delete(ctx context.Context, uid int64, id int64) error
+	ListDeleted(ctx context.Context, uid int64, offset int, limit int) ([]domain.DeletedArticle, int64, error)
+	Restore(ctx context.Context, uid int64, id int64) (domain.DeletedArticle, error)
+	FindExpiredDeleted(ctx context.Context, before time.Time, limit int) ([]domain.DeletedArticle, error)
+	// Purge 彻底删除，不能再恢复
+	Purge(ctx context.Context, id int64) error
 }
 
+// contentURLExpire 私密文章内容签名链接的有效期
+const contentURLExpire = time.Minute * 15
+
+var (
+	ErrArticleNotScheduled    = dao.ErrArticleNotScheduled
+	ErrArticleVersionConflict = dao.ErrArticleVersionConflict
+	ErrArticleStatusMismatch  = dao.ErrArticleStatusMismatch
+	ErrArticleNotFound        = dao.ErrArticleNotFound
+	ErrDeletedArticleNotFound = dao.ErrDeletedArticleNotFound
+	// ErrPubArticleNotFound 线上库里面没有，没有发表过或者已经删除了
+	ErrPubArticleNotFound = dao.ErrRecordNotFound
+	// ErrDraftNotFound 制作库里面没有，GetById 返回
+	ErrDraftNotFound = dao.ErrRecordNotFound
+)
+
 type CachedArticleRepository struct {
 	dao   dao.ArticleDAO
 	cache cache.ArticleCache
@@ -68,6 +106,141 @@ func (c *CachedArticleRepository) GetByIds(ctx context.Context, ids []int64) ([]
 	return articles, nil
 }
 
+func (c *CachedArticleRepository) ListScheduled(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, int64, error) {
+	arts, count, err := c.dao.ListScheduled(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts,
+		func(idx int, src dao.Article) domain.Article {
+			return c.toDomain(src)
+		}), count, nil
+}
+
+func (c *CachedArticleRepository) FindByAuthor(ctx context.Context, uid int64, minId int64, limit int) ([]domain.Article, error) {
+	arts, err := c.dao.FindByAuthor(ctx, uid, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts,
+		func(idx int, src dao.Article) domain.Article {
+			return c.toDomain(src)
+		}), nil
+}
+
+func (c *CachedArticleRepository) FindDueScheduled(ctx context.Context, now time.Time, limit int) ([]domain.Article, error) {
+	arts, err := c.dao.FindDueScheduled(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts,
+		func(idx int, src dao.Article) domain.Article {
+			return c.toDomain(src)
+		}), nil
+}
+
+func (c *CachedArticleRepository) Reschedule(ctx context.Context, uid int64, id int64, publishAt time.Time) error {
+	return c.dao.Reschedule(ctx, uid, id, publishAt.UnixMilli())
+}
+
+func (c *CachedArticleRepository) CancelSchedule(ctx context.Context, uid int64, id int64) error {
+	err := c.dao.CancelSchedule(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	// 状态变了，第一页的缓存要删掉
+	return c.cache.DelFirstPage(ctx, uid)
+}
+
+func (c *CachedArticleRepository) TransitStatus(ctx context.Context, uid int64, id int64, from domain.ArticleStatus, to domain.ArticleStatus) error {
+	err := c.dao.TransitStatus(ctx, uid, id, from.ToUint8(), to.ToUint8())
+	if err != nil {
+		return err
+	}
+	er := c.cache.DelFirstPage(ctx, uid)
+	if er != nil {
+		// 也要记录日志
+	}
+	return c.cache.Del(ctx, id)
+}
+
+func (c *CachedArticleRepository) Delete(ctx context.Context, uid int64, id int64) error {
+	err := c.dao.Delete(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	return c.delCache(ctx, uid, id)
+}
+
+func (c *CachedArticleRepository) Restore(ctx context.Context, uid int64, id int64) (domain.DeletedArticle, error) {
+	deleted, err := c.dao.Restore(ctx, uid, id)
+	if err != nil {
+		return domain.DeletedArticle{}, err
+	}
+	res, err := c.toDeletedDomain(deleted)
+	if err != nil {
+		return domain.DeletedArticle{}, err
+	}
+	// 删除期间可能有并发的查询把旧数据写回了缓存，这里再清一次
+	return res, c.delCache(ctx, uid, id)
+}
+
+// delCache 草稿、线上版本和作者的第一页，删除和恢复都会影响
+func (c *CachedArticleRepository) delCache(ctx context.Context, uid int64, id int64) error {
+	if err := c.cache.DelFirstPage(ctx, uid); err != nil {
+		return err
+	}
+	if err := c.cache.Del(ctx, id); err != nil {
+		return err
+	}
+	return c.cache.DelPub(ctx, id)
+}
+
+func (c *CachedArticleRepository) ListDeleted(ctx context.Context, uid int64, offset int, limit int) ([]domain.DeletedArticle, int64, error) {
+	arts, count, err := c.dao.ListDeleted(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	res, err := c.toDeletedDomains(arts)
+	return res, count, err
+}
+
+func (c *CachedArticleRepository) FindExpiredDeleted(ctx context.Context, before time.Time, limit int) ([]domain.DeletedArticle, error) {
+	arts, err := c.dao.FindExpiredDeleted(ctx, before.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.toDeletedDomains(arts)
+}
+
+func (c *CachedArticleRepository) Purge(ctx context.Context, id int64) error {
+	return c.dao.Purge(ctx, id)
+}
+
+func (c *CachedArticleRepository) toDeletedDomains(arts []dao.DeletedArticle) ([]domain.DeletedArticle, error) {
+	res := make([]domain.DeletedArticle, 0, len(arts))
+	for _, art := range arts {
+		d, err := c.toDeletedDomain(art)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, nil
+}
+
+func (c *CachedArticleRepository) toDeletedDomain(deleted dao.DeletedArticle) (domain.DeletedArticle, error) {
+	art, err := deleted.Article()
+	if err != nil {
+		return domain.DeletedArticle{}, err
+	}
+	return domain.DeletedArticle{
+		Article:   c.toDomain(art),
+		Published: deleted.Published != "",
+		Dtime:     time.UnixMilli(deleted.Dtime),
+	}, nil
+}
+
 func (c *CachedArticleRepository) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error) {
 	arts, err := c.dao.ListPub(ctx, start, offset, limit)
 	if err != nil {
@@ -79,10 +252,51 @@ func (c *CachedArticleRepository) ListPub(ctx context.Context, start time.Time,
 		}), nil
 }
 
+func (c *CachedArticleRepository) ListPubByCursor(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	return c.listPubByCursor(ctx, cursor, limit, c.dao.ListPubByCursor)
+}
+
+func (c *CachedArticleRepository) ListPubByCursorWithContent(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	return c.listPubByCursor(ctx, cursor, limit, c.dao.ListPubByCursorWithContent)
+}
+
+func (c *CachedArticleRepository) listPubByCursor(ctx context.Context, cursor domain.ArticleCursor, limit int,
+	list func(ctx context.Context, utime int64, id int64, limit int) ([]dao.PublishedArticle, error)) ([]domain.Article, error) {
+	var utime int64
+	if !cursor.IsZero() {
+		utime = cursor.Utime.UnixMilli()
+	}
+	arts, err := list(ctx, utime, cursor.Id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.PublishedArticle, domain.Article](arts,
+		func(idx int, src dao.PublishedArticle) domain.Article {
+			return c.toDomain(dao.Article(src))
+		}), nil
+}
+
+func (c *CachedArticleRepository) ListPubByAuthor(ctx context.Context, uid int64, limit int) ([]domain.Article, error) {
+	arts, err := c.dao.ListPubByAuthor(ctx, uid, limit)
+	if err != nil || len(arts) == 0 {
+		return nil, err
+	}
+	user, err := c.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.PublishedArticle, domain.Article](arts,
+		func(idx int, src dao.PublishedArticle) domain.Article {
+			res := c.toDomain(dao.Article(src))
+			res.Author.Name = user.Nickname
+			return res
+		}), nil
+}
+
 func (c *CachedArticleRepository) GetPubById(ctx context.Context, id int64) (domain.Article, error) {
 	res, err := c.cache.GetPub(ctx, id)
 	if err == nil {
-		return res, err
+		return c.signContentURL(ctx, res)
 	}
 	art, err := c.dao.GetPubById(ctx, id)
 	if err != nil {
@@ -104,7 +318,22 @@ func (c *CachedArticleRepository) GetPubById(ctx context.Context, id int64) (dom
 			// 记录日志
 		}
 	}()
-	return res, nil
+	return c.signContentURL(ctx, res)
+}
+
+// signContentURL 私密文章的内容放在对象存储里面的时候，给一个有时效的签名链接。
+// 链接会过期，所以不放进缓存
+func (c *CachedArticleRepository) signContentURL(ctx context.Context, art domain.Article) (domain.Article, error) {
+	signer, ok := c.dao.(dao.ArticleContentSigner)
+	if !ok || art.Status != domain.ArticleStatusPrivate {
+		return art, nil
+	}
+	url, err := signer.SignContentURL(ctx, art.Id, contentURLExpire)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	art.ContentURL = url
+	return art, nil
 }
 
 func (c *CachedArticleRepository) GetById(ctx context.Context, id int64) (domain.Article, error) {
@@ -188,6 +417,10 @@ func (c *CachedArticleRepository) Sync(ctx context.Context, art domain.Article)
 		if er != nil {
 			// 也要记录日志
 		}
+		er = c.cache.Del(ctx, id)
+		if er != nil {
+			// 也要记录日志
+		}
 	}
 	// 在这里尝试，设置缓存
 	go func() {
@@ -273,6 +506,10 @@ func (c *CachedArticleRepository) Update(ctx context.Context, biz string, articl
 	if err != nil {
 		return err
 	}
+	// 草稿的缓存里面有版本号，不删掉的话，前端拿到的一直是旧版本
+	if er := c.cache.Del(ctx, article.Id); er != nil {
+		return er
+	}
 	// update 需要判断是否存在与 topN 存在就要把article内容更新到 topN 相关的 hash 中
 	// 检查文章是否在Top N列表中
 	isInTopN, err := c.cache.IsArticleInTopN(ctx, biz, article.Id)
@@ -293,16 +530,27 @@ func (c *CachedArticleRepository) Update(ctx context.Context, biz string, articl
 }
 
 func (c *CachedArticleRepository) toEntity(art domain.Article) dao.Article {
+	var publishAt int64
+	if !art.PublishAt.IsZero() {
+		publishAt = art.PublishAt.UnixMilli()
+	}
 	return dao.Article{
-		Id:       art.Id,
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   art.Status.ToUint8(),
+		Id:        art.Id,
+		Title:     art.Title,
+		Content:   art.Content,
+		AuthorId:  art.Author.Id,
+		Status:    art.Status.ToUint8(),
+		PublishAt: publishAt,
+		Version:   art.Version,
+		Price:     art.Price,
 	}
 }
 
 func (c *CachedArticleRepository) toDomain(art dao.Article) domain.Article {
+	var publishAt time.Time
+	if art.PublishAt > 0 {
+		publishAt = time.UnixMilli(art.PublishAt)
+	}
 	return domain.Article{
 		Id:      art.Id,
 		Title:   art.Title,
@@ -310,7 +558,12 @@ func (c *CachedArticleRepository) toDomain(art dao.Article) domain.Article {
 		Author: domain.Author{
 			Id: art.AuthorId,
 		},
-		Status: domain.ArticleStatus(art.Status),
+		Status:    domain.ArticleStatus(art.Status),
+		PublishAt: publishAt,
+		Version:   art.Version,
+		Price:     art.Price,
+		Ctime:     time.UnixMilli(art.Ctime),
+		Utime:     time.UnixMilli(art.Utime),
 	}
 }
 
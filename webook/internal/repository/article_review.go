@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"time"
+)
+
+var ErrArticleReviewNotPending = dao.ErrArticleReviewNotPending
+
+// ArticleReviewRepository 人工审核队列
+type ArticleReviewRepository interface {
+	Create(ctx context.Context, r domain.ArticleReview) (int64, error)
+	// GetById 找不到的时候返回 ErrArticleReviewNotPending
+	GetById(ctx context.Context, id int64) (domain.ArticleReview, error)
+	List(ctx context.Context, status domain.ReviewStatus, offset int, limit int) ([]domain.ArticleReview, int64, error)
+	// Finish 把等待审核的记录改成通过或者驳回
+	Finish(ctx context.Context, id int64, status domain.ReviewStatus, reviewerId int64, reason string) error
+}
+
+type articleReviewRepository struct {
+	dao dao.ArticleReviewDAO
+}
+
+func NewArticleReviewRepository(dao dao.ArticleReviewDAO) ArticleReviewRepository {
+	return &articleReviewRepository{
+		dao: dao,
+	}
+}
+
+func (r *articleReviewRepository) Create(ctx context.Context, review domain.ArticleReview) (int64, error) {
+	entity, err := r.toEntity(review)
+	if err != nil {
+		return 0, err
+	}
+	return r.dao.Insert(ctx, entity)
+}
+
+func (r *articleReviewRepository) GetById(ctx context.Context, id int64) (domain.ArticleReview, error) {
+	review, err := r.dao.GetById(ctx, id)
+	if errors.Is(err, dao.ErrRecordNotFound) {
+		return domain.ArticleReview{}, ErrArticleReviewNotPending
+	}
+	if err != nil {
+		return domain.ArticleReview{}, err
+	}
+	return r.toDomain(review), nil
+}
+
+func (r *articleReviewRepository) List(ctx context.Context, status domain.ReviewStatus, offset int, limit int) ([]domain.ArticleReview, int64, error) {
+	reviews, count, err := r.dao.List(ctx, status.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return slice.Map[dao.ArticleReview, domain.ArticleReview](reviews,
+		func(idx int, src dao.ArticleReview) domain.ArticleReview {
+			return r.toDomain(src)
+		}), count, nil
+}
+
+func (r *articleReviewRepository) Finish(ctx context.Context, id int64, status domain.ReviewStatus, reviewerId int64, reason string) error {
+	return r.dao.Finish(ctx, id, status.ToUint8(), reviewerId, reason)
+}
+
+func (r *articleReviewRepository) toEntity(review domain.ArticleReview) (dao.ArticleReview, error) {
+	hits, err := json.Marshal(review.Hits)
+	if err != nil {
+		return dao.ArticleReview{}, err
+	}
+	return dao.ArticleReview{
+		Id:         review.Id,
+		ArticleId:  review.ArticleId,
+		AuthorId:   review.Author.Id,
+		Title:      review.Title,
+		Content:    review.Content,
+		Hits:       string(hits),
+		Status:     review.Status.ToUint8(),
+		ReviewerId: review.ReviewerId,
+		Reason:     review.Reason,
+	}, nil
+}
+
+func (r *articleReviewRepository) toDomain(review dao.ArticleReview) domain.ArticleReview {
+	var hits []string
+	// 命中的词只是给审核的人参考，解析不了也不影响审核
+	_ = json.Unmarshal([]byte(review.Hits), &hits)
+	return domain.ArticleReview{
+		Id:        review.Id,
+		ArticleId: review.ArticleId,
+		Author: domain.Author{
+			Id: review.AuthorId,
+		},
+		Title:      review.Title,
+		Content:    review.Content,
+		Hits:       hits,
+		Status:     domain.ReviewStatus(review.Status),
+		ReviewerId: review.ReviewerId,
+		Reason:     review.Reason,
+		Ctime:      time.UnixMilli(review.Ctime),
+		Utime:      time.UnixMilli(review.Utime),
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"time"
+)
+
+var ErrArticleRevisionNotFound = dao.ErrRecordNotFound
+
+// ArticleRevisionRepository 文章历史版本，只追加，不修改也不删除
+type ArticleRevisionRepository interface {
+	Append(ctx context.Context, art domain.Article) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.ArticleRevision, error)
+	List(ctx context.Context, uid int64, aid int64, offset int, limit int) ([]domain.ArticleRevision, int64, error)
+}
+
+type articleRevisionRepository struct {
+	dao dao.ArticleRevisionDAO
+}
+
+func NewArticleRevisionRepository(dao dao.ArticleRevisionDAO) ArticleRevisionRepository {
+	return &articleRevisionRepository{
+		dao: dao,
+	}
+}
+
+func (r *articleRevisionRepository) Append(ctx context.Context, art domain.Article) (int64, error) {
+	return r.dao.Insert(ctx, dao.ArticleRevision{
+		ArticleId: art.Id,
+		AuthorId:  art.Author.Id,
+		Title:     art.Title,
+		Content:   art.Content,
+		Status:    art.Status.ToUint8(),
+	})
+}
+
+func (r *articleRevisionRepository) GetById(ctx context.Context, id int64) (domain.ArticleRevision, error) {
+	rev, err := r.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	return r.toDomain(rev), nil
+}
+
+func (r *articleRevisionRepository) List(ctx context.Context, uid int64, aid int64, offset int, limit int) ([]domain.ArticleRevision, int64, error) {
+	revs, count, err := r.dao.ListByArticle(ctx, uid, aid, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return slice.Map[dao.ArticleRevision, domain.ArticleRevision](revs,
+		func(idx int, src dao.ArticleRevision) domain.ArticleRevision {
+			return r.toDomain(src)
+		}), count, nil
+}
+
+func (r *articleRevisionRepository) toDomain(rev dao.ArticleRevision) domain.ArticleRevision {
+	return domain.ArticleRevision{
+		Id:        rev.Id,
+		ArticleId: rev.ArticleId,
+		Title:     rev.Title,
+		Content:   rev.Content,
+		Author: domain.Author{
+			Id: rev.AuthorId,
+		},
+		Status: domain.ArticleStatus(rev.Status),
+		Ctime:  time.UnixMilli(rev.Ctime),
+	}
+}
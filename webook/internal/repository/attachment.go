@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"github.com/jayleonc/geektime-go/webook/pkg/objstore"
+	"mime"
+	"time"
+)
+
+var ErrAttachmentNotFound = dao.ErrRecordNotFound
+
+// attachmentLayout 图片在对象存储里面的 key，后面再拼上扩展名
+const attachmentLayout objstore.Layout = "attachments/{prefix}/{hash}"
+
+// AttachmentRepository 图片的记录放在数据库里面，内容放在对象存储里面
+type AttachmentRepository interface {
+	// Save 内容一样的图片只存一份，返回库里面的记录
+	Save(ctx context.Context, att domain.Attachment, data, thumb []byte) (domain.Attachment, error)
+	// GetByHash 没有的时候返回 ErrAttachmentNotFound
+	GetByHash(ctx context.Context, hash string) (domain.Attachment, error)
+	// GetContent thumb 为 true 的时候返回缩略图
+	GetContent(ctx context.Context, att domain.Attachment, thumb bool) ([]byte, error)
+	// SetDraftReferences 草稿现在用到的图片，不再用到的引用会被删掉
+	SetDraftReferences(ctx context.Context, aid int64, hashes []string) error
+	// SetPubReferences 线上版本现在用到的图片
+	SetPubReferences(ctx context.Context, aid int64, hashes []string) error
+	DeleteReferences(ctx context.Context, aid int64) error
+	FindOrphans(ctx context.Context, before time.Time, limit int) ([]domain.Attachment, error)
+	// DeleteOrphan 记录和对象一起删掉，期间被引用了的不删，返回 false
+	DeleteOrphan(ctx context.Context, att domain.Attachment, before time.Time) (bool, error)
+}
+
+type attachmentRepository struct {
+	dao   dao.AttachmentDAO
+	store objstore.ObjectStore
+}
+
+func NewAttachmentRepository(dao dao.AttachmentDAO, store objstore.ObjectStore) AttachmentRepository {
+	return &attachmentRepository{
+		dao:   dao,
+		store: store,
+	}
+}
+
+func (r *attachmentRepository) Save(ctx context.Context, att domain.Attachment, data, thumb []byte) (domain.Attachment, error) {
+	// 先写记录，刷新 utime，这样垃圾回收不会在这个时候把同一张图片删掉
+	entity, err := r.dao.Upsert(ctx, r.toEntity(att))
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	res := r.toDomain(entity)
+	// 对象写失败了，记录也没人引用，会被当成孤儿回收
+	err = objstore.PutIfAbsent(ctx, r.store, r.key(res, false), data, res.ContentType)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	err = objstore.PutIfAbsent(ctx, r.store, r.key(res, true), thumb, mime.TypeByExtension("."+res.ThumbExt))
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	return res, nil
+}
+
+func (r *attachmentRepository) GetByHash(ctx context.Context, hash string) (domain.Attachment, error) {
+	att, err := r.dao.GetByHash(ctx, hash)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	return r.toDomain(att), nil
+}
+
+func (r *attachmentRepository) GetContent(ctx context.Context, att domain.Attachment, thumb bool) ([]byte, error) {
+	return r.store.Get(ctx, r.key(att, thumb))
+}
+
+func (r *attachmentRepository) SetDraftReferences(ctx context.Context, aid int64, hashes []string) error {
+	return r.dao.SetDraftReferences(ctx, aid, hashes)
+}
+
+func (r *attachmentRepository) SetPubReferences(ctx context.Context, aid int64, hashes []string) error {
+	return r.dao.SetPubReferences(ctx, aid, hashes)
+}
+
+func (r *attachmentRepository) DeleteReferences(ctx context.Context, aid int64) error {
+	return r.dao.DeleteReferences(ctx, aid)
+}
+
+func (r *attachmentRepository) FindOrphans(ctx context.Context, before time.Time, limit int) ([]domain.Attachment, error) {
+	atts, err := r.dao.FindOrphans(ctx, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(atts, func(idx int, src dao.Attachment) domain.Attachment {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *attachmentRepository) DeleteOrphan(ctx context.Context, att domain.Attachment, before time.Time) (bool, error) {
+	ok, err := r.dao.DeleteOrphan(ctx, att.Id, before)
+	if err != nil || !ok {
+		return false, err
+	}
+	// 记录已经没了，对象删除失败只是多占一点空间，重新上传的时候也能接着用
+	err = r.store.Delete(ctx, r.key(att, false))
+	if err != nil {
+		return true, err
+	}
+	return true, r.store.Delete(ctx, r.key(att, true))
+}
+
+func (r *attachmentRepository) key(att domain.Attachment, thumb bool) string {
+	if thumb {
+		return attachmentLayout.Key(att.Hash) + "_thumb." + att.ThumbExt
+	}
+	return attachmentLayout.Key(att.Hash) + "." + att.Ext
+}
+
+func (r *attachmentRepository) toEntity(att domain.Attachment) dao.Attachment {
+	return dao.Attachment{
+		Id:          att.Id,
+		Hash:        att.Hash,
+		Ext:         att.Ext,
+		ThumbExt:    att.ThumbExt,
+		ContentType: att.ContentType,
+		Size:        att.Size,
+		Width:       att.Width,
+		Height:      att.Height,
+		Uploader:    att.Uploader,
+	}
+}
+
+func (r *attachmentRepository) toDomain(att dao.Attachment) domain.Attachment {
+	return domain.Attachment{
+		Id:          att.Id,
+		Hash:        att.Hash,
+		Ext:         att.Ext,
+		ThumbExt:    att.ThumbExt,
+		ContentType: att.ContentType,
+		Size:        att.Size,
+		Width:       att.Width,
+		Height:      att.Height,
+		Uploader:    att.Uploader,
+		Ctime:       time.UnixMilli(att.Ctime),
+		Utime:       time.UnixMilli(att.Utime),
+	}
+}
@@ -18,6 +18,10 @@ type ArticleCache interface {
 	SetFirstPage(ctx context.Context, uid int64, res []domain.Article) error
 	Get(ctx context.Context, id int64) (domain.Article, error)
 	Set(ctx context.Context, article domain.Article) error
+	// Del 删除草稿的缓存
+	Del(ctx context.Context, id int64) error
+	// DelPub 删除线上版本的缓存，包括 GetArticlesByIds 用的摘要
+	DelPub(ctx context.Context, id int64) error
 	GetPub(ctx context.Context, id int64) (domain.Article, error)
 	GetArticlesByIds(ctx context.Context, ids []int64) ([]domain.Article, []int64, error)
 	SetArticles(ctx context.Context, articles []domain.Article) error
@@ -122,6 +126,14 @@ func (a *ArticleRedisCache) Get(ctx context.Context, id int64) (domain.Article,
 	return res, err
 }
 
+func (a *ArticleRedisCache) Del(ctx context.Context, id int64) error {
+	return a.client.Del(ctx, a.key(id)).Err()
+}
+
+func (a *ArticleRedisCache) DelPub(ctx context.Context, id int64) error {
+	return a.client.Del(ctx, a.pubKey(id), fmt.Sprintf("article:%d", id)).Err()
+}
+
 func NewArticleRedisCache(c redis.Cmdable) ArticleCache {
 	return &ArticleRedisCache{
 		client: c,
@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// FeedCache 推拉结合的信息流。
+// 收件箱（inbox）是推过来的文章，发件箱（outbox）是作者自己发表的文章，都是 ZSET，
+// member 是文章 ID，score 是进入信息流的毫秒数
+type FeedCache interface {
+	// PushInbox 把文章推到这些用户的收件箱，已经在里面的不会改变位置
+	PushInbox(ctx context.Context, uids []int64, aid int64, ftime time.Time) error
+	// AddOutbox 已经在里面的不会改变位置，所以修改之后重新发表不会把文章顶上去
+	AddOutbox(ctx context.Context, uid int64, aid int64, ftime time.Time) error
+	RemoveOutbox(ctx context.Context, uid int64, aid int64) error
+	// ListInbox 和 ListOutbox 按照 Ftime 和文章 ID 倒序，
+	// cursor 是上一页最后一条，零值表示第一页。返回的 Article 只有 Id
+	ListInbox(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error)
+	ListOutbox(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error)
+	// FillInbox 把拉过来的文章一次性放进收件箱
+	FillInbox(ctx context.Context, uid int64, items []domain.FeedItem) error
+	// SetActive 记录用户最近一次看信息流的时间
+	SetActive(ctx context.Context, uid int64, t time.Time) error
+	// FilterActive 返回 since 之后看过信息流的用户
+	FilterActive(ctx context.Context, uids []int64, since time.Time) ([]int64, error)
+	// SetBigV 粉丝多的作者发表文章的时候不推，由粉丝自己来拉
+	SetBigV(ctx context.Context, uid int64, bigV bool) error
+	FilterBigV(ctx context.Context, uids []int64) ([]int64, error)
+}
+
+type FeedRedisCache struct {
+	client redis.Cmdable
+	// boxSize 收件箱和发件箱最多保留的文章数，更早的只能翻作者的主页了
+	boxSize int64
+	// activeExpiration 太久没有来过的用户就当成从来没有来过
+	activeExpiration time.Duration
+	bigVKey          string
+}
+
+func NewFeedRedisCache(client redis.Cmdable) FeedCache {
+	return &FeedRedisCache{
+		client:           client,
+		boxSize:          1000,
+		activeExpiration: time.Hour * 24 * 30,
+		bigVKey:          "feed:bigv",
+	}
+}
+
+func (f *FeedRedisCache) PushInbox(ctx context.Context, uids []int64, aid int64, ftime time.Time) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	member := redis.Z{Score: float64(ftime.UnixMilli()), Member: aid}
+	pipe := f.client.Pipeline()
+	for _, uid := range uids {
+		key := f.inboxKey(uid)
+		pipe.ZAddNX(ctx, key, member)
+		pipe.ZRemRangeByRank(ctx, key, 0, -f.boxSize-1)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (f *FeedRedisCache) FillInbox(ctx context.Context, uid int64, items []domain.FeedItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	members := make([]redis.Z, 0, len(items))
+	for _, item := range items {
+		members = append(members, redis.Z{
+			Score:  float64(item.Ftime.UnixMilli()),
+			Member: item.Article.Id,
+		})
+	}
+	key := f.inboxKey(uid)
+	pipe := f.client.Pipeline()
+	pipe.ZAddNX(ctx, key, members...)
+	pipe.ZRemRangeByRank(ctx, key, 0, -f.boxSize-1)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (f *FeedRedisCache) AddOutbox(ctx context.Context, uid int64, aid int64, ftime time.Time) error {
+	key := f.outboxKey(uid)
+	pipe := f.client.Pipeline()
+	pipe.ZAddNX(ctx, key, redis.Z{Score: float64(ftime.UnixMilli()), Member: aid})
+	pipe.ZRemRangeByRank(ctx, key, 0, -f.boxSize-1)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (f *FeedRedisCache) RemoveOutbox(ctx context.Context, uid int64, aid int64) error {
+	return f.client.ZRem(ctx, f.outboxKey(uid), aid).Err()
+}
+
+func (f *FeedRedisCache) ListInbox(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	return f.list(ctx, f.inboxKey(uid), cursor, limit)
+}
+
+func (f *FeedRedisCache) ListOutbox(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	return f.list(ctx, f.outboxKey(uid), cursor, limit)
+}
+
+func (f *FeedRedisCache) list(ctx context.Context, key string, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	maxScore := "+inf"
+	var ties int64
+	if cursor.Id > 0 {
+		// score 相同的时候按照 member 倒序，所以和游标同一毫秒的要多查出来再过滤
+		maxScore = strconv.FormatInt(cursor.Utime.UnixMilli(), 10)
+		var err error
+		ties, err = f.client.ZCount(ctx, key, maxScore, maxScore).Result()
+		if err != nil {
+			return nil, err
+		}
+	}
+	zs, err := f.client.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Max:   maxScore,
+		Min:   "-inf",
+		Count: int64(limit) + ties,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.FeedItem, 0, len(zs))
+	for _, z := range zs {
+		aid, er := strconv.ParseInt(z.Member.(string), 10, 64)
+		if er != nil {
+			return nil, er
+		}
+		ms := int64(z.Score)
+		if cursor.Id > 0 && ms == cursor.Utime.UnixMilli() && aid >= cursor.Id {
+			continue
+		}
+		res = append(res, domain.FeedItem{
+			Article: domain.Article{Id: aid},
+			Ftime:   time.UnixMilli(ms),
+		})
+	}
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func (f *FeedRedisCache) SetActive(ctx context.Context, uid int64, t time.Time) error {
+	return f.client.Set(ctx, f.activeKey(uid), t.UnixMilli(), f.activeExpiration).Err()
+}
+
+func (f *FeedRedisCache) FilterActive(ctx context.Context, uids []int64, since time.Time) ([]int64, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		keys = append(keys, f.activeKey(uid))
+	}
+	vals, err := f.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int64, 0, len(uids))
+	for i, val := range vals {
+		str, ok := val.(string)
+		if !ok {
+			// 没有记录
+			continue
+		}
+		ms, er := strconv.ParseInt(str, 10, 64)
+		if er == nil && ms >= since.UnixMilli() {
+			res = append(res, uids[i])
+		}
+	}
+	return res, nil
+}
+
+func (f *FeedRedisCache) SetBigV(ctx context.Context, uid int64, bigV bool) error {
+	if bigV {
+		return f.client.SAdd(ctx, f.bigVKey, uid).Err()
+	}
+	return f.client.SRem(ctx, f.bigVKey, uid).Err()
+}
+
+func (f *FeedRedisCache) FilterBigV(ctx context.Context, uids []int64) ([]int64, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+	members := make([]any, 0, len(uids))
+	for _, uid := range uids {
+		members = append(members, uid)
+	}
+	oks, err := f.client.SMIsMember(ctx, f.bigVKey, members...).Result()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int64, 0, len(uids))
+	for i, ok := range oks {
+		if ok {
+			res = append(res, uids[i])
+		}
+	}
+	return res, nil
+}
+
+func (f *FeedRedisCache) inboxKey(uid int64) string {
+	return fmt.Sprintf("feed:inbox:%d", uid)
+}
+
+func (f *FeedRedisCache) outboxKey(uid int64) string {
+	return fmt.Sprintf("feed:outbox:%d", uid)
+}
+
+func (f *FeedRedisCache) activeKey(uid int64) string {
+	return fmt.Sprintf("feed:active:%d", uid)
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+type FollowCache interface {
+	// GetStatics 没有缓存的时候返回 ErrKeyNotExist
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	SetStatics(ctx context.Context, uid int64, statics domain.FollowStatics) error
+	DelStatics(ctx context.Context, uid int64) error
+}
+
+type FollowRedisCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewFollowRedisCache(client redis.Cmdable) FollowCache {
+	return &FollowRedisCache{
+		client:     client,
+		expiration: time.Minute * 15,
+	}
+}
+
+const (
+	fieldFollowers = "followers"
+	fieldFollowees = "followees"
+)
+
+func (f *FollowRedisCache) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	data, err := f.client.HGetAll(ctx, f.staticsKey(uid)).Result()
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	if len(data) == 0 {
+		return domain.FollowStatics{}, ErrKeyNotExist
+	}
+	var res domain.FollowStatics
+	// 字段解析不了的时候就是 0，下一次变更会把缓存删掉
+	res.Followers, _ = strconv.ParseInt(data[fieldFollowers], 10, 64)
+	res.Followees, _ = strconv.ParseInt(data[fieldFollowees], 10, 64)
+	return res, nil
+}
+
+func (f *FollowRedisCache) SetStatics(ctx context.Context, uid int64, statics domain.FollowStatics) error {
+	key := f.staticsKey(uid)
+	pipe := f.client.TxPipeline()
+	pipe.HSet(ctx, key, fieldFollowers, statics.Followers, fieldFollowees, statics.Followees)
+	pipe.Expire(ctx, key, f.expiration)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (f *FollowRedisCache) DelStatics(ctx context.Context, uid int64) error {
+	return f.client.Del(ctx, f.staticsKey(uid)).Err()
+}
+
+func (f *FollowRedisCache) staticsKey(uid int64) string {
+	return fmt.Sprintf("follow:statics:%d", uid)
+}
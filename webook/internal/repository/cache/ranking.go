@@ -11,6 +11,9 @@ import (
 type RankingCache interface {
 	Set(ctx context.Context, articles []domain.Article) error
 	Get(ctx context.Context) ([]domain.Article, error)
+	// SetByTag 和 GetByTag 是某个标签下的热榜
+	SetByTag(ctx context.Context, tag string, articles []domain.Article) error
+	GetByTag(ctx context.Context, tag string) ([]domain.Article, error)
 }
 
 type RankingRedisCache struct {
@@ -29,6 +32,31 @@ func (r *RankingRedisCache) Get(ctx context.Context) ([]domain.Article, error) {
 	return res, err
 }
 
+func (r *RankingRedisCache) GetByTag(ctx context.Context, tag string) ([]domain.Article, error) {
+	val, err := r.client.Get(ctx, r.tagKey(tag)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Article
+	err = json.Unmarshal(val, &res)
+	return res, err
+}
+
+func (r *RankingRedisCache) SetByTag(ctx context.Context, tag string, articles []domain.Article) error {
+	for i := range articles {
+		articles[i].Content = articles[i].Abstract()
+	}
+	bytes, err := json.Marshal(articles)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.tagKey(tag), bytes, r.expiration).Err()
+}
+
+func (r *RankingRedisCache) tagKey(tag string) string {
+	return r.key + ":tag:" + tag
+}
+
 func NewRankingRedisCache(client redis.Cmdable) RankingCache {
 	return &RankingRedisCache{client: client, key: "ranking:top_n", expiration: time.Minute * 3}
 }
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// RelatedArticleCache 每篇文章的相关文章，离线任务重新算完之后覆盖
+type RelatedArticleCache interface {
+	// Get 没有缓存的时候返回 ErrKeyNotExist
+	Get(ctx context.Context, aid int64) ([]domain.RelatedArticle, error)
+	Set(ctx context.Context, aid int64, rels []domain.RelatedArticle) error
+}
+
+type RelatedArticleRedisCache struct {
+	client redis.Cmdable
+	// expiration 比离线任务的周期长，正常情况下在过期之前就被覆盖了
+	expiration time.Duration
+}
+
+func NewRelatedArticleRedisCache(client redis.Cmdable) RelatedArticleCache {
+	return &RelatedArticleRedisCache{
+		client:     client,
+		expiration: time.Hour * 24,
+	}
+}
+
+func (r *RelatedArticleRedisCache) Get(ctx context.Context, aid int64) ([]domain.RelatedArticle, error) {
+	val, err := r.client.Get(ctx, r.key(aid)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.RelatedArticle
+	err = json.Unmarshal(val, &res)
+	return res, err
+}
+
+func (r *RelatedArticleRedisCache) Set(ctx context.Context, aid int64, rels []domain.RelatedArticle) error {
+	// 没有相关文章也要缓存，不然每次都会查数据库
+	if rels == nil {
+		rels = []domain.RelatedArticle{}
+	}
+	val, err := json.Marshal(rels)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.key(aid), val, r.expiration).Err()
+}
+
+func (r *RelatedArticleRedisCache) key(aid int64) string {
+	return fmt.Sprintf("article:related:%d", aid)
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// SeriesCache 专栏本身，以及文章在哪个专栏里面，文章详情页每次都要查
+type SeriesCache interface {
+	// Get 没有缓存的时候返回 ErrKeyNotExist
+	Get(ctx context.Context, sid int64) (domain.Series, error)
+	Set(ctx context.Context, s domain.Series) error
+	Del(ctx context.Context, sid int64) error
+	// GetSid 文章所在的专栏，0 表示不在任何专栏里面，没有缓存的时候返回 ErrKeyNotExist
+	GetSid(ctx context.Context, aid int64) (int64, error)
+	SetSid(ctx context.Context, aid, sid int64) error
+	DelSid(ctx context.Context, aids ...int64) error
+}
+
+type SeriesRedisCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewSeriesRedisCache(client redis.Cmdable) SeriesCache {
+	return &SeriesRedisCache{
+		client:     client,
+		expiration: time.Minute * 30,
+	}
+}
+
+func (s *SeriesRedisCache) Get(ctx context.Context, sid int64) (domain.Series, error) {
+	val, err := s.client.Get(ctx, s.key(sid)).Bytes()
+	if err != nil {
+		return domain.Series{}, err
+	}
+	var res domain.Series
+	err = json.Unmarshal(val, &res)
+	return res, err
+}
+
+func (s *SeriesRedisCache) Set(ctx context.Context, series domain.Series) error {
+	val, err := json.Marshal(series)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.key(series.Id), val, s.expiration).Err()
+}
+
+func (s *SeriesRedisCache) Del(ctx context.Context, sid int64) error {
+	return s.client.Del(ctx, s.key(sid)).Err()
+}
+
+func (s *SeriesRedisCache) GetSid(ctx context.Context, aid int64) (int64, error) {
+	val, err := s.client.Get(ctx, s.sidKey(aid)).Result()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+func (s *SeriesRedisCache) SetSid(ctx context.Context, aid, sid int64) error {
+	return s.client.Set(ctx, s.sidKey(aid), sid, s.expiration).Err()
+}
+
+func (s *SeriesRedisCache) DelSid(ctx context.Context, aids ...int64) error {
+	if len(aids) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(aids))
+	for _, aid := range aids {
+		keys = append(keys, s.sidKey(aid))
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func (s *SeriesRedisCache) key(sid int64) string {
+	return fmt.Sprintf("series:%d", sid)
+}
+
+func (s *SeriesRedisCache) sidKey(aid int64) string {
+	return fmt.Sprintf("article:series:%d", aid)
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// SyndicationCache 渲染好的订阅源，作者发表文章的时候删掉
+type SyndicationCache interface {
+	// GetAuthor 和 GetHot 没有缓存的时候返回 ErrKeyNotExist
+	GetAuthor(ctx context.Context, uid int64, format domain.SyndicationFormat) (domain.SyndicationDocument, error)
+	SetAuthor(ctx context.Context, uid int64, format domain.SyndicationFormat, doc domain.SyndicationDocument) error
+	// DelAuthor 删掉作者所有格式的订阅源
+	DelAuthor(ctx context.Context, uid int64) error
+	GetHot(ctx context.Context, format domain.SyndicationFormat) (domain.SyndicationDocument, error)
+	SetHot(ctx context.Context, format domain.SyndicationFormat, doc domain.SyndicationDocument) error
+}
+
+type SyndicationRedisCache struct {
+	client redis.Cmdable
+	// authorExpiration 作者的订阅源发表文章的时候会删掉，过期只是兜底
+	authorExpiration time.Duration
+	// hotExpiration 热榜是定时任务算的，不会通知这里
+	hotExpiration time.Duration
+}
+
+func NewSyndicationRedisCache(client redis.Cmdable) SyndicationCache {
+	return &SyndicationRedisCache{
+		client:           client,
+		authorExpiration: time.Hour * 24,
+		hotExpiration:    time.Minute * 10,
+	}
+}
+
+func (s *SyndicationRedisCache) GetAuthor(ctx context.Context, uid int64, format domain.SyndicationFormat) (domain.SyndicationDocument, error) {
+	return s.get(ctx, s.authorKey(uid, format))
+}
+
+func (s *SyndicationRedisCache) SetAuthor(ctx context.Context, uid int64, format domain.SyndicationFormat, doc domain.SyndicationDocument) error {
+	return s.set(ctx, s.authorKey(uid, format), doc, s.authorExpiration)
+}
+
+func (s *SyndicationRedisCache) DelAuthor(ctx context.Context, uid int64) error {
+	return s.client.Del(ctx, s.authorKey(uid, domain.SyndicationRSS),
+		s.authorKey(uid, domain.SyndicationAtom)).Err()
+}
+
+func (s *SyndicationRedisCache) GetHot(ctx context.Context, format domain.SyndicationFormat) (domain.SyndicationDocument, error) {
+	return s.get(ctx, s.hotKey(format))
+}
+
+func (s *SyndicationRedisCache) SetHot(ctx context.Context, format domain.SyndicationFormat, doc domain.SyndicationDocument) error {
+	return s.set(ctx, s.hotKey(format), doc, s.hotExpiration)
+}
+
+func (s *SyndicationRedisCache) get(ctx context.Context, key string) (domain.SyndicationDocument, error) {
+	val, err := s.client.Get(ctx, key).Bytes()
+	if err != nil {
+		return domain.SyndicationDocument{}, err
+	}
+	var doc domain.SyndicationDocument
+	err = json.Unmarshal(val, &doc)
+	return doc, err
+}
+
+func (s *SyndicationRedisCache) set(ctx context.Context, key string, doc domain.SyndicationDocument, expiration time.Duration) error {
+	val, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, key, val, expiration).Err()
+}
+
+func (s *SyndicationRedisCache) authorKey(uid int64, format domain.SyndicationFormat) string {
+	return fmt.Sprintf("syndication:author:%d:%s", uid, format)
+}
+
+func (s *SyndicationRedisCache) hotKey(format domain.SyndicationFormat) string {
+	return fmt.Sprintf("syndication:hot:%s", format)
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"time"
+)
+
+var ErrCommentNotFound = dao.ErrRecordNotFound
+
+type CommentRepository interface {
+	Create(ctx context.Context, c domain.Comment) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Comment, error)
+	// FindRoots 顶层评论，新的在前，maxId 为 0 表示第一页
+	FindRoots(ctx context.Context, biz string, bizId int64, status domain.CommentStatus, maxId int64, limit int) ([]domain.Comment, error)
+	// FindReplies 某条顶层评论下的回复，旧的在前
+	FindReplies(ctx context.Context, rootId int64, status domain.CommentStatus, minId int64, limit int) ([]domain.Comment, error)
+	// Delete 删除评论以及它下面的回复，返回被删除的评论
+	Delete(ctx context.Context, id int64) ([]domain.Comment, error)
+	// UpdateStatus 返回修改之前的评论
+	UpdateStatus(ctx context.Context, id int64, status domain.CommentStatus) (domain.Comment, error)
+}
+
+type commentRepository struct {
+	dao dao.CommentDAO
+}
+
+func NewCommentRepository(dao dao.CommentDAO) CommentRepository {
+	return &commentRepository{
+		dao: dao,
+	}
+}
+
+func (r *commentRepository) Create(ctx context.Context, c domain.Comment) (int64, error) {
+	return r.dao.Insert(ctx, r.toEntity(c))
+}
+
+func (r *commentRepository) GetById(ctx context.Context, id int64) (domain.Comment, error) {
+	c, err := r.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return r.toDomain(c), nil
+}
+
+func (r *commentRepository) FindRoots(ctx context.Context, biz string, bizId int64, status domain.CommentStatus, maxId int64, limit int) ([]domain.Comment, error) {
+	cs, err := r.dao.FindRoots(ctx, biz, bizId, status.ToUint8(), maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(cs), nil
+}
+
+func (r *commentRepository) FindReplies(ctx context.Context, rootId int64, status domain.CommentStatus, minId int64, limit int) ([]domain.Comment, error) {
+	cs, err := r.dao.FindReplies(ctx, rootId, status.ToUint8(), minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(cs), nil
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id int64) ([]domain.Comment, error) {
+	cs, err := r.dao.Delete(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(cs), nil
+}
+
+func (r *commentRepository) UpdateStatus(ctx context.Context, id int64, status domain.CommentStatus) (domain.Comment, error) {
+	c, err := r.dao.UpdateStatus(ctx, id, status.ToUint8())
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return r.toDomain(c), nil
+}
+
+func (r *commentRepository) toDomains(cs []dao.Comment) []domain.Comment {
+	return slice.Map[dao.Comment, domain.Comment](cs, func(idx int, src dao.Comment) domain.Comment {
+		return r.toDomain(src)
+	})
+}
+
+func (r *commentRepository) toDomain(c dao.Comment) domain.Comment {
+	return domain.Comment{
+		Id:          c.Id,
+		Biz:         c.Biz,
+		BizId:       c.BizId,
+		Commentator: domain.Author{Id: c.Uid},
+		Content:     c.Content,
+		RootId:      c.RootId,
+		ParentId:    c.ParentId,
+		ReplyTo:     domain.Author{Id: c.ReplyToUid},
+		Status:      domain.CommentStatus(c.Status),
+		Ctime:       time.UnixMilli(c.Ctime),
+		Utime:       time.UnixMilli(c.Utime),
+	}
+}
+
+func (r *commentRepository) toEntity(c domain.Comment) dao.Comment {
+	return dao.Comment{
+		Id:         c.Id,
+		Biz:        c.Biz,
+		BizId:      c.BizId,
+		RootId:     c.RootId,
+		ParentId:   c.ParentId,
+		Uid:        c.Commentator.Id,
+		ReplyToUid: c.ReplyTo.Id,
+		Content:    c.Content,
+		Status:     c.Status.ToUint8(),
+	}
+}
@@ -14,21 +14,65 @@ type ArticleDAO interface {
 	UpdateById(ctx context.Context, entity Article) error
 	Sync(ctx context.Context, entity Article) (int64, error)
 	GetByAuthor(ctx context.Context, uid int64, limit int, offset int) ([]Article, int64, error)
+	// FindByAuthor 按照 id 遍历作者的全部草稿，minId 是上一批最后一篇的 id
+	FindByAuthor(ctx context.Context, uid int64, minId int64, limit int) ([]Article, error)
 	SyncStatus(ctx context.Context, uid int64, id int64, status uint8) error
 	GetById(ctx context.Context, id int64) (Article, error)
 	GetPubById(ctx context.Context, id int64) (PublishedArticle, error)
 	ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error)
+	// ListPubByCursor 已发表的文章，按照 utime, id 倒序，只返回排在 (utime, id) 之后的。
+	// utime 为 0 表示从最新的开始
+	ListPubByCursor(ctx context.Context, utime int64, id int64, limit int) ([]PublishedArticle, error)
+	// ListPubByCursorWithContent 和 ListPubByCursor 一样，但是一定会带上内容，
+	// 给重建搜索索引、计算相关文章这种要用到内容的后台任务用
+	ListPubByCursorWithContent(ctx context.Context, utime int64, id int64, limit int) ([]PublishedArticle, error)
+	// ListPubByAuthor 作者已发表的文章，按照更新时间倒序
+	ListPubByAuthor(ctx context.Context, uid int64, limit int) ([]PublishedArticle, error)
 	GetByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error)
+
+	// ListScheduled 作者的定时发表列表，按照发表时间排序
+	ListScheduled(ctx context.Context, uid int64, offset int, limit int) ([]Article, int64, error)
+	// FindDueScheduled 找出已经到了发表时间，但是还没有发表的文章
+	FindDueScheduled(ctx context.Context, now time.Time, limit int) ([]Article, error)
+	Reschedule(ctx context.Context, uid int64, id int64, publishAt int64) error
+	CancelSchedule(ctx context.Context, uid int64, id int64) error
+	// TransitStatus 只有草稿处于 from 状态的时候才改成 to，不然返回 ErrArticleStatusMismatch
+	TransitStatus(ctx context.Context, uid int64, id int64, from uint8, to uint8) error
+
+	// Delete 把草稿和线上版本一起挪到回收站，不是这个作者的文章返回 ErrArticleNotFound
+	Delete(ctx context.Context, uid int64, id int64) error
+	// ListDeleted 作者的回收站，最近删除的在前
+	ListDeleted(ctx context.Context, uid int64, offset int, limit int) ([]DeletedArticle, int64, error)
+	// Restore 从回收站恢复，回收站里面没有的返回 ErrDeletedArticleNotFound
+	Restore(ctx context.Context, uid int64, id int64) (DeletedArticle, error)
+	// FindExpiredDeleted 删除时间早于 before 的，先删除的在前
+	FindExpiredDeleted(ctx context.Context, before int64, limit int) ([]DeletedArticle, error)
+	// Purge 从回收站里面彻底删除，包括对象存储里面的内容
+	Purge(ctx context.Context, id int64) error
 }
 
+var (
+	ErrArticleNotScheduled = errors.New("文章不存在或者不是定时发表状态")
+	// ErrArticleVersionConflict 草稿已经被别人（比如另外一个编辑页面）修改过了
+	ErrArticleVersionConflict = errors.New("文章版本冲突")
+	ErrArticleStatusMismatch  = errors.New("文章不存在或者状态已经变了")
+)
+
 type Article struct {
 	Id       int64  `gorm:"primaryKey,autoIncrement" bson:"id,omitempty"`
 	Title    string `gorm:"type=varchar(4096)" bson:"title,omitempty"`
 	Content  string `gorm:"type=BLOB" bson:"content,omitempty"`
 	AuthorId int64  `gorm:"index" bson:"author_id,omitempty"`
 	Ctime    int64  `bson:"ctime,omitempty"`
-	Utime    int64  `bson:"utime,omitempty"`
-	Status   uint8  `bson:"status,omitempty"`
+	// 按照 status, utime 建联合索引，游标翻页用得上
+	Utime  int64 `gorm:"index:idx_status_utime,priority:2" bson:"utime,omitempty"`
+	Status uint8 `gorm:"index:idx_status_utime,priority:1" bson:"status,omitempty"`
+	// PublishAt 定时发表的时间，只有定时发表状态下才有意义
+	PublishAt int64 `gorm:"index" bson:"publish_at,omitempty"`
+	// Version 乐观锁，每次修改内容都会加一
+	Version int64 `gorm:"not null;default:1" bson:"version,omitempty"`
+	// Price 价格，单位是分，0 表示免费
+	Price int64 `gorm:"not null;default:0" bson:"price,omitempty"`
 }
 
 type PublishedArticle Article
@@ -53,6 +97,90 @@ func (a *ArticleGORMDAO) GetByIds(ctx context.Context, ids []int64) ([]Published
 	return articles, nil
 }
 
+func (a *ArticleGORMDAO) ListScheduled(ctx context.Context, uid int64, offset int, limit int) ([]Article, int64, error) {
+	var (
+		arts  []Article
+		count int64
+	)
+	db := a.db.WithContext(ctx).Model(&Article{}).
+		Where("author_id = ? AND status = ?", uid, domain.ArticleStatusScheduled)
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("publish_at ASC").
+		Offset(offset).Limit(limit).
+		Find(&arts).Error
+	return arts, count, err
+}
+
+func (a *ArticleGORMDAO) FindByAuthor(ctx context.Context, uid int64, minId int64, limit int) ([]Article, error) {
+	var arts []Article
+	err := a.db.WithContext(ctx).
+		Where("author_id = ? AND id > ?", uid, minId).
+		Order("id ASC").
+		Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (a *ArticleGORMDAO) FindDueScheduled(ctx context.Context, now time.Time, limit int) ([]Article, error) {
+	var arts []Article
+	err := a.db.WithContext(ctx).
+		Where("status = ? AND publish_at <= ?",
+			domain.ArticleStatusScheduled, now.UnixMilli()).
+		Order("publish_at ASC").
+		Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (a *ArticleGORMDAO) Reschedule(ctx context.Context, uid int64, id int64, publishAt int64) error {
+	return a.updateScheduled(ctx, uid, id, map[string]any{
+		"publish_at": publishAt,
+		"utime":      time.Now().UnixMilli(),
+	})
+}
+
+func (a *ArticleGORMDAO) CancelSchedule(ctx context.Context, uid int64, id int64) error {
+	// 取消之后退回到未发表的草稿
+	return a.updateScheduled(ctx, uid, id, map[string]any{
+		"status":     domain.ArticleStatusUnpublished,
+		"publish_at": 0,
+		"utime":      time.Now().UnixMilli(),
+	})
+}
+
+// updateScheduled 只更新处于定时发表状态的文章，已经被发表出去的就不能再改了
+func (a *ArticleGORMDAO) updateScheduled(ctx context.Context, uid int64, id int64, updates map[string]any) error {
+	res := a.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ?",
+			id, uid, domain.ArticleStatusScheduled).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrArticleNotScheduled
+	}
+	return nil
+}
+
+func (a *ArticleGORMDAO) TransitStatus(ctx context.Context, uid int64, id int64, from uint8, to uint8) error {
+	res := a.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ?", id, uid, from).
+		Updates(map[string]any{
+			"status": to,
+			"utime":  time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrArticleStatusMismatch
+	}
+	return nil
+}
+
 func (a *ArticleGORMDAO) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*100)
 	defer cancel()
@@ -65,6 +193,32 @@ func (a *ArticleGORMDAO) ListPub(ctx context.Context, start time.Time, offset in
 	return res, err
 }
 
+func (a *ArticleGORMDAO) ListPubByAuthor(ctx context.Context, uid int64, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := a.db.WithContext(ctx).
+		Where("author_id = ? AND status = ?", uid, domain.ArticleStatusPublished).
+		Order("utime DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (a *ArticleGORMDAO) ListPubByCursor(ctx context.Context, utime int64, id int64, limit int) ([]PublishedArticle, error) {
+	db := a.db.WithContext(ctx).Where("status = ?", domain.ArticleStatusPublished)
+	if utime > 0 {
+		db = db.Where("utime < ? OR (utime = ? AND id < ?)", utime, utime, id)
+	}
+	var res []PublishedArticle
+	err := db.Order("utime DESC").Order("id DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (a *ArticleGORMDAO) ListPubByCursorWithContent(ctx context.Context, utime int64, id int64, limit int) ([]PublishedArticle, error) {
+	return a.ListPubByCursor(ctx, utime, id, limit)
+}
+
 func (a *ArticleGORMDAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
 	var res PublishedArticle
 	err := a.db.WithContext(ctx).
@@ -107,7 +261,11 @@ func (a *ArticleGORMDAO) SyncStatus(ctx context.Context, uid int64, id int64, st
 func (a *ArticleGORMDAO) GetByAuthor(ctx context.Context, uid int64, pageIndex int, pageSize int) ([]Article, int64, error) {
 	var arts []Article
 	var count int64
-	if err := a.db.WithContext(ctx).Count(&count).Where("author_id = ?", uid).
+	if err := a.db.WithContext(ctx).Model(&Article{}).
+		Where("author_id = ?", uid).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := a.db.WithContext(ctx).Where("author_id = ?", uid).
 		Offset((pageIndex - 1) * pageSize).Limit(pageSize).Order("utime desc").
 		Find(&arts).Error; err != nil {
 		return nil, 0, err
@@ -149,6 +307,7 @@ func (a *ArticleGORMDAO) Sync(ctx context.Context, art Article) (int64, error) {
 				"content": pubArt.Content,
 				"utime":   now,
 				"status":  pubArt.Status,
+				"price":   pubArt.Price,
 			}),
 		}).Create(&pubArt).Error
 		return err
@@ -160,26 +319,48 @@ func (a *ArticleGORMDAO) Insert(ctx context.Context, article Article) (int64, er
 	now := time.Now().UnixMilli()
 	article.Ctime = now
 	article.Utime = now
+	article.Version = 1
 	err := a.db.WithContext(ctx).Create(&article).Error
 	return article.Id, err
 }
 
+// UpdateById 更新草稿。art.Version 大于 0 的时候，只有数据库里面的版本和它一样才会更新，
+// 不然返回 ErrArticleVersionConflict
 func (a *ArticleGORMDAO) UpdateById(ctx context.Context, art Article) error {
 	now := time.Now().UnixMilli()
-	res := a.db.WithContext(ctx).Model(&Article{}).
+	db := a.db.WithContext(ctx).Model(&Article{}).
 		Where("id = ?", art.Id).
-		Where("author_id = ?", art.AuthorId).
-		Updates(map[string]any{
-			"title":   art.Title,
-			"content": art.Content,
-			"status":  art.Status,
-			"utime":   now,
-		})
+		Where("author_id = ?", art.AuthorId)
+	if art.Version > 0 {
+		db = db.Where("version = ?", art.Version)
+	}
+	res := db.Updates(map[string]any{
+		"title":      art.Title,
+		"content":    art.Content,
+		"status":     art.Status,
+		"publish_at": art.PublishAt,
+		"price":      art.Price,
+		"version":    gorm.Expr("version + 1"),
+		"utime":      now,
+	})
 	if res.Error != nil {
 		return res.Error
 	}
 
 	if res.RowsAffected == 0 {
+		if art.Version > 0 {
+			// 区分一下是版本不对，还是 ID 或者作者不对
+			var cnt int64
+			err := a.db.WithContext(ctx).Model(&Article{}).
+				Where("id = ? AND author_id = ?", art.Id, art.AuthorId).
+				Count(&cnt).Error
+			if err != nil {
+				return err
+			}
+			if cnt > 0 {
+				return ErrArticleVersionConflict
+			}
+		}
 		return errors.New("发生错误，Id或作者有误")
 	}
 	return nil
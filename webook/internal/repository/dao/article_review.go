@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrArticleReviewNotPending = errors.New("审核记录不存在或者已经处理过了")
+
+// ArticleReviewDAO 人工审核队列
+type ArticleReviewDAO interface {
+	Insert(ctx context.Context, r ArticleReview) (int64, error)
+	GetById(ctx context.Context, id int64) (ArticleReview, error)
+	// List status 为 0 的时候不按照状态过滤，先提交的在前
+	List(ctx context.Context, status uint8, offset int, limit int) ([]ArticleReview, int64, error)
+	// Finish 处理一条等待审核的记录，已经处理过的返回 ErrArticleReviewNotPending
+	Finish(ctx context.Context, id int64, status uint8, reviewerId int64, reason string) error
+}
+
+// ArticleReview 保留提交审核时候的内容，审核期间作者又改了文章也能发现
+type ArticleReview struct {
+	Id        int64  `gorm:"primaryKey,autoIncrement"`
+	ArticleId int64  `gorm:"index"`
+	AuthorId  int64  `gorm:"index"`
+	Title     string `gorm:"type=varchar(4096)"`
+	Content   string `gorm:"type=BLOB"`
+	// Hits 命中的敏感词，JSON 数组
+	Hits       string `gorm:"type=varchar(1024)"`
+	Status     uint8  `gorm:"index"`
+	ReviewerId int64
+	Reason     string `gorm:"type=varchar(1024)"`
+	Ctime      int64
+	Utime      int64
+}
+
+type ArticleReviewGORMDAO struct {
+	db *gorm.DB
+}
+
+func NewArticleReviewGORMDAO(db *gorm.DB) ArticleReviewDAO {
+	return &ArticleReviewGORMDAO{
+		db: db,
+	}
+}
+
+func (a *ArticleReviewGORMDAO) Insert(ctx context.Context, r ArticleReview) (int64, error) {
+	now := time.Now().UnixMilli()
+	r.Ctime = now
+	r.Utime = now
+	err := a.db.WithContext(ctx).Create(&r).Error
+	return r.Id, err
+}
+
+func (a *ArticleReviewGORMDAO) GetById(ctx context.Context, id int64) (ArticleReview, error) {
+	var r ArticleReview
+	err := a.db.WithContext(ctx).
+		Where("id = ?", id).First(&r).Error
+	return r, err
+}
+
+func (a *ArticleReviewGORMDAO) List(ctx context.Context, status uint8, offset int, limit int) ([]ArticleReview, int64, error) {
+	var (
+		res   []ArticleReview
+		count int64
+	)
+	db := a.db.WithContext(ctx).Model(&ArticleReview{})
+	if status > 0 {
+		db = db.Where("status = ?", status)
+	}
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("id ASC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, count, err
+}
+
+func (a *ArticleReviewGORMDAO) Finish(ctx context.Context, id int64, status uint8, reviewerId int64, reason string) error {
+	res := a.db.WithContext(ctx).Model(&ArticleReview{}).
+		Where("id = ? AND status = ?", id, domain.ReviewStatusPending).
+		Updates(map[string]any{
+			"status":      status,
+			"reviewer_id": reviewerId,
+			"reason":      reason,
+			"utime":       time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrArticleReviewNotPending
+	}
+	return nil
+}
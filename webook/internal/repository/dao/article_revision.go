@@ -0,0 +1,67 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ArticleRevisionDAO 文章的历史版本，只追加不修改
+type ArticleRevisionDAO interface {
+	Insert(ctx context.Context, rev ArticleRevision) (int64, error)
+	GetById(ctx context.Context, id int64) (ArticleRevision, error)
+	ListByArticle(ctx context.Context, uid int64, aid int64, offset int, limit int) ([]ArticleRevision, int64, error)
+}
+
+// ArticleRevision 每一次保存或者发表都会留下一条记录
+type ArticleRevision struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 按照文章查询历史版本
+	ArticleId int64  `gorm:"index:idx_article_id_ctime"`
+	AuthorId  int64  `gorm:"index"`
+	Title     string `gorm:"type=varchar(4096)"`
+	Content   string `gorm:"type=BLOB"`
+	Status    uint8
+	Ctime     int64 `gorm:"index:idx_article_id_ctime"`
+}
+
+type ArticleRevisionGORMDAO struct {
+	db *gorm.DB
+}
+
+func NewArticleRevisionGORMDAO(db *gorm.DB) ArticleRevisionDAO {
+	return &ArticleRevisionGORMDAO{
+		db: db,
+	}
+}
+
+func (a *ArticleRevisionGORMDAO) Insert(ctx context.Context, rev ArticleRevision) (int64, error) {
+	rev.Ctime = time.Now().UnixMilli()
+	err := a.db.WithContext(ctx).Create(&rev).Error
+	return rev.Id, err
+}
+
+func (a *ArticleRevisionGORMDAO) GetById(ctx context.Context, id int64) (ArticleRevision, error) {
+	var rev ArticleRevision
+	err := a.db.WithContext(ctx).
+		Where("id = ?", id).First(&rev).Error
+	return rev, err
+}
+
+func (a *ArticleRevisionGORMDAO) ListByArticle(ctx context.Context, uid int64, aid int64, offset int, limit int) ([]ArticleRevision, int64, error) {
+	var (
+		revs  []ArticleRevision
+		count int64
+	)
+	db := a.db.WithContext(ctx).Model(&ArticleRevision{}).
+		Where("article_id = ? AND author_id = ?", aid, uid)
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	// 列表页不需要内容，内容在查看和对比的时候再取
+	err := db.Select("id", "article_id", "author_id", "title", "status", "ctime").
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&revs).Error
+	return revs, count, err
+}
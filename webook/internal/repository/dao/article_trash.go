@@ -0,0 +1,179 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	ErrArticleNotFound        = errors.New("文章不存在或者不是这个作者的")
+	ErrDeletedArticleNotFound = errors.New("回收站里面没有这篇文章")
+)
+
+// DeletedArticle 回收站。删除的时候把制作库和线上库的记录原样挪到这里，
+// 恢复的时候再挪回去，所以别的查询都不需要关心删除
+type DeletedArticle struct {
+	// Id 就是文章的 ID
+	Id       int64  `gorm:"primaryKey" bson:"id,omitempty"`
+	AuthorId int64  `gorm:"index:idx_author_dtime,priority:1" bson:"author_id,omitempty"`
+	Title    string `gorm:"type=varchar(4096)" bson:"title,omitempty"`
+	// Draft 制作库的记录，JSON
+	Draft string `gorm:"type=BLOB" bson:"draft,omitempty"`
+	// Published 线上库的记录，JSON。没有发表过的是空的
+	Published string `gorm:"type=BLOB" bson:"published,omitempty"`
+	// ContentKey 线上库的内容放在对象存储里面的时候，彻底删除要看看还有没有别人用
+	ContentKey string `gorm:"type:varchar(256);index" bson:"content_key,omitempty"`
+	// Dtime 删除的时间
+	Dtime int64 `gorm:"index:idx_author_dtime,priority:2;index:idx_dtime" bson:"dtime,omitempty"`
+}
+
+// Article 解析出制作库的记录
+func (d DeletedArticle) Article() (Article, error) {
+	var art Article
+	err := json.Unmarshal([]byte(d.Draft), &art)
+	return art, err
+}
+
+func newDeletedArticle(art Article, pub any, contentKey string) (DeletedArticle, error) {
+	draft, err := json.Marshal(art)
+	if err != nil {
+		return DeletedArticle{}, err
+	}
+	res := DeletedArticle{
+		Id:         art.Id,
+		AuthorId:   art.AuthorId,
+		Title:      art.Title,
+		Draft:      string(draft),
+		ContentKey: contentKey,
+		Dtime:      time.Now().UnixMilli(),
+	}
+	if pub != nil {
+		val, err := json.Marshal(pub)
+		if err != nil {
+			return DeletedArticle{}, err
+		}
+		res.Published = string(val)
+	}
+	return res, nil
+}
+
+// moveToTrash 在一个事务里面把草稿和线上版本 P 挪到回收站
+func moveToTrash[P any](ctx context.Context, db *gorm.DB, uid int64, id int64,
+	contentKey func(pub P) string) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var art Article
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND author_id = ?", id, uid).
+			First(&art).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrArticleNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var (
+			pub    P
+			pubVal any
+			key    string
+		)
+		err = tx.Where("id = ?", id).First(&pub).Error
+		switch {
+		case err == nil:
+			pubVal, key = pub, contentKey(pub)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// 没有发表过
+		default:
+			return err
+		}
+		deleted, err := newDeletedArticle(art, pubVal, key)
+		if err != nil {
+			return err
+		}
+		if err = tx.Create(&deleted).Error; err != nil {
+			return err
+		}
+		if err = tx.Where("id = ?", id).Delete(&Article{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(new(P)).Error
+	})
+}
+
+// restoreFromTrash moveToTrash 的逆操作，草稿和线上版本恢复成删除之前的样子
+func restoreFromTrash[P any](ctx context.Context, db *gorm.DB, uid int64, id int64) (DeletedArticle, error) {
+	var deleted DeletedArticle
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND author_id = ?", id, uid).
+			First(&deleted).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDeletedArticleNotFound
+		}
+		if err != nil {
+			return err
+		}
+		art, err := deleted.Article()
+		if err != nil {
+			return err
+		}
+		if err = tx.Create(&art).Error; err != nil {
+			return err
+		}
+		if deleted.Published != "" {
+			var pub P
+			if err = json.Unmarshal([]byte(deleted.Published), &pub); err != nil {
+				return err
+			}
+			if err = tx.Create(&pub).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("id = ?", id).Delete(&DeletedArticle{}).Error
+	})
+	return deleted, err
+}
+
+func (a *ArticleGORMDAO) Delete(ctx context.Context, uid int64, id int64) error {
+	return moveToTrash[PublishedArticle](ctx, a.db, uid, id,
+		func(pub PublishedArticle) string { return "" })
+}
+
+func (a *ArticleGORMDAO) Restore(ctx context.Context, uid int64, id int64) (DeletedArticle, error) {
+	return restoreFromTrash[PublishedArticle](ctx, a.db, uid, id)
+}
+
+func (a *ArticleGORMDAO) ListDeleted(ctx context.Context, uid int64, offset int, limit int) ([]DeletedArticle, int64, error) {
+	var (
+		res   []DeletedArticle
+		count int64
+	)
+	db := a.db.WithContext(ctx).Model(&DeletedArticle{}).
+		Where("author_id = ?", uid)
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("dtime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, count, err
+}
+
+func (a *ArticleGORMDAO) FindExpiredDeleted(ctx context.Context, before int64, limit int) ([]DeletedArticle, error) {
+	var res []DeletedArticle
+	err := a.db.WithContext(ctx).
+		Where("dtime < ?", before).
+		Order("dtime ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (a *ArticleGORMDAO) Purge(ctx context.Context, id int64) error {
+	return a.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&DeletedArticle{}).Error
+}
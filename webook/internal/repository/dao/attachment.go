@@ -0,0 +1,172 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type AttachmentDAO interface {
+	// Upsert 同一个哈希的图片已经有了的时候只更新 utime，返回库里面的记录
+	Upsert(ctx context.Context, att Attachment) (Attachment, error)
+	GetByHash(ctx context.Context, hash string) (Attachment, error)
+	// SetDraftReferences 用 hashes 整体替换草稿引用的图片，库里面没有的哈希直接忽略
+	SetDraftReferences(ctx context.Context, aid int64, hashes []string) error
+	// SetPubReferences 和 SetDraftReferences 一样，替换的是线上版本引用的图片
+	SetPubReferences(ctx context.Context, aid int64, hashes []string) error
+	// DeleteReferences 草稿和线上版本的引用一起删掉
+	DeleteReferences(ctx context.Context, aid int64) error
+	// FindOrphans 找出 before 之前上传的、没有被任何文章引用的图片
+	FindOrphans(ctx context.Context, before time.Time, limit int) ([]Attachment, error)
+	// DeleteOrphan 删除的时候再检查一次，期间被引用或者重新上传了的不删，返回 false
+	DeleteOrphan(ctx context.Context, id int64, before time.Time) (bool, error)
+}
+
+type Attachment struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement"`
+	Hash        string `gorm:"type:char(64);uniqueIndex"`
+	Ext         string `gorm:"type:varchar(8)"`
+	ThumbExt    string `gorm:"type:varchar(8)"`
+	ContentType string `gorm:"type:varchar(32)"`
+	Size        int64
+	Width       int
+	Height      int
+	Uploader    int64 `gorm:"index"`
+	Ctime       int64
+	// Utime 最后一次上传的时间，找孤儿图片用
+	Utime int64 `gorm:"index"`
+}
+
+// ArticleAttachment 线上版本引用了哪些图片，每次发表的时候整体替换。
+// 以前草稿和线上版本的引用都记在这里，这些老数据下次发表的时候才会清理
+type ArticleAttachment struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId int64 `gorm:"uniqueIndex:uk_article_attachment"`
+	// 查图片有没有被引用
+	AttachmentId int64 `gorm:"uniqueIndex:uk_article_attachment;index"`
+	Ctime        int64
+}
+
+// DraftAttachment 草稿引用了哪些图片，每次保存的时候整体替换。
+// 和 ArticleAttachment 分开记，改草稿不会影响线上版本用到的图片
+type DraftAttachment struct {
+	Id           int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId    int64 `gorm:"uniqueIndex:uk_draft_attachment"`
+	AttachmentId int64 `gorm:"uniqueIndex:uk_draft_attachment;index"`
+	Ctime        int64
+}
+
+type AttachmentGORMDAO struct {
+	db *gorm.DB
+}
+
+func NewAttachmentGORMDAO(db *gorm.DB) AttachmentDAO {
+	return &AttachmentGORMDAO{
+		db: db,
+	}
+}
+
+func (a *AttachmentGORMDAO) Upsert(ctx context.Context, att Attachment) (Attachment, error) {
+	now := time.Now().UnixMilli()
+	att.Ctime = now
+	att.Utime = now
+	err := a.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"utime": now,
+		}),
+	}).Create(&att).Error
+	if err != nil {
+		return Attachment{}, err
+	}
+	// 冲突的时候拿不到 id，再查一次
+	return a.GetByHash(ctx, att.Hash)
+}
+
+func (a *AttachmentGORMDAO) GetByHash(ctx context.Context, hash string) (Attachment, error) {
+	var res Attachment
+	err := a.db.WithContext(ctx).Where("hash = ?", hash).First(&res).Error
+	return res, err
+}
+
+func (a *AttachmentGORMDAO) SetDraftReferences(ctx context.Context, aid int64, hashes []string) error {
+	return a.setReferences(ctx, &DraftAttachment{}, aid, hashes)
+}
+
+func (a *AttachmentGORMDAO) SetPubReferences(ctx context.Context, aid int64, hashes []string) error {
+	return a.setReferences(ctx, &ArticleAttachment{}, aid, hashes)
+}
+
+// setReferences 删掉不再引用的，补上新引用的，没变的保持不动
+func (a *AttachmentGORMDAO) setReferences(ctx context.Context, model any, aid int64, hashes []string) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if len(hashes) > 0 {
+			err := tx.Model(&Attachment{}).
+				Where("hash IN ?", hashes).
+				Pluck("id", &ids).Error
+			if err != nil {
+				return err
+			}
+		}
+		stale := tx.Where("article_id = ?", aid)
+		if len(ids) > 0 {
+			stale = stale.Where("attachment_id NOT IN ?", ids)
+		}
+		err := stale.Delete(model).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		now := time.Now().UnixMilli()
+		refs := make([]map[string]any, 0, len(ids))
+		for _, id := range ids {
+			refs = append(refs, map[string]any{
+				"article_id":    aid,
+				"attachment_id": id,
+				"ctime":         now,
+			})
+		}
+		return tx.Model(model).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(refs).Error
+	})
+}
+
+func (a *AttachmentGORMDAO) DeleteReferences(ctx context.Context, aid int64) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("article_id = ?", aid).Delete(&DraftAttachment{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("article_id = ?", aid).Delete(&ArticleAttachment{}).Error
+	})
+}
+
+func (a *AttachmentGORMDAO) FindOrphans(ctx context.Context, before time.Time, limit int) ([]Attachment, error) {
+	var res []Attachment
+	err := a.db.WithContext(ctx).
+		Where("utime < ?", before.UnixMilli()).
+		Where("NOT EXISTS (?)", a.referenced(&ArticleAttachment{}, "article_attachments")).
+		Where("NOT EXISTS (?)", a.referenced(&DraftAttachment{}, "draft_attachments")).
+		Order("id ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (a *AttachmentGORMDAO) DeleteOrphan(ctx context.Context, id int64, before time.Time) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Where("id = ? AND utime < ?", id, before.UnixMilli()).
+		Where("NOT EXISTS (?)", a.referenced(&ArticleAttachment{}, "article_attachments")).
+		Where("NOT EXISTS (?)", a.referenced(&DraftAttachment{}, "draft_attachments")).
+		Delete(&Attachment{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// referenced 相关子查询，attachments 表里面的这张图片有没有被 table 引用
+func (a *AttachmentGORMDAO) referenced(model any, table string) *gorm.DB {
+	return a.db.Model(model).
+		Select("1").
+		Where(table + ".attachment_id = attachments.id")
+}
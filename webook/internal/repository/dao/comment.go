@@ -0,0 +1,140 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type CommentDAO interface {
+	Insert(ctx context.Context, c Comment) (int64, error)
+	GetById(ctx context.Context, id int64) (Comment, error)
+	// FindRoots 某个业务对象下的顶层评论，按照 id 倒序，只返回 id 小于 maxId 的，maxId 为 0 表示从头开始
+	FindRoots(ctx context.Context, biz string, bizId int64, status uint8, maxId int64, limit int) ([]Comment, error)
+	// FindReplies 某条顶层评论下的回复，按照 id 正序，只返回 id 大于 minId 的
+	FindReplies(ctx context.Context, rootId int64, status uint8, minId int64, limit int) ([]Comment, error)
+	// Delete 删除评论，以及直接或者间接回复它的评论，返回被删除的评论
+	Delete(ctx context.Context, id int64) ([]Comment, error)
+	// UpdateStatus 修改状态，返回修改之前的评论
+	UpdateStatus(ctx context.Context, id int64, status uint8) (Comment, error)
+}
+
+type Comment struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 按照业务对象查询顶层评论
+	Biz      string `gorm:"type:varchar(128);index:idx_biz_root"`
+	BizId    int64  `gorm:"index:idx_biz_root"`
+	RootId   int64  `gorm:"index:idx_biz_root;index"`
+	ParentId int64  `gorm:"index"`
+	// Uid 评论的人
+	Uid        int64 `gorm:"index"`
+	ReplyToUid int64
+	Content    string `gorm:"type:text"`
+	Status     uint8
+	Ctime      int64
+	Utime      int64
+}
+
+type CommentGORMDAO struct {
+	db *gorm.DB
+}
+
+func NewCommentGORMDAO(db *gorm.DB) CommentDAO {
+	return &CommentGORMDAO{
+		db: db,
+	}
+}
+
+func (d *CommentGORMDAO) Insert(ctx context.Context, c Comment) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := d.db.WithContext(ctx).Create(&c).Error
+	return c.Id, err
+}
+
+func (d *CommentGORMDAO) GetById(ctx context.Context, id int64) (Comment, error) {
+	var c Comment
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&c).Error
+	return c, err
+}
+
+func (d *CommentGORMDAO) FindRoots(ctx context.Context, biz string, bizId int64, status uint8, maxId int64, limit int) ([]Comment, error) {
+	db := d.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ? AND root_id = 0 AND status = ?", biz, bizId, status)
+	if maxId > 0 {
+		db = db.Where("id < ?", maxId)
+	}
+	var res []Comment
+	err := db.Order("id DESC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (d *CommentGORMDAO) FindReplies(ctx context.Context, rootId int64, status uint8, minId int64, limit int) ([]Comment, error) {
+	var res []Comment
+	err := d.db.WithContext(ctx).
+		Where("root_id = ? AND status = ? AND id > ?", rootId, status, minId).
+		Order("id ASC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (d *CommentGORMDAO) Delete(ctx context.Context, id int64) ([]Comment, error) {
+	var res []Comment
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var c Comment
+		err := tx.Where("id = ?", id).First(&c).Error
+		if err != nil {
+			return err
+		}
+		res = append(res, c)
+		if c.RootId == 0 {
+			// 顶层评论，整个楼都删掉
+			var replies []Comment
+			err = tx.Where("root_id = ?", c.Id).Find(&replies).Error
+			if err != nil {
+				return err
+			}
+			res = append(res, replies...)
+		} else {
+			// 回复，一层层找出回复它的评论
+			parents := []int64{c.Id}
+			for len(parents) > 0 {
+				var children []Comment
+				err = tx.Where("root_id = ? AND parent_id IN ?", c.RootId, parents).
+					Find(&children).Error
+				if err != nil {
+					return err
+				}
+				res = append(res, children...)
+				parents = parents[:0]
+				for _, child := range children {
+					parents = append(parents, child.Id)
+				}
+			}
+		}
+		ids := make([]int64, 0, len(res))
+		for _, r := range res {
+			ids = append(ids, r.Id)
+		}
+		return tx.Where("id IN ?", ids).Delete(&Comment{}).Error
+	})
+	return res, err
+}
+
+func (d *CommentGORMDAO) UpdateStatus(ctx context.Context, id int64, status uint8) (Comment, error) {
+	var old Comment
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).First(&old).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&Comment{}).Where("id = ?", id).
+			Updates(map[string]any{
+				"status": status,
+				"utime":  time.Now().UnixMilli(),
+			}).Error
+	})
+	return old, err
+}
@@ -0,0 +1,203 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	FollowRelationStatusUnknown uint8 = iota
+	FollowRelationStatusActive
+	// FollowRelationStatusInactive 取消关注。记录留着，再次关注的时候直接改状态
+	FollowRelationStatusInactive
+)
+
+type FollowDAO interface {
+	// Follow 关注，已经关注了的时候什么也不做，返回 false
+	Follow(ctx context.Context, follower, followee int64) (bool, error)
+	// Unfollow 取消关注，没有关注的时候什么也不做，返回 false
+	Unfollow(ctx context.Context, follower, followee int64) (bool, error)
+	GetRelation(ctx context.Context, follower, followee int64) (FollowRelation, error)
+	// ListFollowees 关注的人，最近关注的在前
+	ListFollowees(ctx context.Context, follower int64, offset, limit int) ([]FollowRelation, error)
+	// ListFollowers 粉丝，最近关注的在前
+	ListFollowers(ctx context.Context, followee int64, offset, limit int) ([]FollowRelation, error)
+	// FindFollowers 按照 id 遍历粉丝，minId 是上一批最后一条的 id
+	FindFollowers(ctx context.Context, followee int64, minId int64, limit int) ([]FollowRelation, error)
+	// GetStatics 没有记录的时候返回零值
+	GetStatics(ctx context.Context, uid int64) (FollowStatics, error)
+}
+
+type FollowRelation struct {
+	Id       int64 `gorm:"primaryKey,autoIncrement"`
+	Follower int64 `gorm:"uniqueIndex:uk_follower_followee;index:idx_follower_status,priority:1"`
+	// 查粉丝
+	Followee int64 `gorm:"uniqueIndex:uk_follower_followee;index:idx_followee_status,priority:1"`
+	Status   uint8 `gorm:"index:idx_follower_status,priority:2;index:idx_followee_status,priority:2"`
+	Ctime    int64
+	Utime    int64
+}
+
+// FollowStatics 关注数和粉丝数，跟着关注关系在同一个事务里面更新
+type FollowStatics struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	Uid       int64 `gorm:"uniqueIndex"`
+	Followers int64
+	Followees int64
+	Ctime     int64
+	Utime     int64
+}
+
+type FollowGORMDAO struct {
+	db *gorm.DB
+}
+
+func NewFollowGORMDAO(db *gorm.DB) FollowDAO {
+	return &FollowGORMDAO{
+		db: db,
+	}
+}
+
+func (f *FollowGORMDAO) Follow(ctx context.Context, follower, followee int64) (bool, error) {
+	changed := false
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		var rel FollowRelation
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("follower = ? AND followee = ?", follower, followee).
+			First(&rel).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			err = tx.Create(&FollowRelation{
+				Follower: follower,
+				Followee: followee,
+				Status:   FollowRelationStatusActive,
+				Ctime:    now,
+				Utime:    now,
+			}).Error
+		case err != nil:
+			return err
+		case rel.Status == FollowRelationStatusActive:
+			return nil
+		default:
+			// 重新关注，Ctime 也更新，这样在列表里面排在前面
+			err = tx.Model(&FollowRelation{}).
+				Where("id = ?", rel.Id).
+				Updates(map[string]any{
+					"status": FollowRelationStatusActive,
+					"ctime":  now,
+					"utime":  now,
+				}).Error
+		}
+		if err != nil {
+			return err
+		}
+		changed = true
+		return f.incrStatics(tx, follower, followee, 1, now)
+	})
+	return changed, err
+}
+
+func (f *FollowGORMDAO) Unfollow(ctx context.Context, follower, followee int64) (bool, error) {
+	changed := false
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		res := tx.Model(&FollowRelation{}).
+			Where("follower = ? AND followee = ? AND status = ?",
+				follower, followee, FollowRelationStatusActive).
+			Updates(map[string]any{
+				"status": FollowRelationStatusInactive,
+				"utime":  now,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		changed = true
+		return f.incrStatics(tx, follower, followee, -1, now)
+	})
+	return changed, err
+}
+
+// incrStatics follower 的关注数和 followee 的粉丝数一起加上 delta
+func (f *FollowGORMDAO) incrStatics(tx *gorm.DB, follower, followee int64, delta int64, now int64) error {
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "uid"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"followees": gorm.Expr("followees + ?", delta),
+			"utime":     now,
+		}),
+	}).Create(&FollowStatics{
+		Uid:       follower,
+		Followees: delta,
+		Ctime:     now,
+		Utime:     now,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "uid"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"followers": gorm.Expr("followers + ?", delta),
+			"utime":     now,
+		}),
+	}).Create(&FollowStatics{
+		Uid:       followee,
+		Followers: delta,
+		Ctime:     now,
+		Utime:     now,
+	}).Error
+}
+
+func (f *FollowGORMDAO) GetRelation(ctx context.Context, follower, followee int64) (FollowRelation, error) {
+	var rel FollowRelation
+	err := f.db.WithContext(ctx).
+		Where("follower = ? AND followee = ? AND status = ?",
+			follower, followee, FollowRelationStatusActive).
+		First(&rel).Error
+	return rel, err
+}
+
+func (f *FollowGORMDAO) ListFollowees(ctx context.Context, follower int64, offset, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := f.db.WithContext(ctx).
+		Where("follower = ? AND status = ?", follower, FollowRelationStatusActive).
+		Order("ctime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (f *FollowGORMDAO) ListFollowers(ctx context.Context, followee int64, offset, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := f.db.WithContext(ctx).
+		Where("followee = ? AND status = ?", followee, FollowRelationStatusActive).
+		Order("ctime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (f *FollowGORMDAO) FindFollowers(ctx context.Context, followee int64, minId int64, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := f.db.WithContext(ctx).
+		Where("followee = ? AND status = ? AND id > ?", followee, FollowRelationStatusActive, minId).
+		Order("id ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (f *FollowGORMDAO) GetStatics(ctx context.Context, uid int64) (FollowStatics, error) {
+	var res FollowStatics
+	err := f.db.WithContext(ctx).
+		Where("uid = ?", uid).
+		First(&res).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return FollowStatics{Uid: uid}, nil
+	}
+	return res, err
+}
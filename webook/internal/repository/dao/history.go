@@ -0,0 +1,111 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type ReadHistoryDAO interface {
+	// Upsert 看了一篇文章，已经看过的只更新时间，进度不变
+	Upsert(ctx context.Context, uid, aid int64, rtime int64) error
+	// UpsertProgress 进度上报可能比阅读事件先到，所以也要能插入
+	UpsertProgress(ctx context.Context, uid, aid int64, progress float64) error
+	// Get 没有看过的时候返回 ErrRecordNotFound
+	Get(ctx context.Context, uid, aid int64) (ReadHistory, error)
+	// List 最近看过的在前
+	List(ctx context.Context, uid int64, offset, limit int) ([]ReadHistory, int64, error)
+	DeleteAll(ctx context.Context, uid int64) error
+	// FindSince 按照 id 遍历 since 之后看过的记录，minId 是上一批最后一条的 id
+	FindSince(ctx context.Context, since int64, minId int64, limit int) ([]ReadHistory, error)
+}
+
+// ReadHistory 一个读者一篇文章只有一条，Utime 就是最近一次看的时间
+type ReadHistory struct {
+	Id       int64 `gorm:"primaryKey,autoIncrement"`
+	Uid      int64 `gorm:"uniqueIndex:uk_uid_aid;index:idx_uid_utime,priority:1"`
+	Aid      int64 `gorm:"uniqueIndex:uk_uid_aid"`
+	Progress float64
+	Ctime    int64
+	Utime    int64 `gorm:"index:idx_uid_utime,priority:2;index"`
+}
+
+type ReadHistoryGORMDAO struct {
+	db *gorm.DB
+}
+
+func NewReadHistoryGORMDAO(db *gorm.DB) ReadHistoryDAO {
+	return &ReadHistoryGORMDAO{db: db}
+}
+
+func (r *ReadHistoryGORMDAO) Upsert(ctx context.Context, uid, aid int64, rtime int64) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "uid"}, {Name: "aid"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"utime": rtime,
+		}),
+	}).Create(&ReadHistory{
+		Uid:   uid,
+		Aid:   aid,
+		Ctime: rtime,
+		Utime: rtime,
+	}).Error
+}
+
+func (r *ReadHistoryGORMDAO) UpsertProgress(ctx context.Context, uid, aid int64, progress float64) error {
+	now := time.Now().UnixMilli()
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "uid"}, {Name: "aid"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"progress": progress,
+			"utime":    now,
+		}),
+	}).Create(&ReadHistory{
+		Uid:      uid,
+		Aid:      aid,
+		Progress: progress,
+		Ctime:    now,
+		Utime:    now,
+	}).Error
+}
+
+func (r *ReadHistoryGORMDAO) Get(ctx context.Context, uid, aid int64) (ReadHistory, error) {
+	var res ReadHistory
+	err := r.db.WithContext(ctx).
+		Where("uid = ? AND aid = ?", uid, aid).
+		First(&res).Error
+	return res, err
+}
+
+func (r *ReadHistoryGORMDAO) List(ctx context.Context, uid int64, offset, limit int) ([]ReadHistory, int64, error) {
+	var (
+		res   []ReadHistory
+		count int64
+	)
+	db := r.db.WithContext(ctx).Model(&ReadHistory{}).
+		Where("uid = ?", uid)
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, count, err
+}
+
+func (r *ReadHistoryGORMDAO) DeleteAll(ctx context.Context, uid int64) error {
+	return r.db.WithContext(ctx).
+		Where("uid = ?", uid).
+		Delete(&ReadHistory{}).Error
+}
+
+func (r *ReadHistoryGORMDAO) FindSince(ctx context.Context, since int64, minId int64, limit int) ([]ReadHistory, error) {
+	var res []ReadHistory
+	err := r.db.WithContext(ctx).
+		Where("utime >= ? AND id > ?", since, minId).
+		Order("id ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
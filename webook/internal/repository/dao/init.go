@@ -15,11 +15,31 @@ func InitTables(db *gorm.DB) error {
 		&User{},
 		&Article{},
 		&PublishedArticle{},
+		&PublishedArticleV2{},
+		&DeletedArticle{},
+		&ArticleRevision{},
+		&ArticleReview{},
+		&Tag{},
+		&ArticleTag{},
+		&Attachment{},
+		&ArticleAttachment{},
+		&DraftAttachment{},
+		&Comment{},
+		&FollowRelation{},
+		&FollowStatics{},
+		&ReadHistory{},
+		&RelatedArticle{},
+		&ArticleShare{},
+		&ArticleShareAudit{},
+		&Series{},
+		&SeriesArticle{},
+		&Order{},
 		&Job{},
 		&Task{},
 	)
 }
 
+// InitCollection 创建文章用到的索引，索引已经存在的时候什么也不做
 func InitCollection(mdb *mongo.Database) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -30,7 +50,12 @@ func InitCollection(mdb *mongo.Database) error {
 			Options: options.Index().SetUnique(true),
 		},
 		{
-			Keys: bson.D{bson.E{"author_id", 1}},
+			// 作者的文章列表
+			Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "utime", Value: -1}},
+		},
+		{
+			// 定时发表
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "publish_at", Value: 1}},
 		},
 	})
 	if err != nil {
@@ -45,6 +70,28 @@ func InitCollection(mdb *mongo.Database) error {
 		{
 			Keys: bson.D{bson.E{"author_id", 1}},
 		},
+		{
+			// ListPub 和游标翻页
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "utime", Value: -1}, {Key: "id", Value: -1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	trashCol := mdb.Collection("deleted_articles")
+	_, err = trashCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// 作者的回收站
+			Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "dtime", Value: -1}},
+		},
+		{
+			// 找出过期的
+			Keys: bson.D{{Key: "dtime", Value: 1}},
+		},
 	})
 	return err
 }
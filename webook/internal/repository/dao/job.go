@@ -3,6 +3,7 @@ package dao
 import (
 	"context"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
@@ -27,6 +28,9 @@ type JobDAO interface {
 	Preempt(ctx context.Context) (Job, error)
 	Release(ctx context.Context, jid int64) error
 	UpdateUtime(ctx context.Context, jid int64) error
+	UpdateNextTime(ctx context.Context, jid int64, next time.Time) error
+	// Insert 按照 name 去重，已经存在的任务不会被覆盖
+	Insert(ctx context.Context, j Job) error
 }
 
 type GORMJobDAO struct {
@@ -50,6 +54,25 @@ func (dao *GORMJobDAO) UpdateUtime(ctx context.Context, jid int64) error {
 	}).Error
 }
 
+func (dao *GORMJobDAO) UpdateNextTime(ctx context.Context, jid int64, next time.Time) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ?", jid).Updates(map[string]any{
+		"next_time": next.UnixMilli(),
+		"utime":     now,
+	}).Error
+}
+
+func (dao *GORMJobDAO) Insert(ctx context.Context, j Job) error {
+	now := time.Now().UnixMilli()
+	j.Ctime = now
+	j.Utime = now
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoNothing: true,
+	}).Create(&j).Error
+}
+
 func NewGORMJobDAO(db *gorm.DB) JobDAO {
 	return &GORMJobDAO{db: db}
 }
@@ -2,55 +2,355 @@ package dao
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/bwmarrin/snowflake"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
 
+// MongoDBArticleDAO 制作库是 articles，线上库是 published_articles。
+// ID 用雪花算法生成，两个集合里面同一篇文章的 ID 是一样的
 type MongoDBArticleDAO struct {
 	node    *snowflake.Node
 	col     *mongo.Collection
 	liveCol *mongo.Collection
+	// trashCol 回收站
+	trashCol *mongo.Collection
 }
 
 func (m MongoDBArticleDAO) GetByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
-	//TODO implement me
-	panic("implement me")
+	var res []PublishedArticle
+	if len(ids) == 0 {
+		return res, nil
+	}
+	filter := bson.M{
+		"id":     bson.M{"$in": ids},
+		"status": domain.ArticleStatusPublished,
+	}
+	err := m.findAll(ctx, m.liveCol, filter, nil, &res)
+	return res, err
 }
 
 func (m MongoDBArticleDAO) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
-	//TODO implement me
-	panic("implement me")
+	filter := bson.M{
+		"utime":  bson.M{"$lt": start.UnixMilli()},
+		"status": domain.ArticleStatusPublished,
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "utime", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	var res []PublishedArticle
+	err := m.findAll(ctx, m.liveCol, filter, opts, &res)
+	return res, err
+}
+
+func (m MongoDBArticleDAO) ListPubByCursor(ctx context.Context, utime int64, id int64, limit int) ([]PublishedArticle, error) {
+	filter := bson.M{"status": domain.ArticleStatusPublished}
+	if utime > 0 {
+		filter["$or"] = bson.A{
+			bson.M{"utime": bson.M{"$lt": utime}},
+			bson.M{"utime": utime, "id": bson.M{"$lt": id}},
+		}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "utime", Value: -1}, {Key: "id", Value: -1}}).
+		SetLimit(int64(limit))
+	var res []PublishedArticle
+	err := m.findAll(ctx, m.liveCol, filter, opts, &res)
+	return res, err
 }
 
-func (m MongoDBArticleDAO) GetByAuthor(ctx context.Context, uid int64, limit int, offset int) ([]Article, int64, error) {
-	//TODO implement me
-	panic("implement me")
+func (m MongoDBArticleDAO) ListPubByCursorWithContent(ctx context.Context, utime int64, id int64, limit int) ([]PublishedArticle, error) {
+	return m.ListPubByCursor(ctx, utime, id, limit)
+}
+
+func (m MongoDBArticleDAO) ListPubByAuthor(ctx context.Context, uid int64, limit int) ([]PublishedArticle, error) {
+	filter := bson.M{
+		"author_id": uid,
+		"status":    domain.ArticleStatusPublished,
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "utime", Value: -1}}).
+		SetLimit(int64(limit))
+	var res []PublishedArticle
+	err := m.findAll(ctx, m.liveCol, filter, opts, &res)
+	return res, err
+}
+
+// GetByAuthor 和 GORM 的实现保持一致，传进来的其实是页码和每页的数量
+func (m MongoDBArticleDAO) GetByAuthor(ctx context.Context, uid int64, pageIndex int, pageSize int) ([]Article, int64, error) {
+	filter := bson.M{"author_id": uid}
+	count, err := m.col.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "utime", Value: -1}}).
+		SetSkip(int64((pageIndex - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+	var arts []Article
+	err = m.findAll(ctx, m.col, filter, opts, &arts)
+	return arts, count, err
 }
 
 func (m MongoDBArticleDAO) SyncStatus(ctx context.Context, uid int64, id int64, status uint8) error {
-	//TODO implement me
-	panic("implement me")
+	now := time.Now().UnixMilli()
+	set := bson.M{"$set": bson.M{
+		"status": status,
+		"utime":  now,
+	}}
+	res, err := m.col.UpdateOne(ctx, bson.M{"id": id, "author_id": uid}, set)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("ID 不对或者创作者不对")
+	}
+	// 还没有发表过的文章，线上库里面没有
+	_, err = m.liveCol.UpdateOne(ctx, bson.M{"id": id, "author_id": uid}, set)
+	return err
 }
 
 func (m MongoDBArticleDAO) GetById(ctx context.Context, id int64) (Article, error) {
-	//TODO implement me
-	panic("implement me")
+	var art Article
+	err := m.findOne(ctx, m.col, bson.M{"id": id}, &art)
+	return art, err
 }
 
 func (m MongoDBArticleDAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
-	//TODO implement me
-	panic("implement me")
+	var art PublishedArticle
+	err := m.findOne(ctx, m.liveCol, bson.M{"id": id}, &art)
+	return art, err
+}
+
+func (m MongoDBArticleDAO) ListScheduled(ctx context.Context, uid int64, offset int, limit int) ([]Article, int64, error) {
+	filter := bson.M{
+		"author_id": uid,
+		"status":    domain.ArticleStatusScheduled,
+	}
+	count, err := m.col.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "publish_at", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	var arts []Article
+	err = m.findAll(ctx, m.col, filter, opts, &arts)
+	return arts, count, err
+}
+
+func (m MongoDBArticleDAO) FindByAuthor(ctx context.Context, uid int64, minId int64, limit int) ([]Article, error) {
+	filter := bson.M{
+		"author_id": uid,
+		"id":        bson.M{"$gt": minId},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "id", Value: 1}}).
+		SetLimit(int64(limit))
+	var arts []Article
+	err := m.findAll(ctx, m.col, filter, opts, &arts)
+	return arts, err
+}
+
+func (m MongoDBArticleDAO) FindDueScheduled(ctx context.Context, now time.Time, limit int) ([]Article, error) {
+	filter := bson.M{
+		"status":     domain.ArticleStatusScheduled,
+		"publish_at": bson.M{"$lte": now.UnixMilli()},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "publish_at", Value: 1}}).
+		SetLimit(int64(limit))
+	var arts []Article
+	err := m.findAll(ctx, m.col, filter, opts, &arts)
+	return arts, err
+}
+
+func (m MongoDBArticleDAO) Reschedule(ctx context.Context, uid int64, id int64, publishAt int64) error {
+	return m.updateScheduled(ctx, uid, id, bson.M{
+		"publish_at": publishAt,
+		"utime":      time.Now().UnixMilli(),
+	})
+}
+
+func (m MongoDBArticleDAO) CancelSchedule(ctx context.Context, uid int64, id int64) error {
+	// 取消之后退回到未发表的草稿
+	return m.updateScheduled(ctx, uid, id, bson.M{
+		"status":     domain.ArticleStatusUnpublished,
+		"publish_at": int64(0),
+		"utime":      time.Now().UnixMilli(),
+	})
+}
+
+func (m MongoDBArticleDAO) TransitStatus(ctx context.Context, uid int64, id int64, from uint8, to uint8) error {
+	filter := bson.M{
+		"id":        id,
+		"author_id": uid,
+		"status":    from,
+	}
+	res, err := m.col.UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+		"status": to,
+		"utime":  time.Now().UnixMilli(),
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrArticleStatusMismatch
+	}
+	return nil
+}
+
+// Delete MongoDB 没有用事务，先写回收站再删除，中途失败了重新删除一次就可以
+func (m MongoDBArticleDAO) Delete(ctx context.Context, uid int64, id int64) error {
+	var art Article
+	err := m.findOne(ctx, m.col, bson.M{"id": id, "author_id": uid}, &art)
+	if errors.Is(err, ErrRecordNotFound) {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	var (
+		pub    PublishedArticle
+		pubVal any
+	)
+	err = m.findOne(ctx, m.liveCol, bson.M{"id": id}, &pub)
+	switch {
+	case err == nil:
+		pubVal = pub
+	case errors.Is(err, ErrRecordNotFound):
+		// 没有发表过
+	default:
+		return err
+	}
+	deleted, err := newDeletedArticle(art, pubVal, "")
+	if err != nil {
+		return err
+	}
+	_, err = m.trashCol.ReplaceOne(ctx, bson.M{"id": id}, deleted,
+		options.Replace().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	if _, err = m.liveCol.DeleteOne(ctx, bson.M{"id": id}); err != nil {
+		return err
+	}
+	_, err = m.col.DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
+
+// Restore 和 Delete 反过来，先写回去再删除回收站里面的
+func (m MongoDBArticleDAO) Restore(ctx context.Context, uid int64, id int64) (DeletedArticle, error) {
+	var deleted DeletedArticle
+	err := m.findOne(ctx, m.trashCol, bson.M{"id": id, "author_id": uid}, &deleted)
+	if errors.Is(err, ErrRecordNotFound) {
+		return DeletedArticle{}, ErrDeletedArticleNotFound
+	}
+	if err != nil {
+		return DeletedArticle{}, err
+	}
+	art, err := deleted.Article()
+	if err != nil {
+		return DeletedArticle{}, err
+	}
+	_, err = m.col.ReplaceOne(ctx, bson.M{"id": id}, art,
+		options.Replace().SetUpsert(true))
+	if err != nil {
+		return DeletedArticle{}, err
+	}
+	if deleted.Published != "" {
+		var pub PublishedArticle
+		if err = json.Unmarshal([]byte(deleted.Published), &pub); err != nil {
+			return DeletedArticle{}, err
+		}
+		_, err = m.liveCol.ReplaceOne(ctx, bson.M{"id": id}, pub,
+			options.Replace().SetUpsert(true))
+		if err != nil {
+			return DeletedArticle{}, err
+		}
+	}
+	_, err = m.trashCol.DeleteOne(ctx, bson.M{"id": id})
+	return deleted, err
+}
+
+func (m MongoDBArticleDAO) ListDeleted(ctx context.Context, uid int64, offset int, limit int) ([]DeletedArticle, int64, error) {
+	filter := bson.M{"author_id": uid}
+	count, err := m.trashCol.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "dtime", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	var res []DeletedArticle
+	err = m.findAll(ctx, m.trashCol, filter, opts, &res)
+	return res, count, err
+}
+
+func (m MongoDBArticleDAO) FindExpiredDeleted(ctx context.Context, before int64, limit int) ([]DeletedArticle, error) {
+	filter := bson.M{"dtime": bson.M{"$lt": before}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "dtime", Value: 1}}).
+		SetLimit(int64(limit))
+	var res []DeletedArticle
+	err := m.findAll(ctx, m.trashCol, filter, opts, &res)
+	return res, err
+}
+
+func (m MongoDBArticleDAO) Purge(ctx context.Context, id int64) error {
+	_, err := m.trashCol.DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
+
+// updateScheduled 只更新处于定时发表状态的文章
+func (m MongoDBArticleDAO) updateScheduled(ctx context.Context, uid int64, id int64, updates bson.M) error {
+	filter := bson.M{
+		"id":        id,
+		"author_id": uid,
+		"status":    domain.ArticleStatusScheduled,
+	}
+	res, err := m.col.UpdateOne(ctx, filter, bson.M{"$set": updates})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrArticleNotScheduled
+	}
+	return nil
+}
+
+// findOne 找不到的时候返回 ErrRecordNotFound，和 GORM 的实现保持一致
+func (m MongoDBArticleDAO) findOne(ctx context.Context, col *mongo.Collection, filter any, val any) error {
+	err := col.FindOne(ctx, filter).Decode(val)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrRecordNotFound
+	}
+	return err
+}
+
+func (m MongoDBArticleDAO) findAll(ctx context.Context, col *mongo.Collection, filter any,
+	opts *options.FindOptions, val any) error {
+	cursor, err := col.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, val)
 }
 
 func NewMongoDBArticleDAO(mdb *mongo.Database, node *snowflake.Node) *MongoDBArticleDAO {
 	return &MongoDBArticleDAO{
-		col:     mdb.Collection("articles"),
-		liveCol: mdb.Collection("published_articles"),
-		node:    node,
+		col:      mdb.Collection("articles"),
+		liveCol:  mdb.Collection("published_articles"),
+		trashCol: mdb.Collection("deleted_articles"),
+		node:     node,
 	}
 }
 
@@ -59,6 +359,7 @@ func (m MongoDBArticleDAO) Insert(ctx context.Context, article Article) (int64,
 	article.Id = m.node.Generate().Int64()
 	article.Ctime = now
 	article.Utime = now
+	article.Version = 1
 	_, err := m.col.InsertOne(ctx, article)
 	if err != nil {
 		return 0, err
@@ -70,17 +371,31 @@ func (m MongoDBArticleDAO) UpdateById(ctx context.Context, art Article) error {
 	now := time.Now().UnixMilli()
 	filter := bson.D{bson.E{"id", art.Id},
 		bson.E{"author_id", art.AuthorId}}
+	if art.Version > 0 {
+		filter = append(filter, bson.E{"version", art.Version})
+	}
 	set := bson.D{bson.E{"$set", bson.M{
-		"title":   art.Title,
-		"content": art.Content,
-		"status":  art.Status,
-		"utime":   now,
-	}}}
+		"title":      art.Title,
+		"content":    art.Content,
+		"status":     art.Status,
+		"publish_at": art.PublishAt,
+		"price":      art.Price,
+		"utime":      now,
+	}}, bson.E{"$inc", bson.M{"version": 1}}}
 	res, err := m.col.UpdateOne(ctx, filter, set)
 	if err != nil {
 		return err
 	}
-	if res.ModifiedCount == 0 {
+	if res.MatchedCount == 0 {
+		if art.Version > 0 {
+			cnt, er := m.col.CountDocuments(ctx, filter[:2])
+			if er != nil {
+				return er
+			}
+			if cnt > 0 {
+				return ErrArticleVersionConflict
+			}
+		}
 		// 创作者不对，说明有人在瞎搞
 		return errors.New("ID 不对或者创作者不对")
 	}
@@ -100,13 +415,17 @@ func (m MongoDBArticleDAO) Sync(ctx context.Context, art Article) (int64, error)
 	if err != nil {
 		return 0, err
 	}
-	art.Id = id
 	now := time.Now().UnixMilli()
-	art.Utime = now
-	// liveCol 是 INSERT or Update 语义
-	filter := bson.D{bson.E{"id", art.Id},
+	// liveCol 是 INSERT or Update 语义，只同步线上库的字段，版本号这些是草稿才有的
+	filter := bson.D{bson.E{"id", id},
 		bson.E{"author_id", art.AuthorId}}
-	set := bson.D{bson.E{"$set", art},
+	set := bson.D{bson.E{"$set", bson.D{
+		bson.E{"title", art.Title},
+		bson.E{"content", art.Content},
+		bson.E{"utime", now},
+		bson.E{"status", art.Status},
+		bson.E{"price", art.Price},
+	}},
 		bson.E{"$setOnInsert",
 			bson.D{bson.E{"ctime", now}}}}
 	_, err = m.liveCol.UpdateOne(ctx,
@@ -0,0 +1,138 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ErrOrderStatusMismatch 订单已经不是预期的状态了，比如回调和对账同时在改
+var ErrOrderStatusMismatch = errors.New("订单状态不对")
+
+type OrderDAO interface {
+	Insert(ctx context.Context, o Order) (int64, error)
+	SetPayURL(ctx context.Context, id int64, payURL string) error
+	// FindBySN 不存在的时候返回 ErrRecordNotFound
+	FindBySN(ctx context.Context, sn string) (Order, error)
+	// FindInit 用户这篇文章最近的一个还没有支付结果的订单，没有的时候返回 ErrRecordNotFound
+	FindInit(ctx context.Context, uid, aid int64) (Order, error)
+	HasPaid(ctx context.Context, uid, aid int64) (bool, error)
+	// FindByUid 用户的订单，新的在前
+	FindByUid(ctx context.Context, uid int64, offset, limit int) ([]Order, int64, error)
+	// FindInitBefore 在 utime 之前就已经下单，还没有支付结果的订单，按照 ID 从小到大，
+	// 只返回 ID 比 minId 大的
+	FindInitBefore(ctx context.Context, utime int64, minId int64, limit int) ([]Order, error)
+	// TransitStatus 只有订单还是 from 状态的时候才更新，不然返回 ErrOrderStatusMismatch
+	TransitStatus(ctx context.Context, sn string, from, to uint8, txnId string) error
+}
+
+type Order struct {
+	Id     int64  `gorm:"primaryKey,autoIncrement"`
+	SN     string `gorm:"type:varchar(64);uniqueIndex"`
+	Uid    int64  `gorm:"index:idx_uid_aid,priority:1"`
+	Aid    int64  `gorm:"index:idx_uid_aid,priority:2"`
+	Amount int64
+	// 对账按照 status, utime 找卡住的订单
+	Status uint8  `gorm:"index:idx_status_utime,priority:1"`
+	TxnId  string `gorm:"type:varchar(128)"`
+	PayURL string `gorm:"type:varchar(1024)"`
+	Ctime  int64
+	Utime  int64 `gorm:"index:idx_status_utime,priority:2"`
+}
+
+type OrderGORMDAO struct {
+	db *gorm.DB
+}
+
+func NewOrderGORMDAO(db *gorm.DB) OrderDAO {
+	return &OrderGORMDAO{db: db}
+}
+
+func (o *OrderGORMDAO) Insert(ctx context.Context, order Order) (int64, error) {
+	now := time.Now().UnixMilli()
+	order.Ctime = now
+	order.Utime = now
+	err := o.db.WithContext(ctx).Create(&order).Error
+	return order.Id, err
+}
+
+func (o *OrderGORMDAO) SetPayURL(ctx context.Context, id int64, payURL string) error {
+	return o.db.WithContext(ctx).Model(&Order{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"pay_url": payURL,
+			"utime":   time.Now().UnixMilli(),
+		}).Error
+}
+
+func (o *OrderGORMDAO) FindBySN(ctx context.Context, sn string) (Order, error) {
+	var res Order
+	err := o.db.WithContext(ctx).Where("sn = ?", sn).First(&res).Error
+	return res, err
+}
+
+func (o *OrderGORMDAO) FindInit(ctx context.Context, uid, aid int64) (Order, error) {
+	var res Order
+	err := o.db.WithContext(ctx).
+		Where("uid = ? AND aid = ? AND status = ?", uid, aid, domain.OrderStatusInit.ToUint8()).
+		Order("id DESC").
+		First(&res).Error
+	return res, err
+}
+
+func (o *OrderGORMDAO) HasPaid(ctx context.Context, uid, aid int64) (bool, error) {
+	var cnt int64
+	err := o.db.WithContext(ctx).Model(&Order{}).
+		Where("uid = ? AND aid = ? AND status = ?", uid, aid, domain.OrderStatusPaid.ToUint8()).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (o *OrderGORMDAO) FindByUid(ctx context.Context, uid int64, offset, limit int) ([]Order, int64, error) {
+	var (
+		res   []Order
+		count int64
+	)
+	db := o.db.WithContext(ctx)
+	err := db.Model(&Order{}).Where("uid = ?", uid).Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = db.Where("uid = ?", uid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, count, err
+}
+
+func (o *OrderGORMDAO) FindInitBefore(ctx context.Context, utime int64, minId int64, limit int) ([]Order, error) {
+	var res []Order
+	err := o.db.WithContext(ctx).
+		Where("status = ? AND utime < ? AND id > ?", domain.OrderStatusInit.ToUint8(), utime, minId).
+		Order("id ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (o *OrderGORMDAO) TransitStatus(ctx context.Context, sn string, from, to uint8, txnId string) error {
+	updates := map[string]any{
+		"status": to,
+		"utime":  time.Now().UnixMilli(),
+	}
+	if txnId != "" {
+		updates["txn_id"] = txnId
+	}
+	res := o.db.WithContext(ctx).Model(&Order{}).
+		Where("sn = ? AND status = ?", sn, from).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrOrderStatusMismatch
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+type RelatedArticleDAO interface {
+	// Replace 用新算出来的结果整个替换掉一篇文章原来的相关文章
+	Replace(ctx context.Context, aid int64, rels []RelatedArticle) error
+	// FindByAid 按照分数从高到低
+	FindByAid(ctx context.Context, aid int64) ([]RelatedArticle, error)
+}
+
+// RelatedArticle 离线任务算出来的一对相关文章
+type RelatedArticle struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	Aid       int64 `gorm:"index"`
+	RelatedId int64
+	Score     float64
+	Ctime     int64
+}
+
+type RelatedArticleGORMDAO struct {
+	db *gorm.DB
+}
+
+func NewRelatedArticleGORMDAO(db *gorm.DB) RelatedArticleDAO {
+	return &RelatedArticleGORMDAO{db: db}
+}
+
+func (r *RelatedArticleGORMDAO) Replace(ctx context.Context, aid int64, rels []RelatedArticle) error {
+	now := time.Now().UnixMilli()
+	for i := range rels {
+		rels[i].Aid = aid
+		rels[i].Ctime = now
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("aid = ?", aid).Delete(&RelatedArticle{}).Error
+		if err != nil || len(rels) == 0 {
+			return err
+		}
+		return tx.Create(&rels).Error
+	})
+}
+
+func (r *RelatedArticleGORMDAO) FindByAid(ctx context.Context, aid int64) ([]RelatedArticle, error) {
+	var res []RelatedArticle
+	err := r.db.WithContext(ctx).
+		Where("aid = ?", aid).
+		Order("score DESC").
+		Find(&res).Error
+	return res, err
+}
@@ -1,27 +1,43 @@
 package dao
 
 import (
-	"bytes"
 	"context"
 	"errors"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/ecodeclub/ekit"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/pkg/objstore"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
-	"strconv"
 	"time"
 )
 
+// ArticleContentSigner 线上库的内容放在对象存储里面的 DAO 会实现这个接口，
+// 私密文章的内容只能通过有时效的签名链接访问
+type ArticleContentSigner interface {
+	SignContentURL(ctx context.Context, id int64, expire time.Duration) (string, error)
+}
+
+// ArticleS3DAO 制作库还是放在数据库里面，线上库的内容放在对象存储里面，
+// 数据库里面只记录对象的 key。key 按照内容的哈希生成，内容一样的文章共用一个对象
 type ArticleS3DAO struct {
 	ArticleGORMDAO
-	oss *s3.S3
+	store  objstore.ObjectStore
+	layout objstore.Layout
+}
+
+func NewArticleS3DAO(db *gorm.DB, store objstore.ObjectStore, layout objstore.Layout) *ArticleS3DAO {
+	return &ArticleS3DAO{
+		ArticleGORMDAO: ArticleGORMDAO{db: db},
+		store:          store,
+		layout:         layout,
+	}
 }
 
 func (a *ArticleS3DAO) SyncStatus(ctx context.Context, uid int64, id int64, status uint8) error {
 	now := time.Now().UnixMilli()
-	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	// 对象可能被别的文章共用，所以变成私密的时候也不删除，私密文章只能通过签名链接访问
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		res := tx.Model(&Article{}).
-			Where("id = ? and author_id = ?", uid, id).
+			Where("id = ? and author_id = ?", id, uid).
 			Updates(map[string]any{
 				"utime":  now,
 				"status": status,
@@ -33,28 +49,23 @@ func (a *ArticleS3DAO) SyncStatus(ctx context.Context, uid int64, id int64, stat
 			return errors.New("ID 不对或者创作者不对")
 		}
 		return tx.Model(&PublishedArticleV2{}).
-			Where("id = ?", uid).
+			Where("id = ?", id).
 			Updates(map[string]any{
 				"utime":  now,
 				"status": status,
 			}).Error
 	})
-	if err != nil {
-		return err
-	}
-	const statusPrivate = 3
-	if status == statusPrivate {
-		_, err = a.oss.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
-			Bucket: ekit.ToPtr[string]("webook-1314583317"),
-			Key:    ekit.ToPtr[string](strconv.FormatInt(id, 10)),
-		})
-	}
-	return err
 }
 
 func (a *ArticleS3DAO) Sync(ctx context.Context, art Article) (int64, error) {
+	key := a.layout.Key(objstore.Hash([]byte(art.Content)))
+	// 先上传内容，这样线上库的记录不会指向一个不存在的对象
+	err := objstore.PutIfAbsent(ctx, a.store, key, []byte(art.Content), "text/plain;charset=utf-8")
+	if err != nil {
+		return 0, err
+	}
 	var id = art.Id
-	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err = a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var (
 			err error
 		)
@@ -70,48 +81,213 @@ func (a *ArticleS3DAO) Sync(ctx context.Context, art Article) (int64, error) {
 		art.Id = id
 		now := time.Now().UnixMilli()
 		pubArt := PublishedArticleV2{
-			Id:       art.Id,
-			Title:    art.Title,
-			AuthorId: art.AuthorId,
-			Ctime:    now,
-			Utime:    now,
-			Status:   art.Status,
+			Id:         art.Id,
+			Title:      art.Title,
+			ContentKey: key,
+			AuthorId:   art.AuthorId,
+			Ctime:      now,
+			Utime:      now,
+			Status:     art.Status,
+			Price:      art.Price,
 		}
-		pubArt.Ctime = now
-		pubArt.Utime = now
-		err = tx.Clauses(clause.OnConflict{
+		return tx.Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "id"}},
 			DoUpdates: clause.Assignments(map[string]interface{}{
-				"title":  pubArt.Title,
-				"utime":  now,
-				"status": pubArt.Status,
+				"title":       pubArt.Title,
+				"content_key": pubArt.ContentKey,
+				"utime":       now,
+				"status":      pubArt.Status,
+				"price":       pubArt.Price,
 			}),
 		}).Create(&pubArt).Error
-		return err
 	})
+	return id, err
+}
+
+// GetPubById 私密文章不加载内容，要用 SignContentURL 拿签名链接
+func (a *ArticleS3DAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
+	var pub PublishedArticleV2
+	err := a.db.WithContext(ctx).Where("id = ?", id).First(&pub).Error
 	if err != nil {
-		return 0, err
+		return PublishedArticle{}, err
 	}
-	_, err = a.oss.PutObjectWithContext(ctx, &s3.PutObjectInput{
-		Bucket:      ekit.ToPtr[string]("webook-1314583317"),
-		Key:         ekit.ToPtr[string](strconv.FormatInt(art.Id, 10)),
-		Body:        bytes.NewReader([]byte(art.Content)),
-		ContentType: ekit.ToPtr[string]("text/plain;charset=utf-8"),
-	})
-	return id, err
+	res := pub.toPublishedArticle()
+	if pub.Status == domain.ArticleStatusPrivate {
+		return res, nil
+	}
+	content, err := a.store.Get(ctx, pub.ContentKey)
+	if err != nil {
+		return PublishedArticle{}, err
+	}
+	res.Content = string(content)
+	return res, nil
+}
+
+func (a *ArticleS3DAO) SignContentURL(ctx context.Context, id int64, expire time.Duration) (string, error) {
+	var pub PublishedArticleV2
+	err := a.db.WithContext(ctx).Select("content_key").
+		Where("id = ?", id).First(&pub).Error
+	if err != nil {
+		return "", err
+	}
+	return a.store.SignURL(ctx, pub.ContentKey, expire)
+}
+
+func (a *ArticleS3DAO) Delete(ctx context.Context, uid int64, id int64) error {
+	// 对象先留着，彻底删除的时候再看要不要清理
+	return moveToTrash[PublishedArticleV2](ctx, a.db, uid, id,
+		func(pub PublishedArticleV2) string { return pub.ContentKey })
 }
 
-func NewArticleS3DAO(db *gorm.DB, oss *s3.S3) *ArticleS3DAO {
-	return &ArticleS3DAO{ArticleGORMDAO: ArticleGORMDAO{db: db}, oss: oss}
+func (a *ArticleS3DAO) Restore(ctx context.Context, uid int64, id int64) (DeletedArticle, error) {
+	return restoreFromTrash[PublishedArticleV2](ctx, a.db, uid, id)
+}
+
+// Purge 对象是按照内容去重的，只有线上库和回收站里面都没有别的文章用这个对象的时候才删除。
+// 先删对象再删记录，删记录失败了下一次还会再来一遍
+func (a *ArticleS3DAO) Purge(ctx context.Context, id int64) error {
+	var deleted DeletedArticle
+	err := a.db.WithContext(ctx).Where("id = ?", id).First(&deleted).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if deleted.ContentKey != "" {
+		inUse, err := a.contentKeyInUse(ctx, deleted.ContentKey, id)
+		if err != nil {
+			return err
+		}
+		if !inUse {
+			// 这里和 Sync 之间还是有并发问题：Sync 看到对象已经存在，跳过了上传，
+			// 然后这里删掉了对象。概率很低，出现了重新发表一次就可以
+			if err = a.store.Delete(ctx, deleted.ContentKey); err != nil {
+				return err
+			}
+		}
+	}
+	return a.ArticleGORMDAO.Purge(ctx, id)
+}
+
+// contentKeyInUse 除了 id 之外，还有没有文章引用这个对象
+func (a *ArticleS3DAO) contentKeyInUse(ctx context.Context, key string, id int64) (bool, error) {
+	var cnt int64
+	err := a.db.WithContext(ctx).Model(&PublishedArticleV2{}).
+		Where("content_key = ?", key).Count(&cnt).Error
+	if err != nil || cnt > 0 {
+		return cnt > 0, err
+	}
+	err = a.db.WithContext(ctx).Model(&DeletedArticle{}).
+		Where("content_key = ? AND id <> ?", key, id).Count(&cnt).Error
+	return cnt > 0, err
+}
+
+// 列表都不加载内容
+
+func (a *ArticleS3DAO) GetByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	var pubs []PublishedArticleV2
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	err := a.db.WithContext(ctx).
+		Where("id IN ? AND status = ?", ids, domain.ArticleStatusPublished).
+		Find(&pubs).Error
+	return toPublishedArticles(pubs), err
+}
+
+func (a *ArticleS3DAO) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
+	var pubs []PublishedArticleV2
+	err := a.db.WithContext(ctx).
+		Where("utime < ? AND status = ?", start.UnixMilli(), domain.ArticleStatusPublished).
+		Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&pubs).Error
+	return toPublishedArticles(pubs), err
+}
+
+func (a *ArticleS3DAO) ListPubByCursor(ctx context.Context, utime int64, id int64, limit int) ([]PublishedArticle, error) {
+	pubs, err := a.listPubByCursor(ctx, utime, id, limit)
+	return toPublishedArticles(pubs), err
+}
+
+func (a *ArticleS3DAO) listPubByCursor(ctx context.Context, utime int64, id int64, limit int) ([]PublishedArticleV2, error) {
+	db := a.db.WithContext(ctx).Where("status = ?", domain.ArticleStatusPublished)
+	if utime > 0 {
+		db = db.Where("utime < ? OR (utime = ? AND id < ?)", utime, utime, id)
+	}
+	var pubs []PublishedArticleV2
+	err := db.Order("utime DESC").Order("id DESC").
+		Limit(limit).
+		Find(&pubs).Error
+	return pubs, err
+}
+
+// ListPubByAuthor 只有已发表的文章，所以内容也一起加载
+func (a *ArticleS3DAO) ListPubByAuthor(ctx context.Context, uid int64, limit int) ([]PublishedArticle, error) {
+	var pubs []PublishedArticleV2
+	err := a.db.WithContext(ctx).
+		Where("author_id = ? AND status = ?", uid, domain.ArticleStatusPublished).
+		Order("utime DESC").
+		Limit(limit).
+		Find(&pubs).Error
+	if err != nil {
+		return nil, err
+	}
+	return a.withContent(ctx, pubs)
+}
+
+func (a *ArticleS3DAO) ListPubByCursorWithContent(ctx context.Context, utime int64, id int64, limit int) ([]PublishedArticle, error) {
+	pubs, err := a.listPubByCursor(ctx, utime, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return a.withContent(ctx, pubs)
+}
+
+// withContent 从对象存储里面加载内容，一篇一篇地取
+func (a *ArticleS3DAO) withContent(ctx context.Context, pubs []PublishedArticleV2) ([]PublishedArticle, error) {
+	res := toPublishedArticles(pubs)
+	for i, pub := range pubs {
+		content, err := a.store.Get(ctx, pub.ContentKey)
+		if err != nil {
+			return nil, err
+		}
+		res[i].Content = string(content)
+	}
+	return res, nil
 }
 
 type PublishedArticleV2 struct {
 	Id    int64  `gorm:"primaryKey,autoIncrement" bson:"id,omitempty"`
 	Title string `gorm:"type=varchar(4096)" bson:"title,omitempty"`
+	// ContentKey 内容在对象存储里面的 key
+	ContentKey string `gorm:"type:varchar(256);index" bson:"content_key,omitempty"`
 	// 我要根据创作者ID来查询
 	AuthorId int64 `gorm:"index" bson:"author_id,omitempty"`
-	Status   uint8 `bson:"status,omitempty"`
+	Status   uint8 `gorm:"index:idx_status_utime,priority:1" bson:"status,omitempty"`
+	Price    int64 `gorm:"not null;default:0" bson:"price,omitempty"`
 	Ctime    int64 `bson:"ctime,omitempty"`
 	// 更新时间
-	Utime int64 `bson:"utime,omitempty"`
+	Utime int64 `gorm:"index:idx_status_utime,priority:2" bson:"utime,omitempty"`
+}
+
+func (p PublishedArticleV2) toPublishedArticle() PublishedArticle {
+	return PublishedArticle{
+		Id:       p.Id,
+		Title:    p.Title,
+		AuthorId: p.AuthorId,
+		Status:   p.Status,
+		Price:    p.Price,
+		Ctime:    p.Ctime,
+		Utime:    p.Utime,
+	}
+}
+
+func toPublishedArticles(pubs []PublishedArticleV2) []PublishedArticle {
+	res := make([]PublishedArticle, 0, len(pubs))
+	for _, p := range pubs {
+		res = append(res, p.toPublishedArticle())
+	}
+	return res
 }
@@ -0,0 +1,171 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ErrArticleInOtherSeries 一篇文章只能放在一个专栏里面
+var ErrArticleInOtherSeries = errors.New("文章已经在别的专栏里面了")
+
+type SeriesDAO interface {
+	Insert(ctx context.Context, s Series) (int64, error)
+	// Update 只更新标题和简介，不是这个作者的专栏返回 ErrRecordNotFound
+	Update(ctx context.Context, s Series) error
+	// Delete 专栏和文章的关系一起删掉，文章本身不动
+	Delete(ctx context.Context, uid, id int64) error
+	FindById(ctx context.Context, id int64) (Series, error)
+	// FindByUid 作者的专栏，最近修改的在前
+	FindByUid(ctx context.Context, uid int64, offset, limit int) ([]Series, int64, error)
+	// FindArticles 按照专栏里面的顺序
+	FindArticles(ctx context.Context, sid int64) ([]SeriesArticle, error)
+	// FindByAid 文章所在的专栏，不在任何专栏里面的时候返回 ErrRecordNotFound
+	FindByAid(ctx context.Context, aid int64) (SeriesArticle, error)
+	// SetArticles 整个替换专栏里面的文章和顺序
+	SetArticles(ctx context.Context, uid, sid int64, aids []int64) error
+}
+
+type Series struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement"`
+	Uid         int64  `gorm:"index:idx_uid_utime,priority:1"`
+	Title       string `gorm:"type:varchar(256)"`
+	Description string `gorm:"type:varchar(4096)"`
+	Ctime       int64
+	Utime       int64 `gorm:"index:idx_uid_utime,priority:2"`
+}
+
+// SeriesArticle 专栏里面的一篇文章，Position 从 0 开始
+type SeriesArticle struct {
+	Id       int64 `gorm:"primaryKey,autoIncrement"`
+	SeriesId int64 `gorm:"index:idx_series_position,priority:1"`
+	Aid      int64 `gorm:"uniqueIndex"`
+	Position int   `gorm:"index:idx_series_position,priority:2"`
+	Ctime    int64
+}
+
+type SeriesGORMDAO struct {
+	db *gorm.DB
+}
+
+func NewSeriesGORMDAO(db *gorm.DB) SeriesDAO {
+	return &SeriesGORMDAO{db: db}
+}
+
+func (s *SeriesGORMDAO) Insert(ctx context.Context, series Series) (int64, error) {
+	now := time.Now().UnixMilli()
+	series.Ctime = now
+	series.Utime = now
+	err := s.db.WithContext(ctx).Create(&series).Error
+	return series.Id, err
+}
+
+func (s *SeriesGORMDAO) Update(ctx context.Context, series Series) error {
+	res := s.db.WithContext(ctx).Model(&Series{}).
+		Where("id = ? AND uid = ?", series.Id, series.Uid).
+		Updates(map[string]any{
+			"title":       series.Title,
+			"description": series.Description,
+			"utime":       time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *SeriesGORMDAO) Delete(ctx context.Context, uid, id int64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND uid = ?", id, uid).Delete(&Series{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		return tx.Where("series_id = ?", id).Delete(&SeriesArticle{}).Error
+	})
+}
+
+func (s *SeriesGORMDAO) FindById(ctx context.Context, id int64) (Series, error) {
+	var res Series
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
+func (s *SeriesGORMDAO) FindByUid(ctx context.Context, uid int64, offset, limit int) ([]Series, int64, error) {
+	var (
+		res   []Series
+		count int64
+	)
+	db := s.db.WithContext(ctx)
+	err := db.Model(&Series{}).Where("uid = ?", uid).Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = db.Where("uid = ?", uid).
+		Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, count, err
+}
+
+func (s *SeriesGORMDAO) FindArticles(ctx context.Context, sid int64) ([]SeriesArticle, error) {
+	var res []SeriesArticle
+	err := s.db.WithContext(ctx).
+		Where("series_id = ?", sid).
+		Order("position ASC").
+		Find(&res).Error
+	return res, err
+}
+
+func (s *SeriesGORMDAO) FindByAid(ctx context.Context, aid int64) (SeriesArticle, error) {
+	var res SeriesArticle
+	err := s.db.WithContext(ctx).Where("aid = ?", aid).First(&res).Error
+	return res, err
+}
+
+func (s *SeriesGORMDAO) SetArticles(ctx context.Context, uid, sid int64, aids []int64) error {
+	now := time.Now().UnixMilli()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 顺便更新 utime，作者的专栏列表按照它排序
+		res := tx.Model(&Series{}).
+			Where("id = ? AND uid = ?", sid, uid).
+			Update("utime", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		if err := tx.Where("series_id = ?", sid).Delete(&SeriesArticle{}).Error; err != nil {
+			return err
+		}
+		if len(aids) == 0 {
+			return nil
+		}
+		rows := make([]SeriesArticle, 0, len(aids))
+		for i, aid := range aids {
+			rows = append(rows, SeriesArticle{
+				SeriesId: sid,
+				Aid:      aid,
+				Position: i,
+				Ctime:    now,
+			})
+		}
+		return tx.Create(&rows).Error
+	})
+	// aid 上面有唯一索引
+	if me, ok := err.(*mysql.MySQLError); ok {
+		const duplicateErr uint16 = 1062
+		if me.Number == duplicateErr {
+			return ErrArticleInOtherSeries
+		}
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+type ArticleShareDAO interface {
+	Insert(ctx context.Context, s ArticleShare) (int64, error)
+	// FindById 不存在的时候返回 ErrRecordNotFound
+	FindById(ctx context.Context, id int64) (ArticleShare, error)
+	// FindByAid 作者某篇文章的所有分享链接，新的在前
+	FindByAid(ctx context.Context, uid, aid int64) ([]ArticleShare, error)
+	// Revoke 不是这个作者的分享链接返回 ErrRecordNotFound，重复撤销不报错
+	Revoke(ctx context.Context, uid, id int64) error
+	InsertAudit(ctx context.Context, a ArticleShareAudit) error
+	// FindAudits 某个分享链接的审计记录，新的在前
+	FindAudits(ctx context.Context, shareId int64, offset, limit int) ([]ArticleShareAudit, int64, error)
+}
+
+// ArticleShare 分享链接，token 本身不保存，只保存签名里面的 Id
+type ArticleShare struct {
+	Id      int64 `gorm:"primaryKey,autoIncrement"`
+	Aid     int64 `gorm:"index:idx_aid_uid"`
+	Uid     int64 `gorm:"index:idx_aid_uid"`
+	Expire  int64
+	Revoked bool
+	Ctime   int64
+	Utime   int64
+}
+
+type ArticleShareAudit struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	ShareId   int64 `gorm:"index"`
+	Aid       int64
+	Uid       int64
+	Action    uint8
+	IP        string `gorm:"type:varchar(64)"`
+	UserAgent string `gorm:"type:varchar(512)"`
+	Ctime     int64
+}
+
+type ArticleShareGORMDAO struct {
+	db *gorm.DB
+}
+
+func NewArticleShareGORMDAO(db *gorm.DB) ArticleShareDAO {
+	return &ArticleShareGORMDAO{db: db}
+}
+
+func (a *ArticleShareGORMDAO) Insert(ctx context.Context, s ArticleShare) (int64, error) {
+	now := time.Now().UnixMilli()
+	s.Ctime = now
+	s.Utime = now
+	err := a.db.WithContext(ctx).Create(&s).Error
+	return s.Id, err
+}
+
+func (a *ArticleShareGORMDAO) FindById(ctx context.Context, id int64) (ArticleShare, error) {
+	var s ArticleShare
+	err := a.db.WithContext(ctx).Where("id = ?", id).First(&s).Error
+	return s, err
+}
+
+func (a *ArticleShareGORMDAO) FindByAid(ctx context.Context, uid, aid int64) ([]ArticleShare, error) {
+	var res []ArticleShare
+	err := a.db.WithContext(ctx).
+		Where("aid = ? AND uid = ?", aid, uid).
+		Order("id DESC").
+		Find(&res).Error
+	return res, err
+}
+
+func (a *ArticleShareGORMDAO) Revoke(ctx context.Context, uid, id int64) error {
+	res := a.db.WithContext(ctx).Model(&ArticleShare{}).
+		Where("id = ? AND uid = ?", id, uid).
+		Updates(map[string]any{
+			"revoked": true,
+			"utime":   time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (a *ArticleShareGORMDAO) InsertAudit(ctx context.Context, audit ArticleShareAudit) error {
+	audit.Ctime = time.Now().UnixMilli()
+	return a.db.WithContext(ctx).Create(&audit).Error
+}
+
+func (a *ArticleShareGORMDAO) FindAudits(ctx context.Context, shareId int64, offset, limit int) ([]ArticleShareAudit, int64, error) {
+	var (
+		res   []ArticleShareAudit
+		count int64
+	)
+	db := a.db.WithContext(ctx)
+	err := db.Model(&ArticleShareAudit{}).Where("share_id = ?", shareId).Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = db.Where("share_id = ?", shareId).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, count, err
+}
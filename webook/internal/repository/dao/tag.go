@@ -0,0 +1,234 @@
+package dao
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+type TagDAO interface {
+	// SetArticleTags 用 tags 整体替换文章原有的标签
+	SetArticleTags(ctx context.Context, aid int64, tags []string) error
+	GetArticleTags(ctx context.Context, aid int64) ([]string, error)
+	// ListPubByTag 带标签过滤的 ListPub，按照更新时间倒序
+	ListPubByTag(ctx context.Context, tag string, start time.Time, offset int, limit int) ([]PublishedArticle, error)
+	// CountPub 每个标签下已发表的文章数，按照文章数倒序
+	CountPub(ctx context.Context, offset int, limit int) ([]TagCount, error)
+}
+
+type Tag struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Name  string `gorm:"type:varchar(64);uniqueIndex"`
+	Ctime int64
+	Utime int64
+}
+
+// ArticleTag 文章和标签的关联关系
+type ArticleTag struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId int64 `gorm:"uniqueIndex:uk_article_tag"`
+	// 按照标签查文章
+	TagId int64 `gorm:"uniqueIndex:uk_article_tag;index"`
+	Ctime int64
+}
+
+type TagCount struct {
+	Name string
+	Cnt  int64
+}
+
+// TagGORMDAO ListPubByTag 和 CountPub 要 join 线上库，所以线上库也得在同一个数据库里面
+type TagGORMDAO struct {
+	db *gorm.DB
+	// pubTable 线上库的表
+	pubTable string
+}
+
+func NewTagGORMDAO(db *gorm.DB) TagDAO {
+	return &TagGORMDAO{
+		db:       db,
+		pubTable: "published_articles",
+	}
+}
+
+// NewTagS3DAO 配合 ArticleS3DAO 使用，线上库是 PublishedArticleV2 的表。
+// 和 ArticleS3DAO 的列表一样，查出来的文章没有内容
+func NewTagS3DAO(db *gorm.DB) TagDAO {
+	return &TagGORMDAO{
+		db:       db,
+		pubTable: "published_article_v2",
+	}
+}
+
+func (t *TagGORMDAO) SetArticleTags(ctx context.Context, aid int64, tags []string) error {
+	now := time.Now().UnixMilli()
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("article_id = ?", aid).Delete(&ArticleTag{}).Error
+		if err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		newTags := make([]Tag, 0, len(tags))
+		for _, name := range tags {
+			newTags = append(newTags, Tag{Name: name, Ctime: now, Utime: now})
+		}
+		// 标签是共享的，已经有了的就不用再插入了
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoNothing: true,
+		}).Create(&newTags).Error
+		if err != nil {
+			return err
+		}
+		var ids []int64
+		err = tx.Model(&Tag{}).Where("name IN ?", tags).Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		rels := make([]ArticleTag, 0, len(ids))
+		for _, id := range ids {
+			rels = append(rels, ArticleTag{ArticleId: aid, TagId: id, Ctime: now})
+		}
+		return tx.Create(&rels).Error
+	})
+}
+
+func (t *TagGORMDAO) GetArticleTags(ctx context.Context, aid int64) ([]string, error) {
+	var names []string
+	err := t.db.WithContext(ctx).Model(&Tag{}).
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Where("article_tags.article_id = ?", aid).
+		Order("article_tags.id ASC").
+		Pluck("tags.name", &names).Error
+	return names, err
+}
+
+func (t *TagGORMDAO) ListPubByTag(ctx context.Context, tag string, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := t.db.WithContext(ctx).
+		Table(t.pubTable).
+		Select(t.pubTable+".*").
+		Joins("JOIN article_tags ON article_tags.article_id = "+t.pubTable+".id").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("tags.name = ? AND "+t.pubTable+".status = ? AND "+t.pubTable+".utime < ?",
+			tag, domain.ArticleStatusPublished, start.UnixMilli()).
+		Order(t.pubTable + ".utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (t *TagGORMDAO) CountPub(ctx context.Context, offset int, limit int) ([]TagCount, error) {
+	var res []TagCount
+	err := t.db.WithContext(ctx).
+		Table("tags").
+		Select("tags.name AS name, COUNT(*) AS cnt").
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Joins("JOIN "+t.pubTable+" ON "+t.pubTable+".id = article_tags.article_id").
+		Where(t.pubTable+".status = ?", domain.ArticleStatusPublished).
+		Group("tags.name").
+		Order("cnt DESC").
+		Offset(offset).Limit(limit).
+		Scan(&res).Error
+	return res, err
+}
+
+// TagMongoDBDAO 配合 MongoDBArticleDAO 使用。标签和关联关系还是放在数据库里面，
+// 线上库在 MongoDB 里面没办法 join，所以先查出标签下的文章 ID，再去线上库里面过滤
+type TagMongoDBDAO struct {
+	*TagGORMDAO
+	art *MongoDBArticleDAO
+}
+
+func NewTagMongoDBDAO(db *gorm.DB, art *MongoDBArticleDAO) TagDAO {
+	return &TagMongoDBDAO{
+		TagGORMDAO: &TagGORMDAO{db: db},
+		art:        art,
+	}
+}
+
+func (t *TagMongoDBDAO) ListPubByTag(ctx context.Context, tag string, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
+	var ids []int64
+	err := t.db.WithContext(ctx).Model(&ArticleTag{}).
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("tags.name = ?", tag).
+		Pluck("article_tags.article_id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	filter := bson.M{
+		"id":     bson.M{"$in": ids},
+		"status": domain.ArticleStatusPublished,
+		"utime":  bson.M{"$lt": start.UnixMilli()},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "utime", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	var res []PublishedArticle
+	err = t.art.findAll(ctx, t.art.liveCol, filter, opts, &res)
+	return res, err
+}
+
+// CountPub 要把所有带标签的文章都拿到线上库里面过滤一遍，在内存里面计数和分页
+func (t *TagMongoDBDAO) CountPub(ctx context.Context, offset int, limit int) ([]TagCount, error) {
+	var rels []struct {
+		Name      string
+		ArticleId int64
+	}
+	err := t.db.WithContext(ctx).
+		Table("tags").
+		Select("tags.name AS name, article_tags.article_id AS article_id").
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Scan(&rels).Error
+	if err != nil || len(rels) == 0 {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(rels))
+	for _, rel := range rels {
+		ids = append(ids, rel.ArticleId)
+	}
+	var pubs []PublishedArticle
+	err = t.art.findAll(ctx, t.art.liveCol, bson.M{
+		"id":     bson.M{"$in": ids},
+		"status": domain.ArticleStatusPublished,
+	}, options.Find().SetProjection(bson.M{"id": 1}), &pubs)
+	if err != nil {
+		return nil, err
+	}
+	published := make(map[int64]struct{}, len(pubs))
+	for _, pub := range pubs {
+		published[pub.Id] = struct{}{}
+	}
+	cnts := make(map[string]int64)
+	for _, rel := range rels {
+		if _, ok := published[rel.ArticleId]; ok {
+			cnts[rel.Name]++
+		}
+	}
+	res := make([]TagCount, 0, len(cnts))
+	for name, cnt := range cnts {
+		res = append(res, TagCount{Name: name, Cnt: cnt})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Cnt != res[j].Cnt {
+			return res[i].Cnt > res[j].Cnt
+		}
+		return res[i].Name < res[j].Name
+	})
+	if offset >= len(res) {
+		return []TagCount{}, nil
+	}
+	res = res[offset:]
+	if limit < len(res) {
+		res = res[:limit]
+	}
+	return res, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
+	"time"
+)
+
+// FeedRepository 信息流只放在 Redis 里面，丢了的部分可以从发件箱拉回来
+type FeedRepository interface {
+	PushInbox(ctx context.Context, uids []int64, aid int64, ftime time.Time) error
+	AddOutbox(ctx context.Context, uid int64, aid int64, ftime time.Time) error
+	RemoveOutbox(ctx context.Context, uid int64, aid int64) error
+	ListInbox(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error)
+	ListOutbox(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error)
+	FillInbox(ctx context.Context, uid int64, items []domain.FeedItem) error
+	SetActive(ctx context.Context, uid int64, t time.Time) error
+	FilterActive(ctx context.Context, uids []int64, since time.Time) ([]int64, error)
+	SetBigV(ctx context.Context, uid int64, bigV bool) error
+	FilterBigV(ctx context.Context, uids []int64) ([]int64, error)
+}
+
+type feedRepository struct {
+	cache cache.FeedCache
+}
+
+func NewFeedRepository(cache cache.FeedCache) FeedRepository {
+	return &feedRepository{
+		cache: cache,
+	}
+}
+
+func (f *feedRepository) PushInbox(ctx context.Context, uids []int64, aid int64, ftime time.Time) error {
+	return f.cache.PushInbox(ctx, uids, aid, ftime)
+}
+
+func (f *feedRepository) AddOutbox(ctx context.Context, uid int64, aid int64, ftime time.Time) error {
+	return f.cache.AddOutbox(ctx, uid, aid, ftime)
+}
+
+func (f *feedRepository) RemoveOutbox(ctx context.Context, uid int64, aid int64) error {
+	return f.cache.RemoveOutbox(ctx, uid, aid)
+}
+
+func (f *feedRepository) ListInbox(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	return f.cache.ListInbox(ctx, uid, cursor, limit)
+}
+
+func (f *feedRepository) ListOutbox(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	return f.cache.ListOutbox(ctx, uid, cursor, limit)
+}
+
+func (f *feedRepository) SetActive(ctx context.Context, uid int64, t time.Time) error {
+	return f.cache.SetActive(ctx, uid, t)
+}
+
+func (f *feedRepository) FillInbox(ctx context.Context, uid int64, items []domain.FeedItem) error {
+	return f.cache.FillInbox(ctx, uid, items)
+}
+
+func (f *feedRepository) FilterActive(ctx context.Context, uids []int64, since time.Time) ([]int64, error) {
+	return f.cache.FilterActive(ctx, uids, since)
+}
+
+func (f *feedRepository) SetBigV(ctx context.Context, uid int64, bigV bool) error {
+	return f.cache.SetBigV(ctx, uid, bigV)
+}
+
+func (f *feedRepository) FilterBigV(ctx context.Context, uids []int64) ([]int64, error) {
+	return f.cache.FilterBigV(ctx, uids)
+}
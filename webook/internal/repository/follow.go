@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"time"
+)
+
+var ErrFollowRelationNotFound = dao.ErrRecordNotFound
+
+type FollowRepository interface {
+	// Follow 和 Unfollow 是幂等的，关注数和粉丝数只在关系真的变化的时候才变
+	Follow(ctx context.Context, follower, followee int64) error
+	Unfollow(ctx context.Context, follower, followee int64) error
+	// GetRelation 没有关注的时候返回 ErrFollowRelationNotFound
+	GetRelation(ctx context.Context, follower, followee int64) (domain.FollowRelation, error)
+	ListFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error)
+	ListFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error)
+	// FindFollowers 按照关系的 id 遍历全部粉丝，minId 为 0 表示从头开始
+	FindFollowers(ctx context.Context, followee int64, minId int64, limit int) ([]domain.FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+}
+
+type CachedFollowRepository struct {
+	dao   dao.FollowDAO
+	cache cache.FollowCache
+}
+
+func NewCachedFollowRepository(dao dao.FollowDAO, cache cache.FollowCache) FollowRepository {
+	return &CachedFollowRepository{
+		dao:   dao,
+		cache: cache,
+	}
+}
+
+func (r *CachedFollowRepository) Follow(ctx context.Context, follower, followee int64) error {
+	changed, err := r.dao.Follow(ctx, follower, followee)
+	if err != nil || !changed {
+		return err
+	}
+	r.delStatics(ctx, follower, followee)
+	return nil
+}
+
+func (r *CachedFollowRepository) Unfollow(ctx context.Context, follower, followee int64) error {
+	changed, err := r.dao.Unfollow(ctx, follower, followee)
+	if err != nil || !changed {
+		return err
+	}
+	r.delStatics(ctx, follower, followee)
+	return nil
+}
+
+// delStatics 删除失败的时候最多 15 分钟的不一致，计数本身已经在数据库里面了
+func (r *CachedFollowRepository) delStatics(ctx context.Context, uids ...int64) {
+	for _, uid := range uids {
+		_ = r.cache.DelStatics(ctx, uid)
+	}
+}
+
+func (r *CachedFollowRepository) GetRelation(ctx context.Context, follower, followee int64) (domain.FollowRelation, error) {
+	rel, err := r.dao.GetRelation(ctx, follower, followee)
+	if err != nil {
+		return domain.FollowRelation{}, err
+	}
+	return r.toDomain(rel), nil
+}
+
+func (r *CachedFollowRepository) ListFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error) {
+	rels, err := r.dao.ListFollowees(ctx, follower, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(rels), nil
+}
+
+func (r *CachedFollowRepository) ListFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error) {
+	rels, err := r.dao.ListFollowers(ctx, followee, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(rels), nil
+}
+
+func (r *CachedFollowRepository) FindFollowers(ctx context.Context, followee int64, minId int64, limit int) ([]domain.FollowRelation, error) {
+	rels, err := r.dao.FindFollowers(ctx, followee, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(rels), nil
+}
+
+func (r *CachedFollowRepository) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	res, err := r.cache.GetStatics(ctx, uid)
+	if err == nil {
+		return res, nil
+	}
+	statics, err := r.dao.GetStatics(ctx, uid)
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	res = domain.FollowStatics{
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+	}
+	_ = r.cache.SetStatics(ctx, uid, res)
+	return res, nil
+}
+
+func (r *CachedFollowRepository) toDomains(rels []dao.FollowRelation) []domain.FollowRelation {
+	return slice.Map[dao.FollowRelation, domain.FollowRelation](rels,
+		func(idx int, src dao.FollowRelation) domain.FollowRelation {
+			return r.toDomain(src)
+		})
+}
+
+func (r *CachedFollowRepository) toDomain(rel dao.FollowRelation) domain.FollowRelation {
+	return domain.FollowRelation{
+		Id:       rel.Id,
+		Follower: rel.Follower,
+		Followee: rel.Followee,
+		Ctime:    time.UnixMilli(rel.Ctime),
+	}
+}
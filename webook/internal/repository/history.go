@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"time"
+)
+
+var ErrReadHistoryNotFound = dao.ErrRecordNotFound
+
+type ReadHistoryRepository interface {
+	AddRead(ctx context.Context, uid, aid int64, rtime time.Time) error
+	SetProgress(ctx context.Context, uid, aid int64, progress float64) error
+	// Get 没有看过的时候返回 ErrReadHistoryNotFound
+	Get(ctx context.Context, uid, aid int64) (domain.ReadHistory, error)
+	// List 返回的 Article 只有 Id
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.ReadHistory, int64, error)
+	Clear(ctx context.Context, uid int64) error
+	// FindSince 按照 Id 遍历 since 之后看过的记录，minId 为 0 表示从头开始
+	FindSince(ctx context.Context, since time.Time, minId int64, limit int) ([]domain.ReadHistory, error)
+}
+
+type readHistoryRepository struct {
+	dao dao.ReadHistoryDAO
+}
+
+func NewReadHistoryRepository(dao dao.ReadHistoryDAO) ReadHistoryRepository {
+	return &readHistoryRepository{dao: dao}
+}
+
+func (r *readHistoryRepository) AddRead(ctx context.Context, uid, aid int64, rtime time.Time) error {
+	return r.dao.Upsert(ctx, uid, aid, rtime.UnixMilli())
+}
+
+func (r *readHistoryRepository) SetProgress(ctx context.Context, uid, aid int64, progress float64) error {
+	return r.dao.UpsertProgress(ctx, uid, aid, progress)
+}
+
+func (r *readHistoryRepository) Get(ctx context.Context, uid, aid int64) (domain.ReadHistory, error) {
+	h, err := r.dao.Get(ctx, uid, aid)
+	if err != nil {
+		return domain.ReadHistory{}, err
+	}
+	return r.toDomain(h), nil
+}
+
+func (r *readHistoryRepository) List(ctx context.Context, uid int64, offset, limit int) ([]domain.ReadHistory, int64, error) {
+	hs, count, err := r.dao.List(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return slice.Map(hs, func(idx int, src dao.ReadHistory) domain.ReadHistory {
+		return r.toDomain(src)
+	}), count, nil
+}
+
+func (r *readHistoryRepository) Clear(ctx context.Context, uid int64) error {
+	return r.dao.DeleteAll(ctx, uid)
+}
+
+func (r *readHistoryRepository) FindSince(ctx context.Context, since time.Time, minId int64, limit int) ([]domain.ReadHistory, error) {
+	hs, err := r.dao.FindSince(ctx, since.UnixMilli(), minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(hs, func(idx int, src dao.ReadHistory) domain.ReadHistory {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *readHistoryRepository) toDomain(h dao.ReadHistory) domain.ReadHistory {
+	return domain.ReadHistory{
+		Id:       h.Id,
+		Uid:      h.Uid,
+		Article:  domain.Article{Id: h.Aid},
+		Progress: h.Progress,
+		Rtime:    time.UnixMilli(h.Utime),
+	}
+}
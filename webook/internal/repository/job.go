@@ -12,14 +12,25 @@ type CronJobRepository interface {
 	Release(ctx context.Context, jid int64) error
 	UpdateUtime(ctx context.Context, jid int64) error
 	UpdateNextTime(ctx context.Context, id int64, time time.Time) error
+	AddJob(ctx context.Context, j domain.Job) error
 }
 
 type PreemptJobRepository struct {
 	dao dao.JobDAO
 }
 
+func (p *PreemptJobRepository) AddJob(ctx context.Context, j domain.Job) error {
+	return p.dao.Insert(ctx, dao.Job{
+		Name:       j.Name,
+		Executor:   j.Executor,
+		Expression: j.Expression,
+		Cfg:        j.Cfg,
+		NextTime:   j.NextTime().UnixMilli(),
+	})
+}
+
 func (p *PreemptJobRepository) UpdateNextTime(ctx context.Context, id int64, time time.Time) error {
-	return p.dao.UpdateUtime(ctx, id)
+	return p.dao.UpdateNextTime(ctx, id, time)
 }
 
 func (p *PreemptJobRepository) UpdateUtime(ctx context.Context, jid int64) error {
@@ -27,8 +38,7 @@ func (p *PreemptJobRepository) UpdateUtime(ctx context.Context, jid int64) error
 }
 
 func (p *PreemptJobRepository) Release(ctx context.Context, jid int64) error {
-	//TODO implement me
-	panic("implement me")
+	return p.dao.Release(ctx, jid)
 }
 
 func NewPreemptJobRepository(dao dao.JobDAO) CronJobRepository {
@@ -42,5 +52,6 @@ func (p *PreemptJobRepository) Preempt(ctx context.Context) (domain.Job, error)
 		Expression: j.Expression,
 		Executor:   j.Executor,
 		Name:       j.Name,
+		Cfg:        j.Cfg,
 	}, err
 }
@@ -40,6 +40,20 @@ func (m *MockArticleRepository) EXPECT() *MockArticleRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CancelSchedule mocks base method.
+func (m *MockArticleRepository) CancelSchedule(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockArticleRepositoryMockRecorder) CancelSchedule(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*MockArticleRepository)(nil).CancelSchedule), ctx, uid, id)
+}
+
 // Create mocks base method.
 func (m *MockArticleRepository) Create(ctx context.Context, article domain.Article) (int64, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +69,65 @@ func (mr *MockArticleRepositoryMockRecorder) Create(ctx, article any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockArticleRepository)(nil).Create), ctx, article)
 }
 
+// Delete mocks base method.
+func (m *MockArticleRepository) Delete(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockArticleRepositoryMockRecorder) Delete(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockArticleRepository)(nil).Delete), ctx, uid, id)
+}
+
+// FindByAuthor mocks base method.
+func (m *MockArticleRepository) FindByAuthor(ctx context.Context, uid, minId int64, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByAuthor", ctx, uid, minId, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByAuthor indicates an expected call of FindByAuthor.
+func (mr *MockArticleRepositoryMockRecorder) FindByAuthor(ctx, uid, minId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByAuthor", reflect.TypeOf((*MockArticleRepository)(nil).FindByAuthor), ctx, uid, minId, limit)
+}
+
+// FindDueScheduled mocks base method.
+func (m *MockArticleRepository) FindDueScheduled(ctx context.Context, now time.Time, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDueScheduled", ctx, now, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDueScheduled indicates an expected call of FindDueScheduled.
+func (mr *MockArticleRepositoryMockRecorder) FindDueScheduled(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDueScheduled", reflect.TypeOf((*MockArticleRepository)(nil).FindDueScheduled), ctx, now, limit)
+}
+
+// FindExpiredDeleted mocks base method.
+func (m *MockArticleRepository) FindExpiredDeleted(ctx context.Context, before time.Time, limit int) ([]domain.DeletedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindExpiredDeleted", ctx, before, limit)
+	ret0, _ := ret[0].([]domain.DeletedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindExpiredDeleted indicates an expected call of FindExpiredDeleted.
+func (mr *MockArticleRepositoryMockRecorder) FindExpiredDeleted(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindExpiredDeleted", reflect.TypeOf((*MockArticleRepository)(nil).FindExpiredDeleted), ctx, before, limit)
+}
+
 // GetByAuthor mocks base method.
 func (m *MockArticleRepository) GetByAuthor(ctx context.Context, uid int64, limit, offset int) ([]domain.Article, int64, error) {
 	m.ctrl.T.Helper()
@@ -86,6 +159,21 @@ func (mr *MockArticleRepositoryMockRecorder) GetById(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockArticleRepository)(nil).GetById), ctx, id)
 }
 
+// GetByIds mocks base method.
+func (m *MockArticleRepository) GetByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIds", ctx, ids)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIds indicates an expected call of GetByIds.
+func (mr *MockArticleRepositoryMockRecorder) GetByIds(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIds", reflect.TypeOf((*MockArticleRepository)(nil).GetByIds), ctx, ids)
+}
+
 // GetPubById mocks base method.
 func (m *MockArticleRepository) GetPubById(ctx context.Context, id int64) (domain.Article, error) {
 	m.ctrl.T.Helper()
@@ -101,6 +189,22 @@ func (mr *MockArticleRepositoryMockRecorder) GetPubById(ctx, id any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubById", reflect.TypeOf((*MockArticleRepository)(nil).GetPubById), ctx, id)
 }
 
+// ListDeleted mocks base method.
+func (m *MockArticleRepository) ListDeleted(ctx context.Context, uid int64, offset, limit int) ([]domain.DeletedArticle, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]domain.DeletedArticle)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockArticleRepositoryMockRecorder) ListDeleted(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockArticleRepository)(nil).ListDeleted), ctx, uid, offset, limit)
+}
+
 // ListPub mocks base method.
 func (m *MockArticleRepository) ListPub(ctx context.Context, start time.Time, offset, limit int) ([]domain.Article, error) {
 	m.ctrl.T.Helper()
@@ -116,6 +220,110 @@ func (mr *MockArticleRepositoryMockRecorder) ListPub(ctx, start, offset, limit a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPub", reflect.TypeOf((*MockArticleRepository)(nil).ListPub), ctx, start, offset, limit)
 }
 
+// ListPubByAuthor mocks base method.
+func (m *MockArticleRepository) ListPubByAuthor(ctx context.Context, uid int64, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByAuthor", ctx, uid, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByAuthor indicates an expected call of ListPubByAuthor.
+func (mr *MockArticleRepositoryMockRecorder) ListPubByAuthor(ctx, uid, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByAuthor", reflect.TypeOf((*MockArticleRepository)(nil).ListPubByAuthor), ctx, uid, limit)
+}
+
+// ListPubByCursor mocks base method.
+func (m *MockArticleRepository) ListPubByCursor(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByCursor", ctx, cursor, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByCursor indicates an expected call of ListPubByCursor.
+func (mr *MockArticleRepositoryMockRecorder) ListPubByCursor(ctx, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByCursor", reflect.TypeOf((*MockArticleRepository)(nil).ListPubByCursor), ctx, cursor, limit)
+}
+
+// ListPubByCursorWithContent mocks base method.
+func (m *MockArticleRepository) ListPubByCursorWithContent(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByCursorWithContent", ctx, cursor, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByCursorWithContent indicates an expected call of ListPubByCursorWithContent.
+func (mr *MockArticleRepositoryMockRecorder) ListPubByCursorWithContent(ctx, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByCursorWithContent", reflect.TypeOf((*MockArticleRepository)(nil).ListPubByCursorWithContent), ctx, cursor, limit)
+}
+
+// ListScheduled mocks base method.
+func (m *MockArticleRepository) ListScheduled(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduled", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListScheduled indicates an expected call of ListScheduled.
+func (mr *MockArticleRepositoryMockRecorder) ListScheduled(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduled", reflect.TypeOf((*MockArticleRepository)(nil).ListScheduled), ctx, uid, offset, limit)
+}
+
+// Purge mocks base method.
+func (m *MockArticleRepository) Purge(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockArticleRepositoryMockRecorder) Purge(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockArticleRepository)(nil).Purge), ctx, id)
+}
+
+// Reschedule mocks base method.
+func (m *MockArticleRepository) Reschedule(ctx context.Context, uid, id int64, publishAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reschedule", ctx, uid, id, publishAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reschedule indicates an expected call of Reschedule.
+func (mr *MockArticleRepositoryMockRecorder) Reschedule(ctx, uid, id, publishAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reschedule", reflect.TypeOf((*MockArticleRepository)(nil).Reschedule), ctx, uid, id, publishAt)
+}
+
+// Restore mocks base method.
+func (m *MockArticleRepository) Restore(ctx context.Context, uid, id int64) (domain.DeletedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, uid, id)
+	ret0, _ := ret[0].(domain.DeletedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockArticleRepositoryMockRecorder) Restore(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockArticleRepository)(nil).Restore), ctx, uid, id)
+}
+
 // Sync mocks base method.
 func (m *MockArticleRepository) Sync(ctx context.Context, art domain.Article) (int64, error) {
 	m.ctrl.T.Helper()
@@ -145,16 +353,30 @@ func (mr *MockArticleRepositoryMockRecorder) SyncStatus(ctx, uid, id, status any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncStatus", reflect.TypeOf((*MockArticleRepository)(nil).SyncStatus), ctx, uid, id, status)
 }
 
+// TransitStatus mocks base method.
+func (m *MockArticleRepository) TransitStatus(ctx context.Context, uid, id int64, from, to domain.ArticleStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitStatus", ctx, uid, id, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransitStatus indicates an expected call of TransitStatus.
+func (mr *MockArticleRepositoryMockRecorder) TransitStatus(ctx, uid, id, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitStatus", reflect.TypeOf((*MockArticleRepository)(nil).TransitStatus), ctx, uid, id, from, to)
+}
+
 // Update mocks base method.
-func (m *MockArticleRepository) Update(ctx context.Context, article domain.Article) error {
+func (m *MockArticleRepository) Update(ctx context.Context, biz string, article domain.Article) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, article)
+	ret := m.ctrl.Call(m, "Update", ctx, biz, article)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockArticleRepositoryMockRecorder) Update(ctx, article any) *gomock.Call {
+func (mr *MockArticleRepositoryMockRecorder) Update(ctx, biz, article any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockArticleRepository)(nil).Update), ctx, article)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockArticleRepository)(nil).Update), ctx, biz, article)
 }
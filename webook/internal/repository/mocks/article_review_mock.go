@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/article_review.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/article_review.go -destination=./internal/repository/mocks/article_review_mock.go
+//
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleReviewRepository is a mock of ArticleReviewRepository interface.
+type MockArticleReviewRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleReviewRepositoryMockRecorder
+}
+
+// MockArticleReviewRepositoryMockRecorder is the mock recorder for MockArticleReviewRepository.
+type MockArticleReviewRepositoryMockRecorder struct {
+	mock *MockArticleReviewRepository
+}
+
+// NewMockArticleReviewRepository creates a new mock instance.
+func NewMockArticleReviewRepository(ctrl *gomock.Controller) *MockArticleReviewRepository {
+	mock := &MockArticleReviewRepository{ctrl: ctrl}
+	mock.recorder = &MockArticleReviewRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleReviewRepository) EXPECT() *MockArticleReviewRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockArticleReviewRepository) Create(ctx context.Context, r domain.ArticleReview) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, r)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockArticleReviewRepositoryMockRecorder) Create(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockArticleReviewRepository)(nil).Create), ctx, r)
+}
+
+// Finish mocks base method.
+func (m *MockArticleReviewRepository) Finish(ctx context.Context, id int64, status domain.ReviewStatus, reviewerId int64, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finish", ctx, id, status, reviewerId, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Finish indicates an expected call of Finish.
+func (mr *MockArticleReviewRepositoryMockRecorder) Finish(ctx, id, status, reviewerId, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockArticleReviewRepository)(nil).Finish), ctx, id, status, reviewerId, reason)
+}
+
+// GetById mocks base method.
+func (m *MockArticleReviewRepository) GetById(ctx context.Context, id int64) (domain.ArticleReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(domain.ArticleReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockArticleReviewRepositoryMockRecorder) GetById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockArticleReviewRepository)(nil).GetById), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockArticleReviewRepository) List(ctx context.Context, status domain.ReviewStatus, offset, limit int) ([]domain.ArticleReview, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, status, offset, limit)
+	ret0, _ := ret[0].([]domain.ArticleReview)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockArticleReviewRepositoryMockRecorder) List(ctx, status, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockArticleReviewRepository)(nil).List), ctx, status, offset, limit)
+}
@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/article_revision.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/article_revision.go -destination=./internal/repository/mocks/article_revision_mock.go
+//
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleRevisionRepository is a mock of ArticleRevisionRepository interface.
+type MockArticleRevisionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleRevisionRepositoryMockRecorder
+}
+
+// MockArticleRevisionRepositoryMockRecorder is the mock recorder for MockArticleRevisionRepository.
+type MockArticleRevisionRepositoryMockRecorder struct {
+	mock *MockArticleRevisionRepository
+}
+
+// NewMockArticleRevisionRepository creates a new mock instance.
+func NewMockArticleRevisionRepository(ctrl *gomock.Controller) *MockArticleRevisionRepository {
+	mock := &MockArticleRevisionRepository{ctrl: ctrl}
+	mock.recorder = &MockArticleRevisionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleRevisionRepository) EXPECT() *MockArticleRevisionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockArticleRevisionRepository) Append(ctx context.Context, art domain.Article) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, art)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockArticleRevisionRepositoryMockRecorder) Append(ctx, art any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockArticleRevisionRepository)(nil).Append), ctx, art)
+}
+
+// GetById mocks base method.
+func (m *MockArticleRevisionRepository) GetById(ctx context.Context, id int64) (domain.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(domain.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockArticleRevisionRepositoryMockRecorder) GetById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockArticleRevisionRepository)(nil).GetById), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockArticleRevisionRepository) List(ctx context.Context, uid, aid int64, offset, limit int) ([]domain.ArticleRevision, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid, aid, offset, limit)
+	ret0, _ := ret[0].([]domain.ArticleRevision)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockArticleRevisionRepositoryMockRecorder) List(ctx, uid, aid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockArticleRevisionRepository)(nil).List), ctx, uid, aid, offset, limit)
+}
@@ -0,0 +1,158 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/attachment.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/attachment.go -destination=./internal/repository/mocks/attachment_mock.go
+//
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAttachmentRepository is a mock of AttachmentRepository interface.
+type MockAttachmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentRepositoryMockRecorder
+}
+
+// MockAttachmentRepositoryMockRecorder is the mock recorder for MockAttachmentRepository.
+type MockAttachmentRepositoryMockRecorder struct {
+	mock *MockAttachmentRepository
+}
+
+// NewMockAttachmentRepository creates a new mock instance.
+func NewMockAttachmentRepository(ctrl *gomock.Controller) *MockAttachmentRepository {
+	mock := &MockAttachmentRepository{ctrl: ctrl}
+	mock.recorder = &MockAttachmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentRepository) EXPECT() *MockAttachmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteOrphan mocks base method.
+func (m *MockAttachmentRepository) DeleteOrphan(ctx context.Context, att domain.Attachment, before time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrphan", ctx, att, before)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOrphan indicates an expected call of DeleteOrphan.
+func (mr *MockAttachmentRepositoryMockRecorder) DeleteOrphan(ctx, att, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrphan", reflect.TypeOf((*MockAttachmentRepository)(nil).DeleteOrphan), ctx, att, before)
+}
+
+// DeleteReferences mocks base method.
+func (m *MockAttachmentRepository) DeleteReferences(ctx context.Context, aid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReferences", ctx, aid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReferences indicates an expected call of DeleteReferences.
+func (mr *MockAttachmentRepositoryMockRecorder) DeleteReferences(ctx, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReferences", reflect.TypeOf((*MockAttachmentRepository)(nil).DeleteReferences), ctx, aid)
+}
+
+// FindOrphans mocks base method.
+func (m *MockAttachmentRepository) FindOrphans(ctx context.Context, before time.Time, limit int) ([]domain.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrphans", ctx, before, limit)
+	ret0, _ := ret[0].([]domain.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrphans indicates an expected call of FindOrphans.
+func (mr *MockAttachmentRepositoryMockRecorder) FindOrphans(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrphans", reflect.TypeOf((*MockAttachmentRepository)(nil).FindOrphans), ctx, before, limit)
+}
+
+// GetByHash mocks base method.
+func (m *MockAttachmentRepository) GetByHash(ctx context.Context, hash string) (domain.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, hash)
+	ret0, _ := ret[0].(domain.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockAttachmentRepositoryMockRecorder) GetByHash(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockAttachmentRepository)(nil).GetByHash), ctx, hash)
+}
+
+// GetContent mocks base method.
+func (m *MockAttachmentRepository) GetContent(ctx context.Context, att domain.Attachment, thumb bool) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContent", ctx, att, thumb)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContent indicates an expected call of GetContent.
+func (mr *MockAttachmentRepositoryMockRecorder) GetContent(ctx, att, thumb any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContent", reflect.TypeOf((*MockAttachmentRepository)(nil).GetContent), ctx, att, thumb)
+}
+
+// Save mocks base method.
+func (m *MockAttachmentRepository) Save(ctx context.Context, att domain.Attachment, data, thumb []byte) (domain.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, att, data, thumb)
+	ret0, _ := ret[0].(domain.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockAttachmentRepositoryMockRecorder) Save(ctx, att, data, thumb any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockAttachmentRepository)(nil).Save), ctx, att, data, thumb)
+}
+
+// SetDraftReferences mocks base method.
+func (m *MockAttachmentRepository) SetDraftReferences(ctx context.Context, aid int64, hashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDraftReferences", ctx, aid, hashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDraftReferences indicates an expected call of SetDraftReferences.
+func (mr *MockAttachmentRepositoryMockRecorder) SetDraftReferences(ctx, aid, hashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDraftReferences", reflect.TypeOf((*MockAttachmentRepository)(nil).SetDraftReferences), ctx, aid, hashes)
+}
+
+// SetPubReferences mocks base method.
+func (m *MockAttachmentRepository) SetPubReferences(ctx context.Context, aid int64, hashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPubReferences", ctx, aid, hashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPubReferences indicates an expected call of SetPubReferences.
+func (mr *MockAttachmentRepositoryMockRecorder) SetPubReferences(ctx, aid, hashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPubReferences", reflect.TypeOf((*MockAttachmentRepository)(nil).SetPubReferences), ctx, aid, hashes)
+}
@@ -0,0 +1,185 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/feed.go -destination=./internal/repository/mocks/feed_mock.go
+//
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedRepository is a mock of FeedRepository interface.
+type MockFeedRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedRepositoryMockRecorder
+}
+
+// MockFeedRepositoryMockRecorder is the mock recorder for MockFeedRepository.
+type MockFeedRepositoryMockRecorder struct {
+	mock *MockFeedRepository
+}
+
+// NewMockFeedRepository creates a new mock instance.
+func NewMockFeedRepository(ctrl *gomock.Controller) *MockFeedRepository {
+	mock := &MockFeedRepository{ctrl: ctrl}
+	mock.recorder = &MockFeedRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedRepository) EXPECT() *MockFeedRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddOutbox mocks base method.
+func (m *MockFeedRepository) AddOutbox(ctx context.Context, uid, aid int64, ftime time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOutbox", ctx, uid, aid, ftime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddOutbox indicates an expected call of AddOutbox.
+func (mr *MockFeedRepositoryMockRecorder) AddOutbox(ctx, uid, aid, ftime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOutbox", reflect.TypeOf((*MockFeedRepository)(nil).AddOutbox), ctx, uid, aid, ftime)
+}
+
+// FillInbox mocks base method.
+func (m *MockFeedRepository) FillInbox(ctx context.Context, uid int64, items []domain.FeedItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FillInbox", ctx, uid, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FillInbox indicates an expected call of FillInbox.
+func (mr *MockFeedRepositoryMockRecorder) FillInbox(ctx, uid, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FillInbox", reflect.TypeOf((*MockFeedRepository)(nil).FillInbox), ctx, uid, items)
+}
+
+// FilterActive mocks base method.
+func (m *MockFeedRepository) FilterActive(ctx context.Context, uids []int64, since time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterActive", ctx, uids, since)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterActive indicates an expected call of FilterActive.
+func (mr *MockFeedRepositoryMockRecorder) FilterActive(ctx, uids, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterActive", reflect.TypeOf((*MockFeedRepository)(nil).FilterActive), ctx, uids, since)
+}
+
+// FilterBigV mocks base method.
+func (m *MockFeedRepository) FilterBigV(ctx context.Context, uids []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterBigV", ctx, uids)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterBigV indicates an expected call of FilterBigV.
+func (mr *MockFeedRepositoryMockRecorder) FilterBigV(ctx, uids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterBigV", reflect.TypeOf((*MockFeedRepository)(nil).FilterBigV), ctx, uids)
+}
+
+// ListInbox mocks base method.
+func (m *MockFeedRepository) ListInbox(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInbox", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]domain.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInbox indicates an expected call of ListInbox.
+func (mr *MockFeedRepositoryMockRecorder) ListInbox(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInbox", reflect.TypeOf((*MockFeedRepository)(nil).ListInbox), ctx, uid, cursor, limit)
+}
+
+// ListOutbox mocks base method.
+func (m *MockFeedRepository) ListOutbox(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOutbox", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]domain.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutbox indicates an expected call of ListOutbox.
+func (mr *MockFeedRepositoryMockRecorder) ListOutbox(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutbox", reflect.TypeOf((*MockFeedRepository)(nil).ListOutbox), ctx, uid, cursor, limit)
+}
+
+// PushInbox mocks base method.
+func (m *MockFeedRepository) PushInbox(ctx context.Context, uids []int64, aid int64, ftime time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushInbox", ctx, uids, aid, ftime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PushInbox indicates an expected call of PushInbox.
+func (mr *MockFeedRepositoryMockRecorder) PushInbox(ctx, uids, aid, ftime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushInbox", reflect.TypeOf((*MockFeedRepository)(nil).PushInbox), ctx, uids, aid, ftime)
+}
+
+// RemoveOutbox mocks base method.
+func (m *MockFeedRepository) RemoveOutbox(ctx context.Context, uid, aid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveOutbox", ctx, uid, aid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveOutbox indicates an expected call of RemoveOutbox.
+func (mr *MockFeedRepositoryMockRecorder) RemoveOutbox(ctx, uid, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOutbox", reflect.TypeOf((*MockFeedRepository)(nil).RemoveOutbox), ctx, uid, aid)
+}
+
+// SetActive mocks base method.
+func (m *MockFeedRepository) SetActive(ctx context.Context, uid int64, t time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActive", ctx, uid, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetActive indicates an expected call of SetActive.
+func (mr *MockFeedRepositoryMockRecorder) SetActive(ctx, uid, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActive", reflect.TypeOf((*MockFeedRepository)(nil).SetActive), ctx, uid, t)
+}
+
+// SetBigV mocks base method.
+func (m *MockFeedRepository) SetBigV(ctx context.Context, uid int64, bigV bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBigV", ctx, uid, bigV)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBigV indicates an expected call of SetBigV.
+func (mr *MockFeedRepositoryMockRecorder) SetBigV(ctx, uid, bigV any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBigV", reflect.TypeOf((*MockFeedRepository)(nil).SetBigV), ctx, uid, bigV)
+}
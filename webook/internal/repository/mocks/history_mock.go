@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/history.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/history.go -destination=./internal/repository/mocks/history_mock.go
+//
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReadHistoryRepository is a mock of ReadHistoryRepository interface.
+type MockReadHistoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadHistoryRepositoryMockRecorder
+}
+
+// MockReadHistoryRepositoryMockRecorder is the mock recorder for MockReadHistoryRepository.
+type MockReadHistoryRepositoryMockRecorder struct {
+	mock *MockReadHistoryRepository
+}
+
+// NewMockReadHistoryRepository creates a new mock instance.
+func NewMockReadHistoryRepository(ctrl *gomock.Controller) *MockReadHistoryRepository {
+	mock := &MockReadHistoryRepository{ctrl: ctrl}
+	mock.recorder = &MockReadHistoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReadHistoryRepository) EXPECT() *MockReadHistoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddRead mocks base method.
+func (m *MockReadHistoryRepository) AddRead(ctx context.Context, uid, aid int64, rtime time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRead", ctx, uid, aid, rtime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRead indicates an expected call of AddRead.
+func (mr *MockReadHistoryRepositoryMockRecorder) AddRead(ctx, uid, aid, rtime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRead", reflect.TypeOf((*MockReadHistoryRepository)(nil).AddRead), ctx, uid, aid, rtime)
+}
+
+// Clear mocks base method.
+func (m *MockReadHistoryRepository) Clear(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clear", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockReadHistoryRepositoryMockRecorder) Clear(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockReadHistoryRepository)(nil).Clear), ctx, uid)
+}
+
+// FindSince mocks base method.
+func (m *MockReadHistoryRepository) FindSince(ctx context.Context, since time.Time, minId int64, limit int) ([]domain.ReadHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSince", ctx, since, minId, limit)
+	ret0, _ := ret[0].([]domain.ReadHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSince indicates an expected call of FindSince.
+func (mr *MockReadHistoryRepositoryMockRecorder) FindSince(ctx, since, minId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSince", reflect.TypeOf((*MockReadHistoryRepository)(nil).FindSince), ctx, since, minId, limit)
+}
+
+// Get mocks base method.
+func (m *MockReadHistoryRepository) Get(ctx context.Context, uid, aid int64) (domain.ReadHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, uid, aid)
+	ret0, _ := ret[0].(domain.ReadHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockReadHistoryRepositoryMockRecorder) Get(ctx, uid, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockReadHistoryRepository)(nil).Get), ctx, uid, aid)
+}
+
+// List mocks base method.
+func (m *MockReadHistoryRepository) List(ctx context.Context, uid int64, offset, limit int) ([]domain.ReadHistory, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]domain.ReadHistory)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockReadHistoryRepositoryMockRecorder) List(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReadHistoryRepository)(nil).List), ctx, uid, offset, limit)
+}
+
+// SetProgress mocks base method.
+func (m *MockReadHistoryRepository) SetProgress(ctx context.Context, uid, aid int64, progress float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProgress", ctx, uid, aid, progress)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProgress indicates an expected call of SetProgress.
+func (mr *MockReadHistoryRepositoryMockRecorder) SetProgress(ctx, uid, aid, progress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProgress", reflect.TypeOf((*MockReadHistoryRepository)(nil).SetProgress), ctx, uid, aid, progress)
+}
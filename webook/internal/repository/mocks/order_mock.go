@@ -0,0 +1,160 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/order.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/order.go -destination=./internal/repository/mocks/order_mock.go
+//
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderRepository is a mock of OrderRepository interface.
+type MockOrderRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderRepositoryMockRecorder
+}
+
+// MockOrderRepositoryMockRecorder is the mock recorder for MockOrderRepository.
+type MockOrderRepositoryMockRecorder struct {
+	mock *MockOrderRepository
+}
+
+// NewMockOrderRepository creates a new mock instance.
+func NewMockOrderRepository(ctrl *gomock.Controller) *MockOrderRepository {
+	mock := &MockOrderRepository{ctrl: ctrl}
+	mock.recorder = &MockOrderRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOrderRepository) Create(ctx context.Context, o domain.Order) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, o)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOrderRepositoryMockRecorder) Create(ctx, o any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderRepository)(nil).Create), ctx, o)
+}
+
+// FindInit mocks base method.
+func (m *MockOrderRepository) FindInit(ctx context.Context, uid, aid int64) (domain.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindInit", ctx, uid, aid)
+	ret0, _ := ret[0].(domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindInit indicates an expected call of FindInit.
+func (mr *MockOrderRepositoryMockRecorder) FindInit(ctx, uid, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindInit", reflect.TypeOf((*MockOrderRepository)(nil).FindInit), ctx, uid, aid)
+}
+
+// FindInitBefore mocks base method.
+func (m *MockOrderRepository) FindInitBefore(ctx context.Context, t time.Time, minId int64, limit int) ([]domain.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindInitBefore", ctx, t, minId, limit)
+	ret0, _ := ret[0].([]domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindInitBefore indicates an expected call of FindInitBefore.
+func (mr *MockOrderRepositoryMockRecorder) FindInitBefore(ctx, t, minId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindInitBefore", reflect.TypeOf((*MockOrderRepository)(nil).FindInitBefore), ctx, t, minId, limit)
+}
+
+// GetBySN mocks base method.
+func (m *MockOrderRepository) GetBySN(ctx context.Context, sn string) (domain.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBySN", ctx, sn)
+	ret0, _ := ret[0].(domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBySN indicates an expected call of GetBySN.
+func (mr *MockOrderRepositoryMockRecorder) GetBySN(ctx, sn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBySN", reflect.TypeOf((*MockOrderRepository)(nil).GetBySN), ctx, sn)
+}
+
+// HasPaid mocks base method.
+func (m *MockOrderRepository) HasPaid(ctx context.Context, uid, aid int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPaid", ctx, uid, aid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasPaid indicates an expected call of HasPaid.
+func (mr *MockOrderRepositoryMockRecorder) HasPaid(ctx, uid, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPaid", reflect.TypeOf((*MockOrderRepository)(nil).HasPaid), ctx, uid, aid)
+}
+
+// ListByUser mocks base method.
+func (m *MockOrderRepository) ListByUser(ctx context.Context, uid int64, offset, limit int) ([]domain.Order, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]domain.Order)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockOrderRepositoryMockRecorder) ListByUser(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockOrderRepository)(nil).ListByUser), ctx, uid, offset, limit)
+}
+
+// SetPayURL mocks base method.
+func (m *MockOrderRepository) SetPayURL(ctx context.Context, id int64, payURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPayURL", ctx, id, payURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPayURL indicates an expected call of SetPayURL.
+func (mr *MockOrderRepositoryMockRecorder) SetPayURL(ctx, id, payURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPayURL", reflect.TypeOf((*MockOrderRepository)(nil).SetPayURL), ctx, id, payURL)
+}
+
+// TransitStatus mocks base method.
+func (m *MockOrderRepository) TransitStatus(ctx context.Context, sn string, from, to domain.OrderStatus, txnId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitStatus", ctx, sn, from, to, txnId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransitStatus indicates an expected call of TransitStatus.
+func (mr *MockOrderRepositoryMockRecorder) TransitStatus(ctx, sn, from, to, txnId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitStatus", reflect.TypeOf((*MockOrderRepository)(nil).TransitStatus), ctx, sn, from, to, txnId)
+}
@@ -0,0 +1,69 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/related.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/related.go -destination=./internal/repository/mocks/related_mock.go
+//
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRelatedArticleRepository is a mock of RelatedArticleRepository interface.
+type MockRelatedArticleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRelatedArticleRepositoryMockRecorder
+}
+
+// MockRelatedArticleRepositoryMockRecorder is the mock recorder for MockRelatedArticleRepository.
+type MockRelatedArticleRepositoryMockRecorder struct {
+	mock *MockRelatedArticleRepository
+}
+
+// NewMockRelatedArticleRepository creates a new mock instance.
+func NewMockRelatedArticleRepository(ctrl *gomock.Controller) *MockRelatedArticleRepository {
+	mock := &MockRelatedArticleRepository{ctrl: ctrl}
+	mock.recorder = &MockRelatedArticleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRelatedArticleRepository) EXPECT() *MockRelatedArticleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetRelated mocks base method.
+func (m *MockRelatedArticleRepository) GetRelated(ctx context.Context, aid int64) ([]domain.RelatedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRelated", ctx, aid)
+	ret0, _ := ret[0].([]domain.RelatedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRelated indicates an expected call of GetRelated.
+func (mr *MockRelatedArticleRepositoryMockRecorder) GetRelated(ctx, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRelated", reflect.TypeOf((*MockRelatedArticleRepository)(nil).GetRelated), ctx, aid)
+}
+
+// ReplaceRelated mocks base method.
+func (m *MockRelatedArticleRepository) ReplaceRelated(ctx context.Context, aid int64, rels []domain.RelatedArticle) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRelated", ctx, aid, rels)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRelated indicates an expected call of ReplaceRelated.
+func (mr *MockRelatedArticleRepositoryMockRecorder) ReplaceRelated(ctx, aid, rels any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRelated", reflect.TypeOf((*MockRelatedArticleRepository)(nil).ReplaceRelated), ctx, aid, rels)
+}
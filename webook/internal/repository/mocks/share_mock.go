@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/share.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/share.go -destination=./internal/repository/mocks/share_mock.go
+//
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleShareRepository is a mock of ArticleShareRepository interface.
+type MockArticleShareRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleShareRepositoryMockRecorder
+}
+
+// MockArticleShareRepositoryMockRecorder is the mock recorder for MockArticleShareRepository.
+type MockArticleShareRepositoryMockRecorder struct {
+	mock *MockArticleShareRepository
+}
+
+// NewMockArticleShareRepository creates a new mock instance.
+func NewMockArticleShareRepository(ctrl *gomock.Controller) *MockArticleShareRepository {
+	mock := &MockArticleShareRepository{ctrl: ctrl}
+	mock.recorder = &MockArticleShareRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleShareRepository) EXPECT() *MockArticleShareRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddAudit mocks base method.
+func (m *MockArticleShareRepository) AddAudit(ctx context.Context, a domain.ShareAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAudit", ctx, a)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAudit indicates an expected call of AddAudit.
+func (mr *MockArticleShareRepositoryMockRecorder) AddAudit(ctx, a any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAudit", reflect.TypeOf((*MockArticleShareRepository)(nil).AddAudit), ctx, a)
+}
+
+// Create mocks base method.
+func (m *MockArticleShareRepository) Create(ctx context.Context, s domain.ArticleShare) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, s)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockArticleShareRepositoryMockRecorder) Create(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockArticleShareRepository)(nil).Create), ctx, s)
+}
+
+// GetById mocks base method.
+func (m *MockArticleShareRepository) GetById(ctx context.Context, id int64) (domain.ArticleShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(domain.ArticleShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockArticleShareRepositoryMockRecorder) GetById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockArticleShareRepository)(nil).GetById), ctx, id)
+}
+
+// ListAudits mocks base method.
+func (m *MockArticleShareRepository) ListAudits(ctx context.Context, shareId int64, offset, limit int) ([]domain.ShareAudit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAudits", ctx, shareId, offset, limit)
+	ret0, _ := ret[0].([]domain.ShareAudit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAudits indicates an expected call of ListAudits.
+func (mr *MockArticleShareRepositoryMockRecorder) ListAudits(ctx, shareId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAudits", reflect.TypeOf((*MockArticleShareRepository)(nil).ListAudits), ctx, shareId, offset, limit)
+}
+
+// ListByArticle mocks base method.
+func (m *MockArticleShareRepository) ListByArticle(ctx context.Context, uid, aid int64) ([]domain.ArticleShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByArticle", ctx, uid, aid)
+	ret0, _ := ret[0].([]domain.ArticleShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByArticle indicates an expected call of ListByArticle.
+func (mr *MockArticleShareRepositoryMockRecorder) ListByArticle(ctx, uid, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByArticle", reflect.TypeOf((*MockArticleShareRepository)(nil).ListByArticle), ctx, uid, aid)
+}
+
+// Revoke mocks base method.
+func (m *MockArticleShareRepository) Revoke(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockArticleShareRepositoryMockRecorder) Revoke(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockArticleShareRepository)(nil).Revoke), ctx, uid, id)
+}
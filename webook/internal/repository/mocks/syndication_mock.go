@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/syndication.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/syndication.go -destination=./internal/repository/mocks/syndication_mock.go
+//
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSyndicationRepository is a mock of SyndicationRepository interface.
+type MockSyndicationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSyndicationRepositoryMockRecorder
+}
+
+// MockSyndicationRepositoryMockRecorder is the mock recorder for MockSyndicationRepository.
+type MockSyndicationRepositoryMockRecorder struct {
+	mock *MockSyndicationRepository
+}
+
+// NewMockSyndicationRepository creates a new mock instance.
+func NewMockSyndicationRepository(ctrl *gomock.Controller) *MockSyndicationRepository {
+	mock := &MockSyndicationRepository{ctrl: ctrl}
+	mock.recorder = &MockSyndicationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSyndicationRepository) EXPECT() *MockSyndicationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DelAuthor mocks base method.
+func (m *MockSyndicationRepository) DelAuthor(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelAuthor", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelAuthor indicates an expected call of DelAuthor.
+func (mr *MockSyndicationRepositoryMockRecorder) DelAuthor(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelAuthor", reflect.TypeOf((*MockSyndicationRepository)(nil).DelAuthor), ctx, uid)
+}
+
+// GetAuthor mocks base method.
+func (m *MockSyndicationRepository) GetAuthor(ctx context.Context, uid int64, format domain.SyndicationFormat) (domain.SyndicationDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthor", ctx, uid, format)
+	ret0, _ := ret[0].(domain.SyndicationDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthor indicates an expected call of GetAuthor.
+func (mr *MockSyndicationRepositoryMockRecorder) GetAuthor(ctx, uid, format any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthor", reflect.TypeOf((*MockSyndicationRepository)(nil).GetAuthor), ctx, uid, format)
+}
+
+// GetHot mocks base method.
+func (m *MockSyndicationRepository) GetHot(ctx context.Context, format domain.SyndicationFormat) (domain.SyndicationDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHot", ctx, format)
+	ret0, _ := ret[0].(domain.SyndicationDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHot indicates an expected call of GetHot.
+func (mr *MockSyndicationRepositoryMockRecorder) GetHot(ctx, format any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHot", reflect.TypeOf((*MockSyndicationRepository)(nil).GetHot), ctx, format)
+}
+
+// SetAuthor mocks base method.
+func (m *MockSyndicationRepository) SetAuthor(ctx context.Context, uid int64, format domain.SyndicationFormat, doc domain.SyndicationDocument) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAuthor", ctx, uid, format, doc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAuthor indicates an expected call of SetAuthor.
+func (mr *MockSyndicationRepositoryMockRecorder) SetAuthor(ctx, uid, format, doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAuthor", reflect.TypeOf((*MockSyndicationRepository)(nil).SetAuthor), ctx, uid, format, doc)
+}
+
+// SetHot mocks base method.
+func (m *MockSyndicationRepository) SetHot(ctx context.Context, format domain.SyndicationFormat, doc domain.SyndicationDocument) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHot", ctx, format, doc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHot indicates an expected call of SetHot.
+func (mr *MockSyndicationRepositoryMockRecorder) SetHot(ctx, format, doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHot", reflect.TypeOf((*MockSyndicationRepository)(nil).SetHot), ctx, format, doc)
+}
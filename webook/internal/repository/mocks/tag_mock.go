@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/tag.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/tag.go -destination=./internal/repository/mocks/tag_mock.go
+//
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTagRepository is a mock of TagRepository interface.
+type MockTagRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagRepositoryMockRecorder
+}
+
+// MockTagRepositoryMockRecorder is the mock recorder for MockTagRepository.
+type MockTagRepositoryMockRecorder struct {
+	mock *MockTagRepository
+}
+
+// NewMockTagRepository creates a new mock instance.
+func NewMockTagRepository(ctrl *gomock.Controller) *MockTagRepository {
+	mock := &MockTagRepository{ctrl: ctrl}
+	mock.recorder = &MockTagRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTagRepository) EXPECT() *MockTagRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountPub mocks base method.
+func (m *MockTagRepository) CountPub(ctx context.Context, offset, limit int) ([]domain.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPub", ctx, offset, limit)
+	ret0, _ := ret[0].([]domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPub indicates an expected call of CountPub.
+func (mr *MockTagRepositoryMockRecorder) CountPub(ctx, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPub", reflect.TypeOf((*MockTagRepository)(nil).CountPub), ctx, offset, limit)
+}
+
+// GetArticleTags mocks base method.
+func (m *MockTagRepository) GetArticleTags(ctx context.Context, aid int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticleTags", ctx, aid)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArticleTags indicates an expected call of GetArticleTags.
+func (mr *MockTagRepositoryMockRecorder) GetArticleTags(ctx, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticleTags", reflect.TypeOf((*MockTagRepository)(nil).GetArticleTags), ctx, aid)
+}
+
+// ListPubByTag mocks base method.
+func (m *MockTagRepository) ListPubByTag(ctx context.Context, tag string, start time.Time, offset, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByTag", ctx, tag, start, offset, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByTag indicates an expected call of ListPubByTag.
+func (mr *MockTagRepositoryMockRecorder) ListPubByTag(ctx, tag, start, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByTag", reflect.TypeOf((*MockTagRepository)(nil).ListPubByTag), ctx, tag, start, offset, limit)
+}
+
+// SetArticleTags mocks base method.
+func (m *MockTagRepository) SetArticleTags(ctx context.Context, aid int64, tags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArticleTags", ctx, aid, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetArticleTags indicates an expected call of SetArticleTags.
+func (mr *MockTagRepositoryMockRecorder) SetArticleTags(ctx, aid, tags any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArticleTags", reflect.TypeOf((*MockTagRepository)(nil).SetArticleTags), ctx, aid, tags)
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"time"
+)
+
+var (
+	ErrOrderNotFound       = dao.ErrRecordNotFound
+	ErrOrderStatusMismatch = dao.ErrOrderStatusMismatch
+)
+
+type OrderRepository interface {
+	Create(ctx context.Context, o domain.Order) (int64, error)
+	SetPayURL(ctx context.Context, id int64, payURL string) error
+	// GetBySN 不存在的时候返回 ErrOrderNotFound
+	GetBySN(ctx context.Context, sn string) (domain.Order, error)
+	// FindInit 还没有支付结果的订单，没有的时候返回 ErrOrderNotFound
+	FindInit(ctx context.Context, uid, aid int64) (domain.Order, error)
+	HasPaid(ctx context.Context, uid, aid int64) (bool, error)
+	ListByUser(ctx context.Context, uid int64, offset, limit int) ([]domain.Order, int64, error)
+	// FindInitBefore 在 t 之前就下单了，还没有支付结果的订单，按照 ID 从小到大
+	FindInitBefore(ctx context.Context, t time.Time, minId int64, limit int) ([]domain.Order, error)
+	// TransitStatus 订单不是 from 状态的时候返回 ErrOrderStatusMismatch
+	TransitStatus(ctx context.Context, sn string, from, to domain.OrderStatus, txnId string) error
+}
+
+type orderRepository struct {
+	dao dao.OrderDAO
+}
+
+func NewOrderRepository(dao dao.OrderDAO) OrderRepository {
+	return &orderRepository{dao: dao}
+}
+
+func (r *orderRepository) Create(ctx context.Context, o domain.Order) (int64, error) {
+	return r.dao.Insert(ctx, dao.Order{
+		SN:     o.SN,
+		Uid:    o.Uid,
+		Aid:    o.Aid,
+		Amount: o.Amount,
+		Status: o.Status.ToUint8(),
+	})
+}
+
+func (r *orderRepository) SetPayURL(ctx context.Context, id int64, payURL string) error {
+	return r.dao.SetPayURL(ctx, id, payURL)
+}
+
+func (r *orderRepository) GetBySN(ctx context.Context, sn string) (domain.Order, error) {
+	o, err := r.dao.FindBySN(ctx, sn)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return r.toDomain(o), nil
+}
+
+func (r *orderRepository) FindInit(ctx context.Context, uid, aid int64) (domain.Order, error) {
+	o, err := r.dao.FindInit(ctx, uid, aid)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return r.toDomain(o), nil
+}
+
+func (r *orderRepository) HasPaid(ctx context.Context, uid, aid int64) (bool, error) {
+	return r.dao.HasPaid(ctx, uid, aid)
+}
+
+func (r *orderRepository) ListByUser(ctx context.Context, uid int64, offset, limit int) ([]domain.Order, int64, error) {
+	os, count, err := r.dao.FindByUid(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.toDomains(os), count, nil
+}
+
+func (r *orderRepository) FindInitBefore(ctx context.Context, t time.Time, minId int64, limit int) ([]domain.Order, error) {
+	os, err := r.dao.FindInitBefore(ctx, t.UnixMilli(), minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(os), nil
+}
+
+func (r *orderRepository) TransitStatus(ctx context.Context, sn string, from, to domain.OrderStatus, txnId string) error {
+	return r.dao.TransitStatus(ctx, sn, from.ToUint8(), to.ToUint8(), txnId)
+}
+
+func (r *orderRepository) toDomains(os []dao.Order) []domain.Order {
+	return slice.Map(os, func(idx int, src dao.Order) domain.Order {
+		return r.toDomain(src)
+	})
+}
+
+func (r *orderRepository) toDomain(o dao.Order) domain.Order {
+	return domain.Order{
+		Id:     o.Id,
+		SN:     o.SN,
+		Uid:    o.Uid,
+		Aid:    o.Aid,
+		Amount: o.Amount,
+		Status: domain.OrderStatus(o.Status),
+		TxnId:  o.TxnId,
+		PayURL: o.PayURL,
+		Ctime:  time.UnixMilli(o.Ctime),
+		Utime:  time.UnixMilli(o.Utime),
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"github.com/jayleonc/geektime-go/webook/internal/domain"
 	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
 )
@@ -9,6 +10,9 @@ import (
 type RankingRepository interface {
 	ReplaceTopN(ctx context.Context, articles []domain.Article) error
 	GetTopN(ctx context.Context) ([]domain.Article, error)
+	ReplaceTopNByTag(ctx context.Context, tag string, articles []domain.Article) error
+	// GetTopNByTag 只有热门标签才有热榜，别的标签返回空列表
+	GetTopNByTag(ctx context.Context, tag string) ([]domain.Article, error)
 }
 
 type CachedRankingRepository struct {
@@ -51,3 +55,15 @@ func (c *CachedRankingRepository) ReplaceTopNV1(ctx context.Context, arts []doma
 func (c *CachedRankingRepository) ReplaceTopN(ctx context.Context, arts []domain.Article) error {
 	return c.cache.Set(ctx, arts)
 }
+
+func (c *CachedRankingRepository) ReplaceTopNByTag(ctx context.Context, tag string, arts []domain.Article) error {
+	return c.cache.SetByTag(ctx, tag, arts)
+}
+
+func (c *CachedRankingRepository) GetTopNByTag(ctx context.Context, tag string) ([]domain.Article, error) {
+	arts, err := c.cache.GetByTag(ctx, tag)
+	if errors.Is(err, cache.ErrKeyNotExist) {
+		return nil, nil
+	}
+	return arts, err
+}
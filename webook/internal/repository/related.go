@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+)
+
+// RelatedArticleRepository 结果以数据库为准，缓存没有的时候回查数据库
+type RelatedArticleRepository interface {
+	GetRelated(ctx context.Context, aid int64) ([]domain.RelatedArticle, error)
+	ReplaceRelated(ctx context.Context, aid int64, rels []domain.RelatedArticle) error
+}
+
+type CachedRelatedArticleRepository struct {
+	dao   dao.RelatedArticleDAO
+	cache cache.RelatedArticleCache
+	l     logger.Logger
+}
+
+func NewCachedRelatedArticleRepository(dao dao.RelatedArticleDAO, cache cache.RelatedArticleCache,
+	l logger.Logger) RelatedArticleRepository {
+	return &CachedRelatedArticleRepository{
+		dao:   dao,
+		cache: cache,
+		l:     l,
+	}
+}
+
+func (r *CachedRelatedArticleRepository) GetRelated(ctx context.Context, aid int64) ([]domain.RelatedArticle, error) {
+	res, err := r.cache.Get(ctx, aid)
+	if err == nil {
+		return res, nil
+	}
+	rels, err := r.dao.FindByAid(ctx, aid)
+	if err != nil {
+		return nil, err
+	}
+	res = slice.Map(rels, func(idx int, src dao.RelatedArticle) domain.RelatedArticle {
+		return domain.RelatedArticle{Id: src.RelatedId, Score: src.Score}
+	})
+	if er := r.cache.Set(ctx, aid, res); er != nil {
+		r.l.Error("缓存相关文章失败", logger.Int64("aid", aid), logger.Error(er))
+	}
+	return res, nil
+}
+
+func (r *CachedRelatedArticleRepository) ReplaceRelated(ctx context.Context, aid int64, rels []domain.RelatedArticle) error {
+	err := r.dao.Replace(ctx, aid, slice.Map(rels, func(idx int, src domain.RelatedArticle) dao.RelatedArticle {
+		return dao.RelatedArticle{RelatedId: src.Id, Score: src.Score}
+	}))
+	if err != nil {
+		return err
+	}
+	// 覆盖失败的话旧结果最多留到过期
+	if er := r.cache.Set(ctx, aid, rels); er != nil {
+		r.l.Error("缓存相关文章失败", logger.Int64("aid", aid), logger.Error(er))
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"time"
+)
+
+var (
+	// ErrSeriesNotFound 专栏不存在，或者文章不在任何专栏里面
+	ErrSeriesNotFound       = dao.ErrRecordNotFound
+	ErrArticleInOtherSeries = dao.ErrArticleInOtherSeries
+)
+
+type SeriesRepository interface {
+	Create(ctx context.Context, s domain.Series) (int64, error)
+	// Update 不是这个作者的专栏返回 ErrSeriesNotFound
+	Update(ctx context.Context, s domain.Series) error
+	Delete(ctx context.Context, uid, id int64) error
+	// GetById 带上专栏里面的文章顺序
+	GetById(ctx context.Context, id int64) (domain.Series, error)
+	// GetByArticle 文章所在的专栏，不在任何专栏里面的时候返回 ErrSeriesNotFound
+	GetByArticle(ctx context.Context, aid int64) (domain.Series, error)
+	// ListByAuthor 不带文章
+	ListByAuthor(ctx context.Context, uid int64, offset, limit int) ([]domain.Series, int64, error)
+	SetArticles(ctx context.Context, uid, sid int64, aids []int64) error
+}
+
+type CachedSeriesRepository struct {
+	dao   dao.SeriesDAO
+	cache cache.SeriesCache
+	l     logger.Logger
+}
+
+func NewCachedSeriesRepository(dao dao.SeriesDAO, cache cache.SeriesCache, l logger.Logger) SeriesRepository {
+	return &CachedSeriesRepository{
+		dao:   dao,
+		cache: cache,
+		l:     l,
+	}
+}
+
+func (r *CachedSeriesRepository) Create(ctx context.Context, s domain.Series) (int64, error) {
+	return r.dao.Insert(ctx, r.toEntity(s))
+}
+
+func (r *CachedSeriesRepository) Update(ctx context.Context, s domain.Series) error {
+	if err := r.dao.Update(ctx, r.toEntity(s)); err != nil {
+		return err
+	}
+	r.delCache(ctx, s.Id)
+	return nil
+}
+
+func (r *CachedSeriesRepository) Delete(ctx context.Context, uid, id int64) error {
+	arts, err := r.dao.FindArticles(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err = r.dao.Delete(ctx, uid, id); err != nil {
+		return err
+	}
+	r.delCache(ctx, id, r.aids(arts)...)
+	return nil
+}
+
+func (r *CachedSeriesRepository) GetById(ctx context.Context, id int64) (domain.Series, error) {
+	res, err := r.cache.Get(ctx, id)
+	if err == nil {
+		return res, nil
+	}
+	s, err := r.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	arts, err := r.dao.FindArticles(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	res = r.toDomain(s)
+	res.Aids = r.aids(arts)
+	if er := r.cache.Set(ctx, res); er != nil {
+		r.l.Error("缓存专栏失败", logger.Int64("sid", id), logger.Error(er))
+	}
+	return res, nil
+}
+
+func (r *CachedSeriesRepository) GetByArticle(ctx context.Context, aid int64) (domain.Series, error) {
+	sid, err := r.cache.GetSid(ctx, aid)
+	if err != nil {
+		sid, err = r.findSid(ctx, aid)
+		if err != nil {
+			return domain.Series{}, err
+		}
+	}
+	if sid == 0 {
+		return domain.Series{}, ErrSeriesNotFound
+	}
+	return r.GetById(ctx, sid)
+}
+
+// findSid 大部分文章不在专栏里面，这种情况也要缓存下来，用 0 表示
+func (r *CachedSeriesRepository) findSid(ctx context.Context, aid int64) (int64, error) {
+	sa, err := r.dao.FindByAid(ctx, aid)
+	if err != nil && !errors.Is(err, dao.ErrRecordNotFound) {
+		return 0, err
+	}
+	if er := r.cache.SetSid(ctx, aid, sa.SeriesId); er != nil {
+		r.l.Error("缓存文章所在的专栏失败", logger.Int64("aid", aid), logger.Error(er))
+	}
+	return sa.SeriesId, nil
+}
+
+func (r *CachedSeriesRepository) ListByAuthor(ctx context.Context, uid int64, offset, limit int) ([]domain.Series, int64, error) {
+	ss, count, err := r.dao.FindByUid(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return slice.Map(ss, func(idx int, src dao.Series) domain.Series {
+		return r.toDomain(src)
+	}), count, nil
+}
+
+func (r *CachedSeriesRepository) SetArticles(ctx context.Context, uid, sid int64, aids []int64) error {
+	old, err := r.dao.FindArticles(ctx, sid)
+	if err != nil {
+		return err
+	}
+	if err = r.dao.SetArticles(ctx, uid, sid, aids); err != nil {
+		return err
+	}
+	// 移出去的文章和新加进来的文章，所在的专栏都变了
+	r.delCache(ctx, sid, append(r.aids(old), aids...)...)
+	return nil
+}
+
+func (r *CachedSeriesRepository) delCache(ctx context.Context, sid int64, aids ...int64) {
+	if err := r.cache.Del(ctx, sid); err != nil {
+		r.l.Error("删除专栏缓存失败", logger.Int64("sid", sid), logger.Error(err))
+	}
+	if err := r.cache.DelSid(ctx, aids...); err != nil {
+		r.l.Error("删除文章所在专栏的缓存失败", logger.Int64("sid", sid), logger.Error(err))
+	}
+}
+
+func (r *CachedSeriesRepository) aids(arts []dao.SeriesArticle) []int64 {
+	return slice.Map(arts, func(idx int, src dao.SeriesArticle) int64 {
+		return src.Aid
+	})
+}
+
+func (r *CachedSeriesRepository) toEntity(s domain.Series) dao.Series {
+	return dao.Series{
+		Id:          s.Id,
+		Uid:         s.Author.Id,
+		Title:       s.Title,
+		Description: s.Description,
+	}
+}
+
+func (r *CachedSeriesRepository) toDomain(s dao.Series) domain.Series {
+	return domain.Series{
+		Id:          s.Id,
+		Author:      domain.Author{Id: s.Uid},
+		Title:       s.Title,
+		Description: s.Description,
+		Ctime:       time.UnixMilli(s.Ctime),
+		Utime:       time.UnixMilli(s.Utime),
+	}
+}
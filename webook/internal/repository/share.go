@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"time"
+)
+
+var ErrArticleShareNotFound = dao.ErrRecordNotFound
+
+type ArticleShareRepository interface {
+	Create(ctx context.Context, s domain.ArticleShare) (int64, error)
+	// GetById 不存在的时候返回 ErrArticleShareNotFound
+	GetById(ctx context.Context, id int64) (domain.ArticleShare, error)
+	ListByArticle(ctx context.Context, uid, aid int64) ([]domain.ArticleShare, error)
+	// Revoke 不是这个作者的分享链接返回 ErrArticleShareNotFound
+	Revoke(ctx context.Context, uid, id int64) error
+	AddAudit(ctx context.Context, a domain.ShareAudit) error
+	ListAudits(ctx context.Context, shareId int64, offset, limit int) ([]domain.ShareAudit, int64, error)
+}
+
+type articleShareRepository struct {
+	dao dao.ArticleShareDAO
+}
+
+func NewArticleShareRepository(dao dao.ArticleShareDAO) ArticleShareRepository {
+	return &articleShareRepository{dao: dao}
+}
+
+func (r *articleShareRepository) Create(ctx context.Context, s domain.ArticleShare) (int64, error) {
+	return r.dao.Insert(ctx, dao.ArticleShare{
+		Aid:     s.Aid,
+		Uid:     s.Uid,
+		Expire:  s.Expire.UnixMilli(),
+		Revoked: s.Revoked,
+	})
+}
+
+func (r *articleShareRepository) GetById(ctx context.Context, id int64) (domain.ArticleShare, error) {
+	s, err := r.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.ArticleShare{}, err
+	}
+	return r.toDomain(s), nil
+}
+
+func (r *articleShareRepository) ListByArticle(ctx context.Context, uid, aid int64) ([]domain.ArticleShare, error) {
+	ss, err := r.dao.FindByAid(ctx, uid, aid)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(ss, func(idx int, src dao.ArticleShare) domain.ArticleShare {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *articleShareRepository) Revoke(ctx context.Context, uid, id int64) error {
+	return r.dao.Revoke(ctx, uid, id)
+}
+
+func (r *articleShareRepository) AddAudit(ctx context.Context, a domain.ShareAudit) error {
+	return r.dao.InsertAudit(ctx, dao.ArticleShareAudit{
+		ShareId:   a.ShareId,
+		Aid:       a.Aid,
+		Uid:       a.Uid,
+		Action:    a.Action.ToUint8(),
+		IP:        a.IP,
+		UserAgent: a.UserAgent,
+	})
+}
+
+func (r *articleShareRepository) ListAudits(ctx context.Context, shareId int64, offset, limit int) ([]domain.ShareAudit, int64, error) {
+	as, count, err := r.dao.FindAudits(ctx, shareId, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return slice.Map(as, func(idx int, src dao.ArticleShareAudit) domain.ShareAudit {
+		return domain.ShareAudit{
+			Id:        src.Id,
+			ShareId:   src.ShareId,
+			Aid:       src.Aid,
+			Uid:       src.Uid,
+			Action:    domain.ShareAuditAction(src.Action),
+			IP:        src.IP,
+			UserAgent: src.UserAgent,
+			Ctime:     time.UnixMilli(src.Ctime),
+		}
+	}), count, nil
+}
+
+func (r *articleShareRepository) toDomain(s dao.ArticleShare) domain.ArticleShare {
+	return domain.ArticleShare{
+		Id:      s.Id,
+		Aid:     s.Aid,
+		Uid:     s.Uid,
+		Expire:  time.UnixMilli(s.Expire),
+		Revoked: s.Revoked,
+		Ctime:   time.UnixMilli(s.Ctime),
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
+)
+
+// SyndicationRepository 订阅源只是缓存，丢了可以重新生成
+type SyndicationRepository interface {
+	GetAuthor(ctx context.Context, uid int64, format domain.SyndicationFormat) (domain.SyndicationDocument, error)
+	SetAuthor(ctx context.Context, uid int64, format domain.SyndicationFormat, doc domain.SyndicationDocument) error
+	DelAuthor(ctx context.Context, uid int64) error
+	GetHot(ctx context.Context, format domain.SyndicationFormat) (domain.SyndicationDocument, error)
+	SetHot(ctx context.Context, format domain.SyndicationFormat, doc domain.SyndicationDocument) error
+}
+
+type syndicationRepository struct {
+	cache cache.SyndicationCache
+}
+
+func NewSyndicationRepository(cache cache.SyndicationCache) SyndicationRepository {
+	return &syndicationRepository{
+		cache: cache,
+	}
+}
+
+func (s *syndicationRepository) GetAuthor(ctx context.Context, uid int64, format domain.SyndicationFormat) (domain.SyndicationDocument, error) {
+	return s.cache.GetAuthor(ctx, uid, format)
+}
+
+func (s *syndicationRepository) SetAuthor(ctx context.Context, uid int64, format domain.SyndicationFormat, doc domain.SyndicationDocument) error {
+	return s.cache.SetAuthor(ctx, uid, format, doc)
+}
+
+func (s *syndicationRepository) DelAuthor(ctx context.Context, uid int64) error {
+	return s.cache.DelAuthor(ctx, uid)
+}
+
+func (s *syndicationRepository) GetHot(ctx context.Context, format domain.SyndicationFormat) (domain.SyndicationDocument, error) {
+	return s.cache.GetHot(ctx, format)
+}
+
+func (s *syndicationRepository) SetHot(ctx context.Context, format domain.SyndicationFormat, doc domain.SyndicationDocument) error {
+	return s.cache.SetHot(ctx, format, doc)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"time"
+)
+
+type TagRepository interface {
+	SetArticleTags(ctx context.Context, aid int64, tags []string) error
+	GetArticleTags(ctx context.Context, aid int64) ([]string, error)
+	ListPubByTag(ctx context.Context, tag string, start time.Time, offset int, limit int) ([]domain.Article, error)
+	CountPub(ctx context.Context, offset int, limit int) ([]domain.Tag, error)
+}
+
+type tagRepository struct {
+	dao dao.TagDAO
+}
+
+func NewTagRepository(dao dao.TagDAO) TagRepository {
+	return &tagRepository{
+		dao: dao,
+	}
+}
+
+func (t *tagRepository) SetArticleTags(ctx context.Context, aid int64, tags []string) error {
+	return t.dao.SetArticleTags(ctx, aid, tags)
+}
+
+func (t *tagRepository) GetArticleTags(ctx context.Context, aid int64) ([]string, error) {
+	return t.dao.GetArticleTags(ctx, aid)
+}
+
+func (t *tagRepository) ListPubByTag(ctx context.Context, tag string, start time.Time, offset int, limit int) ([]domain.Article, error) {
+	arts, err := t.dao.ListPubByTag(ctx, tag, start, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.PublishedArticle, domain.Article](arts,
+		func(idx int, src dao.PublishedArticle) domain.Article {
+			return domain.Article{
+				Id:      src.Id,
+				Title:   src.Title,
+				Content: src.Content,
+				Author: domain.Author{
+					Id: src.AuthorId,
+				},
+				Status: domain.ArticleStatus(src.Status),
+				Ctime:  time.UnixMilli(src.Ctime),
+				Utime:  time.UnixMilli(src.Utime),
+			}
+		}), nil
+}
+
+func (t *tagRepository) CountPub(ctx context.Context, offset int, limit int) ([]domain.Tag, error) {
+	cnts, err := t.dao.CountPub(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.TagCount, domain.Tag](cnts, func(idx int, src dao.TagCount) domain.Tag {
+		return domain.Tag{
+			Name:       src.Name,
+			ArticleCnt: src.Cnt,
+		}
+	}), nil
+}
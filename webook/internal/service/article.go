@@ -2,28 +2,396 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jayleonc/geektime-go/webook/internal/domain"
 	events "github.com/jayleonc/geektime-go/webook/internal/events/article"
 	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/diffx"
 	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/sensitive"
+	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrArticleRevisionNotFound = errors.New("历史版本不存在")
+	ErrInvalidPublishTime      = errors.New("定时发表的时间必须晚于当前时间")
+	ErrArticleNotScheduled     = repository.ErrArticleNotScheduled
+	ErrInvalidTags             = errors.New("标签不合法")
+	// ErrArticleVersionConflict 保存的时候带上来的版本号不是最新的
+	ErrArticleVersionConflict = repository.ErrArticleVersionConflict
+	// ErrArticlePendingReview 文章命中了敏感词，已经保存下来等待人工审核，这时候返回的 id 是有效的
+	ErrArticlePendingReview    = errors.New("文章需要人工审核")
+	ErrArticleReviewNotPending = repository.ErrArticleReviewNotPending
+	// ErrArticleReviewOutdated 提交审核之后作者又修改了文章
+	ErrArticleReviewOutdated = errors.New("文章在审核期间被修改过了")
+	ErrArticleNotFound       = repository.ErrArticleNotFound
+	// ErrDeletedArticleNotFound 回收站里面没有，可能已经被彻底删除了
+	ErrDeletedArticleNotFound = repository.ErrDeletedArticleNotFound
+)
+
+const (
+	// maxTagCnt 一篇文章最多的标签数
+	maxTagCnt = 10
+	// maxTagLen 单个标签最长的字符数
+	maxTagLen = 32
+	// articleBiz Publish 没有 biz 参数，提交审核的时候保存草稿用这个
+	articleBiz = "article"
 )
 
 type ArticleService interface {
-	Save(ctx context.Context, biz string, article domain.Article) (int64, error)
-	Publish(ctx context.Context, article domain.Article) (int64, error)
+	// Save 保存草稿。article.Version 大于 0 的时候会校验版本，
+	// 草稿已经被改过了就返回 ErrArticleVersionConflict。
+	// 返回保存之后的文章，Version 是新的版本号，下一次保存的时候带上来
+	Save(ctx context.Context, biz string, article domain.Article) (domain.Article, error)
+	// Publish 发表文章，版本的校验和 Save 一样。
+	// 发表之前会过一遍敏感词：需要打码的词打码之后照常发表；
+	// 需要人工审核的时候文章进入审核状态，返回 ErrArticlePendingReview
+	Publish(ctx context.Context, article domain.Article) (domain.Article, error)
 	GetByAuthor(ctx context.Context, uid int64, pageIndex, pageSize int, title string) ([]domain.Article, int64, error)
 	GetById(ctx context.Context, id int64) (domain.Article, error)
 	GetByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
-	GetPubById(ctx context.Context, id, uid int64) (domain.Article, error)
+	// GetPubById 只查文章，不记录阅读，确认读者能看之后再调 Read。
+	// 线上库里面没有的时候返回 ErrArticleNotFound
+	GetPubById(ctx context.Context, id int64) (domain.Article, error)
+	// Read 异步发送阅读事件
+	Read(ctx context.Context, id, uid int64)
 	ListPub(ctx context.Context, start time.Time, offset, limit int) ([]domain.Article, error)
+	// ListPubByCursor 游标翻页，新的在前。cursor 用上一页最后一篇文章的 Utime 和 Id，零值表示第一页
+	ListPubByCursor(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
+
+	// ListRevisions 列出某篇文章的历史版本，不包含内容
+	ListRevisions(ctx context.Context, uid, aid int64, offset, limit int) ([]domain.ArticleRevision, int64, error)
+	// DiffRevisions 按行对比同一篇文章的两个历史版本
+	DiffRevisions(ctx context.Context, uid, aid, from, to int64) ([]diffx.Line, error)
+	// RestoreRevision 把某个历史版本恢复成当前的草稿。version 是前端最后一次拿到的草稿版本号，
+	// 和 Save 一样校验，草稿已经被改过了就返回 ErrArticleVersionConflict
+	RestoreRevision(ctx context.Context, biz string, uid, aid, revId, version int64) (domain.Article, error)
+
+	// Schedule 保存文章，并且在 article.PublishAt 的时候自动发表
+	Schedule(ctx context.Context, biz string, article domain.Article) (domain.Article, error)
+	ListScheduled(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, int64, error)
+	Reschedule(ctx context.Context, uid, id int64, publishAt time.Time) error
+	CancelSchedule(ctx context.Context, uid, id int64) error
+	// PublishDue 发表所有到点了的定时文章，由定时任务调用
+	PublishDue(ctx context.Context, now time.Time) error
+
+	// ListPubByTag 带标签过滤的 ListPub
+	ListPubByTag(ctx context.Context, tag string, start time.Time, offset, limit int) ([]domain.Article, error)
+	// ListTags 标签以及标签下已发表的文章数，文章多的在前
+	ListTags(ctx context.Context, offset, limit int) ([]domain.Tag, error)
+
+	// ListReviews 审核队列，先提交的在前。status 为 ReviewStatusUnknown 的时候不过滤
+	ListReviews(ctx context.Context, status domain.ReviewStatus, offset, limit int) ([]domain.ArticleReview, int64, error)
+	// ApproveReview 审核通过，发表提交审核时候的内容
+	ApproveReview(ctx context.Context, reviewerId, id int64) error
+	// RejectReview 驳回，文章退回到未发表的草稿
+	RejectReview(ctx context.Context, reviewerId, id int64, reason string) error
+
+	// Delete 删除文章，草稿和线上版本一起进回收站
+	Delete(ctx context.Context, uid, id int64) error
+	// ListDeleted 作者的回收站，最近删除的在前
+	ListDeleted(ctx context.Context, uid int64, offset, limit int) ([]domain.DeletedArticle, int64, error)
+	// Restore 从回收站恢复，恢复之后和删除之前一样
+	Restore(ctx context.Context, uid, id int64) error
+	// PurgeDeleted 彻底删除 before 之前进回收站的文章，由定时任务调用
+	PurgeDeleted(ctx context.Context, before time.Time) error
 }
 
 type articleService struct {
-	repo     repository.ArticleRepository
-	producer events.Producer
-	l        logger.Logger
+	repo       repository.ArticleRepository
+	revRepo    repository.ArticleRevisionRepository
+	tagRepo    repository.TagRepository
+	reviewRepo repository.ArticleReviewRepository
+	attachRepo repository.AttachmentRepository
+	filter     *sensitive.Filter
+	producer   events.Producer
+	l          logger.Logger
+}
+
+func (a *articleService) ListPubByTag(ctx context.Context, tag string, start time.Time, offset, limit int) ([]domain.Article, error) {
+	return a.tagRepo.ListPubByTag(ctx, strings.TrimSpace(tag), start, offset, limit)
+}
+
+func (a *articleService) ListTags(ctx context.Context, offset, limit int) ([]domain.Tag, error) {
+	return a.tagRepo.CountPub(ctx, offset, limit)
+}
+
+// normalizeTags 去掉首尾空格、空标签和重复的标签
+func (a *articleService) normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	res := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLen {
+			return nil, ErrInvalidTags
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		res = append(res, tag)
+	}
+	if len(res) > maxTagCnt {
+		return nil, ErrInvalidTags
+	}
+	return res, nil
+}
+
+// save 保存到制作库，然后更新标签，记录历史版本
+func (a *articleService) save(ctx context.Context, biz string, article domain.Article) (domain.Article, error) {
+	tags, err := a.normalizeTags(article.Tags)
+	if err != nil {
+		return article, err
+	}
+	version := nextVersion(article)
+	if article.Id > 0 {
+		err = a.repo.Update(ctx, biz, article)
+	} else {
+		article.Id, err = a.repo.Create(ctx, article)
+	}
+	if err != nil {
+		return article, err
+	}
+	article.Version = version
+	if tags != nil {
+		err = a.tagRepo.SetArticleTags(ctx, article.Id, tags)
+		if err != nil {
+			return article, err
+		}
+	}
+	err = a.syncAttachmentRefs(ctx, article, false)
+	if err != nil {
+		return article, err
+	}
+	a.appendRevision(ctx, article)
+	return article, nil
+}
+
+// nextVersion 保存成功之后草稿的版本号。新建的是 1；
+// 带了版本号的更新只有版本对得上才会成功，所以一定是 Version+1。
+// 不带版本号的更新不知道别人改过几次，返回 0
+func nextVersion(article domain.Article) int64 {
+	switch {
+	case article.Id == 0:
+		return 1
+	case article.Version > 0:
+		return article.Version + 1
+	default:
+		return 0
+	}
+}
+
+// syncAttachmentRefs 用文章现在用到的图片替换原来的引用，不再用到的图片过一段时间会被当成孤儿清理掉。
+// 草稿和线上版本分开记，只改草稿的时候线上版本用到的图片还在
+func (a *articleService) syncAttachmentRefs(ctx context.Context, article domain.Article, published bool) error {
+	hashes := domain.ExtractAttachmentHashes(article.Content)
+	err := a.attachRepo.SetDraftReferences(ctx, article.Id, hashes)
+	if err != nil || !published {
+		return err
+	}
+	return a.attachRepo.SetPubReferences(ctx, article.Id, hashes)
+}
+
+func (a *articleService) Schedule(ctx context.Context, biz string, article domain.Article) (domain.Article, error) {
+	if !article.PublishAt.After(time.Now()) {
+		return domain.Article{}, ErrInvalidPublishTime
+	}
+	article.Status = domain.ArticleStatusScheduled
+	return a.save(ctx, biz, article)
+}
+
+func (a *articleService) ListScheduled(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, int64, error) {
+	return a.repo.ListScheduled(ctx, uid, offset, limit)
+}
+
+func (a *articleService) Reschedule(ctx context.Context, uid, id int64, publishAt time.Time) error {
+	if !publishAt.After(time.Now()) {
+		return ErrInvalidPublishTime
+	}
+	return a.repo.Reschedule(ctx, uid, id, publishAt)
+}
+
+func (a *articleService) CancelSchedule(ctx context.Context, uid, id int64) error {
+	return a.repo.CancelSchedule(ctx, uid, id)
+}
+
+func (a *articleService) PublishDue(ctx context.Context, now time.Time) error {
+	const batchSize = 100
+	for {
+		arts, err := a.repo.FindDueScheduled(ctx, now, batchSize)
+		if err != nil {
+			return err
+		}
+		failed := false
+		for _, art := range arts {
+			// 发表成功之后状态就不再是定时发表了，所以下一轮不会再查出来
+			_, er := a.Publish(ctx, art)
+			if errors.Is(er, ErrArticlePendingReview) {
+				// 进入审核之后状态也不再是定时发表了
+				continue
+			}
+			if er != nil {
+				// 一篇失败了不影响别的，下一次调度的时候会再试
+				failed = true
+				a.l.Error("定时发表文章失败",
+					logger.Int64("aid", art.Id),
+					logger.Error(er))
+			}
+		}
+		// 有失败的时候直接结束，避免一直查出同一批失败的文章
+		if len(arts) < batchSize || failed {
+			return nil
+		}
+	}
+}
+
+func (a *articleService) Delete(ctx context.Context, uid, id int64) error {
+	err := a.repo.Delete(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	// 线上版本没有了，按照下线处理
+	a.producePublishEvent(domain.Article{
+		Id:     id,
+		Author: domain.Author{Id: uid},
+		Status: domain.ArticleStatusUnpublished,
+	})
+	return nil
+}
+
+func (a *articleService) ListDeleted(ctx context.Context, uid int64, offset, limit int) ([]domain.DeletedArticle, int64, error) {
+	return a.repo.ListDeleted(ctx, uid, offset, limit)
+}
+
+func (a *articleService) Restore(ctx context.Context, uid, id int64) error {
+	deleted, err := a.repo.Restore(ctx, uid, id)
+	if err != nil || !deleted.Published {
+		return err
+	}
+	// 线上版本回来了，重新通知一次
+	art, err := a.repo.GetPubById(ctx, id)
+	if err != nil {
+		a.l.Error("查询恢复的线上版本失败",
+			logger.Int64("aid", id),
+			logger.Error(err))
+		return nil
+	}
+	art.Tags = a.getTags(ctx, id)
+	a.producePublishEvent(art)
+	return nil
+}
+
+func (a *articleService) PurgeDeleted(ctx context.Context, before time.Time) error {
+	const batchSize = 100
+	for {
+		arts, err := a.repo.FindExpiredDeleted(ctx, before, batchSize)
+		if err != nil {
+			return err
+		}
+		failed := false
+		for _, art := range arts {
+			er := a.purge(ctx, art.Article.Id)
+			if er != nil {
+				failed = true
+				a.l.Error("彻底删除文章失败",
+					logger.Int64("aid", art.Article.Id),
+					logger.Error(er))
+			}
+		}
+		// 和 PublishDue 一样，有失败的时候等下一次调度
+		if len(arts) < batchSize || failed {
+			return nil
+		}
+	}
+}
+
+func (a *articleService) purge(ctx context.Context, id int64) error {
+	err := a.repo.Purge(ctx, id)
+	if err != nil {
+		return err
+	}
+	// 文章没了，标签关系和图片的引用也不用留着，图片由定时任务清理
+	err = a.tagRepo.SetArticleTags(ctx, id, []string{})
+	if err != nil {
+		return err
+	}
+	return a.attachRepo.DeleteReferences(ctx, id)
+}
+
+func (a *articleService) ListRevisions(ctx context.Context, uid, aid int64, offset, limit int) ([]domain.ArticleRevision, int64, error) {
+	return a.revRepo.List(ctx, uid, aid, offset, limit)
+}
+
+func (a *articleService) DiffRevisions(ctx context.Context, uid, aid, from, to int64) ([]diffx.Line, error) {
+	fromRev, err := a.getRevision(ctx, uid, aid, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := a.getRevision(ctx, uid, aid, to)
+	if err != nil {
+		return nil, err
+	}
+	// 标题也当成一行参与对比
+	return diffx.Lines(fromRev.Title+"\n"+fromRev.Content, toRev.Title+"\n"+toRev.Content), nil
+}
+
+func (a *articleService) RestoreRevision(ctx context.Context, biz string, uid, aid, revId, version int64) (domain.Article, error) {
+	rev, err := a.getRevision(ctx, uid, aid, revId)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	// 历史版本里面没有价格，沿用草稿现在的价格
+	draft, err := a.repo.GetById(ctx, aid)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if version <= 0 {
+		// 至少不能覆盖掉查草稿之后别人保存的内容
+		version = draft.Version
+	}
+	// 恢复本身也是一次保存，所以也会留下一个新的版本，恢复操作可以被撤销
+	return a.Save(ctx, biz, domain.Article{
+		Id:      rev.ArticleId,
+		Title:   rev.Title,
+		Content: rev.Content,
+		Author:  rev.Author,
+		Price:   draft.Price,
+		Version: version,
+	})
+}
+
+func (a *articleService) getRevision(ctx context.Context, uid, aid, revId int64) (domain.ArticleRevision, error) {
+	rev, err := a.revRepo.GetById(ctx, revId)
+	if errors.Is(err, repository.ErrArticleRevisionNotFound) {
+		return domain.ArticleRevision{}, ErrArticleRevisionNotFound
+	}
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	if rev.ArticleId != aid || rev.Author.Id != uid {
+		return domain.ArticleRevision{}, ErrArticleRevisionNotFound
+	}
+	return rev, nil
+}
+
+// appendRevision 记录历史版本，失败了不影响保存本身
+func (a *articleService) appendRevision(ctx context.Context, art domain.Article) {
+	_, err := a.revRepo.Append(ctx, art)
+	if err != nil {
+		a.l.Error("记录文章历史版本失败",
+			logger.Int64("aid", art.Id),
+			logger.Int64("uid", art.Author.Id),
+			logger.Error(err))
+	}
 }
 
 func (a *articleService) GetByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
@@ -34,50 +402,221 @@ func (a *articleService) ListPub(ctx context.Context, start time.Time, offset, l
 	return a.repo.ListPub(ctx, start, offset, limit)
 }
 
-func (a *articleService) GetPubById(ctx context.Context, id, uid int64) (domain.Article, error) {
+func (a *articleService) ListPubByCursor(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	return a.repo.ListPubByCursor(ctx, cursor, limit)
+}
+
+func (a *articleService) GetPubById(ctx context.Context, id int64) (domain.Article, error) {
 	art, err := a.repo.GetPubById(ctx, id)
-	if err == nil {
-		go func() {
-			er := a.producer.ProduceReadEvent(
-				ctx,
-				events.ReadEvent{
-					Aid: id,
-					Uid: uid,
-				},
-			)
-			if er != nil {
-				fmt.Println("发送读者阅读事件失败", err)
-			}
-		}()
+	if errors.Is(err, repository.ErrPubArticleNotFound) {
+		return domain.Article{}, ErrArticleNotFound
 	}
+	if err != nil {
+		return domain.Article{}, err
+	}
+	art.Tags = a.getTags(ctx, id)
 	return art, nil
 }
 
+func (a *articleService) Read(ctx context.Context, id, uid int64) {
+	go func() {
+		er := a.producer.ProduceReadEvent(
+			ctx,
+			events.ReadEvent{
+				Aid: id,
+				Uid: uid,
+			},
+		)
+		if er != nil {
+			fmt.Println("发送读者阅读事件失败", er)
+		}
+	}()
+}
+
 func (a *articleService) GetById(ctx context.Context, id int64) (domain.Article, error) {
-	return a.repo.GetById(ctx, id)
+	art, err := a.repo.GetById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	art.Tags = a.getTags(ctx, id)
+	return art, nil
+}
+
+// getTags 标签查询失败不影响文章本身的展示
+func (a *articleService) getTags(ctx context.Context, aid int64) []string {
+	tags, err := a.tagRepo.GetArticleTags(ctx, aid)
+	if err != nil {
+		a.l.Warn("查询文章标签失败",
+			logger.Int64("aid", aid),
+			logger.Error(err))
+	}
+	return tags
 }
 
 func (a *articleService) GetByAuthor(ctx context.Context, uid int64, pageIndex int, pageSize int, title string) ([]domain.Article, int64, error) {
 	return a.repo.GetByAuthor(ctx, uid, pageIndex, pageSize)
 }
 
-func NewArticleService(repo repository.ArticleRepository, producer events.Producer) ArticleService {
+func NewArticleService(repo repository.ArticleRepository, revRepo repository.ArticleRevisionRepository,
+	tagRepo repository.TagRepository, reviewRepo repository.ArticleReviewRepository,
+	attachRepo repository.AttachmentRepository, filter *sensitive.Filter, producer events.Producer, l logger.Logger) ArticleService {
 	return &articleService{
-		repo:     repo,
-		producer: producer,
+		repo:       repo,
+		revRepo:    revRepo,
+		tagRepo:    tagRepo,
+		reviewRepo: reviewRepo,
+		attachRepo: attachRepo,
+		filter:     filter,
+		producer:   producer,
+		l:          l,
 	}
 }
 
-func (a *articleService) Save(ctx context.Context, biz string, article domain.Article) (int64, error) {
+func (a *articleService) Save(ctx context.Context, biz string, article domain.Article) (domain.Article, error) {
 	article.Status = domain.ArticleStatusUnpublished
-	if article.Id > 0 {
-		err := a.repo.Update(ctx, biz, article)
-		return article.Id, err
+	return a.save(ctx, biz, article)
+}
+
+func (a *articleService) Publish(ctx context.Context, article domain.Article) (domain.Article, error) {
+	title := a.filter.Check(article.Title)
+	content := a.filter.Check(article.Content)
+	var hits []string
+	for _, res := range []sensitive.Result{title, content} {
+		if res.Verdict == sensitive.VerdictReview {
+			hits = append(hits, res.Hits...)
+		}
+	}
+	if len(hits) > 0 {
+		return a.submitReview(ctx, article, hits)
+	}
+	// 打码之后的内容同时写进制作库和线上库，作者看到的也是打码之后的
+	article.Title = title.Text
+	article.Content = content.Text
+	return a.publish(ctx, article)
+}
+
+// submitReview 保存草稿并且进入审核状态，原来已经发表的版本不受影响
+func (a *articleService) submitReview(ctx context.Context, article domain.Article, hits []string) (domain.Article, error) {
+	article.Status = domain.ArticleStatusReviewing
+	article.PublishAt = time.Time{}
+	article, err := a.save(ctx, articleBiz, article)
+	if err != nil {
+		return article, err
+	}
+	_, err = a.reviewRepo.Create(ctx, domain.ArticleReview{
+		ArticleId: article.Id,
+		Author:    article.Author,
+		Title:     article.Title,
+		Content:   article.Content,
+		Hits:      hits,
+		Status:    domain.ReviewStatusPending,
+	})
+	if err != nil {
+		return article, err
 	}
-	return a.repo.Create(ctx, article)
+	return article, ErrArticlePendingReview
+}
+
+func (a *articleService) ListReviews(ctx context.Context, status domain.ReviewStatus, offset, limit int) ([]domain.ArticleReview, int64, error) {
+	return a.reviewRepo.List(ctx, status, offset, limit)
 }
 
-func (a *articleService) Publish(ctx context.Context, article domain.Article) (int64, error) {
+func (a *articleService) ApproveReview(ctx context.Context, reviewerId, id int64) error {
+	review, err := a.reviewRepo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if review.Status != domain.ReviewStatusPending {
+		return ErrArticleReviewNotPending
+	}
+	art, err := a.repo.GetById(ctx, review.ArticleId)
+	if err != nil {
+		return err
+	}
+	// 作者在审核期间保存过，或者又提交了一次审核，这一条就作废了
+	if art.Status != domain.ArticleStatusReviewing ||
+		art.Title != review.Title || art.Content != review.Content {
+		return ErrArticleReviewOutdated
+	}
+	// 先发表再改审核记录，两个管理员同时通过的时候，重复发表也没有关系
+	_, err = a.publish(ctx, art)
+	if err != nil {
+		return err
+	}
+	return a.reviewRepo.Finish(ctx, id, domain.ReviewStatusApproved, reviewerId, "")
+}
+
+func (a *articleService) RejectReview(ctx context.Context, reviewerId, id int64, reason string) error {
+	review, err := a.reviewRepo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = a.reviewRepo.Finish(ctx, id, domain.ReviewStatusRejected, reviewerId, reason)
+	if err != nil {
+		return err
+	}
+	err = a.repo.TransitStatus(ctx, review.Author.Id, review.ArticleId,
+		domain.ArticleStatusReviewing, domain.ArticleStatusUnpublished)
+	if errors.Is(err, repository.ErrArticleStatusMismatch) {
+		// 作者已经改过了，不用再退回
+		return nil
+	}
+	return err
+}
+
+// publish 不经过审核直接发表
+func (a *articleService) publish(ctx context.Context, article domain.Article) (domain.Article, error) {
 	article.Status = domain.ArticleStatusPublished
-	return a.repo.Sync(ctx, article)
+	article.PublishAt = time.Time{}
+	tags, err := a.normalizeTags(article.Tags)
+	if err != nil {
+		return article, err
+	}
+	version := nextVersion(article)
+	article.Id, err = a.repo.Sync(ctx, article)
+	if err != nil {
+		return article, err
+	}
+	article.Version = version
+	if tags != nil {
+		err = a.tagRepo.SetArticleTags(ctx, article.Id, tags)
+		if err != nil {
+			return article, err
+		}
+	} else {
+		tags = a.getTags(ctx, article.Id)
+	}
+	err = a.syncAttachmentRefs(ctx, article, true)
+	if err != nil {
+		return article, err
+	}
+	a.appendRevision(ctx, article)
+	article.Tags = tags
+	a.producePublishEvent(article)
+	return article, nil
+}
+
+// producePublishEvent 异步发送发表事件，失败了只记录日志
+func (a *articleService) producePublishEvent(art domain.Article) {
+	evt := events.PublishEvent{
+		Aid:     art.Id,
+		Uid:     art.Author.Id,
+		Title:   art.Title,
+		Content: art.Content,
+		Tags:    art.Tags,
+		Price:   art.Price,
+		Status:  art.Status.ToUint8(),
+		Utime:   time.Now().UnixMilli(),
+	}
+	go func() {
+		// 请求的 ctx 在返回之后就会被取消，所以这里不能用
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		er := a.producer.ProducePublishEvent(ctx, evt)
+		if er != nil {
+			a.l.Error("发送文章发表事件失败",
+				logger.Int64("aid", evt.Aid),
+				logger.Error(er))
+		}
+	}()
 }
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxImportFiles 一次最多导入的文件数
+	maxImportFiles = 500
+	// maxImportFileSize 单个 Markdown 文件解压之后最大的字节数
+	maxImportFileSize = 1 << 20
+	// maxArchiveTitleLen 导出的文件名里面标题最多的字符数
+	maxArchiveTitleLen = 50
+)
+
+var (
+	ErrTooManyImportFiles = fmt.Errorf("一次最多导入 %d 个文件", maxImportFiles)
+	ErrInvalidArchive     = errors.New("不是有效的 zip 文件")
+)
+
+var articleStatusNames = map[domain.ArticleStatus]string{
+	domain.ArticleStatusUnpublished: "unpublished",
+	domain.ArticleStatusPublished:   "published",
+	domain.ArticleStatusPrivate:     "private",
+	domain.ArticleStatusScheduled:   "scheduled",
+	domain.ArticleStatusReviewing:   "reviewing",
+}
+
+// ArticleArchiveService 作者的文章和 Markdown 文件互相转换，用来备份，以及从别的平台搬文章过来。
+// 每篇文章一个 Markdown 文件，开头是 YAML 格式的 front matter，打包成一个 zip
+type ArticleArchiveService interface {
+	// Export 导出作者全部文章的草稿，包括没有发表的
+	Export(ctx context.Context, uid int64, w io.Writer) error
+	// Import 全部导入成新的草稿，front matter 里面只用 title 和 tags，别的字段忽略。
+	// 没有 title 的时候用第一个一级标题，再没有就用文件名
+	Import(ctx context.Context, uid int64, r io.ReaderAt, size int64) (domain.ArticleImportResult, error)
+}
+
+type articleArchiveService struct {
+	repo    repository.ArticleRepository
+	tagRepo repository.TagRepository
+	artSvc  ArticleService
+	l       logger.Logger
+}
+
+func NewArticleArchiveService(repo repository.ArticleRepository, tagRepo repository.TagRepository,
+	artSvc ArticleService, l logger.Logger) ArticleArchiveService {
+	return &articleArchiveService{
+		repo:    repo,
+		tagRepo: tagRepo,
+		artSvc:  artSvc,
+		l:       l,
+	}
+}
+
+// exportFrontMatter 导出的 front matter，id 只是给人看的，导入的时候不会用
+type exportFrontMatter struct {
+	Id     int64     `yaml:"id"`
+	Title  string    `yaml:"title"`
+	Status string    `yaml:"status"`
+	Tags   []string  `yaml:"tags,omitempty"`
+	Ctime  time.Time `yaml:"ctime"`
+	Utime  time.Time `yaml:"utime"`
+}
+
+// importFrontMatter 别的平台导出的文件字段五花八门，这里只认这两个
+type importFrontMatter struct {
+	Title string     `yaml:"title"`
+	Tags  stringList `yaml:"tags"`
+}
+
+// stringList 兼容 tags: a 和 tags: [a, b] 两种写法
+type stringList []string
+
+func (s *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = []string{node.Value}
+		return nil
+	}
+	var list []string
+	err := node.Decode(&list)
+	*s = list
+	return err
+}
+
+func (s *articleArchiveService) Export(ctx context.Context, uid int64, w io.Writer) error {
+	const batchSize = 100
+	zw := zip.NewWriter(w)
+	var minId int64
+	for {
+		arts, err := s.repo.FindByAuthor(ctx, uid, minId, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, art := range arts {
+			tags, er := s.tagRepo.GetArticleTags(ctx, art.Id)
+			if er != nil {
+				return er
+			}
+			art.Tags = tags
+			f, er := zw.CreateHeader(&zip.FileHeader{
+				Name:     archiveFileName(art),
+				Method:   zip.Deflate,
+				Modified: art.Utime,
+			})
+			if er != nil {
+				return er
+			}
+			data, er := encodeMarkdown(art)
+			if er != nil {
+				return er
+			}
+			if _, er = f.Write(data); er != nil {
+				return er
+			}
+		}
+		if len(arts) < batchSize {
+			break
+		}
+		minId = arts[len(arts)-1].Id
+	}
+	return zw.Close()
+}
+
+func (s *articleArchiveService) Import(ctx context.Context, uid int64, r io.ReaderAt, size int64) (domain.ArticleImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return domain.ArticleImportResult{}, ErrInvalidArchive
+	}
+	files := make([]*zip.File, 0, len(zr.File))
+	for _, f := range zr.File {
+		if isMarkdownFile(f) {
+			files = append(files, f)
+		}
+	}
+	if len(files) > maxImportFiles {
+		return domain.ArticleImportResult{}, ErrTooManyImportFiles
+	}
+	var res domain.ArticleImportResult
+	for _, f := range files {
+		id, er := s.importFile(ctx, uid, f)
+		if er != nil {
+			res.Failures = append(res.Failures, domain.ArticleImportFailure{
+				Name:   f.Name,
+				Reason: er.Error(),
+			})
+			continue
+		}
+		res.Ids = append(res.Ids, id)
+	}
+	return res, nil
+}
+
+func (s *articleArchiveService) importFile(ctx context.Context, uid int64, f *zip.File) (int64, error) {
+	if f.UncompressedSize64 > maxImportFileSize {
+		return 0, errors.New("文件太大了")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return 0, errors.New("文件损坏")
+	}
+	defer rc.Close()
+	// 不相信 zip 里面记录的大小，多读一个字节看看有没有超
+	data, err := io.ReadAll(io.LimitReader(rc, maxImportFileSize+1))
+	if err != nil {
+		return 0, errors.New("文件损坏")
+	}
+	if len(data) > maxImportFileSize {
+		return 0, errors.New("文件太大了")
+	}
+	if !utf8.Valid(data) {
+		return 0, errors.New("文件不是 UTF-8 编码")
+	}
+	art, err := decodeMarkdown(data)
+	if err != nil {
+		return 0, errors.New("front matter 格式不对")
+	}
+	if art.Title == "" {
+		art.Title = strings.TrimSuffix(path.Base(f.Name), path.Ext(f.Name))
+	}
+	art.Author = domain.Author{Id: uid}
+	art, err = s.artSvc.Save(ctx, articleBiz, art)
+	if errors.Is(err, ErrInvalidTags) {
+		return 0, err
+	}
+	if err != nil {
+		s.l.Error("导入文章失败",
+			logger.Int64("uid", uid),
+			logger.String("name", f.Name),
+			logger.Error(err))
+		return 0, errors.New("系统错误")
+	}
+	return art.Id, nil
+}
+
+// isMarkdownFile 跳过目录、隐藏文件和 macOS 打包的时候带上的 __MACOSX
+func isMarkdownFile(f *zip.File) bool {
+	if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") ||
+		strings.HasPrefix(path.Base(f.Name), ".") {
+		return false
+	}
+	ext := strings.ToLower(path.Ext(f.Name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// archiveFileName id 放在前面，标题一样的文章也不会重名
+func archiveFileName(art domain.Article) string {
+	title := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(art.Title))
+	if runes := []rune(title); len(runes) > maxArchiveTitleLen {
+		title = string(runes[:maxArchiveTitleLen])
+	}
+	if title == "" {
+		return fmt.Sprintf("%d.md", art.Id)
+	}
+	return fmt.Sprintf("%d-%s.md", art.Id, title)
+}
+
+func encodeMarkdown(art domain.Article) ([]byte, error) {
+	meta, err := yaml.Marshal(exportFrontMatter{
+		Id:     art.Id,
+		Title:  art.Title,
+		Status: articleStatusNames[art.Status],
+		Tags:   art.Tags,
+		Ctime:  art.Ctime,
+		Utime:  art.Utime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(meta)
+	// 空一行再写正文，导入的时候会去掉这一行
+	buf.WriteString("---\n\n")
+	buf.WriteString(art.Content)
+	return buf.Bytes(), nil
+}
+
+func decodeMarkdown(data []byte) (domain.Article, error) {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var meta importFrontMatter
+	if rest, ok := strings.CutPrefix(text, "---\n"); ok {
+		lines := strings.SplitAfter(rest, "\n")
+		for i, line := range lines {
+			if strings.TrimSuffix(line, "\n") != "---" {
+				continue
+			}
+			err := yaml.Unmarshal([]byte(strings.Join(lines[:i], "")), &meta)
+			if err != nil {
+				return domain.Article{}, err
+			}
+			text = strings.TrimPrefix(strings.Join(lines[i+1:], ""), "\n")
+			break
+		}
+	}
+	title := strings.TrimSpace(meta.Title)
+	if title == "" {
+		title = firstHeading(text)
+	}
+	art := domain.Article{
+		Title:   title,
+		Content: text,
+	}
+	if len(meta.Tags) > 0 {
+		art.Tags = meta.Tags
+	}
+	return art, nil
+}
+
+// firstHeading 第一个一级标题，没有的时候返回空字符串
+func firstHeading(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if title, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	mock_service "github.com/jayleonc/geektime-go/webook/internal/service/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+	"time"
+)
+
+func Test_articleArchiveService_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockArticleRepository(ctrl)
+	tagRepo := mock_repository.NewMockTagRepository(ctrl)
+	svc := NewArticleArchiveService(repo, tagRepo, mock_service.NewMockArticleService(ctrl), logger.NewNopLogger())
+
+	utime := time.UnixMilli(1700000000000).UTC()
+	repo.EXPECT().FindByAuthor(gomock.Any(), int64(123), int64(0), 100).Return([]domain.Article{
+		{Id: 1, Title: "第一篇/草稿", Content: "# 正文\n内容", Status: domain.ArticleStatusPublished,
+			Ctime: utime, Utime: utime},
+	}, nil)
+	tagRepo.EXPECT().GetArticleTags(gomock.Any(), int64(1)).Return([]string{"Go"}, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.Export(context.Background(), 123, &buf))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	assert.Equal(t, "1-第一篇_草稿.md", zr.File[0].Name)
+	rc, err := zr.File[0].Open()
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "---\nid: 1\ntitle: 第一篇/草稿\nstatus: published\ntags:\n    - Go\n"+
+		"ctime: 2023-11-14T22:13:20Z\nutime: 2023-11-14T22:13:20Z\n---\n\n# 正文\n内容", string(data))
+
+	// 导出的文件原样导入，标题、标签和正文都不变
+	art, err := decodeMarkdown(data)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Article{Title: "第一篇/草稿", Content: "# 正文\n内容", Tags: []string{"Go"}}, art)
+}
+
+func Test_articleArchiveService_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	artSvc := mock_service.NewMockArticleService(ctrl)
+	svc := NewArticleArchiveService(mock_repository.NewMockArticleRepository(ctrl),
+		mock_repository.NewMockTagRepository(ctrl), artSvc, logger.NewNopLogger())
+
+	author := domain.Author{Id: 123}
+	artSvc.EXPECT().Save(gomock.Any(), articleBiz, domain.Article{
+		Title: "Hexo 的文章", Content: "正文\n", Tags: []string{"随笔"}, Author: author,
+	}).Return(domain.Article{Id: 10}, nil)
+	artSvc.EXPECT().Save(gomock.Any(), articleBiz, domain.Article{
+		Title: "标题", Content: "# 标题\n正文", Author: author,
+	}).Return(domain.Article{Id: 11}, nil)
+	artSvc.EXPECT().Save(gomock.Any(), articleBiz, domain.Article{
+		Title: "notes", Content: "没有标题", Author: author,
+	}).Return(domain.Article{Id: 12}, nil)
+
+	data := newZip(t, map[string]string{
+		"hexo.md":              "---\r\ntitle: Hexo 的文章\r\ndate: 2020-01-01 10:00:00\r\ntags: 随笔\r\n---\r\n正文\r\n",
+		"dir/heading.markdown": "# 标题\n正文",
+		"notes.md":             "没有标题",
+		"bad.md":               "---\ntitle: [\n---\n",
+		"__MACOSX/._notes.md":  "忽略",
+		"image.png":            "忽略",
+	})
+	res, err := svc.Import(context.Background(), 123, bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int64{10, 11, 12}, res.Ids)
+	assert.Equal(t, []domain.ArticleImportFailure{{Name: "bad.md", Reason: "front matter 格式不对"}}, res.Failures)
+
+	_, err = svc.Import(context.Background(), 123, bytes.NewReader([]byte("abc")), 3)
+	assert.Equal(t, ErrInvalidArchive, err)
+}
+
+func newZip(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
@@ -1,12 +1,20 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	events "github.com/jayleonc/geektime-go/webook/internal/events/article"
 	"github.com/jayleonc/geektime-go/webook/internal/repository"
 	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/diffx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/sensitive"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"strings"
 	"testing"
+	"time"
 )
 
 func Test_articleService_Publish(t *testing.T) {
@@ -212,3 +220,734 @@ func Test_articleService_Publish(t *testing.T) {
 		})
 	}
 }
+
+// nopArticleProducer 发表事件是异步发送的，这里不关心
+type nopArticleProducer struct{}
+
+func (nopArticleProducer) ProduceReadEvent(ctx context.Context, evt events.ReadEvent) error {
+	return nil
+}
+
+func (nopArticleProducer) ProducePublishEvent(ctx context.Context, evt events.PublishEvent) error {
+	return nil
+}
+
+type articleMocks struct {
+	repo       *mock_repository.MockArticleRepository
+	revRepo    *mock_repository.MockArticleRevisionRepository
+	tagRepo    *mock_repository.MockTagRepository
+	reviewRepo *mock_repository.MockArticleReviewRepository
+	attachRepo *mock_repository.MockAttachmentRepository
+}
+
+func newArticleTestService(ctrl *gomock.Controller) (ArticleService, articleMocks) {
+	m := articleMocks{
+		repo:       mock_repository.NewMockArticleRepository(ctrl),
+		revRepo:    mock_repository.NewMockArticleRevisionRepository(ctrl),
+		tagRepo:    mock_repository.NewMockTagRepository(ctrl),
+		reviewRepo: mock_repository.NewMockArticleReviewRepository(ctrl),
+		attachRepo: mock_repository.NewMockAttachmentRepository(ctrl),
+	}
+	filter := sensitive.NewFilter(sensitive.Dict{
+		Mask:   []string{"垃圾"},
+		Review: []string{"赌博"},
+	})
+	svc := NewArticleService(m.repo, m.revRepo, m.tagRepo, m.reviewRepo, m.attachRepo,
+		filter, nopArticleProducer{}, logger.NewNopLogger())
+	return svc, m
+}
+
+func Test_articleService_PublishModeration(t *testing.T) {
+	author := domain.Author{Id: 123}
+	testCases := []struct {
+		name string
+		mock func(m articleMocks)
+
+		art domain.Article
+
+		wantId      int64
+		wantVersion int64
+		wantErr     error
+	}{
+		{
+			name: "没有命中，直接发表",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Sync(gomock.Any(), domain.Article{
+					Title: "标题", Content: "正常的内容",
+					Author: author, Status: domain.ArticleStatusPublished,
+				}).Return(int64(1), nil)
+				m.tagRepo.EXPECT().GetArticleTags(gomock.Any(), int64(1)).Return(nil, nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.attachRepo.EXPECT().SetPubReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			art:         domain.Article{Title: "标题", Content: "正常的内容", Author: author},
+			wantId:      1,
+			wantVersion: 1,
+		},
+		{
+			name: "打码之后发表",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Sync(gomock.Any(), domain.Article{
+					Title: "**标题", Content: "一篇**文章",
+					Author: author, Status: domain.ArticleStatusPublished,
+				}).Return(int64(1), nil)
+				m.tagRepo.EXPECT().GetArticleTags(gomock.Any(), int64(1)).Return(nil, nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.attachRepo.EXPECT().SetPubReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			art:         domain.Article{Title: "垃圾标题", Content: "一篇垃圾文章", Author: author},
+			wantId:      1,
+			wantVersion: 1,
+		},
+		{
+			name: "记录引用的图片",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				m.tagRepo.EXPECT().GetArticleTags(gomock.Any(), int64(1)).Return(nil, nil)
+				hashes := []string{strings.Repeat("a", 64), strings.Repeat("b", 64)}
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), hashes).Return(nil)
+				m.attachRepo.EXPECT().SetPubReferences(gomock.Any(), int64(1), hashes).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			art: domain.Article{Title: "标题", Author: author,
+				Content: "![](/articles/attachments/" + strings.Repeat("a", 64) + ".jpg)" +
+					"![](/articles/attachments/" + strings.Repeat("b", 64) + "_thumb.png)" +
+					"![](/articles/attachments/" + strings.Repeat("a", 64) + "_thumb.jpg)"},
+			wantId:      1,
+			wantVersion: 1,
+		},
+		{
+			name: "命中审核词，保存草稿并且进入审核",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Update(gomock.Any(), articleBiz, domain.Article{
+					Id: 1, Title: "垃圾标题", Content: "赌博",
+					Author: author, Status: domain.ArticleStatusReviewing, Version: 3,
+				}).Return(nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				m.reviewRepo.EXPECT().Create(gomock.Any(), domain.ArticleReview{
+					ArticleId: 1, Author: author,
+					Title: "垃圾标题", Content: "赌博",
+					Hits:   []string{"赌博"},
+					Status: domain.ReviewStatusPending,
+				}).Return(int64(10), nil)
+			},
+			art: domain.Article{Id: 1, Title: "垃圾标题", Content: "赌博",
+				Author: author, Version: 3},
+			wantId:      1,
+			wantVersion: 4,
+			wantErr:     ErrArticlePendingReview,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newArticleTestService(ctrl)
+			tc.mock(m)
+			art, err := svc.Publish(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, art.Id)
+			assert.Equal(t, tc.wantVersion, art.Version)
+		})
+	}
+}
+
+func Test_articleService_SaveAttachmentRefs(t *testing.T) {
+	author := domain.Author{Id: 123}
+	img := "![](/articles/attachments/" + strings.Repeat("a", 64) + ".jpg)"
+	testCases := []struct {
+		name string
+		mock func(m articleMocks)
+
+		art domain.Article
+
+		wantErr error
+	}{
+		{
+			name: "只替换草稿的引用",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Update(gomock.Any(), articleBiz, gomock.Any()).Return(nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1),
+					[]string{strings.Repeat("a", 64)}).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			art: domain.Article{Id: 1, Title: "标题", Content: img, Author: author},
+		},
+		{
+			name: "图片都删掉了",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Update(gomock.Any(), articleBiz, gomock.Any()).Return(nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), []string(nil)).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			art: domain.Article{Id: 1, Title: "标题", Content: "没有图片了", Author: author},
+		},
+		{
+			name: "更新引用失败",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Update(gomock.Any(), articleBiz, gomock.Any()).Return(nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), gomock.Any()).
+					Return(errors.New("db 错误"))
+			},
+			art:     domain.Article{Id: 1, Title: "标题", Content: img, Author: author},
+			wantErr: errors.New("db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newArticleTestService(ctrl)
+			tc.mock(m)
+			art, err := svc.Save(context.Background(), articleBiz, tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, int64(1), art.Id)
+		})
+	}
+}
+
+func Test_articleService_ApproveReview(t *testing.T) {
+	review := domain.ArticleReview{
+		Id: 10, ArticleId: 1, Author: domain.Author{Id: 123},
+		Title: "标题", Content: "赌博", Status: domain.ReviewStatusPending,
+	}
+	testCases := []struct {
+		name    string
+		mock    func(m articleMocks)
+		wantErr error
+	}{
+		{
+			name: "通过之后发表",
+			mock: func(m articleMocks) {
+				m.reviewRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(review, nil)
+				m.repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id: 1, Title: "标题", Content: "赌博", Author: review.Author,
+					Status: domain.ArticleStatusReviewing, Version: 2,
+				}, nil)
+				m.repo.EXPECT().Sync(gomock.Any(), domain.Article{
+					Id: 1, Title: "标题", Content: "赌博", Author: review.Author,
+					Status: domain.ArticleStatusPublished, Version: 2,
+				}).Return(int64(1), nil)
+				m.tagRepo.EXPECT().GetArticleTags(gomock.Any(), int64(1)).Return(nil, nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.attachRepo.EXPECT().SetPubReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				m.reviewRepo.EXPECT().Finish(gomock.Any(), int64(10),
+					domain.ReviewStatusApproved, int64(1), "").Return(nil)
+			},
+		},
+		{
+			name: "已经处理过了",
+			mock: func(m articleMocks) {
+				r := review
+				r.Status = domain.ReviewStatusRejected
+				m.reviewRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(r, nil)
+			},
+			wantErr: ErrArticleReviewNotPending,
+		},
+		{
+			name: "审核期间作者改过文章",
+			mock: func(m articleMocks) {
+				m.reviewRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(review, nil)
+				m.repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id: 1, Title: "标题", Content: "改过的内容", Author: review.Author,
+					Status: domain.ArticleStatusUnpublished,
+				}, nil)
+			},
+			wantErr: ErrArticleReviewOutdated,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newArticleTestService(ctrl)
+			tc.mock(m)
+			err := svc.ApproveReview(context.Background(), 1, 10)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleService_RejectReview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, m := newArticleTestService(ctrl)
+	m.reviewRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.ArticleReview{
+		Id: 10, ArticleId: 1, Author: domain.Author{Id: 123},
+		Status: domain.ReviewStatusPending,
+	}, nil)
+	m.reviewRepo.EXPECT().Finish(gomock.Any(), int64(10),
+		domain.ReviewStatusRejected, int64(1), "广告").Return(nil)
+	// 作者已经把文章改回草稿了，不算错误
+	m.repo.EXPECT().TransitStatus(gomock.Any(), int64(123), int64(1),
+		domain.ArticleStatus(domain.ArticleStatusReviewing),
+		domain.ArticleStatus(domain.ArticleStatusUnpublished)).
+		Return(repository.ErrArticleStatusMismatch)
+	err := svc.RejectReview(context.Background(), 1, 10, "广告")
+	assert.NoError(t, err)
+}
+
+func Test_articleService_Schedule(t *testing.T) {
+	author := domain.Author{Id: 123}
+	publishAt := time.Now().Add(time.Hour)
+	testCases := []struct {
+		name string
+		mock func(m articleMocks)
+
+		art domain.Article
+
+		wantId      int64
+		wantVersion int64
+		wantErr     error
+	}{
+		{
+			name: "新建定时发表的文章",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Create(gomock.Any(), domain.Article{
+					Title: "标题", Content: "内容", Author: author,
+					Status: domain.ArticleStatusScheduled, PublishAt: publishAt,
+				}).Return(int64(1), nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			art:         domain.Article{Title: "标题", Content: "内容", Author: author, PublishAt: publishAt},
+			wantId:      1,
+			wantVersion: 1,
+		},
+		{
+			name: "已有的草稿改成定时发表",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Update(gomock.Any(), articleBiz, domain.Article{
+					Id: 1, Title: "标题", Content: "内容", Author: author, Version: 2,
+					Status: domain.ArticleStatusScheduled, PublishAt: publishAt,
+				}).Return(nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			art: domain.Article{Id: 1, Title: "标题", Content: "内容", Author: author,
+				Version: 2, PublishAt: publishAt},
+			wantId:      1,
+			wantVersion: 3,
+		},
+		{
+			name: "版本冲突",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Update(gomock.Any(), articleBiz, gomock.Any()).
+					Return(repository.ErrArticleVersionConflict)
+			},
+			art: domain.Article{Id: 1, Title: "标题", Content: "内容", Author: author,
+				Version: 1, PublishAt: publishAt},
+			wantId:      1,
+			wantVersion: 1,
+			wantErr:     ErrArticleVersionConflict,
+		},
+		{
+			name: "发表时间已经过了",
+			mock: func(m articleMocks) {},
+			art: domain.Article{Title: "标题", Content: "内容", Author: author,
+				PublishAt: time.Now().Add(-time.Minute)},
+			wantErr: ErrInvalidPublishTime,
+		},
+		{
+			name:    "没有发表时间",
+			mock:    func(m articleMocks) {},
+			art:     domain.Article{Title: "标题", Content: "内容", Author: author},
+			wantErr: ErrInvalidPublishTime,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newArticleTestService(ctrl)
+			tc.mock(m)
+			art, err := svc.Schedule(context.Background(), articleBiz, tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, art.Id)
+			assert.Equal(t, tc.wantVersion, art.Version)
+		})
+	}
+}
+
+func Test_articleService_CancelSchedule(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(m articleMocks)
+		wantErr error
+	}{
+		{
+			name: "取消成功",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().CancelSchedule(gomock.Any(), int64(123), int64(1)).Return(nil)
+			},
+		},
+		{
+			name: "不是定时发表的文章",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().CancelSchedule(gomock.Any(), int64(123), int64(1)).
+					Return(repository.ErrArticleNotScheduled)
+			},
+			wantErr: ErrArticleNotScheduled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newArticleTestService(ctrl)
+			tc.mock(m)
+			err := svc.CancelSchedule(context.Background(), 123, 1)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleService_PublishDue(t *testing.T) {
+	now := time.UnixMilli(10000)
+	author := domain.Author{Id: 123}
+	scheduled := func(ids ...int64) []domain.Article {
+		res := make([]domain.Article, 0, len(ids))
+		for _, id := range ids {
+			res = append(res, domain.Article{Id: id, Title: "标题", Content: "内容",
+				Author: author, Status: domain.ArticleStatusScheduled, PublishAt: now})
+		}
+		return res
+	}
+	// published 期望 times 篇文章照常发表
+	published := func(m articleMocks, times int) {
+		m.repo.EXPECT().Sync(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, art domain.Article) (int64, error) {
+				return art.Id, nil
+			}).Times(times)
+		m.tagRepo.EXPECT().GetArticleTags(gomock.Any(), gomock.Any()).Return(nil, nil).Times(times)
+		m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(times)
+		m.attachRepo.EXPECT().SetPubReferences(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(times)
+		m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(times)
+	}
+	batch := make([]int64, 100)
+	for i := range batch {
+		batch[i] = int64(i + 1)
+	}
+	testCases := []struct {
+		name    string
+		mock    func(m articleMocks)
+		wantErr error
+	}{
+		{
+			name: "全部发表",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().FindDueScheduled(gomock.Any(), now, 100).Return(scheduled(1, 2), nil)
+				m.repo.EXPECT().Sync(gomock.Any(), domain.Article{Id: 1, Title: "标题", Content: "内容",
+					Author: author, Status: domain.ArticleStatusPublished}).Return(int64(1), nil)
+				m.repo.EXPECT().Sync(gomock.Any(), domain.Article{Id: 2, Title: "标题", Content: "内容",
+					Author: author, Status: domain.ArticleStatusPublished}).Return(int64(2), nil)
+				m.tagRepo.EXPECT().GetArticleTags(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				m.attachRepo.EXPECT().SetPubReferences(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(2)
+			},
+		},
+		{
+			name: "没有到期的文章",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().FindDueScheduled(gomock.Any(), now, 100).Return(nil, nil)
+			},
+		},
+		{
+			name: "满一批之后接着查",
+			mock: func(m articleMocks) {
+				first := m.repo.EXPECT().FindDueScheduled(gomock.Any(), now, 100).Return(scheduled(batch...), nil)
+				m.repo.EXPECT().FindDueScheduled(gomock.Any(), now, 100).Return(scheduled(101), nil).After(first)
+				published(m, 101)
+			},
+		},
+		{
+			name: "一篇失败不影响别的，也不再查下一批",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().FindDueScheduled(gomock.Any(), now, 100).Return(scheduled(batch...), nil)
+				m.repo.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db 错误"))
+				published(m, 99)
+			},
+		},
+		{
+			name: "命中审核词进入审核",
+			mock: func(m articleMocks) {
+				arts := scheduled(1)
+				arts[0].Content = "赌博"
+				m.repo.EXPECT().FindDueScheduled(gomock.Any(), now, 100).Return(arts, nil)
+				m.repo.EXPECT().Update(gomock.Any(), articleBiz, domain.Article{Id: 1, Title: "标题",
+					Content: "赌博", Author: author, Status: domain.ArticleStatusReviewing}).Return(nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				m.reviewRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(int64(10), nil)
+			},
+		},
+		{
+			name: "查询失败",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().FindDueScheduled(gomock.Any(), now, 100).Return(nil, errors.New("db 错误"))
+			},
+			wantErr: errors.New("db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newArticleTestService(ctrl)
+			tc.mock(m)
+			err := svc.PublishDue(context.Background(), now)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleService_GetPubById(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(m articleMocks)
+
+		wantArt domain.Article
+		wantErr error
+	}{
+		{
+			name: "查询成功",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Status: domain.ArticleStatusPublished}, nil)
+				m.tagRepo.EXPECT().GetArticleTags(gomock.Any(), int64(1)).Return([]string{"Go"}, nil)
+			},
+			wantArt: domain.Article{Id: 1, Status: domain.ArticleStatusPublished, Tags: []string{"Go"}},
+		},
+		{
+			name: "文章不存在",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{}, repository.ErrPubArticleNotFound)
+			},
+			wantErr: ErrArticleNotFound,
+		},
+		{
+			name: "查询失败",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{}, errors.New("db 错误"))
+			},
+			wantErr: errors.New("db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newArticleTestService(ctrl)
+			tc.mock(m)
+			art, err := svc.GetPubById(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArt, art)
+		})
+	}
+}
+
+func Test_articleService_Restore(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(m articleMocks)
+		wantErr error
+	}{
+		{
+			name: "恢复草稿",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Restore(gomock.Any(), int64(123), int64(1)).
+					Return(domain.DeletedArticle{Article: domain.Article{Id: 1}}, nil)
+			},
+		},
+		{
+			name: "恢复线上版本",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Restore(gomock.Any(), int64(123), int64(1)).
+					Return(domain.DeletedArticle{Article: domain.Article{Id: 1}, Published: true}, nil)
+				m.repo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Status: domain.ArticleStatusPublished}, nil)
+				m.tagRepo.EXPECT().GetArticleTags(gomock.Any(), int64(1)).Return([]string{"Go"}, nil)
+			},
+		},
+		{
+			name: "回收站里面没有",
+			mock: func(m articleMocks) {
+				m.repo.EXPECT().Restore(gomock.Any(), int64(123), int64(1)).
+					Return(domain.DeletedArticle{}, repository.ErrDeletedArticleNotFound)
+			},
+			wantErr: ErrDeletedArticleNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newArticleTestService(ctrl)
+			tc.mock(m)
+			err := svc.Restore(context.Background(), 123, 1)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleService_PurgeDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, m := newArticleTestService(ctrl)
+	before := time.UnixMilli(1000)
+	m.repo.EXPECT().FindExpiredDeleted(gomock.Any(), before, 100).Return([]domain.DeletedArticle{
+		{Article: domain.Article{Id: 1}},
+		{Article: domain.Article{Id: 2}},
+	}, nil)
+	m.repo.EXPECT().Purge(gomock.Any(), int64(1)).Return(nil)
+	m.tagRepo.EXPECT().SetArticleTags(gomock.Any(), int64(1), []string{}).Return(nil)
+	m.attachRepo.EXPECT().DeleteReferences(gomock.Any(), int64(1)).Return(nil)
+	// 一篇失败了不影响别的
+	m.repo.EXPECT().Purge(gomock.Any(), int64(2)).Return(errors.New("mock db error"))
+	err := svc.PurgeDeleted(context.Background(), before)
+	assert.NoError(t, err)
+}
+
+func Test_articleService_DiffRevisions(t *testing.T) {
+	author := domain.Author{Id: 123}
+	testCases := []struct {
+		name    string
+		mock    func(m articleMocks)
+		want    []diffx.Line
+		wantErr error
+	}{
+		{
+			name: "对比两个版本",
+			mock: func(m articleMocks) {
+				m.revRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.ArticleRevision{
+					Id: 10, ArticleId: 1, Author: author, Title: "标题", Content: "内容",
+				}, nil)
+				m.revRepo.EXPECT().GetById(gomock.Any(), int64(11)).Return(domain.ArticleRevision{
+					Id: 11, ArticleId: 1, Author: author, Title: "标题", Content: "新的内容",
+				}, nil)
+			},
+			want: []diffx.Line{
+				{Op: diffx.OpEqual, Text: "标题"},
+				{Op: diffx.OpDelete, Text: "内容"},
+				{Op: diffx.OpInsert, Text: "新的内容"},
+			},
+		},
+		{
+			name: "版本不存在",
+			mock: func(m articleMocks) {
+				m.revRepo.EXPECT().GetById(gomock.Any(), int64(10)).
+					Return(domain.ArticleRevision{}, repository.ErrArticleRevisionNotFound)
+			},
+			wantErr: ErrArticleRevisionNotFound,
+		},
+		{
+			name: "别人的版本",
+			mock: func(m articleMocks) {
+				m.revRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.ArticleRevision{
+					Id: 10, ArticleId: 1, Author: domain.Author{Id: 456},
+				}, nil)
+			},
+			wantErr: ErrArticleRevisionNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newArticleTestService(ctrl)
+			tc.mock(m)
+			lines, err := svc.DiffRevisions(context.Background(), 123, 1, 10, 11)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, lines)
+		})
+	}
+}
+
+func Test_articleService_RestoreRevision(t *testing.T) {
+	author := domain.Author{Id: 123}
+	rev := domain.ArticleRevision{
+		Id: 10, ArticleId: 1, Author: author, Title: "旧的标题", Content: "旧的内容",
+	}
+	testCases := []struct {
+		name    string
+		mock    func(m articleMocks)
+		version int64
+
+		wantVersion int64
+		wantErr     error
+	}{
+		{
+			name: "带上前端的版本号",
+			mock: func(m articleMocks) {
+				m.revRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(rev, nil)
+				m.repo.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Author: author, Price: 100, Version: 4}, nil)
+				m.repo.EXPECT().Update(gomock.Any(), articleBiz, domain.Article{
+					Id: 1, Title: "旧的标题", Content: "旧的内容",
+					Author: author, Price: 100, Version: 3,
+					Status: domain.ArticleStatusUnpublished,
+				}).Return(nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(11), nil)
+			},
+			version:     3,
+			wantVersion: 4,
+		},
+		{
+			name: "没有版本号，用草稿现在的版本号",
+			mock: func(m articleMocks) {
+				m.revRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(rev, nil)
+				m.repo.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Author: author, Version: 4}, nil)
+				m.repo.EXPECT().Update(gomock.Any(), articleBiz, domain.Article{
+					Id: 1, Title: "旧的标题", Content: "旧的内容",
+					Author: author, Version: 4,
+					Status: domain.ArticleStatusUnpublished,
+				}).Return(nil)
+				m.attachRepo.EXPECT().SetDraftReferences(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				m.revRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(int64(11), nil)
+			},
+			wantVersion: 5,
+		},
+		{
+			name: "草稿已经被改过了",
+			mock: func(m articleMocks) {
+				m.revRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(rev, nil)
+				m.repo.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Author: author, Version: 4}, nil)
+				m.repo.EXPECT().Update(gomock.Any(), articleBiz, gomock.Any()).
+					Return(repository.ErrArticleVersionConflict)
+			},
+			version:     3,
+			wantVersion: 3,
+			wantErr:     ErrArticleVersionConflict,
+		},
+		{
+			name: "版本不存在",
+			mock: func(m articleMocks) {
+				m.revRepo.EXPECT().GetById(gomock.Any(), int64(10)).
+					Return(domain.ArticleRevision{}, repository.ErrArticleRevisionNotFound)
+			},
+			version: 3,
+			wantErr: ErrArticleRevisionNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newArticleTestService(ctrl)
+			tc.mock(m)
+			art, err := svc.RestoreRevision(context.Background(), articleBiz, 123, 1, 10, tc.version)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVersion, art.Version)
+		})
+	}
+}
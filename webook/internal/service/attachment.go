@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/imagex"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/objstore"
+	"mime"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAttachmentTooLarge = errors.New("图片太大了")
+	// ErrAttachmentType 不是支持的图片格式，或者像素太多
+	ErrAttachmentType     = errors.New("不支持的图片")
+	ErrAttachmentNotFound = errors.New("图片不存在")
+)
+
+// AttachmentService 文章里面的图片。
+// 上传的图片会重新编码，去掉 EXIF 这类元数据，同时生成缩略图。
+// 文章保存的时候记录引用了哪些图片，一直没有被引用的图片定时清理掉
+type AttachmentService interface {
+	Upload(ctx context.Context, uid int64, data []byte) (domain.Attachment, error)
+	// Get name 是链接里面的文件名，也就是 Attachment.Name 或者 Attachment.ThumbName。
+	// 返回内容和 Content-Type
+	Get(ctx context.Context, name string) ([]byte, string, error)
+	// GCOrphans 删除 before 之前上传的、没有被任何文章引用的图片，由定时任务调用
+	GCOrphans(ctx context.Context, before time.Time) error
+}
+
+type attachmentService struct {
+	repo repository.AttachmentRepository
+	l    logger.Logger
+	// maxSize 上传的图片最大的字节数
+	maxSize int64
+	opts    imagex.Options
+}
+
+func NewAttachmentService(repo repository.AttachmentRepository, l logger.Logger,
+	maxSize int64, opts imagex.Options) AttachmentService {
+	return &attachmentService{
+		repo:    repo,
+		l:       l,
+		maxSize: maxSize,
+		opts:    opts,
+	}
+}
+
+func (s *attachmentService) Upload(ctx context.Context, uid int64, data []byte) (domain.Attachment, error) {
+	if int64(len(data)) > s.maxSize {
+		return domain.Attachment{}, ErrAttachmentTooLarge
+	}
+	img, err := imagex.Process(data, s.opts)
+	if errors.Is(err, imagex.ErrUnsupportedFormat) || errors.Is(err, imagex.ErrTooManyPixels) {
+		return domain.Attachment{}, ErrAttachmentType
+	}
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	return s.repo.Save(ctx, domain.Attachment{
+		Hash:        objstore.Hash(img.Data),
+		Ext:         img.Format.Ext(),
+		ThumbExt:    img.ThumbFormat.Ext(),
+		ContentType: img.Format.ContentType(),
+		Size:        int64(len(img.Data)),
+		Width:       img.Width,
+		Height:      img.Height,
+		Uploader:    uid,
+	}, img.Data, img.Thumb)
+}
+
+func (s *attachmentService) Get(ctx context.Context, name string) ([]byte, string, error) {
+	base, ext, ok := strings.Cut(name, ".")
+	if !ok {
+		return nil, "", ErrAttachmentNotFound
+	}
+	hash, thumb := strings.CutSuffix(base, "_thumb")
+	if len(hash) != 64 {
+		return nil, "", ErrAttachmentNotFound
+	}
+	att, err := s.repo.GetByHash(ctx, hash)
+	if errors.Is(err, repository.ErrAttachmentNotFound) {
+		return nil, "", ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	// 扩展名对不上的当作不存在，一张图片只有一个链接
+	if (thumb && ext != att.ThumbExt) || (!thumb && ext != att.Ext) {
+		return nil, "", ErrAttachmentNotFound
+	}
+	data, err := s.repo.GetContent(ctx, att, thumb)
+	if errors.Is(err, objstore.ErrObjectNotFound) {
+		return nil, "", ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return data, mime.TypeByExtension("." + ext), nil
+}
+
+func (s *attachmentService) GCOrphans(ctx context.Context, before time.Time) error {
+	const batchSize = 100
+	for {
+		atts, err := s.repo.FindOrphans(ctx, before, batchSize)
+		if err != nil {
+			return err
+		}
+		failed := false
+		for _, att := range atts {
+			_, er := s.repo.DeleteOrphan(ctx, att, before)
+			if er != nil {
+				failed = true
+				s.l.Error("清理没有引用的图片失败",
+					logger.String("hash", att.Hash),
+					logger.Error(er))
+			}
+		}
+		// 和 PurgeDeleted 一样，有失败的时候等下一次调度
+		if len(atts) < batchSize || failed {
+			return nil
+		}
+	}
+}
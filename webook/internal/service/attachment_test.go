@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/imagex"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newAttachmentTestService(repo repository.AttachmentRepository) AttachmentService {
+	return NewAttachmentService(repo, logger.NewNopLogger(), 1024, imagex.Options{
+		MaxPixels: 1 << 20,
+		ThumbSize: 4,
+	})
+}
+
+func Test_attachmentService_Upload(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 8, 2))))
+	testCases := []struct {
+		name    string
+		mock    func(repo *mock_repository.MockAttachmentRepository)
+		data    []byte
+		wantErr error
+	}{
+		{
+			name: "上传成功",
+			mock: func(repo *mock_repository.MockAttachmentRepository) {
+				repo.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, att domain.Attachment, data, thumb []byte) (domain.Attachment, error) {
+						assert.Len(t, att.Hash, 64)
+						assert.Equal(t, "png", att.Ext)
+						assert.Equal(t, "png", att.ThumbExt)
+						assert.Equal(t, 8, att.Width)
+						assert.Equal(t, int64(123), att.Uploader)
+						att.Id = 1
+						return att, nil
+					})
+			},
+			data: buf.Bytes(),
+		},
+		{
+			name:    "太大",
+			mock:    func(repo *mock_repository.MockAttachmentRepository) {},
+			data:    make([]byte, 1025),
+			wantErr: ErrAttachmentTooLarge,
+		},
+		{
+			name:    "不是图片",
+			mock:    func(repo *mock_repository.MockAttachmentRepository) {},
+			data:    []byte("<html></html>"),
+			wantErr: ErrAttachmentType,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_repository.NewMockAttachmentRepository(ctrl)
+			tc.mock(repo)
+			_, err := newAttachmentTestService(repo).Upload(context.Background(), 123, tc.data)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_attachmentService_Get(t *testing.T) {
+	hash := strings.Repeat("a", 64)
+	att := domain.Attachment{Hash: hash, Ext: "gif", ThumbExt: "png"}
+	testCases := []struct {
+		name    string
+		mock    func(repo *mock_repository.MockAttachmentRepository)
+		file    string
+		wantCT  string
+		wantErr error
+	}{
+		{
+			name: "缩略图",
+			mock: func(repo *mock_repository.MockAttachmentRepository) {
+				repo.EXPECT().GetByHash(gomock.Any(), hash).Return(att, nil)
+				repo.EXPECT().GetContent(gomock.Any(), att, true).Return([]byte("thumb"), nil)
+			},
+			file:   hash + "_thumb.png",
+			wantCT: "image/png",
+		},
+		{
+			name: "扩展名不对",
+			mock: func(repo *mock_repository.MockAttachmentRepository) {
+				repo.EXPECT().GetByHash(gomock.Any(), hash).Return(att, nil)
+			},
+			file:    hash + ".png",
+			wantErr: ErrAttachmentNotFound,
+		},
+		{
+			name: "没有这张图片",
+			mock: func(repo *mock_repository.MockAttachmentRepository) {
+				repo.EXPECT().GetByHash(gomock.Any(), hash).
+					Return(domain.Attachment{}, repository.ErrAttachmentNotFound)
+			},
+			file:    hash + ".gif",
+			wantErr: ErrAttachmentNotFound,
+		},
+		{
+			name:    "文件名不对",
+			mock:    func(repo *mock_repository.MockAttachmentRepository) {},
+			file:    "../abc.gif",
+			wantErr: ErrAttachmentNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_repository.NewMockAttachmentRepository(ctrl)
+			tc.mock(repo)
+			_, ct, err := newAttachmentTestService(repo).Get(context.Background(), tc.file)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCT, ct)
+		})
+	}
+}
+
+func Test_attachmentService_GCOrphans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockAttachmentRepository(ctrl)
+	before := time.UnixMilli(1000)
+	atts := []domain.Attachment{{Id: 1}, {Id: 2}}
+	repo.EXPECT().FindOrphans(gomock.Any(), before, 100).Return(atts, nil)
+	repo.EXPECT().DeleteOrphan(gomock.Any(), atts[0], before).Return(false, errors.New("mock 存储错误"))
+	// 一张失败了不影响别的
+	repo.EXPECT().DeleteOrphan(gomock.Any(), atts[1], before).Return(true, nil)
+	err := newAttachmentTestService(repo).GCOrphans(context.Background(), before)
+	assert.NoError(t, err)
+}
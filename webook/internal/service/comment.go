@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	events "github.com/jayleonc/geektime-go/webook/internal/events/comment"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidComment        = errors.New("评论内容不合法")
+	ErrCommentNotFound       = repository.ErrCommentNotFound
+	ErrCommentTargetNotFound = errors.New("评论的对象不存在")
+	ErrCommentPermission     = errors.New("只能删除自己的评论")
+)
+
+// maxCommentLen 评论最长的字符数
+const maxCommentLen = 1000
+
+type CommentService interface {
+	// Create 发表评论，ParentId 大于 0 的时候是回复
+	Create(ctx context.Context, c domain.Comment) (int64, error)
+	// ListRoots 顶层评论，新的在前。cursor 是上一页最后一条评论的 id，0 表示第一页
+	ListRoots(ctx context.Context, biz string, bizId int64, cursor int64, limit int) ([]domain.Comment, error)
+	// ListReplies 某条顶层评论下的回复，旧的在前。cursor 是上一页最后一条回复的 id，0 表示第一页
+	ListReplies(ctx context.Context, rootId int64, cursor int64, limit int) ([]domain.Comment, error)
+	// Delete 删除自己的评论，回复它的评论会一起删除
+	Delete(ctx context.Context, uid int64, id int64) error
+	// UpdateStatus 审核，只有审核通过的评论才会展示和计数
+	UpdateStatus(ctx context.Context, id int64, status domain.CommentStatus) error
+}
+
+type commentService struct {
+	repo     repository.CommentRepository
+	artRepo  repository.ArticleRepository
+	producer events.Producer
+	l        logger.Logger
+}
+
+func NewCommentService(repo repository.CommentRepository, artRepo repository.ArticleRepository,
+	producer events.Producer, l logger.Logger) CommentService {
+	return &commentService{
+		repo:     repo,
+		artRepo:  artRepo,
+		producer: producer,
+		l:        l,
+	}
+}
+
+func (s *commentService) Create(ctx context.Context, c domain.Comment) (int64, error) {
+	c.Content = strings.TrimSpace(c.Content)
+	if c.Content == "" || utf8.RuneCountInString(c.Content) > maxCommentLen {
+		return 0, ErrInvalidComment
+	}
+	if c.ParentId > 0 {
+		parent, err := s.repo.GetById(ctx, c.ParentId)
+		if err != nil {
+			if errors.Is(err, repository.ErrCommentNotFound) {
+				return 0, ErrCommentTargetNotFound
+			}
+			return 0, err
+		}
+		if parent.Status != domain.CommentStatusApproved ||
+			parent.Biz != c.Biz || parent.BizId != c.BizId {
+			return 0, ErrCommentTargetNotFound
+		}
+		c.RootId = parent.RootId
+		if c.RootId == 0 {
+			c.RootId = parent.Id
+		}
+		c.ReplyTo = parent.Commentator
+	} else {
+		c.RootId = 0
+		c.ReplyTo = domain.Author{}
+		err := s.checkTarget(ctx, c.Biz, c.BizId)
+		if err != nil {
+			return 0, err
+		}
+	}
+	// 还没有接入自动审核，先直接通过，有问题的评论由管理员在 /admin/comments 撤下来
+	c.Status = domain.CommentStatusApproved
+	id, err := s.repo.Create(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+	s.produceCntEvent(c.Biz, c.BizId, 1)
+	return id, nil
+}
+
+// checkTarget 只能评论已经发表的文章
+func (s *commentService) checkTarget(ctx context.Context, biz string, bizId int64) error {
+	if biz != "article" {
+		return ErrCommentTargetNotFound
+	}
+	art, err := s.artRepo.GetPubById(ctx, bizId)
+	if err != nil || art.Status != domain.ArticleStatusPublished {
+		return ErrCommentTargetNotFound
+	}
+	return nil
+}
+
+func (s *commentService) ListRoots(ctx context.Context, biz string, bizId int64, cursor int64, limit int) ([]domain.Comment, error) {
+	return s.repo.FindRoots(ctx, biz, bizId, domain.CommentStatusApproved, cursor, limit)
+}
+
+func (s *commentService) ListReplies(ctx context.Context, rootId int64, cursor int64, limit int) ([]domain.Comment, error) {
+	return s.repo.FindReplies(ctx, rootId, domain.CommentStatusApproved, cursor, limit)
+}
+
+func (s *commentService) Delete(ctx context.Context, uid int64, id int64) error {
+	c, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Commentator.Id != uid {
+		return ErrCommentPermission
+	}
+	deleted, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	// 只有审核通过的评论计入了评论数
+	var delta int64
+	for _, d := range deleted {
+		if d.Status == domain.CommentStatusApproved {
+			delta--
+		}
+	}
+	if delta != 0 {
+		s.produceCntEvent(c.Biz, c.BizId, delta)
+	}
+	return nil
+}
+
+func (s *commentService) UpdateStatus(ctx context.Context, id int64, status domain.CommentStatus) error {
+	old, err := s.repo.UpdateStatus(ctx, id, status)
+	if err != nil {
+		return err
+	}
+	wasApproved := old.Status == domain.CommentStatusApproved
+	isApproved := status == domain.CommentStatusApproved
+	switch {
+	case wasApproved && !isApproved:
+		s.produceCntEvent(old.Biz, old.BizId, -1)
+	case !wasApproved && isApproved:
+		s.produceCntEvent(old.Biz, old.BizId, 1)
+	}
+	return nil
+}
+
+// produceCntEvent 异步通知 interactive 修改评论数，失败了只记录日志
+func (s *commentService) produceCntEvent(biz string, bizId int64, delta int64) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		er := s.producer.ProduceCommentCntEvent(ctx, events.CommentCntEvent{
+			Biz:   biz,
+			BizId: bizId,
+			Delta: delta,
+		})
+		if er != nil {
+			s.l.Error("发送评论数变化事件失败",
+				logger.String("biz", biz),
+				logger.Int64("biz_id", bizId),
+				logger.Int64("delta", delta),
+				logger.Error(er))
+		}
+	}()
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	events "github.com/jayleonc/geektime-go/webook/internal/events/comment"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+// chanCommentProducer 事件是异步发送的，用 channel 接住
+type chanCommentProducer struct {
+	ch chan events.CommentCntEvent
+}
+
+func (p *chanCommentProducer) ProduceCommentCntEvent(ctx context.Context, evt events.CommentCntEvent) error {
+	p.ch <- evt
+	return nil
+}
+
+func Test_commentService_Create(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.CommentRepository
+
+		c domain.Comment
+
+		wantId  int64
+		wantErr error
+		wantEvt *events.CommentCntEvent
+	}{
+		{
+			name: "回复一条回复，挂到同一个顶层评论下面",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(2)).Return(domain.Comment{
+					Id:          2,
+					Biz:         "article",
+					BizId:       1,
+					RootId:      1,
+					ParentId:    1,
+					Commentator: domain.Author{Id: 456},
+					Status:      domain.CommentStatusApproved,
+				}, nil)
+				repo.EXPECT().Create(gomock.Any(), domain.Comment{
+					Biz:         "article",
+					BizId:       1,
+					RootId:      1,
+					ParentId:    2,
+					Content:     "回复",
+					Commentator: domain.Author{Id: 123},
+					ReplyTo:     domain.Author{Id: 456},
+					Status:      domain.CommentStatusApproved,
+				}).Return(int64(3), nil)
+				return repo
+			},
+			c: domain.Comment{
+				Biz:         "article",
+				BizId:       1,
+				ParentId:    2,
+				Content:     " 回复 ",
+				Commentator: domain.Author{Id: 123},
+			},
+			wantId:  3,
+			wantEvt: &events.CommentCntEvent{Biz: "article", BizId: 1, Delta: 1},
+		},
+		{
+			name: "回复的评论还没有审核通过",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(2)).Return(domain.Comment{
+					Id:     2,
+					Biz:    "article",
+					BizId:  1,
+					Status: domain.CommentStatusPending,
+				}, nil)
+				return repo
+			},
+			c: domain.Comment{
+				Biz:      "article",
+				BizId:    1,
+				ParentId: 2,
+				Content:  "回复",
+			},
+			wantErr: ErrCommentTargetNotFound,
+		},
+		{
+			name: "内容为空",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				return mock_repository.NewMockCommentRepository(ctrl)
+			},
+			c: domain.Comment{
+				Biz:     "article",
+				BizId:   1,
+				Content: "  ",
+			},
+			wantErr: ErrInvalidComment,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			producer := &chanCommentProducer{ch: make(chan events.CommentCntEvent, 1)}
+			svc := NewCommentService(tc.mock(ctrl), nil, producer, logger.NewNopLogger())
+			id, err := svc.Create(context.Background(), tc.c)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+			if tc.wantEvt != nil {
+				assert.Equal(t, *tc.wantEvt, waitCommentEvent(t, producer))
+			}
+		})
+	}
+}
+
+func Test_commentService_Delete(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.CommentRepository
+
+		uid int64
+		id  int64
+
+		wantErr error
+		wantEvt *events.CommentCntEvent
+	}{
+		{
+			name: "删除顶层评论，只扣减审核通过的",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Comment{
+					Id:          1,
+					Biz:         "article",
+					BizId:       10,
+					Commentator: domain.Author{Id: 123},
+				}, nil)
+				repo.EXPECT().Delete(gomock.Any(), int64(1)).Return([]domain.Comment{
+					{Id: 1, Status: domain.CommentStatusApproved},
+					{Id: 2, Status: domain.CommentStatusApproved},
+					{Id: 3, Status: domain.CommentStatusRejected},
+				}, nil)
+				return repo
+			},
+			uid:     123,
+			id:      1,
+			wantEvt: &events.CommentCntEvent{Biz: "article", BizId: 10, Delta: -2},
+		},
+		{
+			name: "不能删除别人的评论",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Comment{
+					Id:          1,
+					Commentator: domain.Author{Id: 456},
+				}, nil)
+				return repo
+			},
+			uid:     123,
+			id:      1,
+			wantErr: ErrCommentPermission,
+		},
+		{
+			name: "数据库错误",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Comment{
+					Id:          1,
+					Commentator: domain.Author{Id: 123},
+				}, nil)
+				repo.EXPECT().Delete(gomock.Any(), int64(1)).
+					Return(nil, errors.New("mock db error"))
+				return repo
+			},
+			uid:     123,
+			id:      1,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			producer := &chanCommentProducer{ch: make(chan events.CommentCntEvent, 1)}
+			svc := NewCommentService(tc.mock(ctrl), nil, producer, logger.NewNopLogger())
+			err := svc.Delete(context.Background(), tc.uid, tc.id)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantEvt != nil {
+				assert.Equal(t, *tc.wantEvt, waitCommentEvent(t, producer))
+			}
+		})
+	}
+}
+
+func Test_commentService_UpdateStatus(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.CommentRepository
+
+		status domain.CommentStatus
+
+		wantErr error
+		wantEvt *events.CommentCntEvent
+	}{
+		{
+			name: "撤下已经展示的评论",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(1), domain.CommentStatusRejected).
+					Return(domain.Comment{Id: 1, Biz: "article", BizId: 10,
+						Status: domain.CommentStatusApproved}, nil)
+				return repo
+			},
+			status:  domain.CommentStatusRejected,
+			wantEvt: &events.CommentCntEvent{Biz: "article", BizId: 10, Delta: -1},
+		},
+		{
+			name: "恢复撤下的评论",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(1), domain.CommentStatusApproved).
+					Return(domain.Comment{Id: 1, Biz: "article", BizId: 10,
+						Status: domain.CommentStatusRejected}, nil)
+				return repo
+			},
+			status:  domain.CommentStatusApproved,
+			wantEvt: &events.CommentCntEvent{Biz: "article", BizId: 10, Delta: 1},
+		},
+		{
+			name: "评论不存在",
+			mock: func(ctrl *gomock.Controller) repository.CommentRepository {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(1), domain.CommentStatusRejected).
+					Return(domain.Comment{}, repository.ErrCommentNotFound)
+				return repo
+			},
+			status:  domain.CommentStatusRejected,
+			wantErr: ErrCommentNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			producer := &chanCommentProducer{ch: make(chan events.CommentCntEvent, 1)}
+			svc := NewCommentService(tc.mock(ctrl), nil, producer, logger.NewNopLogger())
+			err := svc.UpdateStatus(context.Background(), 1, tc.status)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantEvt != nil {
+				assert.Equal(t, *tc.wantEvt, waitCommentEvent(t, producer))
+			}
+		})
+	}
+}
+
+func waitCommentEvent(t *testing.T, p *chanCommentProducer) events.CommentCntEvent {
+	select {
+	case evt := <-p.ch:
+		return evt
+	case <-time.After(time.Second):
+		t.Fatal("没有收到评论数变化事件")
+		return events.CommentCntEvent{}
+	}
+}
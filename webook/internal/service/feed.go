@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"golang.org/x/sync/errgroup"
+	"sort"
+	"time"
+)
+
+const (
+	// pushBatchSize 推送的时候每一批处理的粉丝数
+	pushBatchSize = 500
+	// maxPullFollowees 拉模式最多看这么多个最近关注的人
+	maxPullFollowees = 1000
+	// rebuildSize 重建收件箱的时候每个作者拉这么多篇
+	rebuildSize = 20
+)
+
+// FeedService 关注的人发表的文章。
+// 普通作者发表的时候推到活跃粉丝的收件箱；粉丝多的作者（大 V）只写自己的发件箱，
+// 粉丝看信息流的时候再拉。不活跃的用户回来的时候先从发件箱重建收件箱
+type FeedService interface {
+	// HandlePublish 处理文章发表事件。文章不是已发表状态的时候从发件箱里面拿掉
+	HandlePublish(ctx context.Context, art domain.Article, ftime time.Time) error
+	// GetFeed 新的在前。cursor 是上一页最后一条的 Ftime 和文章 ID，零值表示第一页
+	GetFeed(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error)
+}
+
+type feedService struct {
+	repo       repository.FeedRepository
+	followRepo repository.FollowRepository
+	artRepo    repository.ArticleRepository
+	l          logger.Logger
+	// bigVThreshold 粉丝数达到这个值就不再推了
+	bigVThreshold int64
+	// activeWindow 这段时间里面看过信息流的才算活跃粉丝
+	activeWindow time.Duration
+}
+
+func NewFeedService(repo repository.FeedRepository, followRepo repository.FollowRepository,
+	artRepo repository.ArticleRepository, l logger.Logger,
+	bigVThreshold int64, activeWindow time.Duration) FeedService {
+	return &feedService{
+		repo:          repo,
+		followRepo:    followRepo,
+		artRepo:       artRepo,
+		l:             l,
+		bigVThreshold: bigVThreshold,
+		activeWindow:  activeWindow,
+	}
+}
+
+func (s *feedService) HandlePublish(ctx context.Context, art domain.Article, ftime time.Time) error {
+	if art.Status != domain.ArticleStatusPublished {
+		// 已经推出去的不撤回，读的时候会过滤掉
+		return s.repo.RemoveOutbox(ctx, art.Author.Id, art.Id)
+	}
+	err := s.repo.AddOutbox(ctx, art.Author.Id, art.Id, ftime)
+	if err != nil {
+		return err
+	}
+	statics, err := s.followRepo.GetStatics(ctx, art.Author.Id)
+	if err != nil {
+		return err
+	}
+	bigV := statics.Followers >= s.bigVThreshold
+	err = s.repo.SetBigV(ctx, art.Author.Id, bigV)
+	if err != nil || bigV {
+		return err
+	}
+	return s.push(ctx, art, ftime)
+}
+
+// push 推到活跃粉丝的收件箱，不活跃的粉丝回来的时候会重建
+func (s *feedService) push(ctx context.Context, art domain.Article, ftime time.Time) error {
+	since := time.Now().Add(-s.activeWindow)
+	var minId int64
+	for {
+		rels, err := s.followRepo.FindFollowers(ctx, art.Author.Id, minId, pushBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(rels) == 0 {
+			return nil
+		}
+		uids := make([]int64, 0, len(rels))
+		for _, rel := range rels {
+			uids = append(uids, rel.Follower)
+		}
+		active, err := s.repo.FilterActive(ctx, uids, since)
+		if err != nil {
+			return err
+		}
+		err = s.repo.PushInbox(ctx, active, art.Id, ftime)
+		if err != nil {
+			return err
+		}
+		if len(rels) < pushBatchSize {
+			return nil
+		}
+		minId = rels[len(rels)-1].Id
+	}
+}
+
+func (s *feedService) GetFeed(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	followees, err := s.listFolloweeIds(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.Id == 0 {
+		s.activate(ctx, uid, followees)
+	}
+	bigVs, err := s.repo.FilterBigV(ctx, followees)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.FeedItem, 0, limit)
+	// 删除了的文章会被过滤掉，不够一页的时候接着往后查
+	for len(res) < limit {
+		want := limit - len(res)
+		items, er := s.page(ctx, uid, bigVs, cursor, want)
+		if er != nil {
+			return nil, er
+		}
+		if len(items) == 0 {
+			break
+		}
+		last := items[len(items)-1]
+		cursor = domain.ArticleCursor{Utime: last.Ftime, Id: last.Article.Id}
+		exhausted := len(items) < want
+		items, er = s.fillArticles(ctx, items)
+		if er != nil {
+			return nil, er
+		}
+		res = append(res, items...)
+		if exhausted {
+			break
+		}
+	}
+	return res, nil
+}
+
+// page 收件箱和大 V 的发件箱合并成一页，还没有查文章本身
+func (s *feedService) page(ctx context.Context, uid int64, bigVs []int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	items, err := s.repo.ListInbox(ctx, uid, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, author := range bigVs {
+		pulled, er := s.repo.ListOutbox(ctx, author, cursor, limit)
+		if er != nil {
+			return nil, er
+		}
+		items = append(items, pulled...)
+	}
+	return mergeFeedItems(items, limit), nil
+}
+
+func (s *feedService) listFolloweeIds(ctx context.Context, uid int64) ([]int64, error) {
+	rels, err := s.followRepo.ListFollowees(ctx, uid, 0, maxPullFollowees)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(rels))
+	for _, rel := range rels {
+		ids = append(ids, rel.Followee)
+	}
+	return ids, nil
+}
+
+// activate 刷新活跃时间。不活跃的这段时间里面没有人推给他，所以先从发件箱把收件箱补回来。
+// 失败了只是信息流里面少一些文章，不影响返回
+func (s *feedService) activate(ctx context.Context, uid int64, followees []int64) {
+	now := time.Now()
+	active, err := s.repo.FilterActive(ctx, []int64{uid}, now.Add(-s.activeWindow))
+	if err == nil && len(active) == 0 {
+		err = s.rebuildInbox(ctx, uid, followees)
+	}
+	if err == nil {
+		err = s.repo.SetActive(ctx, uid, now)
+	}
+	if err != nil {
+		s.l.Warn("刷新信息流活跃状态失败",
+			logger.Int64("uid", uid),
+			logger.Error(err))
+	}
+}
+
+func (s *feedService) rebuildInbox(ctx context.Context, uid int64, followees []int64) error {
+	var items []domain.FeedItem
+	for _, author := range followees {
+		pulled, err := s.repo.ListOutbox(ctx, author, domain.ArticleCursor{}, rebuildSize)
+		if err != nil {
+			return err
+		}
+		items = append(items, pulled...)
+	}
+	return s.repo.FillInbox(ctx, uid, items)
+}
+
+// fillArticles 查出文章本身，已经删除或者不再公开的文章直接跳过
+func (s *feedService) fillArticles(ctx context.Context, items []domain.FeedItem) ([]domain.FeedItem, error) {
+	var eg errgroup.Group
+	ok := make([]bool, len(items))
+	for i := range items {
+		i := i
+		eg.Go(func() error {
+			art, err := s.artRepo.GetPubById(ctx, items[i].Article.Id)
+			if errors.Is(err, repository.ErrPubArticleNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			items[i].Article = art
+			ok[i] = art.Status == domain.ArticleStatusPublished
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	res := make([]domain.FeedItem, 0, len(items))
+	for i, item := range items {
+		if ok[i] {
+			res = append(res, item)
+		}
+	}
+	return res, nil
+}
+
+// mergeFeedItems 收件箱和几个发件箱合并成一页，同一篇文章只留一条
+func mergeFeedItems(items []domain.FeedItem, limit int) []domain.FeedItem {
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Ftime.Equal(items[j].Ftime) {
+			return items[i].Ftime.After(items[j].Ftime)
+		}
+		return items[i].Article.Id > items[j].Article.Id
+	})
+	res := make([]domain.FeedItem, 0, limit)
+	seen := make(map[int64]struct{}, len(items))
+	for _, item := range items {
+		if len(res) == limit {
+			break
+		}
+		if _, ok := seen[item.Article.Id]; ok {
+			continue
+		}
+		seen[item.Article.Id] = struct{}{}
+		res = append(res, item)
+	}
+	return res
+}
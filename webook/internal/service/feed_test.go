@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+type feedMocks struct {
+	repo       *mock_repository.MockFeedRepository
+	followRepo *mock_repository.MockFollowRepository
+	artRepo    *mock_repository.MockArticleRepository
+}
+
+func newFeedTestService(ctrl *gomock.Controller) (*feedService, feedMocks) {
+	m := feedMocks{
+		repo:       mock_repository.NewMockFeedRepository(ctrl),
+		followRepo: mock_repository.NewMockFollowRepository(ctrl),
+		artRepo:    mock_repository.NewMockArticleRepository(ctrl),
+	}
+	svc := NewFeedService(m.repo, m.followRepo, m.artRepo, logger.NewNopLogger(),
+		100, time.Hour).(*feedService)
+	return svc, m
+}
+
+func Test_feedService_HandlePublish(t *testing.T) {
+	ftime := time.UnixMilli(1000)
+	testCases := []struct {
+		name    string
+		mock    func(m feedMocks)
+		art     domain.Article
+		wantErr error
+	}{
+		{
+			name: "下线的文章从发件箱拿掉",
+			mock: func(m feedMocks) {
+				m.repo.EXPECT().RemoveOutbox(gomock.Any(), int64(123), int64(1)).Return(nil)
+			},
+			art: domain.Article{Id: 1, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusPrivate},
+		},
+		{
+			name: "大 V 只写发件箱",
+			mock: func(m feedMocks) {
+				m.repo.EXPECT().AddOutbox(gomock.Any(), int64(123), int64(1), ftime).Return(nil)
+				m.followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{Followers: 100}, nil)
+				m.repo.EXPECT().SetBigV(gomock.Any(), int64(123), true).Return(nil)
+			},
+			art: domain.Article{Id: 1, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusPublished},
+		},
+		{
+			name: "普通作者推给活跃的粉丝",
+			mock: func(m feedMocks) {
+				m.repo.EXPECT().AddOutbox(gomock.Any(), int64(123), int64(1), ftime).Return(nil)
+				m.followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{Followers: 2}, nil)
+				m.repo.EXPECT().SetBigV(gomock.Any(), int64(123), false).Return(nil)
+				m.followRepo.EXPECT().FindFollowers(gomock.Any(), int64(123), int64(0), pushBatchSize).
+					Return([]domain.FollowRelation{
+						{Id: 10, Follower: 7, Followee: 123},
+						{Id: 11, Follower: 8, Followee: 123},
+					}, nil)
+				m.repo.EXPECT().FilterActive(gomock.Any(), []int64{7, 8}, gomock.Any()).
+					Return([]int64{8}, nil)
+				m.repo.EXPECT().PushInbox(gomock.Any(), []int64{8}, int64(1), ftime).Return(nil)
+			},
+			art: domain.Article{Id: 1, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusPublished},
+		},
+		{
+			name: "查粉丝数失败",
+			mock: func(m feedMocks) {
+				m.repo.EXPECT().AddOutbox(gomock.Any(), int64(123), int64(1), ftime).Return(nil)
+				m.followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{}, errors.New("mock db 错误"))
+			},
+			art:     domain.Article{Id: 1, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusPublished},
+			wantErr: errors.New("mock db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newFeedTestService(ctrl)
+			tc.mock(m)
+			err := svc.HandlePublish(context.Background(), tc.art, ftime)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_feedService_GetFeed(t *testing.T) {
+	item := func(aid int64, ms int64) domain.FeedItem {
+		return domain.FeedItem{Article: domain.Article{Id: aid}, Ftime: time.UnixMilli(ms)}
+	}
+	pub := func(aid int64) domain.Article {
+		return domain.Article{Id: aid, Status: domain.ArticleStatusPublished}
+	}
+	testCases := []struct {
+		name   string
+		mock   func(m feedMocks)
+		cursor domain.ArticleCursor
+		limit  int
+		want   []int64
+	}{
+		{
+			name: "收件箱和大 V 的发件箱合并，去重",
+			mock: func(m feedMocks) {
+				m.followRepo.EXPECT().ListFollowees(gomock.Any(), int64(7), 0, maxPullFollowees).
+					Return([]domain.FollowRelation{{Followee: 123}, {Followee: 456}}, nil)
+				m.repo.EXPECT().FilterActive(gomock.Any(), []int64{7}, gomock.Any()).Return([]int64{7}, nil)
+				m.repo.EXPECT().SetActive(gomock.Any(), int64(7), gomock.Any()).Return(nil)
+				m.repo.EXPECT().FilterBigV(gomock.Any(), []int64{123, 456}).Return([]int64{456}, nil)
+				m.repo.EXPECT().ListInbox(gomock.Any(), int64(7), domain.ArticleCursor{}, 3).
+					Return([]domain.FeedItem{item(3, 300), item(1, 100)}, nil)
+				m.repo.EXPECT().ListOutbox(gomock.Any(), int64(456), domain.ArticleCursor{}, 3).
+					Return([]domain.FeedItem{item(4, 400), item(3, 300), item(2, 200)}, nil)
+				for _, aid := range []int64{4, 3, 2} {
+					m.artRepo.EXPECT().GetPubById(gomock.Any(), aid).Return(pub(aid), nil)
+				}
+			},
+			limit: 3,
+			want:  []int64{4, 3, 2},
+		},
+		{
+			name: "不活跃的用户先重建收件箱",
+			mock: func(m feedMocks) {
+				m.followRepo.EXPECT().ListFollowees(gomock.Any(), int64(7), 0, maxPullFollowees).
+					Return([]domain.FollowRelation{{Followee: 123}}, nil)
+				m.repo.EXPECT().FilterActive(gomock.Any(), []int64{7}, gomock.Any()).Return(nil, nil)
+				m.repo.EXPECT().ListOutbox(gomock.Any(), int64(123), domain.ArticleCursor{}, rebuildSize).
+					Return([]domain.FeedItem{item(1, 100)}, nil)
+				m.repo.EXPECT().FillInbox(gomock.Any(), int64(7), []domain.FeedItem{item(1, 100)}).Return(nil)
+				m.repo.EXPECT().SetActive(gomock.Any(), int64(7), gomock.Any()).Return(nil)
+				m.repo.EXPECT().FilterBigV(gomock.Any(), []int64{123}).Return(nil, nil)
+				m.repo.EXPECT().ListInbox(gomock.Any(), int64(7), domain.ArticleCursor{}, 2).
+					Return([]domain.FeedItem{item(1, 100)}, nil)
+				m.artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(pub(1), nil)
+			},
+			limit: 2,
+			want:  []int64{1},
+		},
+		{
+			name: "删除和私密的文章跳过，不够一页接着查",
+			mock: func(m feedMocks) {
+				cursor := domain.ArticleCursor{Utime: time.UnixMilli(500), Id: 5}
+				m.followRepo.EXPECT().ListFollowees(gomock.Any(), int64(7), 0, maxPullFollowees).
+					Return([]domain.FollowRelation{{Followee: 123}}, nil)
+				m.repo.EXPECT().FilterBigV(gomock.Any(), []int64{123}).Return(nil, nil)
+				m.repo.EXPECT().ListInbox(gomock.Any(), int64(7), cursor, 2).
+					Return([]domain.FeedItem{item(4, 400), item(3, 300)}, nil)
+				m.artRepo.EXPECT().GetPubById(gomock.Any(), int64(4)).
+					Return(domain.Article{}, repository.ErrPubArticleNotFound)
+				m.artRepo.EXPECT().GetPubById(gomock.Any(), int64(3)).
+					Return(domain.Article{Id: 3, Status: domain.ArticleStatusPrivate}, nil)
+				m.repo.EXPECT().ListInbox(gomock.Any(), int64(7),
+					domain.ArticleCursor{Utime: time.UnixMilli(300), Id: 3}, 2).
+					Return([]domain.FeedItem{item(1, 100)}, nil)
+				m.artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(pub(1), nil)
+			},
+			cursor: domain.ArticleCursor{Utime: time.UnixMilli(500), Id: 5},
+			limit:  2,
+			want:   []int64{1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, m := newFeedTestService(ctrl)
+			tc.mock(m)
+			items, err := svc.GetFeed(context.Background(), 7, tc.cursor, tc.limit)
+			assert.NoError(t, err)
+			ids := make([]int64, 0, len(items))
+			for _, item := range items {
+				ids = append(ids, item.Article.Id)
+			}
+			assert.Equal(t, tc.want, ids)
+		})
+	}
+}
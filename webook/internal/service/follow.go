@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+)
+
+var (
+	ErrFollowSelf       = errors.New("不能关注自己")
+	ErrFolloweeNotFound = errors.New("关注的用户不存在")
+)
+
+// backfillSize 关注之后把对方最近的文章补进自己的收件箱
+const backfillSize = 20
+
+type FollowService interface {
+	// Follow 关注，重复关注不会报错
+	Follow(ctx context.Context, follower, followee int64) error
+	// Unfollow 取消关注，已经推到收件箱里面的文章不会撤回
+	Unfollow(ctx context.Context, follower, followee int64) error
+	IsFollowing(ctx context.Context, follower, followee int64) (bool, error)
+	ListFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error)
+	ListFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+}
+
+type followService struct {
+	repo     repository.FollowRepository
+	userRepo repository.UserRepository
+	feedRepo repository.FeedRepository
+	l        logger.Logger
+}
+
+func NewFollowService(repo repository.FollowRepository, userRepo repository.UserRepository,
+	feedRepo repository.FeedRepository, l logger.Logger) FollowService {
+	return &followService{
+		repo:     repo,
+		userRepo: userRepo,
+		feedRepo: feedRepo,
+		l:        l,
+	}
+}
+
+func (s *followService) Follow(ctx context.Context, follower, followee int64) error {
+	if follower == followee {
+		return ErrFollowSelf
+	}
+	_, err := s.userRepo.FindById(ctx, followee)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrFolloweeNotFound
+	}
+	if err != nil {
+		return err
+	}
+	err = s.repo.Follow(ctx, follower, followee)
+	if err != nil {
+		return err
+	}
+	s.backfill(ctx, follower, followee)
+	return nil
+}
+
+// backfill 推模式下，关注之前发表的文章不会出现在收件箱里面，这里补一下。
+// 失败了只是信息流里面少几篇旧文章
+func (s *followService) backfill(ctx context.Context, follower, followee int64) {
+	items, err := s.feedRepo.ListOutbox(ctx, followee, domain.ArticleCursor{}, backfillSize)
+	if err == nil {
+		err = s.feedRepo.FillInbox(ctx, follower, items)
+	}
+	if err != nil {
+		s.l.Warn("关注之后补充收件箱失败",
+			logger.Int64("follower", follower),
+			logger.Int64("followee", followee),
+			logger.Error(err))
+	}
+}
+
+func (s *followService) Unfollow(ctx context.Context, follower, followee int64) error {
+	return s.repo.Unfollow(ctx, follower, followee)
+}
+
+func (s *followService) IsFollowing(ctx context.Context, follower, followee int64) (bool, error) {
+	_, err := s.repo.GetRelation(ctx, follower, followee)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, repository.ErrFollowRelationNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (s *followService) ListFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error) {
+	return s.repo.ListFollowees(ctx, follower, offset, limit)
+}
+
+func (s *followService) ListFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error) {
+	return s.repo.ListFollowers(ctx, followee, offset, limit)
+}
+
+func (s *followService) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	return s.repo.GetStatics(ctx, uid)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func Test_followService_Follow(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.FollowRepository,
+			repository.UserRepository, repository.FeedRepository)
+		follower int64
+		followee int64
+		wantErr  error
+	}{
+		{
+			name: "关注成功，补充收件箱",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository,
+				repository.UserRepository, repository.FeedRepository) {
+				repo := mock_repository.NewMockFollowRepository(ctrl)
+				userRepo := mock_repository.NewMockUserRepository(ctrl)
+				feedRepo := mock_repository.NewMockFeedRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(456)).Return(domain.User{Id: 456}, nil)
+				repo.EXPECT().Follow(gomock.Any(), int64(123), int64(456)).Return(nil)
+				items := []domain.FeedItem{{Article: domain.Article{Id: 1}, Ftime: time.UnixMilli(100)}}
+				feedRepo.EXPECT().ListOutbox(gomock.Any(), int64(456), domain.ArticleCursor{}, backfillSize).
+					Return(items, nil)
+				feedRepo.EXPECT().FillInbox(gomock.Any(), int64(123), items).Return(nil)
+				return repo, userRepo, feedRepo
+			},
+			follower: 123,
+			followee: 456,
+		},
+		{
+			name: "补充收件箱失败不影响关注",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository,
+				repository.UserRepository, repository.FeedRepository) {
+				repo := mock_repository.NewMockFollowRepository(ctrl)
+				userRepo := mock_repository.NewMockUserRepository(ctrl)
+				feedRepo := mock_repository.NewMockFeedRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(456)).Return(domain.User{Id: 456}, nil)
+				repo.EXPECT().Follow(gomock.Any(), int64(123), int64(456)).Return(nil)
+				feedRepo.EXPECT().ListOutbox(gomock.Any(), int64(456), domain.ArticleCursor{}, backfillSize).
+					Return(nil, errors.New("mock redis 错误"))
+				return repo, userRepo, feedRepo
+			},
+			follower: 123,
+			followee: 456,
+		},
+		{
+			name: "关注自己",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository,
+				repository.UserRepository, repository.FeedRepository) {
+				return mock_repository.NewMockFollowRepository(ctrl),
+					mock_repository.NewMockUserRepository(ctrl),
+					mock_repository.NewMockFeedRepository(ctrl)
+			},
+			follower: 123,
+			followee: 123,
+			wantErr:  ErrFollowSelf,
+		},
+		{
+			name: "用户不存在",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository,
+				repository.UserRepository, repository.FeedRepository) {
+				userRepo := mock_repository.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(456)).
+					Return(domain.User{}, repository.ErrUserNotFound)
+				return mock_repository.NewMockFollowRepository(ctrl), userRepo,
+					mock_repository.NewMockFeedRepository(ctrl)
+			},
+			follower: 123,
+			followee: 456,
+			wantErr:  ErrFolloweeNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, userRepo, feedRepo := tc.mock(ctrl)
+			svc := NewFollowService(repo, userRepo, feedRepo, logger.NewNopLogger())
+			err := svc.Follow(context.Background(), tc.follower, tc.followee)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"golang.org/x/sync/errgroup"
+	"time"
+)
+
+var ErrInvalidProgress = errors.New("阅读进度要在 0 到 1 之间")
+
+// ReadHistoryService 读者的阅读历史，以及每篇文章读到了哪里
+type ReadHistoryService interface {
+	// Record 消费阅读事件的时候调用
+	Record(ctx context.Context, uid, aid int64, rtime time.Time) error
+	// ReportProgress 客户端滚动的时候上报，progress 是 0 到 1 之间的比例
+	ReportProgress(ctx context.Context, uid, aid int64, progress float64) error
+	// GetProgress 没有看过的文章返回 0
+	GetProgress(ctx context.Context, uid, aid int64) (float64, error)
+	// List 最近看过的在前，已经下线的文章只有 Id
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.ReadHistory, int64, error)
+	Clear(ctx context.Context, uid int64) error
+}
+
+type readHistoryService struct {
+	repo    repository.ReadHistoryRepository
+	artRepo repository.ArticleRepository
+}
+
+func NewReadHistoryService(repo repository.ReadHistoryRepository, artRepo repository.ArticleRepository) ReadHistoryService {
+	return &readHistoryService{
+		repo:    repo,
+		artRepo: artRepo,
+	}
+}
+
+func (s *readHistoryService) Record(ctx context.Context, uid, aid int64, rtime time.Time) error {
+	return s.repo.AddRead(ctx, uid, aid, rtime)
+}
+
+func (s *readHistoryService) ReportProgress(ctx context.Context, uid, aid int64, progress float64) error {
+	// NaN 和任何数比较都是 false
+	if !(progress >= 0 && progress <= 1) {
+		return ErrInvalidProgress
+	}
+	return s.repo.SetProgress(ctx, uid, aid, progress)
+}
+
+func (s *readHistoryService) GetProgress(ctx context.Context, uid, aid int64) (float64, error) {
+	h, err := s.repo.Get(ctx, uid, aid)
+	if errors.Is(err, repository.ErrReadHistoryNotFound) {
+		return 0, nil
+	}
+	return h.Progress, err
+}
+
+func (s *readHistoryService) List(ctx context.Context, uid int64, offset, limit int) ([]domain.ReadHistory, int64, error) {
+	hs, count, err := s.repo.List(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	var eg errgroup.Group
+	for i := range hs {
+		i := i
+		eg.Go(func() error {
+			art, er := s.artRepo.GetPubById(ctx, hs[i].Article.Id)
+			if errors.Is(er, repository.ErrPubArticleNotFound) {
+				return nil
+			}
+			if er != nil {
+				return er
+			}
+			if art.Status == domain.ArticleStatusPublished {
+				hs[i].Article = domain.Article{
+					Id:      art.Id,
+					Title:   art.Title,
+					Content: art.Abstract(),
+					Author:  art.Author,
+					Utime:   art.Utime,
+				}
+			}
+			return nil
+		})
+	}
+	if err = eg.Wait(); err != nil {
+		return nil, 0, err
+	}
+	return hs, count, nil
+}
+
+func (s *readHistoryService) Clear(ctx context.Context, uid int64) error {
+	return s.repo.Clear(ctx, uid)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_readHistoryService_ReportProgress(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(repo *mock_repository.MockReadHistoryRepository)
+		progress float64
+		wantErr  error
+	}{
+		{
+			name: "上报成功",
+			mock: func(repo *mock_repository.MockReadHistoryRepository) {
+				repo.EXPECT().SetProgress(gomock.Any(), int64(123), int64(1), 0.5).Return(nil)
+			},
+			progress: 0.5,
+		},
+		{
+			name:     "超过 1",
+			mock:     func(repo *mock_repository.MockReadHistoryRepository) {},
+			progress: 1.2,
+			wantErr:  ErrInvalidProgress,
+		},
+		{
+			name:     "NaN",
+			mock:     func(repo *mock_repository.MockReadHistoryRepository) {},
+			progress: math.NaN(),
+			wantErr:  ErrInvalidProgress,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_repository.NewMockReadHistoryRepository(ctrl)
+			tc.mock(repo)
+			svc := NewReadHistoryService(repo, mock_repository.NewMockArticleRepository(ctrl))
+			err := svc.ReportProgress(context.Background(), 123, 1, tc.progress)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_readHistoryService_GetProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockReadHistoryRepository(ctrl)
+	svc := NewReadHistoryService(repo, mock_repository.NewMockArticleRepository(ctrl))
+
+	repo.EXPECT().Get(gomock.Any(), int64(123), int64(1)).
+		Return(domain.ReadHistory{Progress: 0.3}, nil)
+	progress, err := svc.GetProgress(context.Background(), 123, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0.3, progress)
+
+	// 没有看过的从头开始
+	repo.EXPECT().Get(gomock.Any(), int64(123), int64(2)).
+		Return(domain.ReadHistory{}, repository.ErrReadHistoryNotFound)
+	progress, err = svc.GetProgress(context.Background(), 123, 2)
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), progress)
+}
+
+func Test_readHistoryService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockReadHistoryRepository(ctrl)
+	artRepo := mock_repository.NewMockArticleRepository(ctrl)
+	svc := NewReadHistoryService(repo, artRepo)
+
+	rtime := time.UnixMilli(1700000000000)
+	repo.EXPECT().List(gomock.Any(), int64(123), 0, 20).Return([]domain.ReadHistory{
+		{Uid: 123, Article: domain.Article{Id: 1}, Progress: 0.5, Rtime: rtime},
+		{Uid: 123, Article: domain.Article{Id: 2}, Rtime: rtime},
+		{Uid: 123, Article: domain.Article{Id: 3}, Rtime: rtime},
+	}, int64(3), nil)
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+		Id: 1, Title: "标题", Content: "内容", Status: domain.ArticleStatusPublished,
+		Author: domain.Author{Id: 456, Name: "Tom"},
+	}, nil)
+	// 删除了
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(2)).
+		Return(domain.Article{}, repository.ErrPubArticleNotFound)
+	// 改成了仅自己可见
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(3)).Return(domain.Article{
+		Id: 3, Title: "私密", Status: domain.ArticleStatusPrivate,
+	}, nil)
+
+	hs, count, err := svc.List(context.Background(), 123, 0, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.Equal(t, []domain.ReadHistory{
+		{Uid: 123, Article: domain.Article{Id: 1, Title: "标题", Content: "内容",
+			Author: domain.Author{Id: 456, Name: "Tom"}}, Progress: 0.5, Rtime: rtime},
+		{Uid: 123, Article: domain.Article{Id: 2}, Rtime: rtime},
+		{Uid: 123, Article: domain.Article{Id: 3}, Rtime: rtime},
+	}, hs)
+}
@@ -11,6 +11,8 @@ import (
 type CronJobService interface {
 	Preempt(ctx context.Context) (domain.Job, error)
 	ResetNextTime(ctx context.Context, j domain.Job) error
+	// AddJob 注册一个任务，同名的任务已经存在的话什么也不做
+	AddJob(ctx context.Context, j domain.Job) error
 }
 
 type cronJobService struct {
@@ -24,6 +26,10 @@ func (c *cronJobService) ResetNextTime(ctx context.Context, j domain.Job) error
 	return c.repo.UpdateNextTime(ctx, j.Id, nextTime)
 }
 
+func (c *cronJobService) AddJob(ctx context.Context, j domain.Job) error {
+	return c.repo.AddJob(ctx, j)
+}
+
 func NewCronJobService(repo repository.CronJobRepository, l logger.Logger) CronJobService {
 	return &cronJobService{repo: repo, l: l, refreshInterval: time.Minute}
 }
@@ -52,7 +58,7 @@ func (c *cronJobService) Preempt(ctx context.Context) (domain.Job, error) {
 				logger.Int64("jib", j.Id))
 		}
 	}
-	return domain.Job{}, err
+	return j, nil
 }
 
 func (c *cronJobService) refresh(id int64) {
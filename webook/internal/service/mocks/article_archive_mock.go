@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/article_archive.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/article_archive.go -destination=./internal/service/mocks/article_archive_mock.go
+//
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleArchiveService is a mock of ArticleArchiveService interface.
+type MockArticleArchiveService struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleArchiveServiceMockRecorder
+}
+
+// MockArticleArchiveServiceMockRecorder is the mock recorder for MockArticleArchiveService.
+type MockArticleArchiveServiceMockRecorder struct {
+	mock *MockArticleArchiveService
+}
+
+// NewMockArticleArchiveService creates a new mock instance.
+func NewMockArticleArchiveService(ctrl *gomock.Controller) *MockArticleArchiveService {
+	mock := &MockArticleArchiveService{ctrl: ctrl}
+	mock.recorder = &MockArticleArchiveServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleArchiveService) EXPECT() *MockArticleArchiveServiceMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockArticleArchiveService) Export(ctx context.Context, uid int64, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, uid, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockArticleArchiveServiceMockRecorder) Export(ctx, uid, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockArticleArchiveService)(nil).Export), ctx, uid, w)
+}
+
+// Import mocks base method.
+func (m *MockArticleArchiveService) Import(ctx context.Context, uid int64, r io.ReaderAt, size int64) (domain.ArticleImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, uid, r, size)
+	ret0, _ := ret[0].(domain.ArticleImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockArticleArchiveServiceMockRecorder) Import(ctx, uid, r, size any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockArticleArchiveService)(nil).Import), ctx, uid, r, size)
+}
@@ -14,6 +14,7 @@ import (
 	time "time"
 
 	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	diffx "github.com/jayleonc/geektime-go/webook/pkg/diffx"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -23,11 +24,6 @@ type MockArticleService struct {
 	recorder *MockArticleServiceMockRecorder
 }
 
-func (m *MockArticleService) GetByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
-	//TODO implement me
-	panic("implement me")
-}
-
 // MockArticleServiceMockRecorder is the mock recorder for MockArticleService.
 type MockArticleServiceMockRecorder struct {
 	mock *MockArticleService
@@ -45,6 +41,63 @@ func (m *MockArticleService) EXPECT() *MockArticleServiceMockRecorder {
 	return m.recorder
 }
 
+// ApproveReview mocks base method.
+func (m *MockArticleService) ApproveReview(ctx context.Context, reviewerId, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveReview", ctx, reviewerId, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveReview indicates an expected call of ApproveReview.
+func (mr *MockArticleServiceMockRecorder) ApproveReview(ctx, reviewerId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveReview", reflect.TypeOf((*MockArticleService)(nil).ApproveReview), ctx, reviewerId, id)
+}
+
+// CancelSchedule mocks base method.
+func (m *MockArticleService) CancelSchedule(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockArticleServiceMockRecorder) CancelSchedule(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*MockArticleService)(nil).CancelSchedule), ctx, uid, id)
+}
+
+// Delete mocks base method.
+func (m *MockArticleService) Delete(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockArticleServiceMockRecorder) Delete(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockArticleService)(nil).Delete), ctx, uid, id)
+}
+
+// DiffRevisions mocks base method.
+func (m *MockArticleService) DiffRevisions(ctx context.Context, uid, aid, from, to int64) ([]diffx.Line, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffRevisions", ctx, uid, aid, from, to)
+	ret0, _ := ret[0].([]diffx.Line)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffRevisions indicates an expected call of DiffRevisions.
+func (mr *MockArticleServiceMockRecorder) DiffRevisions(ctx, uid, aid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffRevisions", reflect.TypeOf((*MockArticleService)(nil).DiffRevisions), ctx, uid, aid, from, to)
+}
+
 // GetByAuthor mocks base method.
 func (m *MockArticleService) GetByAuthor(ctx context.Context, uid int64, pageIndex, pageSize int, title string) ([]domain.Article, int64, error) {
 	m.ctrl.T.Helper()
@@ -76,19 +129,50 @@ func (mr *MockArticleServiceMockRecorder) GetById(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockArticleService)(nil).GetById), ctx, id)
 }
 
+// GetByIds mocks base method.
+func (m *MockArticleService) GetByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIds", ctx, ids)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIds indicates an expected call of GetByIds.
+func (mr *MockArticleServiceMockRecorder) GetByIds(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIds", reflect.TypeOf((*MockArticleService)(nil).GetByIds), ctx, ids)
+}
+
 // GetPubById mocks base method.
-func (m *MockArticleService) GetPubById(ctx context.Context, id, uid int64) (domain.Article, error) {
+func (m *MockArticleService) GetPubById(ctx context.Context, id int64) (domain.Article, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPubById", ctx, id, uid)
+	ret := m.ctrl.Call(m, "GetPubById", ctx, id)
 	ret0, _ := ret[0].(domain.Article)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPubById indicates an expected call of GetPubById.
-func (mr *MockArticleServiceMockRecorder) GetPubById(ctx, id, uid any) *gomock.Call {
+func (mr *MockArticleServiceMockRecorder) GetPubById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubById", reflect.TypeOf((*MockArticleService)(nil).GetPubById), ctx, id)
+}
+
+// ListDeleted mocks base method.
+func (m *MockArticleService) ListDeleted(ctx context.Context, uid int64, offset, limit int) ([]domain.DeletedArticle, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]domain.DeletedArticle)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockArticleServiceMockRecorder) ListDeleted(ctx, uid, offset, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubById", reflect.TypeOf((*MockArticleService)(nil).GetPubById), ctx, id, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockArticleService)(nil).ListDeleted), ctx, uid, offset, limit)
 }
 
 // ListPub mocks base method.
@@ -106,11 +190,104 @@ func (mr *MockArticleServiceMockRecorder) ListPub(ctx, start, offset, limit any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPub", reflect.TypeOf((*MockArticleService)(nil).ListPub), ctx, start, offset, limit)
 }
 
+// ListPubByCursor mocks base method.
+func (m *MockArticleService) ListPubByCursor(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByCursor", ctx, cursor, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByCursor indicates an expected call of ListPubByCursor.
+func (mr *MockArticleServiceMockRecorder) ListPubByCursor(ctx, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByCursor", reflect.TypeOf((*MockArticleService)(nil).ListPubByCursor), ctx, cursor, limit)
+}
+
+// ListPubByTag mocks base method.
+func (m *MockArticleService) ListPubByTag(ctx context.Context, tag string, start time.Time, offset, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByTag", ctx, tag, start, offset, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByTag indicates an expected call of ListPubByTag.
+func (mr *MockArticleServiceMockRecorder) ListPubByTag(ctx, tag, start, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByTag", reflect.TypeOf((*MockArticleService)(nil).ListPubByTag), ctx, tag, start, offset, limit)
+}
+
+// ListReviews mocks base method.
+func (m *MockArticleService) ListReviews(ctx context.Context, status domain.ReviewStatus, offset, limit int) ([]domain.ArticleReview, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReviews", ctx, status, offset, limit)
+	ret0, _ := ret[0].([]domain.ArticleReview)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListReviews indicates an expected call of ListReviews.
+func (mr *MockArticleServiceMockRecorder) ListReviews(ctx, status, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReviews", reflect.TypeOf((*MockArticleService)(nil).ListReviews), ctx, status, offset, limit)
+}
+
+// ListRevisions mocks base method.
+func (m *MockArticleService) ListRevisions(ctx context.Context, uid, aid int64, offset, limit int) ([]domain.ArticleRevision, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", ctx, uid, aid, offset, limit)
+	ret0, _ := ret[0].([]domain.ArticleRevision)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockArticleServiceMockRecorder) ListRevisions(ctx, uid, aid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockArticleService)(nil).ListRevisions), ctx, uid, aid, offset, limit)
+}
+
+// ListScheduled mocks base method.
+func (m *MockArticleService) ListScheduled(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduled", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListScheduled indicates an expected call of ListScheduled.
+func (mr *MockArticleServiceMockRecorder) ListScheduled(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduled", reflect.TypeOf((*MockArticleService)(nil).ListScheduled), ctx, uid, offset, limit)
+}
+
+// ListTags mocks base method.
+func (m *MockArticleService) ListTags(ctx context.Context, offset, limit int) ([]domain.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", ctx, offset, limit)
+	ret0, _ := ret[0].([]domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockArticleServiceMockRecorder) ListTags(ctx, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockArticleService)(nil).ListTags), ctx, offset, limit)
+}
+
 // Publish mocks base method.
-func (m *MockArticleService) Publish(ctx context.Context, article domain.Article) (int64, error) {
+func (m *MockArticleService) Publish(ctx context.Context, article domain.Article) (domain.Article, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Publish", ctx, article)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].(domain.Article)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -121,16 +298,129 @@ func (mr *MockArticleServiceMockRecorder) Publish(ctx, article any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockArticleService)(nil).Publish), ctx, article)
 }
 
-// Save indicates an expected call of Save.
-func (mr *MockArticleServiceMockRecorder) Save(ctx, article any) *gomock.Call {
+// PublishDue mocks base method.
+func (m *MockArticleService) PublishDue(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishDue", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishDue indicates an expected call of PublishDue.
+func (mr *MockArticleServiceMockRecorder) PublishDue(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishDue", reflect.TypeOf((*MockArticleService)(nil).PublishDue), ctx, now)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockArticleService) PurgeDeleted(ctx context.Context, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockArticleServiceMockRecorder) PurgeDeleted(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockArticleService)(nil).PurgeDeleted), ctx, before)
+}
+
+// Read mocks base method.
+func (m *MockArticleService) Read(ctx context.Context, id, uid int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Read", ctx, id, uid)
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockArticleServiceMockRecorder) Read(ctx, id, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockArticleService)(nil).Read), ctx, id, uid)
+}
+
+// RejectReview mocks base method.
+func (m *MockArticleService) RejectReview(ctx context.Context, reviewerId, id int64, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectReview", ctx, reviewerId, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RejectReview indicates an expected call of RejectReview.
+func (mr *MockArticleServiceMockRecorder) RejectReview(ctx, reviewerId, id, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectReview", reflect.TypeOf((*MockArticleService)(nil).RejectReview), ctx, reviewerId, id, reason)
+}
+
+// Reschedule mocks base method.
+func (m *MockArticleService) Reschedule(ctx context.Context, uid, id int64, publishAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reschedule", ctx, uid, id, publishAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reschedule indicates an expected call of Reschedule.
+func (mr *MockArticleServiceMockRecorder) Reschedule(ctx, uid, id, publishAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reschedule", reflect.TypeOf((*MockArticleService)(nil).Reschedule), ctx, uid, id, publishAt)
+}
+
+// Restore mocks base method.
+func (m *MockArticleService) Restore(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockArticleServiceMockRecorder) Restore(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockArticleService)(nil).Restore), ctx, uid, id)
+}
+
+// RestoreRevision mocks base method.
+func (m *MockArticleService) RestoreRevision(ctx context.Context, biz string, uid, aid, revId, version int64) (domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreRevision", ctx, biz, uid, aid, revId, version)
+	ret0, _ := ret[0].(domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreRevision indicates an expected call of RestoreRevision.
+func (mr *MockArticleServiceMockRecorder) RestoreRevision(ctx, biz, uid, aid, revId, version any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockArticleService)(nil).Save), ctx, article)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreRevision", reflect.TypeOf((*MockArticleService)(nil).RestoreRevision), ctx, biz, uid, aid, revId, version)
 }
 
-func (m *MockArticleService) Save(ctx context.Context, biz string, article domain.Article) (int64, error) {
+// Save mocks base method.
+func (m *MockArticleService) Save(ctx context.Context, biz string, article domain.Article) (domain.Article, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Save", ctx, biz, article)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].(domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockArticleServiceMockRecorder) Save(ctx, biz, article any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockArticleService)(nil).Save), ctx, biz, article)
+}
+
+// Schedule mocks base method.
+func (m *MockArticleService) Schedule(ctx context.Context, biz string, article domain.Article) (domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Schedule", ctx, biz, article)
+	ret0, _ := ret[0].(domain.Article)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
+
+// Schedule indicates an expected call of Schedule.
+func (mr *MockArticleServiceMockRecorder) Schedule(ctx, biz, article any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockArticleService)(nil).Schedule), ctx, biz, article)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/feed.go -destination=./internal/service/mocks/feed_mock.go
+//
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedService is a mock of FeedService interface.
+type MockFeedService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedServiceMockRecorder
+}
+
+// MockFeedServiceMockRecorder is the mock recorder for MockFeedService.
+type MockFeedServiceMockRecorder struct {
+	mock *MockFeedService
+}
+
+// NewMockFeedService creates a new mock instance.
+func NewMockFeedService(ctrl *gomock.Controller) *MockFeedService {
+	mock := &MockFeedService{ctrl: ctrl}
+	mock.recorder = &MockFeedServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedService) EXPECT() *MockFeedServiceMockRecorder {
+	return m.recorder
+}
+
+// GetFeed mocks base method.
+func (m *MockFeedService) GetFeed(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeed", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]domain.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeed indicates an expected call of GetFeed.
+func (mr *MockFeedServiceMockRecorder) GetFeed(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeed", reflect.TypeOf((*MockFeedService)(nil).GetFeed), ctx, uid, cursor, limit)
+}
+
+// HandlePublish mocks base method.
+func (m *MockFeedService) HandlePublish(ctx context.Context, art domain.Article, ftime time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandlePublish", ctx, art, ftime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandlePublish indicates an expected call of HandlePublish.
+func (mr *MockFeedServiceMockRecorder) HandlePublish(ctx, art, ftime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePublish", reflect.TypeOf((*MockFeedService)(nil).HandlePublish), ctx, art, ftime)
+}
@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/history.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/history.go -destination=./internal/service/mocks/history_mock.go
+//
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReadHistoryService is a mock of ReadHistoryService interface.
+type MockReadHistoryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadHistoryServiceMockRecorder
+}
+
+// MockReadHistoryServiceMockRecorder is the mock recorder for MockReadHistoryService.
+type MockReadHistoryServiceMockRecorder struct {
+	mock *MockReadHistoryService
+}
+
+// NewMockReadHistoryService creates a new mock instance.
+func NewMockReadHistoryService(ctrl *gomock.Controller) *MockReadHistoryService {
+	mock := &MockReadHistoryService{ctrl: ctrl}
+	mock.recorder = &MockReadHistoryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReadHistoryService) EXPECT() *MockReadHistoryServiceMockRecorder {
+	return m.recorder
+}
+
+// Clear mocks base method.
+func (m *MockReadHistoryService) Clear(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clear", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockReadHistoryServiceMockRecorder) Clear(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockReadHistoryService)(nil).Clear), ctx, uid)
+}
+
+// GetProgress mocks base method.
+func (m *MockReadHistoryService) GetProgress(ctx context.Context, uid, aid int64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProgress", ctx, uid, aid)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProgress indicates an expected call of GetProgress.
+func (mr *MockReadHistoryServiceMockRecorder) GetProgress(ctx, uid, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProgress", reflect.TypeOf((*MockReadHistoryService)(nil).GetProgress), ctx, uid, aid)
+}
+
+// List mocks base method.
+func (m *MockReadHistoryService) List(ctx context.Context, uid int64, offset, limit int) ([]domain.ReadHistory, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]domain.ReadHistory)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockReadHistoryServiceMockRecorder) List(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReadHistoryService)(nil).List), ctx, uid, offset, limit)
+}
+
+// Record mocks base method.
+func (m *MockReadHistoryService) Record(ctx context.Context, uid, aid int64, rtime time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, uid, aid, rtime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockReadHistoryServiceMockRecorder) Record(ctx, uid, aid, rtime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockReadHistoryService)(nil).Record), ctx, uid, aid, rtime)
+}
+
+// ReportProgress mocks base method.
+func (m *MockReadHistoryService) ReportProgress(ctx context.Context, uid, aid int64, progress float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportProgress", ctx, uid, aid, progress)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReportProgress indicates an expected call of ReportProgress.
+func (mr *MockReadHistoryServiceMockRecorder) ReportProgress(ctx, uid, aid, progress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportProgress", reflect.TypeOf((*MockReadHistoryService)(nil).ReportProgress), ctx, uid, aid, progress)
+}
@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/order.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/order.go -destination=./internal/service/mocks/order_mock.go
+//
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	payment "github.com/jayleonc/geektime-go/webook/internal/service/payment"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderService is a mock of OrderService interface.
+type MockOrderService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderServiceMockRecorder
+}
+
+// MockOrderServiceMockRecorder is the mock recorder for MockOrderService.
+type MockOrderServiceMockRecorder struct {
+	mock *MockOrderService
+}
+
+// NewMockOrderService creates a new mock instance.
+func NewMockOrderService(ctrl *gomock.Controller) *MockOrderService {
+	mock := &MockOrderService{ctrl: ctrl}
+	mock.recorder = &MockOrderServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderService) EXPECT() *MockOrderServiceMockRecorder {
+	return m.recorder
+}
+
+// Buy mocks base method.
+func (m *MockOrderService) Buy(ctx context.Context, uid, aid int64) (domain.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Buy", ctx, uid, aid)
+	ret0, _ := ret[0].(domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Buy indicates an expected call of Buy.
+func (mr *MockOrderServiceMockRecorder) Buy(ctx, uid, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Buy", reflect.TypeOf((*MockOrderService)(nil).Buy), ctx, uid, aid)
+}
+
+// CanRead mocks base method.
+func (m *MockOrderService) CanRead(ctx context.Context, uid int64, art domain.Article) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanRead", ctx, uid, art)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CanRead indicates an expected call of CanRead.
+func (mr *MockOrderServiceMockRecorder) CanRead(ctx, uid, art any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanRead", reflect.TypeOf((*MockOrderService)(nil).CanRead), ctx, uid, art)
+}
+
+// GetBySN mocks base method.
+func (m *MockOrderService) GetBySN(ctx context.Context, uid int64, sn string) (domain.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBySN", ctx, uid, sn)
+	ret0, _ := ret[0].(domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBySN indicates an expected call of GetBySN.
+func (mr *MockOrderServiceMockRecorder) GetBySN(ctx, uid, sn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBySN", reflect.TypeOf((*MockOrderService)(nil).GetBySN), ctx, uid, sn)
+}
+
+// HandlePayment mocks base method.
+func (m *MockOrderService) HandlePayment(ctx context.Context, res payment.Result) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandlePayment", ctx, res)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandlePayment indicates an expected call of HandlePayment.
+func (mr *MockOrderServiceMockRecorder) HandlePayment(ctx, res any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePayment", reflect.TypeOf((*MockOrderService)(nil).HandlePayment), ctx, res)
+}
+
+// ListByUser mocks base method.
+func (m *MockOrderService) ListByUser(ctx context.Context, uid int64, offset, limit int) ([]domain.Order, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]domain.Order)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockOrderServiceMockRecorder) ListByUser(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockOrderService)(nil).ListByUser), ctx, uid, offset, limit)
+}
+
+// Reconcile mocks base method.
+func (m *MockOrderService) Reconcile(ctx context.Context, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockOrderServiceMockRecorder) Reconcile(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockOrderService)(nil).Reconcile), ctx, before)
+}
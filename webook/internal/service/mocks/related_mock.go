@@ -0,0 +1,69 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/related.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/related.go -destination=./internal/service/mocks/related_mock.go
+//
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRelatedArticleService is a mock of RelatedArticleService interface.
+type MockRelatedArticleService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRelatedArticleServiceMockRecorder
+}
+
+// MockRelatedArticleServiceMockRecorder is the mock recorder for MockRelatedArticleService.
+type MockRelatedArticleServiceMockRecorder struct {
+	mock *MockRelatedArticleService
+}
+
+// NewMockRelatedArticleService creates a new mock instance.
+func NewMockRelatedArticleService(ctrl *gomock.Controller) *MockRelatedArticleService {
+	mock := &MockRelatedArticleService{ctrl: ctrl}
+	mock.recorder = &MockRelatedArticleServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRelatedArticleService) EXPECT() *MockRelatedArticleServiceMockRecorder {
+	return m.recorder
+}
+
+// Compute mocks base method.
+func (m *MockRelatedArticleService) Compute(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compute", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Compute indicates an expected call of Compute.
+func (mr *MockRelatedArticleServiceMockRecorder) Compute(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compute", reflect.TypeOf((*MockRelatedArticleService)(nil).Compute), ctx)
+}
+
+// GetRelated mocks base method.
+func (m *MockRelatedArticleService) GetRelated(ctx context.Context, aid int64, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRelated", ctx, aid, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRelated indicates an expected call of GetRelated.
+func (mr *MockRelatedArticleServiceMockRecorder) GetRelated(ctx, aid, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRelated", reflect.TypeOf((*MockRelatedArticleService)(nil).GetRelated), ctx, aid, limit)
+}
@@ -0,0 +1,143 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/series.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/series.go -destination=./internal/service/mocks/series_mock.go
+//
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSeriesService is a mock of SeriesService interface.
+type MockSeriesService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSeriesServiceMockRecorder
+}
+
+// MockSeriesServiceMockRecorder is the mock recorder for MockSeriesService.
+type MockSeriesServiceMockRecorder struct {
+	mock *MockSeriesService
+}
+
+// NewMockSeriesService creates a new mock instance.
+func NewMockSeriesService(ctrl *gomock.Controller) *MockSeriesService {
+	mock := &MockSeriesService{ctrl: ctrl}
+	mock.recorder = &MockSeriesServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSeriesService) EXPECT() *MockSeriesServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSeriesService) Create(ctx context.Context, s domain.Series) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, s)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSeriesServiceMockRecorder) Create(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSeriesService)(nil).Create), ctx, s)
+}
+
+// Delete mocks base method.
+func (m *MockSeriesService) Delete(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSeriesServiceMockRecorder) Delete(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSeriesService)(nil).Delete), ctx, uid, id)
+}
+
+// Detail mocks base method.
+func (m *MockSeriesService) Detail(ctx context.Context, id int64) (domain.SeriesDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Detail", ctx, id)
+	ret0, _ := ret[0].(domain.SeriesDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Detail indicates an expected call of Detail.
+func (mr *MockSeriesServiceMockRecorder) Detail(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Detail", reflect.TypeOf((*MockSeriesService)(nil).Detail), ctx, id)
+}
+
+// ListByAuthor mocks base method.
+func (m *MockSeriesService) ListByAuthor(ctx context.Context, uid int64, offset, limit int) ([]domain.Series, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByAuthor", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]domain.Series)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListByAuthor indicates an expected call of ListByAuthor.
+func (mr *MockSeriesServiceMockRecorder) ListByAuthor(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByAuthor", reflect.TypeOf((*MockSeriesService)(nil).ListByAuthor), ctx, uid, offset, limit)
+}
+
+// Nav mocks base method.
+func (m *MockSeriesService) Nav(ctx context.Context, aid int64) (domain.SeriesNav, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Nav", ctx, aid)
+	ret0, _ := ret[0].(domain.SeriesNav)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Nav indicates an expected call of Nav.
+func (mr *MockSeriesServiceMockRecorder) Nav(ctx, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nav", reflect.TypeOf((*MockSeriesService)(nil).Nav), ctx, aid)
+}
+
+// SetArticles mocks base method.
+func (m *MockSeriesService) SetArticles(ctx context.Context, uid, sid int64, aids []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArticles", ctx, uid, sid, aids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetArticles indicates an expected call of SetArticles.
+func (mr *MockSeriesServiceMockRecorder) SetArticles(ctx, uid, sid, aids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArticles", reflect.TypeOf((*MockSeriesService)(nil).SetArticles), ctx, uid, sid, aids)
+}
+
+// Update mocks base method.
+func (m *MockSeriesService) Update(ctx context.Context, s domain.Series) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockSeriesServiceMockRecorder) Update(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSeriesService)(nil).Update), ctx, s)
+}
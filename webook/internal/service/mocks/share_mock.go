@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/share.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/share.go -destination=./internal/service/mocks/share_mock.go
+//
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleShareService is a mock of ArticleShareService interface.
+type MockArticleShareService struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleShareServiceMockRecorder
+}
+
+// MockArticleShareServiceMockRecorder is the mock recorder for MockArticleShareService.
+type MockArticleShareServiceMockRecorder struct {
+	mock *MockArticleShareService
+}
+
+// NewMockArticleShareService creates a new mock instance.
+func NewMockArticleShareService(ctrl *gomock.Controller) *MockArticleShareService {
+	mock := &MockArticleShareService{ctrl: ctrl}
+	mock.recorder = &MockArticleShareServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleShareService) EXPECT() *MockArticleShareServiceMockRecorder {
+	return m.recorder
+}
+
+// Access mocks base method.
+func (m *MockArticleShareService) Access(ctx context.Context, aid int64, token string, visitor domain.ShareVisitor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Access", ctx, aid, token, visitor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Access indicates an expected call of Access.
+func (mr *MockArticleShareServiceMockRecorder) Access(ctx, aid, token, visitor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Access", reflect.TypeOf((*MockArticleShareService)(nil).Access), ctx, aid, token, visitor)
+}
+
+// Create mocks base method.
+func (m *MockArticleShareService) Create(ctx context.Context, uid, aid int64, ttl time.Duration) (domain.ArticleShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, uid, aid, ttl)
+	ret0, _ := ret[0].(domain.ArticleShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockArticleShareServiceMockRecorder) Create(ctx, uid, aid, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockArticleShareService)(nil).Create), ctx, uid, aid, ttl)
+}
+
+// List mocks base method.
+func (m *MockArticleShareService) List(ctx context.Context, uid, aid int64) ([]domain.ArticleShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid, aid)
+	ret0, _ := ret[0].([]domain.ArticleShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockArticleShareServiceMockRecorder) List(ctx, uid, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockArticleShareService)(nil).List), ctx, uid, aid)
+}
+
+// ListAudits mocks base method.
+func (m *MockArticleShareService) ListAudits(ctx context.Context, uid, id int64, offset, limit int) ([]domain.ShareAudit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAudits", ctx, uid, id, offset, limit)
+	ret0, _ := ret[0].([]domain.ShareAudit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAudits indicates an expected call of ListAudits.
+func (mr *MockArticleShareServiceMockRecorder) ListAudits(ctx, uid, id, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAudits", reflect.TypeOf((*MockArticleShareService)(nil).ListAudits), ctx, uid, id, offset, limit)
+}
+
+// Revoke mocks base method.
+func (m *MockArticleShareService) Revoke(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockArticleShareServiceMockRecorder) Revoke(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockArticleShareService)(nil).Revoke), ctx, uid, id)
+}
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/syndication.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/syndication.go -destination=./internal/service/mocks/syndication_mock.go
+//
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/jayleonc/geektime-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSyndicationService is a mock of SyndicationService interface.
+type MockSyndicationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSyndicationServiceMockRecorder
+}
+
+// MockSyndicationServiceMockRecorder is the mock recorder for MockSyndicationService.
+type MockSyndicationServiceMockRecorder struct {
+	mock *MockSyndicationService
+}
+
+// NewMockSyndicationService creates a new mock instance.
+func NewMockSyndicationService(ctrl *gomock.Controller) *MockSyndicationService {
+	mock := &MockSyndicationService{ctrl: ctrl}
+	mock.recorder = &MockSyndicationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSyndicationService) EXPECT() *MockSyndicationServiceMockRecorder {
+	return m.recorder
+}
+
+// AuthorFeed mocks base method.
+func (m *MockSyndicationService) AuthorFeed(ctx context.Context, uid int64, format domain.SyndicationFormat) (domain.SyndicationDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorFeed", ctx, uid, format)
+	ret0, _ := ret[0].(domain.SyndicationDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorFeed indicates an expected call of AuthorFeed.
+func (mr *MockSyndicationServiceMockRecorder) AuthorFeed(ctx, uid, format any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorFeed", reflect.TypeOf((*MockSyndicationService)(nil).AuthorFeed), ctx, uid, format)
+}
+
+// HotFeed mocks base method.
+func (m *MockSyndicationService) HotFeed(ctx context.Context, format domain.SyndicationFormat) (domain.SyndicationDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HotFeed", ctx, format)
+	ret0, _ := ret[0].(domain.SyndicationDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HotFeed indicates an expected call of HotFeed.
+func (mr *MockSyndicationServiceMockRecorder) HotFeed(ctx, format any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HotFeed", reflect.TypeOf((*MockSyndicationService)(nil).HotFeed), ctx, format)
+}
+
+// InvalidateAuthor mocks base method.
+func (m *MockSyndicationService) InvalidateAuthor(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateAuthor", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateAuthor indicates an expected call of InvalidateAuthor.
+func (mr *MockSyndicationServiceMockRecorder) InvalidateAuthor(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateAuthor", reflect.TypeOf((*MockSyndicationService)(nil).InvalidateAuthor), ctx, uid)
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/internal/service/payment"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOrderNotFound = repository.ErrOrderNotFound
+	// ErrArticleFree 免费文章，或者是自己的文章，不用买
+	ErrArticleFree      = errors.New("文章不用购买")
+	ErrArticlePurchased = errors.New("已经买过这篇文章了")
+	// ErrInvalidPayment 支付结果和订单对不上，比如金额不一样
+	ErrInvalidPayment = errors.New("支付结果和订单对不上")
+)
+
+// reconcileBatchSize 对账每一批查询的订单数
+const reconcileBatchSize = 100
+
+// OrderService 购买付费文章。支付结果以网关的回调为准，回调丢了的靠 Reconcile 对账补上
+type OrderService interface {
+	// Buy 下单。有还没有支付结果的订单，并且价格没有变的时候，直接返回那个订单
+	Buy(ctx context.Context, uid, aid int64) (domain.Order, error)
+	// HandlePayment 处理支付结果，重复处理同一个结果不会报错
+	HandlePayment(ctx context.Context, res payment.Result) error
+	// CanRead 能不能看全文，免费文章、作者自己和买过的人都可以
+	CanRead(ctx context.Context, uid int64, art domain.Article) (bool, error)
+	// GetBySN 只能查自己的订单，别人的返回 ErrOrderNotFound
+	GetBySN(ctx context.Context, uid int64, sn string) (domain.Order, error)
+	ListByUser(ctx context.Context, uid int64, offset, limit int) ([]domain.Order, int64, error)
+	// Reconcile 对账。before 之前下单还没有结果的订单，主动向网关查询结果，
+	// 没有在网关下单成功的重新下单，超时还没有付钱的关掉
+	Reconcile(ctx context.Context, before time.Time) error
+}
+
+type orderService struct {
+	repo    repository.OrderRepository
+	artRepo repository.ArticleRepository
+	gateway payment.Gateway
+	l       logger.Logger
+	// timeout 下单之后多久还没有付钱就关掉
+	timeout time.Duration
+}
+
+func NewOrderService(repo repository.OrderRepository, artRepo repository.ArticleRepository,
+	gateway payment.Gateway, l logger.Logger, timeout time.Duration) OrderService {
+	return &orderService{
+		repo:    repo,
+		artRepo: artRepo,
+		gateway: gateway,
+		l:       l,
+		timeout: timeout,
+	}
+}
+
+func (s *orderService) Buy(ctx context.Context, uid, aid int64) (domain.Order, error) {
+	art, err := s.artRepo.GetPubById(ctx, aid)
+	if errors.Is(err, repository.ErrPubArticleNotFound) ||
+		(err == nil && art.Status != domain.ArticleStatusPublished) {
+		return domain.Order{}, ErrArticleNotFound
+	}
+	if err != nil {
+		return domain.Order{}, err
+	}
+	if !art.Paid() || art.Author.Id == uid {
+		return domain.Order{}, ErrArticleFree
+	}
+	paid, err := s.repo.HasPaid(ctx, uid, aid)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	if paid {
+		return domain.Order{}, ErrArticlePurchased
+	}
+
+	o, err := s.repo.FindInit(ctx, uid, aid)
+	switch {
+	case err == nil && o.Amount == art.Price && time.Since(o.Ctime) < s.timeout:
+		if o.PayURL != "" {
+			return o, nil
+		}
+		// 上次在网关下单失败了，同一个单号再试一次
+		return s.prepay(ctx, o)
+	case err != nil && !errors.Is(err, repository.ErrOrderNotFound):
+		return domain.Order{}, err
+	}
+	// 价格变了或者快要超时的旧订单留给对账去关
+	o = domain.Order{
+		SN:     strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Uid:    uid,
+		Aid:    aid,
+		Amount: art.Price,
+		Status: domain.OrderStatusInit,
+	}
+	o.Id, err = s.repo.Create(ctx, o)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return s.prepay(ctx, o)
+}
+
+func (s *orderService) prepay(ctx context.Context, o domain.Order) (domain.Order, error) {
+	payURL, err := s.gateway.Prepay(ctx, payment.PrepayRequest{
+		SN:          o.SN,
+		Amount:      o.Amount,
+		Description: fmt.Sprintf("购买文章 %d", o.Aid),
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	if err = s.repo.SetPayURL(ctx, o.Id, payURL); err != nil {
+		return domain.Order{}, err
+	}
+	o.PayURL = payURL
+	return o, nil
+}
+
+func (s *orderService) HandlePayment(ctx context.Context, res payment.Result) error {
+	o, err := s.repo.GetBySN(ctx, res.SN)
+	if err != nil {
+		return err
+	}
+	if res.Amount != o.Amount {
+		s.l.Error("支付金额和订单金额不一样",
+			logger.String("sn", res.SN),
+			logger.Int64("amount", o.Amount),
+			logger.Int64("paid", res.Amount))
+		return ErrInvalidPayment
+	}
+	switch res.Status {
+	case payment.StatusPaid:
+		err = s.repo.TransitStatus(ctx, o.SN, domain.OrderStatusInit, domain.OrderStatusPaid, res.TxnId)
+		if errors.Is(err, repository.ErrOrderStatusMismatch) {
+			// 超时关掉之后用户才付的钱，钱已经收了，还是算买了
+			err = s.repo.TransitStatus(ctx, o.SN, domain.OrderStatusClosed, domain.OrderStatusPaid, res.TxnId)
+		}
+	case payment.StatusFailed:
+		err = s.repo.TransitStatus(ctx, o.SN, domain.OrderStatusInit, domain.OrderStatusFailed, res.TxnId)
+	default:
+		// 还没有付钱
+		return nil
+	}
+	if errors.Is(err, repository.ErrOrderStatusMismatch) {
+		// 重复的回调，或者对账已经处理过了
+		return nil
+	}
+	return err
+}
+
+func (s *orderService) CanRead(ctx context.Context, uid int64, art domain.Article) (bool, error) {
+	if !art.Paid() || art.Author.Id == uid {
+		return true, nil
+	}
+	return s.repo.HasPaid(ctx, uid, art.Id)
+}
+
+func (s *orderService) GetBySN(ctx context.Context, uid int64, sn string) (domain.Order, error) {
+	o, err := s.repo.GetBySN(ctx, sn)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	if o.Uid != uid {
+		return domain.Order{}, ErrOrderNotFound
+	}
+	return o, nil
+}
+
+func (s *orderService) ListByUser(ctx context.Context, uid int64, offset, limit int) ([]domain.Order, int64, error) {
+	return s.repo.ListByUser(ctx, uid, offset, limit)
+}
+
+func (s *orderService) Reconcile(ctx context.Context, before time.Time) error {
+	var minId int64
+	for {
+		orders, err := s.repo.FindInitBefore(ctx, before, minId, reconcileBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, o := range orders {
+			// 一个订单对不上不影响别的订单
+			if er := s.reconcile(ctx, o); er != nil {
+				s.l.Error("订单对账失败", logger.String("sn", o.SN), logger.Error(er))
+			}
+		}
+		if len(orders) < reconcileBatchSize {
+			return nil
+		}
+		minId = orders[len(orders)-1].Id
+	}
+}
+
+func (s *orderService) reconcile(ctx context.Context, o domain.Order) error {
+	res, err := s.gateway.Query(ctx, o.SN)
+	switch {
+	case err == nil && (res.Status == payment.StatusPaid || res.Status == payment.StatusFailed):
+		return s.HandlePayment(ctx, res)
+	case err != nil && !errors.Is(err, payment.ErrPaymentNotFound):
+		return err
+	}
+	if time.Since(o.Ctime) >= s.timeout {
+		err = s.repo.TransitStatus(ctx, o.SN, domain.OrderStatusInit, domain.OrderStatusClosed, "")
+		if errors.Is(err, repository.ErrOrderStatusMismatch) {
+			return nil
+		}
+		return err
+	}
+	if res.Status == payment.StatusUnknown {
+		// 网关那边没有这一笔，说明当时下单失败了
+		_, err = s.prepay(ctx, o)
+		return err
+	}
+	return nil
+}
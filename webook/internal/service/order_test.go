@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/jayleonc/geektime-go/webook/internal/service/payment"
+	mock_payment "github.com/jayleonc/geektime-go/webook/internal/service/payment/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func Test_orderService_Buy(t *testing.T) {
+	paidArt := domain.Article{
+		Id:     1,
+		Author: domain.Author{Id: 456},
+		Status: domain.ArticleStatusPublished,
+		Price:  100,
+	}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.OrderRepository,
+			repository.ArticleRepository, payment.Gateway)
+		uid     int64
+		check   func(t *testing.T, o domain.Order)
+		wantErr error
+	}{
+		{
+			name: "新下单",
+			mock: func(ctrl *gomock.Controller) (repository.OrderRepository,
+				repository.ArticleRepository, payment.Gateway) {
+				repo := mock_repository.NewMockOrderRepository(ctrl)
+				artRepo := mock_repository.NewMockArticleRepository(ctrl)
+				gw := mock_payment.NewMockGateway(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(paidArt, nil)
+				repo.EXPECT().HasPaid(gomock.Any(), int64(123), int64(1)).Return(false, nil)
+				repo.EXPECT().FindInit(gomock.Any(), int64(123), int64(1)).
+					Return(domain.Order{}, repository.ErrOrderNotFound)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(int64(10), nil)
+				gw.EXPECT().Prepay(gomock.Any(), gomock.Any()).Return("https://pay/1", nil)
+				repo.EXPECT().SetPayURL(gomock.Any(), int64(10), "https://pay/1").Return(nil)
+				return repo, artRepo, gw
+			},
+			uid: 123,
+			check: func(t *testing.T, o domain.Order) {
+				assert.Equal(t, int64(10), o.Id)
+				assert.Equal(t, int64(100), o.Amount)
+				assert.Equal(t, domain.OrderStatusInit, o.Status)
+				assert.Equal(t, "https://pay/1", o.PayURL)
+				assert.NotEmpty(t, o.SN)
+			},
+		},
+		{
+			name: "复用还没有付钱的订单",
+			mock: func(ctrl *gomock.Controller) (repository.OrderRepository,
+				repository.ArticleRepository, payment.Gateway) {
+				repo := mock_repository.NewMockOrderRepository(ctrl)
+				artRepo := mock_repository.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(paidArt, nil)
+				repo.EXPECT().HasPaid(gomock.Any(), int64(123), int64(1)).Return(false, nil)
+				repo.EXPECT().FindInit(gomock.Any(), int64(123), int64(1)).Return(domain.Order{
+					Id: 9, SN: "old", Amount: 100, PayURL: "https://pay/old", Ctime: time.Now(),
+				}, nil)
+				return repo, artRepo, mock_payment.NewMockGateway(ctrl)
+			},
+			uid: 123,
+			check: func(t *testing.T, o domain.Order) {
+				assert.Equal(t, "old", o.SN)
+			},
+		},
+		{
+			name: "已经买过",
+			mock: func(ctrl *gomock.Controller) (repository.OrderRepository,
+				repository.ArticleRepository, payment.Gateway) {
+				repo := mock_repository.NewMockOrderRepository(ctrl)
+				artRepo := mock_repository.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(paidArt, nil)
+				repo.EXPECT().HasPaid(gomock.Any(), int64(123), int64(1)).Return(true, nil)
+				return repo, artRepo, mock_payment.NewMockGateway(ctrl)
+			},
+			uid:     123,
+			wantErr: ErrArticlePurchased,
+		},
+		{
+			name: "作者自己",
+			mock: func(ctrl *gomock.Controller) (repository.OrderRepository,
+				repository.ArticleRepository, payment.Gateway) {
+				artRepo := mock_repository.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(paidArt, nil)
+				return mock_repository.NewMockOrderRepository(ctrl), artRepo, mock_payment.NewMockGateway(ctrl)
+			},
+			uid:     456,
+			wantErr: ErrArticleFree,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo, gw := tc.mock(ctrl)
+			svc := NewOrderService(repo, artRepo, gw, logger.NewNopLogger(), time.Minute*30)
+			o, err := svc.Buy(context.Background(), tc.uid, 1)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.check != nil {
+				tc.check(t, o)
+			}
+		})
+	}
+}
+
+func Test_orderService_HandlePayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockOrderRepository(ctrl)
+	svc := NewOrderService(repo, mock_repository.NewMockArticleRepository(ctrl),
+		mock_payment.NewMockGateway(ctrl), logger.NewNopLogger(), time.Minute*30)
+	order := domain.Order{SN: "sn1", Amount: 100, Status: domain.OrderStatusInit}
+
+	// 金额对不上
+	repo.EXPECT().GetBySN(gomock.Any(), "sn1").Return(order, nil)
+	err := svc.HandlePayment(context.Background(), payment.Result{SN: "sn1", Amount: 1, Status: payment.StatusPaid})
+	assert.Equal(t, ErrInvalidPayment, err)
+
+	// 超时关掉之后才付的钱
+	repo.EXPECT().GetBySN(gomock.Any(), "sn1").Return(order, nil)
+	repo.EXPECT().TransitStatus(gomock.Any(), "sn1", domain.OrderStatusInit, domain.OrderStatusPaid, "txn").
+		Return(repository.ErrOrderStatusMismatch)
+	repo.EXPECT().TransitStatus(gomock.Any(), "sn1", domain.OrderStatusClosed, domain.OrderStatusPaid, "txn").
+		Return(nil)
+	err = svc.HandlePayment(context.Background(), payment.Result{SN: "sn1", TxnId: "txn", Amount: 100, Status: payment.StatusPaid})
+	assert.NoError(t, err)
+
+	// 重复的回调
+	repo.EXPECT().GetBySN(gomock.Any(), "sn1").Return(order, nil)
+	repo.EXPECT().TransitStatus(gomock.Any(), "sn1", domain.OrderStatusInit, domain.OrderStatusPaid, "txn").
+		Return(repository.ErrOrderStatusMismatch)
+	repo.EXPECT().TransitStatus(gomock.Any(), "sn1", domain.OrderStatusClosed, domain.OrderStatusPaid, "txn").
+		Return(repository.ErrOrderStatusMismatch)
+	err = svc.HandlePayment(context.Background(), payment.Result{SN: "sn1", TxnId: "txn", Amount: 100, Status: payment.StatusPaid})
+	assert.NoError(t, err)
+}
+
+func Test_orderService_Reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockOrderRepository(ctrl)
+	gw := mock_payment.NewMockGateway(ctrl)
+	svc := NewOrderService(repo, mock_repository.NewMockArticleRepository(ctrl), gw,
+		logger.NewNopLogger(), time.Minute*30)
+
+	before := time.Now().Add(-time.Minute)
+	repo.EXPECT().FindInitBefore(gomock.Any(), before, int64(0), reconcileBatchSize).Return([]domain.Order{
+		// 回调丢了
+		{Id: 1, SN: "paid", Amount: 100, Ctime: time.Now().Add(-time.Minute * 5)},
+		// 超时没有付钱
+		{Id: 2, SN: "timeout", Amount: 100, Ctime: time.Now().Add(-time.Hour)},
+		// 当时在网关下单失败了
+		{Id: 3, SN: "lost", Aid: 7, Amount: 100, Ctime: time.Now().Add(-time.Minute * 5)},
+		// 还在等用户付钱
+		{Id: 4, SN: "pending", Amount: 100, Ctime: time.Now().Add(-time.Minute * 5)},
+	}, nil)
+
+	gw.EXPECT().Query(gomock.Any(), "paid").
+		Return(payment.Result{SN: "paid", TxnId: "txn", Amount: 100, Status: payment.StatusPaid}, nil)
+	repo.EXPECT().GetBySN(gomock.Any(), "paid").Return(domain.Order{SN: "paid", Amount: 100}, nil)
+	repo.EXPECT().TransitStatus(gomock.Any(), "paid", domain.OrderStatusInit, domain.OrderStatusPaid, "txn").Return(nil)
+
+	gw.EXPECT().Query(gomock.Any(), "timeout").
+		Return(payment.Result{SN: "timeout", Amount: 100, Status: payment.StatusPending}, nil)
+	repo.EXPECT().TransitStatus(gomock.Any(), "timeout", domain.OrderStatusInit, domain.OrderStatusClosed, "").Return(nil)
+
+	gw.EXPECT().Query(gomock.Any(), "lost").Return(payment.Result{}, payment.ErrPaymentNotFound)
+	gw.EXPECT().Prepay(gomock.Any(), payment.PrepayRequest{SN: "lost", Amount: 100, Description: "购买文章 7"}).
+		Return("https://pay/lost", nil)
+	repo.EXPECT().SetPayURL(gomock.Any(), int64(3), "https://pay/lost").Return(nil)
+
+	gw.EXPECT().Query(gomock.Any(), "pending").
+		Return(payment.Result{SN: "pending", Amount: 100, Status: payment.StatusPending}, nil)
+
+	assert.NoError(t, svc.Reconcile(context.Background(), before))
+}
@@ -0,0 +1,170 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jayleonc/geektime-go/webook/internal/service/payment"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// SignatureHeader 回调请求里面放签名的头部
+const SignatureHeader = "X-Local-Pay-Signature"
+
+// maxNotifySize 回调的内容很小，超过这个大小的直接当成不合法
+const maxNotifySize = 4096
+
+// Gateway 本地的支付网关，开发和测试的时候用来代替真的支付渠道。
+// 下单之后过 delay 就当作用户付了钱，然后像真的网关一样异步回调 notifyURL。
+// 有 dropRate 比例的回调会被故意丢掉，用来演练对账
+type Gateway struct {
+	notifyURL string
+	secret    []byte
+	delay     time.Duration
+	dropRate  float64
+	client    *http.Client
+	l         logger.Logger
+
+	mu       sync.RWMutex
+	payments map[string]payment.Result
+}
+
+func NewGateway(notifyURL string, secret []byte, delay time.Duration, dropRate float64, l logger.Logger) *Gateway {
+	return &Gateway{
+		notifyURL: notifyURL,
+		secret:    secret,
+		delay:     delay,
+		dropRate:  dropRate,
+		client:    &http.Client{Timeout: time.Second * 3},
+		l:         l,
+		payments:  make(map[string]payment.Result),
+	}
+}
+
+func (g *Gateway) Prepay(ctx context.Context, req payment.PrepayRequest) (string, error) {
+	if req.SN == "" || req.Amount <= 0 {
+		return "", errors.New("单号或者金额不对")
+	}
+	g.mu.Lock()
+	_, ok := g.payments[req.SN]
+	if !ok {
+		g.payments[req.SN] = payment.Result{
+			SN:     req.SN,
+			TxnId:  "local_" + uuid.New().String(),
+			Amount: req.Amount,
+			Status: payment.StatusPending,
+		}
+	}
+	g.mu.Unlock()
+	if !ok {
+		time.AfterFunc(g.delay, func() {
+			g.pay(req.SN)
+		})
+	}
+	// 本地网关没有收银台，这个链接只是占个位置
+	return "https://pay.local/checkout?sn=" + url.QueryEscape(req.SN), nil
+}
+
+func (g *Gateway) Query(ctx context.Context, sn string) (payment.Result, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	res, ok := g.payments[sn]
+	if !ok {
+		return payment.Result{}, payment.ErrPaymentNotFound
+	}
+	return res, nil
+}
+
+func (g *Gateway) ParseNotify(req *http.Request) (payment.Result, error) {
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxNotifySize+1))
+	if err != nil {
+		return payment.Result{}, err
+	}
+	if len(body) > maxNotifySize {
+		return payment.Result{}, payment.ErrInvalidNotify
+	}
+	sig := req.Header.Get(SignatureHeader)
+	if !hmac.Equal([]byte(sig), []byte(g.sign(body))) {
+		return payment.Result{}, payment.ErrInvalidNotify
+	}
+	var n notify
+	if err = json.Unmarshal(body, &n); err != nil {
+		return payment.Result{}, payment.ErrInvalidNotify
+	}
+	return payment.Result{
+		SN:     n.SN,
+		TxnId:  n.TxnId,
+		Amount: n.Amount,
+		Status: payment.Status(n.Status),
+	}, nil
+}
+
+// pay 模拟用户付款成功
+func (g *Gateway) pay(sn string) {
+	g.mu.Lock()
+	res := g.payments[sn]
+	res.Status = payment.StatusPaid
+	g.payments[sn] = res
+	g.mu.Unlock()
+
+	if rand.Float64() < g.dropRate {
+		g.l.Info("故意丢掉支付回调", logger.String("sn", sn))
+		return
+	}
+	if err := g.notify(res); err != nil {
+		// 真的网关会重试，这里就交给对账
+		g.l.Error("支付回调失败", logger.String("sn", sn), logger.Error(err))
+	}
+}
+
+func (g *Gateway) notify(res payment.Result) error {
+	body, err := json.Marshal(notify{
+		SN:     res.SN,
+		TxnId:  res.TxnId,
+		Amount: res.Amount,
+		Status: uint8(res.Status),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, g.notifyURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, g.sign(body))
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("支付回调返回了 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (g *Gateway) sign(body []byte) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type notify struct {
+	SN     string `json:"sn"`
+	TxnId  string `json:"txnId"`
+	Amount int64  `json:"amount"`
+	Status uint8  `json:"status"`
+}
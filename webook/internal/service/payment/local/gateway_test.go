@@ -0,0 +1,69 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/service/payment"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGateway(t *testing.T) {
+	var gw *Gateway
+	notified := make(chan payment.Result, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := gw.ParseNotify(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		notified <- res
+	}))
+	defer server.Close()
+	gw = NewGateway(server.URL, []byte("secret"), time.Millisecond*10, 0, logger.NewNopLogger())
+
+	_, err := gw.Query(context.Background(), "sn1")
+	assert.Equal(t, payment.ErrPaymentNotFound, err)
+
+	_, err = gw.Prepay(context.Background(), payment.PrepayRequest{SN: "sn1", Amount: 100})
+	require.NoError(t, err)
+	res, err := gw.Query(context.Background(), "sn1")
+	require.NoError(t, err)
+	assert.Equal(t, payment.StatusPending, res.Status)
+
+	select {
+	case res = <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("没有收到回调")
+	}
+	assert.Equal(t, "sn1", res.SN)
+	assert.Equal(t, int64(100), res.Amount)
+	assert.Equal(t, payment.StatusPaid, res.Status)
+	assert.NotEmpty(t, res.TxnId)
+
+	res, err = gw.Query(context.Background(), "sn1")
+	require.NoError(t, err)
+	assert.Equal(t, payment.StatusPaid, res.Status)
+}
+
+func TestGateway_ParseNotify(t *testing.T) {
+	gw := NewGateway("", []byte("secret"), time.Second, 0, logger.NewNopLogger())
+	body := []byte(`{"sn":"sn1","txnId":"txn","amount":100,"status":2}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/pay/notify", bytes.NewReader(body))
+	req.Header.Set(SignatureHeader, gw.sign(body))
+	res, err := gw.ParseNotify(req)
+	require.NoError(t, err)
+	assert.Equal(t, payment.Result{SN: "sn1", TxnId: "txn", Amount: 100, Status: payment.StatusPaid}, res)
+
+	// 签名不对
+	req = httptest.NewRequest(http.MethodPost, "/pay/notify", bytes.NewReader(body))
+	req.Header.Set(SignatureHeader, "bad")
+	_, err = gw.ParseNotify(req)
+	assert.Equal(t, payment.ErrInvalidNotify, err)
+}
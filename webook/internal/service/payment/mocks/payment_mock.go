@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/payment/types.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/payment/types.go -destination=./internal/service/payment/mocks/payment_mock.go
+//
+// Package mock_payment is a generated GoMock package.
+package mock_payment
+
+import (
+	context "context"
+	http "net/http"
+	reflect "reflect"
+
+	payment "github.com/jayleonc/geektime-go/webook/internal/service/payment"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockGateway is a mock of Gateway interface.
+type MockGateway struct {
+	ctrl     *gomock.Controller
+	recorder *MockGatewayMockRecorder
+}
+
+// MockGatewayMockRecorder is the mock recorder for MockGateway.
+type MockGatewayMockRecorder struct {
+	mock *MockGateway
+}
+
+// NewMockGateway creates a new mock instance.
+func NewMockGateway(ctrl *gomock.Controller) *MockGateway {
+	mock := &MockGateway{ctrl: ctrl}
+	mock.recorder = &MockGatewayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGateway) EXPECT() *MockGatewayMockRecorder {
+	return m.recorder
+}
+
+// ParseNotify mocks base method.
+func (m *MockGateway) ParseNotify(req *http.Request) (payment.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseNotify", req)
+	ret0, _ := ret[0].(payment.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseNotify indicates an expected call of ParseNotify.
+func (mr *MockGatewayMockRecorder) ParseNotify(req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseNotify", reflect.TypeOf((*MockGateway)(nil).ParseNotify), req)
+}
+
+// Prepay mocks base method.
+func (m *MockGateway) Prepay(ctx context.Context, req payment.PrepayRequest) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prepay", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prepay indicates an expected call of Prepay.
+func (mr *MockGatewayMockRecorder) Prepay(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prepay", reflect.TypeOf((*MockGateway)(nil).Prepay), ctx, req)
+}
+
+// Query mocks base method.
+func (m *MockGateway) Query(ctx context.Context, sn string) (payment.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, sn)
+	ret0, _ := ret[0].(payment.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockGatewayMockRecorder) Query(ctx, sn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockGateway)(nil).Query), ctx, sn)
+}
@@ -0,0 +1,51 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	// ErrPaymentNotFound 支付网关那边没有这一笔
+	ErrPaymentNotFound = errors.New("支付记录不存在")
+	// ErrInvalidNotify 回调的签名或者内容不对
+	ErrInvalidNotify = errors.New("支付回调不合法")
+)
+
+// Gateway 支付网关的抽象，屏蔽不同支付渠道之间的区别
+type Gateway interface {
+	// Prepay 在支付网关下单，返回让用户去支付的链接。同一个 SN 重复下单是幂等的
+	Prepay(ctx context.Context, req PrepayRequest) (string, error)
+	// Query 主动查询支付结果，回调丢了的时候对账用
+	Query(ctx context.Context, sn string) (Result, error)
+	// ParseNotify 校验并解析支付网关的异步回调
+	ParseNotify(req *http.Request) (Result, error)
+}
+
+type PrepayRequest struct {
+	// SN 业务单号
+	SN string
+	// Amount 金额，单位是分
+	Amount      int64
+	Description string
+}
+
+type Status uint8
+
+const (
+	StatusUnknown Status = iota
+	// StatusPending 用户还没有付钱
+	StatusPending
+	StatusPaid
+	// StatusFailed 支付失败或者被用户取消了
+	StatusFailed
+)
+
+type Result struct {
+	SN string
+	// TxnId 支付网关的流水号
+	TxnId  string
+	Amount int64
+	Status Status
+}
@@ -14,6 +14,8 @@ import (
 type RankingService interface {
 	TopN(ctx context.Context) error
 	GetTopN(ctx context.Context) ([]domain.Article, error)
+	// GetTopNByTag 某个标签下的热榜，只有热门标签才有
+	GetTopNByTag(ctx context.Context, tag string) ([]domain.Article, error)
 }
 
 type BatchRankingService struct {
@@ -21,6 +23,8 @@ type BatchRankingService struct {
 	artSvc    ArticleService
 	batchSize int
 	n         int
+	// tagN 计算文章数最多的 tagN 个标签的热榜
+	tagN      int
 	scoreFunc func(likeCnt int64, utime time.Time) float64
 
 	repo repository.RankingRepository
@@ -30,12 +34,17 @@ func (b *BatchRankingService) GetTopN(ctx context.Context) ([]domain.Article, er
 	return b.repo.GetTopN(ctx)
 }
 
+func (b *BatchRankingService) GetTopNByTag(ctx context.Context, tag string) ([]domain.Article, error) {
+	return b.repo.GetTopNByTag(ctx, tag)
+}
+
 func NewBatchRankingService(interSvc intrv1.InteractiveServiceClient, artSvc ArticleService, repo repository.RankingRepository) RankingService {
 	return &BatchRankingService{
 		interSvc:  interSvc,
 		artSvc:    artSvc,
 		batchSize: 100,
 		n:         100,
+		tagN:      10,
 		scoreFunc: func(likeCnt int64, utime time.Time) float64 {
 			duration := time.Since(utime).Seconds()
 			return float64(likeCnt-1) / math.Pow(duration*2, 1.5)
@@ -49,13 +58,57 @@ func (b *BatchRankingService) TopN(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	return b.repo.ReplaceTopN(ctx, articles)
+	err = b.repo.ReplaceTopN(ctx, articles)
+	if err != nil {
+		return err
+	}
+	return b.tagTopN(ctx)
+}
+
+// tagTopN 计算热门标签各自的热榜
+func (b *BatchRankingService) tagTopN(ctx context.Context) error {
+	if b.tagN <= 0 {
+		return nil
+	}
+	tags, err := b.artSvc.ListTags(ctx, 0, b.tagN)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		articles, err := b.topNByTag(ctx, tag.Name)
+		if err != nil {
+			return err
+		}
+		err = b.repo.ReplaceTopNByTag(ctx, tag.Name, articles)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error) {
-	offset := 0
+	return b.topNFrom(ctx, b.artSvc.ListPubByCursor)
+}
+
+func (b *BatchRankingService) topNByTag(ctx context.Context, tag string) ([]domain.Article, error) {
+	// 标签下的文章还是按照偏移量翻页
 	start := time.Now()
-	ddl := start.Add(-7 * 24 * time.Hour)
+	offset := 0
+	return b.topNFrom(ctx, func(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+		arts, err := b.artSvc.ListPubByTag(ctx, tag, start, offset, limit)
+		offset += len(arts)
+		return arts, err
+	})
+}
+
+// topNFrom 从 listPub 分批取出文章，计算得分最高的 n 篇。
+// listPub 要按照 Utime 倒序返回文章
+func (b *BatchRankingService) topNFrom(ctx context.Context,
+	listPub func(ctx context.Context, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)) ([]domain.Article, error) {
+	// 只计算开始之前更新的文章
+	cursor := domain.ArticleCursor{Utime: time.Now()}
+	ddl := cursor.Utime.Add(-7 * 24 * time.Hour)
 
 	type Score struct {
 		score float64
@@ -74,7 +127,7 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 
 	for {
 		// 取数据
-		arts, err := b.artSvc.ListPub(ctx, start, offset, b.batchSize)
+		arts, err := listPub(ctx, cursor, b.batchSize)
 		if err != nil {
 			return nil, err
 		}
@@ -114,7 +167,6 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 				}
 			}
 		}
-		offset = offset + len(arts)
 		// 没有取够一批，我们就直接中断执行
 		// 没有下一批了
 		if len(arts) < b.batchSize ||
@@ -122,6 +174,8 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 			arts[len(arts)-1].Utime.Before(ddl) {
 			break
 		}
+		last := arts[len(arts)-1]
+		cursor = domain.ArticleCursor{Utime: last.Utime, Id: last.Id}
 	}
 
 	// 这边 topN 里面就是最终结果
@@ -2,8 +2,8 @@ package service
 
 import (
 	"context"
-	domain2 "github.com/jayleonc/geektime-go/webook/interactive/domain"
-	"github.com/jayleonc/geektime-go/webook/interactive/service"
+	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
+	mock_intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1/mocks"
 	"github.com/jayleonc/geektime-go/webook/internal/domain"
 	mock_service "github.com/jayleonc/geektime-go/webook/internal/service/mocks"
 	"github.com/stretchr/testify/assert"
@@ -17,48 +17,56 @@ func TestBatchRankingService_topN(t *testing.T) {
 	now := time.Now()
 	tests := []struct {
 		name    string
-		mock    func(*gomock.Controller) (service.InteractiveService, ArticleService)
+		mock    func(*gomock.Controller) (intrv1.InteractiveServiceClient, ArticleService)
 		want    []domain.Article
 		wantErr error
 	}{
 		{
 			name: "成功获取",
-			mock: func(controller *gomock.Controller) (service.InteractiveService, ArticleService) {
-				intrSvc := mock_service.NewMockInteractiveService(controller)
+			mock: func(controller *gomock.Controller) (intrv1.InteractiveServiceClient, ArticleService) {
+				intrSvc := mock_intrv1.NewMockInteractiveServiceClient(controller)
 				artSvc := mock_service.NewMockArticleService(controller)
 				// 先模拟批量获取数据
 				// 先模拟第一批
-				artSvc.EXPECT().ListPub(gomock.Any(), gomock.Any(), 0, 2).
+				artSvc.EXPECT().ListPubByCursor(gomock.Any(), gomock.Any(), 2).
 					Return([]domain.Article{
 						{Id: 1, Utime: now},
 						{Id: 2, Utime: now},
 					}, nil)
 				// 模拟第二批
-				artSvc.EXPECT().ListPub(gomock.Any(), gomock.Any(), 2, 2).
+				// 从第一批的最后一篇开始
+				artSvc.EXPECT().ListPubByCursor(gomock.Any(), domain.ArticleCursor{Utime: now, Id: 2}, 2).
 					Return([]domain.Article{
 						{Id: 3, Utime: now},
 						{Id: 4, Utime: now},
 					}, nil)
 				// 模拟第三批
-				artSvc.EXPECT().ListPub(gomock.Any(), gomock.Any(), 4, 2).
+				artSvc.EXPECT().ListPubByCursor(gomock.Any(), domain.ArticleCursor{Utime: now, Id: 4}, 2).
 					// 没数据了
 					Return([]domain.Article{}, nil)
 
 				// 第一批的点赞数据
-				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2}).
-					Return(map[int64]domain2.Interactive{
+				intrSvc.EXPECT().GetByIds(gomock.Any(), &intrv1.GetByIdsRequest{
+					Biz: "article", Ids: []int64{1, 2},
+				}).Return(&intrv1.GetByIdsResponse{
+					Intrs: map[int64]*intrv1.Interactive{
 						1: {LikeCnt: 1},
 						2: {LikeCnt: 2},
-					}, nil)
+					},
+				}, nil)
 				// 第二批的点赞数据
-				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{3, 4}).
-					Return(map[int64]domain2.Interactive{
+				intrSvc.EXPECT().GetByIds(gomock.Any(), &intrv1.GetByIdsRequest{
+					Biz: "article", Ids: []int64{3, 4},
+				}).Return(&intrv1.GetByIdsResponse{
+					Intrs: map[int64]*intrv1.Interactive{
 						3: {LikeCnt: 3},
 						4: {LikeCnt: 4},
-					}, nil)
+					},
+				}, nil)
 				// 第三批的点赞数据
-				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{}).
-					Return(map[int64]domain2.Interactive{}, nil)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), &intrv1.GetByIdsRequest{
+					Biz: "article", Ids: []int64{},
+				}).Return(&intrv1.GetByIdsResponse{}, nil)
 
 				return intrSvc, artSvc
 			},
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/searchx"
+	"github.com/jayleonc/geektime-go/webook/pkg/tfidf"
+	"golang.org/x/sync/errgroup"
+	"math"
+	"sort"
+	"time"
+)
+
+// RelatedArticleService 文章详情页的相关文章。
+// 定时任务综合内容相似度（TF-IDF）和共同阅读算出每篇文章的 top K 保存下来，在线只查结果
+type RelatedArticleService interface {
+	// Compute 重新计算最近发表的文章的相关文章
+	Compute(ctx context.Context) error
+	// GetRelated 最多 limit 篇，已经下线的文章会被跳过
+	GetRelated(ctx context.Context, aid int64, limit int) ([]domain.Article, error)
+}
+
+type relatedArticleService struct {
+	repo        repository.RelatedArticleRepository
+	artRepo     repository.ArticleRepository
+	historyRepo repository.ReadHistoryRepository
+	l           logger.Logger
+
+	// k 每篇文章保存的相关文章数
+	k int
+	// maxArticles 只算最近发表的这么多篇，更早的文章保留上一次的结果
+	maxArticles int
+	// coReadWindow 只用这段时间里面的阅读记录
+	coReadWindow time.Duration
+	// maxReadsPerUser 每个读者只用最近看的这么多篇，不然一个人看得多就会贡献大量的组合
+	maxReadsPerUser int
+	// minCoRead 至少有这么多读者都看过两篇文章，才算共同阅读
+	minCoRead     int
+	contentWeight float64
+	coReadWeight  float64
+	tfidfOpts     tfidf.Options
+}
+
+func NewRelatedArticleService(repo repository.RelatedArticleRepository, artRepo repository.ArticleRepository,
+	historyRepo repository.ReadHistoryRepository, l logger.Logger) RelatedArticleService {
+	return &relatedArticleService{
+		repo:            repo,
+		artRepo:         artRepo,
+		historyRepo:     historyRepo,
+		l:               l,
+		k:               10,
+		maxArticles:     10000,
+		coReadWindow:    time.Hour * 24 * 30,
+		maxReadsPerUser: 50,
+		minCoRead:       2,
+		contentWeight:   0.6,
+		coReadWeight:    0.4,
+		tfidfOpts: tfidf.Options{
+			MaxTerms: 64,
+			MaxDF:    0.5,
+		},
+	}
+}
+
+func (s *relatedArticleService) Compute(ctx context.Context) error {
+	arts, err := s.loadArticles(ctx)
+	if err != nil {
+		return err
+	}
+	docs := make([]tfidf.Document, 0, len(arts))
+	known := make(map[int64]struct{}, len(arts))
+	for _, art := range arts {
+		tokens := searchx.Tokenize(art.Title + "\n" + art.Content)
+		docs = append(docs, tfidf.Document{
+			Id: art.Id,
+			Terms: slice.Map(tokens, func(idx int, src searchx.Token) string {
+				return src.Term
+			}),
+		})
+		known[art.Id] = struct{}{}
+	}
+	model := tfidf.Build(docs, s.tfidfOpts)
+	coRead, err := s.coRead(ctx, known)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		scores := make(map[int64]float64)
+		// 多取一些候选，和共同阅读合并之后再截断
+		for _, sc := range model.Similar(doc.Id, s.k*2) {
+			scores[sc.Id] += s.contentWeight * sc.Score
+		}
+		for id, score := range coRead[doc.Id] {
+			scores[id] += s.coReadWeight * score
+		}
+		rels := slice.Map(tfidf.TopK(scores, s.k), func(idx int, src tfidf.Scored) domain.RelatedArticle {
+			return domain.RelatedArticle{Id: src.Id, Score: src.Score}
+		})
+		if err = s.repo.ReplaceRelated(ctx, doc.Id, rels); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadArticles 最近发表的 maxArticles 篇文章，要按照内容算相似度，所以一定要带上内容
+func (s *relatedArticleService) loadArticles(ctx context.Context) ([]domain.Article, error) {
+	const batchSize = 100
+	var (
+		res    []domain.Article
+		cursor domain.ArticleCursor
+	)
+	for len(res) < s.maxArticles {
+		arts, err := s.artRepo.ListPubByCursorWithContent(ctx, cursor, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, art := range arts {
+			if art.Status == domain.ArticleStatusPublished {
+				res = append(res, art)
+			}
+		}
+		if len(arts) < batchSize {
+			break
+		}
+		last := arts[len(arts)-1]
+		cursor = domain.ArticleCursor{Utime: last.Utime, Id: last.Id}
+	}
+	if len(res) > s.maxArticles {
+		res = res[:s.maxArticles]
+	}
+	return res, nil
+}
+
+// coRead 两篇文章读者集合的余弦相似度，只算 known 里面的文章
+func (s *relatedArticleService) coRead(ctx context.Context, known map[int64]struct{}) (map[int64]map[int64]float64, error) {
+	const batchSize = 1000
+	reads := make(map[int64][]domain.ReadHistory)
+	since := time.Now().Add(-s.coReadWindow)
+	var minId int64
+	for {
+		hs, err := s.historyRepo.FindSince(ctx, since, minId, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hs {
+			if _, ok := known[h.Article.Id]; ok {
+				reads[h.Uid] = append(reads[h.Uid], h)
+			}
+		}
+		if len(hs) < batchSize {
+			break
+		}
+		minId = hs[len(hs)-1].Id
+	}
+
+	pairs := make(map[[2]int64]int)
+	readers := make(map[int64]int)
+	for _, hs := range reads {
+		sort.Slice(hs, func(i, j int) bool {
+			return hs[i].Rtime.After(hs[j].Rtime)
+		})
+		if len(hs) > s.maxReadsPerUser {
+			hs = hs[:s.maxReadsPerUser]
+		}
+		for i, a := range hs {
+			readers[a.Article.Id]++
+			for _, b := range hs[i+1:] {
+				pairs[coReadPair(a.Article.Id, b.Article.Id)]++
+			}
+		}
+	}
+
+	res := make(map[int64]map[int64]float64)
+	add := func(a, b int64, score float64) {
+		if res[a] == nil {
+			res[a] = make(map[int64]float64)
+		}
+		res[a][b] = score
+	}
+	for p, cnt := range pairs {
+		if cnt < s.minCoRead {
+			continue
+		}
+		score := float64(cnt) / math.Sqrt(float64(readers[p[0]])*float64(readers[p[1]]))
+		add(p[0], p[1], score)
+		add(p[1], p[0], score)
+	}
+	return res, nil
+}
+
+// coReadPair 小的 id 在前，同一对文章只算一次
+func coReadPair(a, b int64) [2]int64 {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int64{a, b}
+}
+
+func (s *relatedArticleService) GetRelated(ctx context.Context, aid int64, limit int) ([]domain.Article, error) {
+	rels, err := s.repo.GetRelated(ctx, aid)
+	if err != nil {
+		return nil, err
+	}
+	arts := make([]domain.Article, len(rels))
+	var eg errgroup.Group
+	for i := range rels {
+		i := i
+		eg.Go(func() error {
+			art, er := s.artRepo.GetPubById(ctx, rels[i].Id)
+			if errors.Is(er, repository.ErrPubArticleNotFound) {
+				return nil
+			}
+			arts[i] = art
+			return er
+		})
+	}
+	if err = eg.Wait(); err != nil {
+		return nil, err
+	}
+	res := make([]domain.Article, 0, limit)
+	for _, art := range arts {
+		if len(res) == limit {
+			break
+		}
+		if art.Id > 0 && art.Status == domain.ArticleStatusPublished {
+			res = append(res, art)
+		}
+	}
+	return res, nil
+}
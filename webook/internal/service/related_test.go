@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_relatedArticleService_Compute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockRelatedArticleRepository(ctrl)
+	artRepo := mock_repository.NewMockArticleRepository(ctrl)
+	historyRepo := mock_repository.NewMockReadHistoryRepository(ctrl)
+	svc := NewRelatedArticleService(repo, artRepo, historyRepo, logger.NewNopLogger())
+
+	var published domain.ArticleStatus = domain.ArticleStatusPublished
+	artRepo.EXPECT().ListPubByCursorWithContent(gomock.Any(), domain.ArticleCursor{}, 100).Return([]domain.Article{
+		{Id: 1, Title: "Redis 分布式锁", Content: "用 Redis 实现分布式锁", Status: published},
+		{Id: 2, Title: "分布式锁的续约", Content: "Redis 分布式锁需要续约", Status: published},
+		{Id: 3, Title: "MySQL 索引", Content: "B+ 树", Status: published},
+		{Id: 4, Title: "Kafka 消息", Content: "消费者组", Status: published},
+	}, nil)
+	rtime := time.Now()
+	historyRepo.EXPECT().FindSince(gomock.Any(), gomock.Any(), int64(0), 1000).Return([]domain.ReadHistory{
+		{Id: 1, Uid: 10, Article: domain.Article{Id: 3}, Rtime: rtime},
+		{Id: 2, Uid: 10, Article: domain.Article{Id: 4}, Rtime: rtime},
+		{Id: 3, Uid: 11, Article: domain.Article{Id: 3}, Rtime: rtime},
+		{Id: 4, Uid: 11, Article: domain.Article{Id: 4}, Rtime: rtime},
+		// 只有一个人一起看过，不算
+		{Id: 5, Uid: 12, Article: domain.Article{Id: 1}, Rtime: rtime},
+		{Id: 6, Uid: 12, Article: domain.Article{Id: 3}, Rtime: rtime},
+		// 不在这一批文章里面
+		{Id: 7, Uid: 12, Article: domain.Article{Id: 99}, Rtime: rtime},
+	}, nil)
+	res := make(map[int64][]domain.RelatedArticle)
+	repo.EXPECT().ReplaceRelated(gomock.Any(), gomock.Any(), gomock.Any()).Times(4).
+		DoAndReturn(func(ctx context.Context, aid int64, rels []domain.RelatedArticle) error {
+			res[aid] = rels
+			return nil
+		})
+
+	require.NoError(t, svc.Compute(context.Background()))
+	// 1 和 2 内容相似
+	require.Len(t, res[1], 1)
+	assert.Equal(t, int64(2), res[1][0].Id)
+	require.Len(t, res[2], 1)
+	assert.Equal(t, int64(1), res[2][0].Id)
+	// 3 和 4 内容没有关系，但是总是被一起看
+	require.Len(t, res[3], 1)
+	assert.Equal(t, int64(4), res[3][0].Id)
+	// 3 有三个读者，4 有两个，一起看过的有两个
+	assert.InDelta(t, 0.4*2/math.Sqrt(3*2), res[3][0].Score, 1e-9)
+	assert.Len(t, res[4], 1)
+}
+
+func Test_relatedArticleService_GetRelated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockRelatedArticleRepository(ctrl)
+	artRepo := mock_repository.NewMockArticleRepository(ctrl)
+	svc := NewRelatedArticleService(repo, artRepo, mock_repository.NewMockReadHistoryRepository(ctrl),
+		logger.NewNopLogger())
+
+	repo.EXPECT().GetRelated(gomock.Any(), int64(1)).Return([]domain.RelatedArticle{
+		{Id: 2, Score: 0.9}, {Id: 3, Score: 0.8}, {Id: 4, Score: 0.7}, {Id: 5, Score: 0.6},
+	}, nil)
+	// 已经删除了
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(2)).Return(domain.Article{}, repository.ErrPubArticleNotFound)
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(3)).
+		Return(domain.Article{Id: 3, Status: domain.ArticleStatusPublished}, nil)
+	// 仅自己可见
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(4)).
+		Return(domain.Article{Id: 4, Status: domain.ArticleStatusPrivate}, nil)
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(5)).
+		Return(domain.Article{Id: 5, Status: domain.ArticleStatusPublished}, nil)
+
+	arts, err := svc.GetRelated(context.Background(), 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Article{{Id: 3, Status: domain.ArticleStatusPublished}}, arts)
+}
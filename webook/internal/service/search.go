@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/searchx"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrInvalidSearchQuery = errors.New("搜索关键字不合法")
+
+const (
+	// maxQueryLen 搜索关键字最长的字符数
+	maxQueryLen = 64
+	// snippetSize 搜索结果摘要的字符数
+	snippetSize = 128
+)
+
+// SearchService 已发表文章的全文搜索。
+// 索引放在进程内存里面，每个实例都各自维护一份完整的索引
+type SearchService interface {
+	// SearchArticle 按照相关度排序，返回这一页的结果和命中的总数
+	SearchArticle(ctx context.Context, query string, offset, limit int) ([]domain.ArticleSearchHit, int, error)
+	// IndexArticle 新增或者更新索引，不是已发表状态的文章会被移出索引
+	IndexArticle(ctx context.Context, art domain.Article) error
+	// RemoveArticle utime 是文章变成不可见的时间，比它旧的索引更新都会被丢弃
+	RemoveArticle(ctx context.Context, id int64, utime time.Time) error
+	// Rebuild 从线上库加载全部已发表的文章，启动的时候调用
+	Rebuild(ctx context.Context) error
+}
+
+type articleSearchService struct {
+	repo    repository.ArticleRepository
+	tagRepo repository.TagRepository
+	idx     *searchx.Index[domain.Article]
+	l       logger.Logger
+}
+
+func NewArticleSearchService(repo repository.ArticleRepository, tagRepo repository.TagRepository,
+	l logger.Logger) SearchService {
+	return &articleSearchService{
+		repo:    repo,
+		tagRepo: tagRepo,
+		idx:     searchx.NewIndex[domain.Article](),
+		l:       l,
+	}
+}
+
+func (s *articleSearchService) SearchArticle(ctx context.Context, query string,
+	offset, limit int) ([]domain.ArticleSearchHit, int, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > maxQueryLen {
+		return nil, 0, ErrInvalidSearchQuery
+	}
+	res := s.idx.Search(query, offset, limit)
+	hits := make([]domain.ArticleSearchHit, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		art := h.Doc.Data
+		hits = append(hits, domain.ArticleSearchHit{
+			Article:          art,
+			Score:            h.Score,
+			HighlightTitle:   searchx.Highlight(art.Title, res.Terms, 0),
+			HighlightSnippet: searchx.Highlight(s.searchableContent(&art), res.Terms, snippetSize),
+		})
+	}
+	return hits, res.Total, nil
+}
+
+func (s *articleSearchService) IndexArticle(ctx context.Context, art domain.Article) error {
+	if art.Status != domain.ArticleStatusPublished {
+		return s.RemoveArticle(ctx, art.Id, art.Utime)
+	}
+	s.idx.Put(searchx.Document[domain.Article]{
+		Id:      art.Id,
+		Version: art.Utime.UnixMilli(),
+		Fields: []searchx.Field{
+			{Name: "title", Text: art.Title, Weight: 3},
+			{Name: "tags", Text: strings.Join(art.Tags, " "), Weight: 2},
+			{Name: "content", Text: s.searchableContent(&art), Weight: 1},
+		},
+		Data: art,
+	})
+	return nil
+}
+
+// searchableContent 付费文章只能搜摘要，不然靠搜索结果的摘要片段就能拼出付费的内容
+func (s *articleSearchService) searchableContent(art *domain.Article) string {
+	if art.Paid() {
+		return art.Abstract()
+	}
+	return art.Content
+}
+
+func (s *articleSearchService) RemoveArticle(ctx context.Context, id int64, utime time.Time) error {
+	s.idx.Delete(id, utime.UnixMilli())
+	return nil
+}
+
+func (s *articleSearchService) Rebuild(ctx context.Context) error {
+	const batchSize = 100
+	var cursor domain.ArticleCursor
+	for {
+		// 对象存储的列表默认不带内容，这里要按照内容建索引
+		arts, err := s.repo.ListPubByCursorWithContent(ctx, cursor, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, art := range arts {
+			tags, er := s.tagRepo.GetArticleTags(ctx, art.Id)
+			if er != nil {
+				// 少了标签也能搜，不影响整体
+				s.l.Warn("重建索引查询文章标签失败",
+					logger.Int64("aid", art.Id),
+					logger.Error(er))
+			}
+			art.Tags = tags
+			_ = s.IndexArticle(ctx, art)
+		}
+		if len(arts) < batchSize {
+			s.l.Info("重建搜索索引完成", logger.Int64("cnt", int64(s.idx.Len())))
+			return nil
+		}
+		last := arts[len(arts)-1]
+		cursor = domain.ArticleCursor{Utime: last.Utime, Id: last.Id}
+	}
+}
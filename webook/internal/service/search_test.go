@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func Test_articleSearchService_Rebuild(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	artRepo := mock_repository.NewMockArticleRepository(ctrl)
+	tagRepo := mock_repository.NewMockTagRepository(ctrl)
+	svc := NewArticleSearchService(artRepo, tagRepo, logger.NewNopLogger())
+
+	utime := time.UnixMilli(1000)
+	artRepo.EXPECT().ListPubByCursorWithContent(gomock.Any(), domain.ArticleCursor{}, 100).
+		Return([]domain.Article{
+			{Id: 1, Title: "redis", Content: "distributed lock", Status: domain.ArticleStatusPublished, Utime: utime},
+			{Id: 2, Title: "kafka", Content: "consumer group", Status: domain.ArticleStatusPublished, Utime: utime},
+		}, nil)
+	tagRepo.EXPECT().GetArticleTags(gomock.Any(), int64(1)).Return([]string{"cache"}, nil)
+	// 标签查不到也要建索引
+	tagRepo.EXPECT().GetArticleTags(gomock.Any(), int64(2)).Return(nil, errors.New("db 错误"))
+	require.NoError(t, svc.Rebuild(context.Background()))
+
+	testCases := []struct {
+		query   string
+		wantIds []int64
+	}{
+		// 内容也能搜到
+		{query: "lock", wantIds: []int64{1}},
+		{query: "consumer", wantIds: []int64{2}},
+		{query: "cache", wantIds: []int64{1}},
+		{query: "kafka", wantIds: []int64{2}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			hits, total, err := svc.SearchArticle(context.Background(), tc.query, 0, 10)
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.wantIds), total)
+			ids := make([]int64, 0, len(hits))
+			for _, h := range hits {
+				ids = append(ids, h.Article.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
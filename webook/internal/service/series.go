@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"golang.org/x/sync/errgroup"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrSeriesNotFound       = repository.ErrSeriesNotFound
+	ErrArticleInOtherSeries = repository.ErrArticleInOtherSeries
+	ErrInvalidSeries        = errors.New("专栏的标题、简介或者文章不合法")
+)
+
+const (
+	maxSeriesTitleLen       = 64
+	maxSeriesDescriptionLen = 1024
+	// maxSeriesArticles 一个专栏最多的文章数
+	maxSeriesArticles = 200
+)
+
+// SeriesService 专栏。一篇文章只能放在一个专栏里面，读者只能看到已经发表的文章
+type SeriesService interface {
+	Create(ctx context.Context, s domain.Series) (int64, error)
+	// Update 只更新标题和简介
+	Update(ctx context.Context, s domain.Series) error
+	Delete(ctx context.Context, uid, id int64) error
+	// SetArticles 按照 aids 的顺序整个替换专栏里面的文章，只能放作者自己的文章
+	SetArticles(ctx context.Context, uid, sid int64, aids []int64) error
+	// ListByAuthor 作者的专栏，最近修改的在前，不带文章
+	ListByAuthor(ctx context.Context, uid int64, offset, limit int) ([]domain.Series, int64, error)
+	// Detail 专栏页，带上已经发表的文章和它们的计数之和
+	Detail(ctx context.Context, id int64) (domain.SeriesDetail, error)
+	// Nav 文章详情页的上一篇、下一篇，文章不在专栏里面的时候返回 ErrSeriesNotFound
+	Nav(ctx context.Context, aid int64) (domain.SeriesNav, error)
+}
+
+type seriesService struct {
+	repo    repository.SeriesRepository
+	artRepo repository.ArticleRepository
+	intrSvc intrv1.InteractiveServiceClient
+	l       logger.Logger
+}
+
+func NewSeriesService(repo repository.SeriesRepository, artRepo repository.ArticleRepository,
+	intrSvc intrv1.InteractiveServiceClient, l logger.Logger) SeriesService {
+	return &seriesService{
+		repo:    repo,
+		artRepo: artRepo,
+		intrSvc: intrSvc,
+		l:       l,
+	}
+}
+
+func (s *seriesService) Create(ctx context.Context, series domain.Series) (int64, error) {
+	series, err := s.normalize(series)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.Create(ctx, series)
+}
+
+func (s *seriesService) Update(ctx context.Context, series domain.Series) error {
+	series, err := s.normalize(series)
+	if err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, series)
+}
+
+func (s *seriesService) normalize(series domain.Series) (domain.Series, error) {
+	series.Title = strings.TrimSpace(series.Title)
+	series.Description = strings.TrimSpace(series.Description)
+	if series.Title == "" ||
+		utf8.RuneCountInString(series.Title) > maxSeriesTitleLen ||
+		utf8.RuneCountInString(series.Description) > maxSeriesDescriptionLen {
+		return domain.Series{}, ErrInvalidSeries
+	}
+	return series, nil
+}
+
+func (s *seriesService) Delete(ctx context.Context, uid, id int64) error {
+	return s.repo.Delete(ctx, uid, id)
+}
+
+func (s *seriesService) SetArticles(ctx context.Context, uid, sid int64, aids []int64) error {
+	if len(aids) > maxSeriesArticles {
+		return ErrInvalidSeries
+	}
+	seen := make(map[int64]struct{}, len(aids))
+	for _, aid := range aids {
+		if _, ok := seen[aid]; ok {
+			return ErrInvalidSeries
+		}
+		seen[aid] = struct{}{}
+	}
+	var eg errgroup.Group
+	for _, aid := range aids {
+		aid := aid
+		eg.Go(func() error {
+			art, err := s.artRepo.GetById(ctx, aid)
+			if errors.Is(err, repository.ErrDraftNotFound) || (err == nil && art.Author.Id != uid) {
+				return ErrArticleNotFound
+			}
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	return s.repo.SetArticles(ctx, uid, sid, aids)
+}
+
+func (s *seriesService) ListByAuthor(ctx context.Context, uid int64, offset, limit int) ([]domain.Series, int64, error) {
+	return s.repo.ListByAuthor(ctx, uid, offset, limit)
+}
+
+func (s *seriesService) Detail(ctx context.Context, id int64) (domain.SeriesDetail, error) {
+	series, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return domain.SeriesDetail{}, err
+	}
+	arts, err := s.published(ctx, series.Aids)
+	if err != nil {
+		return domain.SeriesDetail{}, err
+	}
+	res := domain.SeriesDetail{Series: series, Articles: arts}
+	if len(arts) == 0 {
+		return res, nil
+	}
+	// 计数查不到的时候专栏页照样展示，只是计数是 0
+	resp, err := s.intrSvc.GetByIds(ctx, &intrv1.GetByIdsRequest{
+		Biz: articleBiz,
+		Ids: slice.Map(arts, func(idx int, src domain.Article) int64 {
+			return src.Id
+		}),
+	})
+	if err != nil {
+		s.l.Error("查询专栏的计数失败", logger.Int64("sid", id), logger.Error(err))
+		return res, nil
+	}
+	for _, intr := range resp.GetIntrs() {
+		res.ReadCnt += intr.GetReadCnt()
+		res.LikeCnt += intr.GetLikeCnt()
+		res.CollectCnt += intr.GetCollectCnt()
+		res.CommentCnt += intr.GetCommentCnt()
+	}
+	return res, nil
+}
+
+// published 按照原来的顺序，只保留已经发表的文章
+func (s *seriesService) published(ctx context.Context, aids []int64) ([]domain.Article, error) {
+	arts := make([]domain.Article, len(aids))
+	var eg errgroup.Group
+	for i, aid := range aids {
+		i, aid := i, aid
+		eg.Go(func() error {
+			art, err := s.artRepo.GetPubById(ctx, aid)
+			if errors.Is(err, repository.ErrPubArticleNotFound) {
+				return nil
+			}
+			arts[i] = art
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	res := make([]domain.Article, 0, len(arts))
+	for _, art := range arts {
+		if art.Id > 0 && art.Status == domain.ArticleStatusPublished {
+			res = append(res, art)
+		}
+	}
+	return res, nil
+}
+
+func (s *seriesService) Nav(ctx context.Context, aid int64) (domain.SeriesNav, error) {
+	series, err := s.repo.GetByArticle(ctx, aid)
+	if err != nil {
+		return domain.SeriesNav{}, err
+	}
+	idx := slice.Index(series.Aids, aid)
+	if idx < 0 {
+		// 缓存和数据库不一致，当作不在专栏里面
+		return domain.SeriesNav{}, ErrSeriesNotFound
+	}
+	res := domain.SeriesNav{Series: series}
+	res.Prev, err = s.nearestPublished(ctx, series.Aids[:idx], true)
+	if err != nil {
+		return domain.SeriesNav{}, err
+	}
+	res.Next, err = s.nearestPublished(ctx, series.Aids[idx+1:], false)
+	if err != nil {
+		return domain.SeriesNav{}, err
+	}
+	return res, nil
+}
+
+// nearestPublished 跳过还没有发表的文章，reverse 表示从后往前找
+func (s *seriesService) nearestPublished(ctx context.Context, aids []int64, reverse bool) (domain.Article, error) {
+	for i := range aids {
+		aid := aids[i]
+		if reverse {
+			aid = aids[len(aids)-1-i]
+		}
+		art, err := s.artRepo.GetPubById(ctx, aid)
+		if errors.Is(err, repository.ErrPubArticleNotFound) {
+			continue
+		}
+		if err != nil {
+			return domain.Article{}, err
+		}
+		if art.Status == domain.ArticleStatusPublished {
+			return domain.Article{Id: art.Id, Title: art.Title}, nil
+		}
+	}
+	return domain.Article{}, nil
+}
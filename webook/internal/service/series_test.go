@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
+	mock_intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1/mocks"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func Test_seriesService_Nav(t *testing.T) {
+	series := domain.Series{Id: 10, Title: "专栏", Aids: []int64{1, 2, 3, 4, 5}}
+	testCases := []struct {
+		name    string
+		mock    func(repo *mock_repository.MockSeriesRepository, artRepo *mock_repository.MockArticleRepository)
+		aid     int64
+		wantNav domain.SeriesNav
+		wantErr error
+	}{
+		{
+			name: "跳过没有发表的",
+			mock: func(repo *mock_repository.MockSeriesRepository, artRepo *mock_repository.MockArticleRepository) {
+				repo.EXPECT().GetByArticle(gomock.Any(), int64(3)).Return(series, nil)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(2)).
+					Return(domain.Article{}, repository.ErrPubArticleNotFound)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Title: "第一篇", Status: domain.ArticleStatusPublished}, nil)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(4)).
+					Return(domain.Article{Id: 4, Title: "第四篇", Status: domain.ArticleStatusPrivate}, nil)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(5)).
+					Return(domain.Article{Id: 5, Title: "第五篇", Status: domain.ArticleStatusPublished}, nil)
+			},
+			aid: 3,
+			wantNav: domain.SeriesNav{
+				Series: series,
+				Prev:   domain.Article{Id: 1, Title: "第一篇"},
+				Next:   domain.Article{Id: 5, Title: "第五篇"},
+			},
+		},
+		{
+			name: "第一篇",
+			mock: func(repo *mock_repository.MockSeriesRepository, artRepo *mock_repository.MockArticleRepository) {
+				repo.EXPECT().GetByArticle(gomock.Any(), int64(1)).Return(series, nil)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(2)).
+					Return(domain.Article{Id: 2, Title: "第二篇", Status: domain.ArticleStatusPublished}, nil)
+			},
+			aid: 1,
+			wantNav: domain.SeriesNav{
+				Series: series,
+				Next:   domain.Article{Id: 2, Title: "第二篇"},
+			},
+		},
+		{
+			name: "不在专栏里面",
+			mock: func(repo *mock_repository.MockSeriesRepository, artRepo *mock_repository.MockArticleRepository) {
+				repo.EXPECT().GetByArticle(gomock.Any(), int64(6)).
+					Return(domain.Series{}, repository.ErrSeriesNotFound)
+			},
+			aid:     6,
+			wantErr: ErrSeriesNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_repository.NewMockSeriesRepository(ctrl)
+			artRepo := mock_repository.NewMockArticleRepository(ctrl)
+			tc.mock(repo, artRepo)
+			svc := NewSeriesService(repo, artRepo, mock_intrv1.NewMockInteractiveServiceClient(ctrl),
+				logger.NewNopLogger())
+			nav, err := svc.Nav(context.Background(), tc.aid)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantNav, nav)
+		})
+	}
+}
+
+func Test_seriesService_Detail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockSeriesRepository(ctrl)
+	artRepo := mock_repository.NewMockArticleRepository(ctrl)
+	intrSvc := mock_intrv1.NewMockInteractiveServiceClient(ctrl)
+	svc := NewSeriesService(repo, artRepo, intrSvc, logger.NewNopLogger())
+
+	series := domain.Series{Id: 10, Title: "专栏", Aids: []int64{1, 2, 3}}
+	repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(series, nil)
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+		Return(domain.Article{Id: 1, Status: domain.ArticleStatusPublished}, nil)
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(2)).
+		Return(domain.Article{}, repository.ErrPubArticleNotFound)
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(3)).
+		Return(domain.Article{Id: 3, Status: domain.ArticleStatusPublished}, nil)
+	intrSvc.EXPECT().GetByIds(gomock.Any(), &intrv1.GetByIdsRequest{Biz: "article", Ids: []int64{1, 3}}).
+		Return(&intrv1.GetByIdsResponse{Intrs: map[int64]*intrv1.Interactive{
+			1: {ReadCnt: 10, LikeCnt: 2, CollectCnt: 1},
+			3: {ReadCnt: 5, LikeCnt: 1, CommentCnt: 3},
+		}}, nil)
+
+	detail, err := svc.Detail(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SeriesDetail{
+		Series: series,
+		Articles: []domain.Article{
+			{Id: 1, Status: domain.ArticleStatusPublished},
+			{Id: 3, Status: domain.ArticleStatusPublished},
+		},
+		ReadCnt:    15,
+		LikeCnt:    3,
+		CollectCnt: 1,
+		CommentCnt: 3,
+	}, detail)
+}
+
+func Test_seriesService_SetArticles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockSeriesRepository(ctrl)
+	artRepo := mock_repository.NewMockArticleRepository(ctrl)
+	svc := NewSeriesService(repo, artRepo, mock_intrv1.NewMockInteractiveServiceClient(ctrl),
+		logger.NewNopLogger())
+
+	// 重复的文章
+	assert.Equal(t, ErrInvalidSeries, svc.SetArticles(context.Background(), 123, 10, []int64{1, 1}))
+
+	// 别人的文章
+	artRepo.EXPECT().GetById(gomock.Any(), int64(1)).
+		Return(domain.Article{Id: 1, Author: domain.Author{Id: 123}}, nil)
+	artRepo.EXPECT().GetById(gomock.Any(), int64(2)).
+		Return(domain.Article{Id: 2, Author: domain.Author{Id: 456}}, nil)
+	assert.Equal(t, ErrArticleNotFound, svc.SetArticles(context.Background(), 123, 10, []int64{1, 2}))
+
+	artRepo.EXPECT().GetById(gomock.Any(), int64(2)).
+		Return(domain.Article{Id: 2, Author: domain.Author{Id: 123}}, nil)
+	artRepo.EXPECT().GetById(gomock.Any(), int64(1)).
+		Return(domain.Article{Id: 1, Author: domain.Author{Id: 123}}, nil)
+	repo.EXPECT().SetArticles(gomock.Any(), int64(123), int64(10), []int64{2, 1}).Return(nil)
+	assert.NoError(t, svc.SetArticles(context.Background(), 123, 10, []int64{2, 1}))
+}
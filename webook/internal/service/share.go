@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"time"
+)
+
+var (
+	ErrArticleShareNotFound = repository.ErrArticleShareNotFound
+	ErrInvalidShareTTL      = errors.New("分享链接的有效期不对")
+	// ErrInvalidShareToken 签名不对、不是这篇文章的、已经撤销或者过期了
+	ErrInvalidShareToken = errors.New("分享链接无效")
+)
+
+// ArticleShareService 仅自己可见的文章的分享链接。
+// token 是签过名的 JWT，里面只有分享链接的 Id 和文章 Id，撤销和过期以数据库为准
+type ArticleShareService interface {
+	// Create 只有作者能分享，ttl 不能超过 maxTTL
+	Create(ctx context.Context, uid, aid int64, ttl time.Duration) (domain.ArticleShare, error)
+	// List 作者某篇文章的所有分享链接，不带 Token
+	List(ctx context.Context, uid, aid int64) ([]domain.ArticleShare, error)
+	Revoke(ctx context.Context, uid, id int64) error
+	// Access 校验 token 能不能看 aid 这篇文章，不能的时候返回 ErrInvalidShareToken
+	Access(ctx context.Context, aid int64, token string, visitor domain.ShareVisitor) error
+	// ListAudits 只有作者能看
+	ListAudits(ctx context.Context, uid, id int64, offset, limit int) ([]domain.ShareAudit, int64, error)
+}
+
+type ShareClaims struct {
+	jwt.RegisteredClaims
+	Sid int64
+	Aid int64
+}
+
+type articleShareService struct {
+	repo    repository.ArticleShareRepository
+	artRepo repository.ArticleRepository
+	l       logger.Logger
+	key     []byte
+	maxTTL  time.Duration
+}
+
+func NewArticleShareService(repo repository.ArticleShareRepository, artRepo repository.ArticleRepository,
+	l logger.Logger, key []byte, maxTTL time.Duration) ArticleShareService {
+	return &articleShareService{
+		repo:    repo,
+		artRepo: artRepo,
+		l:       l,
+		key:     key,
+		maxTTL:  maxTTL,
+	}
+}
+
+func (s *articleShareService) Create(ctx context.Context, uid, aid int64, ttl time.Duration) (domain.ArticleShare, error) {
+	if ttl <= 0 || ttl > s.maxTTL {
+		return domain.ArticleShare{}, ErrInvalidShareTTL
+	}
+	// 分享的是线上版本，没有发表过的草稿不能分享
+	art, err := s.artRepo.GetPubById(ctx, aid)
+	if errors.Is(err, repository.ErrPubArticleNotFound) || (err == nil && art.Author.Id != uid) {
+		return domain.ArticleShare{}, ErrArticleNotFound
+	}
+	if err != nil {
+		return domain.ArticleShare{}, err
+	}
+	now := time.Now()
+	share := domain.ArticleShare{
+		Aid:    aid,
+		Uid:    uid,
+		Expire: now.Add(ttl),
+		Ctime:  now,
+	}
+	share.Id, err = s.repo.Create(ctx, share)
+	if err != nil {
+		return domain.ArticleShare{}, err
+	}
+	share.Token, err = s.sign(share)
+	if err != nil {
+		return domain.ArticleShare{}, err
+	}
+	s.audit(ctx, domain.ShareAudit{
+		ShareId: share.Id,
+		Aid:     aid,
+		Uid:     uid,
+		Action:  domain.ShareAuditIssue,
+	})
+	return share, nil
+}
+
+func (s *articleShareService) List(ctx context.Context, uid, aid int64) ([]domain.ArticleShare, error) {
+	return s.repo.ListByArticle(ctx, uid, aid)
+}
+
+func (s *articleShareService) Revoke(ctx context.Context, uid, id int64) error {
+	share, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if share.Uid != uid {
+		return ErrArticleShareNotFound
+	}
+	if err = s.repo.Revoke(ctx, uid, id); err != nil {
+		return err
+	}
+	s.audit(ctx, domain.ShareAudit{
+		ShareId: id,
+		Aid:     share.Aid,
+		Uid:     uid,
+		Action:  domain.ShareAuditRevoke,
+	})
+	return nil
+}
+
+func (s *articleShareService) Access(ctx context.Context, aid int64, token string, visitor domain.ShareVisitor) error {
+	var claims ShareClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		return s.key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	// 过期了签名也是对的，继续往下走，留下一条拒绝的记录
+	if err != nil && !errors.Is(err, jwt.ErrTokenExpired) {
+		return ErrInvalidShareToken
+	}
+	if claims.Aid != aid {
+		return ErrInvalidShareToken
+	}
+	share, err := s.repo.GetById(ctx, claims.Sid)
+	if errors.Is(err, repository.ErrArticleShareNotFound) {
+		return ErrInvalidShareToken
+	}
+	if err != nil {
+		return err
+	}
+	audit := domain.ShareAudit{
+		ShareId:   share.Id,
+		Aid:       aid,
+		Uid:       visitor.Uid,
+		Action:    domain.ShareAuditAccess,
+		IP:        visitor.IP,
+		UserAgent: visitor.UserAgent,
+	}
+	if share.Aid != aid || !share.Valid(time.Now()) {
+		audit.Action = domain.ShareAuditDenied
+		s.audit(ctx, audit)
+		return ErrInvalidShareToken
+	}
+	s.audit(ctx, audit)
+	return nil
+}
+
+func (s *articleShareService) ListAudits(ctx context.Context, uid, id int64, offset, limit int) ([]domain.ShareAudit, int64, error) {
+	share, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if share.Uid != uid {
+		return nil, 0, ErrArticleShareNotFound
+	}
+	return s.repo.ListAudits(ctx, id, offset, limit)
+}
+
+func (s *articleShareService) sign(share domain.ArticleShare) (string, error) {
+	claims := ShareClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(share.Expire),
+		},
+		Sid: share.Id,
+		Aid: share.Aid,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+}
+
+// audit 审计记录写失败不影响业务，只记日志
+func (s *articleShareService) audit(ctx context.Context, a domain.ShareAudit) {
+	if err := s.repo.AddAudit(ctx, a); err != nil {
+		s.l.Error("记录分享链接的审计失败",
+			logger.Int64("shareId", a.ShareId),
+			logger.Int64("uid", a.Uid),
+			logger.Int64("action", int64(a.Action)),
+			logger.Error(err))
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func Test_articleShareService_Create(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(repo *mock_repository.MockArticleShareRepository, artRepo *mock_repository.MockArticleRepository)
+		ttl     time.Duration
+		wantErr error
+	}{
+		{
+			name: "创建成功",
+			mock: func(repo *mock_repository.MockArticleShareRepository, artRepo *mock_repository.MockArticleRepository) {
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id: 1, Status: domain.ArticleStatusPrivate, Author: domain.Author{Id: 123},
+				}, nil)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(int64(10), nil)
+				repo.EXPECT().AddAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, a domain.ShareAudit) error {
+						assert.Equal(t, domain.ShareAuditIssue, a.Action)
+						assert.Equal(t, int64(10), a.ShareId)
+						return nil
+					})
+			},
+			ttl: time.Hour,
+		},
+		{
+			name: "不是作者",
+			mock: func(repo *mock_repository.MockArticleShareRepository, artRepo *mock_repository.MockArticleRepository) {
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id: 1, Status: domain.ArticleStatusPrivate, Author: domain.Author{Id: 456},
+				}, nil)
+			},
+			ttl:     time.Hour,
+			wantErr: ErrArticleNotFound,
+		},
+		{
+			name: "没有发表过",
+			mock: func(repo *mock_repository.MockArticleShareRepository, artRepo *mock_repository.MockArticleRepository) {
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{}, repository.ErrPubArticleNotFound)
+			},
+			ttl:     time.Hour,
+			wantErr: ErrArticleNotFound,
+		},
+		{
+			name: "有效期太长",
+			mock: func(repo *mock_repository.MockArticleShareRepository, artRepo *mock_repository.MockArticleRepository) {
+			},
+			ttl:     time.Hour * 24 * 31,
+			wantErr: ErrInvalidShareTTL,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_repository.NewMockArticleShareRepository(ctrl)
+			artRepo := mock_repository.NewMockArticleRepository(ctrl)
+			tc.mock(repo, artRepo)
+			svc := NewArticleShareService(repo, artRepo, logger.NewNopLogger(), []byte("key"), time.Hour*24*30)
+			share, err := svc.Create(context.Background(), 123, 1, tc.ttl)
+			assert.Equal(t, tc.wantErr, err)
+			if err == nil {
+				assert.Equal(t, int64(10), share.Id)
+				assert.NotEmpty(t, share.Token)
+			}
+		})
+	}
+}
+
+func Test_articleShareService_Access(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockArticleShareRepository(ctrl)
+	artRepo := mock_repository.NewMockArticleRepository(ctrl)
+	svc := NewArticleShareService(repo, artRepo, logger.NewNopLogger(), []byte("key"), time.Hour*24*30)
+
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+		Id: 1, Status: domain.ArticleStatusPrivate, Author: domain.Author{Id: 123},
+	}, nil)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(int64(10), nil)
+	repo.EXPECT().AddAudit(gomock.Any(), gomock.Any()).Return(nil)
+	share, err := svc.Create(context.Background(), 123, 1, time.Hour)
+	require.NoError(t, err)
+
+	visitor := domain.ShareVisitor{Uid: 456, IP: "127.0.0.1", UserAgent: "test"}
+	stored := domain.ArticleShare{Id: 10, Aid: 1, Uid: 123, Expire: time.Now().Add(time.Hour)}
+	repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(stored, nil)
+	repo.EXPECT().AddAudit(gomock.Any(), domain.ShareAudit{
+		ShareId: 10, Aid: 1, Uid: 456, Action: domain.ShareAuditAccess, IP: "127.0.0.1", UserAgent: "test",
+	}).Return(nil)
+	assert.NoError(t, svc.Access(context.Background(), 1, share.Token, visitor))
+
+	// 不能拿去看别的文章
+	assert.Equal(t, ErrInvalidShareToken, svc.Access(context.Background(), 2, share.Token, visitor))
+	// 签名不对
+	assert.Equal(t, ErrInvalidShareToken, svc.Access(context.Background(), 1, share.Token+"x", visitor))
+
+	// 撤销之后
+	stored.Revoked = true
+	repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(stored, nil)
+	repo.EXPECT().AddAudit(gomock.Any(), domain.ShareAudit{
+		ShareId: 10, Aid: 1, Uid: 456, Action: domain.ShareAuditDenied, IP: "127.0.0.1", UserAgent: "test",
+	}).Return(nil)
+	assert.Equal(t, ErrInvalidShareToken, svc.Access(context.Background(), 1, share.Token, visitor))
+}
+
+func Test_articleShareService_Revoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockArticleShareRepository(ctrl)
+	svc := NewArticleShareService(repo, mock_repository.NewMockArticleRepository(ctrl),
+		logger.NewNopLogger(), []byte("key"), time.Hour)
+
+	// 别人的分享链接
+	repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.ArticleShare{Id: 10, Aid: 1, Uid: 456}, nil)
+	assert.Equal(t, ErrArticleShareNotFound, svc.Revoke(context.Background(), 123, 10))
+
+	repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.ArticleShare{Id: 10, Aid: 1, Uid: 123}, nil)
+	repo.EXPECT().Revoke(gomock.Any(), int64(123), int64(10)).Return(nil)
+	repo.EXPECT().AddAudit(gomock.Any(), domain.ShareAudit{
+		ShareId: 10, Aid: 1, Uid: 123, Action: domain.ShareAuditRevoke,
+	}).Return(nil)
+	assert.NoError(t, svc.Revoke(context.Background(), 123, 10))
+}
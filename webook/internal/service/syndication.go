@@ -0,0 +1,266 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyndicationService 作者和热榜的 RSS 2.0、Atom 1.0 订阅源。
+// 渲染好的文档放在缓存里面，作者的订阅源在作者发表或者下线文章的时候删掉
+type SyndicationService interface {
+	// AuthorFeed 作者最近发表的文章
+	AuthorFeed(ctx context.Context, uid int64, format domain.SyndicationFormat) (domain.SyndicationDocument, error)
+	// HotFeed 热榜上的文章
+	HotFeed(ctx context.Context, format domain.SyndicationFormat) (domain.SyndicationDocument, error)
+	// InvalidateAuthor 作者的文章发生变化之后调用
+	InvalidateAuthor(ctx context.Context, uid int64) error
+}
+
+type syndicationService struct {
+	repo       repository.SyndicationRepository
+	artRepo    repository.ArticleRepository
+	rankingSvc RankingService
+	l          logger.Logger
+	// siteURL 文章和订阅源链接的前缀，不带最后的斜杠
+	siteURL string
+	// size 作者的订阅源里面最多的文章数
+	size int
+}
+
+func NewSyndicationService(repo repository.SyndicationRepository, artRepo repository.ArticleRepository,
+	rankingSvc RankingService, l logger.Logger, siteURL string, size int) SyndicationService {
+	return &syndicationService{
+		repo:       repo,
+		artRepo:    artRepo,
+		rankingSvc: rankingSvc,
+		l:          l,
+		siteURL:    strings.TrimSuffix(siteURL, "/"),
+		size:       size,
+	}
+}
+
+// syndicationChannel 订阅源本身的信息
+type syndicationChannel struct {
+	title       string
+	description string
+	// link 订阅源自己的地址，不带格式参数
+	link string
+}
+
+func (s *syndicationService) AuthorFeed(ctx context.Context, uid int64, format domain.SyndicationFormat) (domain.SyndicationDocument, error) {
+	doc, err := s.repo.GetAuthor(ctx, uid, format)
+	if err == nil {
+		return doc, nil
+	}
+	// 修改之后还没有重新发表的文章，用的是线上版本
+	arts, err := s.artRepo.ListPubByAuthor(ctx, uid, s.size)
+	if err != nil {
+		return domain.SyndicationDocument{}, err
+	}
+	title := fmt.Sprintf("用户 %d 的文章", uid)
+	if len(arts) > 0 && arts[0].Author.Name != "" {
+		title = arts[0].Author.Name + " 的文章"
+	}
+	doc, err = s.render(format, syndicationChannel{
+		title:       title,
+		description: title,
+		link:        fmt.Sprintf("%s/feeds/author/%d.xml", s.siteURL, uid),
+	}, arts)
+	if err != nil {
+		return domain.SyndicationDocument{}, err
+	}
+	if er := s.repo.SetAuthor(ctx, uid, format, doc); er != nil {
+		s.l.Error("缓存作者的订阅源失败", logger.Int64("uid", uid), logger.Error(er))
+	}
+	return doc, nil
+}
+
+func (s *syndicationService) HotFeed(ctx context.Context, format domain.SyndicationFormat) (domain.SyndicationDocument, error) {
+	doc, err := s.repo.GetHot(ctx, format)
+	if err == nil {
+		return doc, nil
+	}
+	arts, err := s.rankingSvc.GetTopN(ctx)
+	if err != nil {
+		return domain.SyndicationDocument{}, err
+	}
+	doc, err = s.render(format, syndicationChannel{
+		title:       "热榜",
+		description: "最近最受欢迎的文章",
+		link:        s.siteURL + "/feeds/hot.xml",
+	}, arts)
+	if err != nil {
+		return domain.SyndicationDocument{}, err
+	}
+	if er := s.repo.SetHot(ctx, format, doc); er != nil {
+		s.l.Error("缓存热榜的订阅源失败", logger.Error(er))
+	}
+	return doc, nil
+}
+
+func (s *syndicationService) InvalidateAuthor(ctx context.Context, uid int64) error {
+	return s.repo.DelAuthor(ctx, uid)
+}
+
+func (s *syndicationService) render(format domain.SyndicationFormat, ch syndicationChannel,
+	arts []domain.Article) (domain.SyndicationDocument, error) {
+	var modified time.Time
+	for _, art := range arts {
+		if art.Utime.After(modified) {
+			modified = art.Utime
+		}
+	}
+	var v any
+	if format == domain.SyndicationAtom {
+		v = s.atom(ch, arts, modified)
+	} else {
+		v = s.rss(ch, arts, modified)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return domain.SyndicationDocument{}, err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return domain.SyndicationDocument{
+		Data:     buf.Bytes(),
+		ETag:     `"` + hex.EncodeToString(sum[:16]) + `"`,
+		Modified: modified,
+	}, nil
+}
+
+func (s *syndicationService) articleLink(id int64) string {
+	return fmt.Sprintf("%s/articles/pub/%d", s.siteURL, id)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (s *syndicationService) rss(ch syndicationChannel, arts []domain.Article, modified time.Time) rssFeed {
+	items := make([]rssItem, 0, len(arts))
+	for _, art := range arts {
+		link := s.articleLink(art.Id)
+		items = append(items, rssItem{
+			Title:       art.Title,
+			Link:        link,
+			Description: art.Abstract(),
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			PubDate:     art.Utime.Format(time.RFC1123Z),
+		})
+	}
+	res := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title: ch.title,
+			// RSS 的 link 是网站的地址，不是订阅源自己的
+			Link:        s.siteURL,
+			Description: ch.description,
+			Items:       items,
+		},
+	}
+	if !modified.IsZero() {
+		res.Channel.LastBuildDate = modified.Format(time.RFC1123Z)
+	}
+	return res
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Id        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published,omitempty"`
+	Link      atomLink   `xml:"link"`
+	Author    atomPerson `xml:"author"`
+	Summary   string     `xml:"summary"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+func (s *syndicationService) atom(ch syndicationChannel, arts []domain.Article, modified time.Time) atomFeed {
+	entries := make([]atomEntry, 0, len(arts))
+	for _, art := range arts {
+		link := s.articleLink(art.Id)
+		entry := atomEntry{
+			Id:      link,
+			Title:   art.Title,
+			Updated: art.Utime.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: link},
+			// Atom 要求每一篇都有作者
+			Author:  atomPerson{Name: art.Author.Name},
+			Summary: art.Abstract(),
+		}
+		if entry.Author.Name == "" {
+			entry.Author.Name = strconv.FormatInt(art.Author.Id, 10)
+		}
+		if !art.Ctime.IsZero() {
+			entry.Published = art.Ctime.UTC().Format(time.RFC3339)
+		}
+		entries = append(entries, entry)
+	}
+	// 没有文章的时候 updated 也不能空着
+	if modified.IsZero() {
+		modified = time.UnixMilli(0)
+	}
+	return atomFeed{
+		Id:      ch.link,
+		Title:   ch.title,
+		Updated: modified.UTC().Format(time.RFC3339),
+		Link: []atomLink{
+			{Href: ch.link + "?format=atom", Rel: "self"},
+			{Href: s.siteURL, Rel: "alternate"},
+		},
+		Entries: entries,
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/cache"
+	mock_repository "github.com/jayleonc/geektime-go/webook/internal/repository/mocks"
+	mock_service "github.com/jayleonc/geektime-go/webook/internal/service/mocks"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func Test_syndicationService_AuthorFeed(t *testing.T) {
+	utime := time.UnixMilli(1700000000000).UTC()
+	cached := domain.SyndicationDocument{Data: []byte("<rss/>"), ETag: `"abc"`, Modified: utime}
+	testCases := []struct {
+		name    string
+		mock    func(repo *mock_repository.MockSyndicationRepository, artRepo *mock_repository.MockArticleRepository)
+		wantErr error
+		check   func(t *testing.T, doc domain.SyndicationDocument)
+	}{
+		{
+			name: "缓存命中",
+			mock: func(repo *mock_repository.MockSyndicationRepository, artRepo *mock_repository.MockArticleRepository) {
+				repo.EXPECT().GetAuthor(gomock.Any(), int64(123), domain.SyndicationRSS).Return(cached, nil)
+			},
+			check: func(t *testing.T, doc domain.SyndicationDocument) {
+				assert.Equal(t, cached, doc)
+			},
+		},
+		{
+			name: "作者发表的文章",
+			mock: func(repo *mock_repository.MockSyndicationRepository, artRepo *mock_repository.MockArticleRepository) {
+				repo.EXPECT().GetAuthor(gomock.Any(), int64(123), domain.SyndicationRSS).
+					Return(domain.SyndicationDocument{}, cache.ErrKeyNotExist)
+				artRepo.EXPECT().ListPubByAuthor(gomock.Any(), int64(123), 20).Return([]domain.Article{{
+					Id: 1, Title: "标题", Content: "内容", Status: domain.ArticleStatusPublished,
+					Author: domain.Author{Id: 123, Name: "Tom"}, Utime: utime,
+				}}, nil)
+				repo.EXPECT().SetAuthor(gomock.Any(), int64(123), domain.SyndicationRSS, gomock.Any()).Return(nil)
+			},
+			check: func(t *testing.T, doc domain.SyndicationDocument) {
+				var feed rssFeed
+				require.NoError(t, xml.Unmarshal(doc.Data, &feed))
+				assert.Equal(t, "Tom 的文章", feed.Channel.Title)
+				assert.Equal(t, []rssItem{{
+					Title:       "标题",
+					Link:        "https://webook.com/articles/pub/1",
+					Description: "内容",
+					GUID:        rssGUID{IsPermaLink: true, Value: "https://webook.com/articles/pub/1"},
+					PubDate:     "Tue, 14 Nov 2023 22:13:20 +0000",
+				}}, feed.Channel.Items)
+				assert.True(t, utime.Equal(doc.Modified))
+				assert.Len(t, doc.ETag, 34)
+			},
+		},
+		{
+			name: "查询失败",
+			mock: func(repo *mock_repository.MockSyndicationRepository, artRepo *mock_repository.MockArticleRepository) {
+				repo.EXPECT().GetAuthor(gomock.Any(), int64(123), domain.SyndicationRSS).
+					Return(domain.SyndicationDocument{}, cache.ErrKeyNotExist)
+				artRepo.EXPECT().ListPubByAuthor(gomock.Any(), int64(123), 20).
+					Return(nil, errors.New("mock db 错误"))
+			},
+			wantErr: errors.New("mock db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_repository.NewMockSyndicationRepository(ctrl)
+			artRepo := mock_repository.NewMockArticleRepository(ctrl)
+			tc.mock(repo, artRepo)
+			svc := NewSyndicationService(repo, artRepo, mock_service.NewMockRankingService(ctrl),
+				logger.NewNopLogger(), "https://webook.com/", 20)
+			doc, err := svc.AuthorFeed(context.Background(), 123, domain.SyndicationRSS)
+			assert.Equal(t, tc.wantErr, err)
+			if err == nil {
+				tc.check(t, doc)
+			}
+		})
+	}
+}
+
+func Test_syndicationService_HotFeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_repository.NewMockSyndicationRepository(ctrl)
+	rankingSvc := mock_service.NewMockRankingService(ctrl)
+	utime := time.UnixMilli(1700000000000)
+	repo.EXPECT().GetHot(gomock.Any(), domain.SyndicationAtom).
+		Return(domain.SyndicationDocument{}, cache.ErrKeyNotExist)
+	rankingSvc.EXPECT().GetTopN(gomock.Any()).Return([]domain.Article{
+		{Id: 1, Title: "热门", Content: "内容", Author: domain.Author{Id: 123}, Utime: utime},
+	}, nil)
+	repo.EXPECT().SetHot(gomock.Any(), domain.SyndicationAtom, gomock.Any()).Return(errors.New("mock redis 错误"))
+
+	svc := NewSyndicationService(repo, mock_repository.NewMockArticleRepository(ctrl), rankingSvc,
+		logger.NewNopLogger(), "https://webook.com", 20)
+	// 缓存失败不影响返回
+	doc, err := svc.HotFeed(context.Background(), domain.SyndicationAtom)
+	require.NoError(t, err)
+	var feed atomFeed
+	require.NoError(t, xml.Unmarshal(doc.Data, &feed))
+	assert.Equal(t, "http://www.w3.org/2005/Atom", feed.XMLName.Space)
+	assert.Equal(t, "2023-11-14T22:13:20Z", feed.Updated)
+	assert.Equal(t, []atomEntry{{
+		Id:      "https://webook.com/articles/pub/1",
+		Title:   "热门",
+		Updated: "2023-11-14T22:13:20Z",
+		Link:    atomLink{Href: "https://webook.com/articles/pub/1"},
+		Author:  atomPerson{Name: "123"},
+		Summary: "内容",
+	}}, feed.Entries)
+}
@@ -1,34 +1,56 @@
 package web
 
 import (
+	"encoding/base64"
+	"errors"
 	"github.com/ecodeclub/ekit/slice"
 	"github.com/gin-gonic/gin"
 	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
 	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
 	"github.com/jayleonc/geektime-go/webook/internal/service"
 	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
 	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
 	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
 	"github.com/jayleonc/geektime-go/webook/pkg/logger"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// relatedSize 文章详情页展示的相关文章数
+const relatedSize = 5
+
 type ArticleHandler struct {
-	svc     service.ArticleService
-	intrSvc intrv1.InteractiveServiceClient
-	l       logger.Logger
-	biz     string
+	svc        service.ArticleService
+	intrSvc    intrv1.InteractiveServiceClient
+	relatedSvc service.RelatedArticleService
+	shareSvc   service.ArticleShareService
+	seriesSvc  service.SeriesService
+	orderSvc   service.OrderService
+	rankingSvc service.RankingService
+	l          logger.Logger
+	biz        string
 }
 
-func NewArticleHandler(l logger.Logger, svc service.ArticleService, intrSvc intrv1.InteractiveServiceClient) *ArticleHandler {
+func NewArticleHandler(l logger.Logger, svc service.ArticleService, intrSvc intrv1.InteractiveServiceClient,
+	relatedSvc service.RelatedArticleService, shareSvc service.ArticleShareService,
+	seriesSvc service.SeriesService, orderSvc service.OrderService,
+	rankingSvc service.RankingService) *ArticleHandler {
 	return &ArticleHandler{
-		l:       l,
-		svc:     svc,
-		intrSvc: intrSvc,
-		biz:     "article",
+		l:          l,
+		svc:        svc,
+		intrSvc:    intrSvc,
+		relatedSvc: relatedSvc,
+		shareSvc:   shareSvc,
+		seriesSvc:  seriesSvc,
+		orderSvc:   orderSvc,
+		rankingSvc: rankingSvc,
+		biz:        "article",
 	}
 }
 
@@ -40,46 +62,292 @@ func (h *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 	g.GET("/detail/:id", h.Detail)
 	g.POST("/list", h.List)
 
+	g.POST("/schedule", ginx.WrapBodyAndClaims(h.Schedule))
+	sg := g.Group("/scheduled")
+	sg.POST("/list", ginx.WrapBodyAndClaims(h.ListScheduled))
+	sg.POST("/reschedule", ginx.WrapBodyAndClaims(h.Reschedule))
+	sg.POST("/cancel", ginx.WrapBodyAndClaims(h.CancelSchedule))
+
+	g.POST("/delete", ginx.WrapBodyAndClaims(h.Delete))
+	trash := g.Group("/trash")
+	trash.POST("/list", ginx.WrapBodyAndClaims(h.ListDeleted))
+	trash.POST("/restore", ginx.WrapBodyAndClaims(h.Restore))
+
+	rev := g.Group("/revisions")
+	rev.POST("/list", ginx.WrapBodyAndClaims(h.ListRevisions))
+	rev.POST("/diff", ginx.WrapBodyAndClaims(h.DiffRevisions))
+	rev.POST("/restore", ginx.WrapBodyAndClaims(h.RestoreRevision))
+
 	pub := g.Group("/pub")
 	pub.GET("/:id", h.PubDetail)
 
 	pub.POST("/like", ginx.WrapBodyAndClaims(h.Like))
 	pub.POST("/collect", ginx.WrapBodyAndClaims(h.Collect))
+	pub.POST("/react", ginx.WrapBodyAndClaims(h.React))
 	pub.GET("/top/:n", h.TopNArticle)
+	pub.GET("/feed", h.Feed)
+	pub.GET("/tag/:tag", h.ListPubByTag)
+	pub.GET("/tag/:tag/top", h.TopNByTag)
+	pub.GET("/tags", h.ListTags)
+	pub.POST("/liked", ginx.WrapBodyAndClaims(h.ListLiked))
+	pub.POST("/collected", ginx.WrapBodyAndClaims(h.ListCollected))
 }
 
-// Edit 接收一个 Article 输入，返回文章 ID
+// Edit 接收一个 Article 输入，返回文章 ID 和新的版本号
 // 创建一个 Article
 func (h *ArticleHandler) Edit(ctx *gin.Context, req vo.ArticleEditReq, uc ijwt.UserClaims) (ginx.Response, error) {
-
-	id, err := h.svc.Save(ctx, h.biz, domain.Article{
+	if req.Id > 0 && req.Version <= 0 {
+		// 没有版本号就会直接覆盖别人的修改，当成冲突处理，把最新的草稿带回去
+		return h.versionConflict(ctx, uc.Uid, req.Id), errors.New("修改草稿没有带版本号")
+	}
+	if req.Price < 0 {
+		return h.invalidPrice()
+	}
+	art, err := h.svc.Save(ctx, h.biz, domain.Article{
 		Id:      req.Id,
 		Title:   req.Title,
 		Content: req.Content,
+		Tags:    req.Tags,
 		Author:  domain.Author{Id: uc.Uid},
+		Version: req.Version,
+		Price:   req.Price,
 	})
-	if err != nil {
-		ctx.JSON(http.StatusOK, ginx.Response{
-			Msg: "系统错误",
-		})
+	switch {
+	case err == nil:
+		return ginx.Response{Data: toArticleSaved(art)}, nil
+	case errors.Is(err, service.ErrInvalidTags):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "标签不合法"}, err
+	case errors.Is(err, service.ErrArticleVersionConflict):
+		return h.versionConflict(ctx, uc.Uid, req.Id), err
+	default:
 		h.l.Error("保存文章失败", logger.Int64("uid", uc.Uid), logger.Error(err))
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
 	}
-	return ginx.Response{Data: id}, nil
 }
 
 // Publish 发布文章，也可能是修订文章
 func (h *ArticleHandler) Publish(ctx *gin.Context, req vo.ArticlePublishReq, uc ijwt.UserClaims) (ginx.Response, error) {
-
-	id, err := h.svc.Publish(ctx, domain.Article{
+	if req.Id > 0 && req.Version <= 0 {
+		return h.versionConflict(ctx, uc.Uid, req.Id), errors.New("发表文章没有带版本号")
+	}
+	if req.Price < 0 {
+		return h.invalidPrice()
+	}
+	art, err := h.svc.Publish(ctx, domain.Article{
 		Id:      req.Id,
 		Title:   req.Title,
 		Content: req.Content,
+		Tags:    req.Tags,
 		Author:  domain.Author{Id: uc.Uid},
+		Version: req.Version,
+		Price:   req.Price,
 	})
+	if errors.Is(err, service.ErrInvalidTags) {
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "标签不合法"}, err
+	}
+	if errors.Is(err, service.ErrArticleVersionConflict) {
+		return h.versionConflict(ctx, uc.Uid, req.Id), err
+	}
+	if errors.Is(err, service.ErrArticlePendingReview) {
+		return ginx.Response{Code: errs.ArticlePendingReview, Msg: "文章需要审核之后才能发表", Data: toArticleSaved(art)}, err
+	}
 	if err != nil {
 		return ginx.Response{Code: 5, Msg: "系统错误"}, err
 	}
-	return ginx.Response{Data: id}, nil
+	return ginx.Response{Data: toArticleSaved(art)}, nil
+}
+
+func toArticleSaved(art domain.Article) vo.ArticleSaved {
+	return vo.ArticleSaved{Id: art.Id, Version: art.Version}
+}
+
+func (h *ArticleHandler) invalidPrice() (ginx.Response, error) {
+	return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "价格不对"}, errors.New("价格是负数")
+}
+
+// versionConflict 把服务端最新的草稿带回去，前端可以拿来和本地的内容合并
+func (h *ArticleHandler) versionConflict(ctx *gin.Context, uid, id int64) ginx.Response {
+	res := ginx.Response{Code: errs.ArticleVersionConflict, Msg: "文章已经被修改过了"}
+	art, err := h.svc.GetById(ctx, id)
+	if err != nil || art.Author.Id != uid {
+		// 拿不到最新的草稿，前端也可以自己刷新
+		h.l.Error("查询冲突的草稿失败",
+			logger.Int64("aid", id),
+			logger.Int64("uid", uid),
+			logger.Error(err))
+		return res
+	}
+	res.Data = vo.Article{
+		Id:       art.Id,
+		Title:    art.Title,
+		Content:  art.Content,
+		AuthorId: art.Author.Id,
+		Status:   art.Status.ToUint8(),
+		Tags:     art.Tags,
+		Version:  art.Version,
+		Price:    art.Price,
+		Ctime:    art.Ctime.Format(time.DateTime),
+		Utime:    art.Utime.Format(time.DateTime),
+	}
+	return res
+}
+
+// Schedule 保存文章，到了 PublishAt 的时候自动发表
+func (h *ArticleHandler) Schedule(ctx *gin.Context, req vo.ArticleScheduleReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.Id > 0 && req.Version <= 0 {
+		return h.versionConflict(ctx, uc.Uid, req.Id), errors.New("定时发表没有带版本号")
+	}
+	if req.Price < 0 {
+		return h.invalidPrice()
+	}
+	art, err := h.svc.Schedule(ctx, h.biz, domain.Article{
+		Id:        req.Id,
+		Title:     req.Title,
+		Content:   req.Content,
+		Tags:      req.Tags,
+		Author:    domain.Author{Id: uc.Uid},
+		PublishAt: time.UnixMilli(req.PublishAt),
+		Version:   req.Version,
+		Price:     req.Price,
+	})
+	switch {
+	case err == nil:
+		return ginx.Response{Data: toArticleSaved(art)}, nil
+	case errors.Is(err, service.ErrInvalidPublishTime):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "发表时间必须晚于当前时间"}, err
+	case errors.Is(err, service.ErrInvalidTags):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "标签不合法"}, err
+	case errors.Is(err, service.ErrArticleVersionConflict):
+		return h.versionConflict(ctx, uc.Uid, req.Id), err
+	default:
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+// ListScheduled 列出还没有发表的定时文章，先发表的在前
+func (h *ArticleHandler) ListScheduled(ctx *gin.Context, req vo.ArticleScheduledListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	arts, count, err := h.svc.ListScheduled(ctx, uc.Uid,
+		(req.PageIndex-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(arts, func(idx int, src domain.Article) vo.Article {
+				return vo.Article{
+					Id:        src.Id,
+					Title:     src.Title,
+					Abstract:  src.Abstract(),
+					AuthorId:  src.Author.Id,
+					Status:    src.Status.ToUint8(),
+					PublishAt: src.PublishAt.Format(time.DateTime),
+				}
+			}),
+			Count:     count,
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+// Reschedule 修改定时发表的时间
+func (h *ArticleHandler) Reschedule(ctx *gin.Context, req vo.ArticleRescheduleReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.Reschedule(ctx, uc.Uid, req.Id, time.UnixMilli(req.PublishAt))
+	switch {
+	case err == nil:
+		return ginx.Response{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrInvalidPublishTime):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "发表时间必须晚于当前时间"}, err
+	case errors.Is(err, service.ErrArticleNotScheduled):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "文章不是定时发表状态"}, err
+	default:
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+// CancelSchedule 取消定时发表，文章退回草稿
+func (h *ArticleHandler) CancelSchedule(ctx *gin.Context, req vo.ArticleCancelScheduleReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.CancelSchedule(ctx, uc.Uid, req.Id)
+	switch {
+	case err == nil:
+		return ginx.Response{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrArticleNotScheduled):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "文章不是定时发表状态"}, err
+	default:
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+// Delete 删除文章，进回收站，保留期内可以恢复
+func (h *ArticleHandler) Delete(ctx *gin.Context, req vo.ArticleDeleteReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.Delete(ctx, uc.Uid, req.Id)
+	switch {
+	case err == nil:
+		return ginx.Response{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrArticleNotFound):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "文章不存在"}, err
+	default:
+		h.l.Error("删除文章失败",
+			logger.Int64("uid", uc.Uid),
+			logger.Int64("aid", req.Id),
+			logger.Error(err))
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+// ListDeleted 回收站，最近删除的在前
+func (h *ArticleHandler) ListDeleted(ctx *gin.Context, req vo.ArticleTrashListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	arts, count, err := h.svc.ListDeleted(ctx, uc.Uid,
+		(req.PageIndex-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(arts, func(idx int, src domain.DeletedArticle) vo.DeletedArticle {
+				return vo.DeletedArticle{
+					Id:        src.Article.Id,
+					Title:     src.Article.Title,
+					Abstract:  src.Article.Abstract(),
+					Status:    src.Article.Status.ToUint8(),
+					Published: src.Published,
+					Dtime:     src.Dtime.Format(time.DateTime),
+				}
+			}),
+			Count:     count,
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+// Restore 从回收站恢复
+func (h *ArticleHandler) Restore(ctx *gin.Context, req vo.ArticleRestoreReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.Restore(ctx, uc.Uid, req.Id)
+	switch {
+	case err == nil:
+		return ginx.Response{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrDeletedArticleNotFound):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "回收站里面没有这篇文章"}, err
+	default:
+		h.l.Error("恢复文章失败",
+			logger.Int64("uid", uc.Uid),
+			logger.Int64("aid", req.Id),
+			logger.Error(err))
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
 }
 
 func (h *ArticleHandler) Detail(ctx *gin.Context) {
@@ -105,6 +373,9 @@ func (h *ArticleHandler) Detail(ctx *gin.Context) {
 		Content:  art.Content,
 		AuthorId: art.Author.Id,
 		Status:   art.Status.ToUint8(),
+		Tags:     art.Tags,
+		Version:  art.Version,
+		Price:    art.Price,
 		Ctime:    art.Ctime.Format(time.DateTime),
 		Utime:    art.Utime.Format(time.DateTime),
 	}
@@ -147,6 +418,68 @@ func (h *ArticleHandler) List(ctx *gin.Context) {
 	ginx.PageOK(ctx, g, "")
 }
 
+// ListRevisions 列出草稿的历史版本，新的在前
+func (h *ArticleHandler) ListRevisions(ctx *gin.Context, req vo.ArticleRevisionListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	revs, count, err := h.svc.ListRevisions(ctx, uc.Uid, req.Id,
+		(req.PageIndex-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(revs, func(idx int, src domain.ArticleRevision) vo.ArticleRevision {
+				return vo.ArticleRevision{
+					Id:        src.Id,
+					ArticleId: src.ArticleId,
+					Title:     src.Title,
+					Status:    src.Status.ToUint8(),
+					Ctime:     src.Ctime.Format(time.DateTime),
+				}
+			}),
+			Count:     count,
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+// DiffRevisions 对比两个历史版本
+func (h *ArticleHandler) DiffRevisions(ctx *gin.Context, req vo.ArticleRevisionDiffReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	lines, err := h.svc.DiffRevisions(ctx, uc.Uid, req.Id, req.From, req.To)
+	switch {
+	case err == nil:
+		return ginx.Response{Data: lines}, nil
+	case errors.Is(err, service.ErrArticleRevisionNotFound):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "历史版本不存在"}, err
+	default:
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+// RestoreRevision 把某个历史版本恢复成当前草稿
+func (h *ArticleHandler) RestoreRevision(ctx *gin.Context, req vo.ArticleRevisionRestoreReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.Version <= 0 {
+		return h.versionConflict(ctx, uc.Uid, req.Id), errors.New("恢复历史版本没有带版本号")
+	}
+	art, err := h.svc.RestoreRevision(ctx, h.biz, uc.Uid, req.Id, req.RevisionId, req.Version)
+	switch {
+	case err == nil:
+		return ginx.Response{Data: toArticleSaved(art)}, nil
+	case errors.Is(err, service.ErrArticleRevisionNotFound):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "历史版本不存在"}, err
+	case errors.Is(err, service.ErrArticleVersionConflict):
+		return h.versionConflict(ctx, uc.Uid, req.Id), err
+	default:
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
 func (h *ArticleHandler) PubDetail(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -155,20 +488,33 @@ func (h *ArticleHandler) PubDetail(ctx *gin.Context) {
 		return
 	}
 
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	art, err := h.svc.GetPubById(ctx, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrArticleNotFound):
+		ginx.Error(ctx, errs.ArticleInvalidInput, "文章不存在")
+		return
+	default:
+		h.l.Error("查询文章失败", logger.Int64("aid", id), logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	// 私密文章只有作者自己和拿到分享链接的人能看，
+	// 看不了的时候不能记阅读，也不用查互动数据
+	if art.Status == domain.ArticleStatusPrivate && art.Author.Id != uc.Uid && !h.canAccessShared(ctx, id, uc) {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "文章不存在")
+		return
+	}
+	h.svc.Read(ctx, id, uc.Uid)
+
 	var (
-		eg   errgroup.Group
-		art  domain.Article
-		intr *intrv1.GetResponse
+		eg      errgroup.Group
+		intr    *intrv1.GetResponse
+		related []domain.Article
+		nav     domain.SeriesNav
 	)
 
-	uc := ctx.MustGet("user").(ijwt.UserClaims)
-	eg.Go(func() error {
-		var er error
-		art, er = h.svc.GetPubById(ctx, id, uc.Uid)
-		return er
-	})
-
-	// todo 这里有 bug
 	eg.Go(func() error {
 		var er error
 		req := &intrv1.GetRequest{
@@ -179,30 +525,367 @@ func (h *ArticleHandler) PubDetail(ctx *gin.Context) {
 		intr, er = h.intrSvc.Get(ctx, req)
 		return er
 	})
+
+	// 相关文章查不到不影响看文章
+	eg.Go(func() error {
+		var er error
+		related, er = h.relatedSvc.GetRelated(ctx, id, relatedSize)
+		if er != nil {
+			h.l.Error("查询相关文章失败", logger.Int64("aid", id), logger.Error(er))
+		}
+		return nil
+	})
+
+	// 专栏导航也一样
+	eg.Go(func() error {
+		var er error
+		nav, er = h.seriesSvc.Nav(ctx, id)
+		if er != nil && !errors.Is(er, service.ErrSeriesNotFound) {
+			h.l.Error("查询专栏导航失败", logger.Int64("aid", id), logger.Error(er))
+		}
+		return nil
+	})
 	err = eg.Wait()
 	if err != nil {
 		ginx.Error(ctx, 5, "系统错误")
+		return
 	}
 
 	v := vo.Article{
 		Id:         art.Id,
 		Title:      art.Title,
 		Content:    art.Content,
+		ContentURL: art.ContentURL,
 		AuthorId:   art.Author.Id,
 		AuthorName: art.Author.Name,
 		Status:     art.Status.ToUint8(),
+		Tags:       art.Tags,
 		Ctime:      art.Ctime.Format(time.DateTime),
 		Utime:      art.Utime.Format(time.DateTime),
+		Price:      art.Price,
 
 		ReadCnt:    intr.Intr.ReadCnt,
 		LikeCnt:    intr.Intr.LikeCnt,
 		CollectCnt: intr.Intr.CollectCnt,
+		CommentCnt: intr.Intr.CommentCnt,
 		Liked:      intr.Intr.Liked,
 		Collected:  intr.Intr.Collected,
+		Reactions:  intr.Intr.Reactions,
+		Reaction:   intr.Intr.Reaction,
+
+		UniqueReadCnt: intr.Intr.UniqueReadCnt,
+
+		Related: slice.Map(related, func(idx int, src domain.Article) vo.Article {
+			return vo.Article{
+				Id:         src.Id,
+				Title:      src.Title,
+				Abstract:   src.Abstract(),
+				AuthorId:   src.Author.Id,
+				AuthorName: src.Author.Name,
+			}
+		}),
+	}
+	if !h.canReadPaid(ctx, uc.Uid, art) {
+		v.Abstract = art.Abstract()
+		v.Content = ""
+		v.ContentURL = ""
+		v.Locked = true
+	}
+	if nav.Series.Id > 0 {
+		v.Series = &vo.SeriesNav{Id: nav.Series.Id, Title: nav.Series.Title}
+		if nav.Prev.Id > 0 {
+			v.Series.Prev = &vo.Article{Id: nav.Prev.Id, Title: nav.Prev.Title}
+		}
+		if nav.Next.Id > 0 {
+			v.Series.Next = &vo.Article{Id: nav.Next.Id, Title: nav.Next.Title}
+		}
 	}
 	ginx.OK(ctx, ginx.Response{Data: v})
 }
 
+// canReadPaid 付费文章只有作者自己和买过的人能看全文，查不到购买记录的时候当作没买过
+func (h *ArticleHandler) canReadPaid(ctx *gin.Context, uid int64, art domain.Article) bool {
+	if !art.Paid() {
+		return true
+	}
+	ok, err := h.orderSvc.CanRead(ctx, uid, art)
+	if err != nil {
+		h.l.Error("查询购买记录失败", logger.Int64("aid", art.Id), logger.Int64("uid", uid), logger.Error(err))
+		return false
+	}
+	return ok
+}
+
+// canAccessShared 查询参数里面有没有这篇文章有效的分享链接
+func (h *ArticleHandler) canAccessShared(ctx *gin.Context, aid int64, uc ijwt.UserClaims) bool {
+	token := ctx.Query(shareTokenParam)
+	if token == "" {
+		return false
+	}
+	err := h.shareSvc.Access(ctx, aid, token, domain.ShareVisitor{
+		Uid:       uc.Uid,
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.GetHeader("User-Agent"),
+	})
+	if err != nil && !errors.Is(err, service.ErrInvalidShareToken) {
+		h.l.Error("校验分享链接失败", logger.Int64("aid", aid), logger.Error(err))
+	}
+	return err == nil
+}
+
+// ListPubByTag 某个标签下已发表的文章，新的在前
+func (h *ArticleHandler) ListPubByTag(ctx *gin.Context) {
+	tag := ctx.Param("tag")
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if offset < 0 || limit <= 0 || limit > 100 {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "分页参数错误")
+		return
+	}
+	arts, err := h.svc.ListPubByTag(ctx, tag, time.Now(), offset, limit)
+	if err != nil {
+		h.l.Error("按照标签查询文章失败",
+			logger.String("tag", tag), logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	ginx.OK(ctx, ginx.Response{Data: h.toTagArticles(ctx, arts)})
+}
+
+// TopNByTag 某个标签下的热榜，定时任务只算热门标签，别的标签是空的
+func (h *ArticleHandler) TopNByTag(ctx *gin.Context) {
+	tag := ctx.Param("tag")
+	arts, err := h.rankingSvc.GetTopNByTag(ctx, tag)
+	if err != nil {
+		h.l.Error("查询标签热榜失败",
+			logger.String("tag", tag), logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	ginx.OK(ctx, ginx.Response{Data: h.toTagArticles(ctx, arts)})
+}
+
+// toTagArticles 标签列表和标签热榜都只有摘要，带上当前用户的点赞、收藏状态
+func (h *ArticleHandler) toTagArticles(ctx *gin.Context, arts []domain.Article) []vo.Article {
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	statuses := h.userStatus(ctx, uc.Uid, slice.Map(arts, func(idx int, src domain.Article) int64 {
+		return src.Id
+	}))
+	return slice.Map(arts, func(idx int, src domain.Article) vo.Article {
+		return vo.Article{
+			Id:        src.Id,
+			Title:     src.Title,
+			Abstract:  src.Abstract(),
+			AuthorId:  src.Author.Id,
+			Status:    src.Status.ToUint8(),
+			Ctime:     src.Ctime.Format(time.DateTime),
+			Utime:     src.Utime.Format(time.DateTime),
+			Liked:     statuses[src.Id].GetLiked(),
+			Collected: statuses[src.Id].GetCollected(),
+		}
+	})
+}
+
+// userStatus 列表页一次查出点赞、收藏状态，查询失败的时候列表照样返回
+func (h *ArticleHandler) userStatus(ctx *gin.Context, uid int64, ids []int64) map[int64]*intrv1.UserStatus {
+	if len(ids) == 0 {
+		return nil
+	}
+	resp, err := h.intrSvc.GetUserStatus(ctx, &intrv1.GetUserStatusRequest{
+		Biz: h.biz,
+		Ids: ids,
+		Uid: uid,
+	})
+	if err != nil {
+		h.l.Error("批量查询点赞收藏状态失败", logger.Int64("uid", uid), logger.Error(err))
+		return nil
+	}
+	return resp.GetStatuses()
+}
+
+// ListLiked 我点过赞的文章，最近点赞的在前面
+func (h *ArticleHandler) ListLiked(ctx *gin.Context, req vo.ArticleInteractedListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	resp, err := h.intrSvc.ListLikedItems(ctx, &intrv1.ListLikedItemsRequest{
+		Biz:    h.biz,
+		Uid:    uc.Uid,
+		Offset: int32((req.PageIndex - 1) * req.PageSize),
+		Limit:  int32(req.PageSize),
+	})
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	ids := slice.Map(resp.GetItems(), func(idx int, src *intrv1.LikeItem) int64 {
+		return src.GetBizId()
+	})
+	return h.interactedPage(ctx, uc.Uid, ids, resp.GetTotal(), req)
+}
+
+// ListCollected 我收藏的文章，不管在哪个收藏夹，最近收藏的在前面
+func (h *ArticleHandler) ListCollected(ctx *gin.Context, req vo.ArticleInteractedListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	resp, err := h.intrSvc.ListCollectedItems(ctx, &intrv1.ListCollectedItemsRequest{
+		Biz:    h.biz,
+		Uid:    uc.Uid,
+		Offset: int32((req.PageIndex - 1) * req.PageSize),
+		Limit:  int32(req.PageSize),
+	})
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	ids := slice.Map(resp.GetItems(), func(idx int, src *intrv1.CollectionItem) int64 {
+		return src.GetBizId()
+	})
+	return h.interactedPage(ctx, uc.Uid, ids, resp.GetTotal(), req)
+}
+
+// interactedPage 按照 ids 的顺序组装文章，已经下线的文章只有 Id
+func (h *ArticleHandler) interactedPage(ctx *gin.Context, uid int64, ids []int64, total int64,
+	req vo.ArticleInteractedListReq) (ginx.Response, error) {
+	arts := make(map[int64]domain.Article, len(ids))
+	if len(ids) > 0 {
+		res, err := h.svc.GetByIds(ctx, ids)
+		if err != nil {
+			return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+		}
+		for _, art := range res {
+			arts[art.Id] = art
+		}
+	}
+	statuses := h.userStatus(ctx, uid, ids)
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(ids, func(idx int, id int64) vo.Article {
+				art := arts[id]
+				res := vo.Article{
+					Id:        id,
+					Title:     art.Title,
+					Abstract:  art.Abstract(),
+					AuthorId:  art.Author.Id,
+					Liked:     statuses[id].GetLiked(),
+					Collected: statuses[id].GetCollected(),
+				}
+				if art.Id > 0 {
+					res.Ctime = art.Ctime.Format(time.DateTime)
+					res.Utime = art.Utime.Format(time.DateTime)
+				}
+				return res
+			}),
+			Count:     total,
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+// Feed 已发表文章的信息流，新的在前，不需要登录
+func (h *ArticleHandler) Feed(ctx *gin.Context) {
+	cursor, err := decodeArticleCursor(ctx.Query("cursor"))
+	if err != nil {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "cursor 参数错误")
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "limit 参数错误")
+		return
+	}
+	// 多查一篇，用来判断还有没有下一页
+	arts, err := h.svc.ListPubByCursor(ctx, cursor, limit+1)
+	if err != nil {
+		h.l.Error("查询文章信息流失败", logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	res := vo.ArticleFeed{HasMore: len(arts) > limit}
+	if res.HasMore {
+		arts = arts[:limit]
+	}
+	res.List = slice.Map(arts, func(idx int, src domain.Article) vo.Article {
+		return vo.Article{
+			Id:       src.Id,
+			Title:    src.Title,
+			Abstract: src.Abstract(),
+			AuthorId: src.Author.Id,
+			Status:   src.Status.ToUint8(),
+			Ctime:    src.Ctime.Format(time.DateTime),
+			Utime:    src.Utime.Format(time.DateTime),
+		}
+	})
+	if len(arts) > 0 {
+		last := arts[len(arts)-1]
+		res.Cursor = encodeArticleCursor(domain.ArticleCursor{Utime: last.Utime, Id: last.Id})
+	}
+	ginx.OK(ctx, ginx.Response{Data: res})
+}
+
+// encodeArticleCursor 游标对前端是不透明的，里面是毫秒数和文章 ID
+func encodeArticleCursor(c domain.ArticleCursor) string {
+	raw := strconv.FormatInt(c.Utime.UnixMilli(), 10) + "_" + strconv.FormatInt(c.Id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeArticleCursor 空字符串表示第一页
+func decodeArticleCursor(token string) (domain.ArticleCursor, error) {
+	if token == "" {
+		return domain.ArticleCursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return domain.ArticleCursor{}, err
+	}
+	utimeStr, idStr, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return domain.ArticleCursor{}, errors.New("cursor 格式不对")
+	}
+	utime, err := strconv.ParseInt(utimeStr, 10, 64)
+	if err != nil {
+		return domain.ArticleCursor{}, err
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return domain.ArticleCursor{}, err
+	}
+	if utime <= 0 || id <= 0 {
+		return domain.ArticleCursor{}, errors.New("cursor 格式不对")
+	}
+	return domain.ArticleCursor{Utime: time.UnixMilli(utime), Id: id}, nil
+}
+
+// ListTags 标签和标签下的文章数
+func (h *ArticleHandler) ListTags(ctx *gin.Context) {
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if offset < 0 || limit <= 0 || limit > 200 {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "分页参数错误")
+		return
+	}
+	tags, err := h.svc.ListTags(ctx, offset, limit)
+	if err != nil {
+		h.l.Error("查询标签失败", logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	ginx.OK(ctx, ginx.Response{
+		Data: slice.Map(tags, func(idx int, src domain.Tag) vo.Tag {
+			return vo.Tag{
+				Name:       src.Name,
+				ArticleCnt: src.ArticleCnt,
+			}
+		}),
+	})
+}
+
 func (h *ArticleHandler) Like(ctx *gin.Context, req vo.ArticleLikeReq, uc ijwt.UserClaims) (ginx.Response, error) {
 	var err error
 
@@ -230,6 +913,32 @@ func (h *ArticleHandler) Like(ctx *gin.Context, req vo.ArticleLikeReq, uc ijwt.U
 	return ginx.Response{Msg: "OK"}, nil
 }
 
+// React 表态，一个用户对一篇文章只能有一个表态，新的会替换掉旧的
+func (h *ArticleHandler) React(ctx *gin.Context, req vo.ArticleReactReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	var err error
+	if req.Reaction == "" {
+		_, err = h.intrSvc.Unreact(ctx, &intrv1.UnreactRequest{
+			Biz:   h.biz,
+			BizId: req.Id,
+			Uid:   uc.Uid,
+		})
+	} else {
+		_, err = h.intrSvc.React(ctx, &intrv1.ReactRequest{
+			Biz:      h.biz,
+			BizId:    req.Id,
+			Uid:      uc.Uid,
+			Reaction: req.Reaction,
+		})
+	}
+	if status.Code(err) == codes.InvalidArgument {
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "不支持的表态"}, err
+	}
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{Msg: "OK"}, nil
+}
+
 func (h *ArticleHandler) Collect(ctx *gin.Context, req vo.ArticleCollectReq, uc ijwt.UserClaims) (ginx.Response, error) {
 	type Req struct {
 		Id  int64 `json:"id,omitempty"`
@@ -244,7 +953,9 @@ func (h *ArticleHandler) Collect(ctx *gin.Context, req vo.ArticleCollectReq, uc
 	}
 
 	_, err := h.intrSvc.Collect(ctx, collectReq)
-
+	if status.Code(err) == codes.NotFound {
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "收藏夹不存在"}, err
+	}
 	if err != nil {
 		ginx.Error(ctx, 5, "系统错误")
 		return ginx.Response{Code: 5, Msg: "系统错误"}, err
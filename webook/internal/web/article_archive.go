@@ -0,0 +1,95 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxImportBody 导入的 zip 最大的字节数
+const maxImportBody = 64 << 20
+
+// ArticleArchiveHandler 把自己的文章导出成 Markdown，或者从 Markdown 导入
+type ArticleArchiveHandler struct {
+	svc service.ArticleArchiveService
+	l   logger.Logger
+}
+
+func NewArticleArchiveHandler(svc service.ArticleArchiveService, l logger.Logger) *ArticleArchiveHandler {
+	return &ArticleArchiveHandler{svc: svc, l: l}
+}
+
+func (h *ArticleArchiveHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles")
+	g.GET("/export", h.Export)
+	g.POST("/import", h.Import)
+}
+
+// Export 下载一个 zip，每篇文章一个 Markdown 文件
+func (h *ArticleArchiveHandler) Export(ctx *gin.Context) {
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	// 先写到内存里面，中途失败了还能返回错误
+	var buf bytes.Buffer
+	err := h.svc.Export(ctx, uc.Uid, &buf)
+	if err != nil {
+		h.l.Error("导出文章失败", logger.Int64("uid", uc.Uid), logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	name := fmt.Sprintf("webook-articles-%s.zip", time.Now().Format("20060102"))
+	ctx.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	ctx.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// Import 表单字段 file 是 zip 文件，全部导入成草稿
+func (h *ArticleArchiveHandler) Import(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBody)
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "没有文件或者文件太大了")
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		h.l.Error("打开导入的文件失败", logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	defer f.Close()
+	ra, ok := f.(io.ReaderAt)
+	if !ok {
+		h.l.Error("导入的文件不支持随机读")
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	res, err := h.svc.Import(ctx, uc.Uid, ra, fh.Size)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrTooManyImportFiles), errors.Is(err, service.ErrInvalidArchive):
+		ginx.Error(ctx, errs.ArticleInvalidInput, err.Error())
+		return
+	default:
+		h.l.Error("导入文章失败", logger.Int64("uid", uc.Uid), logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	failures := make([]vo.ArticleImportFailure, 0, len(res.Failures))
+	for _, fail := range res.Failures {
+		failures = append(failures, vo.ArticleImportFailure{Name: fail.Name, Reason: fail.Reason})
+	}
+	ginx.OK(ctx, ginx.Response{Data: vo.ArticleImportResult{
+		Ids:      res.Ids,
+		Failures: failures,
+	}})
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"io"
+	"net/http"
+)
+
+// maxUploadBody 请求体的上限，比图片本身的上限多留一点给 multipart 的其他部分，
+// 图片本身的大小由 AttachmentService 校验
+const maxUploadBody = 32 << 20
+
+// AttachmentHandler 文章里面的图片
+type AttachmentHandler struct {
+	svc service.AttachmentService
+	l   logger.Logger
+}
+
+func NewAttachmentHandler(svc service.AttachmentService, l logger.Logger) *AttachmentHandler {
+	return &AttachmentHandler{svc: svc, l: l}
+}
+
+func (h *AttachmentHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles")
+	g.POST("/upload", h.Upload)
+	// 不需要登录，读者看文章的时候要用
+	g.GET("/attachments/:name", h.Get)
+}
+
+// Upload 表单字段 file 是图片本身
+func (h *AttachmentHandler) Upload(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxUploadBody)
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "没有图片或者图片太大了")
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		h.l.Error("打开上传的图片失败", logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		h.l.Error("读取上传的图片失败", logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	att, err := h.svc.Upload(ctx, uc.Uid, data)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		ginx.Error(ctx, errs.ArticleInvalidInput, "图片太大了")
+		return
+	case errors.Is(err, service.ErrAttachmentType):
+		ginx.Error(ctx, errs.ArticleInvalidInput, "只支持 JPEG、PNG 和 GIF 格式的图片")
+		return
+	default:
+		h.l.Error("上传图片失败", logger.Int64("uid", uc.Uid), logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	ginx.OK(ctx, ginx.Response{Data: vo.Attachment{
+		Url:      domain.AttachmentPath + att.Name(),
+		ThumbUrl: domain.AttachmentPath + att.ThumbName(),
+		Width:    att.Width,
+		Height:   att.Height,
+		Size:     att.Size,
+	}})
+}
+
+// Get 链接里面带着内容的哈希，内容不会变，所以可以一直缓存
+func (h *AttachmentHandler) Get(ctx *gin.Context) {
+	name := ctx.Param("name")
+	data, contentType, err := h.svc.Get(ctx, name)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrAttachmentNotFound):
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	default:
+		h.l.Error("读取图片失败", logger.String("name", name), logger.Error(err))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Data(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,204 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxCollectionNameLen 收藏夹名字最多多少个字
+const maxCollectionNameLen = 64
+
+var errInvalidCollectionName = errors.New("收藏夹名字不合法")
+
+// CollectionHandler 文章收藏夹。收藏本身还是走 /articles/pub/collect
+type CollectionHandler struct {
+	intrSvc intrv1.InteractiveServiceClient
+	artSvc  service.ArticleService
+	l       logger.Logger
+	biz     string
+}
+
+func NewCollectionHandler(intrSvc intrv1.InteractiveServiceClient, artSvc service.ArticleService,
+	l logger.Logger) *CollectionHandler {
+	return &CollectionHandler{
+		intrSvc: intrSvc,
+		artSvc:  artSvc,
+		l:       l,
+		biz:     "article",
+	}
+}
+
+func (h *CollectionHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/collections")
+	g.POST("/create", ginx.WrapBodyAndClaims(h.Create))
+	g.POST("/rename", ginx.WrapBodyAndClaims(h.Rename))
+	g.POST("/delete", ginx.WrapBodyAndClaims(h.Delete))
+	g.POST("/list", ginx.WrapBodyAndClaims(h.List))
+	g.POST("/items", ginx.WrapBodyAndClaims(h.Items))
+	g.POST("/items/remove", ginx.WrapBodyAndClaims(h.RemoveItem))
+	g.POST("/items/move", ginx.WrapBodyAndClaims(h.MoveItem))
+}
+
+func (h *CollectionHandler) Create(ctx *gin.Context, req vo.CollectionCreateReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	name, ok := h.checkName(req.Name)
+	if !ok {
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "收藏夹名字不能为空，也不能太长"}, errInvalidCollectionName
+	}
+	resp, err := h.intrSvc.CreateCollection(ctx, &intrv1.CreateCollectionRequest{Uid: uc.Uid, Name: name})
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{Data: resp.GetCid()}, nil
+}
+
+func (h *CollectionHandler) Rename(ctx *gin.Context, req vo.CollectionRenameReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	name, ok := h.checkName(req.Name)
+	if !ok {
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "收藏夹名字不能为空，也不能太长"}, errInvalidCollectionName
+	}
+	_, err := h.intrSvc.RenameCollection(ctx, &intrv1.RenameCollectionRequest{
+		Uid:  uc.Uid,
+		Cid:  req.Cid,
+		Name: name,
+	})
+	return h.toResponse(err)
+}
+
+func (h *CollectionHandler) Delete(ctx *gin.Context, req vo.CollectionDeleteReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	_, err := h.intrSvc.DeleteCollection(ctx, &intrv1.DeleteCollectionRequest{Uid: uc.Uid, Cid: req.Cid})
+	return h.toResponse(err)
+}
+
+// List 自己建的收藏夹，默认收藏夹前端自己放在最前面
+func (h *CollectionHandler) List(ctx *gin.Context, req vo.CollectionListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	req.PageIndex, req.PageSize = h.normalizePage(req.PageIndex, req.PageSize)
+	resp, err := h.intrSvc.ListCollections(ctx, &intrv1.ListCollectionsRequest{
+		Uid:    uc.Uid,
+		Offset: int32((req.PageIndex - 1) * req.PageSize),
+		Limit:  int32(req.PageSize),
+	})
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(resp.GetCollections(), func(idx int, src *intrv1.Collection) vo.Collection {
+				return vo.Collection{
+					Id:      src.GetId(),
+					Name:    src.GetName(),
+					ItemCnt: src.GetItemCnt(),
+					Ctime:   time.UnixMilli(src.GetCtime()).Format(time.DateTime),
+				}
+			}),
+			Count:     resp.GetTotal(),
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+// Items 收藏夹里面的文章，最近收藏的在前面
+func (h *CollectionHandler) Items(ctx *gin.Context, req vo.CollectionItemListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	req.PageIndex, req.PageSize = h.normalizePage(req.PageIndex, req.PageSize)
+	resp, err := h.intrSvc.ListCollectionItems(ctx, &intrv1.ListCollectionItemsRequest{
+		Uid:    uc.Uid,
+		Cid:    req.Cid,
+		Offset: int32((req.PageIndex - 1) * req.PageSize),
+		Limit:  int32(req.PageSize),
+	})
+	if err != nil {
+		return h.toResponse(err)
+	}
+	items := resp.GetItems()
+	arts := make(map[int64]domain.Article, len(items))
+	if len(items) > 0 {
+		res, er := h.artSvc.GetByIds(ctx, slice.Map(items, func(idx int, src *intrv1.CollectionItem) int64 {
+			return src.GetBizId()
+		}))
+		if er != nil {
+			// 文章详情查不到也能看到收藏了哪些文章
+			h.l.Error("查询收藏的文章失败", logger.Int64("uid", uc.Uid), logger.Error(er))
+		}
+		for _, art := range res {
+			arts[art.Id] = art
+		}
+	}
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(items, func(idx int, src *intrv1.CollectionItem) vo.CollectionItem {
+				art := arts[src.GetBizId()]
+				return vo.CollectionItem{
+					Aid:      src.GetBizId(),
+					Cid:      src.GetCid(),
+					Title:    art.Title,
+					Abstract: art.Abstract(),
+					AuthorId: art.Author.Id,
+					Ctime:    time.UnixMilli(src.GetCtime()).Format(time.DateTime),
+				}
+			}),
+			Count:     resp.GetTotal(),
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+// RemoveItem 取消收藏，没有收藏过也返回成功
+func (h *CollectionHandler) RemoveItem(ctx *gin.Context, req vo.CollectionItemRemoveReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	_, err := h.intrSvc.RemoveCollectionItem(ctx, &intrv1.RemoveCollectionItemRequest{
+		Biz:   h.biz,
+		BizId: req.Aid,
+		Uid:   uc.Uid,
+	})
+	return h.toResponse(err)
+}
+
+func (h *CollectionHandler) MoveItem(ctx *gin.Context, req vo.CollectionItemMoveReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	_, err := h.intrSvc.MoveCollectionItem(ctx, &intrv1.MoveCollectionItemRequest{
+		Biz:   h.biz,
+		BizId: req.Aid,
+		Cid:   req.Cid,
+		Uid:   uc.Uid,
+	})
+	return h.toResponse(err)
+}
+
+func (h *CollectionHandler) checkName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	return name, name != "" && utf8.RuneCountInString(name) <= maxCollectionNameLen
+}
+
+func (h *CollectionHandler) normalizePage(pageIndex, pageSize int) (int, int) {
+	if pageIndex <= 0 {
+		pageIndex = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	return pageIndex, pageSize
+}
+
+func (h *CollectionHandler) toResponse(err error) (ginx.Response, error) {
+	switch {
+	case err == nil:
+		return ginx.Response{Msg: "OK"}, nil
+	case status.Code(err) == codes.NotFound:
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "收藏夹或者收藏不存在"}, err
+	default:
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
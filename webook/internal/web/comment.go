@@ -0,0 +1,188 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// CommentHandler 文章的评论。评论本身也可以被点赞，点赞的 biz 是 comment
+type CommentHandler struct {
+	svc     service.CommentService
+	intrSvc intrv1.InteractiveServiceClient
+	l       logger.Logger
+	biz     string
+	// likeBiz 评论在 interactive 里面的 biz
+	likeBiz string
+}
+
+func NewCommentHandler(svc service.CommentService, intrSvc intrv1.InteractiveServiceClient, l logger.Logger) *CommentHandler {
+	return &CommentHandler{
+		svc:     svc,
+		intrSvc: intrSvc,
+		l:       l,
+		biz:     "article",
+		likeBiz: "comment",
+	}
+}
+
+func (h *CommentHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/comments")
+	g.POST("/create", ginx.WrapBodyAndClaims(h.Create))
+	g.POST("/delete", ginx.WrapBodyAndClaims(h.Delete))
+	g.POST("/like", ginx.WrapBodyAndClaims(h.Like))
+	g.GET("/list", h.List)
+	g.GET("/replies", h.Replies)
+}
+
+// Create 发表评论或者回复，返回评论 ID
+func (h *CommentHandler) Create(ctx *gin.Context, req vo.CommentCreateReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	id, err := h.svc.Create(ctx, domain.Comment{
+		Biz:         h.biz,
+		BizId:       req.BizId,
+		ParentId:    req.ParentId,
+		Content:     req.Content,
+		Commentator: domain.Author{Id: uc.Uid},
+	})
+	switch {
+	case err == nil:
+		return ginx.Response{Data: id}, nil
+	case errors.Is(err, service.ErrInvalidComment):
+		return ginx.Response{Code: errs.CommentInvalidInput, Msg: "评论内容不能为空，并且不能超过 1000 个字"}, err
+	case errors.Is(err, service.ErrCommentTargetNotFound):
+		return ginx.Response{Code: errs.CommentInvalidInput, Msg: "评论的文章或者回复的评论不存在"}, err
+	default:
+		return ginx.Response{Code: errs.CommentInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+// Delete 删除自己的评论，下面的回复会一起被删除
+func (h *CommentHandler) Delete(ctx *gin.Context, req vo.CommentDeleteReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.Delete(ctx, uc.Uid, req.Id)
+	switch {
+	case err == nil:
+		return ginx.Response{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrCommentNotFound):
+		return ginx.Response{Code: errs.CommentInvalidInput, Msg: "评论不存在"}, err
+	case errors.Is(err, service.ErrCommentPermission):
+		return ginx.Response{Code: errs.CommentInvalidInput, Msg: "只能删除自己的评论"}, err
+	default:
+		return ginx.Response{Code: errs.CommentInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+// Like 点赞或者取消点赞某条评论
+func (h *CommentHandler) Like(ctx *gin.Context, req vo.CommentLikeReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	var err error
+	if req.Like {
+		_, err = h.intrSvc.Like(ctx, &intrv1.LikeRequest{Biz: h.likeBiz, Id: req.Id, Uid: uc.Uid})
+	} else {
+		_, err = h.intrSvc.CancelLike(ctx, &intrv1.CancelLikeRequest{Biz: h.likeBiz, Id: req.Id, Uid: uc.Uid})
+	}
+	if err != nil {
+		return ginx.Response{Code: errs.CommentInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{Msg: "OK"}, nil
+}
+
+// List 文章的顶层评论，新的在前
+func (h *CommentHandler) List(ctx *gin.Context) {
+	bizId, err := strconv.ParseInt(ctx.Query("bizId"), 10, 64)
+	if err != nil {
+		ginx.Error(ctx, errs.CommentInvalidInput, "bizId 参数错误")
+		return
+	}
+	cursor, limit, ok := h.cursorParams(ctx)
+	if !ok {
+		return
+	}
+	cs, err := h.svc.ListRoots(ctx, h.biz, bizId, cursor, limit)
+	if err != nil {
+		h.l.Error("查询评论失败", logger.Int64("biz_id", bizId), logger.Error(err))
+		ginx.Error(ctx, errs.CommentInternalServerError, "系统错误")
+		return
+	}
+	ginx.OK(ctx, ginx.Response{Data: h.toList(ctx, cs, limit)})
+}
+
+// Replies 某条顶层评论下的回复，旧的在前
+func (h *CommentHandler) Replies(ctx *gin.Context) {
+	rootId, err := strconv.ParseInt(ctx.Query("rootId"), 10, 64)
+	if err != nil {
+		ginx.Error(ctx, errs.CommentInvalidInput, "rootId 参数错误")
+		return
+	}
+	cursor, limit, ok := h.cursorParams(ctx)
+	if !ok {
+		return
+	}
+	cs, err := h.svc.ListReplies(ctx, rootId, cursor, limit)
+	if err != nil {
+		h.l.Error("查询回复失败", logger.Int64("root_id", rootId), logger.Error(err))
+		ginx.Error(ctx, errs.CommentInternalServerError, "系统错误")
+		return
+	}
+	ginx.OK(ctx, ginx.Response{Data: h.toList(ctx, cs, limit)})
+}
+
+func (h *CommentHandler) cursorParams(ctx *gin.Context) (int64, int, bool) {
+	cursor, err := strconv.ParseInt(ctx.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil || cursor < 0 {
+		ginx.Error(ctx, errs.CommentInvalidInput, "cursor 参数错误")
+		return 0, 0, false
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		ginx.Error(ctx, errs.CommentInvalidInput, "limit 参数错误")
+		return 0, 0, false
+	}
+	return cursor, limit, true
+}
+
+// toList 带上点赞数，点赞数查询失败的时候不影响评论本身的展示
+func (h *CommentHandler) toList(ctx *gin.Context, cs []domain.Comment, limit int) vo.CommentList {
+	var intrs map[int64]*intrv1.Interactive
+	if len(cs) > 0 {
+		resp, err := h.intrSvc.GetByIds(ctx, &intrv1.GetByIdsRequest{
+			Biz: h.likeBiz,
+			Ids: slice.Map(cs, func(idx int, src domain.Comment) int64 {
+				return src.Id
+			}),
+		})
+		if err != nil {
+			h.l.Warn("查询评论点赞数失败", logger.Error(err))
+		} else {
+			intrs = resp.GetIntrs()
+		}
+	}
+	res := vo.CommentList{
+		List: slice.Map(cs, func(idx int, src domain.Comment) vo.Comment {
+			return vo.Comment{
+				Id:         src.Id,
+				BizId:      src.BizId,
+				Uid:        src.Commentator.Id,
+				Content:    src.Content,
+				RootId:     src.RootId,
+				ParentId:   src.ParentId,
+				ReplyToUid: src.ReplyTo.Id,
+				LikeCnt:    intrs[src.Id].GetLikeCnt(),
+				Ctime:      src.Ctime.Format(time.DateTime),
+			}
+		}),
+		HasMore: len(cs) == limit,
+	}
+	if len(cs) > 0 {
+		res.Cursor = cs[len(cs)-1].Id
+	}
+	return res
+}
@@ -0,0 +1,222 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	intrv1 "github.com/jayleonc/geektime-go/webook/api/proto/gen/intr/v1"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// FollowHandler 关注作者，以及关注的人发表的文章组成的信息流
+type FollowHandler struct {
+	svc     service.FollowService
+	feedSvc service.FeedService
+	intrSvc intrv1.InteractiveServiceClient
+	l       logger.Logger
+	biz     string
+}
+
+func NewFollowHandler(svc service.FollowService, feedSvc service.FeedService,
+	intrSvc intrv1.InteractiveServiceClient, l logger.Logger) *FollowHandler {
+	return &FollowHandler{
+		svc:     svc,
+		feedSvc: feedSvc,
+		intrSvc: intrSvc,
+		l:       l,
+		biz:     "article",
+	}
+}
+
+func (h *FollowHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/follow")
+	g.POST("/follow", ginx.WrapBodyAndClaims(h.Follow))
+	g.POST("/unfollow", ginx.WrapBodyAndClaims(h.Unfollow))
+	g.POST("/followees", ginx.WrapBodyAndClaims(h.ListFollowees))
+	g.POST("/followers", ginx.WrapBodyAndClaims(h.ListFollowers))
+	g.GET("/statics/:uid", h.Statics)
+	g.GET("/feed", h.Feed)
+}
+
+func (h *FollowHandler) Follow(ctx *gin.Context, req vo.FollowReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.Follow(ctx, uc.Uid, req.Followee)
+	switch {
+	case err == nil:
+		return ginx.Response{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrFollowSelf):
+		return ginx.Response{Code: errs.UserInvalidInput, Msg: "不能关注自己"}, err
+	case errors.Is(err, service.ErrFolloweeNotFound):
+		return ginx.Response{Code: errs.UserInvalidInput, Msg: "用户不存在"}, err
+	default:
+		return ginx.Response{Code: errs.UserInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+func (h *FollowHandler) Unfollow(ctx *gin.Context, req vo.FollowReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.Unfollow(ctx, uc.Uid, req.Followee)
+	if err != nil {
+		return ginx.Response{Code: errs.UserInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{Msg: "OK"}, nil
+}
+
+// ListFollowees 关注的人，最近关注的在前
+func (h *FollowHandler) ListFollowees(ctx *gin.Context, req vo.FollowListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	return h.list(ctx, req, uc, func(uid int64, offset, limit int) ([]domain.FollowRelation, error) {
+		return h.svc.ListFollowees(ctx, uid, offset, limit)
+	}, func(rel domain.FollowRelation) int64 {
+		return rel.Followee
+	}, func(statics domain.FollowStatics) int64 {
+		return statics.Followees
+	})
+}
+
+// ListFollowers 粉丝，最近关注的在前
+func (h *FollowHandler) ListFollowers(ctx *gin.Context, req vo.FollowListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	return h.list(ctx, req, uc, func(uid int64, offset, limit int) ([]domain.FollowRelation, error) {
+		return h.svc.ListFollowers(ctx, uid, offset, limit)
+	}, func(rel domain.FollowRelation) int64 {
+		return rel.Follower
+	}, func(statics domain.FollowStatics) int64 {
+		return statics.Followers
+	})
+}
+
+// list 总数直接用关注数和粉丝数，不用再 COUNT 一次
+func (h *FollowHandler) list(ctx *gin.Context, req vo.FollowListReq, uc ijwt.UserClaims,
+	find func(uid int64, offset, limit int) ([]domain.FollowRelation, error),
+	other func(rel domain.FollowRelation) int64,
+	count func(statics domain.FollowStatics) int64) (ginx.Response, error) {
+	if req.Uid <= 0 {
+		req.Uid = uc.Uid
+	}
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	rels, err := find(req.Uid, (req.PageIndex-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return ginx.Response{Code: errs.UserInternalServerError, Msg: "系统错误"}, err
+	}
+	statics, err := h.svc.GetStatics(ctx, req.Uid)
+	if err != nil {
+		return ginx.Response{Code: errs.UserInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(rels, func(idx int, src domain.FollowRelation) vo.FollowUser {
+				return vo.FollowUser{
+					Uid:   other(src),
+					Ctime: src.Ctime.Format(time.DateTime),
+				}
+			}),
+			Count:     count(statics),
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+// Statics 某个用户的关注数和粉丝数，以及当前用户有没有关注他
+func (h *FollowHandler) Statics(ctx *gin.Context) {
+	uid, err := strconv.ParseInt(ctx.Param("uid"), 10, 64)
+	if err != nil {
+		ginx.Error(ctx, errs.UserInvalidInput, "uid 参数错误")
+		return
+	}
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	statics, err := h.svc.GetStatics(ctx, uid)
+	if err != nil {
+		h.l.Error("查询关注数失败", logger.Int64("uid", uid), logger.Error(err))
+		ginx.Error(ctx, errs.UserInternalServerError, "系统错误")
+		return
+	}
+	res := vo.FollowStatics{
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+	}
+	if uid != uc.Uid {
+		res.Followed, err = h.svc.IsFollowing(ctx, uc.Uid, uid)
+		if err != nil {
+			h.l.Warn("查询关注关系失败", logger.Int64("uid", uid), logger.Error(err))
+		}
+	}
+	ginx.OK(ctx, ginx.Response{Data: res})
+}
+
+// Feed 关注的人发表的文章，新的在前，带上阅读、点赞、收藏和评论数
+func (h *FollowHandler) Feed(ctx *gin.Context) {
+	cursor, err := decodeArticleCursor(ctx.Query("cursor"))
+	if err != nil {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "cursor 参数错误")
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 50 {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "limit 参数错误")
+		return
+	}
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	// 多查一篇，用来判断还有没有下一页
+	items, err := h.feedSvc.GetFeed(ctx, uc.Uid, cursor, limit+1)
+	if err != nil {
+		h.l.Error("查询关注的信息流失败", logger.Int64("uid", uc.Uid), logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	res := vo.ArticleFeed{HasMore: len(items) > limit}
+	if res.HasMore {
+		items = items[:limit]
+	}
+	intrs := h.getIntrs(ctx, items)
+	res.List = slice.Map(items, func(idx int, src domain.FeedItem) vo.Article {
+		intr := intrs[src.Article.Id]
+		return vo.Article{
+			Id:         src.Article.Id,
+			Title:      src.Article.Title,
+			Abstract:   src.Article.Abstract(),
+			AuthorId:   src.Article.Author.Id,
+			AuthorName: src.Article.Author.Name,
+			Status:     src.Article.Status.ToUint8(),
+			Ctime:      src.Article.Ctime.Format(time.DateTime),
+			Utime:      src.Article.Utime.Format(time.DateTime),
+			ReadCnt:    intr.GetReadCnt(),
+			LikeCnt:    intr.GetLikeCnt(),
+			CollectCnt: intr.GetCollectCnt(),
+			CommentCnt: intr.GetCommentCnt(),
+		}
+	})
+	if len(items) > 0 {
+		last := items[len(items)-1]
+		res.Cursor = encodeArticleCursor(domain.ArticleCursor{Utime: last.Ftime, Id: last.Article.Id})
+	}
+	ginx.OK(ctx, ginx.Response{Data: res})
+}
+
+// getIntrs 计数查询失败的时候不影响信息流本身
+func (h *FollowHandler) getIntrs(ctx *gin.Context, items []domain.FeedItem) map[int64]*intrv1.Interactive {
+	if len(items) == 0 {
+		return nil
+	}
+	resp, err := h.intrSvc.GetByIds(ctx, &intrv1.GetByIdsRequest{
+		Biz: h.biz,
+		Ids: slice.Map(items, func(idx int, src domain.FeedItem) int64 {
+			return src.Article.Id
+		}),
+	})
+	if err != nil {
+		h.l.Warn("查询信息流的计数失败", logger.Error(err))
+		return nil
+	}
+	return resp.GetIntrs()
+}
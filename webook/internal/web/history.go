@@ -0,0 +1,114 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// ReadHistoryHandler 阅读历史和阅读进度
+type ReadHistoryHandler struct {
+	svc service.ReadHistoryService
+	l   logger.Logger
+}
+
+func NewReadHistoryHandler(svc service.ReadHistoryService, l logger.Logger) *ReadHistoryHandler {
+	return &ReadHistoryHandler{svc: svc, l: l}
+}
+
+func (h *ReadHistoryHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users/history")
+	g.GET("", h.List)
+	g.POST("/clear", ginx.WarpClaims(h.Clear))
+	g.POST("/progress", ginx.WrapBodyAndClaims(h.ReportProgress))
+	g.GET("/progress/:aid", h.GetProgress)
+}
+
+// List 最近看过的在前，pageIndex 和 pageSize 放在查询参数里面
+func (h *ReadHistoryHandler) List(ctx *gin.Context) {
+	pageIndex, err := strconv.Atoi(ctx.DefaultQuery("pageIndex", "1"))
+	if err != nil || pageIndex <= 0 {
+		ginx.Error(ctx, errs.UserInvalidInput, "pageIndex 参数错误")
+		return
+	}
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("pageSize", "20"))
+	if err != nil || pageSize <= 0 || pageSize > 100 {
+		ginx.Error(ctx, errs.UserInvalidInput, "pageSize 参数错误")
+		return
+	}
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	hs, count, err := h.svc.List(ctx, uc.Uid, (pageIndex-1)*pageSize, pageSize)
+	if err != nil {
+		h.l.Error("查询阅读历史失败", logger.Int64("uid", uc.Uid), logger.Error(err))
+		ginx.Error(ctx, errs.UserInternalServerError, "系统错误")
+		return
+	}
+	ginx.OK(ctx, ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(hs, func(idx int, src domain.ReadHistory) vo.ReadHistory {
+				return vo.ReadHistory{
+					Aid:        src.Article.Id,
+					Title:      src.Article.Title,
+					Abstract:   src.Article.Abstract(),
+					AuthorId:   src.Article.Author.Id,
+					AuthorName: src.Article.Author.Name,
+					Progress:   src.Progress,
+					Rtime:      src.Rtime.Format(time.DateTime),
+				}
+			}),
+			Count:     count,
+			PageIndex: pageIndex,
+			PageSize:  pageSize,
+		},
+	})
+}
+
+func (h *ReadHistoryHandler) Clear(ctx *gin.Context, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.Clear(ctx, uc.Uid)
+	if err != nil {
+		return ginx.Response{Code: errs.UserInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{Msg: "OK"}, nil
+}
+
+func (h *ReadHistoryHandler) ReportProgress(ctx *gin.Context, req vo.ReadProgressReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.Aid <= 0 {
+		return ginx.Response{Code: errs.UserInvalidInput, Msg: "文章 ID 不对"}, errors.New("上报阅读进度没有带文章 ID")
+	}
+	err := h.svc.ReportProgress(ctx, uc.Uid, req.Aid, req.Progress)
+	switch {
+	case err == nil:
+		return ginx.Response{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrInvalidProgress):
+		return ginx.Response{Code: errs.UserInvalidInput, Msg: "阅读进度要在 0 到 1 之间"}, err
+	default:
+		return ginx.Response{Code: errs.UserInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+// GetProgress 打开文章的时候用来跳到上次读到的地方
+func (h *ReadHistoryHandler) GetProgress(ctx *gin.Context) {
+	aid, err := strconv.ParseInt(ctx.Param("aid"), 10, 64)
+	if err != nil {
+		ginx.Error(ctx, errs.UserInvalidInput, "aid 参数错误")
+		return
+	}
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	progress, err := h.svc.GetProgress(ctx, uc.Uid, aid)
+	if err != nil {
+		h.l.Error("查询阅读进度失败", logger.Int64("uid", uc.Uid),
+			logger.Int64("aid", aid), logger.Error(err))
+		ginx.Error(ctx, errs.UserInternalServerError, "系统错误")
+		return
+	}
+	ginx.OK(ctx, ginx.Response{Data: vo.ReadProgress{Aid: aid, Progress: progress}})
+}
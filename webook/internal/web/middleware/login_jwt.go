@@ -6,6 +6,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
 	"net/http"
+	"strings"
 )
 
 type LoginJWTMiddlewareBuilder struct {
@@ -26,7 +27,16 @@ func (m *LoginJWTMiddlewareBuilder) CheckLogin() gin.HandlerFunc {
 			path == "/users/login_sms/code/send" ||
 			path == "/users/login_sms" ||
 			path == "/oauth2/wechat/authurl" ||
-			path == "/oauth2/wechat/callback" {
+			path == "/oauth2/wechat/callback" ||
+			path == "/articles/pub/feed" ||
+			// 支付网关的回调自己带了签名
+			path == "/pay/notify" ||
+			// 对象的下载链接自己带了签名
+			strings.HasPrefix(path, "/objects/") ||
+			// 文章里面的图片，读者不登录也要能看
+			strings.HasPrefix(path, "/articles/attachments/") ||
+			// RSS 阅读器不会登录
+			strings.HasPrefix(path, "/feeds/") {
 			return
 		}
 		// 检查头部 Authorization
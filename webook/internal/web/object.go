@@ -0,0 +1,56 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/objstore"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ObjectHandler 本地对象存储的下载接口，校验 SignURL 生成的签名。
+// 用 S3 的时候签名链接直接指向 S3，这里什么路由也不注册
+type ObjectHandler struct {
+	store *objstore.LocalStore
+	l     logger.Logger
+}
+
+func NewObjectHandler(store objstore.ObjectStore, l logger.Logger) *ObjectHandler {
+	local, _ := store.(*objstore.LocalStore)
+	return &ObjectHandler{store: local, l: l}
+}
+
+func (h *ObjectHandler) RegisterRoutes(server *gin.Engine) {
+	if h.store == nil {
+		return
+	}
+	server.GET("/objects/*key", h.Get)
+}
+
+func (h *ObjectHandler) Get(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	err := h.store.Verify(key, ctx.Query("expires"), ctx.Query("signature"))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	data, err := h.store.Get(ctx, key)
+	switch {
+	case err == nil:
+	case errors.Is(err, objstore.ErrObjectNotFound):
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	default:
+		h.l.Error("读取对象失败", logger.String("key", key), logger.Error(err))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	ctx.Data(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,126 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/internal/service/payment"
+	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"net/http"
+	"time"
+)
+
+// OrderHandler 购买付费文章，以及接收支付网关的回调
+type OrderHandler struct {
+	svc     service.OrderService
+	gateway payment.Gateway
+	l       logger.Logger
+}
+
+func NewOrderHandler(svc service.OrderService, gateway payment.Gateway, l logger.Logger) *OrderHandler {
+	return &OrderHandler{svc: svc, gateway: gateway, l: l}
+}
+
+func (h *OrderHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/orders")
+	g.POST("/buy", ginx.WrapBodyAndClaims(h.Buy))
+	g.POST("/list", ginx.WrapBodyAndClaims(h.List))
+	g.GET("/:sn", h.Detail)
+	// 支付网关回调，不需要登录，靠签名
+	server.POST("/pay/notify", h.Notify)
+}
+
+func (h *OrderHandler) Buy(ctx *gin.Context, req vo.OrderBuyReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	o, err := h.svc.Buy(ctx, uc.Uid, req.Aid)
+	switch {
+	case err == nil:
+		return ginx.Response{Data: h.toVO(o)}, nil
+	case errors.Is(err, service.ErrArticleNotFound):
+		return ginx.Response{Code: errs.OrderInvalidInput, Msg: "文章不存在"}, err
+	case errors.Is(err, service.ErrArticleFree):
+		return ginx.Response{Code: errs.OrderInvalidInput, Msg: "这篇文章不用购买"}, err
+	case errors.Is(err, service.ErrArticlePurchased):
+		return ginx.Response{Code: errs.OrderArticlePurchased, Msg: "已经买过了"}, err
+	default:
+		return ginx.Response{Code: errs.OrderInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+func (h *OrderHandler) List(ctx *gin.Context, req vo.OrderListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	os, count, err := h.svc.ListByUser(ctx, uc.Uid, (req.PageIndex-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return ginx.Response{Code: errs.OrderInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(os, func(idx int, src domain.Order) vo.Order {
+				return h.toVO(src)
+			}),
+			Count:     count,
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+// Detail 前端跳去支付之后，轮询这个接口看有没有付款成功
+func (h *OrderHandler) Detail(ctx *gin.Context) {
+	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	o, err := h.svc.GetBySN(ctx, uc.Uid, ctx.Param("sn"))
+	switch {
+	case err == nil:
+		ginx.OK(ctx, ginx.Response{Data: h.toVO(o)})
+	case errors.Is(err, service.ErrOrderNotFound):
+		ginx.Error(ctx, errs.OrderInvalidInput, "订单不存在")
+	default:
+		h.l.Error("查询订单失败", logger.String("sn", ctx.Param("sn")), logger.Error(err))
+		ginx.Error(ctx, errs.OrderInternalServerError, "系统错误")
+	}
+}
+
+// Notify 支付网关的异步回调。返回 200 之外的状态码，网关会重试
+func (h *OrderHandler) Notify(ctx *gin.Context) {
+	res, err := h.gateway.ParseNotify(ctx.Request)
+	if err != nil {
+		h.l.Error("支付回调不合法", logger.Error(err))
+		ctx.Status(http.StatusBadRequest)
+		return
+	}
+	err = h.svc.HandlePayment(ctx, res)
+	switch {
+	case err == nil:
+		ctx.Status(http.StatusOK)
+	case errors.Is(err, service.ErrInvalidPayment), errors.Is(err, service.ErrOrderNotFound):
+		// 重试也没用，不然支付平台会一直重试，要人工处理
+		h.l.Error("支付回调和订单对不上", logger.String("sn", res.SN), logger.Error(err))
+		ctx.Status(http.StatusBadRequest)
+	default:
+		// 数据库之类的临时错误，让支付平台重试
+		h.l.Error("处理支付回调失败", logger.String("sn", res.SN), logger.Error(err))
+		ctx.Status(http.StatusInternalServerError)
+	}
+}
+
+func (h *OrderHandler) toVO(o domain.Order) vo.Order {
+	return vo.Order{
+		SN:     o.SN,
+		Aid:    o.Aid,
+		Amount: o.Amount,
+		Status: o.Status.ToUint8(),
+		PayURL: o.PayURL,
+		Ctime:  o.Ctime.Format(time.DateTime),
+		Utime:  o.Utime.Format(time.DateTime),
+	}
+}
@@ -0,0 +1,167 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxReasonLen 驳回理由最长的字符数
+const maxReasonLen = 256
+
+// ReviewHandler 管理员处理敏感内容的审核队列，还有评论的审核
+type ReviewHandler struct {
+	svc        service.ArticleService
+	commentSvc service.CommentService
+	admins     map[int64]struct{}
+	l          logger.Logger
+}
+
+// NewReviewHandler admins 是管理员的用户 ID，只有他们能访问审核接口
+func NewReviewHandler(svc service.ArticleService, commentSvc service.CommentService,
+	admins []int64, l logger.Logger) *ReviewHandler {
+	set := make(map[int64]struct{}, len(admins))
+	for _, uid := range admins {
+		set[uid] = struct{}{}
+	}
+	return &ReviewHandler{
+		svc:        svc,
+		commentSvc: commentSvc,
+		admins:     set,
+		l:          l,
+	}
+}
+
+func (h *ReviewHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/admin/reviews", h.checkAdmin)
+	g.POST("/list", ginx.WrapBodyAndClaims(h.List))
+	g.POST("/approve", ginx.WrapBodyAndClaims(h.Approve))
+	g.POST("/reject", ginx.WrapBodyAndClaims(h.Reject))
+
+	cg := server.Group("/admin/comments", h.checkAdmin)
+	cg.POST("/approve", ginx.WrapBodyAndClaims(h.ApproveComment))
+	cg.POST("/reject", ginx.WrapBodyAndClaims(h.RejectComment))
+}
+
+// checkAdmin 登录校验在前面的中间件里面已经做过了
+func (h *ReviewHandler) checkAdmin(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(ijwt.UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if _, ok = h.admins[uc.Uid]; !ok {
+		ctx.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+}
+
+func (h *ReviewHandler) List(ctx *gin.Context, req vo.ReviewListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	reviews, count, err := h.svc.ListReviews(ctx, domain.ReviewStatus(req.Status),
+		(req.PageIndex-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(reviews, func(idx int, src domain.ArticleReview) vo.ArticleReview {
+				return vo.ArticleReview{
+					Id:         src.Id,
+					ArticleId:  src.ArticleId,
+					AuthorId:   src.Author.Id,
+					Title:      src.Title,
+					Content:    src.Content,
+					Hits:       src.Hits,
+					Status:     src.Status.ToUint8(),
+					ReviewerId: src.ReviewerId,
+					Reason:     src.Reason,
+					Ctime:      src.Ctime.Format(time.DateTime),
+					Utime:      src.Utime.Format(time.DateTime),
+				}
+			}),
+			Count:     count,
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+// Approve 通过之后文章马上发表
+func (h *ReviewHandler) Approve(ctx *gin.Context, req vo.ReviewApproveReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.ApproveReview(ctx, uc.Uid, req.Id)
+	return h.result(uc, req.Id, err)
+}
+
+// Reject 驳回，文章退回草稿
+func (h *ReviewHandler) Reject(ctx *gin.Context, req vo.ReviewRejectReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || utf8.RuneCountInString(req.Reason) > maxReasonLen {
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "驳回理由不合法"}, errors.New("驳回理由不合法")
+	}
+	err := h.svc.RejectReview(ctx, uc.Uid, req.Id, req.Reason)
+	return h.result(uc, req.Id, err)
+}
+
+func (h *ReviewHandler) result(uc ijwt.UserClaims, id int64, err error) (ginx.Response, error) {
+	switch {
+	case err == nil:
+		return ginx.Response{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrArticleReviewNotPending):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "审核记录不存在或者已经处理过了"}, err
+	case errors.Is(err, service.ErrArticleReviewOutdated):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "文章在审核期间被修改过了，请驳回"}, err
+	default:
+		h.l.Error("处理审核失败",
+			logger.Int64("reviewer", uc.Uid),
+			logger.Int64("id", id),
+			logger.Error(err))
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+// ApproveComment 评论审核通过，展示出来并且计入评论数
+func (h *ReviewHandler) ApproveComment(ctx *gin.Context, req vo.CommentModerateReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.commentSvc.UpdateStatus(ctx, req.Id, domain.CommentStatusApproved)
+	return h.commentResult(uc, req.Id, err)
+}
+
+// RejectComment 评论审核不通过，已经展示的评论会被撤下来
+func (h *ReviewHandler) RejectComment(ctx *gin.Context, req vo.CommentModerateReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.commentSvc.UpdateStatus(ctx, req.Id, domain.CommentStatusRejected)
+	return h.commentResult(uc, req.Id, err)
+}
+
+func (h *ReviewHandler) commentResult(uc ijwt.UserClaims, id int64, err error) (ginx.Response, error) {
+	switch {
+	case err == nil:
+		h.l.Info("审核评论",
+			logger.Int64("reviewer", uc.Uid),
+			logger.Int64("id", id))
+		return ginx.Response{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrCommentNotFound):
+		return ginx.Response{Code: errs.CommentInvalidInput, Msg: "评论不存在"}, err
+	default:
+		h.l.Error("审核评论失败",
+			logger.Int64("reviewer", uc.Uid),
+			logger.Int64("id", id),
+			logger.Error(err))
+		return ginx.Response{Code: errs.CommentInternalServerError, Msg: "系统错误"}, err
+	}
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"strconv"
+	"time"
+)
+
+type SearchHandler struct {
+	svc service.SearchService
+	l   logger.Logger
+}
+
+func NewSearchHandler(svc service.SearchService, l logger.Logger) *SearchHandler {
+	return &SearchHandler{svc: svc, l: l}
+}
+
+func (h *SearchHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/search")
+	g.GET("/articles", h.SearchArticle)
+}
+
+// SearchArticle 搜索已发表的文章，相关度高的在前
+func (h *SearchHandler) SearchArticle(ctx *gin.Context) {
+	q := ctx.Query("q")
+	pageIndex, _ := strconv.Atoi(ctx.DefaultQuery("pageIndex", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("pageSize", "20"))
+	if pageIndex <= 0 || pageSize <= 0 || pageSize > 100 {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "分页参数错误")
+		return
+	}
+	hits, total, err := h.svc.SearchArticle(ctx, q, (pageIndex-1)*pageSize, pageSize)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrInvalidSearchQuery):
+		ginx.Error(ctx, errs.ArticleInvalidInput, "搜索关键字不合法")
+		return
+	default:
+		h.l.Error("搜索文章失败", logger.String("q", q), logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	ginx.OK(ctx, ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(hits, func(idx int, src domain.ArticleSearchHit) vo.ArticleSearchHit {
+				return vo.ArticleSearchHit{
+					Id:       src.Article.Id,
+					Title:    src.HighlightTitle,
+					Abstract: src.HighlightSnippet,
+					AuthorId: src.Article.Author.Id,
+					Tags:     src.Article.Tags,
+					Score:    src.Score,
+					Utime:    src.Article.Utime.Format(time.DateTime),
+				}
+			}),
+			Count:     int64(total),
+			PageIndex: pageIndex,
+			PageSize:  pageSize,
+		},
+	})
+}
@@ -0,0 +1,162 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// SeriesHandler 专栏，作者管理自己的专栏，读者看专栏页
+type SeriesHandler struct {
+	svc service.SeriesService
+	l   logger.Logger
+}
+
+func NewSeriesHandler(svc service.SeriesService, l logger.Logger) *SeriesHandler {
+	return &SeriesHandler{svc: svc, l: l}
+}
+
+func (h *SeriesHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/series")
+	g.POST("/create", ginx.WrapBodyAndClaims(h.Create))
+	g.POST("/update", ginx.WrapBodyAndClaims(h.Update))
+	g.POST("/delete", ginx.WrapBodyAndClaims(h.Delete))
+	g.POST("/articles", ginx.WrapBodyAndClaims(h.SetArticles))
+	g.POST("/list", ginx.WrapBodyAndClaims(h.List))
+	g.GET("/:id", h.Detail)
+}
+
+func (h *SeriesHandler) Create(ctx *gin.Context, req vo.SeriesEditReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	id, err := h.svc.Create(ctx, domain.Series{
+		Author:      domain.Author{Id: uc.Uid},
+		Title:       req.Title,
+		Description: req.Description,
+	})
+	if err != nil {
+		return h.errResponse(err)
+	}
+	return ginx.Response{Data: id}, nil
+}
+
+func (h *SeriesHandler) Update(ctx *gin.Context, req vo.SeriesEditReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.Update(ctx, domain.Series{
+		Id:          req.Id,
+		Author:      domain.Author{Id: uc.Uid},
+		Title:       req.Title,
+		Description: req.Description,
+	})
+	if err != nil {
+		return h.errResponse(err)
+	}
+	return ginx.Response{Msg: "OK"}, nil
+}
+
+func (h *SeriesHandler) Delete(ctx *gin.Context, req vo.SeriesDeleteReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if err := h.svc.Delete(ctx, uc.Uid, req.Id); err != nil {
+		return h.errResponse(err)
+	}
+	return ginx.Response{Msg: "OK"}, nil
+}
+
+func (h *SeriesHandler) SetArticles(ctx *gin.Context, req vo.SeriesArticlesReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if err := h.svc.SetArticles(ctx, uc.Uid, req.Id, req.Aids); err != nil {
+		return h.errResponse(err)
+	}
+	return ginx.Response{Msg: "OK"}, nil
+}
+
+func (h *SeriesHandler) List(ctx *gin.Context, req vo.SeriesListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	ss, count, err := h.svc.ListByAuthor(ctx, uc.Uid, (req.PageIndex-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(ss, func(idx int, src domain.Series) vo.Series {
+				return h.toVO(src)
+			}),
+			Count:     count,
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+// Detail 专栏页，只有已经发表的文章
+func (h *SeriesHandler) Detail(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ginx.Error(ctx, errs.ArticleInvalidInput, "id 参数错误")
+		return
+	}
+	detail, err := h.svc.Detail(ctx, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrSeriesNotFound):
+		ginx.Error(ctx, errs.ArticleInvalidInput, "专栏不存在")
+		return
+	default:
+		h.l.Error("查询专栏失败", logger.Int64("sid", id), logger.Error(err))
+		ginx.Error(ctx, errs.ArticleInternalServerError, "系统错误")
+		return
+	}
+	v := vo.SeriesDetail{
+		Series: h.toVO(detail.Series),
+		Articles: slice.Map(detail.Articles, func(idx int, src domain.Article) vo.Article {
+			return vo.Article{
+				Id:         src.Id,
+				Title:      src.Title,
+				Abstract:   src.Abstract(),
+				AuthorId:   src.Author.Id,
+				AuthorName: src.Author.Name,
+				Utime:      src.Utime.Format(time.DateTime),
+			}
+		}),
+		ReadCnt:    detail.ReadCnt,
+		LikeCnt:    detail.LikeCnt,
+		CollectCnt: detail.CollectCnt,
+		CommentCnt: detail.CommentCnt,
+	}
+	ginx.OK(ctx, ginx.Response{Data: v})
+}
+
+func (h *SeriesHandler) errResponse(err error) (ginx.Response, error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSeries):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "标题、简介或者文章不对"}, err
+	case errors.Is(err, service.ErrSeriesNotFound):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "专栏不存在"}, err
+	case errors.Is(err, service.ErrArticleNotFound):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "文章不存在"}, err
+	case errors.Is(err, service.ErrArticleInOtherSeries):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "文章已经在别的专栏里面了"}, err
+	default:
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+func (h *SeriesHandler) toVO(s domain.Series) vo.Series {
+	return vo.Series{
+		Id:          s.Id,
+		AuthorId:    s.Author.Id,
+		Title:       s.Title,
+		Description: s.Description,
+		Ctime:       s.Ctime.Format(time.DateTime),
+		Utime:       s.Utime.Format(time.DateTime),
+	}
+}
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/errs"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	ijwt "github.com/jayleonc/geektime-go/webook/internal/web/jwt"
+	"github.com/jayleonc/geektime-go/webook/internal/web/vo"
+	"github.com/jayleonc/geektime-go/webook/pkg/ginx"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"net/url"
+	"time"
+)
+
+// shareTokenParam 文章详情页带分享链接 token 的查询参数
+const shareTokenParam = "share_token"
+
+// ArticleShareHandler 作者管理仅自己可见的文章的分享链接
+type ArticleShareHandler struct {
+	svc service.ArticleShareService
+	l   logger.Logger
+}
+
+func NewArticleShareHandler(svc service.ArticleShareService, l logger.Logger) *ArticleShareHandler {
+	return &ArticleShareHandler{svc: svc, l: l}
+}
+
+func (h *ArticleShareHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles/shares")
+	g.POST("/create", ginx.WrapBodyAndClaims(h.Create))
+	g.POST("/list", ginx.WrapBodyAndClaims(h.List))
+	g.POST("/revoke", ginx.WrapBodyAndClaims(h.Revoke))
+	g.POST("/audits", ginx.WrapBodyAndClaims(h.ListAudits))
+}
+
+func (h *ArticleShareHandler) Create(ctx *gin.Context, req vo.ArticleShareReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	share, err := h.svc.Create(ctx, uc.Uid, req.Aid, time.Duration(req.TTL)*time.Second)
+	switch {
+	case err == nil:
+		v := h.toVO(share)
+		v.Token = share.Token
+		v.Link = fmt.Sprintf("/articles/pub/%d?%s=%s", share.Aid, shareTokenParam, url.QueryEscape(share.Token))
+		return ginx.Response{Data: v}, nil
+	case errors.Is(err, service.ErrInvalidShareTTL):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "有效期不对"}, err
+	case errors.Is(err, service.ErrArticleNotFound):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "文章不存在"}, err
+	default:
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+func (h *ArticleShareHandler) List(ctx *gin.Context, req vo.ArticleShareListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	shares, err := h.svc.List(ctx, uc.Uid, req.Aid)
+	if err != nil {
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{
+		Data: slice.Map(shares, func(idx int, src domain.ArticleShare) vo.ArticleShare {
+			return h.toVO(src)
+		}),
+	}, nil
+}
+
+func (h *ArticleShareHandler) Revoke(ctx *gin.Context, req vo.ArticleShareRevokeReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	err := h.svc.Revoke(ctx, uc.Uid, req.Id)
+	switch {
+	case err == nil:
+		return ginx.Response{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrArticleShareNotFound):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "分享链接不存在"}, err
+	default:
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+}
+
+func (h *ArticleShareHandler) ListAudits(ctx *gin.Context, req vo.ShareAuditListReq, uc ijwt.UserClaims) (ginx.Response, error) {
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	audits, count, err := h.svc.ListAudits(ctx, uc.Uid, req.Id, (req.PageIndex-1)*req.PageSize, req.PageSize)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrArticleShareNotFound):
+		return ginx.Response{Code: errs.ArticleInvalidInput, Msg: "分享链接不存在"}, err
+	default:
+		return ginx.Response{Code: errs.ArticleInternalServerError, Msg: "系统错误"}, err
+	}
+	return ginx.Response{
+		Data: ginx.Page{
+			List: slice.Map(audits, func(idx int, src domain.ShareAudit) vo.ShareAudit {
+				return vo.ShareAudit{
+					Uid:       src.Uid,
+					Action:    src.Action.ToUint8(),
+					IP:        src.IP,
+					UserAgent: src.UserAgent,
+					Ctime:     src.Ctime.Format(time.DateTime),
+				}
+			}),
+			Count:     count,
+			PageIndex: req.PageIndex,
+			PageSize:  req.PageSize,
+		},
+	}, nil
+}
+
+func (h *ArticleShareHandler) toVO(share domain.ArticleShare) vo.ArticleShare {
+	return vo.ArticleShare{
+		Id:      share.Id,
+		Aid:     share.Aid,
+		Expire:  share.Expire.Format(time.DateTime),
+		Revoked: share.Revoked,
+		Ctime:   share.Ctime.Format(time.DateTime),
+	}
+}
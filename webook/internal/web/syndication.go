@@ -0,0 +1,85 @@
+package web
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SyndicationHandler RSS 和 Atom 订阅源，不需要登录。
+// 默认是 RSS 2.0，带上 format=atom 返回 Atom 1.0
+type SyndicationHandler struct {
+	svc service.SyndicationService
+	l   logger.Logger
+}
+
+func NewSyndicationHandler(svc service.SyndicationService, l logger.Logger) *SyndicationHandler {
+	return &SyndicationHandler{svc: svc, l: l}
+}
+
+func (h *SyndicationHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/feeds")
+	g.GET("/hot.xml", h.Hot)
+	// gin 的路由参数不能带后缀，在 Author 里面去掉 .xml
+	g.GET("/author/:file", h.Author)
+}
+
+func (h *SyndicationHandler) Author(ctx *gin.Context) {
+	idStr, ok := strings.CutSuffix(ctx.Param("file"), ".xml")
+	uid, err := strconv.ParseInt(idStr, 10, 64)
+	if !ok || err != nil || uid <= 0 {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	format, ok := h.format(ctx)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	doc, err := h.svc.AuthorFeed(ctx, uid, format)
+	if err != nil {
+		h.l.Error("生成作者的订阅源失败", logger.Int64("uid", uid), logger.Error(err))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	h.serve(ctx, format, doc)
+}
+
+func (h *SyndicationHandler) Hot(ctx *gin.Context) {
+	format, ok := h.format(ctx)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	doc, err := h.svc.HotFeed(ctx, format)
+	if err != nil {
+		h.l.Error("生成热榜的订阅源失败", logger.Error(err))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	h.serve(ctx, format, doc)
+}
+
+func (h *SyndicationHandler) format(ctx *gin.Context) (domain.SyndicationFormat, bool) {
+	switch f := domain.SyndicationFormat(ctx.Query("format")); f {
+	case "":
+		return domain.SyndicationRSS, true
+	case domain.SyndicationRSS, domain.SyndicationAtom:
+		return f, true
+	default:
+		return "", false
+	}
+}
+
+// serve 由 http.ServeContent 处理 If-None-Match 和 If-Modified-Since，没变的时候返回 304
+func (h *SyndicationHandler) serve(ctx *gin.Context, format domain.SyndicationFormat, doc domain.SyndicationDocument) {
+	ctx.Header("Content-Type", format.ContentType())
+	ctx.Header("ETag", doc.ETag)
+	ctx.Header("Cache-Control", "public, max-age=300")
+	http.ServeContent(ctx.Writer, ctx.Request, "", doc.Modified, bytes.NewReader(doc.Data))
+}
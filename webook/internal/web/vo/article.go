@@ -1,33 +1,77 @@
 package vo
 
 type Article struct {
-	Id         int64  `json:"id,omitempty"`
-	Title      string `json:"title,omitempty"`
-	Abstract   string `json:"abstract,omitempty"`
-	Content    string `json:"content,omitempty"`
-	AuthorId   int64  `json:"authorId,omitempty"`
-	AuthorName string `json:"authorName,omitempty"`
-	Status     uint8  `json:"status,omitempty"`
-	Ctime      string `json:"ctime,omitempty"`
-	Utime      string `json:"utime,omitempty"`
+	Id         int64    `json:"id,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Abstract   string   `json:"abstract,omitempty"`
+	Content    string   `json:"content,omitempty"`
+	ContentURL string   `json:"contentUrl,omitempty"`
+	AuthorId   int64    `json:"authorId,omitempty"`
+	AuthorName string   `json:"authorName,omitempty"`
+	Status     uint8    `json:"status,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	PublishAt  string   `json:"publishAt,omitempty"`
+	Version    int64    `json:"version,omitempty"`
+	Ctime      string   `json:"ctime,omitempty"`
+	Utime      string   `json:"utime,omitempty"`
+	// Price 价格，单位是分，0 表示免费
+	Price int64 `json:"price,omitempty"`
+	// Locked 付费文章没有买过，只有 Abstract 没有 Content
+	Locked bool `json:"locked,omitempty"`
 
 	ReadCnt    int64 `json:"readCnt"`
 	LikeCnt    int64 `json:"likeCnt"`
 	CollectCnt int64 `json:"collectCnt"`
+	CommentCnt int64 `json:"commentCnt"`
 	Liked      bool  `json:"liked"`
 	Collected  bool  `json:"collected"`
+	// Reactions 每一种表态的数量
+	Reactions map[string]int64 `json:"reactions,omitempty"`
+	// Reaction 当前用户的表态
+	Reaction string `json:"reaction,omitempty"`
+	// UniqueReadCnt 去重之后的读者数
+	UniqueReadCnt int64 `json:"uniqueReadCnt"`
+
+	// Related 详情页的相关文章，只有标题、摘要和作者
+	Related []Article `json:"related,omitempty"`
+	// Series 文章在专栏里面的时候才有
+	Series *SeriesNav `json:"series,omitempty"`
+}
+
+// ArticleFeed 游标分页，下一页把 Cursor 原样带回来
+type ArticleFeed struct {
+	List    []Article `json:"list"`
+	Cursor  string    `json:"cursor"`
+	HasMore bool      `json:"hasMore"`
 }
 
 type ArticleEditReq struct {
 	Id      int64
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	// Tags 不传表示不修改标签，传空数组表示清空标签
+	Tags []string `json:"tags"`
+	// Version 修改已有的草稿时必传，是前端最后一次拿到的版本号，
+	// 也就是上一次保存返回的 ArticleSaved.Version
+	Version int64 `json:"version"`
+	// Price 价格，单位是分，0 表示免费
+	Price int64 `json:"price"`
+}
+
+// ArticleSaved 保存、发表和定时发表的结果，下一次保存的时候带上 Version
+type ArticleSaved struct {
+	Id      int64 `json:"id"`
+	Version int64 `json:"version"`
 }
 
 type ArticlePublishReq struct {
 	Id      int64
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title   string   `json:"title"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"`
+	// Version 发表已有的草稿时必传，和 ArticleEditReq 一样
+	Version int64 `json:"version"`
+	Price   int64 `json:"price"`
 }
 
 type ArticleLikeReq struct {
@@ -36,7 +80,128 @@ type ArticleLikeReq struct {
 	Like bool `json:"like"`
 }
 
+type ArticleReactReq struct {
+	Id int64 `json:"id"`
+	// Reaction 为空就是取消表态
+	Reaction string `json:"reaction"`
+}
+
 type ArticleCollectReq struct {
 	Id  int64 `json:"id"`
 	Cid int64 `json:"cid"`
 }
+
+type ArticleRevision struct {
+	Id        int64  `json:"id"`
+	ArticleId int64  `json:"articleId"`
+	Title     string `json:"title,omitempty"`
+	Content   string `json:"content,omitempty"`
+	Status    uint8  `json:"status"`
+	Ctime     string `json:"ctime,omitempty"`
+}
+
+type ArticleRevisionListReq struct {
+	// Id 文章 ID
+	Id        int64 `json:"id"`
+	PageIndex int   `json:"pageIndex"`
+	PageSize  int   `json:"pageSize"`
+}
+
+type ArticleRevisionDiffReq struct {
+	// Id 文章 ID
+	Id int64 `json:"id"`
+	// From 和 To 都是历史版本的 ID
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+type ArticleRevisionRestoreReq struct {
+	// Id 文章 ID
+	Id         int64 `json:"id"`
+	RevisionId int64 `json:"revisionId"`
+	// Version 必传，和 ArticleEditReq 一样是前端最后一次拿到的草稿版本号
+	Version int64 `json:"version"`
+}
+
+type ArticleScheduleReq struct {
+	Id      int64
+	Title   string   `json:"title"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"`
+	// PublishAt 定时发表的时间，毫秒数
+	PublishAt int64 `json:"publishAt"`
+	// Version 发表已有的草稿时必传，和 ArticleEditReq 一样
+	Version int64 `json:"version"`
+	Price   int64 `json:"price"`
+}
+
+type ArticleScheduledListReq struct {
+	PageIndex int `json:"pageIndex"`
+	PageSize  int `json:"pageSize"`
+}
+
+type ArticleRescheduleReq struct {
+	Id        int64 `json:"id"`
+	PublishAt int64 `json:"publishAt"`
+}
+
+type ArticleCancelScheduleReq struct {
+	Id int64 `json:"id"`
+}
+
+type Tag struct {
+	Name       string `json:"name"`
+	ArticleCnt int64  `json:"articleCnt"`
+}
+
+// ArticleSearchHit Title 和 Abstract 是高亮之后的 HTML 片段
+type ArticleSearchHit struct {
+	Id       int64    `json:"id"`
+	Title    string   `json:"title"`
+	Abstract string   `json:"abstract"`
+	AuthorId int64    `json:"authorId"`
+	Tags     []string `json:"tags,omitempty"`
+	Score    float64  `json:"score"`
+	Utime    string   `json:"utime"`
+}
+
+type ArticleDeleteReq struct {
+	Id int64 `json:"id"`
+}
+
+// ArticleInteractedListReq 我的点赞、我的收藏
+type ArticleInteractedListReq struct {
+	PageIndex int `json:"pageIndex"`
+	PageSize  int `json:"pageSize"`
+}
+
+type ArticleTrashListReq struct {
+	PageIndex int `json:"pageIndex"`
+	PageSize  int `json:"pageSize"`
+}
+
+type ArticleRestoreReq struct {
+	Id int64 `json:"id"`
+}
+
+// DeletedArticle 回收站里面的文章
+type DeletedArticle struct {
+	Id       int64  `json:"id"`
+	Title    string `json:"title"`
+	Abstract string `json:"abstract"`
+	Status   uint8  `json:"status"`
+	// Published 恢复的时候线上版本会一起恢复
+	Published bool   `json:"published"`
+	Dtime     string `json:"dtime"`
+}
+
+// ArticleImportResult 导入成功的草稿 ID，以及导入失败的文件
+type ArticleImportResult struct {
+	Ids      []int64                `json:"ids"`
+	Failures []ArticleImportFailure `json:"failures"`
+}
+
+type ArticleImportFailure struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,10 @@
+package vo
+
+// Attachment 上传图片的结果，Url 和 ThumbUrl 是站内的绝对路径，可以直接写进文章内容里面
+type Attachment struct {
+	Url      string `json:"url"`
+	ThumbUrl string `json:"thumbUrl"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Size     int64  `json:"size"`
+}
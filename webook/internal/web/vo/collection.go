@@ -0,0 +1,53 @@
+package vo
+
+// Collection 收藏夹，Id 为 0 的是默认收藏夹
+type Collection struct {
+	Id      int64  `json:"id"`
+	Name    string `json:"name"`
+	ItemCnt int64  `json:"itemCnt"`
+	Ctime   string `json:"ctime"`
+}
+
+// CollectionItem 收藏夹里面的一篇文章，文章已经下线的时候只有 Aid
+type CollectionItem struct {
+	Aid      int64  `json:"aid"`
+	Cid      int64  `json:"cid"`
+	Title    string `json:"title"`
+	Abstract string `json:"abstract"`
+	AuthorId int64  `json:"authorId"`
+	// Ctime 收藏的时间
+	Ctime string `json:"ctime"`
+}
+
+type CollectionCreateReq struct {
+	Name string `json:"name"`
+}
+
+type CollectionRenameReq struct {
+	Cid  int64  `json:"cid"`
+	Name string `json:"name"`
+}
+
+type CollectionDeleteReq struct {
+	Cid int64 `json:"cid"`
+}
+
+type CollectionListReq struct {
+	PageIndex int `json:"pageIndex"`
+	PageSize  int `json:"pageSize"`
+}
+
+type CollectionItemListReq struct {
+	Cid       int64 `json:"cid"`
+	PageIndex int   `json:"pageIndex"`
+	PageSize  int   `json:"pageSize"`
+}
+
+type CollectionItemRemoveReq struct {
+	Aid int64 `json:"aid"`
+}
+
+type CollectionItemMoveReq struct {
+	Aid int64 `json:"aid"`
+	Cid int64 `json:"cid"`
+}
@@ -0,0 +1,37 @@
+package vo
+
+type Comment struct {
+	Id         int64  `json:"id"`
+	BizId      int64  `json:"bizId"`
+	Uid        int64  `json:"uid"`
+	Content    string `json:"content"`
+	RootId     int64  `json:"rootId"`
+	ParentId   int64  `json:"parentId"`
+	ReplyToUid int64  `json:"replyToUid,omitempty"`
+	LikeCnt    int64  `json:"likeCnt"`
+	Ctime      string `json:"ctime"`
+}
+
+// CommentList 游标分页，下一页把 Cursor 原样带回来
+type CommentList struct {
+	List    []Comment `json:"list"`
+	Cursor  int64     `json:"cursor"`
+	HasMore bool      `json:"hasMore"`
+}
+
+type CommentCreateReq struct {
+	// BizId 评论的文章
+	BizId int64 `json:"bizId"`
+	// ParentId 回复的评论，发表顶层评论的时候不传
+	ParentId int64  `json:"parentId"`
+	Content  string `json:"content"`
+}
+
+type CommentDeleteReq struct {
+	Id int64 `json:"id"`
+}
+
+type CommentLikeReq struct {
+	Id   int64 `json:"id"`
+	Like bool  `json:"like"`
+}
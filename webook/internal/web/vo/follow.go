@@ -0,0 +1,25 @@
+package vo
+
+type FollowReq struct {
+	Followee int64 `json:"followee"`
+}
+
+// FollowListReq Uid 不传的时候查自己的
+type FollowListReq struct {
+	Uid       int64 `json:"uid"`
+	PageIndex int   `json:"pageIndex"`
+	PageSize  int   `json:"pageSize"`
+}
+
+// FollowUser 关注列表和粉丝列表里面的一个人
+type FollowUser struct {
+	Uid   int64  `json:"uid"`
+	Ctime string `json:"ctime"`
+}
+
+type FollowStatics struct {
+	Followers int64 `json:"followers"`
+	Followees int64 `json:"followees"`
+	// Followed 当前用户有没有关注这个人
+	Followed bool `json:"followed"`
+}
@@ -0,0 +1,24 @@
+package vo
+
+// ReadHistory 阅读历史里面的一篇文章，文章已经下线的时候只有 Aid
+type ReadHistory struct {
+	Aid        int64   `json:"aid"`
+	Title      string  `json:"title"`
+	Abstract   string  `json:"abstract"`
+	AuthorId   int64   `json:"authorId"`
+	AuthorName string  `json:"authorName"`
+	Progress   float64 `json:"progress"`
+	// Rtime 最近一次看的时间
+	Rtime string `json:"rtime"`
+}
+
+// ReadProgressReq Progress 是 0 到 1 之间的比例
+type ReadProgressReq struct {
+	Aid      int64   `json:"aid"`
+	Progress float64 `json:"progress"`
+}
+
+type ReadProgress struct {
+	Aid      int64   `json:"aid"`
+	Progress float64 `json:"progress"`
+}
@@ -0,0 +1,22 @@
+package vo
+
+type OrderBuyReq struct {
+	Aid int64 `json:"aid"`
+}
+
+type OrderListReq struct {
+	PageIndex int `json:"pageIndex"`
+	PageSize  int `json:"pageSize"`
+}
+
+// Order Amount 的单位是分
+type Order struct {
+	SN     string `json:"sn"`
+	Aid    int64  `json:"aid"`
+	Amount int64  `json:"amount"`
+	Status uint8  `json:"status"`
+	// PayURL 还没有付钱的时候跳过去支付
+	PayURL string `json:"payUrl,omitempty"`
+	Ctime  string `json:"ctime"`
+	Utime  string `json:"utime"`
+}
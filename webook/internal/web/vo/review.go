@@ -0,0 +1,35 @@
+package vo
+
+type ArticleReview struct {
+	Id         int64    `json:"id"`
+	ArticleId  int64    `json:"articleId"`
+	AuthorId   int64    `json:"authorId"`
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	Hits       []string `json:"hits"`
+	Status     uint8    `json:"status"`
+	ReviewerId int64    `json:"reviewerId,omitempty"`
+	Reason     string   `json:"reason,omitempty"`
+	Ctime      string   `json:"ctime"`
+	Utime      string   `json:"utime"`
+}
+
+type ReviewListReq struct {
+	// Status 不传的时候列出所有状态的
+	Status    uint8 `json:"status"`
+	PageIndex int   `json:"pageIndex"`
+	PageSize  int   `json:"pageSize"`
+}
+
+type ReviewApproveReq struct {
+	Id int64 `json:"id"`
+}
+
+type ReviewRejectReq struct {
+	Id     int64  `json:"id"`
+	Reason string `json:"reason"`
+}
+
+type CommentModerateReq struct {
+	Id int64 `json:"id"`
+}
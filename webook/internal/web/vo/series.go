@@ -0,0 +1,49 @@
+package vo
+
+type SeriesEditReq struct {
+	Id          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type SeriesDeleteReq struct {
+	Id int64 `json:"id"`
+}
+
+// SeriesArticlesReq Aids 是专栏里面文章的新顺序
+type SeriesArticlesReq struct {
+	Id   int64   `json:"id"`
+	Aids []int64 `json:"aids"`
+}
+
+type SeriesListReq struct {
+	PageIndex int `json:"pageIndex"`
+	PageSize  int `json:"pageSize"`
+}
+
+type Series struct {
+	Id          int64  `json:"id"`
+	AuthorId    int64  `json:"authorId"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Ctime       string `json:"ctime"`
+	Utime       string `json:"utime"`
+}
+
+// SeriesDetail 专栏页，计数是专栏里面已经发表的文章加起来的
+type SeriesDetail struct {
+	Series
+	Articles   []Article `json:"articles"`
+	ReadCnt    int64     `json:"readCnt"`
+	LikeCnt    int64     `json:"likeCnt"`
+	CollectCnt int64     `json:"collectCnt"`
+	CommentCnt int64     `json:"commentCnt"`
+}
+
+// SeriesNav 文章详情页的专栏导航，没有上一篇或者下一篇的时候不返回
+type SeriesNav struct {
+	Id    int64    `json:"id"`
+	Title string   `json:"title"`
+	Prev  *Article `json:"prev,omitempty"`
+	Next  *Article `json:"next,omitempty"`
+}
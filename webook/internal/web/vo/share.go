@@ -0,0 +1,40 @@
+package vo
+
+// ArticleShareReq TTL 是有效期的秒数
+type ArticleShareReq struct {
+	Aid int64 `json:"aid"`
+	TTL int64 `json:"ttl"`
+}
+
+// ArticleShare Token 和 Link 只有刚创建的时候有
+type ArticleShare struct {
+	Id      int64  `json:"id"`
+	Aid     int64  `json:"aid"`
+	Token   string `json:"token,omitempty"`
+	Link    string `json:"link,omitempty"`
+	Expire  string `json:"expire"`
+	Revoked bool   `json:"revoked"`
+	Ctime   string `json:"ctime"`
+}
+
+type ArticleShareListReq struct {
+	Aid int64 `json:"aid"`
+}
+
+type ArticleShareRevokeReq struct {
+	Id int64 `json:"id"`
+}
+
+type ShareAuditListReq struct {
+	Id        int64 `json:"id"`
+	PageIndex int   `json:"pageIndex"`
+	PageSize  int   `json:"pageSize"`
+}
+
+type ShareAudit struct {
+	Uid       int64  `json:"uid"`
+	Action    uint8  `json:"action"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"userAgent"`
+	Ctime     string `json:"ctime"`
+}
@@ -0,0 +1,163 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/bwmarrin/snowflake"
+	"github.com/jayleonc/geektime-go/webook/internal/repository/dao"
+	"github.com/jayleonc/geektime-go/webook/pkg/objstore"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+	"os"
+	"time"
+)
+
+// InitArticleDAO 按照配置 article.storage 选择文章的存储，默认是 gorm。
+// 可选 gorm、s3 和 mongodb，s3 表示线上库的内容放在 store 里面
+func InitArticleDAO(db *gorm.DB, store objstore.ObjectStore) dao.ArticleDAO {
+	type Config struct {
+		Storage string `yaml:"storage"`
+		// NodeId 雪花算法的节点 ID，只有 mongodb 用得上，每个实例都要不一样
+		NodeId int64 `yaml:"nodeId"`
+		// KeyLayout 内容在对象存储里面的 key 模板，只有 s3 用得上
+		KeyLayout string `yaml:"keyLayout"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("article", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	switch cfg.Storage {
+	case "", "gorm":
+		return dao.NewArticleGORMDAO(db)
+	case "s3":
+		err = db.AutoMigrate(&dao.PublishedArticleV2{})
+		if err != nil {
+			panic(err)
+		}
+		layout := objstore.DefaultLayout
+		if cfg.KeyLayout != "" {
+			layout = objstore.Layout(cfg.KeyLayout)
+		}
+		if err = layout.Validate(); err != nil {
+			panic(err)
+		}
+		return dao.NewArticleS3DAO(db, store, layout)
+	case "mongodb":
+		mdb := initMongoDB()
+		err = dao.InitCollection(mdb)
+		if err != nil {
+			panic(err)
+		}
+		node, err := snowflake.NewNode(cfg.NodeId)
+		if err != nil {
+			panic(err)
+		}
+		return dao.NewMongoDBArticleDAO(mdb, node)
+	default:
+		panic(fmt.Sprintf("未知的文章存储 %s", cfg.Storage))
+	}
+}
+
+// InitTagDAO 标签要和线上库一起查，跟着文章的存储走
+func InitTagDAO(db *gorm.DB, artDAO dao.ArticleDAO) dao.TagDAO {
+	switch art := artDAO.(type) {
+	case *dao.ArticleS3DAO:
+		return dao.NewTagS3DAO(db)
+	case *dao.MongoDBArticleDAO:
+		return dao.NewTagMongoDBDAO(db, art)
+	default:
+		return dao.NewTagGORMDAO(db)
+	}
+}
+
+func initMongoDB() *mongo.Database {
+	type Config struct {
+		URI      string `yaml:"uri"`
+		Database string `yaml:"database"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("mongo", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI))
+	if err != nil {
+		panic(err)
+	}
+	err = client.Ping(ctx, nil)
+	if err != nil {
+		panic(err)
+	}
+	return client.Database(cfg.Database)
+}
+
+// InitObjectStore 按照配置 objectStore.driver 选择对象存储，默认是 local，
+// 开发和测试的时候不需要云厂商的密钥
+func InitObjectStore() objstore.ObjectStore {
+	type LocalConfig struct {
+		Dir string `yaml:"dir"`
+		// BaseURL 签名链接的前缀，要指向 /objects 接口
+		BaseURL string `yaml:"baseURL"`
+		Secret  string `yaml:"secret"`
+	}
+	type Config struct {
+		Driver string      `yaml:"driver"`
+		Bucket string      `yaml:"bucket"`
+		Local  LocalConfig `yaml:"local"`
+	}
+	cfg := Config{
+		Local: LocalConfig{
+			Dir:     "./data/objects",
+			BaseURL: "http://localhost:8080/objects",
+		},
+	}
+	err := viper.UnmarshalKey("objectStore", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	switch cfg.Driver {
+	case "", "local":
+		if cfg.Local.Secret == "" {
+			panic("objectStore.local.secret 不能为空，签名链接要用")
+		}
+		return objstore.NewLocalStore(cfg.Local.Dir, cfg.Local.BaseURL, []byte(cfg.Local.Secret))
+	case "s3":
+		return objstore.NewS3Store(initS3(), cfg.Bucket)
+	default:
+		panic(fmt.Sprintf("未知的对象存储 %s", cfg.Driver))
+	}
+}
+
+// initS3 密钥从环境变量 COS_APP_ID 和 COS_APP_SECRET 里面读，不放在配置文件里
+func initS3() *s3.S3 {
+	type Config struct {
+		Region   string `yaml:"region"`
+		Endpoint string `yaml:"endpoint"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("s3", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	sess, err := session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials(
+			os.Getenv("COS_APP_ID"), os.Getenv("COS_APP_SECRET"), ""),
+		Region:   aws.String(cfg.Region),
+		Endpoint: aws.String(cfg.Endpoint),
+		// 强制使用 /bucket/key 的形态
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return s3.New(sess)
+}
@@ -0,0 +1,33 @@
+package ioc
+
+import (
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/imagex"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/spf13/viper"
+)
+
+func InitAttachmentService(repo repository.AttachmentRepository, l logger.Logger) service.AttachmentService {
+	type Config struct {
+		MaxSize     int64 `yaml:"maxSize"`
+		MaxPixels   int   `yaml:"maxPixels"`
+		ThumbSize   int   `yaml:"thumbSize"`
+		JPEGQuality int   `yaml:"jpegQuality"`
+	}
+	cfg := Config{
+		MaxSize:     10 << 20,
+		MaxPixels:   25_000_000,
+		ThumbSize:   320,
+		JPEGQuality: 85,
+	}
+	err := viper.UnmarshalKey("attachment", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	return service.NewAttachmentService(repo, l, cfg.MaxSize, imagex.Options{
+		MaxPixels:   cfg.MaxPixels,
+		ThumbSize:   cfg.ThumbSize,
+		JPEGQuality: cfg.JPEGQuality,
+	})
+}
@@ -0,0 +1,22 @@
+package ioc
+
+import (
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/spf13/viper"
+	"time"
+)
+
+func InitFeedService(repo repository.FeedRepository, followRepo repository.FollowRepository,
+	artRepo repository.ArticleRepository, l logger.Logger) service.FeedService {
+	bigVThreshold := viper.GetInt64("feed.bigVThreshold")
+	if bigVThreshold <= 0 {
+		bigVThreshold = 10000
+	}
+	activeWindow := viper.GetDuration("feed.activeWindow")
+	if activeWindow <= 0 {
+		activeWindow = time.Hour * 24 * 7
+	}
+	return service.NewFeedService(repo, followRepo, artRepo, l, bigVThreshold, activeWindow)
+}
@@ -1,12 +1,15 @@
 package ioc
 
 import (
+	"context"
 	rlock "github.com/gotomicro/redis-lock"
+	"github.com/jayleonc/geektime-go/webook/internal/domain"
 	"github.com/jayleonc/geektime-go/webook/internal/service"
 	"github.com/jayleonc/geektime-go/webook/job"
 	"github.com/jayleonc/geektime-go/webook/pkg/logger"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/robfig/cron/v3"
+	"github.com/spf13/viper"
 	"time"
 )
 
@@ -14,7 +17,35 @@ func InitRankingJob(svc service.RankingService, l logger.Logger, client *rlock.C
 	return job.NewRankingJob(svc, l, time.Second*30, client)
 }
 
-func InitJobs(l logger.Logger, rjob *job.RankingJob) *cron.Cron {
+// InitArticlePurgeJob 回收站的保留期来自配置 article.trashRetention，默认 30 天
+func InitArticlePurgeJob(svc service.ArticleService) *job.ArticlePurgeJob {
+	retention := viper.GetDuration("article.trashRetention")
+	if retention <= 0 {
+		retention = time.Hour * 24 * 30
+	}
+	return job.NewArticlePurgeJob(svc, retention, time.Minute)
+}
+
+// InitAttachmentGCJob 没有被引用的图片保留 attachment.gcGrace，默认 1 天
+func InitAttachmentGCJob(svc service.AttachmentService) *job.AttachmentGCJob {
+	grace := viper.GetDuration("attachment.gcGrace")
+	if grace <= 0 {
+		grace = time.Hour * 24
+	}
+	return job.NewAttachmentGCJob(svc, grace, time.Minute)
+}
+
+// InitOrderReconcileJob 下单之后超过 payment.reconcileAfter 还没有支付结果的订单要对账，默认 1 分钟
+func InitOrderReconcileJob(svc service.OrderService) *job.OrderReconcileJob {
+	after := viper.GetDuration("payment.reconcileAfter")
+	if after <= 0 {
+		after = time.Minute
+	}
+	return job.NewOrderReconcileJob(svc, after, time.Minute)
+}
+
+func InitJobs(l logger.Logger, rjob *job.RankingJob, purgeJob *job.ArticlePurgeJob,
+	gcJob *job.AttachmentGCJob, reconcileJob *job.OrderReconcileJob) *cron.Cron {
 	builder := job.NewCronJobBuilder(l, prometheus.SummaryOpts{
 		Namespace: "geektime_jayleonc",
 		Subsystem: "webook",
@@ -33,5 +64,57 @@ func InitJobs(l logger.Logger, rjob *job.RankingJob) *cron.Cron {
 	if err != nil {
 		panic(err)
 	}
+	_, err = expr.AddJob("@every 1h", builder.Build(purgeJob))
+	if err != nil {
+		panic(err)
+	}
+	_, err = expr.AddJob("@every 1h", builder.Build(gcJob))
+	if err != nil {
+		panic(err)
+	}
+	_, err = expr.AddJob("@every 1m", builder.Build(reconcileJob))
+	if err != nil {
+		panic(err)
+	}
 	return expr
 }
+
+// InitScheduler 初始化基于 MySQL 抢占的分布式任务调度器，并注册本地任务
+func InitScheduler(l logger.Logger, svc service.CronJobService, artSvc service.ArticleService,
+	relatedSvc service.RelatedArticleService) *job.Scheduler {
+	exec := job.NewLocalFuncExecutor()
+	// 定时发表文章，每 10 秒钟检查一次有没有到点的文章
+	const publishScheduledArticles = "publish_scheduled_articles"
+	exec.RegisterFunc(publishScheduledArticles, func(ctx context.Context, j domain.Job) error {
+		return artSvc.PublishDue(ctx, time.Now())
+	})
+
+	// 相关文章，每小时重新算一次，只会有一个实例在算
+	const computeRelatedArticles = "compute_related_articles"
+	exec.RegisterFunc(computeRelatedArticles, func(ctx context.Context, j domain.Job) error {
+		return relatedSvc.Compute(ctx)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := svc.AddJob(ctx, domain.Job{
+		Name:       publishScheduledArticles,
+		Expression: "*/10 * * * * ?",
+		Executor:   exec.Name(),
+	})
+	if err != nil {
+		panic(err)
+	}
+	err = svc.AddJob(ctx, domain.Job{
+		Name:       computeRelatedArticles,
+		Expression: "0 0 * * * ?",
+		Executor:   exec.Name(),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	scheduler := job.NewScheduler(svc, l)
+	scheduler.RegisterExecutor(exec)
+	return scheduler
+}
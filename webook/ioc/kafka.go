@@ -5,6 +5,10 @@ import (
 	"github.com/jayleonc/geektime-go/webook/internal/events"
 	"github.com/jayleonc/geektime-go/webook/internal/events/article"
 	"github.com/jayleonc/geektime-go/webook/internal/events/article/prometheus"
+	"github.com/jayleonc/geektime-go/webook/internal/events/feed"
+	"github.com/jayleonc/geektime-go/webook/internal/events/history"
+	"github.com/jayleonc/geektime-go/webook/internal/events/search"
+	"github.com/jayleonc/geektime-go/webook/internal/events/syndication"
 	"github.com/spf13/viper"
 )
 
@@ -35,8 +39,9 @@ func NewSyncProducer(client sarama.Client) sarama.SyncProducer {
 }
 
 // RegisterConsumers 注册 Consumer
-func RegisterConsumers() []events.Consumer {
-	return []events.Consumer{}
+func RegisterConsumers(searchConsumer *search.ArticleIndexConsumer, feedConsumer *feed.ArticlePublishConsumer,
+	syndicationConsumer *syndication.ArticlePublishConsumer, historyConsumer *history.ReadEventConsumer) []events.Consumer {
+	return []events.Consumer{searchConsumer, feedConsumer, syndicationConsumer, historyConsumer}
 }
 
 func NewKafkaProducerWithMetricsDecorator(syncProducer sarama.SyncProducer) article.Producer {
@@ -0,0 +1,43 @@
+package ioc
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/internal/web"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/jayleonc/geektime-go/webook/pkg/sensitive"
+	"github.com/spf13/viper"
+)
+
+// InitSensitiveFilter 词库来自配置 moderation.words，配置文件改了之后自动重新加载
+func InitSensitiveFilter(l logger.Logger) *sensitive.Filter {
+	loadDict := func() (sensitive.Dict, error) {
+		var dict sensitive.Dict
+		err := viper.UnmarshalKey("moderation.words", &dict)
+		return dict, err
+	}
+	dict, err := loadDict()
+	if err != nil {
+		panic(err)
+	}
+	filter := sensitive.NewFilter(dict)
+	viper.OnConfigChange(func(in fsnotify.Event) {
+		dict, er := loadDict()
+		if er != nil {
+			// 新的配置有问题就继续用原来的词库
+			l.Error("重新加载敏感词词库失败", logger.Error(er))
+			return
+		}
+		filter.Reload(dict)
+	})
+	return filter
+}
+
+func InitReviewHandler(svc service.ArticleService, commentSvc service.CommentService, l logger.Logger) *web.ReviewHandler {
+	var admins []int64
+	err := viper.UnmarshalKey("moderation.admins", &admins)
+	if err != nil {
+		panic(err)
+	}
+	return web.NewReviewHandler(svc, commentSvc, admins, l)
+}
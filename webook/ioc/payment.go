@@ -0,0 +1,55 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/internal/service/payment"
+	"github.com/jayleonc/geektime-go/webook/internal/service/payment/local"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/spf13/viper"
+	"time"
+)
+
+// InitPaymentGateway 按照配置 payment.gateway 选择支付网关，目前只有本地的 local
+func InitPaymentGateway(l logger.Logger) payment.Gateway {
+	type LocalConfig struct {
+		// NotifyURL 支付回调的地址，也就是 /pay/notify
+		NotifyURL string        `yaml:"notifyURL"`
+		Key       string        `yaml:"key"`
+		Delay     time.Duration `yaml:"delay"`
+		// DropRate 故意丢掉的回调比例，用来演练对账
+		DropRate float64 `yaml:"dropRate"`
+	}
+	type Config struct {
+		Gateway string      `yaml:"gateway"`
+		Local   LocalConfig `yaml:"local"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("payment", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	switch cfg.Gateway {
+	case "", "local":
+		if cfg.Local.Key == "" {
+			panic("payment.local.key 不能为空，支付回调要用来签名")
+		}
+		if cfg.Local.Delay <= 0 {
+			cfg.Local.Delay = time.Second * 3
+		}
+		return local.NewGateway(cfg.Local.NotifyURL, []byte(cfg.Local.Key), cfg.Local.Delay, cfg.Local.DropRate, l)
+	default:
+		panic(fmt.Sprintf("未知的支付网关 %s", cfg.Gateway))
+	}
+}
+
+// InitOrderService 下单之后 payment.orderTimeout 还没有付钱就关掉，默认 30 分钟
+func InitOrderService(repo repository.OrderRepository, artRepo repository.ArticleRepository,
+	gateway payment.Gateway, l logger.Logger) service.OrderService {
+	timeout := viper.GetDuration("payment.orderTimeout")
+	if timeout <= 0 {
+		timeout = time.Minute * 30
+	}
+	return service.NewOrderService(repo, artRepo, gateway, l, timeout)
+}
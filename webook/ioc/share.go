@@ -0,0 +1,23 @@
+package ioc
+
+import (
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/spf13/viper"
+	"time"
+)
+
+// InitArticleShareService 分享链接的签名密钥来自配置 share.key，最长有效期默认 30 天
+func InitArticleShareService(repo repository.ArticleShareRepository, artRepo repository.ArticleRepository,
+	l logger.Logger) service.ArticleShareService {
+	key := viper.GetString("share.key")
+	if key == "" {
+		panic("share.key 不能为空，分享链接要用来签名")
+	}
+	maxTTL := viper.GetDuration("share.maxTTL")
+	if maxTTL <= 0 {
+		maxTTL = time.Hour * 24 * 30
+	}
+	return service.NewArticleShareService(repo, artRepo, l, []byte(key), maxTTL)
+}
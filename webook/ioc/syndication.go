@@ -0,0 +1,25 @@
+package ioc
+
+import (
+	"github.com/jayleonc/geektime-go/webook/internal/repository"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/spf13/viper"
+)
+
+func InitSyndicationService(repo repository.SyndicationRepository, artRepo repository.ArticleRepository,
+	rankingSvc service.RankingService, l logger.Logger) service.SyndicationService {
+	type Config struct {
+		SiteURL string `yaml:"siteURL"`
+		Size    int    `yaml:"size"`
+	}
+	cfg := Config{
+		SiteURL: "http://localhost:8080",
+		Size:    20,
+	}
+	err := viper.UnmarshalKey("syndication", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	return service.NewSyndicationService(repo, artRepo, rankingSvc, l, cfg.SiteURL, cfg.Size)
+}
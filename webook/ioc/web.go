@@ -17,13 +17,32 @@ import (
 	"time"
 )
 
-func InitWebServer(mdls []gin.HandlerFunc, userHdl *web.UserHandler, wechatHdl *web.OAuth2WechatHandler, artHdl *web.ArticleHandler) *gin.Engine {
+func InitWebServer(mdls []gin.HandlerFunc, userHdl *web.UserHandler, wechatHdl *web.OAuth2WechatHandler, artHdl *web.ArticleHandler,
+	searchHdl *web.SearchHandler, commentHdl *web.CommentHandler, objHdl *web.ObjectHandler,
+	reviewHdl *web.ReviewHandler, followHdl *web.FollowHandler, attachHdl *web.AttachmentHandler,
+	archiveHdl *web.ArticleArchiveHandler, syndicationHdl *web.SyndicationHandler,
+	historyHdl *web.ReadHistoryHandler, shareHdl *web.ArticleShareHandler,
+	seriesHdl *web.SeriesHandler, orderHdl *web.OrderHandler,
+	collectionHdl *web.CollectionHandler) *gin.Engine {
 	engine := gin.Default()
 	engine.Use(mdls...)
 
 	userHdl.RegisterRoutes(engine)
 	wechatHdl.RegisterRoutes(engine)
 	artHdl.RegisterRoutes(engine)
+	searchHdl.RegisterRoutes(engine)
+	commentHdl.RegisterRoutes(engine)
+	objHdl.RegisterRoutes(engine)
+	reviewHdl.RegisterRoutes(engine)
+	followHdl.RegisterRoutes(engine)
+	attachHdl.RegisterRoutes(engine)
+	archiveHdl.RegisterRoutes(engine)
+	syndicationHdl.RegisterRoutes(engine)
+	historyHdl.RegisterRoutes(engine)
+	shareHdl.RegisterRoutes(engine)
+	seriesHdl.RegisterRoutes(engine)
+	orderHdl.RegisterRoutes(engine)
+	collectionHdl.RegisterRoutes(engine)
 	return engine
 }
 
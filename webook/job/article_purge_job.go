@@ -0,0 +1,29 @@
+package job
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"time"
+)
+
+// ArticlePurgeJob 彻底删除在回收站里面超过保留期的文章。
+// 多个实例同时跑也没关系，彻底删除是幂等的
+type ArticlePurgeJob struct {
+	svc       service.ArticleService
+	retention time.Duration
+	timeout   time.Duration
+}
+
+func NewArticlePurgeJob(svc service.ArticleService, retention time.Duration, timeout time.Duration) *ArticlePurgeJob {
+	return &ArticlePurgeJob{svc: svc, retention: retention, timeout: timeout}
+}
+
+func (a *ArticlePurgeJob) Name() string {
+	return "article_purge"
+}
+
+func (a *ArticlePurgeJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	return a.svc.PurgeDeleted(ctx, time.Now().Add(-a.retention))
+}
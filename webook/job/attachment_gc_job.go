@@ -0,0 +1,29 @@
+package job
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"time"
+)
+
+// AttachmentGCJob 清理上传之后一直没有被文章引用的图片。
+// grace 要足够长，写文章的时候先上传图片，保存草稿之前的这段时间图片也是没有引用的
+type AttachmentGCJob struct {
+	svc     service.AttachmentService
+	grace   time.Duration
+	timeout time.Duration
+}
+
+func NewAttachmentGCJob(svc service.AttachmentService, grace time.Duration, timeout time.Duration) *AttachmentGCJob {
+	return &AttachmentGCJob{svc: svc, grace: grace, timeout: timeout}
+}
+
+func (a *AttachmentGCJob) Name() string {
+	return "attachment_gc"
+}
+
+func (a *AttachmentGCJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	return a.svc.GCOrphans(ctx, time.Now().Add(-a.grace))
+}
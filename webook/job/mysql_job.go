@@ -43,6 +43,8 @@ func (l *LocalFuncExecutor) Exec(ctx context.Context, j domain.Job) error {
 
 type Scheduler struct {
 	dbTimeout time.Duration
+	// interval 没有抢到任务的时候，等多久再抢
+	interval time.Duration
 
 	svc service.CronJobService
 
@@ -57,6 +59,7 @@ func NewScheduler(svc service.CronJobService, l logger.Logger) *Scheduler {
 		svc:       svc,
 		l:         l,
 		dbTimeout: time.Second,
+		interval:  time.Second,
 		limiter:   semaphore.NewWeighted(100),
 		executors: map[string]Executor{},
 	}
@@ -80,8 +83,13 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 		j, err := s.svc.Preempt(dbCtx)
 		cancel()
 		if err != nil {
-			// 有 Error
-			// 最简单的做法就是直接下一轮，也可以睡一段时间
+			// 有 Error，一般是没有可以调度的任务，睡一段时间再抢
+			s.limiter.Release(1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.interval):
+			}
 			continue
 		}
 
@@ -92,6 +100,8 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 			s.l.Error("找不到执行器",
 				logger.Int64("jid", j.Id),
 				logger.String("executor", j.Executor))
+			s.limiter.Release(1)
+			j.CancelFunc()
 			continue
 		}
 
@@ -0,0 +1,30 @@
+package job
+
+import (
+	"context"
+	"github.com/jayleonc/geektime-go/webook/internal/service"
+	"time"
+)
+
+// OrderReconcileJob 对账，补上丢掉的支付回调，关掉超时的订单。
+// 多个实例同时跑也没关系，订单状态的变更是 CAS
+type OrderReconcileJob struct {
+	svc service.OrderService
+	// after 下单之后超过 after 还没有结果的才去查，刚下的单交给回调
+	after   time.Duration
+	timeout time.Duration
+}
+
+func NewOrderReconcileJob(svc service.OrderService, after time.Duration, timeout time.Duration) *OrderReconcileJob {
+	return &OrderReconcileJob{svc: svc, after: after, timeout: timeout}
+}
+
+func (o *OrderReconcileJob) Name() string {
+	return "order_reconcile"
+}
+
+func (o *OrderReconcileJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+	return o.svc.Reconcile(ctx, time.Now().Add(-o.after))
+}
@@ -0,0 +1,156 @@
+package diffx
+
+import "strings"
+
+type Op string
+
+const (
+	// OpEqual 两边相同的行
+	OpEqual Op = "="
+	// OpInsert 新版本中新增的行
+	OpInsert Op = "+"
+	// OpDelete 旧版本中被删除的行
+	OpDelete Op = "-"
+)
+
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines 按行比较 a 和 b，返回把 a 变成 b 的最短编辑脚本
+func Lines(a, b string) []Line {
+	return Diff(splitLines(a), splitLines(b))
+}
+
+const (
+	// maxLines 任何一边超过这么多行就不求最短编辑脚本了
+	maxLines = 10000
+	// maxEditDistance 回溯要保存每一轮的快照，内存是 O(D²)，差异超过这么多行就不求最短了
+	maxEditDistance = 1000
+)
+
+// Diff 使用 Myers 算法计算最短编辑脚本，时间复杂度 O((N+M)D)。
+// 相同的开头和结尾直接跳过，剩下的部分太长或者差异太大的时候，整体当成先删除再新增
+func Diff(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	res := make([]Line, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		res = append(res, Line{Op: OpEqual, Text: line})
+	}
+	res = append(res, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		res = append(res, Line{Op: OpEqual, Text: line})
+	}
+	return res
+}
+
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD == 0 {
+		return nil
+	}
+	if n > maxLines || m > maxLines {
+		return replace(a, b)
+	}
+	limit := maxD
+	if limit > maxEditDistance {
+		limit = maxEditDistance
+	}
+	// v[k+offset] 表示在对角线 k 上能走到的最远的 x，快照时会访问到 [-maxD-1, maxD+1]
+	offset := maxD + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] 保存第 d 轮开始前 v 在 [-d-1, d+1] 上的快照，用于回溯
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		snapshot := make([]int, 2*d+3)
+		for k := -d - 1; k <= d+1; k++ {
+			snapshot[k+d+1] = v[k+offset]
+		}
+		trace = append(trace, snapshot)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+				x = v[k+1+offset]
+			} else {
+				x = v[k-1+offset] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+offset] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+	return replace(a, b)
+}
+
+// replace 不是最短的编辑脚本，先删掉 a 的全部，再新增 b 的全部
+func replace(a, b []string) []Line {
+	res := make([]Line, 0, len(a)+len(b))
+	for _, line := range a {
+		res = append(res, Line{Op: OpDelete, Text: line})
+	}
+	for _, line := range b {
+		res = append(res, Line{Op: OpInsert, Text: line})
+	}
+	return res
+}
+
+func backtrack(trace [][]int, a, b []string) []Line {
+	x, y := len(a), len(b)
+	res := make([]Line, 0, x+y)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		get := func(k int) int {
+			return v[k+d+1]
+		}
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := get(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			res = append(res, Line{Op: OpEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				res = append(res, Line{Op: OpInsert, Text: b[y-1]})
+			} else {
+				res = append(res, Line{Op: OpDelete, Text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	// 回溯得到的是逆序的
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
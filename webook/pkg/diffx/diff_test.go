@@ -0,0 +1,100 @@
+package diffx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		want []Line
+	}{
+		{
+			name: "都为空",
+			want: []Line{},
+		},
+		{
+			name: "完全相同",
+			a:    "a\nb",
+			b:    "a\nb",
+			want: []Line{
+				{Op: OpEqual, Text: "a"},
+				{Op: OpEqual, Text: "b"},
+			},
+		},
+		{
+			name: "全部新增",
+			b:    "a\nb",
+			want: []Line{
+				{Op: OpInsert, Text: "a"},
+				{Op: OpInsert, Text: "b"},
+			},
+		},
+		{
+			name: "全部删除",
+			a:    "a\nb",
+			want: []Line{
+				{Op: OpDelete, Text: "a"},
+				{Op: OpDelete, Text: "b"},
+			},
+		},
+		{
+			name: "修改中间一行",
+			a:    "a\nb\nc",
+			b:    "a\nx\nc",
+			want: []Line{
+				{Op: OpEqual, Text: "a"},
+				{Op: OpDelete, Text: "b"},
+				{Op: OpInsert, Text: "x"},
+				{Op: OpEqual, Text: "c"},
+			},
+		},
+		{
+			name: "首尾增删",
+			a:    "a\nb\nc",
+			b:    "b\nc\nd",
+			want: []Line{
+				{Op: OpDelete, Text: "a"},
+				{Op: OpEqual, Text: "b"},
+				{Op: OpEqual, Text: "c"},
+				{Op: OpInsert, Text: "d"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Lines(tc.a, tc.b))
+		})
+	}
+}
+
+func TestDiff_Fallback(t *testing.T) {
+	lines := func(prefix string, n int) []string {
+		res := make([]string, n)
+		for i := range res {
+			res[i] = prefix + strconv.Itoa(i)
+		}
+		return res
+	}
+	// 相同的开头和结尾保留，中间差异太大，整体替换
+	a := append(append([]string{"head"}, lines("a", maxEditDistance)...), "tail")
+	b := append(append([]string{"head"}, lines("b", maxEditDistance)...), "tail")
+	res := Diff(a, b)
+	assert.Len(t, res, 2*maxEditDistance+2)
+	assert.Equal(t, Line{Op: OpEqual, Text: "head"}, res[0])
+	assert.Equal(t, Line{Op: OpDelete, Text: "a0"}, res[1])
+	assert.Equal(t, Line{Op: OpInsert, Text: "b0"}, res[maxEditDistance+1])
+	assert.Equal(t, Line{Op: OpEqual, Text: "tail"}, res[len(res)-1])
+
+	// 行数太多，不求最短
+	a = lines("a", maxLines+1)
+	b = lines("b", maxLines+1)
+	res = Diff(a, b)
+	assert.Len(t, res, 2*maxLines+2)
+	assert.Equal(t, Line{Op: OpDelete, Text: "a0"}, res[0])
+	assert.Equal(t, Line{Op: OpInsert, Text: "b0"}, res[maxLines+1])
+}
@@ -0,0 +1,196 @@
+// Package imagex 处理用户上传的图片：校验格式和尺寸，去掉 EXIF 这类元数据，生成缩略图。
+// 只用到了标准库的编解码器，支持 JPEG、PNG 和 GIF
+package imagex
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("不支持的图片格式")
+	ErrTooManyPixels     = errors.New("图片的像素太多")
+)
+
+type Format string
+
+const (
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+	FormatGIF  Format = "gif"
+)
+
+// Ext 文件扩展名，不带点
+func (f Format) Ext() string {
+	if f == FormatJPEG {
+		return "jpg"
+	}
+	return string(f)
+}
+
+func (f Format) ContentType() string {
+	return "image/" + string(f)
+}
+
+// FormatByExt Ext 的逆操作，不认识的返回空字符串
+func FormatByExt(ext string) Format {
+	switch ext {
+	case "jpg":
+		return FormatJPEG
+	case "png":
+		return FormatPNG
+	case "gif":
+		return FormatGIF
+	default:
+		return ""
+	}
+}
+
+type Options struct {
+	// MaxPixels 宽乘以高的上限，避免一张很小的图片解码之后把内存撑爆
+	MaxPixels int
+	// ThumbSize 缩略图长边的像素数，原图更小的时候不放大
+	ThumbSize int
+	// JPEGQuality 重新编码 JPEG 的质量
+	JPEGQuality int
+}
+
+type Result struct {
+	Format Format
+	// Data 重新编码之后的图片，原图里面的 EXIF 等元数据都没有了
+	Data   []byte
+	Width  int
+	Height int
+	// Thumb 缩略图，GIF 只取第一帧，编码成 PNG
+	Thumb       []byte
+	ThumbFormat Format
+}
+
+// Process 解码再重新编码，这样原图里面除了像素以外的东西都会被丢掉。
+// JPEG 会先按照 EXIF 里面的方向转正，因为方向信息也会一起被丢掉
+func Process(data []byte, opts Options) (Result, error) {
+	format := detect(data)
+	if format == "" {
+		return Result{}, ErrUnsupportedFormat
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > opts.MaxPixels {
+		return Result{}, ErrTooManyPixels
+	}
+	if format == FormatGIF {
+		return processGIF(data, opts)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, ErrUnsupportedFormat
+	}
+	if format == FormatJPEG {
+		img = orient(img, jpegOrientation(data))
+	}
+	res := Result{
+		Format:      format,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		ThumbFormat: format,
+	}
+	res.Data, err = encode(img, format, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	res.Thumb, err = encode(Thumbnail(img, opts.ThumbSize), format, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	return res, nil
+}
+
+// processGIF 保留动画，图形控制扩展之外的扩展块（注释、XMP 之类）都会被丢掉
+func processGIF(data []byte, opts Options) (Result, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil || len(g.Image) == 0 {
+		return Result{}, ErrUnsupportedFormat
+	}
+	var buf bytes.Buffer
+	if err = gif.EncodeAll(&buf, g); err != nil {
+		return Result{}, err
+	}
+	res := Result{
+		Format:      FormatGIF,
+		Data:        buf.Bytes(),
+		Width:       g.Config.Width,
+		Height:      g.Config.Height,
+		ThumbFormat: FormatPNG,
+	}
+	res.Thumb, err = encode(Thumbnail(g.Image[0], opts.ThumbSize), FormatPNG, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	return res, nil
+}
+
+// detect 按照文件头判断格式，不相信客户端传上来的 Content-Type 和文件名
+func detect(data []byte) Format {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return FormatJPEG
+	case "image/png":
+		return FormatPNG
+	case "image/gif":
+		return FormatGIF
+	default:
+		return ""
+	}
+}
+
+func encode(img image.Image, format Format, opts Options) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.JPEGQuality})
+	case FormatPNG:
+		err = png.Encode(&buf, img)
+	case FormatGIF:
+		err = gif.Encode(&buf, img, nil)
+	default:
+		err = ErrUnsupportedFormat
+	}
+	return buf.Bytes(), err
+}
+
+// Thumbnail 等比缩放到长边不超过 size
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	if w >= h {
+		h = h * size / w
+		w = size
+	} else {
+		w = w * size / h
+		h = size
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
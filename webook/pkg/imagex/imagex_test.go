@@ -0,0 +1,90 @@
+package imagex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var testOpts = Options{MaxPixels: 1 << 20, ThumbSize: 16, JPEGQuality: 90}
+
+func TestProcess(t *testing.T) {
+	t.Run("JPEG 按方向转正并去掉 EXIF", func(t *testing.T) {
+		data := withOrientation(t, encodeJPEG(t, newImage(40, 20)), 6)
+		require.Equal(t, 6, jpegOrientation(data))
+		res, err := Process(data, testOpts)
+		require.NoError(t, err)
+		assert.Equal(t, FormatJPEG, res.Format)
+		assert.Equal(t, 20, res.Width)
+		assert.Equal(t, 40, res.Height)
+		assert.False(t, bytes.Contains(res.Data, []byte("Exif")))
+		assert.Equal(t, 1, jpegOrientation(res.Data))
+		thumb, _, err := image.DecodeConfig(bytes.NewReader(res.Thumb))
+		require.NoError(t, err)
+		assert.Equal(t, 8, thumb.Width)
+		assert.Equal(t, 16, thumb.Height)
+	})
+
+	t.Run("小图不放大", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, newImage(10, 5)))
+		res, err := Process(buf.Bytes(), testOpts)
+		require.NoError(t, err)
+		assert.Equal(t, FormatPNG, res.ThumbFormat)
+		thumb, _, err := image.DecodeConfig(bytes.NewReader(res.Thumb))
+		require.NoError(t, err)
+		assert.Equal(t, 10, thumb.Width)
+		assert.Equal(t, 5, thumb.Height)
+	})
+
+	t.Run("像素太多", func(t *testing.T) {
+		_, err := Process(encodeJPEG(t, newImage(40, 20)), Options{MaxPixels: 100, ThumbSize: 16})
+		assert.Equal(t, ErrTooManyPixels, err)
+	})
+
+	t.Run("不是图片", func(t *testing.T) {
+		_, err := Process([]byte("<svg></svg>"), testOpts)
+		assert.Equal(t, ErrUnsupportedFormat, err)
+	})
+}
+
+func newImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 5), G: uint8(y * 5), A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+// withOrientation 在 SOI 后面插一个只有方向的 EXIF 段
+func withOrientation(t *testing.T, data []byte, o uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	ifd := make([]byte, 2+12+4)
+	binary.BigEndian.PutUint16(ifd[0:], 1)
+	binary.BigEndian.PutUint16(ifd[2:], 0x0112)
+	binary.BigEndian.PutUint16(ifd[4:], 3)
+	binary.BigEndian.PutUint32(ifd[6:], 1)
+	binary.BigEndian.PutUint16(ifd[10:], o)
+	seg := append([]byte("Exif\x00\x00"), append(tiff, ifd...)...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(seg)+2))
+	app1 = append(app1, seg...)
+	require.True(t, len(data) > 2)
+	res := append([]byte{}, data[:2]...)
+	res = append(res, app1...)
+	return append(res, data[2:]...)
+}
@@ -0,0 +1,85 @@
+package objstore
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStore(t.TempDir(), "http://localhost:8080/objects/", []byte("secret"))
+
+	ok, err := store.Exists(ctx, "a/b")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, err = store.Get(ctx, "a/b")
+	assert.Equal(t, ErrObjectNotFound, err)
+
+	err = store.Put(ctx, "a/b", []byte("hello"), "text/plain")
+	require.NoError(t, err)
+	ok, err = store.Exists(ctx, "a/b")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	data, err := store.Get(ctx, "a/b")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, store.Delete(ctx, "a/b"))
+	// 删除不存在的对象不报错
+	require.NoError(t, store.Delete(ctx, "a/b"))
+	ok, err = store.Exists(ctx, "a/b")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, key := range []string{"", "/etc/passwd", "../a", "a/../../b", "a//b", `a\b`} {
+		err = store.Put(ctx, key, []byte("x"), "")
+		assert.Equal(t, ErrInvalidKey, err, key)
+	}
+}
+
+func TestLocalStore_SignURL(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "http://localhost:8080/objects", []byte("secret"))
+	now := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return now }
+
+	raw, err := store.SignURL(context.Background(), "articles/ab/abc", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, "http://localhost:8080/objects/articles/ab/abc?"))
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	expires, sig := u.Query().Get("expires"), u.Query().Get("signature")
+
+	assert.NoError(t, store.Verify("articles/ab/abc", expires, sig))
+	// 换一个对象
+	assert.Equal(t, ErrInvalidSignature, store.Verify("articles/ab/abd", expires, sig))
+	// 改了过期时间
+	assert.Equal(t, ErrInvalidSignature, store.Verify("articles/ab/abc", "1800000000", sig))
+	// 过期了
+	now = now.Add(time.Minute + time.Second)
+	assert.Equal(t, ErrInvalidSignature, store.Verify("articles/ab/abc", expires, sig))
+}
+
+func TestLayout(t *testing.T) {
+	hash := Hash([]byte("hello"))
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", hash)
+	assert.Equal(t, "articles/2c/"+hash, DefaultLayout.Key(hash))
+	assert.NoError(t, DefaultLayout.Validate())
+	assert.Error(t, Layout("articles/{prefix}").Validate())
+	assert.Error(t, Layout("/articles/{hash}").Validate())
+}
+
+func TestPutIfAbsent(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStore(t.TempDir(), "", nil)
+	require.NoError(t, PutIfAbsent(ctx, store, "k", []byte("first"), ""))
+	// 已经有了就不再写
+	require.NoError(t, PutIfAbsent(ctx, store, "k", []byte("second"), ""))
+	data, err := store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+}
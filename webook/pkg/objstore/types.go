@@ -0,0 +1,83 @@
+package objstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrObjectNotFound = errors.New("对象不存在")
+	ErrInvalidKey     = errors.New("对象的 key 不合法")
+)
+
+// ObjectStore 对象存储，key 是 / 分隔的相对路径
+type ObjectStore interface {
+	// Put 写入对象，已经存在的时候覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get 对象不存在的时候返回 ErrObjectNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete 对象不存在也不会报错
+	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	// SignURL 生成一个 expire 之后失效的下载链接，用来访问不公开的对象
+	SignURL(ctx context.Context, key string, expire time.Duration) (string, error)
+}
+
+// Hash 内容的 SHA-256，十六进制
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Layout 对象 key 的模板，支持两个占位符：
+// {hash} 内容的哈希，{prefix} 哈希的前两个字符，用来把对象打散到不同的目录。
+// 比如 "articles/{prefix}/{hash}"。
+// 因为 key 只和内容有关，所以内容一样的对象只会存一份
+type Layout string
+
+const DefaultLayout Layout = "articles/{prefix}/{hash}"
+
+func (l Layout) Validate() error {
+	if !strings.Contains(string(l), "{hash}") {
+		return errors.New("key 模板里面必须有 {hash}")
+	}
+	return checkKey(l.Key(strings.Repeat("0", 64)))
+}
+
+func (l Layout) Key(hash string) string {
+	prefix := hash
+	if len(prefix) > 2 {
+		prefix = prefix[:2]
+	}
+	return strings.NewReplacer("{hash}", hash, "{prefix}", prefix).Replace(string(l))
+}
+
+// PutIfAbsent 对象已经存在的时候不再上传。
+// key 是按照内容哈希生成的时候，就相当于去重
+func PutIfAbsent(ctx context.Context, store ObjectStore, key string, data []byte, contentType string) error {
+	ok, err := store.Exists(ctx, key)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+	return store.Put(ctx, key, data, contentType)
+}
+
+// checkKey 不允许绝对路径和 ..，避免本地存储写到目录外面
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package saramax
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"strings"
+)
+
+// ConsumeLoop 一直消费到出错为止，调用方自己开 goroutine。
+// 发生 rebalance 的时候 Consume 会返回，需要重新进入
+func ConsumeLoop(cgroup sarama.ConsumerGroup, topics []string,
+	handler sarama.ConsumerGroupHandler, l logger.Logger) {
+	for {
+		err := cgroup.Consume(context.Background(), topics, handler)
+		if err != nil {
+			l.Error("退出消费循环",
+				logger.String("topics", strings.Join(topics, ",")),
+				logger.Error(err))
+			return
+		}
+	}
+}
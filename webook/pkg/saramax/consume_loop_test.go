@@ -0,0 +1,33 @@
+package saramax
+
+import (
+	"context"
+	"errors"
+	"github.com/IBM/sarama"
+	"github.com/jayleonc/geektime-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// fakeConsumerGroup 前几次 Consume 正常返回，模拟 rebalance，之后返回 err
+type fakeConsumerGroup struct {
+	sarama.ConsumerGroup
+	rebalances int
+	calls      int
+	err        error
+}
+
+func (f *fakeConsumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	f.calls++
+	if f.calls <= f.rebalances {
+		return nil
+	}
+	return f.err
+}
+
+func TestConsumeLoop(t *testing.T) {
+	cgroup := &fakeConsumerGroup{rebalances: 2, err: errors.New("consumer group 已经关闭")}
+	ConsumeLoop(cgroup, []string{"article_publish"}, NewHandler[struct{}](nil), logger.NewNopLogger())
+	// rebalance 之后重新进入，出错之后退出
+	assert.Equal(t, 3, cgroup.calls)
+}
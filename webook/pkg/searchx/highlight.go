@@ -0,0 +1,109 @@
+package searchx
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	HighlightPre  = "<em>"
+	HighlightPost = "</em>"
+)
+
+// Highlight 用 <em> 标出 text 中命中 terms 的部分，其余部分会做 HTML 转义。
+// size 大于 0 的时候只截取第一个命中位置附近 size 个字符作为摘要
+func Highlight(text string, terms []string, size int) string {
+	set := make(map[string]struct{}, len(terms))
+	for _, t := range terms {
+		set[t] = struct{}{}
+	}
+	var spans [][2]int
+	for _, t := range tokenize(text, true) {
+		if _, ok := set[t.Term]; ok {
+			spans = append(spans, [2]int{t.Start, t.End})
+		}
+	}
+	spans = mergeSpans(spans)
+
+	start, end := 0, len(text)
+	if size > 0 {
+		if len(spans) > 0 {
+			// 命中的位置前面留四分之一的上下文
+			start = backward(text, spans[0][0], size/4)
+		}
+		end = forward(text, start, size)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("...")
+	}
+	pos := start
+	for _, sp := range spans {
+		if sp[1] <= start {
+			continue
+		}
+		if sp[0] >= end {
+			break
+		}
+		s, e := sp[0], sp[1]
+		if s < pos {
+			s = pos
+		}
+		if e > end {
+			e = end
+		}
+		sb.WriteString(html.EscapeString(text[pos:s]))
+		sb.WriteString(HighlightPre)
+		sb.WriteString(html.EscapeString(text[s:e]))
+		sb.WriteString(HighlightPost)
+		pos = e
+	}
+	sb.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		sb.WriteString("...")
+	}
+	return sb.String()
+}
+
+// mergeSpans 二元组切分出来的词是互相重叠的，合并成不重叠的区间
+func mergeSpans(spans [][2]int) [][2]int {
+	if len(spans) == 0 {
+		return spans
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i][0] < spans[j][0]
+	})
+	res := spans[:1]
+	for _, sp := range spans[1:] {
+		last := &res[len(res)-1]
+		if sp[0] <= last[1] {
+			if sp[1] > last[1] {
+				last[1] = sp[1]
+			}
+			continue
+		}
+		res = append(res, sp)
+	}
+	return res
+}
+
+// backward 从 pos 往前走 n 个字符
+func backward(text string, pos, n int) int {
+	for ; n > 0 && pos > 0; n-- {
+		_, sz := utf8.DecodeLastRuneInString(text[:pos])
+		pos -= sz
+	}
+	return pos
+}
+
+// forward 从 pos 往后走 n 个字符
+func forward(text string, pos, n int) int {
+	for ; n > 0 && pos < len(text); n-- {
+		_, sz := utf8.DecodeRuneInString(text[pos:])
+		pos += sz
+	}
+	return pos
+}
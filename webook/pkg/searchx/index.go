@@ -0,0 +1,259 @@
+package searchx
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 的两个参数，取常用的默认值
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Field 文档里面参与检索的一个字段，Weight 是这个字段的打分权重
+type Field struct {
+	Name   string
+	Text   string
+	Weight float64
+}
+
+// Document 被索引的文档，Data 是业务自己的数据，检索的时候原样返回。
+// Version 用于丢弃乱序到达的旧数据，一般用更新时间
+type Document[T any] struct {
+	Id      int64
+	Version int64
+	Fields  []Field
+	Data    T
+}
+
+type Hit[T any] struct {
+	Doc   Document[T]
+	Score float64
+}
+
+type Result[T any] struct {
+	Hits []Hit[T]
+	// Total 命中的总数，用于分页
+	Total int
+	// Terms 查询分词的结果，用于高亮
+	Terms []string
+}
+
+// posting 某个词在某篇文档某个字段中出现的次数
+type posting struct {
+	field int
+	tf    int
+}
+
+type entry[T any] struct {
+	doc Document[T]
+	// lens 每个字段的词数
+	lens []int
+	// terms 文档里面出现过的词，删除的时候用
+	terms []string
+}
+
+// fieldStat 同名字段的总词数和文档数，用于计算平均长度
+type fieldStat struct {
+	total int64
+	cnt   int64
+}
+
+// Index 进程内的倒排索引，并发安全。
+// 多个词之间是 AND 的关系，按照 BM25 打分，字段之间按照权重加权求和
+type Index[T any] struct {
+	mu       sync.RWMutex
+	docs     map[int64]*entry[T]
+	postings map[string]map[int64][]posting
+	stats    map[string]*fieldStat
+	// deleted 删除的时候的版本，不超过这个版本的 Put 都是乱序到达的旧数据
+	deleted map[int64]int64
+}
+
+func NewIndex[T any]() *Index[T] {
+	return &Index[T]{
+		docs:     make(map[int64]*entry[T]),
+		postings: make(map[string]map[int64][]posting),
+		stats:    make(map[string]*fieldStat),
+		deleted:  make(map[int64]int64),
+	}
+}
+
+// Len 索引中的文档数
+func (idx *Index[T]) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Put 新增或者替换文档。已有版本更新的文档，或者文档在这个版本之后被删除了的时候，
+// 什么也不做并且返回 false
+func (idx *Index[T]) Put(doc Document[T]) bool {
+	e := &entry[T]{doc: doc, lens: make([]int, len(doc.Fields))}
+	tfs := make(map[string][]posting)
+	for i, f := range doc.Fields {
+		tokens := tokenize(f.Text, true)
+		e.lens[i] = len(tokens)
+		cnt := make(map[string]int, len(tokens))
+		for _, t := range tokens {
+			cnt[t.Term]++
+		}
+		for term, tf := range cnt {
+			tfs[term] = append(tfs[term], posting{field: i, tf: tf})
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if version, ok := idx.deleted[doc.Id]; ok {
+		if version >= doc.Version {
+			return false
+		}
+		delete(idx.deleted, doc.Id)
+	}
+	if old, ok := idx.docs[doc.Id]; ok {
+		if old.doc.Version > doc.Version {
+			return false
+		}
+		idx.remove(old)
+	}
+	e.terms = make([]string, 0, len(tfs))
+	for term, ps := range tfs {
+		docs, ok := idx.postings[term]
+		if !ok {
+			docs = make(map[int64][]posting)
+			idx.postings[term] = docs
+		}
+		docs[doc.Id] = ps
+		e.terms = append(e.terms, term)
+	}
+	for i, f := range doc.Fields {
+		st, ok := idx.stats[f.Name]
+		if !ok {
+			st = &fieldStat{}
+			idx.stats[f.Name] = st
+		}
+		st.total += int64(e.lens[i])
+		st.cnt++
+	}
+	idx.docs[doc.Id] = e
+	return true
+}
+
+// Delete 删除文档，version 和 Document 的 Version 是同一种版本。
+// 已有版本更新的文档的时候什么也不做并且返回 false，文档不存在也会记下版本
+func (idx *Index[T]) Delete(id int64, version int64) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if old, ok := idx.docs[id]; ok {
+		if old.doc.Version > version {
+			return false
+		}
+		idx.remove(old)
+	}
+	if idx.deleted[id] < version {
+		idx.deleted[id] = version
+	}
+	return true
+}
+
+// remove 调用者需要持有写锁
+func (idx *Index[T]) remove(e *entry[T]) {
+	id := e.doc.Id
+	for _, term := range e.terms {
+		docs := idx.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	for i, f := range e.doc.Fields {
+		st := idx.stats[f.Name]
+		st.total -= int64(e.lens[i])
+		st.cnt--
+		if st.cnt == 0 {
+			delete(idx.stats, f.Name)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Search 检索包含查询中所有词的文档，分数高的在前，分数一样的时候版本新的在前
+func (idx *Index[T]) Search(query string, offset, limit int) Result[T] {
+	terms := Terms(query)
+	res := Result[T]{Terms: terms}
+	if len(terms) == 0 {
+		return res
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	lists := make([]map[int64][]posting, 0, len(terms))
+	for _, term := range terms {
+		docs, ok := idx.postings[term]
+		if !ok {
+			// AND 语义，有一个词没有命中就整体没有结果
+			return res
+		}
+		lists = append(lists, docs)
+	}
+	// 从最短的倒排链开始求交集
+	sort.Slice(lists, func(i, j int) bool {
+		return len(lists[i]) < len(lists[j])
+	})
+
+	n := float64(len(idx.docs))
+	idfs := make([]float64, len(lists))
+	for i, docs := range lists {
+		df := float64(len(docs))
+		idfs[i] = math.Log(1 + (n-df+0.5)/(df+0.5))
+	}
+
+	hits := make([]Hit[T], 0, len(lists[0]))
+outer:
+	for id := range lists[0] {
+		for _, docs := range lists[1:] {
+			if _, ok := docs[id]; !ok {
+				continue outer
+			}
+		}
+		e := idx.docs[id]
+		var score float64
+		for i, docs := range lists {
+			for _, p := range docs[id] {
+				f := e.doc.Fields[p.field]
+				st := idx.stats[f.Name]
+				avg := float64(st.total) / float64(st.cnt)
+				if avg == 0 {
+					avg = 1
+				}
+				tf := float64(p.tf)
+				norm := tf * (k1 + 1) / (tf + k1*(1-b+b*float64(e.lens[p.field])/avg))
+				score += f.Weight * idfs[i] * norm
+			}
+		}
+		hits = append(hits, Hit[T]{Doc: e.doc, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Doc.Version != hits[j].Doc.Version {
+			return hits[i].Doc.Version > hits[j].Doc.Version
+		}
+		return hits[i].Doc.Id > hits[j].Doc.Id
+	})
+
+	res.Total = len(hits)
+	if offset >= len(hits) {
+		return res
+	}
+	end := offset + limit
+	if end > len(hits) {
+		end = len(hits)
+	}
+	res.Hits = hits[offset:end]
+	return res
+}
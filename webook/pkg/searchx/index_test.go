@@ -0,0 +1,77 @@
+package searchx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex[string]()
+	put := func(id, version int64, title, content string) bool {
+		return idx.Put(Document[string]{
+			Id:      id,
+			Version: version,
+			Fields: []Field{
+				{Name: "title", Text: title, Weight: 3},
+				{Name: "content", Text: content, Weight: 1},
+			},
+			Data: title,
+		})
+	}
+	put(1, 1, "Go 并发编程", "goroutine 和 channel 的用法")
+	put(2, 1, "Redis 分布式锁", "用 Redis 和 Go 实现一个分布式锁")
+	put(3, 1, "MySQL 索引", "B+ 树，和 Go 没有关系")
+	assert.Equal(t, 3, idx.Len())
+
+	ids := func(res Result[string]) []int64 {
+		var res2 []int64
+		for _, h := range res.Hits {
+			res2 = append(res2, h.Doc.Id)
+		}
+		return res2
+	}
+
+	// 标题命中的权重更高
+	res := idx.Search("go", 0, 10)
+	assert.Equal(t, 3, res.Total)
+	assert.Equal(t, int64(1), res.Hits[0].Doc.Id)
+
+	// 多个词之间是 AND
+	res = idx.Search("分布式 go", 0, 10)
+	assert.Equal(t, []int64{2}, ids(res))
+	assert.Equal(t, []string{"分布", "布式", "go"}, res.Terms)
+
+	// 分页
+	res = idx.Search("go", 1, 1)
+	assert.Equal(t, 3, res.Total)
+	assert.Len(t, res.Hits, 1)
+	res = idx.Search("go", 5, 1)
+	assert.Equal(t, 3, res.Total)
+	assert.Empty(t, res.Hits)
+
+	// 旧版本不会覆盖新版本
+	assert.True(t, put(2, 2, "Redis 分布式锁", "换成了 Lua 脚本"))
+	assert.False(t, put(2, 1, "Redis 分布式锁", "用 Go 实现"))
+	assert.Equal(t, []int64{2}, ids(idx.Search("lua", 0, 10)))
+	assert.Equal(t, []int64{1, 3}, ids(idx.Search("go", 0, 10)))
+
+	// 删除之后搜不到
+	assert.True(t, idx.Delete(2, 3))
+	assert.True(t, idx.Delete(100, 1))
+	assert.Empty(t, idx.Search("lua", 0, 10).Hits)
+	assert.Equal(t, 2, idx.Len())
+
+	// 删除之前的版本晚到了，不会再加回来
+	assert.False(t, put(2, 2, "Redis 分布式锁", "换成了 Lua 脚本"))
+	assert.False(t, put(2, 3, "Redis 分布式锁", "换成了 Lua 脚本"))
+	assert.False(t, put(100, 1, "文档 100", "还没加进来就删掉了"))
+	assert.Empty(t, idx.Search("lua", 0, 10).Hits)
+	// 删除之后又重新发表了
+	assert.True(t, put(2, 4, "Redis 分布式锁", "换成了 Lua 脚本"))
+	assert.Equal(t, []int64{2}, ids(idx.Search("lua", 0, 10)))
+	// 删除的版本比文档旧，不删
+	assert.False(t, idx.Delete(2, 3))
+	assert.Equal(t, []int64{2}, ids(idx.Search("lua", 0, 10)))
+
+	assert.Empty(t, idx.Search("  ，。 ", 0, 10).Hits)
+}
@@ -0,0 +1,108 @@
+package searchx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxTermLen 超过这个长度的拉丁词基本都是链接、哈希之类的，没有检索的意义
+const maxTermLen = 64
+
+// Token 一个词，以及它在原文中的字节区间 [Start, End)
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// Tokenize 查询分词：拉丁字母和数字按照单词切分并转小写，
+// 连续的中日韩文字按照二元组（bigram）切分，只有一个字的时候就是这个字本身
+func Tokenize(text string) []Token {
+	return tokenize(text, false)
+}
+
+// tokenize 建索引的时候 unigram 为 true，额外把每个中日韩文字单独作为一个词，
+// 这样只输入一个字也能搜到
+func tokenize(text string, unigram bool) []Token {
+	var res []Token
+	// 当前这一段连续字符的开始位置，以及这一段是不是中日韩文字
+	start, cjk := -1, false
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if cjk {
+			res = appendCJK(res, text[start:end], start, unigram)
+		} else if end-start <= maxTermLen {
+			res = append(res, Token{Term: strings.ToLower(text[start:end]), Start: start, End: end})
+		}
+		start = -1
+	}
+	for i, r := range text {
+		switch {
+		case isCJK(r):
+			if start >= 0 && !cjk {
+				flush(i)
+			}
+			if start < 0 {
+				start, cjk = i, true
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if start >= 0 && cjk {
+				flush(i)
+			}
+			if start < 0 {
+				start, cjk = i, false
+			}
+		default:
+			flush(i)
+		}
+	}
+	flush(len(text))
+	return res
+}
+
+func appendCJK(res []Token, seg string, offset int, unigram bool) []Token {
+	// 每个字的起始位置，最后再放一个结束位置
+	pos := make([]int, 0, len(seg)/3+1)
+	for i := range seg {
+		pos = append(pos, i)
+	}
+	pos = append(pos, len(seg))
+	n := len(pos) - 1
+	if n == 1 {
+		return append(res, Token{Term: seg, Start: offset, End: offset + len(seg)})
+	}
+	for i := 0; i < n; i++ {
+		if unigram {
+			res = append(res, Token{Term: seg[pos[i]:pos[i+1]], Start: offset + pos[i], End: offset + pos[i+1]})
+		}
+		if i+1 < n {
+			res = append(res, Token{Term: seg[pos[i]:pos[i+2]], Start: offset + pos[i], End: offset + pos[i+2]})
+		}
+	}
+	return res
+}
+
+func isCJK(r rune) bool {
+	if r < utf8.RuneSelf {
+		return false
+	}
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Terms 查询里面去重之后的词
+func Terms(query string) []string {
+	tokens := Tokenize(query)
+	res := make([]string, 0, len(tokens))
+	seen := make(map[string]struct{}, len(tokens))
+	for _, t := range tokens {
+		if _, ok := seen[t.Term]; ok {
+			continue
+		}
+		seen[t.Term] = struct{}{}
+		res = append(res, t.Term)
+	}
+	return res
+}
@@ -0,0 +1,88 @@
+package searchx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "空字符串",
+		},
+		{
+			name: "英文单词转小写",
+			text: "Hello, Go World!",
+			want: []string{"hello", "go", "world"},
+		},
+		{
+			name: "中文二元组",
+			text: "分布式锁",
+			want: []string{"分布", "布式", "式锁"},
+		},
+		{
+			name: "单个汉字",
+			text: "锁",
+			want: []string{"锁"},
+		},
+		{
+			name: "中英文混合",
+			text: "用Redis实现分布式锁v2",
+			want: []string{"用", "redis", "实现", "现分", "分布", "布式", "式锁", "v2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var terms []string
+			for _, tk := range Tokenize(tc.text) {
+				terms = append(terms, tk.Term)
+			}
+			assert.Equal(t, tc.want, terms)
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	testCases := []struct {
+		name  string
+		text  string
+		query string
+		size  int
+		want  string
+	}{
+		{
+			name:  "合并重叠的二元组",
+			text:  "基于 Redis 的分布式锁",
+			query: "分布式",
+			want:  "基于 Redis 的<em>分布式</em>锁",
+		},
+		{
+			name:  "单字查询",
+			text:  "分布式锁",
+			query: "锁",
+			want:  "分布式<em>锁</em>",
+		},
+		{
+			name:  "忽略大小写并且转义",
+			text:  "<b>Go</b> 语言",
+			query: "go",
+			want:  "&lt;b&gt;<em>Go</em>&lt;/b&gt; 语言",
+		},
+		{
+			name:  "截取命中位置附近的摘要",
+			text:  "一二三四五六七八九十分布式锁一二三四五六七八九十",
+			query: "分布式",
+			size:  8,
+			want:  "...九十<em>分布式</em>锁一二...",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Highlight(tc.text, Terms(tc.query), tc.size))
+		})
+	}
+}
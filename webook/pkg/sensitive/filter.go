@@ -0,0 +1,87 @@
+package sensitive
+
+import "sync/atomic"
+
+type Verdict uint8
+
+const (
+	// VerdictPass 没有命中
+	VerdictPass Verdict = iota
+	// VerdictMask 只命中了需要打码的词，打码之后就可以用
+	VerdictMask
+	// VerdictReview 命中了需要人工审核的词
+	VerdictReview
+)
+
+// Dict 词库
+type Dict struct {
+	// Mask 直接打码的词
+	Mask []string
+	// Review 需要人工审核的词
+	Review []string
+}
+
+type Result struct {
+	Verdict Verdict
+	// Text 打码之后的文本，不需要打码的时候就是原文
+	Text string
+	// Hits 决定了 Verdict 的那些词，去重
+	Hits []string
+}
+
+// Filter 敏感词过滤。词库可以通过 Reload 整体替换，替换的过程中检查照常进行
+type Filter struct {
+	dict atomic.Pointer[matchers]
+	mask rune
+}
+
+type matchers struct {
+	mask   *Matcher
+	review *Matcher
+}
+
+func NewFilter(dict Dict) *Filter {
+	f := &Filter{mask: '*'}
+	f.Reload(dict)
+	return f
+}
+
+// Reload 新的词库先构建好，再一次性换上去
+func (f *Filter) Reload(dict Dict) {
+	f.dict.Store(&matchers{
+		mask:   NewMatcher(dict.Mask),
+		review: NewMatcher(dict.Review),
+	})
+}
+
+func (f *Filter) Check(text string) Result {
+	ms := f.dict.Load()
+	if matches := ms.review.FindAll(text); len(matches) > 0 {
+		return Result{
+			Verdict: VerdictReview,
+			Text:    text,
+			Hits:    words(matches),
+		}
+	}
+	if matches := ms.mask.FindAll(text); len(matches) > 0 {
+		return Result{
+			Verdict: VerdictMask,
+			Text:    Mask(text, matches, f.mask),
+			Hits:    words(matches),
+		}
+	}
+	return Result{Verdict: VerdictPass, Text: text}
+}
+
+func words(matches []Match) []string {
+	res := make([]string, 0, len(matches))
+	seen := make(map[string]struct{}, len(matches))
+	for _, m := range matches {
+		if _, ok := seen[m.Word]; ok {
+			continue
+		}
+		seen[m.Word] = struct{}{}
+		res = append(res, m.Word)
+	}
+	return res
+}
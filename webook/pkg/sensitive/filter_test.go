@@ -0,0 +1,48 @@
+package sensitive
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFilter_Check(t *testing.T) {
+	f := NewFilter(Dict{
+		Mask:   []string{"垃圾"},
+		Review: []string{"赌博"},
+	})
+	testCases := []struct {
+		name string
+		text string
+		want Result
+	}{
+		{
+			name: "通过",
+			text: "正常的内容",
+			want: Result{Verdict: VerdictPass, Text: "正常的内容"},
+		},
+		{
+			name: "打码",
+			text: "垃圾内容，真垃圾",
+			want: Result{Verdict: VerdictMask, Text: "**内容，真**", Hits: []string{"垃圾"}},
+		},
+		{
+			name: "审核优先于打码，原文不动",
+			text: "垃圾赌博",
+			want: Result{Verdict: VerdictReview, Text: "垃圾赌博", Hits: []string{"赌博"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, f.Check(tc.text))
+		})
+	}
+}
+
+func TestFilter_Reload(t *testing.T) {
+	f := NewFilter(Dict{Review: []string{"赌博"}})
+	assert.Equal(t, VerdictReview, f.Check("赌博").Verdict)
+	f.Reload(Dict{Mask: []string{"赌博"}})
+	assert.Equal(t, VerdictMask, f.Check("赌博").Verdict)
+	f.Reload(Dict{})
+	assert.Equal(t, VerdictPass, f.Check("赌博").Verdict)
+}
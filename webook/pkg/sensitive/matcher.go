@@ -0,0 +1,172 @@
+package sensitive
+
+import "unicode"
+
+// Matcher 基于 Aho-Corasick 自动机的多模式匹配，扫描一遍文本就能找出所有命中的词。
+// 匹配不区分大小写。构造之后只读，可以并发使用
+type Matcher struct {
+	nodes []node
+	words []string
+}
+
+type node struct {
+	next map[rune]int
+	fail int
+	// word 以这个节点结尾的词在 words 里面的下标，-1 表示不是词的结尾
+	word int
+	// output 沿着 fail 链往上，最近的一个词结尾的节点，-1 表示没有
+	output int
+}
+
+// Match 命中的词，Start 和 End 按照字符（rune）计算，左闭右开
+type Match struct {
+	Word  string
+	Start int
+	End   int
+}
+
+func NewMatcher(words []string) *Matcher {
+	m := &Matcher{
+		nodes: []node{newNode()},
+	}
+	for _, w := range words {
+		m.insert(w)
+	}
+	m.build()
+	return m
+}
+
+func newNode() node {
+	return node{
+		next:   map[rune]int{},
+		word:   -1,
+		output: -1,
+	}
+}
+
+func (m *Matcher) insert(word string) {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return
+	}
+	cur := 0
+	for _, r := range runes {
+		r = unicode.ToLower(r)
+		nxt, ok := m.nodes[cur].next[r]
+		if !ok {
+			nxt = len(m.nodes)
+			m.nodes = append(m.nodes, newNode())
+			m.nodes[cur].next[r] = nxt
+		}
+		cur = nxt
+	}
+	// 重复的词只记一次
+	if m.nodes[cur].word < 0 {
+		m.nodes[cur].word = len(m.words)
+		m.words = append(m.words, word)
+	}
+}
+
+// build 按照层序遍历计算 fail 指针
+func (m *Matcher) build() {
+	queue := make([]int, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].next {
+			fail := m.nodes[cur].fail
+			for {
+				if nxt, ok := m.nodes[fail].next[r]; ok {
+					m.nodes[child].fail = nxt
+					break
+				}
+				if fail == 0 {
+					m.nodes[child].fail = 0
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			f := m.nodes[child].fail
+			if m.nodes[f].word >= 0 {
+				m.nodes[child].output = f
+			} else {
+				m.nodes[child].output = m.nodes[f].output
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// FindAll 按照结束位置的顺序返回所有命中，包括互相重叠的
+func (m *Matcher) FindAll(text string) []Match {
+	var res []Match
+	m.scan(text, func(match Match) bool {
+		res = append(res, match)
+		return true
+	})
+	return res
+}
+
+// Contains 有没有命中任何一个词
+func (m *Matcher) Contains(text string) bool {
+	found := false
+	m.scan(text, func(match Match) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// scan fn 返回 false 的时候停止扫描
+func (m *Matcher) scan(text string, fn func(match Match) bool) {
+	if len(m.words) == 0 {
+		return
+	}
+	cur, pos := 0, 0
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		pos++
+		for {
+			if nxt, ok := m.nodes[cur].next[r]; ok {
+				cur = nxt
+				break
+			}
+			if cur == 0 {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		out := cur
+		if m.nodes[out].word < 0 {
+			out = m.nodes[out].output
+		}
+		for out > 0 {
+			word := m.words[m.nodes[out].word]
+			if !fn(Match{
+				Word:  word,
+				Start: pos - len([]rune(word)),
+				End:   pos,
+			}) {
+				return
+			}
+			out = m.nodes[out].output
+		}
+	}
+}
+
+// Mask 把命中的部分替换成 mask，一个字符换一个
+func Mask(text string, matches []Match, mask rune) string {
+	if len(matches) == 0 {
+		return text
+	}
+	runes := []rune(text)
+	for _, match := range matches {
+		for i := match.Start; i < match.End; i++ {
+			runes[i] = mask
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,75 @@
+package sensitive
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMatcher_FindAll(t *testing.T) {
+	testCases := []struct {
+		name  string
+		words []string
+		text  string
+		want  []Match
+	}{
+		{
+			name: "空词库",
+			text: "随便什么内容",
+		},
+		{
+			name:  "没有命中",
+			words: []string{"赌博"},
+			text:  "今天天气不错",
+		},
+		{
+			name:  "多次命中",
+			words: []string{"赌博", "发票"},
+			text:  "赌博和代开发票，还是赌博",
+			want: []Match{
+				{Word: "赌博", Start: 0, End: 2},
+				{Word: "发票", Start: 5, End: 7},
+				{Word: "赌博", Start: 10, End: 12},
+			},
+		},
+		{
+			name:  "重叠和包含",
+			words: []string{"he", "she", "his", "hers"},
+			text:  "ushers",
+			want: []Match{
+				{Word: "she", Start: 1, End: 4},
+				{Word: "he", Start: 2, End: 4},
+				{Word: "hers", Start: 2, End: 6},
+			},
+		},
+		{
+			name:  "不区分大小写",
+			words: []string{"Casino"},
+			text:  "online CASINO",
+			want: []Match{
+				{Word: "Casino", Start: 7, End: 13},
+			},
+		},
+		{
+			name:  "失配之后沿着 fail 指针继续",
+			words: []string{"abcd", "bce"},
+			text:  "abce",
+			want: []Match{
+				{Word: "bce", Start: 1, End: 4},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewMatcher(tc.words)
+			assert.Equal(t, tc.want, m.FindAll(tc.text))
+			assert.Equal(t, len(tc.want) > 0, m.Contains(tc.text))
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	m := NewMatcher([]string{"垃圾", "广告"})
+	text := "这是垃圾广告"
+	assert.Equal(t, "这是****", Mask(text, m.FindAll(text), '*'))
+	assert.Equal(t, "正常内容", Mask("正常内容", nil, '*'))
+}
@@ -0,0 +1,124 @@
+package tfidf
+
+import (
+	"math"
+	"sort"
+)
+
+// Document 一篇文档切好的词，同一个词出现几次就放几次
+type Document struct {
+	Id    int64
+	Terms []string
+}
+
+type Options struct {
+	// MaxTerms 每篇文档只保留权重最高的这么多个词，0 表示不限制
+	MaxTerms int
+	// MaxDF 出现在超过这个比例的文档里面的词区分不了文档，直接忽略，0 表示不限制
+	MaxDF float64
+}
+
+// Scored 一篇文档以及它的分数
+type Scored struct {
+	Id    int64
+	Score float64
+}
+
+type weight struct {
+	term  string
+	value float64
+}
+
+type posting struct {
+	id    int64
+	value float64
+}
+
+// Model 一批文档的 TF-IDF 向量。向量都归一化了，两个向量的点积就是余弦相似度。
+// 建好之后只读，可以并发使用
+type Model struct {
+	vectors  map[int64][]weight
+	postings map[string][]posting
+}
+
+func Build(docs []Document, opts Options) *Model {
+	df := make(map[string]int)
+	tfs := make([]map[string]int, len(docs))
+	for i, doc := range docs {
+		tf := make(map[string]int, len(doc.Terms))
+		for _, term := range doc.Terms {
+			tf[term]++
+		}
+		for term := range tf {
+			df[term]++
+		}
+		tfs[i] = tf
+	}
+	n := float64(len(docs))
+	m := &Model{
+		vectors:  make(map[int64][]weight, len(docs)),
+		postings: make(map[string][]posting),
+	}
+	for i, doc := range docs {
+		ws := make([]weight, 0, len(tfs[i]))
+		for term, cnt := range tfs[i] {
+			if opts.MaxDF > 0 && float64(df[term]) > opts.MaxDF*n {
+				continue
+			}
+			// 平滑过的 idf，所有文档里面都有的词也不会是 0
+			idf := math.Log((1+n)/(1+float64(df[term]))) + 1
+			ws = append(ws, weight{term: term, value: (1 + math.Log(float64(cnt))) * idf})
+		}
+		sort.Slice(ws, func(i, j int) bool {
+			if ws[i].value != ws[j].value {
+				return ws[i].value > ws[j].value
+			}
+			return ws[i].term < ws[j].term
+		})
+		if opts.MaxTerms > 0 && len(ws) > opts.MaxTerms {
+			ws = ws[:opts.MaxTerms]
+		}
+		var norm float64
+		for _, w := range ws {
+			norm += w.value * w.value
+		}
+		norm = math.Sqrt(norm)
+		for j := range ws {
+			ws[j].value /= norm
+			m.postings[ws[j].term] = append(m.postings[ws[j].term], posting{id: doc.Id, value: ws[j].value})
+		}
+		m.vectors[doc.Id] = ws
+	}
+	return m
+}
+
+// Similar 和 id 最相似的 k 篇文档，不包括自己。没有共同词的文档不会出现
+func (m *Model) Similar(id int64, k int) []Scored {
+	scores := make(map[int64]float64)
+	for _, w := range m.vectors[id] {
+		for _, p := range m.postings[w.term] {
+			if p.id != id {
+				scores[p.id] += w.value * p.value
+			}
+		}
+	}
+	return TopK(scores, k)
+}
+
+// TopK 分数最高的 k 个，分数一样的时候 id 大的在前
+func TopK(scores map[int64]float64, k int) []Scored {
+	res := make([]Scored, 0, len(scores))
+	for id, score := range scores {
+		res = append(res, Scored{Id: id, Score: score})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Id > res[j].Id
+	})
+	if len(res) > k {
+		res = res[:k]
+	}
+	return res
+}
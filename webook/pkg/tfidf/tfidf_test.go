@@ -0,0 +1,44 @@
+package tfidf
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestModel_Similar(t *testing.T) {
+	m := Build([]Document{
+		{Id: 1, Terms: []string{"go", "redis", "lock", "the"}},
+		{Id: 2, Terms: []string{"go", "redis", "cache", "the"}},
+		{Id: 3, Terms: []string{"mysql", "index", "the"}},
+		{Id: 4, Terms: []string{"redis", "lock", "lock", "the"}},
+	}, Options{MaxDF: 0.8})
+
+	res := m.Similar(1, 10)
+	require.Len(t, res, 2)
+	// 4 和 1 共有 redis 和 lock，lock 比 go 少见，所以排在前面
+	assert.Equal(t, int64(4), res[0].Id)
+	assert.Equal(t, int64(2), res[1].Id)
+	assert.Greater(t, res[0].Score, res[1].Score)
+	assert.LessOrEqual(t, res[0].Score, 1.0)
+
+	// the 到处都有，被忽略了，3 和别的文档没有共同的词
+	assert.Empty(t, m.Similar(3, 10))
+	assert.Len(t, m.Similar(1, 1), 1)
+	assert.Empty(t, m.Similar(100, 10))
+}
+
+func TestBuild_MaxTerms(t *testing.T) {
+	m := Build([]Document{
+		{Id: 1, Terms: []string{"a", "a", "a", "b"}},
+		{Id: 2, Terms: []string{"b"}},
+		{Id: 3, Terms: []string{"a", "a", "c"}},
+	}, Options{MaxTerms: 1})
+	// 1 只留了 a，和 2 就没有关系了。都只有一个词，所以相似度是 1
+	assert.Equal(t, []Scored{{Id: 3, Score: 1}}, m.Similar(1, 10))
+}
+
+func TestTopK(t *testing.T) {
+	res := TopK(map[int64]float64{1: 0.5, 2: 0.9, 3: 0.5}, 2)
+	assert.Equal(t, []Scored{{Id: 2, Score: 0.9}, {Id: 3, Score: 0.5}}, res)
+}